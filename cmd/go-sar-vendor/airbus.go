@@ -4,12 +4,19 @@ package main
 //
 // Follows the same structure as capellaCmd / iceyeCmd / umbraCmd already in the
 // repository. It supports:
-//   - POST /feasibility       – gosar airbus feasibility < body.json
-//   - POST /catalogue         – gosar airbus catalogue < body.json
-//   - POST /baskets           – gosar airbus basket create
+//   - POST /feasibility       – gosar airbus feasibility [--geojson out.json] < body.json
+//     or gosar airbus feasibility --template NAME --aoi aoi.geojson --from T [--to T]
+//   - templates               – gosar airbus feasibility templates list|show|save
+//   - POST /catalogue         – gosar airbus catalogue [--geojson out.json] < body.json
+//   - POST /catalogue/retrieve – gosar airbus catalogue retrieve --item UUID | --acquisition ID [--product-type SSC]
+//   - POST /baskets           – gosar airbus basket create [--purpose Government]
 //   - POST /baskets/{id}/addItems – gosar airbus basket add --basket-id ID --item ACQID [...]
-//   - POST /baskets/{id}/submit – gosar airbus basket submit --basket-id ID
+//   - POST /baskets/{id}/submit – gosar airbus basket submit --basket-id ID [--max-price N --currency EUR]
+//   - GET /orders             – gosar airbus order list [--customer NAME --item-status delivered ...]
 //   - GET /orders/{id}        – gosar airbus order ORD-123
+//   - POST /orders/reorder    – gosar airbus order reorder --item UUID --product-type SSC
+//   - POST /orders/submit     – gosar airbus order submit --acquisition ID --purpose Government
+//   - /config/deliveries      – gosar airbus delivery list|get|create|update|delete
 //
 // The command inherits global flags (api-key, token-url, base-url) so the SDK
 // can target staging or test endpoints.
@@ -17,8 +24,11 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/airbus"
 	"github.com/urfave/cli/v3"
@@ -37,10 +47,41 @@ func abClient(cmd *cli.Command) (*airbus.Client, error) {
 	return airbus.NewClient(cmd.String("api-key"), opts...)
 }
 
-func prettyJSON(v any) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(v)
+// geojsonFlags are the flags of commands that can export search results as
+// GeoJSON for web maps.
+func geojsonFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "geojson", Usage: "Write the results to this file as styled GeoJSON instead of printing them"},
+		&cli.StringFlag{Name: "style-by", Value: string(airbus.StyleByMission), Usage: "Color GeoJSON features by mission or pathDirection (empty for none)"},
+	}
+}
+
+// printOrExportFeatures prints res, or writes it to the --geojson file.
+func printOrExportFeatures(cmd *cli.Command, res *airbus.FeatureCollection) error {
+	path := cmd.String("geojson")
+	if path == "" {
+		return printItems(cmd, res, res.Features)
+	}
+	styleBy := airbus.StyleBy(cmd.String("style-by"))
+	switch styleBy {
+	case airbus.StyleByNone, airbus.StyleByMission, airbus.StyleByPathDirection:
+	default:
+		return fmt.Errorf("unknown --style-by %q: use mission or pathDirection", styleBy)
+	}
+	skipped, err := res.ExportGeoJSONFile(path, airbus.ExportOptions{
+		StyleBy: styleBy,
+		Titles:  true,
+		Indent:  true,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d features to %s", len(res.Features)-skipped, path)
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, " (%d without geometry skipped)", skipped)
+	}
+	fmt.Fprintln(os.Stderr)
+	return nil
 }
 
 /*──────────────────── root command ────────────────────*/
@@ -61,6 +102,7 @@ func airbusCmd() *cli.Command {
 			abCatCmd(),
 			abBasketCmd(),
 			abOrderCmd(),
+			abDeliveryCmd(),
 			abPingCmd(),
 			abWhoAmICmd(),
 		},
@@ -102,7 +144,7 @@ func abWhoAmICmd() *cli.Command {
 			if err != nil {
 				return err
 			}
-			return prettyJSON(user)
+			return printValue(cmd, user)
 		},
 	}
 }
@@ -112,21 +154,115 @@ func abWhoAmICmd() *cli.Command {
 func abFeasCmd() *cli.Command {
 	return &cli.Command{
 		Name:  "feasibility",
-		Usage: "Create feasibility/tasking request (reads JSON from stdin)",
+		Usage: "Create feasibility/tasking request (reads JSON from stdin, or applies --template)",
+		Flags: append(geojsonFlags(),
+			&cli.StringFlag{Name: "template", Usage: "Apply this saved template instead of reading the request from stdin"},
+			&cli.StringFlag{Name: "aoi", Usage: "GeoJSON file holding the area of interest (with --template)"},
+			&cli.TimestampFlag{Name: "from", Usage: "Start of the acquisition window (RFC 3339, with --template)", Config: cli.TimestampConfig{Layouts: []string{time.RFC3339}}},
+			&cli.TimestampFlag{Name: "to", Usage: "End of the acquisition window (RFC 3339, with --template)", Config: cli.TimestampConfig{Layouts: []string{time.RFC3339}}},
+			templateDirFlag(),
+		),
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			var body airbus.FeasibilityRequest
-			if err := json.NewDecoder(os.Stdin).Decode(&body); err != nil {
-				return fmt.Errorf("failed to parse request JSON: %w", err)
+			body, err := feasibilityRequest(cmd)
+			if err != nil {
+				return err
 			}
 			cli, err := abClient(cmd)
 			if err != nil {
 				return err
 			}
-			res, err := cli.SearchFeasibility(ctx, &body)
+			res, err := cli.SearchFeasibility(ctx, body)
 			if err != nil {
 				return err
 			}
-			return prettyJSON(res)
+			return printOrExportFeatures(cmd, res)
+		},
+		Commands: []*cli.Command{abTemplatesCmd()},
+	}
+}
+
+// feasibilityRequest builds the request from --template, --aoi, --from and
+// --to, or reads it from stdin when no template is given.
+func feasibilityRequest(cmd *cli.Command) (*airbus.FeasibilityRequest, error) {
+	name := cmd.String("template")
+	if name == "" {
+		var body airbus.FeasibilityRequest
+		if err := json.NewDecoder(os.Stdin).Decode(&body); err != nil {
+			return nil, fmt.Errorf("failed to parse request JSON: %w", err)
+		}
+		return &body, nil
+	}
+	if cmd.String("aoi") == "" || !cmd.IsSet("from") {
+		return nil, errors.New("--template requires --aoi and --from")
+	}
+	tpl, err := airbus.LoadTemplate(cmd.String("template-dir"), name)
+	if err != nil {
+		return nil, err
+	}
+	aoi, err := airbus.ReadAOIFile(cmd.String("aoi"))
+	if err != nil {
+		return nil, err
+	}
+	return airbus.ApplyTemplate(tpl, aoi, airbus.TimeRange{From: cmd.Timestamp("from"), To: cmd.Timestamp("to")})
+}
+
+func templateDirFlag() cli.Flag {
+	return &cli.StringFlag{Name: "template-dir", Usage: "Directory of saved feasibility templates (default: gosar/airbus/templates in the user config directory)"}
+}
+
+/*──────────────────── feasibility templates ───────────*/
+
+func abTemplatesCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "templates",
+		Usage: "Saved feasibility request templates",
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List saved templates",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					names, err := airbus.ListTemplates(cmd.String("template-dir"))
+					if err != nil {
+						return err
+					}
+					return printItems(cmd, names, names)
+				},
+			},
+			{
+				Name:      "show",
+				Usage:     "Show a saved template",
+				ArgsUsage: "<name>",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					tpl, err := airbus.LoadTemplate(cmd.String("template-dir"), cmd.Args().First())
+					if err != nil {
+						return err
+					}
+					return printValue(cmd, tpl)
+				},
+			},
+			{
+				Name:      "save",
+				Usage:     "Save the parameters of a feasibility request read from stdin as a template; its aoi and time are not kept",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "description", Usage: "Template description"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					var body airbus.FeasibilityRequest
+					if err := json.NewDecoder(os.Stdin).Decode(&body); err != nil {
+						return fmt.Errorf("failed to parse request JSON: %w", err)
+					}
+					tpl, err := airbus.NewFeasibilityTemplate(cmd.Args().First(), &body)
+					if err != nil {
+						return err
+					}
+					tpl.Description = cmd.String("description")
+					if err := airbus.SaveTemplate(cmd.String("template-dir"), tpl); err != nil {
+						return err
+					}
+					return printValue(cmd, tpl)
+				},
+			},
 		},
 	}
 }
@@ -137,6 +273,7 @@ func abCatCmd() *cli.Command {
 	return &cli.Command{
 		Name:  "catalogue",
 		Usage: "Search archive catalogue (reads JSON from stdin)",
+		Flags: geojsonFlags(),
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			var body airbus.CatalogueRequest
 			if err := json.NewDecoder(os.Stdin).Decode(&body); err != nil {
@@ -146,11 +283,65 @@ func abCatCmd() *cli.Command {
 			if err != nil {
 				return err
 			}
+			p, err := newPrinter(cmd)
+			if err != nil {
+				return err
+			}
+			if p.streaming() && cmd.String("geojson") == "" {
+				// Print features as they are decoded rather than
+				// holding the whole result.
+				_, err := cli.SearchCatalogueStream(ctx, &body, func(f airbus.Feature) error {
+					return p.item(f)
+				})
+				return err
+			}
 			res, err := cli.SearchCatalogue(ctx, &body)
 			if err != nil {
 				return err
 			}
-			return prettyJSON(res)
+			return printOrExportFeatures(cmd, res)
+		},
+		Commands: []*cli.Command{
+			{
+				Name:  "retrieve",
+				Usage: "Order previously acquired items or acquisitions for processing",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{Name: "item", Usage: "Item UUID to retrieve (repeatable)"},
+					&cli.StringSliceFlag{Name: "acquisition", Usage: "Acquisition ID to retrieve, e.g. of an acquisition-only order (repeatable)"},
+					&cli.StringFlag{Name: "product-type", Usage: "Product type (SSC, MGD, GEC, EEC)"},
+					&cli.StringFlag{Name: "resolution", Usage: "Resolution variant (SE, RE)"},
+					&cli.StringFlag{Name: "orbit-type", Usage: "Orbit type (rapid, science, NRT)"},
+					&cli.StringFlag{Name: "map-projection", Usage: "Map projection (auto, UTM, UPS)"},
+					&cli.StringFlag{Name: "template", Usage: "Order template name"},
+					&cli.StringFlag{Name: "customer", Usage: "End customer (resellers only)"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					cli, err := abClient(cmd)
+					if err != nil {
+						return err
+					}
+					req := &airbus.RetrieveRequest{
+						Items:         cmd.StringSlice("item"),
+						Acquisitions:  cmd.StringSlice("acquisition"),
+						OrderTemplate: cmd.String("template"),
+						Customer:      cmd.String("customer"),
+					}
+					opts := airbus.OrderOptions{
+						ProductType:       airbus.ProductType(cmd.String("product-type")),
+						ResolutionVariant: airbus.ResolutionVariant(cmd.String("resolution")),
+						OrbitType:         airbus.OrbitType(cmd.String("orbit-type")),
+						MapProjection:     airbus.MapProjection(cmd.String("map-projection")),
+					}
+					if opts != (airbus.OrderOptions{}) {
+						req.OrderOptions = &opts
+					}
+					res, err := cli.RetrieveItems(ctx, req)
+					if err != nil {
+						return err
+					}
+					return printValue(cmd, res)
+				},
+			},
 		},
 	}
 }
@@ -174,7 +365,7 @@ func abBasketCmd() *cli.Command {
 					if err != nil {
 						return err
 					}
-					return prettyJSON(baskets)
+					return printItems(cmd, baskets, baskets)
 				},
 			},
 			{
@@ -182,21 +373,27 @@ func abBasketCmd() *cli.Command {
 				Usage: "Create a new basket",
 				Flags: []cli.Flag{
 					&cli.StringFlag{Name: "customer-ref", Usage: "Customer reference"},
-					&cli.StringFlag{Name: "purpose", Usage: "Order purpose"},
+					&cli.StringFlag{Name: "purpose", Usage: "Order purpose, e.g. \"Government\""},
+					&cli.StringFlag{Name: "delivery-config", Usage: "Delivery configuration ID (default: Airbus pickup)"},
 				},
 				Action: func(ctx context.Context, cmd *cli.Command) error {
+					purpose, err := parsePurpose(cmd.String("purpose"))
+					if err != nil {
+						return err
+					}
 					cli, err := abClient(cmd)
 					if err != nil {
 						return err
 					}
 					basket, err := cli.CreateBasket(ctx, &airbus.CreateBasketRequest{
 						CustomerReference: cmd.String("customer-ref"),
-						Purpose:           airbus.Purpose(cmd.String("purpose")),
+						Purpose:           purpose,
+						DeliveryConfigID:  cmd.String("delivery-config"),
 					})
 					if err != nil {
 						return err
 					}
-					return prettyJSON(basket)
+					return printValue(cmd, basket)
 				},
 			},
 			{
@@ -216,7 +413,7 @@ func abBasketCmd() *cli.Command {
 					if err != nil {
 						return err
 					}
-					return prettyJSON(basket)
+					return printValue(cmd, basket)
 				},
 			},
 			{
@@ -253,7 +450,7 @@ func abBasketCmd() *cli.Command {
 					if err != nil {
 						return err
 					}
-					return prettyJSON(basket)
+					return printValue(cmd, basket)
 				},
 			},
 			{
@@ -275,7 +472,7 @@ func abBasketCmd() *cli.Command {
 					if err != nil {
 						return err
 					}
-					return prettyJSON(basket)
+					return printValue(cmd, basket)
 				},
 			},
 			{
@@ -283,17 +480,55 @@ func abBasketCmd() *cli.Command {
 				Usage: "Submit basket as order",
 				Flags: []cli.Flag{
 					&cli.StringFlag{Name: "basket-id", Required: true, Usage: "Basket ID"},
+					&cli.FloatFlag{Name: "max-price", Usage: "Refuse to submit if the quoted total exceeds this amount"},
+					&cli.StringFlag{Name: "currency", Value: "EUR", Usage: "Currency of --max-price"},
+					&cli.BoolFlag{Name: "require-final", Usage: "With --max-price, refuse to submit a non-final quote"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					cli, err := abClient(cmd)
+					if err != nil {
+						return err
+					}
+					var order *airbus.Order
+					if cmd.IsSet("max-price") {
+						order, err = cli.SubmitBasketIfUnder(ctx, cmd.String("basket-id"), cmd.Float("max-price"), cmd.String("currency"), cmd.Bool("require-final"))
+					} else {
+						order, err = cli.SubmitBasket(ctx, cmd.String("basket-id"))
+					}
+					if err != nil {
+						return err
+					}
+					return printValue(cmd, order)
+				},
+			},
+			{
+				Name:  "lint",
+				Usage: "Check a basket for conflicts, missing prices and revoked acquisitions",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "basket-id", Required: true, Usage: "Basket ID"},
+					&cli.BoolFlag{Name: "json", Usage: "Print the report as JSON"},
 				},
 				Action: func(ctx context.Context, cmd *cli.Command) error {
 					cli, err := abClient(cmd)
 					if err != nil {
 						return err
 					}
-					order, err := cli.SubmitBasket(ctx, cmd.String("basket-id"))
+					report, err := cli.LintBasket(ctx, cmd.String("basket-id"))
+					if err != nil {
+						return err
+					}
+					if cmd.Bool("json") {
+						err = printValue(cmd, report)
+					} else {
+						_, err = fmt.Println(report)
+					}
 					if err != nil {
 						return err
 					}
-					return prettyJSON(order)
+					if !report.Empty() {
+						return fmt.Errorf("basket %s has problems", report.BasketID)
+					}
+					return nil
 				},
 			},
 			{
@@ -318,6 +553,153 @@ func abBasketCmd() *cli.Command {
 	}
 }
 
+// parsePurpose returns the purpose named s, ignoring case. An unknown
+// purpose is rejected with the closest known one as a suggestion. An empty
+// s leaves the purpose unset.
+func parsePurpose(s string) (airbus.Purpose, error) {
+	if s == "" {
+		return "", nil
+	}
+	var closest airbus.Purpose
+	best := -1
+	for _, p := range airbus.Purposes() {
+		if strings.EqualFold(s, string(p)) {
+			return p, nil
+		}
+		if d := editDistance(strings.ToLower(s), strings.ToLower(string(p))); best < 0 || d < best {
+			closest, best = p, d
+		}
+	}
+	return "", fmt.Errorf("unknown --purpose %q: did you mean %q?", s, closest)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range ra {
+		cur[0] = i + 1
+		for j := range rb {
+			cost := 1
+			if ra[i] == rb[j] {
+				cost = 0
+			}
+			cur[j+1] = min(prev[j+1]+1, cur[j]+1, prev[j]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+/*──────────────────── delivery ────────────────────────*/
+
+func abDeliveryCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "delivery",
+		Usage: "Delivery configuration commands (SFTP, S3)",
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List delivery configurations",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					cli, err := abClient(cmd)
+					if err != nil {
+						return err
+					}
+					configs, err := cli.ListDeliveryConfigs(ctx)
+					if err != nil {
+						return err
+					}
+					return printItems(cmd, configs, configs)
+				},
+			},
+			{
+				Name:      "get",
+				Usage:     "Get a delivery configuration",
+				ArgsUsage: "<deliveryConfigId>",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					id := cmd.Args().First()
+					if id == "" {
+						return fmt.Errorf("deliveryConfigId required")
+					}
+					cli, err := abClient(cmd)
+					if err != nil {
+						return err
+					}
+					cfg, err := cli.GetDeliveryConfig(ctx, id)
+					if err != nil {
+						return err
+					}
+					return printValue(cmd, cfg)
+				},
+			},
+			{
+				Name:  "create",
+				Usage: "Create a delivery configuration (reads JSON from stdin)",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					var body airbus.DeliveryConfig
+					if err := json.NewDecoder(os.Stdin).Decode(&body); err != nil {
+						return fmt.Errorf("failed to parse request JSON: %w", err)
+					}
+					cli, err := abClient(cmd)
+					if err != nil {
+						return err
+					}
+					cfg, err := cli.CreateDeliveryConfig(ctx, &body)
+					if err != nil {
+						return err
+					}
+					return printValue(cmd, cfg)
+				},
+			},
+			{
+				Name:  "update",
+				Usage: "Replace a delivery configuration (reads JSON from stdin)",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "id", Required: true, Usage: "Delivery configuration ID"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					var body airbus.DeliveryConfig
+					if err := json.NewDecoder(os.Stdin).Decode(&body); err != nil {
+						return fmt.Errorf("failed to parse request JSON: %w", err)
+					}
+					cli, err := abClient(cmd)
+					if err != nil {
+						return err
+					}
+					cfg, err := cli.UpdateDeliveryConfig(ctx, cmd.String("id"), &body)
+					if err != nil {
+						return err
+					}
+					return printValue(cmd, cfg)
+				},
+			},
+			{
+				Name:  "delete",
+				Usage: "Delete a delivery configuration",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "id", Required: true, Usage: "Delivery configuration ID"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					cli, err := abClient(cmd)
+					if err != nil {
+						return err
+					}
+					if err := cli.DeleteDeliveryConfig(ctx, cmd.String("id")); err != nil {
+						return err
+					}
+					fmt.Println("Delivery configuration deleted")
+					return nil
+				},
+			},
+		},
+	}
+}
+
 /*──────────────────── order ───────────────────────────*/
 
 func abOrderCmd() *cli.Command {
@@ -327,17 +709,49 @@ func abOrderCmd() *cli.Command {
 		Commands: []*cli.Command{
 			{
 				Name:  "list",
-				Usage: "List all orders",
+				Usage: "List orders, optionally filtered",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "customer", Usage: "Only orders placed for this customer"},
+					&cli.StringFlag{Name: "order-type", Usage: "Only orders of this type (product, feasibility, ...)"},
+					&cli.StringFlag{Name: "item-status", Usage: "Only orders with at least one item in this status"},
+					&cli.TimestampFlag{Name: "submitted-after", Usage: "Only orders submitted at or after (RFC 3339)", Config: cli.TimestampConfig{Layouts: []string{time.RFC3339}}},
+					&cli.TimestampFlag{Name: "submitted-before", Usage: "Only orders submitted before (RFC 3339)", Config: cli.TimestampConfig{Layouts: []string{time.RFC3339}}},
+					&cli.IntFlag{Name: "page-size", Usage: "Orders requested per page (0 = default)"},
+				},
 				Action: func(ctx context.Context, cmd *cli.Command) error {
 					cli, err := abClient(cmd)
 					if err != nil {
 						return err
 					}
-					orders, err := cli.ListOrders(ctx)
+					opts := airbus.ListOrdersOptions{
+						Customer:        cmd.String("customer"),
+						OrderType:       airbus.OrderType(cmd.String("order-type")),
+						ItemStatus:      airbus.ItemStatus(cmd.String("item-status")),
+						SubmittedAfter:  cmd.Timestamp("submitted-after"),
+						SubmittedBefore: cmd.Timestamp("submitted-before"),
+						PageSize:        int(cmd.Int("page-size")),
+					}
+					p, err := newPrinter(cmd)
 					if err != nil {
 						return err
 					}
-					return prettyJSON(orders)
+					orders := []airbus.OrderSummary{}
+					for o, err := range cli.ListOrdersFiltered(ctx, opts) {
+						if err != nil {
+							return err
+						}
+						if p.streaming() {
+							if err := p.item(o); err != nil {
+								return err
+							}
+							continue
+						}
+						orders = append(orders, o)
+					}
+					if p.streaming() {
+						return nil
+					}
+					return p.value(orders)
 				},
 			},
 			{
@@ -357,7 +771,7 @@ func abOrderCmd() *cli.Command {
 					if err != nil {
 						return err
 					}
-					return prettyJSON(order)
+					return printValue(cmd, order)
 				},
 			},
 			{
@@ -377,7 +791,95 @@ func abOrderCmd() *cli.Command {
 					if err != nil {
 						return err
 					}
-					return prettyJSON(result)
+					return printValue(cmd, result)
+				},
+			},
+			{
+				Name:  "submit",
+				Usage: "Submit an order directly, without a basket",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{Name: "acquisition", Usage: "Acquisition ID to price and order (repeatable)"},
+					&cli.StringSliceFlag{Name: "item", Usage: "Item UUID to order (repeatable)"},
+					&cli.StringFlag{Name: "basket-id", Usage: "Existing basket to submit"},
+					&cli.StringFlag{Name: "purpose", Usage: "Order purpose"},
+					&cli.StringFlag{Name: "product-type", Usage: "Product type (SSC, MGD, GEC, EEC)"},
+					&cli.StringFlag{Name: "resolution", Usage: "Resolution variant (SE, RE)"},
+					&cli.StringFlag{Name: "orbit-type", Usage: "Orbit type (rapid, science, NRT)"},
+					&cli.StringFlag{Name: "map-projection", Usage: "Map projection (auto, UTM, UPS)"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					cli, err := abClient(cmd)
+					if err != nil {
+						return err
+					}
+					var opts *airbus.OrderOptions
+					o := airbus.OrderOptions{
+						ProductType:       airbus.ProductType(cmd.String("product-type")),
+						ResolutionVariant: airbus.ResolutionVariant(cmd.String("resolution")),
+						OrbitType:         airbus.OrbitType(cmd.String("orbit-type")),
+						MapProjection:     airbus.MapProjection(cmd.String("map-projection")),
+					}
+					if o != (airbus.OrderOptions{}) {
+						opts = &o
+					}
+					purpose := airbus.Purpose(cmd.String("purpose"))
+
+					var order *airbus.Order
+					if acqs := cmd.StringSlice("acquisition"); len(acqs) > 0 {
+						if len(cmd.StringSlice("item")) > 0 || cmd.String("basket-id") != "" {
+							return fmt.Errorf("--acquisition cannot be combined with --item or --basket-id")
+						}
+						order, err = cli.OrderAcquisitions(ctx, acqs, opts, purpose)
+					} else {
+						order, err = cli.SubmitOrder(ctx, &airbus.SubmitOrderRequest{
+							BasketID:     cmd.String("basket-id"),
+							Items:        cmd.StringSlice("item"),
+							Purpose:      purpose,
+							OrderOptions: opts,
+						})
+					}
+					if err != nil {
+						return err
+					}
+					return printValue(cmd, order)
+				},
+			},
+			{
+				Name:  "reorder",
+				Usage: "Reorder delivered items with different order options",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{Name: "item", Required: true, Usage: "Item UUID to reorder (repeatable)"},
+					&cli.StringFlag{Name: "product-type", Usage: "Product type (SSC, MGD, GEC, EEC)"},
+					&cli.StringFlag{Name: "resolution", Usage: "Resolution variant (SE, RE)"},
+					&cli.StringFlag{Name: "orbit-type", Usage: "Orbit type (rapid, science, NRT)"},
+					&cli.StringFlag{Name: "map-projection", Usage: "Map projection (auto, UTM, UPS)"},
+					&cli.StringFlag{Name: "template", Usage: "Order template name"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					cli, err := abClient(cmd)
+					if err != nil {
+						return err
+					}
+					req := &airbus.ReorderRequest{
+						Items:         cmd.StringSlice("item"),
+						OrderTemplate: cmd.String("template"),
+					}
+					opts := airbus.OrderOptions{
+						ProductType:       airbus.ProductType(cmd.String("product-type")),
+						ResolutionVariant: airbus.ResolutionVariant(cmd.String("resolution")),
+						OrbitType:         airbus.OrbitType(cmd.String("orbit-type")),
+						MapProjection:     airbus.MapProjection(cmd.String("map-projection")),
+					}
+					if opts != (airbus.OrderOptions{}) {
+						req.OrderOptions = &opts
+					}
+					order, err := cli.ReorderItems(ctx, req)
+					if order != nil {
+						if perr := printValue(cmd, order); perr != nil {
+							return perr
+						}
+					}
+					return err
 				},
 			},
 		},
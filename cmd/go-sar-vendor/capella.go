@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
 	"github.com/urfave/cli/v3"
@@ -37,6 +38,7 @@ func capellaCmd() *cli.Command {
 		Commands: []*cli.Command{
 			accessCmd(),
 			tasksCmd(),
+			usageCmd(),
 		},
 	}
 	return root
@@ -90,7 +92,7 @@ func accessCreateAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return printJSON(resp)
+	return printValue(cmd, resp)
 }
 
 func accessGetAction(ctx context.Context, cmd *cli.Command) error {
@@ -106,7 +108,7 @@ func accessGetAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return printJSON(resp)
+	return printValue(cmd, resp)
 }
 
 // -----------------------------------------------------------------------------
@@ -167,7 +169,7 @@ func tasksCreateAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return printJSON(resp)
+	return printValue(cmd, resp)
 }
 
 func tasksGetAction(ctx context.Context, cmd *cli.Command) error {
@@ -183,7 +185,7 @@ func tasksGetAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return printJSON(resp)
+	return printValue(cmd, resp)
 }
 
 func tasksApproveAction(ctx context.Context, cmd *cli.Command) error {
@@ -199,7 +201,7 @@ func tasksApproveAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return printJSON(resp)
+	return printValue(cmd, resp)
 }
 
 func tasksListAction(ctx context.Context, cmd *cli.Command) error {
@@ -212,11 +214,15 @@ func tasksListAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
 	for t, err := range cli.ListTasks(ctx, params) {
 		if err != nil {
 			return err
 		}
-		if err := printJSON(t); err != nil {
+		if err := p.value(t); err != nil {
 			return err
 		}
 	}
@@ -238,5 +244,78 @@ func tasksSearchAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return printJSON(resp)
+	return printValue(cmd, resp)
+}
+
+// -----------------------------------------------------------------------------
+// Usage sub-tree ---------------------------------------------------------------
+// -----------------------------------------------------------------------------
+
+func usageCmd() *cli.Command {
+	rangeFlags := []cli.Flag{
+		&cli.TimestampFlag{Name: "from", Required: true, Usage: "Start of range (RFC 3339)", Config: cli.TimestampConfig{Layouts: []string{time.RFC3339}}},
+		&cli.TimestampFlag{Name: "to", Required: true, Usage: "End of range (RFC 3339)", Config: cli.TimestampConfig{Layouts: []string{time.RFC3339}}},
+	}
+	return &cli.Command{
+		Name:  "usage",
+		Usage: "Organization usage reporting (task counts, area, spend)",
+		Commands: []*cli.Command{
+			{
+				Name:   "summary",
+				Usage:  "Print aggregated usage for a time range",
+				Flags:  rangeFlags,
+				Action: usageSummaryAction,
+			},
+			{
+				Name:  "records",
+				Usage: "Stream per-task usage line items as newline-delimited JSON or CSV",
+				Flags: append(rangeFlags,
+					&cli.StringFlag{Name: "organization-id"},
+					&cli.BoolFlag{Name: "csv", Usage: "Write CSV instead of JSON"},
+				),
+				Action: usageRecordsAction,
+			},
+		},
+	}
+}
+
+func usageSummaryAction(ctx context.Context, cmd *cli.Command) error {
+	cli, err := capellaClientFromCmd(cmd)
+	if err != nil {
+		return err
+	}
+	resp, err := cli.GetOrgUsage(ctx, cmd.Timestamp("from"), cmd.Timestamp("to"))
+	if err != nil {
+		return err
+	}
+	return printValue(cmd, resp)
+}
+
+func usageRecordsAction(ctx context.Context, cmd *cli.Command) error {
+	params := capella.ListUsageParams{
+		From:           cmd.Timestamp("from"),
+		To:             cmd.Timestamp("to"),
+		OrganizationID: cmd.String("organization-id"),
+	}
+	cli, err := capellaClientFromCmd(cmd)
+	if err != nil {
+		return err
+	}
+	records := cli.ListUsageRecords(ctx, params)
+	if cmd.Bool("csv") {
+		return capella.WriteUsageCSV(os.Stdout, records)
+	}
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	for rec, err := range records {
+		if err != nil {
+			return err
+		}
+		if err := p.value(rec); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -40,12 +40,6 @@ func iceyeClient(cmd *cli.Command) (*iceye.Client, error) {
 	)
 }
 
-func iceyePrint(v any) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(v)
-}
-
 /* ---------- contracts ---------- */
 
 func iceyeContractsCmd() *cli.Command {
@@ -58,6 +52,10 @@ func iceyeContractsCmd() *cli.Command {
 			if err != nil {
 				return err
 			}
+			p, err := newPrinter(cmd)
+			if err != nil {
+				return err
+			}
 			limit := int(cmd.Int("limit"))
 			seq := cli.ListContracts(ctx, limit)
 			for page, err := range seq {
@@ -65,7 +63,7 @@ func iceyeContractsCmd() *cli.Command {
 					return err
 				}
 				for _, c := range page.Data {
-					if err := iceyePrint(c); err != nil {
+					if err := p.value(c); err != nil {
 						return err
 					}
 				}
@@ -141,7 +139,7 @@ func iceyeCreateTask(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return iceyePrint(resp)
+	return printValue(cmd, resp)
 }
 
 func iceyeGetTask(ctx context.Context, cmd *cli.Command) error {
@@ -157,7 +155,7 @@ func iceyeGetTask(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return iceyePrint(resp)
+	return printValue(cmd, resp)
 }
 
 func iceyeCancelTask(ctx context.Context, cmd *cli.Command) error {
@@ -173,7 +171,7 @@ func iceyeCancelTask(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return iceyePrint(resp)
+	return printValue(cmd, resp)
 }
 
 func iceyeListTasks(ctx context.Context, cmd *cli.Command) error {
@@ -181,6 +179,10 @@ func iceyeListTasks(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
 	limit := int(cmd.Int("limit"))
 	seq := cli.ListTasks(ctx, limit, nil)
 	for page, err := range seq {
@@ -188,7 +190,7 @@ func iceyeListTasks(ctx context.Context, cmd *cli.Command) error {
 			return err
 		}
 		for _, t := range page {
-			if err := iceyePrint(t); err != nil {
+			if err := p.value(t); err != nil {
 				return err
 			}
 		}
@@ -209,7 +211,7 @@ func iceyeGetScene(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return iceyePrint(resp)
+	return printValue(cmd, resp)
 }
 
 func iceyeGetProducts(ctx context.Context, cmd *cli.Command) error {
@@ -221,12 +223,16 @@ func iceyeGetProducts(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
 	products, err := cli.ListTaskProducts(ctx, id)
 	if err != nil {
 		return err
 	}
-	for _, p := range products {
-		if err := iceyePrint(p); err != nil {
+	for _, prod := range products {
+		if err := p.value(prod); err != nil {
 			return err
 		}
 	}
@@ -246,5 +252,5 @@ func iceyeGetPrice(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return iceyePrint(resp)
+	return printValue(cmd, resp)
 }
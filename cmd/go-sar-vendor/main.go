@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"os"
 
@@ -13,10 +15,18 @@ import (
 // top-level command ------------------------------------------------------------
 // -----------------------------------------------------------------------------
 func main() {
-	root := &cli.Command{
+	if err := rootCmd().Run(context.Background(), os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func rootCmd() *cli.Command {
+	return &cli.Command{
 		Name:  "gosar",
 		Usage: "Command-line helper for Capella Space Tasking & Access API",
 
+		Flags: []cli.Flag{outputFlag()},
+
 		// sub-commands (property renamed Subcommands → Commands)
 		Commands: []*cli.Command{
 			umbraCmd(),
@@ -25,14 +35,105 @@ func main() {
 			airbusCmd(),
 		},
 	}
+}
 
-	if err := root.Run(context.Background(), os.Args); err != nil {
-		log.Fatal(err)
+// -----------------------------------------------------------------------------
+// output formats ---------------------------------------------------------------
+// -----------------------------------------------------------------------------
+
+// Formats selected with the global --output flag.
+const (
+	outputJSON   = "json"   // one indented JSON document
+	outputNDJSON = "ndjson" // list results as one compact JSON object per line
+	outputRaw    = "raw"    // one compact JSON document
+)
+
+func outputFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "output",
+		Value: outputJSON,
+		Usage: "Output format: json, ndjson (one list element per line, for jq -c or ogr2ogr) or raw (compact JSON)",
+	}
+}
+
+// printer writes command results in the --output format. Commands use it
+// through printValue and printItems, or newPrinter when they print a stream
+// of results one at a time.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func newPrinter(cmd *cli.Command) (*printer, error) {
+	format := cmd.String("output")
+	switch format {
+	case "":
+		format = outputJSON
+	case outputJSON, outputNDJSON, outputRaw:
+	default:
+		return nil, fmt.Errorf("unknown --output %q: use json, ndjson or raw", format)
+	}
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
 	}
+	return &printer{w: w, format: format}, nil
 }
 
-func printJSON(v any) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(v)
+// streaming reports whether list results are printed element by element.
+func (p *printer) streaming() bool { return p.format == outputNDJSON }
+
+// value prints a single result. In NDJSON mode it takes one line.
+func (p *printer) value(v any) error {
+	enc := json.NewEncoder(p.w)
+	if p.format == outputJSON {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return p.flush()
+}
+
+// item prints one element of a list result as a line of its own and flushes
+// it, so consumers downstream see it at once.
+func (p *printer) item(v any) error {
+	if err := json.NewEncoder(p.w).Encode(v); err != nil {
+		return err
+	}
+	return p.flush()
+}
+
+func (p *printer) flush() error {
+	if f, ok := p.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// printValue prints a single result in the --output format.
+func printValue(cmd *cli.Command, v any) error {
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	return p.value(v)
+}
+
+// printItems prints a list result: in NDJSON mode one element of items per
+// line, otherwise whole, which holds items, as a single document.
+func printItems[T any](cmd *cli.Command, whole any, items []T) error {
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	if !p.streaming() {
+		return p.value(whole)
+	}
+	for _, it := range items {
+		if err := p.item(it); err != nil {
+			return err
+		}
+	}
+	return nil
 }
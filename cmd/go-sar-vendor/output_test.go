@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// airbusServer serves a token and count features for both feature
+// searches, plus two baskets.
+func airbusServer(t *testing.T, count int) *httptest.Server {
+	t.Helper()
+	var features []string
+	for i := range count {
+		features = append(features, fmt.Sprintf(
+			`{"type":"Feature","geometry":{"type":"Point","coordinates":[%d,45]},"properties":{"acquisitionId":"ACQ-%d"}}`, i, i))
+	}
+	collection := `{"type":"FeatureCollection","total":` + fmt.Sprint(count) + `,"features":[` + strings.Join(features, ",") + `]}`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /auth/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"test-token","token_type":"Bearer","expires_in":3600}`))
+	})
+	for _, path := range []string{"POST /sar/catalogue", "POST /sar/feasibility"} {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(collection))
+		})
+	}
+	mux.HandleFunc("GET /sar/baskets", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"basketId":"B-1"},{"basketId":"B-2"}]`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// run runs gosar with args against srv, feeding stdin to the command, and
+// returns what it printed.
+func run(t *testing.T, srv *httptest.Server, stdin string, args ...string) (string, error) {
+	t.Helper()
+	in, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.WriteString(stdin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdin
+	os.Stdin = in
+	t.Cleanup(func() { os.Stdin = orig; in.Close() })
+
+	var out bytes.Buffer
+	root := rootCmd()
+	root.Writer = &out
+	argv := []string{"gosar"}
+	for i, a := range args {
+		argv = append(argv, a)
+		if a == "airbus" {
+			argv = append(argv, "--api-key", "k", "--token-url", srv.URL+"/auth/token", "--base-url", srv.URL)
+			argv = append(argv, args[i+1:]...)
+			break
+		}
+		if a == "umbra" {
+			argv = append(argv, "--api-key", "k", "--vendor-base-url", srv.URL)
+			argv = append(argv, args[i+1:]...)
+			break
+		}
+	}
+	err = root.Run(context.Background(), argv)
+	return out.String(), err
+}
+
+// ndjsonLines checks that every line of out is a JSON object and returns
+// them decoded.
+func ndjsonLines(t *testing.T, out string) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		var obj map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &obj); err != nil {
+			t.Fatalf("line %d is not a JSON object: %v\n%s", len(lines)+1, err, sc.Text())
+		}
+		lines = append(lines, obj)
+	}
+	return lines
+}
+
+func TestAirbusNDJSONFeatures(t *testing.T) {
+	const count = 5
+	srv := airbusServer(t, count)
+
+	for _, command := range []string{"catalogue", "feasibility"} {
+		t.Run(command, func(t *testing.T) {
+			out, err := run(t, srv, `{}`, "--output", "ndjson", "airbus", command)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := ndjsonLines(t, out)
+			if len(lines) != count {
+				t.Fatalf("got %d lines, want one per feature (%d)", len(lines), count)
+			}
+			for i, obj := range lines {
+				props, _ := obj["properties"].(map[string]any)
+				if obj["type"] != "Feature" || props["acquisitionId"] != fmt.Sprintf("ACQ-%d", i) {
+					t.Errorf("line %d = %v, want feature ACQ-%d", i, obj, i)
+				}
+			}
+		})
+	}
+}
+
+func TestAirbusNDJSONBaskets(t *testing.T) {
+	srv := airbusServer(t, 0)
+	out, err := run(t, srv, "", "--output", "ndjson", "airbus", "basket", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := ndjsonLines(t, out)
+	if len(lines) != 2 || lines[0]["basketId"] != "B-1" || lines[1]["basketId"] != "B-2" {
+		t.Errorf("lines = %v, want one per basket", lines)
+	}
+}
+
+func TestAirbusOutputFormats(t *testing.T) {
+	srv := airbusServer(t, 3)
+
+	out, err := run(t, srv, `{}`, "airbus", "catalogue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fc struct {
+		Features []json.RawMessage `json:"features"`
+	}
+	if err := json.Unmarshal([]byte(out), &fc); err != nil || len(fc.Features) != 3 {
+		t.Fatalf("default output is not the whole collection: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "\n  ") {
+		t.Error("default output is not indented")
+	}
+
+	out, err = run(t, srv, `{}`, "--output", "raw", "airbus", "catalogue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out, "\n") != 1 || !strings.HasPrefix(out, `{"type":"FeatureCollection"`) {
+		t.Errorf("raw output is not one compact document:\n%s", out)
+	}
+
+	if _, err := run(t, srv, `{}`, "--output", "yaml", "airbus", "catalogue"); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestAirbusFeasibilityTemplate(t *testing.T) {
+	var got map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /auth/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"test-token","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("POST /sar/feasibility", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"type":"FeatureCollection","features":[]}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	aoi := dir + "/aoi.geojson"
+	if err := os.WriteFile(aoi, []byte(`{"type":"Feature","properties":{},"geometry":{"type":"Point","coordinates":[9.9,53.5]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	req := `{"aoi":{"type":"Point","coordinates":[0,0]},"time":{"from":"2025-01-01T00:00:00Z"},
+		"feasibilityLevel":"complete","sensorMode":"SAR_SM_S","priority":"priority"}`
+	if _, err := run(t, srv, req, "airbus", "feasibility", "--template-dir", dir, "templates", "save", "coastal"); err != nil {
+		t.Fatalf("templates save: %v", err)
+	}
+	out, err := run(t, srv, "", "airbus", "feasibility", "--template-dir", dir, "templates", "list")
+	if err != nil || strings.TrimSpace(out) != `[
+  "coastal"
+]` {
+		t.Fatalf("templates list = %q, %v", out, err)
+	}
+
+	_, err = run(t, srv, "", "airbus", "feasibility", "--template-dir", dir,
+		"--template", "coastal", "--aoi", aoi, "--from", "2025-03-01T00:00:00Z", "--to", "2025-03-08T00:00:00Z")
+	if err != nil {
+		t.Fatalf("feasibility --template: %v", err)
+	}
+	if got["sensorMode"] != "SAR_SM_S" || got["priority"] != "priority" {
+		t.Errorf("request = %v, want the template parameters", got)
+	}
+	if pt, _ := got["aoi"].(map[string]any); pt["type"] != "Point" || fmt.Sprint(pt["coordinates"]) != "[9.9 53.5]" {
+		t.Errorf("aoi = %v, want the point from the AOI file", got["aoi"])
+	}
+	if tr, _ := got["time"].(map[string]any); tr["from"] != "2025-03-01T00:00:00Z" || tr["to"] != "2025-03-08T00:00:00Z" {
+		t.Errorf("time = %v, want the --from/--to window", got["time"])
+	}
+
+	if _, err := run(t, srv, "", "airbus", "feasibility", "--template-dir", dir, "--template", "coastal"); err == nil {
+		t.Error("--template without --aoi succeeded")
+	}
+}
+
+func TestAirbusBasketCreatePurpose(t *testing.T) {
+	var got map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /auth/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"test-token","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("POST /sar/baskets", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"basketId":"B-3"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	if _, err := run(t, srv, "", "airbus", "basket", "create", "--purpose", "government"); err != nil {
+		t.Fatalf("basket create: %v", err)
+	}
+	if got["purpose"] != "Government" {
+		t.Errorf("purpose = %v, want Government", got["purpose"])
+	}
+
+	got = nil
+	_, err := run(t, srv, "", "airbus", "basket", "create", "--purpose", "Goverment")
+	if err == nil || !strings.Contains(err.Error(), `did you mean "Government"?`) {
+		t.Errorf("err = %v, want a suggestion of Government", err)
+	}
+	if got != nil {
+		t.Errorf("basket created with an unknown purpose: %v", got)
+	}
+}
+
+// umbraServer serves a task and a task search with two results.
+func umbraServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasking/tasks/T-1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"T-1","status":"ACTIVE"}`))
+	})
+	mux.HandleFunc("POST /tasking/tasks/search", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id":"T-1","status":"ACTIVE"},{"id":"T-2","status":"SCHEDULED"}]`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestUmbraOutputFormats(t *testing.T) {
+	srv := umbraServer(t)
+
+	out, err := run(t, srv, "", "umbra", "task", "get", "T-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "\n  ") {
+		t.Errorf("default output is not indented:\n%s", out)
+	}
+
+	out, err = run(t, srv, "", "--output", "raw", "umbra", "task", "get", "T-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out, "\n") != 1 || !strings.HasPrefix(out, `{"id":"T-1"`) {
+		t.Errorf("raw output is not one compact document:\n%s", out)
+	}
+
+	out, err = run(t, srv, `{}`, "--output", "ndjson", "umbra", "task", "search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := ndjsonLines(t, out)
+	if len(lines) != 2 || lines[0]["id"] != "T-1" || lines[1]["id"] != "T-2" {
+		t.Errorf("lines = %v, want one per task", lines)
+	}
+
+	if _, err := run(t, srv, "", "--output", "yaml", "umbra", "task", "get", "T-1"); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
@@ -144,7 +144,7 @@ func umbraCreateFeasAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return printJSON(resp)
+	return printValue(cmd, resp)
 }
 
 func umbraGetFeasAction(ctx context.Context, cmd *cli.Command) error {
@@ -161,7 +161,7 @@ func umbraGetFeasAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return printJSON(resp)
+	return printValue(cmd, resp)
 }
 
 /*──────────────── task actions ──────────────────────────────────────────────*/
@@ -180,7 +180,7 @@ func umbraCreateTaskAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return printJSON(resp)
+	return printValue(cmd, resp)
 }
 
 func umbraGetTaskAction(ctx context.Context, cmd *cli.Command) error {
@@ -197,7 +197,7 @@ func umbraGetTaskAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return printJSON(resp)
+	return printValue(cmd, resp)
 }
 
 func umbraCancelTaskAction(ctx context.Context, cmd *cli.Command) error {
@@ -214,7 +214,7 @@ func umbraCancelTaskAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return printJSON(resp)
+	return printValue(cmd, resp)
 }
 
 func umbraSearchTaskAction(ctx context.Context, cmd *cli.Command) error {
@@ -227,12 +227,16 @@ func umbraSearchTaskAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
 	seq := cli.SearchTasks(ctx, req)
 	for task, err := range seq {
 		if err != nil {
 			return err
 		}
-		if err := printJSON(task); err != nil {
+		if err := p.value(task); err != nil {
 			return err
 		}
 	}
@@ -255,7 +259,7 @@ func umbraGetCollectAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return printJSON(resp)
+	return printValue(cmd, resp)
 }
 
 func umbraSearchCollectAction(ctx context.Context, cmd *cli.Command) error {
@@ -268,12 +272,16 @@ func umbraSearchCollectAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
 	seq := cli.SearchCollects(ctx, req)
 	for col, err := range seq {
 		if err != nil {
 			return err
 		}
-		if err := printJSON(col); err != nil {
+		if err := p.value(col); err != nil {
 			return err
 		}
 	}
@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,14 +19,29 @@ import (
 // APIKeyAuth implements common.Authenticator for Airbus API key authentication.
 // It exchanges an API key for a Bearer token via the token endpoint using
 // the api_key grant type.
+//
+// Concurrent Apply calls share a single in-flight token request, and the
+// token is refreshed once it is within the refresh margin of its expiry.
 type APIKeyAuth struct {
 	apiKey     string
 	tokenURL   string
 	httpClient *http.Client
 
-	mu    sync.Mutex
-	token string
-	exp   time.Time
+	mu            sync.Mutex
+	token         string
+	exp           time.Time
+	refreshMargin time.Duration
+	inflight      *tokenFetch
+}
+
+// tokenFetchTimeout bounds a shared token request, which no caller's context
+// can cancel.
+const tokenFetchTimeout = 30 * time.Second
+
+// tokenFetch tracks an in-flight token request shared by concurrent callers.
+type tokenFetch struct {
+	done chan struct{}
+	err  error
 }
 
 // NewAPIKeyAuth creates an authenticator that exchanges an API key for a bearer token.
@@ -34,33 +51,88 @@ func NewAPIKeyAuth(apiKey, tokenURL string, httpClient *http.Client) *APIKeyAuth
 		httpClient = &http.Client{Timeout: 10 * time.Second}
 	}
 	return &APIKeyAuth{
-		apiKey:     apiKey,
-		tokenURL:   tokenURL,
-		httpClient: httpClient,
+		apiKey:        apiKey,
+		tokenURL:      tokenURL,
+		httpClient:    httpClient,
+		refreshMargin: common.TokenExpiryBuffer,
 	}
 }
 
+// SetRefreshMargin sets how long before expiry the token is proactively
+// refreshed. It defaults to common.TokenExpiryBuffer.
+func (a *APIKeyAuth) SetRefreshMargin(d time.Duration) {
+	a.mu.Lock()
+	a.refreshMargin = d
+	a.mu.Unlock()
+}
+
+// Invalidate discards the cached token so the next Apply fetches a new one.
+// Use it when the server has revoked the token before its local expiry.
+func (a *APIKeyAuth) Invalidate() {
+	a.mu.Lock()
+	a.token = ""
+	a.exp = time.Time{}
+	a.mu.Unlock()
+}
+
 // Apply applies authentication to the HTTP request.
 // It implements the common.Authenticator interface.
 func (a *APIKeyAuth) Apply(ctx context.Context, req *http.Request) error {
-	if err := a.refreshIfNeeded(ctx); err != nil {
+	token, err := a.validToken(ctx)
+	if err != nil {
 		return err
 	}
-	a.mu.Lock()
-	req.Header.Set("Authorization", "Bearer "+a.token)
-	a.mu.Unlock()
+	req.Header.Set("Authorization", "Bearer "+token)
 	return nil
 }
 
-// refreshIfNeeded obtains or refreshes the bearer token if needed.
-func (a *APIKeyAuth) refreshIfNeeded(ctx context.Context) error {
+// validToken returns the cached token, refreshing it if it is missing or
+// within the refresh margin of expiry. Only one refresh runs at a time;
+// concurrent callers wait for its result. The refresh is not tied to the
+// cancellation of the caller that started it, so callers whose contexts are
+// still live get its result; each caller stops waiting when its own ctx is
+// done.
+func (a *APIKeyAuth) validToken(ctx context.Context) (string, error) {
 	a.mu.Lock()
-	if time.Until(a.exp) > common.TokenExpiryBuffer {
+	if a.token != "" && time.Until(a.exp) > a.refreshMargin {
+		token := a.token
 		a.mu.Unlock()
-		return nil
+		return token, nil
+	}
+
+	f := a.inflight
+	if f == nil {
+		f = &tokenFetch{done: make(chan struct{})}
+		a.inflight = f
+		go a.refresh(context.WithoutCancel(ctx), f)
 	}
 	a.mu.Unlock()
 
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	if f.err != nil {
+		return "", f.err
+	}
+	return a.Token(), nil
+}
+
+// refresh runs the shared token request f, bounded by tokenFetchTimeout.
+func (a *APIKeyAuth) refresh(ctx context.Context, f *tokenFetch) {
+	ctx, cancel := context.WithTimeout(ctx, tokenFetchTimeout)
+	defer cancel()
+	f.err = a.fetchToken(ctx)
+
+	a.mu.Lock()
+	a.inflight = nil
+	a.mu.Unlock()
+	close(f.done)
+}
+
+// fetchToken requests a new bearer token and stores it.
+func (a *APIKeyAuth) fetchToken(ctx context.Context) error {
 	// Request new token
 	form := url.Values{
 		"apikey":     {a.apiKey},
@@ -128,3 +200,81 @@ func (a *APIKeyAuth) Expiry() time.Time {
 	defer a.mu.Unlock()
 	return a.exp
 }
+
+// invalidate discards the cached token only if it is still the given one, so
+// concurrent requests rejected with the same token trigger a single refresh.
+func (a *APIKeyAuth) invalidate(token string) {
+	a.mu.Lock()
+	if a.token == token {
+		a.token = ""
+		a.exp = time.Time{}
+	}
+	a.mu.Unlock()
+}
+
+// maxErrorBodySniff bounds how much of a 401 body is read to classify it.
+const maxErrorBodySniff = 64 << 10
+
+// retryTransport retries a request once with a fresh token when the API
+// rejects the current token as invalid or expired (e.g. after a password
+// rotation revokes it early). Other 401s are returned unchanged.
+type retryTransport struct {
+	base http.RoundTripper
+	auth *APIKeyAuth
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	// The body can only be replayed if the request knows how to rebuild it.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+	if !isInvalidTokenResponse(resp) {
+		return resp, nil
+	}
+
+	t.auth.invalidate(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	if err := t.auth.Apply(req.Context(), retry); err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("authenticate: %w", err)
+	}
+
+	resp.Body.Close()
+	return t.base.RoundTrip(retry)
+}
+
+// isInvalidTokenResponse reports whether a 401 response indicates a rejected
+// bearer token rather than a permission error. The body is restored so the
+// caller can still parse it.
+func isInvalidTokenResponse(resp *http.Response) bool {
+	if strings.Contains(resp.Header.Get("WWW-Authenticate"), "invalid_token") {
+		return true
+	}
+
+	buf, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySniff))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), resp.Body), resp.Body}
+
+	body := strings.ToLower(string(buf))
+	for _, marker := range []string{"invalid_token", "token expired", "expired token", "token is not active"} {
+		if strings.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
+}
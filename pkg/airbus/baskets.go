@@ -2,7 +2,11 @@ package airbus
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
@@ -15,9 +19,20 @@ func (c *Client) ListBaskets(ctx context.Context) ([]Basket, error) {
 	return out, err
 }
 
-// CreateBasket creates a new basket.
+// CreateBasket creates a new basket. A purpose or customer the request
+// omits is taken from WithDefaultPurpose or WithDefaultCustomer.
 // POST /sar/baskets
 func (c *Client) CreateBasket(ctx context.Context, req *CreateBasketRequest) (*Basket, error) {
+	if req != nil && (req.Purpose == "" || req.Customer == "") {
+		withDefaults := *req
+		if withDefaults.Purpose == "" {
+			withDefaults.Purpose = c.defaultPurpose
+		}
+		if withDefaults.Customer == "" {
+			withDefaults.Customer = c.defaultCustomer
+		}
+		req = &withDefaults
+	}
 	body, err := common.MarshalBody(req)
 	if err != nil {
 		return nil, err
@@ -68,8 +83,19 @@ func (c *Client) DeleteBasket(ctx context.Context, basketID string) error {
 
 // AddItemsToBasket adds acquisitions or items to a basket.
 // You can add either by acquisition ID (from catalogue) or by item UUID (from feasibility).
+// With WithOrderOptionDefaults, OrderOptions are resolved against the account
+// defaults before sending.
 // POST /sar/baskets/{basketId}/addItems
 func (c *Client) AddItemsToBasket(ctx context.Context, basketID string, req *AddItemsRequest) (*Basket, error) {
+	if c.resolveOrderOptions && req != nil && req.OrderOptions != nil {
+		opts, err := c.ResolveOrderOptions(ctx, req.OrderOptions)
+		if err != nil {
+			return nil, err
+		}
+		resolved := *req
+		resolved.OrderOptions = opts
+		req = &resolved
+	}
 	body, err := common.MarshalBody(req)
 	if err != nil {
 		return nil, err
@@ -106,9 +132,232 @@ func (c *Client) RearrangeBasketItems(ctx context.Context, basketID string, req
 
 // SubmitBasket submits a basket as an order.
 // The basket must have a purpose set and contain at least one item.
+// Unless the client was created WithoutComplianceCheck, the basket is
+// fetched first and a *ComplianceError is returned, without submitting,
+// when it has no purpose or, for a reseller account, no customer.
 // POST /sar/baskets/{basketId}/submit
 func (c *Client) SubmitBasket(ctx context.Context, basketID string) (*Order, error) {
+	if c.complianceCheck {
+		if err := c.checkCompliance(ctx, basketID); err != nil {
+			return nil, err
+		}
+	}
 	var out Order
 	err := c.DoRaw(ctx, http.MethodPost, c.BaseURL().JoinPath("sar", "baskets", basketID, "submit"), nil, http.StatusOK, &out)
 	return &out, err
 }
+
+// checkCompliance returns a *ComplianceError if the basket lacks a purpose,
+// or a customer when the account is a reseller. Permissions are only
+// fetched for a basket without a customer.
+func (c *Client) checkCompliance(ctx context.Context, basketID string) error {
+	basket, err := c.GetBasket(ctx, basketID)
+	if err != nil {
+		return fmt.Errorf("get basket: %w", err)
+	}
+	var missing []string
+	if basket.Purpose == "" {
+		missing = append(missing, "purpose")
+	}
+	if basket.Customer == "" {
+		perms, err := c.permissions(ctx)
+		if err != nil {
+			return err
+		}
+		if perms.IsReseller {
+			missing = append(missing, "customer")
+		}
+	}
+	if len(missing) > 0 {
+		return &ComplianceError{BasketID: basketID, Missing: missing}
+	}
+	return nil
+}
+
+// GetBasketPrice prices every item in the basket afresh and returns the
+// total. The total is final only when every item has a final price; items the
+// API returns no price for leave it non-final. Items quoted in different
+// currencies cannot be totalled and return an error.
+func (c *Client) GetBasketPrice(ctx context.Context, basketID string) (*Price, error) {
+	basket, err := c.GetBasket(ctx, basketID)
+	if err != nil {
+		return nil, fmt.Errorf("get basket: %w", err)
+	}
+	if len(basket.Items) == 0 {
+		return nil, fmt.Errorf("basket %s has no items", basketID)
+	}
+
+	ids := make([]string, len(basket.Items))
+	for i, it := range basket.Items {
+		ids[i] = it.ItemID
+	}
+	prices, err := c.GetPrices(ctx, &PricesRequest{Items: ids, Customer: basket.Customer, OrderTemplate: basket.OrderTemplate})
+	if err != nil {
+		return nil, fmt.Errorf("get prices: %w", err)
+	}
+
+	total := &Price{Final: len(prices) >= len(ids)}
+	priced := make(map[string]bool, len(prices))
+	for _, p := range prices {
+		if total.Currency == "" {
+			total.Currency = p.Price.Currency
+		} else if !strings.EqualFold(total.Currency, p.Price.Currency) {
+			return nil, fmt.Errorf("basket %s mixes currencies %s and %s", basketID, total.Currency, p.Price.Currency)
+		}
+		total.Total += p.Price.Total
+		total.Final = total.Final && p.Price.Final
+		priced[p.ItemID] = true
+	}
+	for _, id := range ids {
+		if !priced[id] {
+			total.Final = false
+		}
+	}
+	return total, nil
+}
+
+// SubmitBasketIfUnder submits the basket only if its freshly quoted total is
+// at most max in the given currency. A quote in another currency, or a
+// non-final quote when requireFinal is set, is refused as well. Refusals
+// return a *PriceGuardError carrying the quote; nothing is submitted.
+func (c *Client) SubmitBasketIfUnder(ctx context.Context, basketID string, max float64, currency string, requireFinal bool) (*Order, error) {
+	if currency == "" {
+		return nil, errors.New("price guard requires a currency")
+	}
+	price, err := c.GetBasketPrice(ctx, basketID)
+	if err != nil {
+		return nil, err
+	}
+
+	guard := &PriceGuardError{BasketID: basketID, Price: *price, Max: max, Currency: currency}
+	switch {
+	case !strings.EqualFold(price.Currency, currency):
+		guard.Reason = PriceGuardCurrencyMismatch
+	case price.Total > max:
+		guard.Reason = PriceGuardOverLimit
+	case requireFinal && !price.Final:
+		guard.Reason = PriceGuardNotFinal
+	default:
+		return c.SubmitBasket(ctx, basketID)
+	}
+	return nil, guard
+}
+
+// maxRevocationPages bounds how many revocation pages LintBasket walks.
+const maxRevocationPages = 100
+
+// LintBasket checks a basket for problems that would make submission fail:
+// conflicting items, items without a price and items whose acquisitions have
+// been revoked from the catalogue. Problems are collected into the report
+// rather than returned as errors; an error means a check could not be run.
+func (c *Client) LintBasket(ctx context.Context, basketID string) (*BasketLintReport, error) {
+	basket, err := c.GetBasket(ctx, basketID)
+	if err != nil {
+		return nil, fmt.Errorf("get basket: %w", err)
+	}
+	report := &BasketLintReport{BasketID: basketID}
+	if len(basket.Items) == 0 {
+		return report, nil
+	}
+
+	ids := make([]string, len(basket.Items))
+	for i, it := range basket.Items {
+		ids[i] = it.ItemID
+	}
+
+	conflicts, err := c.CheckConflicts(ctx, &ConflictsRequest{Items: ids})
+	if err != nil {
+		return nil, fmt.Errorf("check conflicts: %w", err)
+	}
+	report.Conflicts = conflicts.Conflicts
+
+	prices, err := c.GetPrices(ctx, &PricesRequest{Items: ids, Customer: basket.Customer, OrderTemplate: basket.OrderTemplate})
+	if err != nil {
+		return nil, fmt.Errorf("get prices: %w", err)
+	}
+	priced := make(map[string]bool, len(prices))
+	for _, p := range prices {
+		priced[p.ItemID] = true
+	}
+	for _, id := range ids {
+		if !priced[id] {
+			report.MissingPrices = append(report.MissingPrices, id)
+		}
+	}
+
+	revoked, err := c.revokedSince(ctx, earliestStart(basket.Items))
+	if err != nil {
+		return nil, fmt.Errorf("get revocations: %w", err)
+	}
+	for _, it := range basket.Items {
+		if reason, ok := revoked[it.AcquisitionID]; ok {
+			report.Revoked = append(report.Revoked, RevokedItem{ItemID: it.ItemID, AcquisitionID: it.AcquisitionID, Reason: reason})
+		}
+	}
+	return report, nil
+}
+
+// revokedSince returns revoked acquisition IDs mapped to their revocation
+// reason, walking all revocation pages issued since the given time.
+func (c *Client) revokedSince(ctx context.Context, since time.Time) (map[string]string, error) {
+	revoked := make(map[string]string)
+	opts := &RevocationOptions{Since: since}
+	for range maxRevocationPages {
+		resp, err := c.GetCatalogueRevocations(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range resp.Revocations {
+			revoked[id] = ""
+		}
+		for _, r := range resp.Data {
+			revoked[r.AcquisitionID] = r.Reason
+		}
+		next := resp.NextToken()
+		if next == "" || next == opts.Token {
+			return revoked, nil
+		}
+		opts.Token = next
+	}
+	return revoked, nil
+}
+
+// earliestStart returns the earliest acquisition start among items, or the
+// zero time if none is known. Revocations cannot predate an acquisition.
+func earliestStart(items []Item) time.Time {
+	var t time.Time
+	for _, it := range items {
+		if it.StartTime != nil && (t.IsZero() || it.StartTime.Before(t)) {
+			t = *it.StartTime
+		}
+	}
+	return t
+}
+
+// Empty reports whether the lint found no problems.
+func (r *BasketLintReport) Empty() bool {
+	return len(r.Conflicts) == 0 && len(r.MissingPrices) == 0 && len(r.Revoked) == 0
+}
+
+// String returns a human-readable summary of the report.
+func (r *BasketLintReport) String() string {
+	if r.Empty() {
+		return fmt.Sprintf("basket %s: no problems found", r.BasketID)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "basket %s: %d conflict(s), %d missing price(s), %d revoked item(s)",
+		r.BasketID, len(r.Conflicts), len(r.MissingPrices), len(r.Revoked))
+	for _, c := range r.Conflicts {
+		fmt.Fprintf(&b, "\n  conflict: %s <-> %s: %s", c.ItemID1, c.ItemID2, c.Reason)
+	}
+	for _, id := range r.MissingPrices {
+		fmt.Fprintf(&b, "\n  missing price: %s", id)
+	}
+	for _, it := range r.Revoked {
+		fmt.Fprintf(&b, "\n  revoked: %s (acquisition %s)", it.ItemID, it.AcquisitionID)
+		if it.Reason != "" {
+			fmt.Fprintf(&b, ": %s", it.Reason)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,173 @@
+package airbus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of the client's circuit breaker.
+type CircuitState string
+
+const (
+	// CircuitClosed lets requests through. It is also reported by clients
+	// without a circuit breaker.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen fails requests without sending them until the cooldown
+	// has passed.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe through to test the API.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitOpenError is returned instead of sending a request while the
+// circuit breaker is open.
+type CircuitOpenError struct {
+	// RetryIn is the time until the next probe is let through; zero while a
+	// probe is in flight.
+	RetryIn time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryIn <= 0 {
+		return "circuit breaker open: probe in progress"
+	}
+	return fmt.Sprintf("circuit breaker open: next probe in %s", e.RetryIn.Round(time.Millisecond))
+}
+
+// circuitBreaker trips after threshold consecutive server errors or
+// connection failures, and lets one probe through per cooldown.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	// probe, if set, tests the API when the circuit is half-open instead of
+	// letting the first request through.
+	probe func(context.Context) error
+	now   func() time.Time
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     CircuitClosed,
+	}
+}
+
+// State returns the current state. An open circuit whose cooldown has passed
+// is reported as half-open, since the next request will probe.
+func (b *circuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && !b.now().Before(b.openedAt.Add(b.cooldown)) {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a request may be sent, and whether it is the
+// half-open probe whose outcome decides the circuit state.
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if wait := b.openedAt.Add(b.cooldown).Sub(b.now()); wait > 0 {
+			return false, &CircuitOpenError{RetryIn: wait}
+		}
+		b.state = CircuitHalfOpen
+		return true, nil
+	case CircuitHalfOpen:
+		return false, &CircuitOpenError{}
+	}
+	return false, nil
+}
+
+// record updates the breaker with a request outcome. Requests that were
+// cancelled by their caller leave it unchanged, except that an abandoned
+// probe reopens the circuit so the next request probes again.
+func (b *circuitBreaker) record(probe, failed, cancelled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case cancelled:
+		if probe {
+			b.state = CircuitOpen
+		}
+	case failed:
+		b.failures++
+		if probe || (b.state == CircuitClosed && b.failures >= b.threshold) {
+			b.state = CircuitOpen
+			b.openedAt = b.now()
+			b.failures = 0
+		}
+	case probe || b.state == CircuitClosed:
+		b.state = CircuitClosed
+		b.failures = 0
+	}
+}
+
+// probeKey marks the context of a health probe, which bypasses the breaker.
+type probeKey struct{}
+
+// breakerTransport fails requests fast while the circuit is open.
+type breakerTransport struct {
+	base    http.RoundTripper
+	breaker *circuitBreaker
+}
+
+// RoundTrip implements http.RoundTripper. Responses with a 5xx status and
+// transport errors count as failures; 4xx responses do not.
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if ctx.Value(probeKey{}) != nil {
+		return t.base.RoundTrip(req)
+	}
+
+	probe, err := t.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	if probe && t.breaker.probe != nil {
+		perr := t.breaker.probe(context.WithValue(ctx, probeKey{}, true))
+		t.breaker.record(true, perr != nil, perr != nil && ctx.Err() != nil)
+		if perr != nil {
+			return nil, &CircuitOpenError{RetryIn: t.breaker.cooldown}
+		}
+		probe = false
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+	t.breaker.record(probe, failed, err != nil && ctx.Err() != nil)
+	return resp, err
+}
+
+// healthProbe reports an error unless the API health check passes.
+func (c *Client) healthProbe(ctx context.Context) error {
+	h, err := c.Health(ctx)
+	if err != nil {
+		return err
+	}
+	if h.Status == "fail" {
+		return fmt.Errorf("health check failed: %s", h.Description)
+	}
+	return nil
+}
+
+// CircuitState returns the state of the client's circuit breaker, e.g. for
+// metrics. Clients without WithCircuitBreaker always report CircuitClosed.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.State()
+}
@@ -0,0 +1,157 @@
+package airbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CacheEndpoint identifies a read-mostly endpoint whose responses the client
+// can cache. See WithConfigCache and WithEndpointCache.
+type CacheEndpoint string
+
+const (
+	CacheConfig            CacheEndpoint = "config"                   // GetConfig
+	CacheWhoAmI            CacheEndpoint = "whoami"                   // WhoAmI
+	CachePermissions       CacheEndpoint = "config/permissions"       // GetPermissions
+	CacheSettings          CacheEndpoint = "config/settings"          // GetSettings
+	CacheCustomers         CacheEndpoint = "config/customers"         // GetCustomers
+	CacheOrderTemplates    CacheEndpoint = "config/orderTemplates"    // GetOrderTemplates
+	CacheReceivingStations CacheEndpoint = "config/receivingStations" // GetReceivingStations
+)
+
+// responseCache caches decoded responses per endpoint. Concurrent misses for
+// the same endpoint share one request, and values are deep-copied on the way
+// out so callers cannot modify the cached entry.
+type responseCache struct {
+	ttls map[CacheEndpoint]time.Duration // set at construction, read-only
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[CacheEndpoint]*cacheEntry
+}
+
+// cacheEntry is a cached response, or a request in flight until ready is
+// closed.
+type cacheEntry struct {
+	ready   chan struct{}
+	value   any
+	err     error
+	expires time.Time // zero: never
+}
+
+func newResponseCache(ttls map[CacheEndpoint]time.Duration) *responseCache {
+	return &responseCache{
+		ttls:    ttls,
+		now:     time.Now,
+		entries: make(map[CacheEndpoint]*cacheEntry),
+	}
+}
+
+// ttl returns the configured TTL of an endpoint; zero means not cached.
+func (rc *responseCache) ttl(endpoint CacheEndpoint) time.Duration {
+	return rc.ttls[endpoint]
+}
+
+// invalidate drops all entries. Requests in flight complete for their
+// callers but are not cached.
+func (rc *responseCache) invalidate() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries = make(map[CacheEndpoint]*cacheEntry)
+}
+
+// cachedCall returns fetch's result through the cache if endpoint has a TTL
+// configured, and calls fetch directly otherwise.
+func cachedCall[T any](ctx context.Context, c *Client, endpoint CacheEndpoint, fetch func(context.Context) (T, error)) (T, error) {
+	ttl := c.cache.ttl(endpoint)
+	if ttl <= 0 {
+		return fetch(ctx)
+	}
+	return getCached(ctx, c.cache, endpoint, ttl, fetch)
+}
+
+// getCached returns a copy of the cached value for endpoint, fetching it if
+// it is missing or expired. A ttl below zero caches for the lifetime of the
+// client (or until invalidated). Errors are not cached.
+func getCached[T any](ctx context.Context, rc *responseCache, endpoint CacheEndpoint, ttl time.Duration, fetch func(context.Context) (T, error)) (T, error) {
+	var zero T
+	for {
+		rc.mu.Lock()
+		e := rc.entries[endpoint]
+		if e != nil {
+			select {
+			case <-e.ready:
+				if e.err == nil && (e.expires.IsZero() || rc.now().Before(e.expires)) {
+					rc.mu.Unlock()
+					return deepCopy(e.value.(T))
+				}
+			default:
+				// Another caller is fetching; wait for its result.
+				rc.mu.Unlock()
+				select {
+				case <-e.ready:
+				case <-ctx.Done():
+					return zero, ctx.Err()
+				}
+				if e.err != nil {
+					// The fetching caller gave up; try again ourselves.
+					if isContextErr(e.err) && ctx.Err() == nil {
+						continue
+					}
+					return zero, e.err
+				}
+				return deepCopy(e.value.(T))
+			}
+		}
+
+		e = &cacheEntry{ready: make(chan struct{})}
+		rc.entries[endpoint] = e
+		rc.mu.Unlock()
+
+		v, err := fetch(ctx)
+
+		rc.mu.Lock()
+		e.value, e.err = v, err
+		if ttl > 0 {
+			e.expires = rc.now().Add(ttl)
+		}
+		if err != nil && rc.entries[endpoint] == e {
+			delete(rc.entries, endpoint)
+		}
+		rc.mu.Unlock()
+		close(e.ready)
+
+		if err != nil {
+			return zero, err
+		}
+		return deepCopy(v)
+	}
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// deepCopy copies v through its JSON encoding, which every cached response
+// type round-trips.
+func deepCopy[T any](v T) (T, error) {
+	var out T
+	data, err := json.Marshal(v)
+	if err != nil {
+		return out, err
+	}
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+// InvalidateCache drops all cached responses (see WithConfigCache) and
+// prices (see WithPriceCache), e.g. after changing account settings. The
+// account configuration used by ResolveOrderOptions and receiving station
+// validation is refetched too.
+func (c *Client) InvalidateCache() {
+	c.cache.invalidate()
+	c.prices.clear()
+}
@@ -2,8 +2,13 @@ package airbus
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"slices"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
@@ -20,6 +25,120 @@ func (c *Client) SearchCatalogue(ctx context.Context, req *CatalogueRequest) (*F
 	return &out, err
 }
 
+// CatalogueSummary holds the FeatureCollection metadata encountered while
+// streaming a catalogue response.
+type CatalogueSummary struct {
+	Type  string `json:"type"`
+	Limit int    `json:"limit,omitempty"`
+	Total int    `json:"total,omitempty"`
+	Count int    `json:"count"` // Number of features passed to the callback
+}
+
+// SearchCatalogueStream searches the archive like SearchCatalogue but decodes
+// the response incrementally, invoking fn once per feature instead of
+// materializing the whole FeatureCollection. This keeps memory usage roughly
+// constant for very large result sets.
+//
+// Streaming stops early, and the response body is closed, if fn returns an
+// error or ctx is cancelled; that error is returned alongside the summary of
+// what was read so far.
+// POST /sar/catalogue
+func (c *Client) SearchCatalogueStream(ctx context.Context, req *CatalogueRequest, fn func(Feature) error) (*CatalogueSummary, error) {
+	body, err := common.MarshalBody(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := c.NewRequest(ctx, http.MethodPost, "/sar/catalogue", body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTPClient().Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseError(resp)
+	}
+	return decodeFeatureStream(ctx, resp.Body, fn)
+}
+
+// decodeFeatureStream walks a FeatureCollection token by token, decoding one
+// feature at a time and recording the collection metadata in the summary.
+func decodeFeatureStream(ctx context.Context, r io.Reader, fn func(Feature) error) (*CatalogueSummary, error) {
+	dec := json.NewDecoder(r)
+	summary := &CatalogueSummary{}
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return summary, err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return summary, fmt.Errorf("decode response: %w", err)
+		}
+		key, _ := tok.(string)
+
+		switch key {
+		case "type":
+			err = dec.Decode(&summary.Type)
+		case "limit":
+			err = dec.Decode(&summary.Limit)
+		case "total":
+			err = dec.Decode(&summary.Total)
+		case "features":
+			err = decodeFeatures(ctx, dec, summary, fn)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+func decodeFeatures(ctx context.Context, dec *json.Decoder, summary *CatalogueSummary, fn func(Feature) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if tok == nil {
+		return nil // "features": null
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("decode response: expected features array, got %v", tok)
+	}
+
+	for dec.More() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var f Feature
+		if err := dec.Decode(&f); err != nil {
+			return fmt.Errorf("decode feature %d: %w", summary.Count, err)
+		}
+		summary.Count++
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("decode response: expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
 // ReplicateCatalogue retrieves catalogue updates for replication.
 // This endpoint is for bulk catalogue synchronization.
 // GET /sar/catalogue/replication
@@ -28,7 +147,7 @@ func (c *Client) ReplicateCatalogue(ctx context.Context, opts *ReplicationOption
 	if opts != nil {
 		q := u.Query()
 		if !opts.Since.IsZero() {
-			q.Set("since", opts.Since.Format("2006-01-02T15:04:05Z"))
+			q.Set("since", opts.Since.UTC().Format(timeFormat))
 		}
 		if opts.Limit > 0 {
 			q.Set("limit", fmt.Sprintf("%d", opts.Limit))
@@ -48,11 +167,14 @@ func (c *Client) GetCatalogueRevocations(ctx context.Context, opts *RevocationOp
 	if opts != nil {
 		q := u.Query()
 		if !opts.Since.IsZero() {
-			q.Set("since", opts.Since.Format("2006-01-02T15:04:05Z"))
+			q.Set("since", opts.Since.UTC().Format(timeFormat))
 		}
 		if opts.Limit > 0 {
 			q.Set("limit", fmt.Sprintf("%d", opts.Limit))
 		}
+		if opts.Token != "" {
+			q.Set("token", opts.Token)
+		}
 		u.RawQuery = q.Encode()
 	}
 	var out RevocationResponse
@@ -60,6 +182,28 @@ func (c *Client) GetCatalogueRevocations(ctx context.Context, opts *RevocationOp
 	return &out, err
 }
 
+// AcquisitionIDs returns the IDs of all revoked acquisitions in the response.
+func (r *RevocationResponse) AcquisitionIDs() []string {
+	ids := slices.Clone(r.Revocations)
+	for _, d := range r.Data {
+		ids = append(ids, d.AcquisitionID)
+	}
+	return ids
+}
+
+// NextToken returns the continuation token of the next page, or "" if this
+// is the last page.
+func (r *RevocationResponse) NextToken() string {
+	if r.Links.Next == "" {
+		return ""
+	}
+	u, err := url.Parse(r.Links.Next)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("token")
+}
+
 // RetrieveCatalogueItems retrieves ordered items from the catalogue.
 // This returns the updated state of previously ordered items.
 // POST /sar/catalogue/retrieve
@@ -72,3 +216,42 @@ func (c *Client) RetrieveCatalogueItems(ctx context.Context, req *RetrieveReques
 	err = c.DoRaw(ctx, http.MethodPost, c.BaseURL().JoinPath("sar", "catalogue", "retrieve"), body, http.StatusOK, &out)
 	return &out, err
 }
+
+// Validate checks that exactly one of Acquisitions or Items is set and that
+// the order options are valid.
+func (r *RetrieveRequest) Validate() error {
+	if r == nil {
+		return errors.New("retrieve request is nil")
+	}
+	switch {
+	case len(r.Acquisitions) > 0 && len(r.Items) > 0:
+		return errors.New("only one of acquisitions or items may be set")
+	case len(r.Acquisitions) == 0 && len(r.Items) == 0:
+		return errors.New("at least one acquisition or item is required")
+	}
+	return r.OrderOptions.Validate()
+}
+
+// RetrieveItems orders previously acquired data from the catalogue, e.g. to
+// process the acquisitions of a completed acquisition-only order, and returns
+// the retrieved catalogue items. Unlike RetrieveCatalogueItems it validates
+// the request and checks the account permissions from the cached
+// configuration first: retrieving acquisitions requires
+// canOrderAcquisitionOnly, retrieving items requires canOrder. A missing
+// permission fails with a *PermissionError without calling the endpoint.
+// POST /sar/catalogue/retrieve
+func (c *Client) RetrieveItems(ctx context.Context, req *RetrieveRequest) (*FeatureCollection, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	var err error
+	if len(req.Acquisitions) > 0 {
+		err = c.requirePermission(ctx, "canOrderAcquisitionOnly", func(p *Permissions) bool { return p.CanOrderAcquisitionOnly })
+	} else {
+		err = c.requirePermission(ctx, "canOrder", func(p *Permissions) bool { return p.CanOrder })
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.RetrieveCatalogueItems(ctx, req)
+}
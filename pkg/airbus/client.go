@@ -1,7 +1,9 @@
 package airbus
 
 import (
+	"crypto/tls"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
@@ -29,6 +31,16 @@ const (
 // Client is the SAR-API client. It embeds common.Client for HTTP operations.
 type Client struct {
 	*common.Client
+	auth *APIKeyAuth
+
+	resolveOrderOptions bool
+	complianceCheck     bool
+	defaultPurpose      Purpose
+	defaultCustomer     string
+	cache               *responseCache
+	prices              *priceCache
+	breaker             *circuitBreaker
+	rateLimit           *rateLimitTransport
 }
 
 // Option configures a Client.
@@ -40,6 +52,26 @@ type clientConfig struct {
 	httpClient *http.Client
 	timeout    time.Duration
 	userAgent  string
+	proxy      *url.URL
+	tlsConfig  *tls.Config
+
+	refreshMargin time.Duration
+
+	resolveOrderOptions bool
+
+	skipComplianceCheck bool
+	defaultPurpose      Purpose
+	defaultCustomer     string
+
+	breakerThreshold   int
+	breakerCooldown    time.Duration
+	breakerHealthProbe bool
+
+	rateLimitRetries int
+	rateLimitMaxWait time.Duration
+
+	cacheTTLs map[CacheEndpoint]time.Duration
+	priceTTL  time.Duration
 }
 
 // WithHTTPClient sets a custom HTTP client.
@@ -71,39 +103,220 @@ func WithTimeout(timeout time.Duration) Option {
 }
 
 // WithUserAgent sets a custom User-Agent header.
+// Defaults to "go-sar-vendor/<version> (+airbus)".
 func WithUserAgent(userAgent string) Option {
 	return func(c *clientConfig) {
 		c.userAgent = userAgent
 	}
 }
 
+// WithProxy routes API and token requests through the given proxy, composing
+// with the default transport (or the transport of a client set via WithHTTPClient).
+func WithProxy(proxy *url.URL) Option {
+	return func(c *clientConfig) {
+		c.proxy = proxy
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for outgoing connections,
+// e.g. to trust a custom CA. It composes with the default transport.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *clientConfig) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// WithTokenRefreshMargin sets how long before expiry the bearer token is
+// proactively refreshed. Defaults to common.TokenExpiryBuffer.
+func WithTokenRefreshMargin(d time.Duration) Option {
+	return func(c *clientConfig) {
+		c.refreshMargin = d
+	}
+}
+
+// WithOrderOptionDefaults makes AddItemsToBasket fill unset fields of the
+// request's OrderOptions from the account's default order options and
+// validate the result (see ResolveOrderOptions). Requests without
+// OrderOptions are sent unchanged.
+func WithOrderOptionDefaults() Option {
+	return func(c *clientConfig) {
+		c.resolveOrderOptions = true
+	}
+}
+
+// WithoutComplianceCheck stops SubmitBasket from checking that the basket
+// has the fields required for compliance before submitting it, leaving the
+// check to the API.
+func WithoutComplianceCheck() Option {
+	return func(c *clientConfig) {
+		c.skipComplianceCheck = true
+	}
+}
+
+// WithDefaultPurpose sets the purpose CreateBasket uses for baskets created
+// without one.
+func WithDefaultPurpose(p Purpose) Option {
+	return func(c *clientConfig) {
+		c.defaultPurpose = p
+	}
+}
+
+// WithDefaultCustomer sets the end customer CreateBasket uses for baskets
+// created without one, e.g. for a reseller account ordering for a single
+// customer.
+func WithDefaultCustomer(customer string) Option {
+	return func(c *clientConfig) {
+		c.defaultCustomer = customer
+	}
+}
+
+// WithConfigCache caches GetConfig and WhoAmI responses in memory for ttl.
+// Concurrent calls on a cache miss share a single request, and each caller
+// gets its own copy of the response. Use InvalidateCache to drop cached
+// responses early.
+func WithConfigCache(ttl time.Duration) Option {
+	return func(c *clientConfig) {
+		c.setCacheTTL(CacheConfig, ttl)
+		c.setCacheTTL(CacheWhoAmI, ttl)
+	}
+}
+
+// WithEndpointCache caches responses of another read-mostly endpoint, e.g.
+// CacheOrderTemplates, in the same way as WithConfigCache, or overrides the
+// TTL it sets for one endpoint. A ttl of zero disables caching the endpoint.
+func WithEndpointCache(endpoint CacheEndpoint, ttl time.Duration) Option {
+	return func(c *clientConfig) {
+		c.setCacheTTL(endpoint, ttl)
+	}
+}
+
+// WithPriceCache caches the prices GetPrices obtains for ttl, per
+// acquisition or item, order template and customer, so only acquisitions and
+// items without a cached price are queried. Prices that are not final are
+// cached for at most a minute. InvalidateCache drops cached prices.
+func WithPriceCache(ttl time.Duration) Option {
+	return func(c *clientConfig) {
+		c.priceTTL = ttl
+	}
+}
+
+func (c *clientConfig) setCacheTTL(endpoint CacheEndpoint, ttl time.Duration) {
+	if c.cacheTTLs == nil {
+		c.cacheTTLs = make(map[CacheEndpoint]time.Duration)
+	}
+	c.cacheTTLs[endpoint] = ttl
+}
+
+// WithCircuitBreaker makes the client fail fast with a *CircuitOpenError
+// after threshold consecutive server errors or connection failures, instead
+// of sending requests to an API that is down, e.g. for maintenance. Once
+// cooldown has passed, one request is let through as a probe: if it
+// succeeds the circuit closes, otherwise it stays open for another cooldown.
+// Client errors (4xx) do not count as failures.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *clientConfig) {
+		c.breakerThreshold = threshold
+		c.breakerCooldown = cooldown
+	}
+}
+
+// WithCircuitBreakerHealthProbe makes the circuit breaker probe with Health
+// rather than with the first request after the cooldown. The request is sent
+// only if the health check passes. It has no effect without
+// WithCircuitBreaker.
+func WithCircuitBreakerHealthProbe() Option {
+	return func(c *clientConfig) {
+		c.breakerHealthProbe = true
+	}
+}
+
+// WithRateLimitRetry retries a 429 response up to maxRetries times, each
+// after waiting until the quota resets as reported by the X-RateLimit-Reset
+// or Retry-After header. A 429 whose reset is unknown or more than maxWait
+// away is returned as an *APIError carrying the RateLimit.
+func WithRateLimitRetry(maxRetries int, maxWait time.Duration) Option {
+	return func(c *clientConfig) {
+		c.rateLimitRetries = maxRetries
+		c.rateLimitMaxWait = maxWait
+	}
+}
+
 // NewClient creates a new SAR-API client with the given API key.
 // By default, it connects to the production OneAtlas environment.
 func NewClient(apiKey string, opts ...Option) (*Client, error) {
 	cfg := &clientConfig{
-		baseURL:  DefaultBaseURL,
-		tokenURL: DefaultTokenURL,
-		timeout:  defaultTimeout,
+		baseURL:   DefaultBaseURL,
+		tokenURL:  DefaultTokenURL,
+		timeout:   defaultTimeout,
+		userAgent: common.DefaultUserAgent("airbus"),
 	}
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
-	httpClient := common.EnsureHTTPClient(cfg.httpClient, cfg.timeout)
+	httpClient, err := common.ConfigureTransport(
+		common.EnsureHTTPClient(cfg.httpClient, cfg.timeout), cfg.proxy, cfg.tlsConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	auth := NewAPIKeyAuth(apiKey, cfg.tokenURL, httpClient)
+	if cfg.refreshMargin > 0 {
+		auth.SetRefreshMargin(cfg.refreshMargin)
+	}
+
+	// API calls retry once on a revoked token; token requests use the plain client.
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	apiClient := *httpClient
+	apiClient.Transport = &retryTransport{base: base, auth: auth}
+
+	// Rate limits are read outside the token retry, from the final response.
+	rateLimit := newRateLimitTransport(apiClient.Transport, cfg.rateLimitRetries, cfg.rateLimitMaxWait)
+	apiClient.Transport = rateLimit
+
+	// The breaker sits outside the retry, so a retried call counts once.
+	var breaker *circuitBreaker
+	if cfg.breakerThreshold > 0 {
+		breaker = newCircuitBreaker(cfg.breakerThreshold, cfg.breakerCooldown)
+		apiClient.Transport = &breakerTransport{base: apiClient.Transport, breaker: breaker}
+	}
 
 	c, err := common.NewClient(common.ClientConfig{
-		BaseURL:    cfg.baseURL,
-		HTTPClient: httpClient,
-		Auth:       auth,
-		UserAgent:  cfg.userAgent,
+		BaseURL:     cfg.baseURL,
+		HTTPClient:  &apiClient,
+		Auth:        auth,
+		UserAgent:   cfg.userAgent,
+		ErrorParser: parseError,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{Client: c}, nil
+	cli := &Client{
+		Client:              c,
+		auth:                auth,
+		resolveOrderOptions: cfg.resolveOrderOptions,
+		complianceCheck:     !cfg.skipComplianceCheck,
+		defaultPurpose:      cfg.defaultPurpose,
+		defaultCustomer:     cfg.defaultCustomer,
+		cache:               newResponseCache(cfg.cacheTTLs),
+		prices:              newPriceCache(cfg.priceTTL),
+		breaker:             breaker,
+		rateLimit:           rateLimit,
+	}
+	if breaker != nil && cfg.breakerHealthProbe {
+		breaker.probe = cli.healthProbe
+	}
+	return cli, nil
+}
+
+// Auth returns the client's token authenticator, e.g. to force a refresh
+// with Invalidate.
+func (c *Client) Auth() *APIKeyAuth {
+	return c.auth
 }
 
 // NewDevClient creates a client configured for the development environment.
@@ -130,4 +343,3 @@ func NewLegacyDevClient(apiKey string, opts ...Option) (*Client, error) {
 	}
 	return NewClient(apiKey, append(legacyOpts, opts...)...)
 }
-
@@ -1,12 +1,32 @@
 package airbus
 
 import (
+	"cmp"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unicode/utf8"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
 )
 
 // testServer creates a test server with a token endpoint and API endpoints.
@@ -68,6 +88,37 @@ func TestNewDevClient(t *testing.T) {
 	}
 }
 
+func TestNewClient_UserAgentAndTLSConfig(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "test-token", "expires_in": 3600})
+	})
+	mux.HandleFunc("/sar/ping", func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != "acme/1.0" {
+			t.Errorf("expected User-Agent acme/1.0, got %q", ua)
+		}
+	})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	// Both the token fetch and the API call must trust the custom CA.
+	client, err := NewClient("test-api-key",
+		WithBaseURL(server.URL),
+		WithTokenURL(server.URL+"/auth/token"),
+		WithUserAgent("acme/1.0"),
+		WithTLSConfig(&tls.Config{RootCAs: pool}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+}
+
 func TestPing(t *testing.T) {
 	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/sar/ping" {
@@ -185,6 +236,256 @@ func TestSearchCatalogue(t *testing.T) {
 	}
 }
 
+func TestSearchCatalogueStream(t *testing.T) {
+	const n = 10000
+
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sar/catalogue" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		// Write the collection incrementally so neither side holds it in memory.
+		io.WriteString(w, `{"type":"FeatureCollection","features":[`)
+		enc := json.NewEncoder(w)
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				io.WriteString(w, ",")
+			}
+			enc.Encode(Feature{
+				Type: "Feature",
+				Properties: AcquisitionProperties{
+					ItemID:        fmt.Sprintf("item-%d", i),
+					AcquisitionID: strings.Repeat("x", 256),
+					Mission:       MissionTSX,
+				},
+			})
+		}
+		io.WriteString(w, `],"limit":20000,"total":42000,"extra":{"ignored":true}}`)
+	})
+	defer server.Close()
+
+	var baseline, peak uint64
+	var ms runtime.MemStats
+	summary, err := client.SearchCatalogueStream(context.Background(), &CatalogueRequest{}, func(f Feature) error {
+		switch {
+		case f.Properties.ItemID == "item-1000":
+			runtime.GC()
+			runtime.ReadMemStats(&ms)
+			baseline = ms.HeapAlloc
+		case f.Properties.ItemID == "item-9999":
+			runtime.GC()
+			runtime.ReadMemStats(&ms)
+			peak = ms.HeapAlloc
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SearchCatalogueStream() error = %v", err)
+	}
+	if summary.Count != n {
+		t.Errorf("expected %d features, got %d", n, summary.Count)
+	}
+	if summary.Total != 42000 || summary.Limit != 20000 || summary.Type != "FeatureCollection" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	// ~3 MB of features were streamed between the two samples; retained heap
+	// should not grow with them.
+	const ceiling = 1 << 20
+	if peak > baseline && peak-baseline > ceiling {
+		t.Errorf("heap grew by %d bytes while streaming, want < %d", peak-baseline, ceiling)
+	}
+}
+
+func TestSearchCatalogueStream_StopEarly(t *testing.T) {
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"type":"FeatureCollection","total":3,"features":[`+
+			`{"type":"Feature","properties":{"itemId":"a"}},`+
+			`{"type":"Feature","properties":{"itemId":"b"}},`+
+			`{"type":"Feature","properties":{"itemId":"c"}}]}`)
+	})
+	defer server.Close()
+
+	errStop := errors.New("stop")
+	var seen []string
+	summary, err := client.SearchCatalogueStream(context.Background(), &CatalogueRequest{}, func(f Feature) error {
+		seen = append(seen, f.Properties.ItemID)
+		if len(seen) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("expected errStop, got %v", err)
+	}
+	if len(seen) != 2 || summary.Count != 2 {
+		t.Errorf("expected to stop after 2 features, saw %v (count %d)", seen, summary.Count)
+	}
+	if summary.Total != 3 {
+		t.Errorf("expected total 3 from metadata before features, got %d", summary.Total)
+	}
+}
+
+func TestSearchCatalogueStream_ContextCancelled(t *testing.T) {
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"features":[{"type":"Feature"},{"type":"Feature"},{"type":"Feature"}]}`)
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	_, err := client.SearchCatalogueStream(ctx, &CatalogueRequest{}, func(Feature) error {
+		calls++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 callback before cancellation, got %d", calls)
+	}
+}
+
+func TestSearchCatalogueStream_APIError(t *testing.T) {
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"bad aoi"}`, http.StatusBadRequest)
+	})
+	defer server.Close()
+
+	_, err := client.SearchCatalogueStream(context.Background(), &CatalogueRequest{}, func(Feature) error {
+		t.Fatal("callback should not be invoked")
+		return nil
+	})
+	if !IsBadRequest(err) {
+		t.Fatalf("expected bad request error, got %v", err)
+	}
+}
+
+// retrieveServer serves a config with the given permissions and counts the
+// retrieve calls; status other than 200 fails them with a problem document.
+func retrieveServer(t *testing.T, perms Permissions, status int, retrieves *atomic.Int32) (*httptest.Server, *Client) {
+	t.Helper()
+	return testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sar/config":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(Config{Permissions: &perms})
+		case "/sar/catalogue/retrieve":
+			retrieves.Add(1)
+			if status != http.StatusOK {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(status)
+				w.Write([]byte(`{"title":"Forbidden","detail":"acquisition is not owned by the account"}`))
+				return
+			}
+			var req RetrieveRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if len(req.Acquisitions) != 1 || req.OrderOptions == nil || req.OrderOptions.ProductType != ProductTypeSSC {
+				t.Errorf("unexpected retrieve request: %+v", req)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(FeatureCollection{Features: []Feature{
+				{Properties: AcquisitionProperties{ItemID: "item-1", AcquisitionID: req.Acquisitions[0]}},
+			}})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+}
+
+var acquisitionRetrieve = &RetrieveRequest{
+	Acquisitions: []string{"TSX-1_ST_S_spot_049R_49677_D31767159_432"},
+	OrderOptions: &OrderOptions{ProductType: ProductTypeSSC, OrbitType: OrbitTypeRapid},
+}
+
+func TestRetrieveItems(t *testing.T) {
+	var retrieves atomic.Int32
+	server, client := retrieveServer(t, Permissions{CanOrderAcquisitionOnly: true}, http.StatusOK, &retrieves)
+	defer server.Close()
+
+	fc, err := client.RetrieveItems(context.Background(), acquisitionRetrieve)
+	if err != nil {
+		t.Fatalf("RetrieveItems() error = %v", err)
+	}
+	if len(fc.Features) != 1 || fc.Features[0].Properties.ItemID != "item-1" {
+		t.Errorf("unexpected features: %+v", fc.Features)
+	}
+	if retrieves.Load() != 1 {
+		t.Errorf("expected one retrieve call, got %d", retrieves.Load())
+	}
+}
+
+func TestRetrieveItems_PermissionDenied(t *testing.T) {
+	tests := []struct {
+		name  string
+		perms Permissions
+		req   *RetrieveRequest
+		want  string
+	}{
+		{"acquisitions", Permissions{CanOrder: true}, acquisitionRetrieve, "canOrderAcquisitionOnly"},
+		{"items", Permissions{CanOrderAcquisitionOnly: true}, &RetrieveRequest{Items: []string{"item-1"}}, "canOrder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var retrieves atomic.Int32
+			server, client := retrieveServer(t, tt.perms, http.StatusOK, &retrieves)
+			defer server.Close()
+
+			_, err := client.RetrieveItems(context.Background(), tt.req)
+			var permErr *PermissionError
+			if !errors.As(err, &permErr) || permErr.Permission != tt.want {
+				t.Fatalf("expected a %s PermissionError, got %v", tt.want, err)
+			}
+			if retrieves.Load() != 0 {
+				t.Error("expected no retrieve call")
+			}
+		})
+	}
+}
+
+func TestRetrieveItems_InvalidRequest(t *testing.T) {
+	var retrieves atomic.Int32
+	server, client := retrieveServer(t, Permissions{CanOrder: true, CanOrderAcquisitionOnly: true}, http.StatusOK, &retrieves)
+	defer server.Close()
+
+	for _, req := range []*RetrieveRequest{
+		{},
+		{Acquisitions: []string{"a"}, Items: []string{"b"}},
+		{Items: []string{"b"}, OrderOptions: &OrderOptions{ProductType: "XYZ"}},
+	} {
+		if _, err := client.RetrieveItems(context.Background(), req); err == nil {
+			t.Errorf("expected an error for %+v", req)
+		}
+	}
+	if retrieves.Load() != 0 {
+		t.Error("expected invalid requests not to reach the API")
+	}
+}
+
+func TestRetrieveItems_ServerError(t *testing.T) {
+	var retrieves atomic.Int32
+	server, client := retrieveServer(t, Permissions{CanOrderAcquisitionOnly: true}, http.StatusForbidden, &retrieves)
+	defer server.Close()
+
+	_, err := client.RetrieveItems(context.Background(), acquisitionRetrieve)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusForbidden || !strings.Contains(apiErr.Message, "not owned") {
+		t.Errorf("unexpected error: %+v", apiErr)
+	}
+	if !IsForbidden(err) {
+		t.Error("expected the error to match IsForbidden")
+	}
+}
+
 func TestSearchFeasibility(t *testing.T) {
 	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/sar/feasibility" {
@@ -250,6 +551,218 @@ func TestSearchFeasibility(t *testing.T) {
 	}
 }
 
+// stationServer serves a config with the given direct-access flag and
+// stations NSG_POOL and KIR, counting feasibility searches, which return one
+// feature per station plus one without a station.
+func stationServer(t *testing.T, directAccess bool, searches *atomic.Int32) (*httptest.Server, *Client) {
+	t.Helper()
+	return testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sar/config":
+			json.NewEncoder(w).Encode(Config{
+				Permissions:       &Permissions{IsDirectAccess: directAccess},
+				ReceivingStations: []ReceivingStation{{ID: "NSG_POOL"}, {ID: "KIR"}},
+			})
+		case "/sar/feasibility":
+			searches.Add(1)
+			var req FeasibilityRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if !req.AcquisitionOnly || req.ReceivingStation != "KIR" {
+				t.Errorf("expected an acquisition-only search at KIR, got %+v", req)
+			}
+			json.NewEncoder(w).Encode(FeatureCollection{Features: []Feature{
+				{Properties: AcquisitionProperties{ItemID: "kir", ReceivingStation: "KIR"}},
+				{Properties: AcquisitionProperties{ItemID: "pool", ReceivingStation: "NSG_POOL"}},
+				{Properties: AcquisitionProperties{ItemID: "unreported"}},
+			}, Total: 3})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+}
+
+func stationFeasibilityRequest(station string) *FeasibilityRequest {
+	return &FeasibilityRequest{
+		AOI:              NewPointGeometry(9.5, 47.5),
+		FeasibilityLevel: FeasibilityLevelSimple,
+		SensorMode:       SensorModeStaringSpotlight,
+		ReceivingStation: station,
+	}
+}
+
+func TestFeasibilityRequestValidate(t *testing.T) {
+	if err := stationFeasibilityRequest("").Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	err := (&FeasibilityRequest{Occurrences: 60}).Validate()
+	for _, want := range []string{"aoi", "feasibilityLevel", "sensorMode", "occurrences 60"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error mentioning %q, got %v", want, err)
+		}
+	}
+
+	stations := []ReceivingStation{{ID: "NSG_POOL"}, {ID: "KIR"}}
+	if err := stationFeasibilityRequest("KIR").Validate(stations...); err != nil {
+		t.Errorf("expected a known station to pass, got %v", err)
+	}
+	if err := stationFeasibilityRequest("SVALBARD").Validate(); err != nil {
+		t.Errorf("expected no station check without stations, got %v", err)
+	}
+	err = stationFeasibilityRequest("SVALBARD").Validate(stations...)
+	if err == nil || !strings.Contains(err.Error(), `unknown receiving station "SVALBARD" (account stations: NSG_POOL, KIR)`) {
+		t.Errorf("expected unknown station error, got %v", err)
+	}
+}
+
+func TestTimeRange_MarshalJSON(t *testing.T) {
+	from := time.Date(2024, 3, 1, 10, 30, 15, 123456789, time.FixedZone("CET", 3600))
+	tests := []struct {
+		name string
+		r    TimeRange
+		want string
+	}{
+		{"closed", TimeRange{From: from, To: from.Add(48 * time.Hour)}, `{"from":"2024-03-01T09:30:15Z","to":"2024-03-03T09:30:15Z"}`},
+		{"open-ended", TimeRange{From: from}, `{"from":"2024-03-01T09:30:15Z"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.r)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := json.Marshal(TimeRange{From: from, To: from}); err == nil {
+		t.Error("expected an error for an empty range")
+	}
+}
+
+func TestTimeRange_UnmarshalJSON(t *testing.T) {
+	want := time.Date(2024, 3, 1, 9, 30, 15, 0, time.UTC)
+	for _, in := range []string{
+		`{"from":"2024-03-01T09:30:15Z","to":"2024-03-02T09:30:15Z"}`,
+		`{"from":"2024-03-01T10:30:15+01:00","to":"2024-03-02T04:30:15-05:00"}`,
+	} {
+		var r TimeRange
+		if err := json.Unmarshal([]byte(in), &r); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", in, err)
+		}
+		if !r.From.Equal(want) || !r.To.Equal(want.Add(24*time.Hour)) || r.From.Location() != time.UTC {
+			t.Errorf("Unmarshal(%s) = %+v", in, r)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		if string(out) != `{"from":"2024-03-01T09:30:15Z","to":"2024-03-02T09:30:15Z"}` {
+			t.Errorf("round trip = %s", out)
+		}
+	}
+
+	var open TimeRange
+	if err := json.Unmarshal([]byte(`{"from":"2024-03-01T09:30:15Z"}`), &open); err != nil || !open.To.IsZero() {
+		t.Errorf("expected a zero To, got %+v (err %v)", open, err)
+	}
+}
+
+// Regression test: a zero To was sent as "0001-01-01T00:00:00Z", which the
+// API treats as an invalid range and answers with no results.
+func TestSearchFeasibility_OpenEndedTime(t *testing.T) {
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Time map[string]any `json:"time"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if _, ok := body.Time["to"]; ok {
+			t.Errorf("expected no time.to, got %v", body.Time)
+		}
+		if body.Time["from"] != "2024-03-01T09:30:15Z" {
+			t.Errorf("unexpected time.from %v", body.Time["from"])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(FeatureCollection{Type: "FeatureCollection"})
+	})
+	defer server.Close()
+
+	req := stationFeasibilityRequest("")
+	req.Time = TimeRange{From: time.Date(2024, 3, 1, 9, 30, 15, 500, time.UTC)}
+	if _, err := client.SearchFeasibility(context.Background(), req); err != nil {
+		t.Fatalf("SearchFeasibility() error = %v", err)
+	}
+
+	req.Time.To = req.Time.From.Add(-time.Hour)
+	if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "not before") {
+		t.Errorf("expected an inverted range error, got %v", err)
+	}
+}
+
+func TestValidateFeasibilityRequest_ReceivingStation(t *testing.T) {
+	var searches atomic.Int32
+	server, client := stationServer(t, true, &searches)
+	defer server.Close()
+	ctx := context.Background()
+
+	if err := client.ValidateFeasibilityRequest(ctx, stationFeasibilityRequest("KIR")); err != nil {
+		t.Errorf("expected a known station to pass, got %v", err)
+	}
+	if err := client.ValidateFeasibilityRequest(ctx, stationFeasibilityRequest("")); err != nil {
+		t.Errorf("expected no station to pass, got %v", err)
+	}
+	err := client.ValidateFeasibilityRequest(ctx, stationFeasibilityRequest("SVALBARD"))
+	if err == nil || !strings.Contains(err.Error(), `unknown receiving station "SVALBARD"`) {
+		t.Errorf("expected unknown station error, got %v", err)
+	}
+
+	stations, err := client.ListReceivingStations(ctx)
+	if err != nil || len(stations) != 2 {
+		t.Errorf("expected 2 stations, got %v (err %v)", stations, err)
+	}
+}
+
+func TestSearchFeasibilityForStation(t *testing.T) {
+	var searches atomic.Int32
+	server, client := stationServer(t, true, &searches)
+	defer server.Close()
+
+	req := stationFeasibilityRequest("")
+	fc, err := client.SearchFeasibilityForStation(context.Background(), req, "KIR")
+	if err != nil {
+		t.Fatalf("SearchFeasibilityForStation() error = %v", err)
+	}
+	var ids []string
+	for _, f := range fc.Features {
+		ids = append(ids, f.Properties.ItemID)
+	}
+	if !slices.Equal(ids, []string{"kir", "unreported"}) || fc.Total != 3 || fc.Dropped != 1 {
+		t.Errorf("expected features downlinked at KIR, got %v (total %d, dropped %d)", ids, fc.Total, fc.Dropped)
+	}
+	if req.AcquisitionOnly || req.ReceivingStation != "" {
+		t.Error("the caller's request must not be modified")
+	}
+}
+
+func TestSearchFeasibilityForStation_RequiresDirectAccess(t *testing.T) {
+	var searches atomic.Int32
+	server, client := stationServer(t, false, &searches)
+	defer server.Close()
+
+	_, err := client.SearchFeasibilityForStation(context.Background(), stationFeasibilityRequest(""), "KIR")
+	var permErr *PermissionError
+	if !errors.As(err, &permErr) || permErr.Permission != "isDirectAccess" {
+		t.Fatalf("expected *PermissionError, got %v", err)
+	}
+	if searches.Load() != 0 {
+		t.Error("search must not be sent without direct access")
+	}
+}
+
 func TestListBaskets(t *testing.T) {
 	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/sar/baskets" {
@@ -315,6 +828,47 @@ func TestCreateBasket(t *testing.T) {
 	}
 }
 
+func TestCreateBasket_Defaults(t *testing.T) {
+	var sent []CreateBasketRequest
+	server, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req CreateBasketRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		sent = append(sent, req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Basket{BasketID: "basket-1", Purpose: req.Purpose, Customer: req.Customer})
+	})
+	defer server.Close()
+
+	client, err := NewClient("test-api-key",
+		WithBaseURL(server.URL),
+		WithTokenURL(server.URL+"/auth/token"),
+		WithDefaultPurpose(PurposeGovernment),
+		WithDefaultCustomer("cust-1"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := &CreateBasketRequest{CustomerReference: "PROJECT-001"}
+	if _, err := client.CreateBasket(context.Background(), req); err != nil {
+		t.Fatalf("CreateBasket() error = %v", err)
+	}
+	if _, err := client.CreateBasket(context.Background(), &CreateBasketRequest{Purpose: PurposeBank, Customer: "cust-2"}); err != nil {
+		t.Fatalf("CreateBasket() error = %v", err)
+	}
+
+	want := []CreateBasketRequest{
+		{CustomerReference: "PROJECT-001", Purpose: PurposeGovernment, Customer: "cust-1"},
+		{Purpose: PurposeBank, Customer: "cust-2"},
+	}
+	if !slices.Equal(sent, want) {
+		t.Errorf("sent %+v, want %+v", sent, want)
+	}
+	if req.Purpose != "" || req.Customer != "" {
+		t.Errorf("caller's request was modified: %+v", req)
+	}
+}
+
 func TestAddItemsToBasket(t *testing.T) {
 	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/sar/baskets/basket-123/addItems" {
@@ -362,8 +916,154 @@ func TestAddItemsToBasket(t *testing.T) {
 	}
 }
 
+func TestOrderOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts OrderOptions
+		want []string
+	}{
+		{name: "empty", opts: OrderOptions{}},
+		{name: "EEC with projection", opts: OrderOptions{ProductType: ProductTypeEEC, MapProjection: MapProjectionUTM, GainAttenuation: GainAttenuation10}},
+		{name: "EEC without projection", opts: OrderOptions{ProductType: ProductTypeEEC}, want: []string{"EEC requires a map projection"}},
+		{name: "SSC with variant", opts: OrderOptions{ProductType: ProductTypeSSC, ResolutionVariant: ResolutionVariantSE}, want: []string{"SSC does not take a resolution variant"}},
+		{name: "SSC with gain", opts: OrderOptions{ProductType: ProductTypeSSC, GainAttenuation: GainAttenuation20}, want: []string{"only applies to detected products"}},
+		{name: "MGD with projection", opts: OrderOptions{ProductType: ProductTypeMGD, MapProjection: MapProjectionAuto}, want: []string{"only applies to geocoded products"}},
+		{name: "GEC projection optional", opts: OrderOptions{ProductType: ProductTypeGEC, ResolutionVariant: ResolutionVariantRE}},
+		{name: "unknown product", opts: OrderOptions{ProductType: "XYZ"}, want: []string{`unknown product type "XYZ"`}},
+		{
+			name: "unknown enums",
+			opts: OrderOptions{OrbitType: "slow", MapProjection: "LCC", GainAttenuation: 5},
+			want: []string{`unknown orbit type "slow"`, `unknown map projection "LCC"`, "gain attenuation 5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected validation error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+// orderOptionsServer serves a config with EEC/UTM/science default order
+// options, counting config fetches, and records the options of addItems
+// requests in sent.
+func orderOptionsServer(t *testing.T, configHits *atomic.Int32, sent **OrderOptions, opts ...Option) (*httptest.Server, *Client) {
+	t.Helper()
+	server, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sar/config":
+			configHits.Add(1)
+			json.NewEncoder(w).Encode(Config{Settings: &Settings{DefaultOrderOptions: &OrderOptions{
+				ProductType:   ProductTypeEEC,
+				MapProjection: MapProjectionUTM,
+				OrbitType:     OrbitTypeScience,
+			}}})
+		case "/sar/baskets/basket-123/addItems":
+			var req AddItemsRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			*sent = req.OrderOptions
+			json.NewEncoder(w).Encode(Basket{BasketID: "basket-123"})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	client, err := NewClient("test-api-key",
+		append([]Option{WithBaseURL(server.URL), WithTokenURL(server.URL + "/auth/token")}, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return server, client
+}
+
+func TestResolveOrderOptions(t *testing.T) {
+	var hits atomic.Int32
+	var sent *OrderOptions
+	server, client := orderOptionsServer(t, &hits, &sent)
+	defer server.Close()
+
+	user := &OrderOptions{OrbitType: OrbitTypeRapid, GainAttenuation: GainAttenuation10}
+	got, err := client.ResolveOrderOptions(context.Background(), user)
+	if err != nil {
+		t.Fatalf("ResolveOrderOptions() error = %v", err)
+	}
+	want := OrderOptions{
+		ProductType:     ProductTypeEEC,
+		MapProjection:   MapProjectionUTM,
+		OrbitType:       OrbitTypeRapid,
+		GainAttenuation: GainAttenuation10,
+	}
+	if *got != want {
+		t.Errorf("expected %+v, got %+v", want, *got)
+	}
+	if user.ProductType != "" {
+		t.Error("user options must not be modified")
+	}
+
+	// Overriding the product type to SSC keeps the default projection,
+	// which SSC rejects.
+	if _, err := client.ResolveOrderOptions(context.Background(), &OrderOptions{ProductType: ProductTypeSSC}); err == nil {
+		t.Error("expected merged options to fail validation")
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("expected config to be fetched once, got %d", n)
+	}
+}
+
+func TestAddItemsToBasket_OrderOptionDefaults(t *testing.T) {
+	var hits atomic.Int32
+	var sent *OrderOptions
+
+	// Without the option, partial options are sent as given.
+	server, client := orderOptionsServer(t, &hits, &sent)
+	defer server.Close()
+	partial := &OrderOptions{ResolutionVariant: ResolutionVariantSE}
+	if _, err := client.AddItemsToBasket(context.Background(), "basket-123", &AddItemsRequest{OrderOptions: partial}); err != nil {
+		t.Fatalf("AddItemsToBasket() error = %v", err)
+	}
+	if sent == nil || *sent != *partial || hits.Load() != 0 {
+		t.Errorf("expected options sent unchanged without a config fetch, got %+v after %d fetches", sent, hits.Load())
+	}
+
+	server, client = orderOptionsServer(t, &hits, &sent, WithOrderOptionDefaults())
+	defer server.Close()
+	if _, err := client.AddItemsToBasket(context.Background(), "basket-123", &AddItemsRequest{OrderOptions: partial}); err != nil {
+		t.Fatalf("AddItemsToBasket() error = %v", err)
+	}
+	want := OrderOptions{ProductType: ProductTypeEEC, ResolutionVariant: ResolutionVariantSE, MapProjection: MapProjectionUTM, OrbitType: OrbitTypeScience}
+	if sent == nil || *sent != want {
+		t.Errorf("expected resolved options %+v, got %+v", want, sent)
+	}
+
+	sent = nil
+	_, err := client.AddItemsToBasket(context.Background(), "basket-123", &AddItemsRequest{
+		OrderOptions: &OrderOptions{ProductType: ProductTypeMGD},
+	})
+	if err == nil || sent != nil {
+		t.Errorf("expected invalid options to be rejected before sending, got err=%v sent=%+v", err, sent)
+	}
+}
+
 func TestSubmitBasket(t *testing.T) {
 	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sar/baskets/basket-123" && r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(Basket{BasketID: "basket-123", Purpose: PurposeGovernment, Customer: "cust-1"})
+			return
+		}
 		if r.URL.Path != "/sar/baskets/basket-123/submit" {
 			http.Error(w, "not found", http.StatusNotFound)
 			return
@@ -391,7 +1091,452 @@ func TestSubmitBasket(t *testing.T) {
 	}
 }
 
-func TestListOrders(t *testing.T) {
+func TestSubmitOrder(t *testing.T) {
+	var bodies []map[string]any
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sar/orders/submit" || r.Method != http.MethodPost {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Order{OrderID: "order-1"})
+	})
+	defer server.Close()
+	ctx := context.Background()
+
+	if _, err := client.SubmitOrder(ctx, &SubmitOrderRequest{BasketID: "basket-1"}); err != nil {
+		t.Fatalf("SubmitOrder(basket) error = %v", err)
+	}
+	if _, err := client.SubmitOrder(ctx, &SubmitOrderRequest{Items: []string{"item-1", "item-2"}, Purpose: PurposeGovernment}); err != nil {
+		t.Fatalf("SubmitOrder(items) error = %v", err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(bodies))
+	}
+	if bodies[0]["basketId"] != "basket-1" || bodies[0]["items"] != nil {
+		t.Errorf("unexpected basket submission %v", bodies[0])
+	}
+	if items, _ := bodies[1]["items"].([]any); len(items) != 2 || bodies[1]["basketId"] != nil || bodies[1]["purpose"] != "Government" {
+		t.Errorf("unexpected item submission %v", bodies[1])
+	}
+}
+
+func TestSubmitOrder_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *SubmitOrderRequest
+	}{
+		{"nil", nil},
+		{"neither", &SubmitOrderRequest{DeliveryConfigID: "dc-1"}},
+		{"both", &SubmitOrderRequest{BasketID: "basket-1", Items: []string{"item-1"}}},
+		{"invalid options", &SubmitOrderRequest{Items: []string{"item-1"}, OrderOptions: &OrderOptions{ResolutionVariant: "XX"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("expected no request, got %s %s", r.Method, r.URL.Path)
+			})
+			defer server.Close()
+
+			if _, err := client.SubmitOrder(context.Background(), tt.req); err == nil {
+				t.Error("expected a validation error")
+			}
+		})
+	}
+}
+
+func TestOrderAcquisitions(t *testing.T) {
+	var calls []string
+	var submitted SubmitOrderRequest
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sar/prices":
+			var req PricesRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if !slices.Equal(req.Acquisitions, []string{"acq-1", "acq-2"}) {
+				t.Errorf("unexpected acquisitions %v", req.Acquisitions)
+			}
+			// Prices may come back in any order.
+			json.NewEncoder(w).Encode([]PriceResponse{
+				{AcquisitionID: "acq-2", ItemID: "item-2", Price: Price{Total: 200, Currency: "EUR"}},
+				{AcquisitionID: "acq-1", ItemID: "item-1", Price: Price{Total: 100, Currency: "EUR"}},
+			})
+		case "/sar/orders/submit":
+			json.NewDecoder(r.Body).Decode(&submitted)
+			json.NewEncoder(w).Encode(Order{OrderID: "order-1", Purpose: submitted.Purpose})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	order, err := client.OrderAcquisitions(context.Background(), []string{"acq-1", "acq-2"},
+		&OrderOptions{ProductType: ProductTypeSSC}, PurposeEmergencyResponse)
+	if err != nil {
+		t.Fatalf("OrderAcquisitions() error = %v", err)
+	}
+	if order.OrderID != "order-1" {
+		t.Errorf("expected order-1, got %s", order.OrderID)
+	}
+	if want := []string{"POST /sar/prices", "POST /sar/orders/submit"}; !slices.Equal(calls, want) {
+		t.Errorf("expected requests %v, got %v", want, calls)
+	}
+	if !slices.Equal(submitted.Items, []string{"item-1", "item-2"}) || submitted.Purpose != PurposeEmergencyResponse ||
+		submitted.OrderOptions == nil || submitted.OrderOptions.ProductType != ProductTypeSSC || submitted.BasketID != "" {
+		t.Errorf("unexpected submission %+v", submitted)
+	}
+}
+
+func TestOrderAcquisitions_Unpriced(t *testing.T) {
+	var submits atomic.Int32
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sar/prices":
+			json.NewEncoder(w).Encode([]PriceResponse{{AcquisitionID: "acq-1", ItemID: "item-1"}})
+		case "/sar/orders/submit":
+			submits.Add(1)
+			json.NewEncoder(w).Encode(Order{OrderID: "order-1"})
+		}
+	})
+	defer server.Close()
+
+	_, err := client.OrderAcquisitions(context.Background(), []string{"acq-1", "acq-2"}, nil, PurposeOther)
+	if err == nil || !strings.Contains(err.Error(), "acq-2") {
+		t.Fatalf("expected an error naming acq-2, got %v", err)
+	}
+	if submits.Load() != 0 {
+		t.Error("expected nothing to be submitted")
+	}
+	if _, err := client.OrderAcquisitions(context.Background(), []string{"acq-1"}, nil, ""); err == nil {
+		t.Error("expected an error without a purpose")
+	}
+}
+
+// lintServer serves a two-item basket along with the given conflicts and
+// revocations responses. Prices are returned for every requested item except
+// those in unpriced.
+func lintServer(t *testing.T, conflicts string, revocations []string, unpriced map[string]bool) (*httptest.Server, *Client) {
+	start := time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)
+	return testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sar/baskets/basket-1":
+			json.NewEncoder(w).Encode(Basket{
+				BasketID: "basket-1",
+				Items: []Item{
+					{ItemID: "item-1", AcquisitionID: "TSX-1_acq_1", StartTime: &start},
+					{ItemID: "item-2", AcquisitionID: "PAZ-1_acq_2", StartTime: timePtr(start.Add(24 * time.Hour))},
+				},
+			})
+		case "/sar/conflicts":
+			var req ConflictsRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if len(req.Items) != 2 {
+				t.Errorf("expected conflicts check for 2 items, got %v", req.Items)
+			}
+			io.WriteString(w, conflicts)
+		case "/sar/prices":
+			var req PricesRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			var out []PriceResponse
+			for _, id := range req.Items {
+				if !unpriced[id] {
+					out = append(out, PriceResponse{ItemID: id, Price: Price{Final: true, Total: 1000, Currency: "EUR"}})
+				}
+			}
+			json.NewEncoder(w).Encode(out)
+		case "/sar/catalogue/revocation":
+			if got := r.URL.Query().Get("since"); got != "2024-05-01T06:00:00Z" {
+				t.Errorf("expected revocations since earliest item start, got %q", got)
+			}
+			// Serve one revocation per page to exercise pagination.
+			page, _ := strconv.Atoi(r.URL.Query().Get("token"))
+			resp := map[string]any{"_links": map[string]any{"self": r.URL.String()}, "data": []any{}}
+			if page < len(revocations) {
+				resp["data"] = []map[string]any{{"acquisitionId": revocations[page], "issueTime": "2024-06-01T00:00:00Z", "reason": "quality"}}
+				if page+1 < len(revocations) {
+					resp["_links"].(map[string]any)["next"] = fmt.Sprintf("http://%s/sar/catalogue/revocation?token=%d", r.Host, page+1)
+				}
+			}
+			json.NewEncoder(w).Encode(resp)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+}
+
+func TestLintBasket_Clean(t *testing.T) {
+	server, client := lintServer(t, `[
+		{"itemId": "item-1", "acquisitionId": "TSX-1_acq_1", "conflicts": {"status": "none"}},
+		{"itemId": "item-2", "acquisitionId": "PAZ-1_acq_2", "conflicts": {"status": "none", "shopcart": {"item-1": {"status": "none"}}}}
+	]`, []string{"TSX-1_unrelated"}, nil)
+	defer server.Close()
+
+	report, err := client.LintBasket(context.Background(), "basket-1")
+	if err != nil {
+		t.Fatalf("LintBasket() error = %v", err)
+	}
+	if !report.Empty() {
+		t.Errorf("expected empty report, got %s", report)
+	}
+}
+
+func TestLintBasket_Conflicts(t *testing.T) {
+	server, client := lintServer(t, `[
+		{"itemId": "item-1", "acquisitionId": "TSX-1_acq_1", "conflicts": {"status": "expected",
+			"shopcart": {"item-2": {"status": "expected"}},
+			"external": {"ext-9": {"status": "potential", "startTime": "2024-05-01T06:00:00Z"}}}},
+		{"itemId": "item-2", "acquisitionId": "PAZ-1_acq_2", "conflicts": {"status": "expected",
+			"shopcart": {"item-1": {"status": "expected"}}}}
+	]`, nil, map[string]bool{"item-2": true})
+	defer server.Close()
+
+	report, err := client.LintBasket(context.Background(), "basket-1")
+	if err != nil {
+		t.Fatalf("LintBasket() error = %v", err)
+	}
+	if len(report.Conflicts) != 2 {
+		t.Fatalf("expected 2 deduplicated conflicts, got %+v", report.Conflicts)
+	}
+	if c := report.Conflicts[0]; c.ItemID1 != "item-1" || c.ItemID2 != "item-2" || c.Status != ConflictStatusExpected {
+		t.Errorf("unexpected basket conflict: %+v", c)
+	}
+	if c := report.Conflicts[1]; c.ItemID2 != "ext-9" || c.Status != ConflictStatusPotential {
+		t.Errorf("unexpected external conflict: %+v", c)
+	}
+	if len(report.MissingPrices) != 1 || report.MissingPrices[0] != "item-2" {
+		t.Errorf("expected item-2 to be missing a price, got %v", report.MissingPrices)
+	}
+	if report.Empty() || !strings.Contains(report.String(), "2 conflict(s), 1 missing price(s)") {
+		t.Errorf("unexpected summary: %s", report)
+	}
+}
+
+func TestLintBasket_RevokedAcquisition(t *testing.T) {
+	server, client := lintServer(t, `[]`, []string{"TSX-1_other", "PAZ-1_acq_2"}, nil)
+	defer server.Close()
+
+	report, err := client.LintBasket(context.Background(), "basket-1")
+	if err != nil {
+		t.Fatalf("LintBasket() error = %v", err)
+	}
+	if len(report.Revoked) != 1 {
+		t.Fatalf("expected 1 revoked item, got %+v", report.Revoked)
+	}
+	if r := report.Revoked[0]; r.ItemID != "item-2" || r.AcquisitionID != "PAZ-1_acq_2" || r.Reason != "quality" {
+		t.Errorf("unexpected revoked item: %+v", r)
+	}
+}
+
+// complianceServer serves basket-1 as basket and an account whose reseller
+// flag is reseller, counting submissions.
+func complianceServer(t *testing.T, basket Basket, reseller bool, submits *atomic.Int32, opts ...Option) (*httptest.Server, *Client) {
+	t.Helper()
+	server, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sar/baskets/basket-1":
+			json.NewEncoder(w).Encode(basket)
+		case "/sar/config":
+			json.NewEncoder(w).Encode(Config{Permissions: &Permissions{IsReseller: reseller}})
+		case "/sar/baskets/basket-1/submit":
+			submits.Add(1)
+			json.NewEncoder(w).Encode(Order{BasketID: "basket-1", OrderID: "order-1"})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	client, err := NewClient("test-api-key",
+		append([]Option{WithBaseURL(server.URL), WithTokenURL(server.URL + "/auth/token")}, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return server, client
+}
+
+func TestSubmitBasket_Compliance(t *testing.T) {
+	tests := []struct {
+		name     string
+		basket   Basket
+		reseller bool
+		opts     []Option
+		missing  []string
+	}{
+		{name: "compliant", basket: Basket{Purpose: PurposeGovernment}},
+		{name: "missing purpose", basket: Basket{Customer: "cust-1"}, missing: []string{"purpose"}},
+		{name: "reseller without customer", basket: Basket{Purpose: PurposeGovernment}, reseller: true, missing: []string{"customer"}},
+		{name: "reseller missing both", reseller: true, missing: []string{"purpose", "customer"}},
+		{name: "reseller with customer", basket: Basket{Purpose: PurposeGovernment, Customer: "cust-1"}, reseller: true},
+		{name: "check disabled", opts: []Option{WithoutComplianceCheck()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var submits atomic.Int32
+			tt.basket.BasketID = "basket-1"
+			server, client := complianceServer(t, tt.basket, tt.reseller, &submits, tt.opts...)
+			defer server.Close()
+
+			order, err := client.SubmitBasket(context.Background(), "basket-1")
+			if tt.missing == nil {
+				if err != nil {
+					t.Fatalf("SubmitBasket() error = %v", err)
+				}
+				if order.OrderID != "order-1" || submits.Load() != 1 {
+					t.Errorf("expected one submission, got order %+v after %d submits", order, submits.Load())
+				}
+				return
+			}
+
+			var compErr *ComplianceError
+			if !errors.As(err, &compErr) {
+				t.Fatalf("expected *ComplianceError, got %v", err)
+			}
+			if compErr.BasketID != "basket-1" || !slices.Equal(compErr.Missing, tt.missing) {
+				t.Errorf("unexpected compliance error: %+v", compErr)
+			}
+			if submits.Load() != 0 {
+				t.Errorf("basket must not be submitted, got %d submits", submits.Load())
+			}
+		})
+	}
+}
+
+// priceServer serves a two-item basket priced with the given item prices and
+// counts submissions.
+func priceServer(t *testing.T, prices string, submits *atomic.Int32) (*httptest.Server, *Client) {
+	t.Helper()
+	return testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sar/baskets/basket-1":
+			w.Write([]byte(`{"basketId": "basket-1", "purpose": "Government", "customer": "cust-1",
+				"items": [{"itemId": "item-1"}, {"itemId": "item-2"}]}`))
+		case "/sar/prices":
+			w.Write([]byte(prices))
+		case "/sar/baskets/basket-1/submit":
+			submits.Add(1)
+			w.Write([]byte(`{"basketId": "basket-1", "orderId": "order-1"}`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+}
+
+func TestGetBasketPrice(t *testing.T) {
+	var submits atomic.Int32
+	server, client := priceServer(t, `[
+		{"itemId": "item-1", "price": {"final": true, "total": 400, "currency": "EUR"}},
+		{"itemId": "item-2", "price": {"final": true, "total": 350.5, "currency": "EUR"}}
+	]`, &submits)
+	defer server.Close()
+
+	price, err := client.GetBasketPrice(context.Background(), "basket-1")
+	if err != nil {
+		t.Fatalf("GetBasketPrice() error = %v", err)
+	}
+	if price.Total != 750.5 || price.Currency != "EUR" || !price.Final {
+		t.Errorf("unexpected price: %+v", price)
+	}
+}
+
+func TestSubmitBasketIfUnder(t *testing.T) {
+	final := `[
+		{"itemId": "item-1", "price": {"final": true, "total": 400, "currency": "EUR"}},
+		{"itemId": "item-2", "price": {"final": true, "total": 600, "currency": "EUR"}}
+	]`
+	tests := []struct {
+		name         string
+		prices       string
+		max          float64
+		currency     string
+		requireFinal bool
+		reason       PriceGuardReason
+	}{
+		{name: "under budget", prices: final, max: 1000, currency: "EUR", requireFinal: true},
+		{name: "currency case-insensitive", prices: final, max: 1000, currency: "eur"},
+		{name: "over budget", prices: final, max: 999.99, currency: "EUR", reason: PriceGuardOverLimit},
+		{name: "currency mismatch", prices: final, max: 5000, currency: "USD", reason: PriceGuardCurrencyMismatch},
+		{
+			name: "non-final with requireFinal",
+			prices: `[
+				{"itemId": "item-1", "price": {"final": true, "total": 400, "currency": "EUR"}},
+				{"itemId": "item-2", "price": {"final": false, "total": 100, "currency": "EUR"}}
+			]`,
+			max: 1000, currency: "EUR", requireFinal: true, reason: PriceGuardNotFinal,
+		},
+		{
+			name:   "missing item price with requireFinal",
+			prices: `[{"itemId": "item-1", "price": {"final": true, "total": 400, "currency": "EUR"}}]`,
+			max:    1000, currency: "EUR", requireFinal: true, reason: PriceGuardNotFinal,
+		},
+		{
+			name: "non-final allowed",
+			prices: `[
+				{"itemId": "item-1", "price": {"final": false, "total": 400, "currency": "EUR"}},
+				{"itemId": "item-2", "price": {"final": false, "total": 100, "currency": "EUR"}}
+			]`,
+			max: 1000, currency: "EUR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var submits atomic.Int32
+			server, client := priceServer(t, tt.prices, &submits)
+			defer server.Close()
+
+			order, err := client.SubmitBasketIfUnder(context.Background(), "basket-1", tt.max, tt.currency, tt.requireFinal)
+			if tt.reason == "" {
+				if err != nil {
+					t.Fatalf("SubmitBasketIfUnder() error = %v", err)
+				}
+				if order.OrderID != "order-1" || submits.Load() != 1 {
+					t.Errorf("expected one submission, got order %+v after %d submits", order, submits.Load())
+				}
+				return
+			}
+
+			var guard *PriceGuardError
+			if !errors.As(err, &guard) {
+				t.Fatalf("expected *PriceGuardError, got %v", err)
+			}
+			if guard.Reason != tt.reason || guard.Price.Currency != "EUR" || guard.BasketID != "basket-1" {
+				t.Errorf("unexpected guard error: %+v", guard)
+			}
+			if order != nil || submits.Load() != 0 {
+				t.Errorf("basket must not be submitted, got order %+v after %d submits", order, submits.Load())
+			}
+		})
+	}
+}
+
+func TestSubmitBasketIfUnder_MixedCurrencies(t *testing.T) {
+	var submits atomic.Int32
+	server, client := priceServer(t, `[
+		{"itemId": "item-1", "price": {"final": true, "total": 400, "currency": "EUR"}},
+		{"itemId": "item-2", "price": {"final": true, "total": 100, "currency": "USD"}}
+	]`, &submits)
+	defer server.Close()
+
+	if _, err := client.SubmitBasketIfUnder(context.Background(), "basket-1", 1000, "EUR", false); err == nil {
+		t.Fatal("expected error for a basket quoted in mixed currencies")
+	}
+	if _, err := client.SubmitBasketIfUnder(context.Background(), "basket-1", 1000, "", false); err == nil {
+		t.Fatal("expected error for an empty guard currency")
+	}
+	if submits.Load() != 0 {
+		t.Errorf("basket must not be submitted, got %d submits", submits.Load())
+	}
+}
+
+func TestListOrders(t *testing.T) {
 	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/sar/orders" {
 			http.Error(w, "not found", http.StatusNotFound)
@@ -418,6 +1563,286 @@ func TestListOrders(t *testing.T) {
 	}
 }
 
+func TestListOrdersFiltered_Query(t *testing.T) {
+	var query url.Values
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	})
+	defer server.Close()
+
+	opts := ListOrdersOptions{
+		Customer:        "ACME Corp",
+		OrderType:       OrderTypeFeasibility,
+		ItemStatus:      ItemStatusDelivered,
+		SubmittedAfter:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600)),
+		SubmittedBefore: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		PageSize:        50,
+	}
+	for _, err := range client.ListOrdersFiltered(context.Background(), opts) {
+		t.Fatalf("unexpected result: %v", err)
+	}
+
+	want := url.Values{
+		"customer":        {"ACME Corp"},
+		"orderType":       {"feasibility"},
+		"status":          {"delivered"},
+		"submittedAfter":  {"2023-12-31T23:00:00Z"},
+		"submittedBefore": {"2024-07-01T00:00:00Z"},
+		"limit":           {"50"},
+	}
+	if query.Encode() != want.Encode() {
+		t.Errorf("query = %s, want %s", query.Encode(), want.Encode())
+	}
+}
+
+func TestListOrdersFiltered_Pages(t *testing.T) {
+	var offsets []string
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var page []OrderSummary
+		for i := offset; i < min(offset+limit, 5); i++ {
+			page = append(page, OrderSummary{BasketID: fmt.Sprintf("basket-%d", i)})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	})
+	defer server.Close()
+
+	var ids []string
+	for o, err := range client.ListOrdersFiltered(context.Background(), ListOrdersOptions{PageSize: 2}) {
+		if err != nil {
+			t.Fatalf("ListOrdersFiltered() error = %v", err)
+		}
+		ids = append(ids, o.BasketID)
+	}
+	if want := []string{"basket-0", "basket-1", "basket-2", "basket-3", "basket-4"}; !slices.Equal(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+	if want := []string{"", "2", "4", "5"}; !slices.Equal(offsets, want) {
+		t.Errorf("requested offsets %q, want %q", offsets, want)
+	}
+}
+
+func TestListOrdersFiltered_ClampedLimit(t *testing.T) {
+	// The server returns at most 2 orders per page whatever the limit.
+	var offsets []string
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		var page []OrderSummary
+		for i := offset; i < min(offset+2, 5); i++ {
+			page = append(page, OrderSummary{BasketID: fmt.Sprintf("basket-%d", i)})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	})
+	defer server.Close()
+
+	var ids []string
+	for o, err := range client.ListOrdersFiltered(context.Background(), ListOrdersOptions{PageSize: 10}) {
+		if err != nil {
+			t.Fatalf("ListOrdersFiltered() error = %v", err)
+		}
+		ids = append(ids, o.BasketID)
+	}
+	if want := []string{"basket-0", "basket-1", "basket-2", "basket-3", "basket-4"}; !slices.Equal(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+	if want := []string{"", "2", "4", "5"}; !slices.Equal(offsets, want) {
+		t.Errorf("requested offsets %q, want %q", offsets, want)
+	}
+}
+
+func TestListOrdersFiltered_Empty(t *testing.T) {
+	var requests int
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	})
+	defer server.Close()
+
+	for o, err := range client.ListOrdersFiltered(context.Background(), ListOrdersOptions{Customer: "nobody"}) {
+		t.Fatalf("unexpected result %+v, %v", o, err)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+	orders, err := client.ListOrders(context.Background())
+	if err != nil || orders == nil || len(orders) != 0 {
+		t.Errorf("ListOrders() = %v, %v; want an empty list", orders, err)
+	}
+}
+
+func TestListOrdersFiltered_ServerIgnoresParameters(t *testing.T) {
+	submitted := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	all := []OrderSummary{
+		{BasketID: "b1", Customer: "ACME", SubmissionTime: &submitted, ItemStatistics: &ItemStats{Delivered: 2}},
+		{BasketID: "b2", Customer: "ACME", ItemStatistics: &ItemStats{Planned: 1}},
+		{BasketID: "b3", Customer: "Other", SubmissionTime: &submitted, ItemStatistics: &ItemStats{Delivered: 1}},
+	}
+	var requests int
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(all)
+	})
+	defer server.Close()
+
+	// The whole list comes back for every page: the filters still apply
+	// and paging stops instead of repeating orders.
+	for _, pageSize := range []int{2, 3} {
+		requests = 0
+		var ids []string
+		opts := ListOrdersOptions{Customer: "ACME", ItemStatus: ItemStatusDelivered, SubmittedAfter: submitted.Add(-time.Hour), PageSize: pageSize}
+		for o, err := range client.ListOrdersFiltered(context.Background(), opts) {
+			if err != nil {
+				t.Fatalf("ListOrdersFiltered() error = %v", err)
+			}
+			ids = append(ids, o.BasketID)
+		}
+		if !slices.Equal(ids, []string{"b1"}) || requests > 2 {
+			t.Errorf("page size %d: got %v after %d requests", pageSize, ids, requests)
+		}
+	}
+}
+
+func TestReorderItems(t *testing.T) {
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/sar/orders/reorder" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		var req ReorderRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.OrderOptions == nil || req.OrderOptions.ProductType != ProductTypeSSC {
+			t.Errorf("unexpected order options: %+v", req.OrderOptions)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Order{
+			BasketID: "basket-reorder",
+			Items: []Item{
+				{ItemID: "new-1", ParentItemID: "item-1", ProductType: ProductTypeSSC},
+				{ItemID: "new-2", ParentItemID: "item-2", ProductType: ProductTypeSSC},
+			},
+		})
+	})
+	defer server.Close()
+
+	order, err := client.ReorderItems(context.Background(), &ReorderRequest{
+		Items:        []string{"item-1", "item-2"},
+		OrderOptions: &OrderOptions{ProductType: ProductTypeSSC},
+	})
+	if err != nil {
+		t.Fatalf("ReorderItems() error = %v", err)
+	}
+	if order.BasketID != "basket-reorder" || len(order.Items) != 2 {
+		t.Errorf("unexpected order: %+v", order)
+	}
+}
+
+func TestReorderItems_PartialFailure(t *testing.T) {
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"basketId": "basket-reorder",
+			"items": [{"itemId": "new-1", "parentItemId": "item-1"}],
+			"failed": [{"itemId": "item-2", "reason": "item not delivered"}]
+		}`))
+	})
+	defer server.Close()
+
+	order, err := client.ReorderItems(context.Background(), &ReorderRequest{
+		Items:        []string{"item-1", "item-2", "item-3"},
+		OrderOptions: &OrderOptions{ProductType: ProductTypeSSC},
+	})
+	var perr *PartialItemsError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *PartialItemsError, got %v", err)
+	}
+	if order == nil || order.BasketID != "basket-reorder" {
+		t.Errorf("expected order alongside partial error, got %+v", order)
+	}
+	if len(perr.Succeeded) != 1 || perr.Succeeded[0] != "item-1" {
+		t.Errorf("unexpected succeeded items: %v", perr.Succeeded)
+	}
+	if len(perr.Failed) != 2 || perr.Failed[0].ItemID != "item-2" || perr.Failed[0].Reason != "item not delivered" || perr.Failed[1].ItemID != "item-3" {
+		t.Errorf("unexpected failed items: %+v", perr.Failed)
+	}
+}
+
+func TestUpdateOrderItemsOptions(t *testing.T) {
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/sar/orderOptions" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"type": "FeatureCollection",
+			"features": [
+				{"type": "Feature", "properties": {"itemId": "item-1", "productType": "MGD", "orbitType": "rapid"}},
+				{"type": "Feature", "properties": {"itemId": "item-2", "productType": "MGD", "orbitType": "rapid"}}
+			]
+		}`))
+	})
+	defer server.Close()
+
+	items, err := client.UpdateOrderItemsOptions(context.Background(), &UpdateOrderOptionsRequest{
+		Items:        []string{"item-1", "item-2"},
+		OrderOptions: &OrderOptions{ProductType: ProductTypeMGD, OrbitType: OrbitTypeRapid},
+	})
+	if err != nil {
+		t.Fatalf("UpdateOrderItemsOptions() error = %v", err)
+	}
+	if len(items) != 2 || items[1].ItemID != "item-2" || items[1].ProductType != ProductTypeMGD {
+		t.Errorf("unexpected items: %+v", items)
+	}
+
+	_, err = client.UpdateOrderItemsOptions(context.Background(), &UpdateOrderOptionsRequest{
+		Items: []string{"item-1", "item-2", "item-3"},
+	})
+	var perr *PartialItemsError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *PartialItemsError, got %v", err)
+	}
+	if len(perr.Succeeded) != 2 || len(perr.Failed) != 1 || perr.Failed[0].ItemID != "item-3" {
+		t.Errorf("unexpected partial result: %+v", perr)
+	}
+}
+
+func TestItemsOptionsValidation(t *testing.T) {
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request should not be sent, got %s %s", r.Method, r.URL.Path)
+	})
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		items []string
+		opts  *OrderOptions
+	}{
+		{"no items", nil, &OrderOptions{ProductType: ProductTypeSSC}},
+		{"SSC with map projection", []string{"item-1"}, &OrderOptions{ProductType: ProductTypeSSC, MapProjection: MapProjectionUTM}},
+		{"GIM without EEC", []string{"item-1"}, &OrderOptions{ProductType: ProductTypeGEC, GeocodedIncidenceMask: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.ReorderItems(context.Background(), &ReorderRequest{Items: tt.items, OrderOptions: tt.opts}); err == nil {
+				t.Error("ReorderItems: expected validation error")
+			}
+			if _, err := client.UpdateOrderItemsOptions(context.Background(), &UpdateOrderOptionsRequest{Items: tt.items, OrderOptions: tt.opts}); err == nil {
+				t.Error("UpdateOrderItemsOptions: expected validation error")
+			}
+		})
+	}
+}
+
 func TestGetPrices(t *testing.T) {
 	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/sar/prices" {
@@ -458,13 +1883,310 @@ func TestGetPrices(t *testing.T) {
 	}
 }
 
-func TestGetConfig(t *testing.T) {
-	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/sar/config" {
+// batchPriceServer prices every requested acquisition and item, returning
+// them in reverse order, and records each batch it receives. Acquisitions
+// prefixed "draft-" get prices that are not final. Batches containing an ID
+// for which fail returns a non-zero status fail with that status.
+func batchPriceServer(t *testing.T, fail func(id string) int, opts ...Option) (*Client, func() []PricesRequest, *time.Time) {
+	t.Helper()
+	var (
+		mu      sync.Mutex
+		batches []PricesRequest
+	)
+	server, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sar/prices" {
 			http.Error(w, "not found", http.StatusNotFound)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
+		var req PricesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode prices request: %v", err)
+		}
+		mu.Lock()
+		batches = append(batches, req)
+		mu.Unlock()
+		if len(req.Acquisitions)+len(req.Items) > maxPriceBatch {
+			http.Error(w, `{"title": "too many items"}`, http.StatusBadRequest)
+			return
+		}
+		var out []PriceResponse
+		for _, id := range req.Acquisitions {
+			if status := fail(id); status != 0 {
+				http.Error(w, `{"title": "pricing failed"}`, status)
+				return
+			}
+			final := !strings.HasPrefix(id, "draft-")
+			out = append(out, PriceResponse{ItemID: "item-of-" + id, AcquisitionID: id, Price: Price{Final: final, Total: 100, Currency: "EUR"}})
+		}
+		for _, id := range req.Items {
+			if status := fail(id); status != 0 {
+				http.Error(w, `{"title": "pricing failed"}`, status)
+				return
+			}
+			out = append(out, PriceResponse{ItemID: id, Price: Price{Final: true, Total: 200, Currency: "EUR"}})
+		}
+		slices.Reverse(out)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(out)
+	})
+	t.Cleanup(server.Close)
+
+	client, err := NewClient("test-api-key", append([]Option{
+		WithBaseURL(server.URL),
+		WithTokenURL(server.URL + "/auth/token"),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if client.prices != nil {
+		client.prices.Now = func() time.Time { return now }
+	}
+	recorded := func() []PricesRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(batches)
+	}
+	return client, recorded, &now
+}
+
+func noPriceFailures(string) int { return 0 }
+
+func acquisitionIDs(prefix string, n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%s%03d", prefix, i)
+	}
+	return ids
+}
+
+func TestGetPrices_Batching(t *testing.T) {
+	tests := []struct {
+		name         string
+		acquisitions int
+		items        int
+		batches      []int
+	}{
+		{name: "one batch", acquisitions: maxPriceBatch, batches: []int{maxPriceBatch}},
+		{name: "just over", acquisitions: maxPriceBatch + 1, batches: []int{maxPriceBatch, 1}},
+		{name: "acquisitions and items", acquisitions: 150, items: 60, batches: []int{maxPriceBatch, maxPriceBatch, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, recorded, _ := batchPriceServer(t, noPriceFailures)
+			req := &PricesRequest{
+				Acquisitions:  acquisitionIDs("acq-", tt.acquisitions),
+				Items:         acquisitionIDs("item-", tt.items),
+				Customer:      "cust-1",
+				OrderTemplate: "tpl-1",
+			}
+			prices, err := client.GetPrices(context.Background(), req)
+			if err != nil {
+				t.Fatalf("GetPrices() error = %v", err)
+			}
+
+			var sizes []int
+			for _, b := range recorded() {
+				if b.Customer != "cust-1" || b.OrderTemplate != "tpl-1" {
+					t.Errorf("batch lost customer or order template: %+v", b)
+				}
+				sizes = append(sizes, len(b.Acquisitions)+len(b.Items))
+			}
+			slices.Sort(sizes)
+			slices.Reverse(sizes)
+			if !slices.Equal(sizes, tt.batches) {
+				t.Errorf("batch sizes = %v, want %v", sizes, tt.batches)
+			}
+
+			// Prices come back in request order, acquisitions first.
+			want := append(slices.Clone(req.Acquisitions), req.Items...)
+			var got []string
+			for _, p := range prices {
+				if p.AcquisitionID != "" {
+					got = append(got, p.AcquisitionID)
+				} else {
+					got = append(got, p.ItemID)
+				}
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("prices out of order: got %v..., want %v...", got[:min(len(got), 3)], want[:3])
+			}
+		})
+	}
+}
+
+func TestGetPrices_PartialFailure(t *testing.T) {
+	var attempts atomic.Int32
+	client, recorded, _ := batchPriceServer(t, func(id string) int {
+		switch id {
+		case "acq-150":
+			attempts.Add(1)
+			return http.StatusBadGateway
+		case "acq-160":
+			return http.StatusBadRequest
+		}
+		return 0
+	})
+	req := &PricesRequest{Acquisitions: acquisitionIDs("acq-", 250)}
+	prices, err := client.GetPrices(context.Background(), req)
+
+	var perr *PartialPricesError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *PartialPricesError, got %v", err)
+	}
+	if len(prices) != 150 {
+		t.Errorf("expected the 150 prices of the other batches, got %d", len(prices))
+	}
+	if len(perr.Failed) != 100 || perr.Failed[0].ID != "acq-100" || !perr.Failed[0].Acquisition {
+		t.Errorf("expected acq-100 to acq-199 to fail, got %d failures starting %+v", len(perr.Failed), perr.Failed[0])
+	}
+	if !IsServerError(err) {
+		t.Errorf("expected the batch's API error to be wrapped, got %v", err)
+	}
+	// The failing batch is retried once; the other two succeed first time.
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("expected the failed batch to be sent twice, got %d", got)
+	}
+	if got := len(recorded()); got != 4 {
+		t.Errorf("expected 4 requests, got %d", got)
+	}
+
+	// A client error is not retried, and a request that prices nothing
+	// returns the error alone.
+	prices, err = client.GetPrices(context.Background(), &PricesRequest{Acquisitions: []string{"acq-160"}})
+	if prices != nil || !IsClientError(err) || errors.As(err, &perr) {
+		t.Errorf("expected a plain client error, got %v, %v", prices, err)
+	}
+	if got := len(recorded()); got != 5 {
+		t.Errorf("expected the client error not to be retried, got %d requests", got)
+	}
+}
+
+func TestGetPrices_Duplicates(t *testing.T) {
+	client, recorded, _ := batchPriceServer(t, noPriceFailures)
+	prices, err := client.GetPrices(context.Background(), &PricesRequest{
+		Acquisitions: []string{"acq-1", "acq-2", "acq-1"},
+		Items:        []string{"item-1", "item-1"},
+	})
+	if err != nil {
+		t.Fatalf("GetPrices() error = %v", err)
+	}
+	var got []string
+	for _, p := range prices {
+		got = append(got, cmp.Or(p.AcquisitionID, p.ItemID))
+	}
+	if want := []string{"acq-1", "acq-2", "item-1"}; !slices.Equal(got, want) {
+		t.Errorf("got prices for %v, want %v", got, want)
+	}
+	if b := recorded(); len(b) != 1 || len(b[0].Acquisitions) != 2 || len(b[0].Items) != 1 {
+		t.Errorf("expected one de-duplicated batch, got %+v", b)
+	}
+
+	if _, err := client.GetPrices(context.Background(), nil); err == nil {
+		t.Error("expected an error for a nil request")
+	}
+}
+
+// uncomparableError is an error type that cannot be compared with ==.
+type uncomparableError struct{ details []string }
+
+func (e uncomparableError) Error() string { return strings.Join(e.details, "; ") }
+
+func TestPartialPricesError_Unwrap(t *testing.T) {
+	shared := errors.New("batch failed")
+	perr := &PartialPricesError{Failed: []PriceFailure{
+		{ID: "a", Err: uncomparableError{details: []string{"x"}}},
+		{ID: "b", Err: uncomparableError{details: []string{"y"}}},
+		{ID: "c", Err: shared},
+		{ID: "d", Err: shared},
+	}}
+	if errs := perr.Unwrap(); len(errs) != 3 {
+		t.Errorf("expected 3 distinct errors, got %v", errs)
+	}
+	if !errors.Is(perr, shared) {
+		t.Error("expected the shared batch error to be wrapped")
+	}
+}
+
+func TestGetPrices_Cache(t *testing.T) {
+	client, recorded, now := batchPriceServer(t, noPriceFailures, WithPriceCache(time.Hour))
+	ctx := context.Background()
+	queried := func() []string {
+		b := recorded()
+		if len(b) == 0 {
+			return nil
+		}
+		last := b[len(b)-1]
+		return append(last.Acquisitions, last.Items...)
+	}
+	price := func(req *PricesRequest) []PriceResponse {
+		t.Helper()
+		prices, err := client.GetPrices(ctx, req)
+		if err != nil {
+			t.Fatalf("GetPrices() error = %v", err)
+		}
+		return prices
+	}
+
+	price(&PricesRequest{Acquisitions: []string{"acq-1", "draft-1"}, Customer: "c1", OrderTemplate: "t1"})
+
+	prices := price(&PricesRequest{Acquisitions: []string{"acq-2", "acq-1"}, Customer: "c1", OrderTemplate: "t1"})
+	if got := queried(); !slices.Equal(got, []string{"acq-2"}) {
+		t.Errorf("expected only the uncached acquisition to be queried, got %v", got)
+	}
+	if len(prices) != 2 || prices[0].AcquisitionID != "acq-2" || prices[1].AcquisitionID != "acq-1" {
+		t.Errorf("expected cached and fetched prices in request order, got %+v", prices)
+	}
+
+	n := len(recorded())
+	price(&PricesRequest{Acquisitions: []string{"acq-1", "acq-2"}, Customer: "c1", OrderTemplate: "t1"})
+	if got := len(recorded()); got != n {
+		t.Errorf("expected a full cache hit, got %d new requests", got-n)
+	}
+
+	for _, tt := range []struct {
+		name string
+		req  *PricesRequest
+	}{
+		{"other customer", &PricesRequest{Acquisitions: []string{"acq-1"}, Customer: "c2", OrderTemplate: "t1"}},
+		{"other order template", &PricesRequest{Acquisitions: []string{"acq-1"}, Customer: "c1", OrderTemplate: "t2"}},
+		{"item with the same ID", &PricesRequest{Items: []string{"acq-1"}, Customer: "c1", OrderTemplate: "t1"}},
+	} {
+		n := len(recorded())
+		price(tt.req)
+		if got := len(recorded()); got != n+1 {
+			t.Errorf("%s: expected a cache miss", tt.name)
+		}
+	}
+
+	// Prices that are not final expire sooner.
+	*now = now.Add(nonFinalPriceTTL)
+	price(&PricesRequest{Acquisitions: []string{"acq-1", "draft-1"}, Customer: "c1", OrderTemplate: "t1"})
+	if got := queried(); !slices.Equal(got, []string{"draft-1"}) {
+		t.Errorf("expected only the non-final price to be refetched, got %v", got)
+	}
+
+	*now = now.Add(time.Hour)
+	price(&PricesRequest{Acquisitions: []string{"acq-1"}, Customer: "c1", OrderTemplate: "t1"})
+	if got := queried(); !slices.Equal(got, []string{"acq-1"}) {
+		t.Errorf("expected a refetch after the TTL, got %v", got)
+	}
+
+	n = len(recorded())
+	client.InvalidateCache()
+	price(&PricesRequest{Acquisitions: []string{"acq-1"}, Customer: "c1", OrderTemplate: "t1"})
+	if got := len(recorded()); got != n+1 {
+		t.Error("expected a refetch after InvalidateCache")
+	}
+}
+
+func TestGetConfig(t *testing.T) {
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sar/config" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(Config{
 			Permissions: &Permissions{
 				CanOrder: true,
@@ -486,6 +2208,367 @@ func TestGetConfig(t *testing.T) {
 	}
 }
 
+// cacheServer serves the account configuration, identity and order
+// templates, counting requests per path in hits.
+func cacheServer(t *testing.T, hits *sync.Map, delay time.Duration, opts ...Option) (*Client, *time.Time) {
+	t.Helper()
+	server, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		n, _ := hits.LoadOrStore(r.URL.Path, new(atomic.Int32))
+		n.(*atomic.Int32).Add(1)
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sar/config":
+			json.NewEncoder(w).Encode(Config{
+				Permissions:    &Permissions{CanOrder: true},
+				OrderTemplates: []OrderTemplate{{Name: "tpl-1"}},
+			})
+		case "/user/whoami":
+			json.NewEncoder(w).Encode(UserInfo{Username: "ops", Services: []Service{ServiceRadar}})
+		case "/sar/config/orderTemplates":
+			json.NewEncoder(w).Encode([]OrderTemplate{{Name: "tpl-1"}})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	t.Cleanup(server.Close)
+
+	client, err := NewClient("test-api-key", append([]Option{
+		WithBaseURL(server.URL),
+		WithTokenURL(server.URL + "/auth/token"),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	client.cache.now = func() time.Time { return now }
+	return client, &now
+}
+
+func hitCount(hits *sync.Map, path string) int32 {
+	n, ok := hits.Load(path)
+	if !ok {
+		return 0
+	}
+	return n.(*atomic.Int32).Load()
+}
+
+func TestConfigCache_HitAndExpiry(t *testing.T) {
+	var hits sync.Map
+	client, now := cacheServer(t, &hits, 0, WithConfigCache(time.Minute))
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := client.GetConfig(ctx); err != nil {
+			t.Fatalf("GetConfig() error = %v", err)
+		}
+		if _, err := client.WhoAmI(ctx); err != nil {
+			t.Fatalf("WhoAmI() error = %v", err)
+		}
+	}
+	if got := hitCount(&hits, "/sar/config"); got != 1 {
+		t.Errorf("expected 1 config request, got %d", got)
+	}
+	if got := hitCount(&hits, "/user/whoami"); got != 1 {
+		t.Errorf("expected 1 whoami request, got %d", got)
+	}
+
+	*now = now.Add(time.Minute)
+	client.GetConfig(ctx)
+	if got := hitCount(&hits, "/sar/config"); got != 2 {
+		t.Errorf("expected a refetch after the TTL, got %d requests", got)
+	}
+
+	client.InvalidateCache()
+	client.WhoAmI(ctx)
+	if got := hitCount(&hits, "/user/whoami"); got != 2 {
+		t.Errorf("expected a refetch after InvalidateCache, got %d requests", got)
+	}
+}
+
+func TestConfigCache_SingleFlight(t *testing.T) {
+	var hits sync.Map
+	client, _ := cacheServer(t, &hits, 50*time.Millisecond, WithConfigCache(time.Minute))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetConfig(context.Background()); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("GetConfig() error = %v", err)
+	}
+	if got := hitCount(&hits, "/sar/config"); got != 1 {
+		t.Errorf("expected concurrent misses to share 1 request, got %d", got)
+	}
+}
+
+func TestConfigCache_ReturnsCopies(t *testing.T) {
+	var hits sync.Map
+	client, _ := cacheServer(t, &hits, 0, WithConfigCache(time.Minute))
+	ctx := context.Background()
+
+	first, err := client.GetConfig(ctx)
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
+	first.Permissions.CanOrder = false
+	first.OrderTemplates[0].Name = "changed"
+
+	second, err := client.GetConfig(ctx)
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
+	if !second.Permissions.CanOrder || second.OrderTemplates[0].Name != "tpl-1" {
+		t.Errorf("caller mutation leaked into the cache: %+v", second)
+	}
+}
+
+func TestEndpointCache(t *testing.T) {
+	var hits sync.Map
+	client, _ := cacheServer(t, &hits, 0,
+		WithConfigCache(time.Minute),
+		WithEndpointCache(CacheWhoAmI, 0),
+		WithEndpointCache(CacheOrderTemplates, time.Hour),
+	)
+	ctx := context.Background()
+
+	for range 2 {
+		client.WhoAmI(ctx)
+		client.GetOrderTemplates(ctx)
+	}
+	if got := hitCount(&hits, "/user/whoami"); got != 2 {
+		t.Errorf("expected whoami caching to be disabled, got %d requests", got)
+	}
+	if got := hitCount(&hits, "/sar/config/orderTemplates"); got != 1 {
+		t.Errorf("expected order templates to be cached, got %d requests", got)
+	}
+}
+
+func TestConfigCache_Disabled(t *testing.T) {
+	var hits sync.Map
+	client, _ := cacheServer(t, &hits, 0)
+	ctx := context.Background()
+
+	client.GetConfig(ctx)
+	client.GetConfig(ctx)
+	if got := hitCount(&hits, "/sar/config"); got != 2 {
+		t.Errorf("expected GetConfig not to be cached by default, got %d requests", got)
+	}
+}
+
+// deliveryServer stores delivery configurations in memory and, like a
+// misbehaving API, echoes secrets back in its responses.
+func deliveryServer(t *testing.T, bodies *[]string) (*httptest.Server, *Client) {
+	t.Helper()
+	configs := map[string]json.RawMessage{}
+	var order []string
+	return testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		id := strings.TrimPrefix(r.URL.Path, "/sar/config/deliveries/")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/sar/config/deliveries",
+			r.Method == http.MethodPut && id != r.URL.Path:
+			body, _ := io.ReadAll(r.Body)
+			*bodies = append(*bodies, string(body))
+			var cfg map[string]any
+			json.Unmarshal(body, &cfg)
+			if r.Method == http.MethodPost {
+				id = fmt.Sprintf("dc-%d", len(order)+1)
+				order = append(order, id)
+			}
+			cfg["id"] = id
+			configs[id], _ = json.Marshal(cfg)
+			w.Write(configs[id])
+		case r.Method == http.MethodGet && r.URL.Path == "/sar/config/deliveries":
+			list := make([]json.RawMessage, 0, len(order))
+			for _, id := range order {
+				list = append(list, configs[id])
+			}
+			json.NewEncoder(w).Encode(list)
+		case r.Method == http.MethodGet:
+			w.Write(configs[id])
+		case r.Method == http.MethodDelete:
+			delete(configs, id)
+			order = slices.DeleteFunc(order, func(s string) bool { return s == id })
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+}
+
+func TestDeliveryConfig_CRUD(t *testing.T) {
+	var bodies []string
+	server, client := deliveryServer(t, &bodies)
+	defer server.Close()
+	ctx := context.Background()
+
+	created, err := client.CreateDeliveryConfig(ctx, &DeliveryConfig{
+		Name: "ops sftp",
+		Type: DeliveryTypeSFTP,
+		SFTP: &SFTPDelivery{Host: "sftp.example.com", Path: "/incoming", Username: "ops", Password: "hunter2"},
+	})
+	if err != nil {
+		t.Fatalf("CreateDeliveryConfig() error = %v", err)
+	}
+	if created.ID != "dc-1" || created.SFTP.Host != "sftp.example.com" {
+		t.Errorf("unexpected created config: %v", created)
+	}
+	if !strings.Contains(bodies[0], `"password":"hunter2"`) {
+		t.Errorf("expected the secret to be sent, got %s", bodies[0])
+	}
+
+	if _, err := client.CreateDeliveryConfig(ctx, &DeliveryConfig{
+		Name: "archive bucket",
+		Type: DeliveryTypeS3,
+		S3:   &S3Delivery{Bucket: "sar-archive", Region: "eu-west-1", CredentialsRef: "cred-1"},
+	}); err != nil {
+		t.Fatalf("CreateDeliveryConfig() error = %v", err)
+	}
+
+	updated, err := client.UpdateDeliveryConfig(ctx, "dc-1", &DeliveryConfig{
+		Name: "ops sftp",
+		Type: DeliveryTypeSFTP,
+		SFTP: &SFTPDelivery{Host: "sftp2.example.com", Port: 2222, Username: "ops", KeyRef: "key-1"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateDeliveryConfig() error = %v", err)
+	}
+	if updated.SFTP.Host != "sftp2.example.com" || updated.SFTP.Port != 2222 {
+		t.Errorf("unexpected updated config: %v", updated)
+	}
+
+	got, err := client.GetDeliveryConfig(ctx, "dc-1")
+	if err != nil {
+		t.Fatalf("GetDeliveryConfig() error = %v", err)
+	}
+	if got.SFTP.KeyRef != "key-1" {
+		t.Errorf("unexpected config: %v", got)
+	}
+
+	if err := client.DeleteDeliveryConfig(ctx, "dc-1"); err != nil {
+		t.Fatalf("DeleteDeliveryConfig() error = %v", err)
+	}
+	list, err := client.ListDeliveryConfigs(ctx)
+	if err != nil {
+		t.Fatalf("ListDeliveryConfigs() error = %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "dc-2" || list[0].S3.Bucket != "sar-archive" {
+		t.Errorf("unexpected configs after delete: %v", list)
+	}
+}
+
+func TestDeliveryConfig_SecretRedaction(t *testing.T) {
+	var bodies []string
+	server, client := deliveryServer(t, &bodies)
+	defer server.Close()
+
+	cfg := &DeliveryConfig{
+		Name: "bucket",
+		Type: DeliveryTypeS3,
+		S3:   &S3Delivery{Bucket: "sar", Region: "eu-west-1", AccessKeyID: "AKIA123", SecretAccessKey: "s3cr3t"},
+	}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		if out := fmt.Sprintf(format, cfg.S3); strings.Contains(out, "s3cr3t") {
+			t.Errorf("%s leaks the secret: %s", format, out)
+		}
+	}
+	if out := fmt.Sprintf("%+v", *cfg); strings.Contains(out, "s3cr3t") {
+		t.Errorf("String() leaks the secret: %s", out)
+	}
+
+	created, err := client.CreateDeliveryConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("CreateDeliveryConfig() error = %v", err)
+	}
+	if !strings.Contains(bodies[0], "s3cr3t") {
+		t.Fatalf("expected the secret to be sent, got %s", bodies[0])
+	}
+	if created.S3.SecretAccessKey != "" {
+		t.Error("secret echoed in the create response must be cleared")
+	}
+	got, err := client.GetDeliveryConfig(context.Background(), created.ID)
+	if err != nil {
+		t.Fatalf("GetDeliveryConfig() error = %v", err)
+	}
+	if got.S3.SecretAccessKey != "" || got.S3.AccessKeyID != "AKIA123" {
+		t.Errorf("expected only the secret to be cleared, got %+v", got.S3)
+	}
+}
+
+func TestDeliveryConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *DeliveryConfig
+	}{
+		{"nil", nil},
+		{"no name", &DeliveryConfig{Type: DeliveryTypeS3, S3: &S3Delivery{Bucket: "b", Region: "r", CredentialsRef: "c"}}},
+		{"unknown type", &DeliveryConfig{Name: "x", Type: "FTP"}},
+		{"type mismatch", &DeliveryConfig{Name: "x", Type: DeliveryTypeSFTP, S3: &S3Delivery{Bucket: "b", Region: "r", CredentialsRef: "c"}}},
+		{"empty host", &DeliveryConfig{Name: "x", Type: DeliveryTypeSFTP, SFTP: &SFTPDelivery{Username: "u", KeyRef: "k"}}},
+		{"bad port", &DeliveryConfig{Name: "x", Type: DeliveryTypeSFTP, SFTP: &SFTPDelivery{Host: "h", Port: 70000, Username: "u", KeyRef: "k"}}},
+		{"no sftp credential", &DeliveryConfig{Name: "x", Type: DeliveryTypeSFTP, SFTP: &SFTPDelivery{Host: "h", Username: "u"}}},
+		{"empty bucket", &DeliveryConfig{Name: "x", Type: DeliveryTypeS3, S3: &S3Delivery{Region: "r", CredentialsRef: "c"}}},
+		{"no s3 secret", &DeliveryConfig{Name: "x", Type: DeliveryTypeS3, S3: &S3Delivery{Bucket: "b", Region: "r", AccessKeyID: "a"}}},
+	}
+
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("invalid config must not reach the API: %s %s", r.Method, r.URL.Path)
+	})
+	defer server.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.CreateDeliveryConfig(context.Background(), tt.cfg); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestDeliverySelection(t *testing.T) {
+	var bodies []string
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sar/baskets":
+			w.Write([]byte(`{"basketId": "basket-1", "deliveryConfigId": "dc-1"}`))
+		case "/sar/orders/submit":
+			w.Write([]byte(`{"basketId": "basket-1", "orderId": "order-1", "deliveryConfigId": "dc-2"}`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	basket, err := client.CreateBasket(context.Background(), &CreateBasketRequest{Purpose: "Research", DeliveryConfigID: "dc-1"})
+	if err != nil {
+		t.Fatalf("CreateBasket() error = %v", err)
+	}
+	if basket.DeliveryConfigID != "dc-1" || !strings.Contains(bodies[0], `"deliveryConfigId":"dc-1"`) {
+		t.Errorf("expected basket to carry dc-1, got %+v from %s", basket, bodies[0])
+	}
+
+	order, err := client.SubmitOrder(context.Background(), &SubmitOrderRequest{BasketID: "basket-1", DeliveryConfigID: "dc-2"})
+	if err != nil {
+		t.Fatalf("SubmitOrder() error = %v", err)
+	}
+	if order.DeliveryConfigID != "dc-2" || !strings.Contains(bodies[1], `"deliveryConfigId":"dc-2"`) {
+		t.Errorf("expected order to carry dc-2, got %+v from %s", order, bodies[1])
+	}
+}
+
 func TestAPIKeyAuth(t *testing.T) {
 	tokenCalled := false
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -574,6 +2657,1584 @@ func TestAPIKeyAuth_TokenReuse(t *testing.T) {
 	}
 }
 
+func TestAPIKeyAuth_ConcurrentApplySingleFetch(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond) // widen the race window
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	auth := NewAPIKeyAuth("test-key", server.URL+"/token", nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, "https://example.com/api", nil)
+			if err := auth.Apply(context.Background(), req); err != nil {
+				t.Errorf("Apply() error = %v", err)
+			}
+			if got := req.Header.Get("Authorization"); got != "Bearer token" {
+				t.Errorf("expected Bearer token, got %q", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected 1 token request, got %d", n)
+	}
+}
+
+func TestAPIKeyAuth_CanceledLeaderDoesNotFailWaiters(t *testing.T) {
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	auth := NewAPIKeyAuth("test-key", server.URL+"/token", nil)
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com/api", nil)
+		leaderErr <- auth.Apply(leaderCtx, req)
+	}()
+	<-started
+
+	waiterErr := make(chan error, 1)
+	waiter, _ := http.NewRequest(http.MethodGet, "https://example.com/api", nil)
+	go func() { waiterErr <- auth.Apply(context.Background(), waiter) }()
+
+	cancel()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("leader Apply() error = %v, want context.Canceled", err)
+	}
+	close(release)
+
+	if err := <-waiterErr; err != nil {
+		t.Fatalf("waiter Apply() error = %v", err)
+	}
+	if got := waiter.Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("expected Bearer token, got %q", got)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected 1 token request, got %d", n)
+	}
+}
+
+func TestAPIKeyAuth_RefreshMarginAndInvalidate(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 600})
+	}))
+	defer server.Close()
+
+	auth := NewAPIKeyAuth("test-key", server.URL+"/token", nil)
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/api", nil)
+
+	auth.Apply(context.Background(), req)
+	auth.Apply(context.Background(), req)
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected cached token, got %d token requests", n)
+	}
+
+	// A margin larger than the token lifetime forces a refresh.
+	auth.SetRefreshMargin(15 * time.Minute)
+	auth.Apply(context.Background(), req)
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected refresh within margin, got %d token requests", n)
+	}
+
+	auth.SetRefreshMargin(time.Minute)
+	auth.Invalidate()
+	auth.Apply(context.Background(), req)
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("expected refresh after Invalidate, got %d token requests", n)
+	}
+}
+
+// revocationServer issues sequential tokens and rejects API calls using
+// tokens listed in revoked with the given 401 body.
+func TestAPIError_ProblemDocument(t *testing.T) {
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.Header().Set("X-Correlation-Id", "corr-123")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"type":"/problems/invalid-parameter","title":"Invalid parameter","status":400,` +
+			`"detail":"acquisitionDate must be in the past","instance":"/sar/catalogue/abc",` +
+			`"parameters":[{"name":"acquisitionDate","path":"$.acquisitionDate","reason":"in the future"}]}`))
+	})
+	defer server.Close()
+
+	_, err := client.SearchCatalogue(context.Background(), &CatalogueRequest{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Type != "/problems/invalid-parameter" {
+		t.Errorf("unexpected status/type: %d %q", apiErr.StatusCode, apiErr.Type)
+	}
+	if apiErr.Message != "acquisitionDate must be in the past" || apiErr.Title != "Invalid parameter" {
+		t.Errorf("unexpected message/title: %q %q", apiErr.Message, apiErr.Title)
+	}
+	if apiErr.Instance != "/sar/catalogue/abc" {
+		t.Errorf("unexpected instance: %q", apiErr.Instance)
+	}
+	if len(apiErr.Parameters) != 1 || apiErr.Parameters[0].Name != "acquisitionDate" {
+		t.Errorf("unexpected parameters: %+v", apiErr.Parameters)
+	}
+	if apiErr.RequestID != "corr-123" {
+		t.Errorf("expected request ID corr-123, got %q", apiErr.RequestID)
+	}
+	if !strings.Contains(err.Error(), "corr-123") || !strings.Contains(err.Error(), "acquisitionDate: in the future") {
+		t.Errorf("error string missing details: %v", err)
+	}
+	if !IsValidation(err) || !IsBadRequest(err) {
+		t.Error("expected validation and bad request predicates to match")
+	}
+}
+
+func TestAPIError_HTMLBody(t *testing.T) {
+	page := "<html><body>" + strings.Repeat("Bad Gateway ", 100) + "</body></html>"
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("X-Request-Id", "req-42")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(page))
+	})
+	defer server.Close()
+
+	_, err := client.ListBaskets(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if apiErr.Code != "Bad Gateway" || apiErr.Message != "" {
+		t.Errorf("unexpected code/message: %q %q", apiErr.Code, apiErr.Message)
+	}
+	if len(apiErr.RawBody) > 600 || !strings.HasPrefix(apiErr.RawBody, "<html>") {
+		t.Errorf("expected truncated raw body, got %d bytes", len(apiErr.RawBody))
+	}
+	if apiErr.RequestID != "req-42" {
+		t.Errorf("expected request ID req-42, got %q", apiErr.RequestID)
+	}
+	if !IsServerError(err) {
+		t.Error("expected server error predicate to match")
+	}
+}
+
+func TestAPIError_TruncatesOnRuneBoundary(t *testing.T) {
+	// "é" is two bytes, so the cut falls inside a rune.
+	body := "x" + strings.Repeat("é", maxErrorBody)
+	err := parseError(&http.Response{
+		StatusCode: http.StatusBadGateway,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if !utf8.ValidString(apiErr.RawBody) || !strings.HasSuffix(apiErr.RawBody, "é...") {
+		t.Errorf("expected valid UTF-8 cut on a rune boundary, got %q", apiErr.RawBody[len(apiErr.RawBody)-8:])
+	}
+}
+
+func TestAPIError_Predicates(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		notFound   bool
+		unauth     bool
+		quota      bool
+		validation bool
+	}{
+		{"not found", http.StatusNotFound, `{"title":"Not Found"}`, true, false, false, false},
+		{"unauthorized", http.StatusUnauthorized, `{"title":"Unauthorized"}`, false, true, false, false},
+		{"quota by status", http.StatusPaymentRequired, `{"title":"Payment Required"}`, false, false, true, false},
+		{"quota by message", http.StatusForbidden, `{"title":"Forbidden","detail":"Order quota exceeded for contract"}`, false, false, true, false},
+		{"validation 422", http.StatusUnprocessableEntity, `{"title":"Unprocessable"}`, false, false, false, true},
+		{"server error", http.StatusInternalServerError, `{"title":"quota service down"}`, false, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			defer server.Close()
+
+			_, err := client.GetBasket(context.Background(), "b-1")
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if IsNotFound(err) != tt.notFound {
+				t.Errorf("IsNotFound = %v, want %v", IsNotFound(err), tt.notFound)
+			}
+			if IsUnauthorized(err) != tt.unauth {
+				t.Errorf("IsUnauthorized = %v, want %v", IsUnauthorized(err), tt.unauth)
+			}
+			if IsQuotaExceeded(err) != tt.quota {
+				t.Errorf("IsQuotaExceeded = %v, want %v", IsQuotaExceeded(err), tt.quota)
+			}
+			if IsValidation(err) != tt.validation {
+				t.Errorf("IsValidation = %v, want %v", IsValidation(err), tt.validation)
+			}
+		})
+	}
+
+	if IsQuotaExceeded(errors.New("quota exceeded")) || IsValidation(nil) {
+		t.Error("predicates should not match non-API errors")
+	}
+}
+
+func revocationServer(t *testing.T, revoked map[string]bool, body string, apiHits *atomic.Int32) (*httptest.Server, *Client) {
+	t.Helper()
+	var issued atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		n := issued.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"access_token": fmt.Sprintf("token-%d", n), "expires_in": 3600})
+	})
+	mux.HandleFunc("/sar/baskets", func(w http.ResponseWriter, r *http.Request) {
+		apiHits.Add(1)
+		if revoked[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")] {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, body)
+			return
+		}
+		var req CreateBasketRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CustomerReference != "ref" {
+			t.Errorf("request body not replayed: %+v, %v", req, err)
+		}
+		json.NewEncoder(w).Encode(Basket{BasketID: "basket-1"})
+	})
+	server := httptest.NewServer(mux)
+
+	client, err := NewClient("test-api-key",
+		WithBaseURL(server.URL),
+		WithTokenURL(server.URL+"/auth/token"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return server, client
+}
+
+func TestClient_RetriesOnInvalidToken(t *testing.T) {
+	var hits atomic.Int32
+	server, client := revocationServer(t, map[string]bool{"token-1": true},
+		`{"error":"invalid_token","error_description":"Token is not active"}`, &hits)
+	defer server.Close()
+
+	basket, err := client.CreateBasket(context.Background(), &CreateBasketRequest{CustomerReference: "ref"})
+	if err != nil {
+		t.Fatalf("CreateBasket() error = %v", err)
+	}
+	if basket.BasketID != "basket-1" {
+		t.Errorf("expected basket-1, got %s", basket.BasketID)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("expected 2 API calls (401 then retry), got %d", n)
+	}
+	if tok := client.Auth().Token(); tok != "token-2" {
+		t.Errorf("expected refreshed token-2, got %s", tok)
+	}
+}
+
+func TestClient_DoesNotRetryPermissionError(t *testing.T) {
+	var hits atomic.Int32
+	server, client := revocationServer(t, map[string]bool{"token-1": true},
+		`{"message":"user lacks ordering permission"}`, &hits)
+	defer server.Close()
+
+	_, err := client.CreateBasket(context.Background(), &CreateBasketRequest{CustomerReference: "ref"})
+	if !IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Message != "user lacks ordering permission" {
+		t.Errorf("expected original error body to be preserved, got %q", apiErr.Message)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("expected 1 API call, got %d", n)
+	}
+}
+
+func TestClient_RetriesInvalidTokenOnlyOnce(t *testing.T) {
+	var hits atomic.Int32
+	server, client := revocationServer(t, map[string]bool{"token-1": true, "token-2": true},
+		`{"error":"invalid_token"}`, &hits)
+	defer server.Close()
+
+	_, err := client.CreateBasket(context.Background(), &CreateBasketRequest{CustomerReference: "ref"})
+	if !IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("expected exactly 2 API calls, got %d", n)
+	}
+}
+
+// breakerClient returns a client with a circuit breaker of threshold 2 and
+// a one-minute cooldown on a fake clock, against a server answering /sar/ping
+// with the status in status and /sar/health with health.
+func breakerClient(t *testing.T, status, health *atomic.Int32, hits *atomic.Int32, opts ...Option) (*Client, *time.Time) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "test-token", "expires_in": 3600})
+	})
+	mux.HandleFunc("/sar/ping", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(int(status.Load()))
+	})
+	mux.HandleFunc("/sar/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(int(health.Load()))
+		json.NewEncoder(w).Encode(HealthStatus{Status: "pass"})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient("test-api-key", append([]Option{
+		WithBaseURL(server.URL),
+		WithTokenURL(server.URL + "/auth/token"),
+		WithCircuitBreaker(2, time.Minute),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	now := time.Now()
+	client.breaker.now = func() time.Time { return now }
+	return client, &now
+}
+
+func TestCircuitBreaker_TripAndProbe(t *testing.T) {
+	var status, health, hits atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	client, now := breakerClient(t, &status, &health, &hits)
+	ctx := context.Background()
+
+	for range 2 {
+		if err := client.Ping(ctx); !IsServerError(err) {
+			t.Fatalf("expected a server error, got %v", err)
+		}
+	}
+	if s := client.CircuitState(); s != CircuitOpen {
+		t.Fatalf("expected open circuit, got %s", s)
+	}
+
+	// Open: calls fail without reaching the server.
+	err := client.Ping(ctx)
+	var open *CircuitOpenError
+	if !errors.As(err, &open) || open.RetryIn != time.Minute {
+		t.Fatalf("expected *CircuitOpenError with a minute to wait, got %v", err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("expected 2 requests to reach the server, got %d", n)
+	}
+
+	// Half-open: a failed probe reopens the circuit.
+	*now = now.Add(time.Minute)
+	if s := client.CircuitState(); s != CircuitHalfOpen {
+		t.Errorf("expected half-open circuit, got %s", s)
+	}
+	if err := client.Ping(ctx); !IsServerError(err) {
+		t.Fatalf("expected the probe to fail, got %v", err)
+	}
+	if err := client.Ping(ctx); !errors.As(err, &open) {
+		t.Fatalf("expected the circuit to reopen, got %v", err)
+	}
+
+	// A successful probe closes it.
+	status.Store(http.StatusOK)
+	*now = now.Add(time.Minute)
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	if s := client.CircuitState(); s != CircuitClosed {
+		t.Errorf("expected closed circuit, got %s", s)
+	}
+	if err := client.Ping(ctx); err != nil {
+		t.Errorf("expected requests to go through, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	var status, health, hits atomic.Int32
+	status.Store(http.StatusBadRequest)
+	client, _ := breakerClient(t, &status, &health, &hits)
+
+	for range 5 {
+		if err := client.Ping(context.Background()); !IsBadRequest(err) {
+			t.Fatalf("expected a bad request error, got %v", err)
+		}
+	}
+	if s := client.CircuitState(); s != CircuitClosed {
+		t.Errorf("expected 4xx responses not to trip the circuit, got %s", s)
+	}
+
+	// A success in between resets the count of consecutive failures.
+	for _, code := range []int32{http.StatusBadGateway, http.StatusOK, http.StatusBadGateway} {
+		status.Store(code)
+		client.Ping(context.Background())
+	}
+	if s := client.CircuitState(); s != CircuitClosed {
+		t.Errorf("expected non-consecutive failures not to trip the circuit, got %s", s)
+	}
+}
+
+func TestCircuitBreaker_HealthProbe(t *testing.T) {
+	var status, health, hits atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	health.Store(http.StatusServiceUnavailable)
+	client, now := breakerClient(t, &status, &health, &hits, WithCircuitBreakerHealthProbe())
+	ctx := context.Background()
+
+	client.Ping(ctx)
+	client.Ping(ctx)
+	status.Store(http.StatusOK)
+
+	// The failing health check keeps the circuit open without sending the
+	// request.
+	*now = now.Add(time.Minute)
+	var open *CircuitOpenError
+	if err := client.Ping(ctx); !errors.As(err, &open) {
+		t.Fatalf("expected *CircuitOpenError, got %v", err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("expected the request not to be sent, got %d hits", n)
+	}
+
+	health.Store(http.StatusOK)
+	*now = now.Add(time.Minute)
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("expected the request to be sent after a passing health check, got %v", err)
+	}
+	if s := client.CircuitState(); s != CircuitClosed {
+		t.Errorf("expected closed circuit, got %s", s)
+	}
+}
+
+func TestCircuitState_WithoutBreaker(t *testing.T) {
+	client, err := NewClient("test-api-key")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if s := client.CircuitState(); s != CircuitClosed {
+		t.Errorf("expected closed circuit, got %s", s)
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    RateLimit
+		ok      bool
+	}{
+		{
+			name:    "x-ratelimit with epoch reset",
+			headers: map[string]string{"X-RateLimit-Limit": "100", "X-RateLimit-Remaining": "7", "X-RateLimit-Reset": strconv.FormatInt(now.Add(time.Minute).Unix(), 10)},
+			want:    RateLimit{Limit: 100, Remaining: 7, Reset: now.Add(time.Minute)},
+			ok:      true,
+		},
+		{
+			name:    "x-rate-limit with delta reset",
+			headers: map[string]string{"X-Rate-Limit-Limit": "50", "X-Rate-Limit-Remaining": "0", "X-Rate-Limit-Reset": "30"},
+			want:    RateLimit{Limit: 50, Remaining: 0, Reset: now.Add(30 * time.Second)},
+			ok:      true,
+		},
+		{
+			name:    "lower case",
+			headers: map[string]string{"x-ratelimit-remaining": "3", "x-ratelimit-reset": "5"},
+			want:    RateLimit{Remaining: 3, Reset: now.Add(5 * time.Second)},
+			ok:      true,
+		},
+		{
+			name:    "ietf draft",
+			headers: map[string]string{"RateLimit-Limit": "10", "RateLimit-Remaining": "9", "RateLimit-Reset": "60"},
+			want:    RateLimit{Limit: 10, Remaining: 9, Reset: now.Add(time.Minute)},
+			ok:      true,
+		},
+		{
+			name:    "retry-after date",
+			headers: map[string]string{"Retry-After": now.Add(2 * time.Minute).Format(http.TimeFormat)},
+			want:    RateLimit{Reset: now.Add(2 * time.Minute)},
+			ok:      true,
+		},
+		{
+			name:    "reset preferred over retry-after",
+			headers: map[string]string{"X-RateLimit-Reset": "10", "Retry-After": "120"},
+			want:    RateLimit{Reset: now.Add(10 * time.Second)},
+			ok:      true,
+		},
+		{
+			name:    "x-ratelimit preferred over other spellings",
+			headers: map[string]string{"RateLimit-Remaining": "9", "X-Rate-Limit-Remaining": "8", "X-RateLimit-Remaining": "7"},
+			want:    RateLimit{Remaining: 7},
+			ok:      true,
+		},
+		{
+			name:    "invalid value falls back to the next spelling",
+			headers: map[string]string{"X-RateLimit-Limit": "many", "RateLimit-Limit": "10"},
+			want:    RateLimit{Limit: 10},
+			ok:      true,
+		},
+		{
+			name:    "none",
+			headers: map[string]string{"Content-Type": "application/json", "X-RateLimit-Remaining": "lots"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Set the map directly so the names keep their case.
+			h := http.Header{}
+			for k, v := range tt.headers {
+				h[k] = []string{v}
+			}
+			got, ok := parseRateLimit(h, now)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if got.Limit != tt.want.Limit || got.Remaining != tt.want.Remaining || !got.Reset.Equal(tt.want.Reset) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRateLimit_APIErrorAndMeta(t *testing.T) {
+	var limited atomic.Bool
+	_, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.Header().Set("X-RateLimit-Limit", "100")
+		if limited.Load() {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "60")
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"title":"Too Many Requests","detail":"search quota exhausted"}`))
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"type":"FeatureCollection","features":[]}`))
+	})
+
+	if _, ok := client.LastRateLimit(); ok {
+		t.Error("expected no rate limit before the first call")
+	}
+
+	ctx, meta := WithResponseMeta(context.Background())
+	if _, err := client.SearchCatalogue(ctx, &CatalogueRequest{}); err != nil {
+		t.Fatalf("SearchCatalogue() error = %v", err)
+	}
+	rl, ok := meta.RateLimit()
+	if !ok || rl.Limit != 100 || rl.Remaining != 42 {
+		t.Errorf("expected 42 of 100 remaining in the meta, got %+v (ok=%v)", rl, ok)
+	}
+	if meta.StatusCode() != http.StatusOK || meta.RequestID() != "req-1" {
+		t.Errorf("unexpected meta status %d, request ID %q", meta.StatusCode(), meta.RequestID())
+	}
+
+	limited.Store(true)
+	start := time.Now()
+	_, err := client.SearchCatalogue(context.Background(), &CatalogueRequest{})
+	if !IsRateLimited(err) {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RateLimit == nil {
+		t.Fatalf("expected *APIError with a RateLimit, got %#v", err)
+	}
+	if apiErr.RateLimit.Remaining != 0 || apiErr.RateLimit.Reset.Before(start.Add(59*time.Second)) {
+		t.Errorf("unexpected rate limit %+v", apiErr.RateLimit)
+	}
+	if last, _ := client.LastRateLimit(); last.Remaining != 0 || last.Limit != 100 {
+		t.Errorf("expected the 429 to be the last rate limit, got %+v", last)
+	}
+}
+
+func TestLastRateLimit_Concurrent(t *testing.T) {
+	var n atomic.Int32
+	_, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(int(n.Add(1))))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"type":"FeatureCollection","features":[]}`))
+	})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 5 {
+				if _, err := client.SearchCatalogue(context.Background(), &CatalogueRequest{}); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 5 {
+				client.LastRateLimit()
+			}
+		}()
+	}
+	wg.Wait()
+
+	rl, ok := client.LastRateLimit()
+	if !ok || rl.Remaining < 1 || rl.Remaining > 40 {
+		t.Errorf("expected one of the observed values, got %+v (ok=%v)", rl, ok)
+	}
+}
+
+func TestRateLimitRetry_WaitsUntilReset(t *testing.T) {
+	var hits atomic.Int32
+	server, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "" {
+			t.Error("expected the request body to be replayed")
+		}
+		if hits.Add(1) == 1 {
+			w.Header().Set("X-Rate-Limit-Remaining", "0")
+			w.Header().Set("X-Rate-Limit-Reset", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("X-Rate-Limit-Remaining", "99")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"type":"FeatureCollection","features":[]}`))
+	})
+	client, err := NewClient("test-api-key",
+		WithBaseURL(server.URL),
+		WithTokenURL(server.URL+"/auth/token"),
+		WithRateLimitRetry(2, time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var slept []time.Duration
+	client.rateLimit.now = func() time.Time { return now }
+	client.rateLimit.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+
+	if _, err := client.SearchCatalogue(context.Background(), &CatalogueRequest{}); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if hits.Load() != 2 || len(slept) != 1 || slept[0] != 30*time.Second {
+		t.Errorf("expected one 30s wait before the retry, got %d hits and waits %v", hits.Load(), slept)
+	}
+
+	// A reset beyond maxWait is returned to the caller instead.
+	hits.Store(0)
+	slept = nil
+	client.rateLimit.maxWait = 10 * time.Second
+	_, err = client.SearchCatalogue(context.Background(), &CatalogueRequest{})
+	if !IsRateLimited(err) || hits.Load() != 1 || len(slept) != 0 {
+		t.Errorf("expected the 429 without waiting, got %v after %d hits and waits %v", err, hits.Load(), slept)
+	}
+}
+
+func TestRateLimitRetry_NoResetNotRetried(t *testing.T) {
+	var hits atomic.Int32
+	server, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	client, err := NewClient("test-api-key",
+		WithBaseURL(server.URL),
+		WithTokenURL(server.URL+"/auth/token"),
+		WithRateLimitRetry(3, time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if err := client.Ping(context.Background()); !IsRateLimited(err) {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("expected no blind retry, got %d hits", n)
+	}
+}
+
+func TestDiffFeasibility(t *testing.T) {
+	t0 := time.Date(2025, 3, 3, 5, 12, 0, 0, time.UTC)
+	acq := func(beam string, orbit int, start time.Time, incidence, coverage float64) Feature {
+		return Feature{Type: "Feature", Properties: AcquisitionProperties{
+			AcquisitionID:  fmt.Sprintf("%s-%d-%d", beam, orbit, start.Unix()),
+			Mission:        MissionTSX,
+			BeamID:         beam,
+			RelativeOrbit:  orbit,
+			StartTime:      start,
+			StopTime:       start.Add(10 * time.Second),
+			IncidenceAngle: incidence,
+			Coverage:       coverage,
+		}}
+	}
+	fc := func(features ...Feature) *FeatureCollection {
+		return &FeatureCollection{Type: "FeatureCollection", Features: features}
+	}
+	week := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name                      string
+		old, new                  *FeatureCollection
+		opts                      DiffOptions
+		added, removed, unchanged int
+		changed                   []string // properties of the single changed feature
+	}{
+		{
+			name:      "identical",
+			old:       fc(acq("strip_004", 42, t0, 30, 100)),
+			new:       fc(acq("strip_004", 42, t0, 30, 100)),
+			unchanged: 1,
+		},
+		{
+			name:      "start shifted within tolerance",
+			old:       fc(acq("strip_004", 42, t0, 30, 100)),
+			new:       fc(acq("strip_004", 42, t0.Add(4*time.Second), 30.1, 100)),
+			unchanged: 1,
+		},
+		{
+			name:    "start shifted beyond tolerance",
+			old:     fc(acq("strip_004", 42, t0, 30, 100)),
+			new:     fc(acq("strip_004", 42, t0.Add(2*time.Minute), 30, 100)),
+			added:   1,
+			removed: 1,
+		},
+		{
+			name:      "custom tolerance",
+			old:       fc(acq("strip_004", 42, t0, 30, 100)),
+			new:       fc(acq("strip_004", 42, t0.Add(2*time.Minute), 30, 100)),
+			opts:      DiffOptions{TimeTolerance: 5 * time.Minute},
+			unchanged: 1,
+		},
+		{
+			name:  "new pass",
+			old:   fc(acq("strip_004", 42, t0, 30, 100)),
+			new:   fc(acq("strip_004", 42, t0, 30, 100), acq("strip_007", 118, t0.Add(week), 41, 100)),
+			added: 1, unchanged: 1,
+		},
+		{
+			name:    "lost pass",
+			old:     fc(acq("strip_004", 42, t0, 30, 100), acq("strip_004", 42, t0.Add(11*24*time.Hour), 30, 100)),
+			new:     fc(acq("strip_004", 42, t0, 30, 100)),
+			removed: 1, unchanged: 1,
+		},
+		{
+			name:    "incidence angle changed",
+			old:     fc(acq("strip_004", 42, t0, 30, 100)),
+			new:     fc(acq("strip_004", 42, t0.Add(time.Second), 31.2, 100)),
+			changed: []string{"incidenceAngle"},
+		},
+		{
+			name:    "coverage changed",
+			old:     fc(acq("strip_004", 42, t0, 30, 80)),
+			new:     fc(acq("strip_004", 42, t0, 30, 95)),
+			changed: []string{"coverage"},
+		},
+		{
+			name:      "change below custom threshold",
+			old:       fc(acq("strip_004", 42, t0, 30, 80)),
+			new:       fc(acq("strip_004", 42, t0, 32, 95)),
+			opts:      DiffOptions{IncidenceAngleThreshold: 5, CoverageThreshold: 20},
+			unchanged: 1,
+		},
+		{
+			name:      "missing optional properties",
+			old:       fc(acq("strip_004", 0, t0, 30, 80)),
+			new:       fc(acq("strip_004", 0, t0, 0, 0)),
+			unchanged: 1,
+		},
+		{
+			name:    "different relative orbit",
+			old:     fc(acq("strip_004", 42, t0, 30, 100)),
+			new:     fc(acq("strip_004", 43, t0, 30, 100)),
+			added:   1,
+			removed: 1,
+		},
+		{
+			name: "custom identity",
+			old:  fc(acq("strip_004", 42, t0, 30, 100)),
+			new:  fc(acq("strip_005", 42, t0, 30, 100)),
+			opts: DiffOptions{Identity: func(p AcquisitionProperties) string {
+				return strconv.Itoa(p.RelativeOrbit)
+			}},
+			unchanged: 1,
+		},
+		{
+			name:  "nil old",
+			new:   fc(acq("strip_004", 42, t0, 30, 100), acq("strip_004", 42, t0.Add(week), 30, 100)),
+			added: 2,
+		},
+		{
+			name:    "empty new",
+			old:     fc(acq("strip_004", 42, t0, 30, 100)),
+			new:     fc(),
+			removed: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := DiffFeasibility(tt.old, tt.new, tt.opts)
+			if err != nil {
+				t.Fatalf("DiffFeasibility: %v", err)
+			}
+			if len(diff.Added) != tt.added || len(diff.Removed) != tt.removed || diff.Unchanged != tt.unchanged {
+				t.Errorf("got %d added, %d removed, %d unchanged; want %d, %d, %d",
+					len(diff.Added), len(diff.Removed), diff.Unchanged, tt.added, tt.removed, tt.unchanged)
+			}
+			var changed []string
+			for _, c := range diff.Changed {
+				for _, d := range c.Deltas {
+					changed = append(changed, d.Property)
+				}
+			}
+			if !slices.Equal(changed, tt.changed) {
+				t.Errorf("changed properties = %v, want %v", changed, tt.changed)
+			}
+			if diff.Empty() != (tt.added+tt.removed+len(tt.changed) == 0) {
+				t.Errorf("Empty() = %v", diff.Empty())
+			}
+		})
+	}
+}
+
+func TestDiffFeasibility_PreservesPassOrder(t *testing.T) {
+	t0 := time.Date(2025, 3, 3, 5, 12, 0, 0, time.UTC)
+	feature := func(start time.Time, incidence float64) Feature {
+		return Feature{Properties: AcquisitionProperties{BeamID: "spot_031", RelativeOrbit: 7, StartTime: start, IncidenceAngle: incidence}}
+	}
+	// Two passes 20s apart in the same group both shift by 12s; each must
+	// pair with its own counterpart, not the nearer neighbour's.
+	before := &FeatureCollection{Features: []Feature{feature(t0, 30), feature(t0.Add(20*time.Second), 35)}}
+	after := &FeatureCollection{Features: []Feature{feature(t0.Add(32*time.Second), 35), feature(t0.Add(12*time.Second), 30)}}
+
+	diff, err := DiffFeasibility(before, after, DiffOptions{TimeTolerance: 15 * time.Second})
+	if err != nil {
+		t.Fatalf("DiffFeasibility: %v", err)
+	}
+	if diff.Unchanged != 2 || !diff.Empty() {
+		t.Errorf("expected both passes to pair unchanged, got %s", diff.Summary())
+	}
+}
+
+func TestDiffFeasibility_SummaryAndJSON(t *testing.T) {
+	t0 := time.Date(2025, 3, 3, 5, 12, 0, 0, time.UTC)
+	props := AcquisitionProperties{Satellite: SatelliteTSX1, BeamID: "strip_004", RelativeOrbit: 42, StartTime: t0, IncidenceAngle: 30, Coverage: 80}
+	moved := props
+	moved.StartTime = t0.Add(3 * time.Second)
+	moved.IncidenceAngle = 31.5
+	gone := props
+	gone.RelativeOrbit = 43
+	gone.Coverage = 0
+
+	diff, err := DiffFeasibility(
+		&FeatureCollection{Features: []Feature{{Properties: props}, {Properties: gone}}},
+		&FeatureCollection{Features: []Feature{{Properties: moved}}},
+		DiffOptions{},
+	)
+	if err != nil {
+		t.Fatalf("DiffFeasibility: %v", err)
+	}
+	want := "0 added, 1 removed, 1 changed, 0 unchanged\n" +
+		"- TSX-1 beam strip_004 orbit 43 at 2025-03-03T05:12:00Z, incidence 30.00\n" +
+		"~ TSX-1 beam strip_004 orbit 42 at 2025-03-03T05:12:03Z, incidence 31.50, coverage 80.0%: incidenceAngle 30.00 -> 31.50"
+	if got := diff.Summary(); got != want {
+		t.Errorf("Summary() =\n%s\nwant\n%s", got, want)
+	}
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var decoded struct {
+		Added   []json.RawMessage `json:"added"`
+		Changed []struct {
+			Identity   string          `json:"identity"`
+			StartShift float64         `json:"startShiftSeconds"`
+			Deltas     []PropertyDelta `json:"deltas"`
+		} `json:"changed"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if decoded.Added == nil || !strings.Contains(string(data), `"added":[]`) {
+		t.Errorf("expected an empty added array, got %s", data)
+	}
+	if len(decoded.Changed) != 1 || decoded.Changed[0].Identity != "strip_004/42" || decoded.Changed[0].StartShift != 3 ||
+		decoded.Changed[0].Deltas[0] != (PropertyDelta{Property: "incidenceAngle", Old: 30, New: 31.5}) {
+		t.Errorf("unexpected changed entry in %s", data)
+	}
+}
+
+func TestDiffFeasibility_InvalidOptions(t *testing.T) {
+	if _, err := DiffFeasibility(nil, nil, DiffOptions{TimeTolerance: -time.Second}); err == nil {
+		t.Error("expected an error for a negative tolerance")
+	}
+}
+
+func TestItemStatusEventSlice_Durations(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	// Out of order, as the API may return it.
+	history := ItemStatusEventSlice{
+		{Status: ItemStatusProcessing, Timestamp: t0.Add(5 * time.Hour)},
+		{Status: ItemStatusPlanned, Timestamp: t0},
+		{Status: ItemStatusDelivered, Timestamp: t0.Add(8 * time.Hour)},
+		{Status: ItemStatusAcquired, Timestamp: t0.Add(4 * time.Hour)},
+		{Status: ItemStatusProcessing, Timestamp: t0.Add(6*time.Hour + 30*time.Minute)},
+		{Status: ItemStatusDelivering, Timestamp: t0.Add(7 * time.Hour)},
+		{Status: ItemStatusProcessed, Timestamp: t0.Add(6 * time.Hour)},
+	}
+
+	durations := []struct {
+		status ItemStatus
+		want   time.Duration
+	}{
+		{ItemStatusPlanned, 4 * time.Hour},
+		{ItemStatusAcquired, time.Hour},
+		{ItemStatusProcessing, time.Hour + 30*time.Minute},
+		{ItemStatusProcessed, 30 * time.Minute},
+		{ItemStatusCancelled, 0},
+	}
+	for _, tt := range durations {
+		if got := history.DurationIn(tt.status); got != tt.want {
+			t.Errorf("DurationIn(%s) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+
+	if d, ok := history.TimeTo(ItemStatusDelivered); !ok || d != 8*time.Hour {
+		t.Errorf("TimeTo(delivered) = %v, %v; want 8h, true", d, ok)
+	}
+	if d, ok := history.TimeTo(ItemStatusProcessing); !ok || d != 5*time.Hour {
+		t.Errorf("TimeTo(processing) = %v, %v; want 5h, true", d, ok)
+	}
+	if d, ok := history.TimeTo(ItemStatusCancelled); ok || d != 0 {
+		t.Errorf("TimeTo(cancelled) = %v, %v; want 0, false", d, ok)
+	}
+	if history[0].Status != ItemStatusProcessing {
+		t.Error("DurationIn and TimeTo must not reorder the slice")
+	}
+}
+
+func TestItemStatusEventSlice_NotYetDelivered(t *testing.T) {
+	start := time.Now().Add(-3 * time.Hour)
+	history := ItemStatusEventSlice{
+		{Status: ItemStatusPlanned, Timestamp: start},
+		{Status: ItemStatusProcessing, Timestamp: start.Add(time.Hour)},
+	}
+	if got := history.DurationIn(ItemStatusProcessing); got < 2*time.Hour {
+		t.Errorf("DurationIn(processing) = %v, want at least 2h", got)
+	}
+	if _, ok := history.TimeTo(ItemStatusDelivered); ok {
+		t.Error("TimeTo(delivered) reported an undelivered item as delivered")
+	}
+	if _, ok := ItemStatusEventSlice(nil).TimeTo(ItemStatusPlanned); ok {
+		t.Error("TimeTo on an empty history should report false")
+	}
+}
+
+func TestOrderTimeline(t *testing.T) {
+	order := &Order{
+		Items: []Item{
+			{ItemID: "a", StatusHistory: []ItemStatusEvent{
+				{Status: ItemStatusPlanned, Timestamp: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)},
+				{Status: ItemStatusAcquired, Timestamp: time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)},
+			}},
+			{ItemID: "b", StatusHistory: []ItemStatusEvent{
+				{Status: ItemStatusPlanned, Timestamp: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)},
+			}},
+			{ItemID: "c"},
+		},
+	}
+
+	timeline := order.Timeline()
+	want := []string{"a", "b", "a"}
+	if len(timeline) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(timeline))
+	}
+	for i, id := range want {
+		if timeline[i].ItemID != id {
+			t.Errorf("event %d: expected item %s, got %s", i, id, timeline[i].ItemID)
+		}
+	}
+
+}
+
+// exportCollection returns search results for the GeoJSON export tests: two
+// acquisitions and one without a geometry.
+func exportCollection() *FeatureCollection {
+	start := time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)
+	return &FeatureCollection{
+		Type: "FeatureCollection",
+		Features: []Feature{
+			{
+				Type: "Feature",
+				Geometry: NewPolygonGeometry([][][2]float64{{
+					{16.3, 48.1}, {16.5, 48.1}, {16.5, 48.25}, {16.3, 48.25}, {16.3, 48.1},
+				}}),
+				Properties: AcquisitionProperties{
+					ItemID: "item-1", AcquisitionID: "TSX-1_SM_S_srA_20240501T101500", Mission: MissionTSX,
+					SensorMode: SensorModeStripmap, PolarizationChannels: PolarizationHH, StartTime: start,
+					StopTime: start.Add(8 * time.Second), BeamID: "strip_005", PathDirection: PathDirectionAscending,
+					LookDirection: LookDirectionRight, IncidenceAngle: 34.56,
+				},
+			},
+			{
+				Type:       "Feature",
+				Properties: AcquisitionProperties{ItemID: "item-2", Mission: MissionTSX},
+			},
+			{
+				Type: "Feature",
+				Geometry: NewPolygonGeometry([][][2]float64{{
+					{16.31, 48.12}, {16.49, 48.12}, {16.49, 48.24}, {16.31, 48.24}, {16.31, 48.12},
+				}}),
+				Properties: AcquisitionProperties{
+					ItemID: "item-3", AcquisitionID: "PAZ1_SL_S_spotlight_20240502T053000", Mission: MissionPAZ,
+					SensorMode: SensorModeSpotlight, PolarizationChannels: PolarizationVV, StartTime: start.Add(19*time.Hour + 15*time.Minute),
+					StopTime: start.Add(19*time.Hour + 15*time.Minute + 5*time.Second), BeamID: "spot_042",
+					PathDirection: PathDirectionDescending, LookDirection: LookDirectionLeft, IncidenceAngle: 41.2, Coverage: 100,
+				},
+			},
+		},
+	}
+}
+
+func TestFeatureCollectionToGeoJSON_Styled(t *testing.T) {
+	fc := exportCollection()
+	got, err := fc.ToGeoJSON(ExportOptions{StyleBy: StyleByMission, Titles: true, Indent: true})
+	if err != nil {
+		t.Fatalf("ToGeoJSON() error = %v", err)
+	}
+	golden, err := os.ReadFile("testdata/catalogue_styled.geojson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got)+"\n" != string(golden) {
+		t.Errorf("GeoJSON output differs from golden file:\n%s", got)
+	}
+
+	// Geometries pass through unmodified.
+	out, skipped, err := fc.GeoJSON(ExportOptions{})
+	if err != nil {
+		t.Fatalf("GeoJSON() error = %v", err)
+	}
+	if skipped != 1 || len(out.Features) != 2 {
+		t.Fatalf("expected 2 features and 1 skipped, got %d and %d", len(out.Features), skipped)
+	}
+	if !orb.Equal(out.Features[1].Geometry, fc.Features[2].Geometry.Geometry()) {
+		t.Errorf("geometry changed: %v", out.Features[1].Geometry)
+	}
+	if _, ok := out.Features[0].Properties["fill"]; ok {
+		t.Error("unstyled export should not carry simplestyle properties")
+	}
+	if out.Features[0].Properties["acquisitionId"] != "TSX-1_SM_S_srA_20240501T101500" {
+		t.Errorf("expected flattened acquisition properties, got %v", out.Features[0].Properties)
+	}
+}
+
+func TestFeatureCollectionToGeoJSON_PathDirectionColors(t *testing.T) {
+	out, _, err := exportCollection().GeoJSON(ExportOptions{
+		StyleBy: StyleByPathDirection,
+		Colors:  map[string]string{string(PathDirectionDescending): "#000000"},
+	})
+	if err != nil {
+		t.Fatalf("GeoJSON() error = %v", err)
+	}
+	if got := out.Features[0].Properties["stroke"]; got != DefaultStyleColors[string(PathDirectionAscending)] {
+		t.Errorf("ascending stroke = %v, want the default color", got)
+	}
+	if got := out.Features[1].Properties["fill"]; got != "#000000" {
+		t.Errorf("descending fill = %v, want the configured color", got)
+	}
+	if _, ok := out.Features[0].Properties["title"]; ok {
+		t.Error("titles should only be added with Titles set")
+	}
+}
+
+func TestExportGeoJSONFile(t *testing.T) {
+	fc := exportCollection()
+	path := filepath.Join(t.TempDir(), "out.geojson")
+	skipped, err := fc.ExportGeoJSONFile(path, ExportOptions{StyleBy: StyleByMission, Titles: true, Indent: true})
+	if err != nil {
+		t.Fatalf("ExportGeoJSONFile() error = %v", err)
+	}
+	if skipped != 1 {
+		t.Errorf("expected 1 skipped feature, got %d", skipped)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile("testdata/catalogue_styled.geojson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(golden) {
+		t.Errorf("exported file differs from golden file:\n%s", got)
+	}
+
+	// The output is a standard FeatureCollection.
+	parsed, err := geojson.UnmarshalFeatureCollection(got)
+	if err != nil {
+		t.Fatalf("exported file is not a FeatureCollection: %v", err)
+	}
+	if len(parsed.Features) != 2 {
+		t.Errorf("expected 2 features, got %d", len(parsed.Features))
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	const oldPW, newPW = "old-Secret-123!x", "New-Secret-456!xyz"
+	var got ChangePasswordRequest
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/user/password" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer server.Close()
+
+	if err := client.ChangePassword(context.Background(), oldPW, newPW); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if got.OldPassword != "b2xkLVNlY3JldC0xMjMheA==" {
+		t.Errorf("oldPassword = %q, want Base64 of the plain text", got.OldPassword)
+	}
+	if got.Password != "TmV3LVNlY3JldC00NTYheHl6" {
+		t.Errorf("password = %q, want Base64 of the plain text", got.Password)
+	}
+}
+
+func TestChangePasswordErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   PasswordErrorReason
+	}{
+		{"forbidden", http.StatusForbidden, `{"type":"/problems/forbidden","title":"Forbidden","detail":"old password does not match"}`, PasswordWrongOld},
+		{"old password parameter", http.StatusBadRequest, `{"type":"/problems/validation","title":"Bad Request","parameters":[{"name":"oldPassword","reason":"does not match"}]}`, PasswordWrongOld},
+		{"new password parameter", http.StatusBadRequest, `{"type":"/problems/validation","title":"Bad Request","parameters":[{"name":"password","reason":"was used before"}]}`, PasswordPolicyViolation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			})
+			defer server.Close()
+
+			err := client.ChangePassword(context.Background(), "wrong", "New-Secret-456!xyz")
+			var perr *PasswordError
+			if !errors.As(err, &perr) {
+				t.Fatalf("error = %v, want *PasswordError", err)
+			}
+			if perr.Reason != tt.want {
+				t.Errorf("Reason = %s, want %s", perr.Reason, tt.want)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Errorf("error should wrap the %d APIError, got %v", tt.status, err)
+			}
+		})
+	}
+
+	t.Run("server error is not a password error", func(t *testing.T) {
+		server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		defer server.Close()
+
+		err := client.ChangePassword(context.Background(), "old", "New-Secret-456!xyz")
+		var perr *PasswordError
+		if err == nil || errors.As(err, &perr) {
+			t.Errorf("error = %v, want a plain APIError", err)
+		}
+	})
+}
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	tests := []struct {
+		password string
+		ok       bool
+	}{
+		{"New-Secret-456!xyz", true},
+		{"Sh0rt!pass", false},
+		{"no-upper-case-123!", false},
+		{"NO-LOWER-CASE-123!", false},
+		{"No-Digits-At-All!!", false},
+		{"NoSpecialChars12345", false},
+	}
+	for _, tt := range tests {
+		err := CheckPasswordPolicy(tt.password)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckPasswordPolicy(%q) = %v, want ok=%v", tt.password, err, tt.ok)
+		}
+		var perr *PasswordError
+		if err != nil && (!errors.As(err, &perr) || perr.Reason != PasswordPolicyViolation) {
+			t.Errorf("CheckPasswordPolicy(%q) = %v, want a policy PasswordError", tt.password, err)
+		}
+	}
+
+	// A weak password is refused before any request is sent.
+	var hits atomic.Int32
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer server.Close()
+	if err := client.ChangePassword(context.Background(), "old", "weak"); err == nil {
+		t.Error("expected weak password to be refused")
+	}
+	if hits.Load() != 0 {
+		t.Errorf("expected no request, got %d", hits.Load())
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	var got map[string]any
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/user/password/reset" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer server.Close()
+
+	if err := client.ResetPassword(context.Background(), "john.doe@company.com"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	if got["username"] != "john.doe@company.com" {
+		t.Errorf("username = %v", got["username"])
+	}
+}
+
+func TestUpdateNotification(t *testing.T) {
+	var bodies []string
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/user/notifications/n-1" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		var req UpdateNotificationRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		bodies = append(bodies, string(req.Status))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"n-1","title":"Maintenance","status":%q}`, req.Status)
+	})
+	defer server.Close()
+
+	n, err := client.UpdateNotification(context.Background(), "n-1", true)
+	if err != nil {
+		t.Fatalf("UpdateNotification() error = %v", err)
+	}
+	if n.Status != NotificationRead {
+		t.Errorf("Status = %s, want read", n.Status)
+	}
+	if _, err := client.UpdateNotification(context.Background(), "n-1", false); err != nil {
+		t.Fatalf("UpdateNotification() error = %v", err)
+	}
+	if !slices.Equal(bodies, []string{"read", "unread"}) {
+		t.Errorf("sent statuses %v, want [read unread]", bodies)
+	}
+}
+
+func TestDeleteNotification(t *testing.T) {
+	server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete && r.URL.Path == "/user/notifications/n-1":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete && r.URL.Path == "/user/notifications/maint-1":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"type":"/problems/bad-request","title":"Bad Request","detail":"maintenance notifications cannot be deleted"}`)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	if err := client.DeleteNotification(context.Background(), "n-1"); err != nil {
+		t.Fatalf("DeleteNotification() error = %v", err)
+	}
+	if err := client.DeleteNotification(context.Background(), "maint-1"); !IsBadRequest(err) {
+		t.Errorf("DeleteNotification(maintenance) error = %v, want 400", err)
+	}
+}
+
+func TestPasswordExpiry(t *testing.T) {
+	u := UserInfo{PWExpirationDate: "2025-03-31"}
+	got, err := u.PasswordExpiry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The expiration date is the last day the password can be used.
+	if want := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("PasswordExpiry() = %v, want %v", got, want)
+	}
+
+	if got, err := (&UserInfo{}).PasswordExpiry(); err != nil || !got.IsZero() {
+		t.Errorf("no expiration date: got %v, %v", got, err)
+	}
+	if _, err := (&UserInfo{PWExpirationDate: "31/03/2025"}).PasswordExpiry(); err == nil {
+		t.Error("expected error for malformed date")
+	}
+}
+
+func TestPasswordExpiresSoon(t *testing.T) {
+	today := time.Now().UTC()
+	tests := []struct {
+		name   string
+		user   UserInfo
+		within time.Duration
+		want   bool
+	}{
+		{"expires in ten days, within a week", UserInfo{PWExpirationDate: today.AddDate(0, 0, 10).Format(time.DateOnly)}, 7 * 24 * time.Hour, false},
+		{"expires in ten days, within two weeks", UserInfo{PWExpirationDate: today.AddDate(0, 0, 10).Format(time.DateOnly)}, 14 * 24 * time.Hour, true},
+		{"already expired", UserInfo{PWExpirationDate: today.AddDate(0, 0, -2).Format(time.DateOnly)}, 0, true},
+		{"never expires", UserInfo{}, 365 * 24 * time.Hour, false},
+		{"change needed", UserInfo{PWChangeNeeded: true}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(tt.user)
+			})
+			defer server.Close()
+
+			soon, expires, err := client.PasswordExpiresSoon(context.Background(), tt.within)
+			if err != nil {
+				t.Fatalf("PasswordExpiresSoon() error = %v", err)
+			}
+			if soon != tt.want {
+				t.Errorf("PasswordExpiresSoon() = %v, want %v (expires %v)", soon, tt.want, expires)
+			}
+			if (tt.user.PWExpirationDate == "") != expires.IsZero() {
+				t.Errorf("expires = %v", expires)
+			}
+		})
+	}
+}
+
+func TestFeasibilityTemplateRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	req := &FeasibilityRequest{
+		AOI:                  NewPointGeometry(9.9, 53.5),
+		Time:                 TimeRange{From: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		FeasibilityLevel:     FeasibilityLevelComplete,
+		SensorMode:           SensorModeStripmap,
+		Mission:              []any{string(MissionTSX), string(MissionPAZ)},
+		Priority:             PriorityPriority,
+		PolarizationChannels: PolarizationHH,
+		IncidenceAngle:       &IncidenceAngleRange{Minimum: 20, Maximum: 45},
+		Customer:             "harbour-authority",
+	}
+	tpl, err := NewFeasibilityTemplate("coastal-monitoring", req)
+	if err != nil {
+		t.Fatalf("NewFeasibilityTemplate: %v", err)
+	}
+	tpl.Description = "Weekly harbour stripmap"
+	if err := SaveTemplate(dir, tpl); err != nil {
+		t.Fatalf("SaveTemplate: %v", err)
+	}
+	if err := SaveTemplate(dir, &FeasibilityTemplate{Name: "arctic", FeasibilityLevel: FeasibilityLevelSimple, SensorMode: SensorModeSpotlight}); err != nil {
+		t.Fatalf("SaveTemplate: %v", err)
+	}
+
+	got, err := LoadTemplate(dir, "coastal-monitoring")
+	if err != nil {
+		t.Fatalf("LoadTemplate: %v", err)
+	}
+	if got.Version != FeasibilityTemplateVersion || got.Description != tpl.Description ||
+		got.SensorMode != SensorModeStripmap || got.Priority != PriorityPriority ||
+		got.Customer != "harbour-authority" || *got.IncidenceAngle != *tpl.IncidenceAngle {
+		t.Errorf("loaded template = %+v, want %+v", got, tpl)
+	}
+	if missions, _ := got.Mission.([]any); len(missions) != 2 || missions[1] != string(MissionPAZ) {
+		t.Errorf("mission = %v", got.Mission)
+	}
+
+	names, err := ListTemplates(dir)
+	if err != nil || !slices.Equal(names, []string{"arctic", "coastal-monitoring"}) {
+		t.Errorf("ListTemplates = %v, %v", names, err)
+	}
+	if names, err := ListTemplates(filepath.Join(dir, "missing")); err != nil || len(names) != 0 {
+		t.Errorf("ListTemplates of a missing dir = %v, %v", names, err)
+	}
+
+	if _, err := LoadTemplate(dir, "unknown"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadTemplate of a missing template err = %v", err)
+	}
+	for _, name := range []string{"", "../escape", ".hidden"} {
+		if err := SaveTemplate(dir, &FeasibilityTemplate{Name: name}); err == nil {
+			t.Errorf("SaveTemplate(%q) succeeded", name)
+		}
+	}
+}
+
+func TestFeasibilityTemplateFields(t *testing.T) {
+	jsonNames := func(typ reflect.Type) map[string]bool {
+		names := make(map[string]bool)
+		for i := range typ.NumField() {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			names[name] = true
+		}
+		return names
+	}
+	tplNames := jsonNames(reflect.TypeFor[FeasibilityTemplate]())
+	for name := range jsonNames(reflect.TypeFor[FeasibilityRequest]()) {
+		if name != "aoi" && name != "time" && !tplNames[name] {
+			t.Errorf("FeasibilityTemplate has no %q field", name)
+		}
+	}
+
+	req := stationFeasibilityRequest("KIR")
+	req.MinimumCoverage = 80
+	req.GainAttenuation = 2
+	tpl, err := NewFeasibilityTemplate("stations", req)
+	if err != nil {
+		t.Fatalf("NewFeasibilityTemplate: %v", err)
+	}
+	if tpl.ReceivingStation != "KIR" || tpl.MinimumCoverage != 80 || tpl.GainAttenuation != 2 {
+		t.Errorf("template = %+v, want the request parameters", tpl)
+	}
+	if _, err := NewFeasibilityTemplate("nil", nil); err == nil {
+		t.Error("NewFeasibilityTemplate(nil) succeeded")
+	}
+}
+
+func TestLoadTemplateRejectsOtherVersions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"old":     `{"name": "old", "feasibilityLevel": "simple", "sensorMode": "SAR_SM_S"}`,
+		"future":  `{"version": 2, "name": "future", "feasibilityLevel": "simple", "sensorMode": "SAR_SM_S"}`,
+		"withaoi": `{"version": 1, "name": "withaoi", "aoi": {"type": "Point", "coordinates": [0, 0]}}`,
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, version := range map[string]int{"old": 0, "future": 2} {
+		var verr *TemplateVersionError
+		if _, err := LoadTemplate(dir, name); !errors.As(err, &verr) || verr.Version != version {
+			t.Errorf("LoadTemplate(%q) err = %v, want a *TemplateVersionError for version %d", name, err, version)
+		}
+	}
+	if _, err := LoadTemplate(dir, "withaoi"); err == nil || !strings.Contains(err.Error(), "aoi") {
+		t.Errorf("LoadTemplate with an aoi err = %v, want an unknown field error", err)
+	}
+}
+
+func TestApplyTemplate(t *testing.T) {
+	tpl := &FeasibilityTemplate{
+		Name:             "coastal-monitoring",
+		FeasibilityLevel: FeasibilityLevelComplete,
+		SensorMode:       SensorModeStripmap,
+		Priority:         PriorityStandard,
+		IncidenceAngle:   &IncidenceAngleRange{Minimum: 20, Maximum: 45},
+	}
+	aoi := NewPointGeometry(9.9, 53.5)
+	tr := TimeRange{
+		From: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	req, err := ApplyTemplate(tpl, aoi, tr)
+	if err != nil {
+		t.Fatalf("ApplyTemplate: %v", err)
+	}
+	if req.AOI != aoi || req.Time != tr {
+		t.Errorf("aoi and time = %v, %v; want the arguments", req.AOI, req.Time)
+	}
+	if req.FeasibilityLevel != FeasibilityLevelComplete || req.SensorMode != SensorModeStripmap || req.Priority != PriorityStandard {
+		t.Errorf("template parameters not applied: %+v", req)
+	}
+
+	// The request is a copy: changing it leaves the template alone.
+	req.IncidenceAngle.Minimum = 30
+	req.Priority = PriorityPriority
+	if tpl.IncidenceAngle.Minimum != 20 || tpl.Priority != PriorityStandard {
+		t.Errorf("template modified through the request: %+v", tpl)
+	}
+
+	if _, err := ApplyTemplate(tpl, nil, tr); err == nil || !strings.Contains(err.Error(), "aoi is required") {
+		t.Errorf("missing aoi err = %v", err)
+	}
+	if _, err := ApplyTemplate(tpl, aoi, TimeRange{From: tr.To, To: tr.From}); err == nil {
+		t.Error("inverted time range was accepted")
+	}
+	if _, err := ApplyTemplate(&FeasibilityTemplate{Name: "empty"}, aoi, tr); err == nil || !strings.Contains(err.Error(), "sensorMode is required") {
+		t.Errorf("incomplete template err = %v", err)
+	}
+}
+
+func TestReadAOIFile(t *testing.T) {
+	dir := t.TempDir()
+	polygon := `{"type": "Polygon", "coordinates": [[[9, 53], [10, 53], [10, 54], [9, 53]]]}`
+	files := map[string]string{
+		"geometry.geojson":   polygon,
+		"feature.geojson":    `{"type": "Feature", "properties": {"name": "harbour"}, "geometry": ` + polygon + `}`,
+		"collection.geojson": `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {}, "geometry": ` + polygon + `}]}`,
+		"two.geojson":        `{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": ` + polygon + `}, {"type": "Feature", "geometry": ` + polygon + `}]}`,
+		"nogeometry.geojson": `{"type": "Feature", "properties": {}, "geometry": null}`,
+		"notgeojson.geojson": `{"name": "harbour"}`,
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"geometry.geojson", "feature.geojson", "collection.geojson"} {
+		aoi, err := ReadAOIFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("ReadAOIFile(%s): %v", name, err)
+			continue
+		}
+		poly, ok := aoi.Geometry().(orb.Polygon)
+		if !ok || len(poly[0]) != 4 || poly[0][1] != (orb.Point{10, 53}) {
+			t.Errorf("ReadAOIFile(%s) = %v, want the polygon", name, aoi.Geometry())
+		}
+	}
+	for _, name := range []string{"two.geojson", "nogeometry.geojson", "notgeojson.geojson", "missing.geojson"} {
+		if _, err := ReadAOIFile(filepath.Join(dir, name)); err == nil {
+			t.Errorf("ReadAOIFile(%s) succeeded", name)
+		}
+	}
+}
+
 func TestNewPolygonGeometry(t *testing.T) {
 	geom := NewPolygonGeometry([][][2]float64{{
 		{9.0, 47.0}, {10.0, 47.0}, {10.0, 48.0}, {9.0, 48.0}, {9.0, 47.0},
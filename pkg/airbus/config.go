@@ -5,41 +5,66 @@ import (
 	"net/http"
 )
 
-// GetConfig retrieves the entire user configuration.
+// GetConfig retrieves the entire user configuration. It is cached when the
+// client was created with WithConfigCache.
 // GET /sar/config
 func (c *Client) GetConfig(ctx context.Context) (*Config, error) {
+	return cachedCall(ctx, c, CacheConfig, c.fetchConfig)
+}
+
+func (c *Client) fetchConfig(ctx context.Context) (*Config, error) {
 	var out Config
 	err := c.DoRaw(ctx, http.MethodGet, c.BaseURL().JoinPath("sar", "config"), nil, http.StatusOK, &out)
 	return &out, err
 }
 
 // GetPermissions retrieves user permissions.
+// Cacheable with WithEndpointCache(CachePermissions, ttl).
 // GET /sar/config/permissions
 func (c *Client) GetPermissions(ctx context.Context) (*Permissions, error) {
+	return cachedCall(ctx, c, CachePermissions, c.fetchPermissions)
+}
+
+func (c *Client) fetchPermissions(ctx context.Context) (*Permissions, error) {
 	var out Permissions
 	err := c.DoRaw(ctx, http.MethodGet, c.BaseURL().JoinPath("sar", "config", "permissions"), nil, http.StatusOK, &out)
 	return &out, err
 }
 
 // GetSettings retrieves user settings.
+// Cacheable with WithEndpointCache(CacheSettings, ttl).
 // GET /sar/config/settings
 func (c *Client) GetSettings(ctx context.Context) (*Settings, error) {
+	return cachedCall(ctx, c, CacheSettings, c.fetchSettings)
+}
+
+func (c *Client) fetchSettings(ctx context.Context) (*Settings, error) {
 	var out Settings
 	err := c.DoRaw(ctx, http.MethodGet, c.BaseURL().JoinPath("sar", "config", "settings"), nil, http.StatusOK, &out)
 	return &out, err
 }
 
 // GetCustomers retrieves available customers (for resellers).
+// Cacheable with WithEndpointCache(CacheCustomers, ttl).
 // GET /sar/config/customers
 func (c *Client) GetCustomers(ctx context.Context) ([]Customer, error) {
+	return cachedCall(ctx, c, CacheCustomers, c.fetchCustomers)
+}
+
+func (c *Client) fetchCustomers(ctx context.Context) ([]Customer, error) {
 	var out []Customer
 	err := c.DoRaw(ctx, http.MethodGet, c.BaseURL().JoinPath("sar", "config", "customers"), nil, http.StatusOK, &out)
 	return out, err
 }
 
 // GetOrderTemplates retrieves available order templates.
+// Cacheable with WithEndpointCache(CacheOrderTemplates, ttl).
 // GET /sar/config/orderTemplates
 func (c *Client) GetOrderTemplates(ctx context.Context) ([]OrderTemplate, error) {
+	return cachedCall(ctx, c, CacheOrderTemplates, c.fetchOrderTemplates)
+}
+
+func (c *Client) fetchOrderTemplates(ctx context.Context) ([]OrderTemplate, error) {
 	var out []OrderTemplate
 	err := c.DoRaw(ctx, http.MethodGet, c.BaseURL().JoinPath("sar", "config", "orderTemplates"), nil, http.StatusOK, &out)
 	return out, err
@@ -55,8 +80,13 @@ func (c *Client) GetAssociatedUsers(ctx context.Context) ([]Association, error)
 
 // GetReceivingStations retrieves allowed receiving stations.
 // This is only relevant for direct-access customers.
+// Cacheable with WithEndpointCache(CacheReceivingStations, ttl).
 // GET /sar/config/receivingStations
 func (c *Client) GetReceivingStations(ctx context.Context) ([]ReceivingStation, error) {
+	return cachedCall(ctx, c, CacheReceivingStations, c.fetchReceivingStations)
+}
+
+func (c *Client) fetchReceivingStations(ctx context.Context) ([]ReceivingStation, error) {
 	var out []ReceivingStation
 	err := c.DoRaw(ctx, http.MethodGet, c.BaseURL().JoinPath("sar", "config", "receivingStations"), nil, http.StatusOK, &out)
 	return out, err
@@ -0,0 +1,176 @@
+package airbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// ListDeliveryConfigs returns the account's delivery configurations.
+// Secrets are never returned.
+// GET /sar/config/deliveries
+func (c *Client) ListDeliveryConfigs(ctx context.Context) ([]DeliveryConfig, error) {
+	var out []DeliveryConfig
+	err := c.DoRaw(ctx, http.MethodGet, c.BaseURL().JoinPath("sar", "config", "deliveries"), nil, http.StatusOK, &out)
+	for i := range out {
+		out[i].clearSecrets()
+	}
+	return out, err
+}
+
+// GetDeliveryConfig retrieves a delivery configuration by ID. Secrets are
+// never returned.
+// GET /sar/config/deliveries/{deliveryConfigId}
+func (c *Client) GetDeliveryConfig(ctx context.Context, id string) (*DeliveryConfig, error) {
+	var out DeliveryConfig
+	err := c.DoRaw(ctx, http.MethodGet, c.BaseURL().JoinPath("sar", "config", "deliveries", id), nil, http.StatusOK, &out)
+	out.clearSecrets()
+	return &out, err
+}
+
+// CreateDeliveryConfig creates a delivery configuration. The configuration is
+// validated before it is sent.
+// POST /sar/config/deliveries
+func (c *Client) CreateDeliveryConfig(ctx context.Context, cfg *DeliveryConfig) (*DeliveryConfig, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	body, err := common.MarshalBody(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var out DeliveryConfig
+	err = c.DoRaw(ctx, http.MethodPost, c.BaseURL().JoinPath("sar", "config", "deliveries"), body, http.StatusOK, &out)
+	out.clearSecrets()
+	return &out, err
+}
+
+// UpdateDeliveryConfig replaces a delivery configuration. Secrets must be
+// sent again unless the configuration uses a key or credentials reference.
+// PUT /sar/config/deliveries/{deliveryConfigId}
+func (c *Client) UpdateDeliveryConfig(ctx context.Context, id string, cfg *DeliveryConfig) (*DeliveryConfig, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	body, err := common.MarshalBody(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var out DeliveryConfig
+	err = c.DoRaw(ctx, http.MethodPut, c.BaseURL().JoinPath("sar", "config", "deliveries", id), body, http.StatusOK, &out)
+	out.clearSecrets()
+	return &out, err
+}
+
+// DeleteDeliveryConfig deletes a delivery configuration.
+// DELETE /sar/config/deliveries/{deliveryConfigId}
+func (c *Client) DeleteDeliveryConfig(ctx context.Context, id string) error {
+	return c.DoRaw(ctx, http.MethodDelete, c.BaseURL().JoinPath("sar", "config", "deliveries", id), nil, http.StatusNoContent, nil)
+}
+
+// Validate checks for configurations the API would reject: a missing name,
+// a type that does not match the target set, or an empty host or bucket.
+func (d *DeliveryConfig) Validate() error {
+	if d == nil {
+		return errors.New("delivery config is nil")
+	}
+	if d.Name == "" {
+		return errors.New("delivery config name is required")
+	}
+	switch d.Type {
+	case DeliveryTypeSFTP:
+		if d.SFTP == nil || d.S3 != nil {
+			return errors.New("SFTP delivery config must set only sftp")
+		}
+		return d.SFTP.validate()
+	case DeliveryTypeS3:
+		if d.S3 == nil || d.SFTP != nil {
+			return errors.New("S3 delivery config must set only s3")
+		}
+		return d.S3.validate()
+	default:
+		return fmt.Errorf("unknown delivery type %q", d.Type)
+	}
+}
+
+func (s *SFTPDelivery) validate() error {
+	if s.Host == "" {
+		return errors.New("sftp host is required")
+	}
+	if s.Port < 0 || s.Port > 65535 {
+		return fmt.Errorf("sftp port %d is out of range", s.Port)
+	}
+	if s.Username == "" {
+		return errors.New("sftp username is required")
+	}
+	if s.KeyRef == "" && s.Password == "" {
+		return errors.New("sftp delivery requires a keyRef or password")
+	}
+	return nil
+}
+
+func (s *S3Delivery) validate() error {
+	if s.Bucket == "" {
+		return errors.New("s3 bucket is required")
+	}
+	if s.Region == "" {
+		return errors.New("s3 region is required")
+	}
+	if s.CredentialsRef == "" && (s.AccessKeyID == "" || s.SecretAccessKey == "") {
+		return errors.New("s3 delivery requires a credentialsRef or an access key pair")
+	}
+	return nil
+}
+
+// String describes the delivery target without secrets.
+func (d DeliveryConfig) String() string {
+	target := "no target"
+	switch {
+	case d.SFTP != nil:
+		target = d.SFTP.String()
+	case d.S3 != nil:
+		target = d.S3.String()
+	}
+	return fmt.Sprintf("%s %q (%s): %s", d.Type, d.Name, d.ID, target)
+}
+
+// String describes the SFTP target without secrets.
+func (s SFTPDelivery) String() string {
+	port := s.Port
+	if port == 0 {
+		port = 22
+	}
+	return fmt.Sprintf("sftp://%s@%s:%d/%s", s.Username, s.Host, port, strings.TrimPrefix(s.Path, "/"))
+}
+
+// String describes the S3 target without secrets.
+func (s S3Delivery) String() string {
+	return fmt.Sprintf("s3://%s/%s (%s)", s.Bucket, s.Prefix, s.Region)
+}
+
+// String returns "[REDACTED]" for a non-empty secret.
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "[REDACTED]"
+}
+
+// GoString keeps secrets out of %#v output.
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+// clearSecrets drops any secret the API echoed back.
+func (d *DeliveryConfig) clearSecrets() {
+	if d.SFTP != nil {
+		d.SFTP.Password = ""
+	}
+	if d.S3 != nil {
+		d.S3.SecretAccessKey = ""
+	}
+}
@@ -1,11 +1,140 @@
 package airbus
 
 import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
 
-// APIError is an alias for common.APIError.
-type APIError = common.APIError
+// maxErrorBody bounds how much of a non-JSON error body is kept on APIError.
+const maxErrorBody = 512
+
+// requestIDHeaders are checked in order for a request or correlation ID to
+// quote in support tickets.
+var requestIDHeaders = []string{"X-Request-Id", "X-Correlation-Id", "X-Amzn-Requestid", "X-Amzn-Trace-Id"}
+
+// APIError is returned for non-2xx SAR-API responses. It is populated from
+// the RFC 7807 problem document the API returns; gateway errors that are not
+// JSON keep a truncated copy of the body in RawBody.
+//
+// APIError unwraps to a *common.APIError, so the shared predicates and
+// errors.As(err, **common.APIError) keep working.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Title      string
+	Detail     string
+	Instance   string
+
+	// Code is a machine-readable error code when the body carries one,
+	// otherwise the HTTP status text.
+	Code string
+	// Message is the most specific human-readable message in the body.
+	Message string
+	// Parameters lists the offending request parameters of a validation error.
+	Parameters []ParameterError
+
+	// RequestID is the request or correlation ID header of the response.
+	RequestID string
+	// RawBody is the response body, truncated when it is not JSON.
+	RawBody string
+
+	// RateLimit is the quota reported in the response's rate limit headers,
+	// notably on 429 responses; nil if there were none. Wait until
+	// RateLimit.Reset before retrying.
+	RateLimit *RateLimit
+}
+
+// ParameterError describes a single invalid request parameter.
+type ParameterError struct {
+	Name   string `json:"name"`
+	Path   string `json:"path,omitempty"`
+	Reason string `json:"reason"`
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s (%d)", e.Code, e.StatusCode)
+	if e.Message != "" && e.Message != e.Code {
+		msg += ": " + e.Message
+	}
+	for _, p := range e.Parameters {
+		msg += fmt.Sprintf(" [%s: %s]", cmp.Or(p.Name, p.Path), p.Reason)
+	}
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the equivalent *common.APIError.
+func (e *APIError) Unwrap() error {
+	return &common.APIError{
+		StatusCode: e.StatusCode,
+		Code:       e.Code,
+		Message:    e.Message,
+		Detail:     e.Detail,
+		RawBody:    e.RawBody,
+	}
+}
+
+// requestIDFromHeader returns the first request or correlation ID header
+// found in h, or "" if there is none.
+func requestIDFromHeader(h http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := h.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// parseError builds an *APIError from a non-2xx response.
+func parseError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  requestIDFromHeader(resp.Header),
+		RawBody:    string(body),
+	}
+	if rl, ok := parseRateLimit(resp.Header, time.Now()); ok {
+		e.RateLimit = &rl
+	}
+
+	var doc struct {
+		Type       string           `json:"type"`
+		Title      string           `json:"title"`
+		Detail     string           `json:"detail"`
+		Instance   string           `json:"instance"`
+		Code       string           `json:"code"`
+		Error      string           `json:"error"`
+		Message    string           `json:"message"`
+		Parameters []ParameterError `json:"parameters"`
+	}
+	if err := json.Unmarshal(body, &doc); err == nil {
+		e.Type, e.Title, e.Detail, e.Instance = doc.Type, doc.Title, doc.Detail, doc.Instance
+		e.Parameters = doc.Parameters
+		e.Code = doc.Code
+		e.Message = cmp.Or(doc.Message, doc.Detail, doc.Title, doc.Error)
+	} else if len(body) > 0 {
+		text := strings.TrimSpace(string(body))
+		if len(text) > maxErrorBody {
+			// Cutting may split a multi-byte rune; drop the partial rune.
+			text = strings.ToValidUTF8(text[:maxErrorBody], "") + "..."
+		}
+		e.RawBody = text
+	}
+	e.Code = cmp.Or(e.Code, http.StatusText(resp.StatusCode), strconv.Itoa(resp.StatusCode))
+	return e
+}
 
 // Error checking functions from common package.
 var (
@@ -36,3 +165,174 @@ var (
 	// IsConflict returns true if the error is a 409 Conflict error.
 	IsConflict = common.IsConflict
 )
+
+// IsQuotaExceeded returns true if the error reports an exhausted order or
+// acquisition quota: a 402 response, or any 4xx whose code or message
+// mentions a quota.
+func IsQuotaExceeded(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.StatusCode == http.StatusPaymentRequired {
+		return true
+	}
+	if apiErr.StatusCode < 400 || apiErr.StatusCode >= 500 {
+		return false
+	}
+	for _, s := range []string{apiErr.Code, apiErr.Type, apiErr.Title, apiErr.Message} {
+		if strings.Contains(strings.ToLower(s), "quota") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidation returns true if the API rejected the request parameters: a
+// 400 or 422 response, or any error listing invalid parameters.
+func IsValidation(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusBadRequest ||
+		apiErr.StatusCode == http.StatusUnprocessableEntity ||
+		len(apiErr.Parameters) > 0
+}
+
+// PartialItemsError is returned by bulk item operations such as ReorderItems
+// and UpdateOrderItemsOptions when the API processed only some of the
+// requested items. The operation's result is returned alongside it.
+type PartialItemsError struct {
+	Succeeded []string
+	Failed    []ItemFailure
+}
+
+func (e *PartialItemsError) Error() string {
+	msg := fmt.Sprintf("%d of %d items failed", len(e.Failed), len(e.Failed)+len(e.Succeeded))
+	if len(e.Failed) > 0 && e.Failed[0].Reason != "" {
+		msg += fmt.Sprintf(" (%s: %s)", e.Failed[0].ItemID, e.Failed[0].Reason)
+	}
+	return msg
+}
+
+// PartialPricesError is returned by GetPrices, alongside the prices it did
+// obtain, when some price batches failed.
+type PartialPricesError struct {
+	Failed []PriceFailure
+}
+
+// PriceFailure is an acquisition or item GetPrices could not price.
+type PriceFailure struct {
+	Acquisition bool // ID is an acquisition ID rather than an item UUID
+	ID          string
+	Err         error
+}
+
+func (e *PartialPricesError) Error() string {
+	msg := fmt.Sprintf("%d acquisitions or items were not priced", len(e.Failed))
+	if len(e.Failed) > 0 {
+		msg += fmt.Sprintf(" (%s: %v)", e.Failed[0].ID, e.Failed[0].Err)
+	}
+	return msg
+}
+
+// Unwrap returns the distinct errors of the failed batches.
+func (e *PartialPricesError) Unwrap() []error {
+	var errs []error
+	for _, f := range e.Failed {
+		if !slices.ContainsFunc(errs, func(err error) bool { return errors.Is(err, f.Err) }) {
+			errs = append(errs, f.Err)
+		}
+	}
+	return errs
+}
+
+// PriceGuardReason says why SubmitBasketIfUnder refused to submit.
+type PriceGuardReason string
+
+const (
+	PriceGuardOverLimit        PriceGuardReason = "over_limit"
+	PriceGuardNotFinal         PriceGuardReason = "not_final"
+	PriceGuardCurrencyMismatch PriceGuardReason = "currency_mismatch"
+)
+
+// PriceGuardError is returned by SubmitBasketIfUnder when the basket's quote
+// does not satisfy the guard. Price is the quote that was checked.
+type PriceGuardError struct {
+	BasketID string
+	Price    Price
+	Max      float64
+	Currency string
+	Reason   PriceGuardReason
+}
+
+func (e *PriceGuardError) Error() string {
+	quote := fmt.Sprintf("%.2f %s", e.Price.Total, e.Price.Currency)
+	switch e.Reason {
+	case PriceGuardCurrencyMismatch:
+		return fmt.Sprintf("basket %s quoted %s, not in guard currency %s", e.BasketID, quote, e.Currency)
+	case PriceGuardNotFinal:
+		return fmt.Sprintf("basket %s price %s is not final", e.BasketID, quote)
+	default:
+		return fmt.Sprintf("basket %s price %s exceeds limit %.2f %s", e.BasketID, quote, e.Max, e.Currency)
+	}
+}
+
+// PermissionError is returned before a request is sent when the account
+// lacks the permission the operation requires. Permission is the name of the
+// Permissions field, e.g. "isDirectAccess".
+type PermissionError struct {
+	Permission string
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("account lacks the %s permission", e.Permission)
+}
+
+// ComplianceError is returned by SubmitBasket before the basket is submitted
+// when it lacks fields required for compliance. Missing names the Basket
+// fields, e.g. "purpose", or "customer" for reseller accounts.
+type ComplianceError struct {
+	BasketID string
+	Missing  []string
+}
+
+func (e *ComplianceError) Error() string {
+	return fmt.Sprintf("basket %s is missing %s required for compliance", e.BasketID, strings.Join(e.Missing, " and "))
+}
+
+// PasswordErrorReason says why a password change failed.
+type PasswordErrorReason string
+
+const (
+	// PasswordWrongOld means the API did not accept the old password.
+	PasswordWrongOld PasswordErrorReason = "wrong_old_password"
+	// PasswordPolicyViolation means the new password does not meet the
+	// password policy.
+	PasswordPolicyViolation PasswordErrorReason = "policy_violation"
+)
+
+// PasswordError is returned by ChangePassword when the password change is
+// refused. Err is the API error, or nil if the new password was rejected
+// before sending.
+type PasswordError struct {
+	Reason  PasswordErrorReason
+	Message string
+	Err     error
+}
+
+func (e *PasswordError) Error() string {
+	msg := "password change refused: "
+	if e.Reason == PasswordWrongOld {
+		msg += "wrong old password"
+	} else {
+		msg += "new password violates the password policy"
+	}
+	if e.Message != "" {
+		msg += " (" + e.Message + ")"
+	}
+	return msg
+}
+
+func (e *PasswordError) Unwrap() error { return e.Err }
@@ -1,8 +1,14 @@
 package airbus
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
@@ -21,10 +27,14 @@ func (c *Client) SearchFeasibility(ctx context.Context, req *FeasibilityRequest)
 	return &out, err
 }
 
-// GetConflicts checks for conflicts between items.
-// Conflicts can occur when items have overlapping acquisition windows.
+// CheckConflicts checks items for conflicts with each other, with items in
+// other baskets and with external orders, e.g. overlapping datatakes on the
+// same orbit.
 // POST /sar/conflicts
-func (c *Client) GetConflicts(ctx context.Context, req *ConflictsRequest) (*ConflictsResponse, error) {
+func (c *Client) CheckConflicts(ctx context.Context, req *ConflictsRequest) (*ConflictsResponse, error) {
+	if len(req.Items) == 0 {
+		return nil, errors.New("at least one item is required")
+	}
 	body, err := common.MarshalBody(req)
 	if err != nil {
 		return nil, err
@@ -34,6 +44,63 @@ func (c *Client) GetConflicts(ctx context.Context, req *ConflictsRequest) (*Conf
 	return &out, err
 }
 
+// GetConflicts checks for conflicts between items.
+//
+// Deprecated: use CheckConflicts.
+func (c *Client) GetConflicts(ctx context.Context, req *ConflictsRequest) (*ConflictsResponse, error) {
+	return c.CheckConflicts(ctx, req)
+}
+
+// UnmarshalJSON accepts both the per-item array returned by POST
+// /sar/conflicts and the flattened object form. Per-item results are
+// flattened into Conflicts, skipping entries with status "none".
+func (r *ConflictsResponse) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '[' {
+		type plain ConflictsResponse
+		return json.Unmarshal(data, (*plain)(r))
+	}
+	if err := json.Unmarshal(data, &r.Items); err != nil {
+		return err
+	}
+
+	r.Conflicts = nil
+	seen := make(map[[2]string]bool)
+	add := func(a, b, where string, w ConflictWindow) {
+		if w.Status == "" || w.Status == ConflictStatusNone {
+			return
+		}
+		key := [2]string{min(a, b), max(a, b)}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		r.Conflicts = append(r.Conflicts, Conflict{
+			ItemID1: a,
+			ItemID2: b,
+			Reason:  fmt.Sprintf("%s conflict with %s", w.Status, where),
+			Status:  w.Status,
+		})
+	}
+	for _, it := range r.Items {
+		c := it.Conflicts
+		for _, id := range slices.Sorted(maps.Keys(c.Shopcart)) {
+			add(it.ItemID, id, "item in the same basket", c.Shopcart[id])
+		}
+		for _, basketID := range slices.Sorted(maps.Keys(c.Baskets)) {
+			for _, id := range slices.Sorted(maps.Keys(c.Baskets[basketID])) {
+				add(it.ItemID, id, "item in basket "+basketID, c.Baskets[basketID][id])
+			}
+		}
+		for _, id := range slices.Sorted(maps.Keys(c.Feasibility)) {
+			add(it.ItemID, id, "feasibility item", c.Feasibility[id])
+		}
+		for _, id := range slices.Sorted(maps.Keys(c.External)) {
+			add(it.ItemID, id, "external order", c.External[id])
+		}
+	}
+	return nil
+}
+
 // GetSwathEditInfo retrieves swath editing information for items.
 // This shows the editable geometry bounds for acquisitions.
 // POST /sar/swathedit
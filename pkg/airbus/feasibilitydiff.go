@@ -0,0 +1,274 @@
+package airbus
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Feasibility Diffing
+// ----------------------------------------------------------------------------
+
+// Default DiffOptions values.
+const (
+	DefaultDiffTimeTolerance           = 30 * time.Second
+	DefaultDiffIncidenceAngleThreshold = 0.5 // degrees
+	DefaultDiffCoverageThreshold       = 1.0 // percentage points
+)
+
+// DiffOptions configures DiffFeasibility. Zero values select the defaults.
+type DiffOptions struct {
+	// Identity groups features that describe the same acquisition
+	// opportunity. Within a group, features are paired by start time.
+	// Defaults to DefaultFeasibilityIdentity.
+	Identity func(AcquisitionProperties) string
+	// TimeTolerance is how far the start time of the same acquisition may
+	// move between planning runs. Defaults to DefaultDiffTimeTolerance.
+	TimeTolerance time.Duration
+	// IncidenceAngleThreshold is the incidence angle change, in degrees,
+	// above which a paired feature is reported as changed. Defaults to
+	// DefaultDiffIncidenceAngleThreshold.
+	IncidenceAngleThreshold float64
+	// CoverageThreshold is the AOI coverage change, in percentage points,
+	// above which a paired feature is reported as changed. Defaults to
+	// DefaultDiffCoverageThreshold.
+	CoverageThreshold float64
+}
+
+// DefaultFeasibilityIdentity identifies an acquisition opportunity by its
+// beam and relative orbit. Acquisition and item IDs are not used: they are
+// reassigned by every planning run.
+func DefaultFeasibilityIdentity(p AcquisitionProperties) string {
+	return fmt.Sprintf("%s/%d", p.BeamID, p.RelativeOrbit)
+}
+
+// FeasibilityDiff is the difference between two feasibility results.
+type FeasibilityDiff struct {
+	// Added are the acquisitions only in the new result.
+	Added []Feature `json:"added"`
+	// Removed are the acquisitions only in the old result.
+	Removed []Feature `json:"removed"`
+	// Changed are the acquisitions in both results whose properties moved
+	// by more than the configured thresholds.
+	Changed []FeatureChange `json:"changed"`
+	// Unchanged is the number of acquisitions in both results within the
+	// thresholds.
+	Unchanged int `json:"unchanged"`
+}
+
+// FeatureChange is an acquisition present in both feasibility results with
+// property changes above the configured thresholds.
+type FeatureChange struct {
+	Identity string  `json:"identity"`
+	Old      Feature `json:"old"`
+	New      Feature `json:"new"`
+	// StartShift is how far the start time moved, new minus old, in
+	// seconds.
+	StartShift float64         `json:"startShiftSeconds"`
+	Deltas     []PropertyDelta `json:"deltas"`
+}
+
+// PropertyDelta is the change of one numeric property.
+type PropertyDelta struct {
+	Property string  `json:"property"`
+	Old      float64 `json:"old"`
+	New      float64 `json:"new"`
+}
+
+// Empty reports whether the results differ in no reported way.
+func (d *FeasibilityDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Summary returns a compact human-readable report: a count line followed by
+// one line per added (+), removed (-) and changed (~) acquisition.
+func (d *FeasibilityDiff) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d added, %d removed, %d changed, %d unchanged",
+		len(d.Added), len(d.Removed), len(d.Changed), d.Unchanged)
+	for _, f := range d.Added {
+		fmt.Fprintf(&b, "\n+ %s", describeFeature(f.Properties))
+	}
+	for _, f := range d.Removed {
+		fmt.Fprintf(&b, "\n- %s", describeFeature(f.Properties))
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&b, "\n~ %s:", describeFeature(c.New.Properties))
+		for i, delta := range c.Deltas {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, " %s %.2f -> %.2f", delta.Property, delta.Old, delta.New)
+		}
+	}
+	return b.String()
+}
+
+func describeFeature(p AcquisitionProperties) string {
+	s := fmt.Sprintf("%s beam %s orbit %d at %s", cmp.Or(string(p.Satellite), string(p.Mission), "?"),
+		cmp.Or(p.BeamID, "?"), p.RelativeOrbit, p.StartTime.UTC().Format(timeFormat))
+	if p.IncidenceAngle != 0 {
+		s += fmt.Sprintf(", incidence %.2f", p.IncidenceAngle)
+	}
+	if p.Coverage != 0 {
+		s += fmt.Sprintf(", coverage %.1f%%", p.Coverage)
+	}
+	return s
+}
+
+// DiffFeasibility compares two feasibility results for the same request,
+// e.g. consecutive runs of a weekly monitoring job. Features are grouped by
+// opts.Identity and, within a group, paired in start time order, with
+// start times at most opts.TimeTolerance apart. Unpaired features are added
+// or removed; paired ones are changed when their incidence angle or
+// coverage moved by more than the thresholds. A property missing from
+// either feature is not compared.
+//
+// A nil collection is treated as empty. The lists in the result are sorted
+// by start time.
+func DiffFeasibility(before, after *FeatureCollection, opts DiffOptions) (*FeasibilityDiff, error) {
+	if opts.TimeTolerance < 0 || opts.IncidenceAngleThreshold < 0 || opts.CoverageThreshold < 0 {
+		return nil, errors.New("diff tolerance and thresholds must not be negative")
+	}
+	identity := opts.Identity
+	if identity == nil {
+		identity = DefaultFeasibilityIdentity
+	}
+	tolerance := cmp.Or(opts.TimeTolerance, DefaultDiffTimeTolerance)
+	angleThreshold := cmp.Or(opts.IncidenceAngleThreshold, DefaultDiffIncidenceAngleThreshold)
+	coverageThreshold := cmp.Or(opts.CoverageThreshold, DefaultDiffCoverageThreshold)
+
+	oldGroups := groupFeatures(before, identity)
+	newGroups := groupFeatures(after, identity)
+
+	diff := &FeasibilityDiff{Added: []Feature{}, Removed: []Feature{}, Changed: []FeatureChange{}}
+	for key, olds := range oldGroups {
+		news := newGroups[key]
+		pairs, oldLeft, newLeft := pairByStartTime(olds, news, tolerance)
+		diff.Removed = append(diff.Removed, oldLeft...)
+		diff.Added = append(diff.Added, newLeft...)
+		for _, p := range pairs {
+			deltas := featureDeltas(p[0].Properties, p[1].Properties, angleThreshold, coverageThreshold)
+			if len(deltas) == 0 {
+				diff.Unchanged++
+				continue
+			}
+			diff.Changed = append(diff.Changed, FeatureChange{
+				Identity:   key,
+				Old:        p[0],
+				New:        p[1],
+				StartShift: p[1].Properties.StartTime.Sub(p[0].Properties.StartTime).Seconds(),
+				Deltas:     deltas,
+			})
+		}
+	}
+	for key, news := range newGroups {
+		if _, ok := oldGroups[key]; !ok {
+			diff.Added = append(diff.Added, news...)
+		}
+	}
+
+	byStart := func(a, b Feature) int {
+		return cmp.Or(a.Properties.StartTime.Compare(b.Properties.StartTime),
+			cmp.Compare(identity(a.Properties), identity(b.Properties)))
+	}
+	slices.SortFunc(diff.Added, byStart)
+	slices.SortFunc(diff.Removed, byStart)
+	slices.SortFunc(diff.Changed, func(a, b FeatureChange) int { return byStart(a.New, b.New) })
+	return diff, nil
+}
+
+// groupFeatures groups the features of fc by identity, each group sorted by
+// start time.
+func groupFeatures(fc *FeatureCollection, identity func(AcquisitionProperties) string) map[string][]Feature {
+	groups := make(map[string][]Feature)
+	if fc == nil {
+		return groups
+	}
+	for _, f := range fc.Features {
+		key := identity(f.Properties)
+		groups[key] = append(groups[key], f)
+	}
+	for _, g := range groups {
+		slices.SortStableFunc(g, func(a, b Feature) int {
+			return a.Properties.StartTime.Compare(b.Properties.StartTime)
+		})
+	}
+	return groups
+}
+
+// pairByStartTime pairs features of two groups sorted by start time. Passes
+// keep their order between planning runs, so the pairing is an alignment of
+// the two sequences: it pairs as many features as possible with start times
+// at most tolerance apart and, among those alignments, the one moving start
+// times the least.
+func pairByStartTime(olds, news []Feature, tolerance time.Duration) (pairs [][2]Feature, oldLeft, newLeft []Feature) {
+	type score struct {
+		pairs int
+		shift time.Duration
+	}
+	better := func(a, b score) bool {
+		return a.pairs > b.pairs || a.pairs == b.pairs && a.shift < b.shift
+	}
+	gap := func(i, j int) (time.Duration, bool) {
+		d := news[j].Properties.StartTime.Sub(olds[i].Properties.StartTime)
+		d = max(d, -d)
+		return d, d <= tolerance
+	}
+
+	// best[i][j] is the best alignment of olds[i:] and news[j:].
+	best := make([][]score, len(olds)+1)
+	for i := range best {
+		best[i] = make([]score, len(news)+1)
+	}
+	for i := len(olds) - 1; i >= 0; i-- {
+		for j := len(news) - 1; j >= 0; j-- {
+			s := best[i+1][j]
+			if better(best[i][j+1], s) {
+				s = best[i][j+1]
+			}
+			if d, ok := gap(i, j); ok {
+				if p := (score{best[i+1][j+1].pairs + 1, best[i+1][j+1].shift + d}); better(p, s) {
+					s = p
+				}
+			}
+			best[i][j] = s
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(olds) && j < len(news) {
+		if d, ok := gap(i, j); ok && best[i][j] == (score{best[i+1][j+1].pairs + 1, best[i+1][j+1].shift + d}) {
+			pairs = append(pairs, [2]Feature{olds[i], news[j]})
+			i, j = i+1, j+1
+		} else if best[i][j] == best[i+1][j] {
+			oldLeft = append(oldLeft, olds[i])
+			i++
+		} else {
+			newLeft = append(newLeft, news[j])
+			j++
+		}
+	}
+	oldLeft = append(oldLeft, olds[i:]...)
+	newLeft = append(newLeft, news[j:]...)
+	return pairs, oldLeft, newLeft
+}
+
+// featureDeltas returns the property changes between a and b above the
+// thresholds. Zero values are taken as missing: coverage, for one, is
+// omitted from some results.
+func featureDeltas(a, b AcquisitionProperties, angleThreshold, coverageThreshold float64) []PropertyDelta {
+	var deltas []PropertyDelta
+	if a.IncidenceAngle != 0 && b.IncidenceAngle != 0 && math.Abs(b.IncidenceAngle-a.IncidenceAngle) > angleThreshold {
+		deltas = append(deltas, PropertyDelta{Property: "incidenceAngle", Old: a.IncidenceAngle, New: b.IncidenceAngle})
+	}
+	if a.Coverage != 0 && b.Coverage != 0 && math.Abs(b.Coverage-a.Coverage) > coverageThreshold {
+		deltas = append(deltas, PropertyDelta{Property: "coverage", Old: a.Coverage, New: b.Coverage})
+	}
+	return deltas
+}
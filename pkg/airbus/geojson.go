@@ -0,0 +1,151 @@
+package airbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/paulmach/orb/geojson"
+)
+
+// ----------------------------------------------------------------------------
+// GeoJSON Export
+// ----------------------------------------------------------------------------
+
+// StyleBy selects the acquisition property web map styles are keyed on.
+type StyleBy string
+
+const (
+	StyleByNone          StyleBy = ""
+	StyleByMission       StyleBy = "mission"
+	StyleByPathDirection StyleBy = "pathDirection"
+)
+
+// DefaultStyleColors are the fill and stroke colors used per mission and
+// path direction when ExportOptions.Colors does not name one.
+var DefaultStyleColors = map[string]string{
+	string(MissionTSX):              "#1f77b4",
+	string(MissionPAZ):              "#ff7f0e",
+	string(PathDirectionAscending):  "#2ca02c",
+	string(PathDirectionDescending): "#d62728",
+}
+
+// defaultStyleColor colors values without a palette entry.
+const defaultStyleColor = "#7f7f7f"
+
+// ExportOptions configures GeoJSON export of search results.
+type ExportOptions struct {
+	// StyleBy adds simplestyle-spec "fill", "fill-opacity", "stroke" and
+	// "stroke-width" properties, colored by the given acquisition property.
+	StyleBy StyleBy
+	// Colors maps values of the StyleBy property to CSS colors, overriding
+	// DefaultStyleColors.
+	Colors map[string]string
+	// Titles adds simplestyle-spec "title" and "description" properties
+	// summarizing each acquisition.
+	Titles bool
+	// Indent pretty-prints the output.
+	Indent bool
+}
+
+// GeoJSON converts the collection to an orb FeatureCollection whose
+// features carry the flattened acquisition properties and the styling
+// properties selected by opts. Geometries are passed through unmodified;
+// features without a geometry are left out and counted in skipped.
+func (fc *FeatureCollection) GeoJSON(opts ExportOptions) (out *geojson.FeatureCollection, skipped int, err error) {
+	out = geojson.NewFeatureCollection()
+	for _, f := range fc.Features {
+		if f.Geometry == nil || f.Geometry.Geometry() == nil {
+			skipped++
+			continue
+		}
+		props, err := flattenProperties(f.Properties)
+		if err != nil {
+			return nil, 0, err
+		}
+		opts.style(props, f.Properties)
+
+		feature := geojson.NewFeature(f.Geometry.Geometry())
+		feature.Properties = props
+		out.Append(feature)
+	}
+	return out, skipped, nil
+}
+
+// ToGeoJSON encodes the collection as a GeoJSON FeatureCollection for web
+// maps such as geojson.io or Leaflet; see GeoJSON. Features without a
+// geometry are left out, use GeoJSON or ExportGeoJSONFile to count them.
+func (fc *FeatureCollection) ToGeoJSON(opts ExportOptions) ([]byte, error) {
+	data, _, err := fc.encodeGeoJSON(opts)
+	return data, err
+}
+
+// ExportGeoJSONFile writes the collection to path as GeoJSON, returning the
+// number of features left out for lack of a geometry.
+func (fc *FeatureCollection) ExportGeoJSONFile(path string, opts ExportOptions) (skipped int, err error) {
+	data, skipped, err := fc.encodeGeoJSON(opts)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return 0, err
+	}
+	return skipped, nil
+}
+
+func (fc *FeatureCollection) encodeGeoJSON(opts ExportOptions) (data []byte, skipped int, err error) {
+	out, skipped, err := fc.GeoJSON(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	if opts.Indent {
+		data, err = json.MarshalIndent(out, "", "  ")
+	} else {
+		data, err = json.Marshal(out)
+	}
+	return data, skipped, err
+}
+
+// flattenProperties returns the acquisition properties as a flat property
+// map with their JSON names.
+func flattenProperties(p AcquisitionProperties) (geojson.Properties, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var props geojson.Properties
+	if err := json.Unmarshal(data, &props); err != nil {
+		return nil, err
+	}
+	return props, nil
+}
+
+// style adds the styling properties selected by o to props.
+func (o ExportOptions) style(props geojson.Properties, p AcquisitionProperties) {
+	var key string
+	switch o.StyleBy {
+	case StyleByMission:
+		key = string(p.Mission)
+	case StyleByPathDirection:
+		key = string(p.PathDirection)
+	}
+	if o.StyleBy != StyleByNone {
+		color, ok := o.Colors[key]
+		if !ok {
+			color, ok = DefaultStyleColors[key]
+		}
+		if !ok {
+			color = defaultStyleColor
+		}
+		props["fill"] = color
+		props["fill-opacity"] = 0.3
+		props["stroke"] = color
+		props["stroke-width"] = 2
+	}
+
+	if o.Titles {
+		props["title"] = fmt.Sprintf("%s %s %s", p.Mission, p.SensorMode, p.StartTime.UTC().Format("2006-01-02 15:04:05Z"))
+		props["description"] = fmt.Sprintf("%s %s-looking, incidence %.1f°, beam %s",
+			p.PathDirection, p.LookDirection, p.IncidenceAngle, p.BeamID)
+	}
+}
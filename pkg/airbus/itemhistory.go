@@ -0,0 +1,83 @@
+package airbus
+
+import (
+	"slices"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Item Status History
+// ----------------------------------------------------------------------------
+
+// ItemStatusEvent is a timestamped status transition of an order item.
+type ItemStatusEvent struct {
+	// ItemID is set on events aggregated across items by Order.Timeline.
+	ItemID    string     `json:"itemId,omitempty"`
+	Status    ItemStatus `json:"status"`
+	Timestamp time.Time  `json:"timestamp"`
+	Message   string     `json:"message,omitempty"`
+}
+
+// ItemStatusEventSlice is a status history, oldest event first.
+type ItemStatusEventSlice []ItemStatusEvent
+
+// sortEvents sorts events by timestamp, keeping the order of simultaneous
+// events. The API does not guarantee chronological order.
+func sortEvents(events []ItemStatusEvent) {
+	slices.SortStableFunc(events, func(a, b ItemStatusEvent) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+}
+
+// Timeline returns the status events embedded in the order's items, tagged
+// with their item IDs, oldest first. Items without an embedded history
+// contribute nothing: the SAR API (v2.7.0) has no endpoint serving the
+// history of a single item.
+func (o *Order) Timeline() ItemStatusEventSlice {
+	var events ItemStatusEventSlice
+	for _, item := range o.Items {
+		for _, e := range item.StatusHistory {
+			e.ItemID = item.ItemID
+			events = append(events, e)
+		}
+	}
+	sortEvents(events)
+	return events
+}
+
+// sorted returns the events in chronological order without modifying s.
+func (s ItemStatusEventSlice) sorted() ItemStatusEventSlice {
+	events := slices.Clone(s)
+	sortEvents(events)
+	return events
+}
+
+// DurationIn returns the total time an item spent in status, from each
+// event entering it to the next event. If the item is still in status, the
+// current stay counts up to now. s should be a single item's history.
+func (s ItemStatusEventSlice) DurationIn(status ItemStatus) time.Duration {
+	events := s.sorted()
+	var d time.Duration
+	for i, e := range events {
+		if e.Status != status {
+			continue
+		}
+		end := time.Now()
+		if i+1 < len(events) {
+			end = events[i+1].Timestamp
+		}
+		d += end.Sub(e.Timestamp)
+	}
+	return d
+}
+
+// TimeTo returns the time from submission, the first event of the history,
+// until the item first reached status. ok is false if it has not reached it.
+func (s ItemStatusEventSlice) TimeTo(status ItemStatus) (d time.Duration, ok bool) {
+	events := s.sorted()
+	i := slices.IndexFunc(events, func(e ItemStatusEvent) bool { return e.Status == status })
+	if i < 0 {
+		return 0, false
+	}
+	return events[i].Timestamp.Sub(events[0].Timestamp), true
+}
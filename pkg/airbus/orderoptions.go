@@ -0,0 +1,138 @@
+package airbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// productOptions lists which processing options apply to a product type.
+type productOptions struct {
+	ResolutionVariant bool // SE/RE enhancement applies
+	GainAttenuation   bool // detected (amplitude) product
+	MapProjection     bool // geocoded product
+	ProjectionNeeded  bool // MapProjection must be set
+}
+
+// orderOptionsMatrix is the documented compatibility of processing options
+// per product type. SSC is a complex slant range product, so amplitude and
+// geocoding options do not apply to it.
+var orderOptionsMatrix = map[ProductType]productOptions{
+	ProductTypeSSC: {},
+	ProductTypeMGD: {ResolutionVariant: true, GainAttenuation: true},
+	ProductTypeGEC: {ResolutionVariant: true, GainAttenuation: true, MapProjection: true},
+	ProductTypeEEC: {ResolutionVariant: true, GainAttenuation: true, MapProjection: true, ProjectionNeeded: true},
+}
+
+var (
+	validResolutionVariants = []ResolutionVariant{ResolutionVariantSE, ResolutionVariantRE}
+	validOrbitTypes         = []OrbitType{OrbitTypePremiumNRT, OrbitTypeNRT, OrbitTypeRapid, OrbitTypeScience}
+	validMapProjections     = []MapProjection{MapProjectionAuto, MapProjectionUTM, MapProjectionUPS}
+	validGainAttenuations   = []GainAttenuation{GainAttenuation0, GainAttenuation10, GainAttenuation20}
+)
+
+// Validate checks the options against the product type compatibility
+// matrix: EEC requires a MapProjection, SSC takes no ResolutionVariant,
+// GainAttenuation applies only to detected products and MapProjection only to
+// geocoded ones. Unknown enum values are rejected. Unset fields are not
+// checked unless the product type requires them. All violations are returned
+// together.
+func (o *OrderOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	var errs []error
+	if o.ResolutionVariant != "" && !slices.Contains(validResolutionVariants, o.ResolutionVariant) {
+		errs = append(errs, fmt.Errorf("unknown resolution variant %q", o.ResolutionVariant))
+	}
+	if o.OrbitType != "" && !slices.Contains(validOrbitTypes, o.OrbitType) {
+		errs = append(errs, fmt.Errorf("unknown orbit type %q", o.OrbitType))
+	}
+	if o.MapProjection != "" && !slices.Contains(validMapProjections, o.MapProjection) {
+		errs = append(errs, fmt.Errorf("unknown map projection %q", o.MapProjection))
+	}
+	if !slices.Contains(validGainAttenuations, o.GainAttenuation) {
+		errs = append(errs, fmt.Errorf("gain attenuation %d is not one of 0, 10, 20", o.GainAttenuation))
+	}
+
+	if o.ProductType != "" {
+		rule, ok := orderOptionsMatrix[o.ProductType]
+		if !ok {
+			return errors.Join(append(errs, fmt.Errorf("unknown product type %q", o.ProductType))...)
+		}
+		if o.ResolutionVariant != "" && !rule.ResolutionVariant {
+			errs = append(errs, fmt.Errorf("%s does not take a resolution variant", o.ProductType))
+		}
+		if o.GainAttenuation != 0 && !rule.GainAttenuation {
+			errs = append(errs, fmt.Errorf("gain attenuation only applies to detected products, not %s", o.ProductType))
+		}
+		if o.MapProjection != "" && !rule.MapProjection {
+			errs = append(errs, fmt.Errorf("map projection only applies to geocoded products, not %s", o.ProductType))
+		}
+		if o.MapProjection == "" && rule.ProjectionNeeded {
+			errs = append(errs, fmt.Errorf("%s requires a map projection", o.ProductType))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// merge returns o with unset fields taken from defaults. GeocodedIncidenceMask
+// cannot be unset, so a default of true always applies.
+func (o *OrderOptions) merge(defaults *OrderOptions) *OrderOptions {
+	var out OrderOptions
+	if defaults != nil {
+		out = *defaults
+	}
+	if o == nil {
+		return &out
+	}
+	if o.ProductType != "" {
+		out.ProductType = o.ProductType
+	}
+	if o.ResolutionVariant != "" {
+		out.ResolutionVariant = o.ResolutionVariant
+	}
+	if o.OrbitType != "" {
+		out.OrbitType = o.OrbitType
+	}
+	if o.MapProjection != "" {
+		out.MapProjection = o.MapProjection
+	}
+	if o.GainAttenuation != 0 {
+		out.GainAttenuation = o.GainAttenuation
+	}
+	out.GeocodedIncidenceMask = out.GeocodedIncidenceMask || o.GeocodedIncidenceMask
+	return &out
+}
+
+// cachedConfig returns the account configuration, fetching it on first use
+// and reusing it for the lifetime of the client, or for the TTL set with
+// WithConfigCache. Failed fetches are not cached.
+func (c *Client) cachedConfig(ctx context.Context) (*Config, error) {
+	ttl := c.cache.ttl(CacheConfig)
+	if ttl <= 0 {
+		ttl = -1
+	}
+	return getCached(ctx, c.cache, CacheConfig, ttl, c.fetchConfig)
+}
+
+// ResolveOrderOptions returns the options that will actually be sent: the
+// fields set in user over the account's Settings.DefaultOrderOptions, checked
+// with Validate. The account configuration is fetched once per client and
+// reused. user is not modified.
+func (c *Client) ResolveOrderOptions(ctx context.Context, user *OrderOptions) (*OrderOptions, error) {
+	cfg, err := c.cachedConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get config: %w", err)
+	}
+	var defaults *OrderOptions
+	if cfg.Settings != nil {
+		defaults = cfg.Settings.DefaultOrderOptions
+	}
+	resolved := user.merge(defaults)
+	if err := resolved.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid order options: %w", err)
+	}
+	return resolved, nil
+}
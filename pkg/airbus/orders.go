@@ -2,17 +2,172 @@ package airbus
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"iter"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
 
+// defaultOrdersPageSize is the page size of ListOrdersFiltered when
+// ListOrdersOptions.PageSize is not set.
+const defaultOrdersPageSize = 500
+
 // ListOrders returns all orders for the current user.
 // GET /sar/orders
 func (c *Client) ListOrders(ctx context.Context) ([]OrderSummary, error) {
-	var out []OrderSummary
-	err := c.DoRaw(ctx, http.MethodGet, c.BaseURL().JoinPath("sar", "orders"), nil, http.StatusOK, &out)
-	return out, err
+	out := []OrderSummary{}
+	for o, err := range c.ListOrdersFiltered(ctx, ListOrdersOptions{}) {
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+// ListOrdersOptions filters and pages ListOrdersFiltered. Zero fields do not
+// filter.
+type ListOrdersOptions struct {
+	Customer  string
+	OrderType OrderType
+	// ItemStatus selects orders with at least one item in the status.
+	ItemStatus ItemStatus
+	// SubmittedAfter and SubmittedBefore bound the submission time.
+	// Unsubmitted orders are excluded when either is set.
+	SubmittedAfter  time.Time
+	SubmittedBefore time.Time
+	// PageSize is the number of orders requested per page. Defaults to 500.
+	PageSize int
+}
+
+// query encodes the filters and page as query parameters.
+func (o ListOrdersOptions) query(offset, limit int) url.Values {
+	q := url.Values{}
+	if o.Customer != "" {
+		q.Set("customer", o.Customer)
+	}
+	if o.OrderType != "" {
+		q.Set("orderType", string(o.OrderType))
+	}
+	if o.ItemStatus != "" {
+		q.Set("status", string(o.ItemStatus))
+	}
+	if !o.SubmittedAfter.IsZero() {
+		q.Set("submittedAfter", o.SubmittedAfter.UTC().Format(timeFormat))
+	}
+	if !o.SubmittedBefore.IsZero() {
+		q.Set("submittedBefore", o.SubmittedBefore.UTC().Format(timeFormat))
+	}
+	q.Set("limit", strconv.Itoa(limit))
+	if offset > 0 {
+		q.Set("offset", strconv.Itoa(offset))
+	}
+	return q
+}
+
+// match reports whether an order passes the filters.
+func (o ListOrdersOptions) match(s OrderSummary) bool {
+	if o.Customer != "" && s.Customer != o.Customer {
+		return false
+	}
+	if o.OrderType != "" && s.OrderType != o.OrderType {
+		return false
+	}
+	if o.ItemStatus != "" && (s.ItemStatistics == nil || s.ItemStatistics.Count(o.ItemStatus) == 0) {
+		return false
+	}
+	if !o.SubmittedAfter.IsZero() || !o.SubmittedBefore.IsZero() {
+		if s.SubmissionTime == nil {
+			return false
+		}
+		if !o.SubmittedAfter.IsZero() && s.SubmissionTime.Before(o.SubmittedAfter) {
+			return false
+		}
+		if !o.SubmittedBefore.IsZero() && !s.SubmissionTime.Before(o.SubmittedBefore) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListOrdersFiltered returns an iterator over the orders matching opts,
+// filtered by the server and fetched a page at a time with limit and offset.
+// Paging continues until a page is empty or holds no new orders; a short
+// page does not end it, since the server may cap limit below PageSize. The
+// filters are also applied to the results, and paging stops after a page
+// larger than requested, so a server ignoring some parameters yields the
+// same orders, only more slowly.
+// GET /sar/orders
+func (c *Client) ListOrdersFiltered(ctx context.Context, opts ListOrdersOptions) iter.Seq2[OrderSummary, error] {
+	return func(yield func(OrderSummary, error) bool) {
+		limit := opts.PageSize
+		if limit <= 0 {
+			limit = defaultOrdersPageSize
+		}
+		seen := make(map[[2]string]bool)
+		for offset := 0; ; {
+			u := c.BaseURL().JoinPath("sar", "orders")
+			u.RawQuery = opts.query(offset, limit).Encode()
+			var page []OrderSummary
+			if err := c.DoRaw(ctx, http.MethodGet, u, nil, http.StatusOK, &page); err != nil {
+				yield(OrderSummary{}, err)
+				return
+			}
+
+			fresh := 0
+			for _, o := range page {
+				key := [2]string{o.BasketID, o.OrderID}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				fresh++
+				if opts.match(o) && !yield(o, nil) {
+					return
+				}
+			}
+			if len(page) == 0 || len(page) > limit || fresh == 0 {
+				return
+			}
+			offset += len(page)
+		}
+	}
+}
+
+// Count returns the number of items in status.
+func (s *ItemStats) Count(status ItemStatus) int {
+	switch status {
+	case ItemStatusPlanned:
+		return s.Planned
+	case ItemStatusAcquired:
+		return s.Acquired
+	case ItemStatusAcquisitionFailed:
+		return s.AcquisitionFailed
+	case ItemStatusProcessing:
+		return s.Processing
+	case ItemStatusProcessed:
+		return s.Processed
+	case ItemStatusProcessingFailed:
+		return s.ProcessingFailed
+	case ItemStatusDelivering:
+		return s.Delivering
+	case ItemStatusDelivered:
+		return s.Delivered
+	case ItemStatusDeliveryFailed:
+		return s.DeliveryFailed
+	case ItemStatusCancelled:
+		return s.Cancelled
+	case ItemStatusCancellationFailed:
+		return s.CancellationFailed
+	case ItemStatusExpired:
+		return s.Expired
+	}
+	return 0
 }
 
 // GetOrder retrieves an order by ID or basket ID.
@@ -72,23 +227,48 @@ func (c *Client) CancelOrderItems(ctx context.Context, req *CancelItemsRequest)
 	return &out, err
 }
 
-// ReorderItems reorders items with different order options.
-// This creates a new basket with the specified items.
+// ReorderItems reorders delivered items with different order options or an
+// order template, e.g. to reprocess an EEC product as SSC. The reorder is
+// submitted automatically once all items are available in the catalog.
+//
+// If the API accepts only some of the items, the returned order is non-nil and
+// the error is a *PartialItemsError listing succeeded and failed item IDs.
 // POST /sar/orders/reorder
-func (c *Client) ReorderItems(ctx context.Context, req *ReorderRequest) (*Basket, error) {
+func (c *Client) ReorderItems(ctx context.Context, req *ReorderRequest) (*Order, error) {
+	if err := validateItemsOptions(req.Items, req.OrderOptions); err != nil {
+		return nil, err
+	}
 	body, err := common.MarshalBody(req)
 	if err != nil {
 		return nil, err
 	}
-	var out Basket
-	err = c.DoRaw(ctx, http.MethodPost, c.BaseURL().JoinPath("sar", "orders", "reorder"), body, http.StatusOK, &out)
-	return &out, err
+	var out struct {
+		Order
+		Failed []ItemFailure `json:"failed,omitempty"`
+	}
+	if err := c.DoRaw(ctx, http.MethodPost, c.BaseURL().JoinPath("sar", "orders", "reorder"), body, http.StatusOK, &out); err != nil {
+		return nil, err
+	}
+
+	// Reorder items reference the item they are based upon via parentItemId.
+	// Without backreferences we cannot tell which items were accepted.
+	var returned []string
+	for _, it := range out.Items {
+		if it.ParentItemID != "" {
+			returned = append(returned, it.ParentItemID)
+		}
+	}
+	return &out.Order, partialItemsError(req.Items, returned, out.Failed)
 }
 
-// SubmitOrder submits an order directly.
+// SubmitOrder submits an order directly, either for an existing basket or
+// for item UUIDs without creating a basket. The request is validated first.
 // This is an alternative to SubmitBasket that allows direct order submission.
 // POST /sar/orders/submit
 func (c *Client) SubmitOrder(ctx context.Context, req *SubmitOrderRequest) (*Order, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
 	body, err := common.MarshalBody(req)
 	if err != nil {
 		return nil, err
@@ -98,12 +278,150 @@ func (c *Client) SubmitOrder(ctx context.Context, req *SubmitOrderRequest) (*Ord
 	return &out, err
 }
 
-// UpdateOrderOptions updates processing options for items.
+// Validate checks that exactly one of BasketID or Items is set and that the
+// order options are valid.
+func (r *SubmitOrderRequest) Validate() error {
+	if r == nil {
+		return errors.New("submit order request is nil")
+	}
+	switch {
+	case r.BasketID != "" && len(r.Items) > 0:
+		return errors.New("only one of basketId or items may be set")
+	case r.BasketID == "" && len(r.Items) == 0:
+		return errors.New("a basketId or at least one item is required")
+	}
+	return r.OrderOptions.Validate()
+}
+
+// OrderAcquisitions orders catalogue acquisitions in a single step, without
+// a basket: the acquisitions are priced first, which creates their items,
+// and the items are then submitted with the given options and purpose. An
+// acquisition the API does not price is reported as an error before
+// anything is submitted.
+func (c *Client) OrderAcquisitions(ctx context.Context, acquisitionIDs []string, opts *OrderOptions, purpose Purpose) (*Order, error) {
+	if len(acquisitionIDs) == 0 {
+		return nil, errors.New("at least one acquisition is required")
+	}
+	if purpose == "" {
+		return nil, errors.New("purpose is required")
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	prices, err := c.GetPrices(ctx, &PricesRequest{Acquisitions: acquisitionIDs})
+	if err != nil {
+		return nil, fmt.Errorf("price acquisitions: %w", err)
+	}
+	itemIDs := make(map[string]string, len(prices))
+	for _, p := range prices {
+		if p.AcquisitionID != "" && p.ItemID != "" {
+			itemIDs[p.AcquisitionID] = p.ItemID
+		}
+	}
+	items := make([]string, 0, len(acquisitionIDs))
+	for _, id := range acquisitionIDs {
+		itemID, ok := itemIDs[id]
+		if !ok {
+			return nil, fmt.Errorf("acquisition %s was not priced", id)
+		}
+		items = append(items, itemID)
+	}
+
+	return c.SubmitOrder(ctx, &SubmitOrderRequest{
+		Items:        items,
+		Purpose:      purpose,
+		OrderOptions: opts,
+	})
+}
+
+// UpdateOrderItemsOptions changes the order options of items that have not
+// been processed yet and returns the items with their new options.
+//
+// If the API accepts only some of the items, the updated items are returned
+// together with a *PartialItemsError listing succeeded and failed item IDs.
 // PATCH /sar/orderOptions
-func (c *Client) UpdateOrderOptions(ctx context.Context, req *UpdateOrderOptionsRequest) error {
+func (c *Client) UpdateOrderItemsOptions(ctx context.Context, req *UpdateOrderOptionsRequest) ([]Item, error) {
+	if err := validateItemsOptions(req.Items, req.OrderOptions); err != nil {
+		return nil, err
+	}
 	body, err := common.MarshalBody(req)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var out struct {
+		Features []struct {
+			Properties Item `json:"properties"`
+		} `json:"features"`
+		Failed []ItemFailure `json:"failed,omitempty"`
+	}
+	if err := c.DoRaw(ctx, http.MethodPatch, c.BaseURL().JoinPath("sar", "orderOptions"), body, http.StatusOK, &out); err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, len(out.Features))
+	returned := make([]string, len(out.Features))
+	for i, f := range out.Features {
+		items[i] = f.Properties
+		returned[i] = f.Properties.ItemID
+	}
+	return items, partialItemsError(req.Items, returned, out.Failed)
+}
+
+// UpdateOrderOptions updates processing options for items.
+//
+// Deprecated: use UpdateOrderItemsOptions, which returns the updated items.
+// PATCH /sar/orderOptions
+func (c *Client) UpdateOrderOptions(ctx context.Context, req *UpdateOrderOptionsRequest) error {
+	_, err := c.UpdateOrderItemsOptions(ctx, req)
+	return err
+}
+
+// validateItemsOptions rejects item option changes the API documents as invalid.
+func validateItemsOptions(items []string, opts *OrderOptions) error {
+	if len(items) == 0 {
+		return errors.New("at least one item is required")
+	}
+	if opts == nil {
+		return nil
+	}
+	if opts.ProductType == ProductTypeSSC && opts.MapProjection != "" {
+		return fmt.Errorf("map projection %q is not applicable to %s products", opts.MapProjection, ProductTypeSSC)
+	}
+	if opts.GeocodedIncidenceMask && opts.ProductType != "" && opts.ProductType != ProductTypeEEC {
+		return fmt.Errorf("geocoded incidence mask is only applicable to %s products, not %s", ProductTypeEEC, opts.ProductType)
+	}
+	return nil
+}
+
+// partialItemsError compares the requested item IDs against those the API
+// reported as processed (nil when unknown) and explicitly failed. It returns
+// nil when every requested item succeeded.
+func partialItemsError(requested, returned []string, failed []ItemFailure) error {
+	failedIDs := make(map[string]bool, len(failed))
+	for _, f := range failed {
+		failedIDs[f.ItemID] = true
+	}
+	var processed map[string]bool
+	if returned != nil {
+		processed = make(map[string]bool, len(returned))
+		for _, id := range returned {
+			processed[id] = true
+		}
+	}
+
+	e := &PartialItemsError{Failed: failed}
+	for _, id := range requested {
+		switch {
+		case failedIDs[id]:
+		case processed != nil && !processed[id]:
+			e.Failed = append(e.Failed, ItemFailure{ItemID: id, Reason: "not included in response"})
+		default:
+			e.Succeeded = append(e.Succeeded, id)
+		}
+	}
+	if len(e.Failed) == 0 {
+		return nil
 	}
-	return c.DoRaw(ctx, http.MethodPatch, c.BaseURL().JoinPath("sar", "orderOptions"), body, http.StatusOK, nil)
+	return e
 }
@@ -2,16 +2,158 @@ package airbus
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
 
+const (
+	// maxPriceBatch is the most acquisitions and items the API prices in one
+	// request; larger requests are rejected with 400.
+	maxPriceBatch = 100
+	// priceConcurrency bounds the price batches GetPrices has in flight.
+	priceConcurrency = 4
+	// nonFinalPriceTTL caps how long WithPriceCache keeps prices that are
+	// not final.
+	nonFinalPriceTTL = time.Minute
+)
+
 // GetPrices queries prices for acquisitions or items.
 // You can provide either acquisition IDs (for catalogue items) or
 // item UUIDs (for feasibility items).
+//
+// Requests larger than the API's batch size are split into batches sent
+// concurrently, and the prices are returned in the order of the requested
+// acquisitions, then items. A failed batch is retried once, unless the API
+// rejected it with a client error. If some batches still fail, the prices
+// obtained are returned with a *PartialPricesError naming the acquisitions
+// and items left unpriced; if none could be priced, the error is returned
+// alone. Acquisitions or items requested more than once are priced once.
+// With WithPriceCache, only acquisitions and items without a cached price
+// are queried.
 // POST /sar/prices
 func (c *Client) GetPrices(ctx context.Context, req *PricesRequest) ([]PriceResponse, error) {
+	if req == nil {
+		return nil, errors.New("prices request is nil")
+	}
+	refs := priceRefs(req)
+	if len(refs) == 0 {
+		return c.fetchPrices(ctx, req)
+	}
+
+	slots := make([][]PriceResponse, len(refs))
+	var missing []int
+	for i, ref := range refs {
+		if p, ok := c.prices.get(ref.cacheKey(req)); ok {
+			slots[i] = []PriceResponse{p}
+		} else {
+			missing = append(missing, i)
+		}
+	}
+
+	type batchResult struct {
+		indexes []int
+		prices  []PriceResponse
+		err     error
+	}
+	var batches []*batchResult
+	for len(missing) > 0 {
+		n := min(len(missing), maxPriceBatch)
+		batches = append(batches, &batchResult{indexes: missing[:n]})
+		missing = missing[n:]
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, priceConcurrency)
+	)
+	for _, b := range batches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				b.err = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			batch := &PricesRequest{Customer: req.Customer, OrderTemplate: req.OrderTemplate}
+			for _, i := range b.indexes {
+				if refs[i].acquisition {
+					batch.Acquisitions = append(batch.Acquisitions, refs[i].id)
+				} else {
+					batch.Items = append(batch.Items, refs[i].id)
+				}
+			}
+			b.prices, b.err = c.fetchPrices(ctx, batch)
+			if b.err != nil && !IsClientError(b.err) && ctx.Err() == nil {
+				b.prices, b.err = c.fetchPrices(ctx, batch)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var (
+		extra  []PriceResponse
+		failed []PriceFailure
+		first  error
+	)
+	for _, b := range batches {
+		if b.err != nil {
+			if first == nil {
+				first = b.err
+			}
+			for _, i := range b.indexes {
+				failed = append(failed, PriceFailure{Acquisition: refs[i].acquisition, ID: refs[i].id, Err: b.err})
+			}
+			continue
+		}
+		acquisitions := make(map[string]int)
+		items := make(map[string]int)
+		for _, i := range b.indexes {
+			if refs[i].acquisition {
+				acquisitions[refs[i].id] = i
+			} else {
+				items[refs[i].id] = i
+			}
+		}
+		for _, p := range b.prices {
+			i, ok := acquisitions[p.AcquisitionID]
+			if !ok || p.AcquisitionID == "" {
+				i, ok = items[p.ItemID]
+			}
+			if !ok {
+				// Not matched to a requested ID: returned, but not cached.
+				extra = append(extra, p)
+				continue
+			}
+			slots[i] = append(slots[i], p)
+			c.prices.put(refs[i].cacheKey(req), p)
+		}
+	}
+
+	var out []PriceResponse
+	for _, s := range slots {
+		out = append(out, s...)
+	}
+	out = append(out, extra...)
+	if len(failed) > 0 {
+		if len(out) == 0 {
+			return nil, first
+		}
+		return out, &PartialPricesError{Failed: failed}
+	}
+	return out, nil
+}
+
+// fetchPrices sends a single price request.
+func (c *Client) fetchPrices(ctx context.Context, req *PricesRequest) ([]PriceResponse, error) {
 	body, err := common.MarshalBody(req)
 	if err != nil {
 		return nil, err
@@ -20,3 +162,77 @@ func (c *Client) GetPrices(ctx context.Context, req *PricesRequest) ([]PriceResp
 	err = c.DoRaw(ctx, http.MethodPost, c.BaseURL().JoinPath("sar", "prices"), body, http.StatusOK, &out)
 	return out, err
 }
+
+// priceRef is an acquisition or item of a price request.
+type priceRef struct {
+	acquisition bool
+	id          string
+}
+
+// priceRefs returns the acquisitions, then the items, of req, each once.
+func priceRefs(req *PricesRequest) []priceRef {
+	refs := make([]priceRef, 0, len(req.Acquisitions)+len(req.Items))
+	seen := make(map[priceRef]bool, cap(refs))
+	add := func(ref priceRef) {
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	for _, id := range req.Acquisitions {
+		add(priceRef{acquisition: true, id: id})
+	}
+	for _, id := range req.Items {
+		add(priceRef{id: id})
+	}
+	return refs
+}
+
+// cacheKey identifies the price of the acquisition or item under the order
+// template and customer of req.
+func (r priceRef) cacheKey(req *PricesRequest) string {
+	kind := "item"
+	if r.acquisition {
+		kind = "acquisition"
+	}
+	return strings.Join([]string{kind, r.id, req.OrderTemplate, req.Customer}, "\x00")
+}
+
+// priceCache memoizes prices per acquisition or item, order template and
+// customer. A nil *priceCache caches nothing.
+type priceCache struct {
+	*common.TTLCache[PriceResponse]
+	ttl time.Duration
+}
+
+func newPriceCache(ttl time.Duration) *priceCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &priceCache{TTLCache: common.NewTTLCache[PriceResponse](), ttl: ttl}
+}
+
+func (pc *priceCache) get(key string) (PriceResponse, bool) {
+	if pc == nil {
+		return PriceResponse{}, false
+	}
+	return pc.Get(key)
+}
+
+func (pc *priceCache) put(key string, p PriceResponse) {
+	if pc == nil {
+		return
+	}
+	ttl := pc.ttl
+	if !p.Price.Final {
+		ttl = min(ttl, nonFinalPriceTTL)
+	}
+	pc.Put(key, p, ttl)
+}
+
+func (pc *priceCache) clear() {
+	if pc == nil {
+		return
+	}
+	pc.Clear()
+}
@@ -0,0 +1,273 @@
+package airbus
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// ----------------------------------------------------------------------------
+// Rate Limits
+// ----------------------------------------------------------------------------
+
+// RateLimit is the request quota reported in a response's rate limit
+// headers. Fields whose header is absent are zero.
+type RateLimit struct {
+	// Limit is the number of requests allowed in the current window.
+	Limit int
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+	// Reset is when the window resets, or when to retry a 429 response
+	// that only carries Retry-After.
+	Reset time.Time
+}
+
+// rateLimitHeaders lists, for each field, the headers that set it in order
+// of preference. The API has used both the X-RateLimit- and X-Rate-Limit-
+// spellings; the unprefixed RateLimit- names are those of the IETF draft.
+var rateLimitHeaders = []struct {
+	field string
+	names []string
+}{
+	{"limit", []string{"X-RateLimit-Limit", "X-Rate-Limit-Limit", "RateLimit-Limit"}},
+	{"remaining", []string{"X-RateLimit-Remaining", "X-Rate-Limit-Remaining", "RateLimit-Remaining"}},
+	{"reset", []string{"X-RateLimit-Reset", "X-Rate-Limit-Reset", "RateLimit-Reset"}},
+	{"retry-after", []string{"Retry-After"}},
+}
+
+// epochThreshold separates reset values given as Unix timestamps from those
+// given as seconds from now; no window is anywhere near 30 years long.
+const epochThreshold = 1e9
+
+// parseRateLimit reads the rate limit headers of a response, matching names
+// case-insensitively. When several spellings are present, the first valid
+// one in rateLimitHeaders wins. Reset may be a Unix timestamp, a number of
+// seconds from now or an HTTP date; Retry-After is used when there is no
+// reset header. ok is false if the response carries none of the headers.
+func parseRateLimit(h http.Header, now time.Time) (rl RateLimit, ok bool) {
+	var retryAfter time.Time
+	for _, hdr := range rateLimitHeaders {
+		for _, name := range hdr.names {
+			v, found := headerValue(h, name)
+			if !found {
+				continue
+			}
+			switch hdr.field {
+			case "limit", "remaining":
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					continue
+				}
+				if hdr.field == "limit" {
+					rl.Limit = n
+				} else {
+					rl.Remaining = n
+				}
+			case "reset", "retry-after":
+				t, valid := parseResetTime(v, now)
+				if !valid {
+					continue
+				}
+				if hdr.field == "reset" {
+					rl.Reset = t
+				} else {
+					retryAfter = t
+				}
+			}
+			ok = true
+			break
+		}
+	}
+	if rl.Reset.IsZero() {
+		rl.Reset = retryAfter
+	}
+	return rl, ok
+}
+
+// headerValue returns the first value of the header name, matching it
+// case-insensitively so that non-canonical keys set on the map directly are
+// found too.
+func headerValue(h http.Header, name string) (string, bool) {
+	if values := h[http.CanonicalHeaderKey(name)]; len(values) > 0 {
+		return strings.TrimSpace(values[0]), true
+	}
+	for k, values := range h {
+		if strings.EqualFold(k, name) && len(values) > 0 {
+			return strings.TrimSpace(values[0]), true
+		}
+	}
+	return "", false
+}
+
+func parseResetTime(v string, now time.Time) (time.Time, bool) {
+	if n, err := strconv.ParseFloat(v, 64); err == nil {
+		if n < 0 {
+			return time.Time{}, false
+		}
+		if n >= epochThreshold {
+			return time.Unix(int64(n), 0), true
+		}
+		return now.Add(time.Duration(n * float64(time.Second))), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// LastRateLimit returns the rate limit reported by the most recent response
+// that carried rate limit headers. ok is false until one has been seen. It
+// is safe to call concurrently with API calls.
+func (c *Client) LastRateLimit() (rl RateLimit, ok bool) {
+	if p := c.rateLimit.last.Load(); p != nil {
+		return *p, true
+	}
+	return RateLimit{}, false
+}
+
+// ----------------------------------------------------------------------------
+// Response Metadata
+// ----------------------------------------------------------------------------
+
+// ResponseMeta records the response details of the last call made with a
+// context returned by WithResponseMeta. Failed calls also carry the rate
+// limit in APIError.RateLimit.
+type ResponseMeta struct {
+	*common.ResponseMeta
+}
+
+// RateLimit returns the rate limit reported by the response. ok is false if
+// the response carried no rate limit headers.
+func (m *ResponseMeta) RateLimit() (rl RateLimit, ok bool) {
+	if p, _ := m.Extra().(*RateLimit); p != nil {
+		return *p, true
+	}
+	return RateLimit{}, false
+}
+
+// WithResponseMeta returns a context that records the response details of
+// calls made with it, and the ResponseMeta they are recorded in:
+//
+//	ctx, meta := airbus.WithResponseMeta(ctx)
+//	results, err := client.SearchCatalogue(ctx, req)
+//	if rl, ok := meta.RateLimit(); ok && rl.Remaining < 10 {
+//		// slow down until rl.Reset
+//	}
+func WithResponseMeta(ctx context.Context) (context.Context, *ResponseMeta) {
+	ctx, meta := common.WithResponseMeta(ctx)
+	return ctx, &ResponseMeta{meta}
+}
+
+// MetaFromContext returns the ResponseMeta attached by WithResponseMeta, or
+// nil if there is none.
+func MetaFromContext(ctx context.Context) *ResponseMeta {
+	if meta := common.MetaFromContext(ctx); meta != nil {
+		return &ResponseMeta{meta}
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Transport
+// ----------------------------------------------------------------------------
+
+// rateLimitTransport records the rate limit headers of every response for
+// LastRateLimit and the context's ResponseMeta. With retries enabled, a 429
+// is retried once its quota resets, provided the reset is within maxWait;
+// a 429 without a reset time is returned rather than retried blindly.
+type rateLimitTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	maxWait    time.Duration
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+
+	last atomic.Pointer[RateLimit]
+}
+
+func newRateLimitTransport(base http.RoundTripper, maxRetries int, maxWait time.Duration) *rateLimitTransport {
+	return &rateLimitTransport{
+		base:       base,
+		maxRetries: maxRetries,
+		maxWait:    maxWait,
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		rl, ok := t.record(req.Context(), resp)
+
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= t.maxRetries || !ok || rl.Reset.IsZero() {
+			return resp, nil
+		}
+		// The body can only be replayed if the request knows how to rebuild it.
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, nil
+		}
+		wait := max(rl.Reset.Sub(t.now()), 0)
+		if wait > t.maxWait {
+			return resp, nil
+		}
+
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			retry.Body = body
+		}
+		resp.Body.Close()
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		req = retry
+	}
+}
+
+// record stores the response's rate limit and fills in the context's
+// ResponseMeta.
+func (t *rateLimitTransport) record(ctx context.Context, resp *http.Response) (RateLimit, bool) {
+	rl, ok := parseRateLimit(resp.Header, t.now())
+	if ok {
+		t.last.Store(&rl)
+	}
+	if meta := common.MetaFromContext(ctx); meta != nil {
+		var extra *RateLimit
+		if ok {
+			extra = &rl
+		}
+		meta.Record(resp.StatusCode, requestIDFromHeader(resp.Header), "", extra)
+	}
+	return rl, ok
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
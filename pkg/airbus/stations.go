@@ -0,0 +1,145 @@
+package airbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Validate checks the request for fields the API requires: an AOI, a
+// feasibility level and a sensor mode, a time range whose start precedes its
+// end, and an occurrence count within 2-50 when one is given. If the
+// account's receiving stations are passed, a ReceivingStation must be one of
+// them; Client.ValidateFeasibilityRequest looks them up.
+func (r *FeasibilityRequest) Validate(stations ...ReceivingStation) error {
+	if r == nil {
+		return errors.New("feasibility request is nil")
+	}
+	var errs []error
+	if r.AOI == nil || r.AOI.Geometry() == nil {
+		errs = append(errs, errors.New("aoi is required"))
+	}
+	if err := r.Time.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if r.FeasibilityLevel == "" {
+		errs = append(errs, errors.New("feasibilityLevel is required"))
+	}
+	if r.SensorMode == "" {
+		errs = append(errs, errors.New("sensorMode is required"))
+	}
+	if r.Occurrences != 0 && (r.Occurrences < 2 || r.Occurrences > 50) {
+		errs = append(errs, fmt.Errorf("occurrences %d is outside 2-50", r.Occurrences))
+	}
+	if r.ReceivingStation != "" && len(stations) > 0 &&
+		!slices.ContainsFunc(stations, func(s ReceivingStation) bool { return s.ID == r.ReceivingStation }) {
+		ids := make([]string, len(stations))
+		for i, s := range stations {
+			ids[i] = s.ID
+		}
+		errs = append(errs, fmt.Errorf("unknown receiving station %q (account stations: %s)", r.ReceivingStation, strings.Join(ids, ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+// permissions returns the account permissions from the cached configuration,
+// fetching them separately if the configuration does not include them.
+func (c *Client) permissions(ctx context.Context) (*Permissions, error) {
+	cfg, err := c.cachedConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get config: %w", err)
+	}
+	if cfg.Permissions != nil {
+		return cfg.Permissions, nil
+	}
+	return c.GetPermissions(ctx)
+}
+
+// requirePermission returns a *PermissionError naming permission unless has
+// reports it granted in the account permissions.
+func (c *Client) requirePermission(ctx context.Context, permission string, has func(*Permissions) bool) error {
+	perms, err := c.permissions(ctx)
+	if err != nil {
+		return err
+	}
+	if !has(perms) {
+		return &PermissionError{Permission: permission}
+	}
+	return nil
+}
+
+// requireDirectAccess returns a *PermissionError unless the account is a
+// direct-access account.
+func (c *Client) requireDirectAccess(ctx context.Context) error {
+	return c.requirePermission(ctx, "isDirectAccess", func(p *Permissions) bool { return p.IsDirectAccess })
+}
+
+// ListReceivingStations returns the receiving stations of a direct-access
+// account, from the cached configuration or, if it lists none, from the
+// dedicated endpoint.
+func (c *Client) ListReceivingStations(ctx context.Context) ([]ReceivingStation, error) {
+	cfg, err := c.cachedConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get config: %w", err)
+	}
+	if len(cfg.ReceivingStations) > 0 {
+		return cfg.ReceivingStations, nil
+	}
+	return c.GetReceivingStations(ctx)
+}
+
+// ValidateFeasibilityRequest runs req.Validate and, when req names a
+// receiving station, checks that the account is direct-access and passes its
+// receiving stations to Validate. An account without direct access fails
+// with a *PermissionError.
+func (c *Client) ValidateFeasibilityRequest(ctx context.Context, req *FeasibilityRequest) error {
+	if req == nil || req.ReceivingStation == "" {
+		return req.Validate()
+	}
+	if err := c.requireDirectAccess(ctx); err != nil {
+		return err
+	}
+	stations, err := c.ListReceivingStations(ctx)
+	if err != nil {
+		return err
+	}
+	if len(stations) == 0 {
+		return fmt.Errorf("unknown receiving station %q (the account has no stations)", req.ReceivingStation)
+	}
+	return req.Validate(stations...)
+}
+
+// SearchFeasibilityForStation searches for acquisition-only opportunities
+// downlinked at the given receiving station. It sets AcquisitionOnly and
+// ReceivingStation on a copy of req, validates it with
+// ValidateFeasibilityRequest, and drops returned features that report a
+// different receiving station. Features that do not report a station are
+// kept, since the search already targets the station. Total keeps the
+// server's count; Dropped counts the features removed.
+func (c *Client) SearchFeasibilityForStation(ctx context.Context, req *FeasibilityRequest, stationID string) (*FeatureCollection, error) {
+	if req == nil {
+		return nil, errors.New("feasibility request is nil")
+	}
+	if stationID == "" {
+		return nil, errors.New("receiving station is required")
+	}
+	r := *req
+	r.AcquisitionOnly = true
+	r.ReceivingStation = stationID
+	if err := c.ValidateFeasibilityRequest(ctx, &r); err != nil {
+		return nil, err
+	}
+
+	fc, err := c.SearchFeasibility(ctx, &r)
+	if err != nil {
+		return fc, err
+	}
+	n := len(fc.Features)
+	fc.Features = slices.DeleteFunc(fc.Features, func(f Feature) bool {
+		return f.Properties.ReceivingStation != "" && f.Properties.ReceivingStation != stationID
+	})
+	fc.Dropped = n - len(fc.Features)
+	return fc, nil
+}
@@ -0,0 +1,296 @@
+package airbus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/paulmach/orb/geojson"
+)
+
+// FeasibilityTemplateVersion is the schema version written by SaveTemplate.
+// LoadTemplate rejects files with any other version, so a template saved
+// before a change to FeasibilityTemplate is not silently misread.
+const FeasibilityTemplateVersion = 1
+
+// templateExt is the file extension of saved templates.
+const templateExt = ".json"
+
+// ----------------------------------------------------------------------------
+// Feasibility Templates
+// ----------------------------------------------------------------------------
+
+// FeasibilityTemplate holds the reusable parameters of a FeasibilityRequest,
+// everything except the AOI and time range, so recurring requests can be
+// saved once and applied to a new area and window with ApplyTemplate.
+// Templates are kept client-side; they are unrelated to the server-side
+// order templates of Config.OrderTemplates, which OrderTemplate names.
+type FeasibilityTemplate struct {
+	Version     int    `json:"version"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	FeasibilityLevel      FeasibilityLevel     `json:"feasibilityLevel"`
+	SensorMode            SensorMode           `json:"sensorMode"`
+	Mission               any                  `json:"mission,omitempty"`
+	Priority              Priority             `json:"priority,omitempty"`
+	Periodicity           Periodicity          `json:"periodicity,omitempty"`
+	Occurrences           int                  `json:"occurrences,omitempty"`
+	PolarizationChannels  Polarization         `json:"polarizationChannels,omitempty"`
+	IncidenceAngle        *IncidenceAngleRange `json:"incidenceAngle,omitempty"`
+	PathDirection         any                  `json:"pathDirection,omitempty"`
+	LookDirection         LookDirection        `json:"lookDirection,omitempty"`
+	BeamID                any                  `json:"beamId,omitempty"`
+	RelativeOrbit         any                  `json:"relativeOrbit,omitempty"`
+	OutOfFullPerformance  bool                 `json:"outOfFullPerformance,omitempty"`
+	Customer              string               `json:"customer,omitempty"`
+	OrderTemplate         string               `json:"orderTemplate,omitempty"`
+	ProductType           ProductType          `json:"productType,omitempty"`
+	ResolutionVariant     ResolutionVariant    `json:"resolutionVariant,omitempty"`
+	AcquisitionOnly       bool                 `json:"acquisitionOnly,omitempty"`
+	ReceivingStation      string               `json:"receivingStation,omitempty"`
+	OrbitType             OrbitType            `json:"orbitType,omitempty"`
+	GeocodedIncidenceMask bool                 `json:"geocodedIncidenceMask,omitempty"`
+	MapProjection         MapProjection        `json:"mapProjection,omitempty"`
+	GainAttenuation       GainAttenuation      `json:"gainAttenuation,omitempty"`
+	MinimumCoverage       float64              `json:"minimumCoverage,omitempty"`
+}
+
+// NewFeasibilityTemplate returns a template named name holding the reusable
+// parameters of req. Its AOI and time range are not kept.
+func NewFeasibilityTemplate(name string, req *FeasibilityRequest) (*FeasibilityTemplate, error) {
+	if req == nil {
+		return nil, errors.New("feasibility request is nil")
+	}
+	tpl := &FeasibilityTemplate{}
+	if err := convertParams(feasibilityParams{FeasibilityRequest: req}, tpl); err != nil {
+		return nil, fmt.Errorf("template %q: %w", name, err)
+	}
+	tpl.Version = FeasibilityTemplateVersion
+	tpl.Name = name
+	return tpl, nil
+}
+
+// ApplyTemplate returns a feasibility request with the parameters of tpl
+// over aoi and timeRange, validated with FeasibilityRequest.Validate. The
+// request does not share memory with tpl, so it can be modified before it
+// is sent.
+func ApplyTemplate(tpl *FeasibilityTemplate, aoi *geojson.Geometry, timeRange TimeRange) (*FeasibilityRequest, error) {
+	if tpl == nil {
+		return nil, errors.New("feasibility template is nil")
+	}
+	req := &FeasibilityRequest{}
+	if err := convertParams(tpl, req); err != nil {
+		return nil, fmt.Errorf("template %q: %w", tpl.Name, err)
+	}
+	req.AOI = aoi
+	req.Time = timeRange
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("template %q: %w", tpl.Name, err)
+	}
+	return req, nil
+}
+
+// feasibilityParams encodes a FeasibilityRequest without its AOI and time
+// range: the outer fields take the JSON names of the embedded ones and are
+// always omitted.
+type feasibilityParams struct {
+	*FeasibilityRequest
+	AOI  *struct{} `json:"aoi,omitempty"`
+	Time *struct{} `json:"time,omitempty"`
+}
+
+// convertParams copies the parameters of src into dst through their JSON
+// encoding, matching fields by JSON name, so templates and requests need no
+// hand-kept field mapping. Fields dst does not have are dropped.
+func convertParams(src, dst any) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// TemplateVersionError is returned by LoadTemplate for a template file
+// written with a different schema version.
+type TemplateVersionError struct {
+	Name    string
+	Version int
+}
+
+func (e *TemplateVersionError) Error() string {
+	return fmt.Sprintf("template %q has schema version %d, want %d; recreate it",
+		e.Name, e.Version, FeasibilityTemplateVersion)
+}
+
+// DefaultTemplateDir returns the directory templates are kept in when none
+// is given: gosar/airbus/templates under os.UserConfigDir.
+func DefaultTemplateDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gosar", "airbus", "templates"), nil
+}
+
+// templatePath returns the file of template name in dir, or in
+// DefaultTemplateDir if dir is empty.
+func templatePath(dir, name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+	if dir == "" {
+		var err error
+		if dir, err = DefaultTemplateDir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, name+templateExt), nil
+}
+
+// SaveTemplate writes tpl to dir as <name>.json, creating dir if needed and
+// replacing an existing template of the same name. An empty dir means
+// DefaultTemplateDir. The file is written with the current schema version.
+func SaveTemplate(dir string, tpl *FeasibilityTemplate) error {
+	if tpl == nil {
+		return errors.New("feasibility template is nil")
+	}
+	path, err := templatePath(dir, tpl.Name)
+	if err != nil {
+		return err
+	}
+	out := *tpl
+	out.Version = FeasibilityTemplateVersion
+	data, err := json.MarshalIndent(&out, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadTemplate reads template name from dir, or DefaultTemplateDir if dir
+// is empty. A file with another schema version fails with a
+// *TemplateVersionError, and one with fields the template does not have,
+// such as an aoi, fails rather than having them dropped.
+func LoadTemplate(dir, name string) (*FeasibilityTemplate, error) {
+	path, err := templatePath(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var head struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("parse template %q: %w", name, err)
+	}
+	if head.Version != FeasibilityTemplateVersion {
+		return nil, &TemplateVersionError{Name: name, Version: head.Version}
+	}
+
+	var tpl FeasibilityTemplate
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&tpl); err != nil {
+		return nil, fmt.Errorf("parse template %q: %w", name, err)
+	}
+	tpl.Name = name
+	return &tpl, nil
+}
+
+// ListTemplates returns the names of the templates in dir, or
+// DefaultTemplateDir if dir is empty, in sorted order. A missing directory
+// has no templates.
+func ListTemplates(dir string) ([]string, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultTemplateDir(); err != nil {
+			return nil, err
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), templateExt)
+		if ok && e.Type().IsRegular() && name != "" && !strings.HasPrefix(name, ".") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// ----------------------------------------------------------------------------
+// AOI Files
+// ----------------------------------------------------------------------------
+
+// ReadAOIFile reads an area of interest from a GeoJSON file holding a
+// geometry, a feature, or a feature collection with exactly one feature.
+func ReadAOIFile(path string) (*geojson.Geometry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	aoi, err := parseAOI(data)
+	if err != nil {
+		return nil, fmt.Errorf("aoi %s: %w", path, err)
+	}
+	return aoi, nil
+}
+
+func parseAOI(data []byte) (*geojson.Geometry, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+
+	var g *geojson.Geometry
+	switch head.Type {
+	case "FeatureCollection":
+		fc, err := geojson.UnmarshalFeatureCollection(data)
+		if err != nil {
+			return nil, err
+		}
+		if len(fc.Features) != 1 {
+			return nil, fmt.Errorf("feature collection has %d features, want 1", len(fc.Features))
+		}
+		g = geojson.NewGeometry(fc.Features[0].Geometry)
+	case "Feature":
+		f, err := geojson.UnmarshalFeature(data)
+		if err != nil {
+			return nil, err
+		}
+		g = geojson.NewGeometry(f.Geometry)
+	case "":
+		return nil, errors.New("not a GeoJSON object: missing type")
+	default:
+		var err error
+		if g, err = geojson.UnmarshalGeometry(data); err != nil {
+			return nil, err
+		}
+	}
+	if g.Coordinates == nil && len(g.Geometries) == 0 {
+		return nil, errors.New("no geometry")
+	}
+	return g, nil
+}
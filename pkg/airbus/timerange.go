@@ -0,0 +1,63 @@
+package airbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// timeFormat is the timestamp layout the SAR-API accepts: UTC with seconds
+// precision. Some endpoints reject fractional seconds.
+const timeFormat = "2006-01-02T15:04:05Z"
+
+// timeRangeJSON is the wire form of TimeRange.
+type timeRangeJSON struct {
+	From string `json:"from"`
+	To   string `json:"to,omitempty"`
+}
+
+// Validate checks that From is before To when both are set.
+func (r TimeRange) Validate() error {
+	if !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+		return fmt.Errorf("time range from %s is not before to %s",
+			r.From.UTC().Format(timeFormat), r.To.UTC().Format(timeFormat))
+	}
+	return nil
+}
+
+// MarshalJSON encodes the range in UTC with seconds precision. A zero To is
+// omitted, leaving the range open-ended; the API rejects the zero time as an
+// invalid range.
+func (r TimeRange) MarshalJSON() ([]byte, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	out := timeRangeJSON{From: r.From.UTC().Format(timeFormat)}
+	if !r.To.IsZero() {
+		out.To = r.To.UTC().Format(timeFormat)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes RFC 3339 timestamps with a Z or numeric offset. The
+// times are converted to UTC.
+func (r *TimeRange) UnmarshalJSON(data []byte) error {
+	var in timeRangeJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	var from, to time.Time
+	var err error
+	if in.From != "" {
+		if from, err = time.Parse(time.RFC3339, in.From); err != nil {
+			return fmt.Errorf("parse time range from: %w", err)
+		}
+	}
+	if in.To != "" {
+		if to, err = time.Parse(time.RFC3339, in.To); err != nil {
+			return fmt.Errorf("parse time range to: %w", err)
+		}
+	}
+	r.From, r.To = from.UTC(), to.UTC()
+	return nil
+}
@@ -339,6 +339,24 @@ const (
 	PurposeValueAdding               Purpose = "Value Adding"
 )
 
+// Purposes returns every purpose known to this package, in declaration order.
+func Purposes() []Purpose {
+	return []Purpose{
+		PurposeAerospaceIndustry, PurposeAgroCompany, PurposeAgroServiceCompany, PurposeBank,
+		PurposeConsultingCompany, PurposeConsumer, PurposeCooperativeCompany, PurposeDefenceCompany,
+		PurposeDEM, PurposeEditionCommunication, PurposeEducationResearch, PurposeElectronicSystemCompany,
+		PurposeEmergencyResponse, PurposeEnergyCompany, PurposeEnergyServiceCompany, PurposeEngineeringCompany,
+		PurposeEngineeringServiceCompany, PurposeEnvironment, PurposeForestCompany, PurposeForestServiceCompany,
+		PurposeGeoservicesCompany, PurposeGovernment, PurposeHumanitarianOrganization, PurposeInfrastructuresMonitoring,
+		PurposeInsuranceCompany, PurposeLocationBasedServices, PurposeLogisticsCompany, PurposeMaritimeServices,
+		PurposeMiningCompany, PurposeNetworkOperator, PurposeNGO, PurposeOilGasCompany,
+		PurposeOther, PurposePublicAdministration, PurposeRealEstateCompany, PurposeSecurityCompany,
+		PurposeSpaceAgency, PurposeTelecomCompany, PurposeTransportCompany, PurposeUrbanPlanningCompany,
+		PurposeUtilitiesCompany, PurposeWaterCompany, PurposeWaterServiceCompany, PurposeInternalUse,
+		PurposeValueAdding,
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Notification Type
 // ----------------------------------------------------------------------------
@@ -414,18 +432,27 @@ type ResetPasswordRequest struct {
 
 // Notification represents a user notification.
 type Notification struct {
-	ID        string           `json:"id"`
-	Type      NotificationType `json:"type"`
-	Title     string           `json:"title"`
-	Message   string           `json:"message"`
-	Read      bool             `json:"read"`
-	CreatedAt time.Time        `json:"createdAt"`
-	ExpiresAt *time.Time       `json:"expiresAt,omitempty"`
+	ID        string             `json:"id"`
+	Type      NotificationType   `json:"type"`
+	Title     string             `json:"title"`
+	Message   string             `json:"message"`
+	Read      bool               `json:"read"`
+	Status    NotificationStatus `json:"status,omitempty"`
+	CreatedAt time.Time          `json:"createdAt"`
+	ExpiresAt *time.Time         `json:"expiresAt,omitempty"`
 }
 
+// NotificationStatus is the read state of a notification.
+type NotificationStatus string
+
+const (
+	NotificationRead   NotificationStatus = "read"
+	NotificationUnread NotificationStatus = "unread"
+)
+
 // UpdateNotificationRequest represents a notification update.
 type UpdateNotificationRequest struct {
-	Read bool `json:"read"`
+	Status NotificationStatus `json:"status"`
 }
 
 // ----------------------------------------------------------------------------
@@ -494,16 +521,40 @@ type ReplicationOptions struct {
 type RevocationOptions struct {
 	Since time.Time `url:"since,omitempty"`
 	Limit int       `url:"limit,omitempty"`
+	Token string    `url:"token,omitempty"` // Continuation token from a previous response
 }
 
 // RevocationResponse contains revoked acquisition IDs.
 type RevocationResponse struct {
-	Revocations []string `json:"revocations"`
+	Revocations []string        `json:"revocations,omitempty"`
+	Data        []Revocation    `json:"data,omitempty"`
+	Links       RevocationLinks `json:"_links"`
+	Limit       int             `json:"limit,omitempty"`
+	Size        int             `json:"size,omitempty"`
 }
 
-// RetrieveRequest represents a request to retrieve ordered items from catalogue.
+// Revocation describes a catalogue datatake that can no longer be ordered.
+type Revocation struct {
+	AcquisitionID string    `json:"acquisitionId"`
+	IssueTime     time.Time `json:"issueTime"`
+	Reason        string    `json:"reason,omitempty"`
+}
+
+// RevocationLinks holds pagination links of a revocations response.
+type RevocationLinks struct {
+	Self string `json:"self,omitempty"`
+	Next string `json:"next,omitempty"`
+}
+
+// RetrieveRequest represents a request to retrieve ordered items from
+// catalogue. Exactly one of Acquisitions or Items must be set; the order
+// fields apply when previously acquired data is ordered for processing.
 type RetrieveRequest struct {
-	Items []string `json:"items"` // Order item IDs
+	Acquisitions  []string      `json:"acquisitions,omitempty"` // Acquisition IDs, e.g. of acquisition-only orders
+	Items         []string      `json:"items,omitempty"`        // Order item IDs
+	Customer      string        `json:"customer,omitempty"`
+	OrderTemplate string        `json:"orderTemplate,omitempty"`
+	OrderOptions  *OrderOptions `json:"orderOptions,omitempty"`
 }
 
 // ----------------------------------------------------------------------------
@@ -577,6 +628,23 @@ type Basket struct {
 	OrderType         OrderType  `json:"orderType,omitempty"`
 	OrderID           string     `json:"orderId,omitempty"`
 	SubmissionTime    *time.Time `json:"submissionTime,omitempty"`
+	DeliveryConfigID  string     `json:"deliveryConfigId,omitempty"`
+}
+
+// BasketLintReport lists problems found by LintBasket. An empty report
+// means the basket is expected to submit cleanly.
+type BasketLintReport struct {
+	BasketID      string        `json:"basketId"`
+	Conflicts     []Conflict    `json:"conflicts,omitempty"`
+	MissingPrices []string      `json:"missingPrices,omitempty"` // Item UUIDs
+	Revoked       []RevokedItem `json:"revoked,omitempty"`
+}
+
+// RevokedItem is a basket item whose acquisition has been revoked.
+type RevokedItem struct {
+	ItemID        string `json:"itemId"`
+	AcquisitionID string `json:"acquisitionId"`
+	Reason        string `json:"reason,omitempty"`
 }
 
 // Item represents an item in a basket or order.
@@ -604,6 +672,8 @@ type Item struct {
 	Status                ItemStatus        `json:"status,omitempty"`
 	Price                 *Price            `json:"price,omitempty"`
 	OutOfFullPerformance  bool              `json:"outOfFullPerformance,omitempty"`
+	ParentItemID          string            `json:"parentItemId,omitempty"`
+	StatusHistory         []ItemStatusEvent `json:"statusHistory,omitempty"`
 }
 
 // CreateBasketRequest represents a basket creation request.
@@ -613,6 +683,7 @@ type CreateBasketRequest struct {
 	NotifyEndpoint    *string `json:"notifyEndpoint,omitempty"`
 	Purpose           Purpose `json:"purpose,omitempty"`
 	OrderTemplate     string  `json:"orderTemplate,omitempty"`
+	DeliveryConfigID  string  `json:"deliveryConfigId,omitempty"` // Delivery configuration; defaults to Airbus pickup
 }
 
 // UpdateBasketRequest represents a basket update request (PATCH).
@@ -621,6 +692,7 @@ type UpdateBasketRequest struct {
 	NotifyEndpoint    *string `json:"notifyEndpoint,omitempty"`
 	Purpose           Purpose `json:"purpose,omitempty"`
 	OrderTemplate     string  `json:"orderTemplate,omitempty"`
+	DeliveryConfigID  *string `json:"deliveryConfigId,omitempty"`
 }
 
 // ReplaceBasketRequest represents a basket replacement request (PUT).
@@ -629,6 +701,7 @@ type ReplaceBasketRequest struct {
 	NotifyEndpoint    *string `json:"notifyEndpoint,omitempty"`
 	Purpose           Purpose `json:"purpose,omitempty"`
 	OrderTemplate     string  `json:"orderTemplate,omitempty"`
+	DeliveryConfigID  string  `json:"deliveryConfigId,omitempty"`
 }
 
 // AddItemsRequest represents a request to add items to a basket.
@@ -670,6 +743,7 @@ type Order struct {
 	Items             []Item     `json:"items,omitempty"`
 	Price             *Price     `json:"price,omitempty"`
 	ItemStatistics    *ItemStats `json:"itemStatistics,omitempty"`
+	DeliveryConfigID  string     `json:"deliveryConfigId,omitempty"`
 }
 
 // OrderSummary represents an order in a list response.
@@ -756,10 +830,22 @@ type ReorderRequest struct {
 	OrderTemplate string        `json:"orderTemplate,omitempty"`
 }
 
-// SubmitOrderRequest represents a direct order submission request.
+// ItemFailure describes an item a bulk item operation could not process.
+type ItemFailure struct {
+	ItemID string `json:"itemId"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SubmitOrderRequest represents a direct order submission request. Exactly
+// one of BasketID, to submit an existing basket, or Items, to order item
+// UUIDs without a basket, must be set.
 type SubmitOrderRequest struct {
-	BasketID string   `json:"basketId,omitempty"`
-	Items    []string `json:"items,omitempty"`
+	BasketID          string        `json:"basketId,omitempty"`
+	Items             []string      `json:"items,omitempty"`
+	DeliveryConfigID  string        `json:"deliveryConfigId,omitempty"`
+	Purpose           Purpose       `json:"purpose,omitempty"`
+	CustomerReference string        `json:"customerReference,omitempty"`
+	OrderOptions      *OrderOptions `json:"orderOptions,omitempty"`
 }
 
 // ----------------------------------------------------------------------------
@@ -848,6 +934,7 @@ type AcquisitionProperties struct {
 	Status               string        `json:"status,omitempty"`
 	LastUpdateTime       *time.Time    `json:"lastUpdateTime,omitempty"`
 	OutOfFullPerformance bool          `json:"outOfFullPerformance,omitempty"`
+	ReceivingStation     string        `json:"receivingStation,omitempty"` // Downlink station (direct access)
 	// Feasibility-specific fields
 	Coverage float64 `json:"coverage,omitempty"`
 }
@@ -865,6 +952,63 @@ type FeatureCollection struct {
 	Features []Feature `json:"features"`
 	Limit    int       `json:"limit,omitempty"`
 	Total    int       `json:"total,omitempty"`
+
+	// Dropped is the number of returned features removed on the client,
+	// e.g. by SearchFeasibilityForStation. Total still counts them.
+	Dropped int `json:"-"`
+}
+
+// ----------------------------------------------------------------------------
+// Delivery Types
+// ----------------------------------------------------------------------------
+
+// DeliveryType represents an electronic delivery method.
+type DeliveryType string
+
+const (
+	DeliveryTypeSFTP DeliveryType = "SFTP"
+	DeliveryTypeS3   DeliveryType = "S3"
+)
+
+// Secret is a write-only credential. It is sent when creating or updating a
+// delivery configuration but never read back: the delivery methods clear it
+// from responses, and it prints as "[REDACTED]".
+type Secret string
+
+// DeliveryConfig is an electronic delivery configuration of the account.
+// Exactly one of SFTP and S3 is set, matching Type.
+type DeliveryConfig struct {
+	ID           string        `json:"id,omitempty"`
+	Name         string        `json:"name"`
+	Type         DeliveryType  `json:"type"`
+	Default      bool          `json:"default,omitempty"`
+	SFTP         *SFTPDelivery `json:"sftp,omitempty"`
+	S3           *S3Delivery   `json:"s3,omitempty"`
+	CreationTime *time.Time    `json:"creationTime,omitempty"`
+}
+
+// SFTPDelivery pushes products to an SFTP server. KeyRef names an SSH key
+// already registered with Airbus; Password is an alternative write-only
+// credential.
+type SFTPDelivery struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"` // Defaults to 22
+	Path     string `json:"path,omitempty"`
+	Username string `json:"username"`
+	KeyRef   string `json:"keyRef,omitempty"`
+	Password Secret `json:"password,omitempty"`
+}
+
+// S3Delivery pushes products to an S3 bucket. CredentialsRef names
+// credentials already registered with Airbus; AccessKeyID and SecretAccessKey
+// are an alternative, with the secret key write-only.
+type S3Delivery struct {
+	Bucket          string `json:"bucket"`
+	Region          string `json:"region"`
+	Prefix          string `json:"prefix,omitempty"`
+	CredentialsRef  string `json:"credentialsRef,omitempty"`
+	AccessKeyID     string `json:"accessKeyId,omitempty"`
+	SecretAccessKey Secret `json:"secretAccessKey,omitempty"`
 }
 
 // ----------------------------------------------------------------------------
@@ -878,14 +1022,52 @@ type ConflictsRequest struct {
 
 // Conflict represents a conflict between items.
 type Conflict struct {
-	ItemID1 string `json:"itemId1"`
-	ItemID2 string `json:"itemId2"`
-	Reason  string `json:"reason"`
+	ItemID1 string         `json:"itemId1"`
+	ItemID2 string         `json:"itemId2"`
+	Reason  string         `json:"reason"`
+	Status  ConflictStatus `json:"status,omitempty"`
+}
+
+// ConflictStatus represents how likely a conflict is.
+type ConflictStatus string
+
+const (
+	ConflictStatusNone      ConflictStatus = "none"
+	ConflictStatusPotential ConflictStatus = "potential"
+	ConflictStatusExpected  ConflictStatus = "expected"
+)
+
+// ConflictWindow describes a conflict with a single other datatake.
+type ConflictWindow struct {
+	Status    ConflictStatus `json:"status"`
+	StartTime *time.Time     `json:"startTime,omitempty"`
+	StopTime  *time.Time     `json:"stopTime,omitempty"`
+}
+
+// ItemConflictDetails groups an item's conflicts by where the conflicting
+// datatake lives. Map keys are the conflicting item IDs (for Baskets, the
+// outer key is the basket ID).
+type ItemConflictDetails struct {
+	Status      ConflictStatus                       `json:"status"`
+	External    map[string]ConflictWindow            `json:"external,omitempty"`
+	Baskets     map[string]map[string]ConflictWindow `json:"baskets,omitempty"`
+	Shopcart    map[string]ConflictWindow            `json:"shopcart,omitempty"`
+	Feasibility map[string]ConflictWindow            `json:"feasibility,omitempty"`
+}
+
+// ItemConflicts is the conflict information returned for a single item.
+type ItemConflicts struct {
+	ItemID        string              `json:"itemId"`
+	AcquisitionID string              `json:"acquisitionId"`
+	Conflicts     ItemConflictDetails `json:"conflicts"`
 }
 
 // ConflictsResponse represents the conflicts check result.
 type ConflictsResponse struct {
 	Conflicts []Conflict `json:"conflicts"`
+
+	// Items holds the per-item conflict information when the API returns it.
+	Items []ItemConflicts `json:"items,omitempty"`
 }
 
 // SwathEditRequest represents a swath editing request.
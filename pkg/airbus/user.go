@@ -2,38 +2,152 @@ package airbus
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
 
-// WhoAmI returns account information for the current user.
+// WhoAmI returns account information for the current user. It is cached when
+// the client was created with WithConfigCache.
 // GET /user/whoami
 func (c *Client) WhoAmI(ctx context.Context) (*UserInfo, error) {
+	return cachedCall(ctx, c, CacheWhoAmI, c.fetchWhoAmI)
+}
+
+func (c *Client) fetchWhoAmI(ctx context.Context) (*UserInfo, error) {
 	var out UserInfo
 	err := c.DoRaw(ctx, http.MethodGet, c.BaseURL().JoinPath("user", "whoami"), nil, http.StatusOK, &out)
 	return &out, err
 }
 
-// ChangePassword changes the account password.
-// The oldPassword and password fields should be base64 encoded.
+// ChangePassword changes the account password from oldPassword to
+// newPassword, given in plain text; they are Base64 encoded on the wire. A
+// new password that does not meet the documented policy (see
+// CheckPasswordPolicy) is rejected before the request is sent. A wrong old
+// password or a password the API refuses is returned as a *PasswordError.
 // POST /user/password
-func (c *Client) ChangePassword(ctx context.Context, req *ChangePasswordRequest) error {
-	body, err := common.MarshalBody(req)
+func (c *Client) ChangePassword(ctx context.Context, oldPassword, newPassword string) error {
+	if err := CheckPasswordPolicy(newPassword); err != nil {
+		return err
+	}
+	body, err := common.MarshalBody(&ChangePasswordRequest{
+		OldPassword: base64.StdEncoding.EncodeToString([]byte(oldPassword)),
+		Password:    base64.StdEncoding.EncodeToString([]byte(newPassword)),
+	})
 	if err != nil {
 		return err
 	}
-	return c.DoRaw(ctx, http.MethodPost, c.BaseURL().JoinPath("user", "password"), body, http.StatusOK, nil)
+	err = c.DoRaw(ctx, http.MethodPost, c.BaseURL().JoinPath("user", "password"), body, common.StatusAny2xx, nil)
+	if err != nil {
+		return passwordError(err)
+	}
+	// The cached account information holds the old expiration date.
+	c.InvalidateCache()
+	return nil
 }
 
-// ResetPassword requests a password reset for the given username.
+// ResetPassword requests a password reset for username; the API emails
+// reset instructions to the account.
 // POST /user/password/reset
-func (c *Client) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
-	body, err := common.MarshalBody(req)
+func (c *Client) ResetPassword(ctx context.Context, username string) error {
+	body, err := common.MarshalBody(&ResetPasswordRequest{Username: username})
 	if err != nil {
 		return err
 	}
-	return c.DoRaw(ctx, http.MethodPost, c.BaseURL().JoinPath("user", "password", "reset"), body, http.StatusOK, nil)
+	return c.DoRaw(ctx, http.MethodPost, c.BaseURL().JoinPath("user", "password", "reset"), body, common.StatusAny2xx, nil)
+}
+
+// PasswordExpiresSoon reports whether the account password must be changed
+// within the given duration, either because it expires by then or because
+// the API flags that a change is needed, and returns the expiry time. The
+// time is zero if the password does not expire.
+func (c *Client) PasswordExpiresSoon(ctx context.Context, within time.Duration) (bool, time.Time, error) {
+	user, err := c.WhoAmI(ctx)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	expires, err := user.PasswordExpiry()
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	soon := user.PWChangeNeeded || (!expires.IsZero() && time.Until(expires) <= within)
+	return soon, expires, nil
+}
+
+// accountDateLayout is the layout of the account expiration dates.
+const accountDateLayout = time.DateOnly
+
+// PasswordExpiry returns the time the password expires: the end of
+// PWExpirationDate, the last day it can be used, in UTC. It returns the
+// zero time if the password does not expire.
+func (u *UserInfo) PasswordExpiry() (time.Time, error) {
+	if u.PWExpirationDate == "" {
+		return time.Time{}, nil
+	}
+	day, err := time.Parse(accountDateLayout, u.PWExpirationDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse pw_expiration_date: %w", err)
+	}
+	return day.AddDate(0, 0, 1), nil
+}
+
+// passwordPolicy describes the documented password policy: at least 15
+// characters with lower and upper case letters, a number and a special
+// character.
+const passwordPolicy = "at least 15 characters with lower and upper case letters, a number and a special character"
+
+// CheckPasswordPolicy returns a *PasswordError if password does not meet the
+// documented password policy.
+func CheckPasswordPolicy(password string) error {
+	var lower, upper, digit, special bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			special = true
+		}
+	}
+	if utf8.RuneCountInString(password) < 15 || !lower || !upper || !digit || !special {
+		return &PasswordError{Reason: PasswordPolicyViolation, Message: "password must have " + passwordPolicy}
+	}
+	return nil
+}
+
+// passwordError maps a failed password change to a *PasswordError. The API
+// names the offending parameter of a 400 response; a 403 means the old
+// password was not accepted.
+func passwordError(err error) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	perr := &PasswordError{Message: apiErr.Message, Err: apiErr}
+	switch {
+	case apiErr.StatusCode == http.StatusForbidden:
+		perr.Reason = PasswordWrongOld
+	case apiErr.StatusCode != http.StatusBadRequest:
+		return err
+	case slices.ContainsFunc(apiErr.Parameters, func(p ParameterError) bool {
+		return strings.EqualFold(p.Name, "oldPassword") || strings.HasSuffix(p.Path, ".oldPassword")
+	}):
+		perr.Reason = PasswordWrongOld
+	default:
+		perr.Reason = PasswordPolicyViolation
+	}
+	return perr
 }
 
 // ListNotifications retrieves all notifications for the current user.
@@ -44,14 +158,24 @@ func (c *Client) ListNotifications(ctx context.Context) ([]Notification, error)
 	return out, err
 }
 
-// UpdateNotification updates a notification (e.g., mark as read).
+// UpdateNotification marks a notification as read or unread and returns
+// the updated notification.
 // PATCH /user/notifications/{notificationId}
-func (c *Client) UpdateNotification(ctx context.Context, notificationID string, req *UpdateNotificationRequest) error {
-	body, err := common.MarshalBody(req)
+func (c *Client) UpdateNotification(ctx context.Context, notificationID string, read bool) (*Notification, error) {
+	req := UpdateNotificationRequest{Status: NotificationUnread}
+	if read {
+		req.Status = NotificationRead
+	}
+	body, err := common.MarshalBody(&req)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var out Notification
+	err = c.DoRaw(ctx, http.MethodPatch, c.BaseURL().JoinPath("user", "notifications", notificationID), body, http.StatusOK, &out)
+	if err != nil {
+		return nil, err
 	}
-	return c.DoRaw(ctx, http.MethodPatch, c.BaseURL().JoinPath("user", "notifications", notificationID), body, http.StatusOK, nil)
+	return &out, nil
 }
 
 // DeleteNotification deletes a notification.
@@ -0,0 +1,144 @@
+package capella
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// STAC Item Asset Helpers
+// ----------------------------------------------------------------------------
+
+// Asset roles used by Capella STAC items.
+const (
+	AssetRoleData      = "data"
+	AssetRoleMetadata  = "metadata"
+	AssetRoleThumbnail = "thumbnail"
+	AssetRoleOverview  = "overview"
+)
+
+// assetKeys returns the asset keys of the item in sorted order, so that
+// helpers choosing among several assets are deterministic.
+func (item STACItem) assetKeys() []string {
+	return slices.Sorted(maps.Keys(item.Assets))
+}
+
+// AssetByRole returns the asset with the given role, preferring the first
+// key in sorted order when several assets have it. Asset keys change between
+// product versions; roles are the stable way to find an asset.
+func (item STACItem) AssetByRole(role string) (Asset, bool) {
+	for _, k := range item.assetKeys() {
+		if a := item.Assets[k]; slices.Contains(a.Roles, role) {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// DataAssets returns the assets with the "data" role, sorted by key.
+func (item STACItem) DataAssets() []Asset {
+	var assets []Asset
+	for _, k := range item.assetKeys() {
+		if a := item.Assets[k]; slices.Contains(a.Roles, AssetRoleData) {
+			assets = append(assets, a)
+		}
+	}
+	return assets
+}
+
+// maybeDataAssets returns the assets with the "data" role and
+// those without any roles, which older items leave unset.
+func (item STACItem) maybeDataAssets() map[string]Asset {
+	out := make(map[string]Asset)
+	for k, a := range item.Assets {
+		if len(a.Roles) == 0 || slices.Contains(a.Roles, AssetRoleData) {
+			out[k] = a
+		}
+	}
+	return out
+}
+
+// Thumbnail returns the thumbnail asset, found by role or, for items without
+// roles, by the "thumbnail" key.
+func (item STACItem) Thumbnail() (Asset, bool) {
+	if a, ok := item.AssetByRole(AssetRoleThumbnail); ok {
+		return a, true
+	}
+	a, ok := item.Assets[AssetRoleThumbnail]
+	return a, ok
+}
+
+// PolarizationAssets returns the data assets keyed by polarization. The
+// polarization is read from the asset's sar:polarizations and, for older
+// items that do not set it per asset, from an asset key naming a
+// polarization such as "HH". Assets covering several polarizations are
+// listed under each.
+func (item STACItem) PolarizationAssets() map[Polarization]Asset {
+	out := make(map[Polarization]Asset)
+	candidates := item.maybeDataAssets()
+	for _, k := range slices.Sorted(maps.Keys(candidates)) {
+		a := candidates[k]
+		pols := a.Polarizations
+		if len(pols) == 0 && isPolarization(k) {
+			pols = []string{k}
+		}
+		for _, p := range pols {
+			pol := Polarization(strings.ToUpper(p))
+			if _, ok := out[pol]; !ok {
+				out[pol] = a
+			}
+		}
+	}
+	return out
+}
+
+// isPolarization reports whether s names a linear polarization, e.g. "HH"
+// or "vh".
+func isPolarization(s string) bool {
+	s = strings.ToUpper(s)
+	return len(s) == 2 && strings.ContainsRune("HV", rune(s[0])) && strings.ContainsRune("HV", rune(s[1]))
+}
+
+// Size returns the sum of the file:size of the item's assets. Assets without
+// a size are not counted, so the result is a lower bound when some are
+// missing.
+func (item STACItem) Size() int64 {
+	var n int64
+	for _, a := range item.Assets {
+		n += a.Size
+	}
+	return n
+}
+
+// IncidenceAngleDeg returns the item's view:incidence_angle in degrees,
+// falling back to the first data asset (or asset without roles) that sets
+// one when the property is zero, as in older catalog items.
+func (item STACItem) IncidenceAngleDeg() float64 {
+	if item.Properties.IncidenceAngle != 0 {
+		return item.Properties.IncidenceAngle
+	}
+	candidates := item.maybeDataAssets()
+	for _, k := range slices.Sorted(maps.Keys(candidates)) {
+		if a := candidates[k]; a.IncidenceAngle != 0 {
+			return a.IncidenceAngle
+		}
+	}
+	return 0
+}
+
+// LookDirection returns the item's sar:observation_direction, falling back
+// to the first data asset (or asset without roles) that sets one when the
+// property is empty.
+func (item STACItem) LookDirection() LookDirection {
+	if item.Properties.ObservationDirection != "" {
+		return item.Properties.ObservationDirection
+	}
+	candidates := item.maybeDataAssets()
+	for _, k := range slices.Sorted(maps.Keys(candidates)) {
+		if a := candidates[k]; a.ObservationDirection != "" {
+			return LookDirection(a.ObservationDirection)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package capella_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+// loadItem decodes a captured STAC item from testdata.
+func loadItem(t *testing.T, name string) capella.STACItem {
+	t.Helper()
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	var item capella.STACItem
+	if err := json.Unmarshal(b, &item); err != nil {
+		t.Fatalf("decode %s: %v", name, err)
+	}
+	return item
+}
+
+func TestSTACItemAssets_Current(t *testing.T) {
+	item := loadItem(t, "stac_item_current.json")
+
+	data := item.DataAssets()
+	if len(data) != 1 || !strings.HasSuffix(data[0].Href, "_20240611093528.tif") {
+		t.Errorf("unexpected data assets: %+v", data)
+	}
+	if a, ok := item.AssetByRole(capella.AssetRoleMetadata); !ok || !strings.HasSuffix(a.Href, "_extended.json") {
+		t.Errorf("expected the metadata asset, got %+v, %v", a, ok)
+	}
+	if thumb, ok := item.Thumbnail(); !ok || !strings.HasSuffix(thumb.Href, "_thumb.png") {
+		t.Errorf("expected the thumbnail asset, got %+v, %v", thumb, ok)
+	}
+	if _, ok := item.AssetByRole("visual"); ok {
+		t.Error("expected no asset with the visual role")
+	}
+
+	pols := item.PolarizationAssets()
+	if len(pols) != 1 || pols[capella.PolarizationVV].Href != data[0].Href {
+		t.Errorf("unexpected polarization assets: %+v", pols)
+	}
+	if got, want := item.Size(), int64(268435456+98304); got != want {
+		t.Errorf("Size() = %d, want %d", got, want)
+	}
+
+	// Item properties win over asset metadata.
+	if got := item.IncidenceAngleDeg(); got != 41.2 {
+		t.Errorf("IncidenceAngleDeg() = %v, want 41.2", got)
+	}
+	if got := item.LookDirection(); got != capella.LookLeft {
+		t.Errorf("LookDirection() = %q, want left", got)
+	}
+}
+
+func TestSTACItemAssets_Legacy(t *testing.T) {
+	item := loadItem(t, "stac_item_legacy.json")
+
+	// Legacy items set no roles, so only key-based lookups find assets.
+	if data := item.DataAssets(); len(data) != 0 {
+		t.Errorf("expected no role-tagged data assets, got %+v", data)
+	}
+	if thumb, ok := item.Thumbnail(); !ok || !strings.HasSuffix(thumb.Href, "_thumb.png") {
+		t.Errorf("expected the thumbnail by key, got %+v, %v", thumb, ok)
+	}
+
+	pols := item.PolarizationAssets()
+	if len(pols) != 1 || !strings.HasSuffix(pols[capella.PolarizationHH].Href, "_20220320114013.tif") {
+		t.Errorf("expected the HH asset by key, got %+v", pols)
+	}
+	if got, want := item.Size(), int64(524288000+120000); got != want {
+		t.Errorf("Size() = %d, want %d", got, want)
+	}
+
+	// The properties lack view and observation metadata; the data asset has it.
+	if item.Properties.IncidenceAngle != 0 || item.Properties.ObservationDirection != "" {
+		t.Fatal("fixture should not set incidence angle or look direction properties")
+	}
+	if got := item.IncidenceAngleDeg(); got != 34.8 {
+		t.Errorf("IncidenceAngleDeg() = %v, want 34.8 from the asset", got)
+	}
+	if got := item.LookDirection(); got != capella.LookRight {
+		t.Errorf("LookDirection() = %q, want right from the asset", got)
+	}
+}
+
+func TestSTACItemAssets_Empty(t *testing.T) {
+	var item capella.STACItem
+	if _, ok := item.Thumbnail(); ok {
+		t.Error("expected no thumbnail")
+	}
+	if len(item.PolarizationAssets()) != 0 || item.Size() != 0 || item.IncidenceAngleDeg() != 0 || item.LookDirection() != "" {
+		t.Error("expected zero values for an item without assets")
+	}
+}
@@ -76,46 +76,63 @@ func (a *AuthClient) GetToken(ctx context.Context) (string, error) {
 	return token, nil
 }
 
-// refreshToken exchanges credentials for a new JWT token.
+// refreshToken exchanges credentials for a new JWT token. A token URL under
+// the client's base URL follows its regional failover (see WithBaseURLs);
+// the password grant is safe to repeat.
 func (a *AuthClient) refreshToken(ctx context.Context) (string, error) {
+	u, err := url.Parse(a.tokenURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse token URL: %w", err)
+	}
+
+	var tokenResp TokenResponse
+	err = a.client.call(ctx, http.MethodPost, u, []CallOption{WithFailover()}, func(ctx context.Context, u *url.URL) error {
+		return a.requestToken(ctx, u, &tokenResp)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if tokenResp.AccessToken == "" {
+		return "", fmt.Errorf("empty access token in response")
+	}
+
+	a.token = tokenResp.AccessToken
+	a.expiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+
+	return a.token, nil
+}
+
+// requestToken sends the password grant to the token endpoint at u.
+func (a *AuthClient) requestToken(ctx context.Context, u *url.URL, tokenResp *TokenResponse) error {
 	data := url.Values{}
 	data.Set("grant_type", "password")
 	data.Set("username", a.username)
 	data.Set("password", a.password)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(data.Encode()))
 	if err != nil {
-		return "", fmt.Errorf("failed to create token request: %w", err)
+		return fmt.Errorf("failed to create token request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := a.client.HTTPClient().Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to execute token request: %w", err)
+		return fmt.Errorf("failed to execute token request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", parseError(resp)
+		return parseError(resp)
 	}
 
-	var tokenResp TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return "", fmt.Errorf("failed to decode token response: %w", err)
-	}
-
-	if tokenResp.AccessToken == "" {
-		return "", fmt.Errorf("empty access token in response")
+	if err := json.NewDecoder(resp.Body).Decode(tokenResp); err != nil {
+		return fmt.Errorf("failed to decode token response: %w", err)
 	}
-
-	a.token = tokenResp.AccessToken
-	a.expiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
-
-	return a.token, nil
+	return nil
 }
 
-
 // APIKey represents an API key.
 type APIKey struct {
 	ID          string    `json:"id"`
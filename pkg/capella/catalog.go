@@ -2,12 +2,18 @@ package capella
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"iter"
 	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
 
 
@@ -19,6 +25,8 @@ import (
 type STACItem struct {
 	ID         string            `json:"id"`
 	Type       string            `json:"type"` // always "Feature"
+	StacVersion    string        `json:"stac_version,omitempty"`
+	StacExtensions []string      `json:"stac_extensions,omitempty"`
 	Geometry   *geojson.Geometry          `json:"geometry"`
 	BBox       []float64         `json:"bbox,omitempty"`
 	Properties STACProperties    `json:"properties"`
@@ -31,10 +39,10 @@ type STACItem struct {
 type STACProperties struct {
 	// Core properties
 	DateTime      time.Time `json:"datetime"`
-	StartDateTime time.Time `json:"start_datetime,omitempty"`
-	EndDateTime   time.Time `json:"end_datetime,omitempty"`
-	Created       time.Time `json:"created,omitempty"`
-	Updated       time.Time `json:"updated,omitempty"`
+	StartDateTime time.Time `json:"start_datetime,omitzero"`
+	EndDateTime   time.Time `json:"end_datetime,omitzero"`
+	Created       time.Time `json:"created,omitzero"`
+	Updated       time.Time `json:"updated,omitzero"`
 	Title         string    `json:"title,omitempty"`
 	Description   string    `json:"description,omitempty"`
 
@@ -61,7 +69,7 @@ type STACProperties struct {
 	OrbitState       OrbitState `json:"sat:orbit_state,omitempty"`
 	RelativeOrbit    int        `json:"sat:relative_orbit,omitempty"`
 	AbsoluteOrbit    int        `json:"sat:absolute_orbit,omitempty"`
-	AnxDatetime      time.Time  `json:"sat:anx_datetime,omitempty"`
+	AnxDatetime      time.Time  `json:"sat:anx_datetime,omitzero"`
 
 	// View properties (view:*)
 	IncidenceAngle float64 `json:"view:incidence_angle,omitempty"`
@@ -77,6 +85,7 @@ type STACProperties struct {
 type STACCollection struct {
 	ID          string                 `json:"id"`
 	Type        string                 `json:"type"` // "Collection"
+	StacVersion string                 `json:"stac_version,omitempty"`
 	Title       string                 `json:"title,omitempty"`
 	Description string                 `json:"description,omitempty"`
 	Keywords    []string               `json:"keywords,omitempty"`
@@ -106,11 +115,28 @@ type STACSpatialExtent struct {
 	BBox [][]float64 `json:"bbox"`
 }
 
-// STACTemporalExtent represents the temporal extent.
+// STACTemporalExtent represents the temporal extent. An empty string in an
+// interval is an open bound; it is encoded as null, as STAC requires.
 type STACTemporalExtent struct {
 	Interval [][]string `json:"interval"`
 }
 
+// MarshalJSON implements json.Marshaler, encoding open bounds as null.
+func (e STACTemporalExtent) MarshalJSON() ([]byte, error) {
+	interval := make([][]*string, len(e.Interval))
+	for i, bounds := range e.Interval {
+		interval[i] = make([]*string, len(bounds))
+		for j := range bounds {
+			if bounds[j] != "" {
+				interval[i][j] = &bounds[j]
+			}
+		}
+	}
+	return json.Marshal(struct {
+		Interval [][]*string `json:"interval"`
+	}{interval})
+}
+
 // ----------------------------------------------------------------------------
 // Search Parameters
 // ----------------------------------------------------------------------------
@@ -173,7 +199,7 @@ type SearchContext struct {
 // CatalogSearch performs a STAC catalog search.
 func (c *Client) CatalogSearch(ctx context.Context, params SearchParams) (*SearchResponse, error) {
 	var resp SearchResponse
-	if err := c.Do(ctx, http.MethodPost, "/catalog/search", 0, params, &resp); err != nil {
+	if err := c.Do(ctx, http.MethodPost, "/catalog/search", 0, params, &resp, WithFailover()); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -266,6 +292,85 @@ func (c *Client) GetCollection(ctx context.Context, collectionID string) (*STACC
 	return &resp, nil
 }
 
+// GetItem retrieves a STAC item from a collection by ID.
+func (c *Client) GetItem(ctx context.Context, collectionID, itemID string) (*STACItem, error) {
+	var resp STACItem
+	p := "/catalog/collections/" + url.PathEscape(collectionID) + "/items/" + url.PathEscape(itemID)
+	if err := c.Do(ctx, http.MethodGet, p, 0, nil, &resp, WithFailover()); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ----------------------------------------------------------------------------
+// Items by Collect
+// ----------------------------------------------------------------------------
+
+// collectIDBatchSize is the number of collect IDs sent in one "in" query;
+// the query extension limits the values of an "in" filter.
+const collectIDBatchSize = 50
+
+// UnmatchedCollectsError is yielded last by GetItemsByCollectIDs when some
+// collect IDs matched no catalog items, e.g. because they are not indexed
+// yet. The items of the other collects have been yielded before it.
+type UnmatchedCollectsError struct {
+	CollectIDs []string
+}
+
+func (e *UnmatchedCollectsError) Error() string {
+	return fmt.Sprintf("%d collect(s) matched no catalog items: %s", len(e.CollectIDs), strings.Join(e.CollectIDs, ", "))
+}
+
+// GetItemsByCollectIDs returns an iterator over the catalog items of the
+// given collects. The IDs are searched in batches of 50 with a
+// capella:collect_id "in" query, so any number can be passed. Items are
+// yielded in no particular order, each at most once. Collects that matched
+// no items are reported by a final *UnmatchedCollectsError.
+func (c *Client) GetItemsByCollectIDs(ctx context.Context, collectIDs []string) iter.Seq2[STACItem, error] {
+	return func(yield func(STACItem, error) bool) {
+		var ids []string
+		seen := make(map[string]bool, len(collectIDs))
+		for _, id := range collectIDs {
+			if id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+
+		matched := make(map[string]bool, len(ids))
+		yielded := make(map[string]bool)
+		for batch := range slices.Chunk(ids, collectIDBatchSize) {
+			params := SearchParams{
+				Query: map[string]any{"capella:collect_id": QueryFilter(QueryIn, batch)},
+			}
+			for item, err := range c.CatalogSearchItems(ctx, params) {
+				if err != nil {
+					yield(STACItem{}, err)
+					return
+				}
+				matched[item.Properties.CollectID] = true
+				if yielded[item.ID] {
+					continue
+				}
+				yielded[item.ID] = true
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+
+		var unmatched []string
+		for _, id := range ids {
+			if !matched[id] {
+				unmatched = append(unmatched, id)
+			}
+		}
+		if len(unmatched) > 0 {
+			yield(STACItem{}, &UnmatchedCollectsError{CollectIDs: unmatched})
+		}
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Archive Export
 // ----------------------------------------------------------------------------
@@ -306,6 +411,105 @@ func (c *Client) GetArchiveExportURL(ctx context.Context, exportID string) (*Pre
 	return &resp, nil
 }
 
+// ExportStatus represents the state of an archive export job.
+type ExportStatus string
+
+const (
+	ExportPending    ExportStatus = "pending"
+	ExportProcessing ExportStatus = "processing"
+	ExportCompleted  ExportStatus = "completed"
+	ExportFailed     ExportStatus = "failed"
+)
+
+// ExportRequest requests a new archive footprint export.
+type ExportRequest struct {
+	Name        string    `json:"name,omitempty"`
+	BBox        []float64 `json:"bbox,omitempty"`
+	Datetime    string    `json:"datetime,omitempty"`
+	Collections []string  `json:"collections,omitempty"`
+	Format      string    `json:"format,omitempty"`
+}
+
+// ExportJob tracks an asynchronous archive export. Once Status is
+// ExportCompleted, ExportID can be passed to GetArchiveExportURL.
+type ExportJob struct {
+	ID        string       `json:"id"`
+	Status    ExportStatus `json:"status"`
+	ExportID  string       `json:"exportId,omitempty"`
+	Message   string       `json:"message,omitempty"`
+	CreatedAt time.Time    `json:"createdAt,omitempty"`
+	UpdatedAt time.Time    `json:"updatedAt,omitempty"`
+
+	// Location is the URL to poll for the job, from the Location header.
+	Location string `json:"-"`
+}
+
+// RequestArchiveExport starts an archive footprint export. The API accepts
+// the request with 202 and a Location header naming the job; the job ID is
+// taken from the response body or, if absent, the last Location path segment.
+// GetExportJob polls the Location while the job runs, if it is on the API
+// host.
+func (c *Client) RequestArchiveExport(ctx context.Context, req ExportRequest) (*ExportJob, error) {
+	var job ExportJob
+	loc, err := c.DoAsync(ctx, http.MethodPost, c.BuildURL("/catalog/archive-export"), req, &job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request archive export: %w", err)
+	}
+	job.Location = loc.String()
+	if job.ID == "" {
+		job.ID = path.Base(loc.Path)
+	}
+	if job.Status == "" {
+		job.Status = ExportPending
+	}
+	// Credentials are only sent to the API host, so a Location elsewhere is
+	// not polled.
+	if strings.EqualFold(loc.Host, c.BaseURL().Host) {
+		c.exportLocations.Store(job.ID, loc)
+	}
+	return &job, nil
+}
+
+// GetExportJob retrieves the state of an archive export job, from the
+// Location returned by RequestArchiveExport when this client started the job,
+// or else from /catalog/archive-export/jobs/{jobID}.
+func (c *Client) GetExportJob(ctx context.Context, jobID string) (*ExportJob, error) {
+	u := c.BuildURL("/catalog/archive-export/jobs/" + url.PathEscape(jobID))
+	if loc, ok := c.exportLocations.Load(jobID); ok {
+		u = loc.(*url.URL)
+	}
+	var job ExportJob
+	if err := c.DoRaw(ctx, http.MethodGet, u, nil, 0, &job); err != nil {
+		return nil, err
+	}
+	job.Location = u.String()
+	if job.Status == ExportCompleted || job.Status == ExportFailed {
+		c.exportLocations.Delete(jobID)
+	}
+	return &job, nil
+}
+
+// WaitForExport polls an archive export job until it completes or fails. A
+// failed job is returned together with an error.
+func (c *Client) WaitForExport(ctx context.Context, jobID string, opts WaitOptions) (*ExportJob, error) {
+	var job *ExportJob
+	err := common.Poll(ctx, opts, pollStep(func(ctx context.Context) (bool, error) {
+		var err error
+		job, err = c.GetExportJob(ctx, jobID)
+		if err != nil {
+			return false, err
+		}
+		return job.Status == ExportCompleted || job.Status == ExportFailed, nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == ExportFailed {
+		return job, fmt.Errorf("archive export %s failed: %s", jobID, job.Message)
+	}
+	return job, nil
+}
+
 // ----------------------------------------------------------------------------
 // Query Helpers
 // ----------------------------------------------------------------------------
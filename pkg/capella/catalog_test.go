@@ -3,12 +3,17 @@ package capella_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
 
 func TestCatalogService_Search(t *testing.T) {
@@ -177,6 +182,107 @@ func TestCatalogService_GetCollection(t *testing.T) {
 	}
 }
 
+func TestCatalogService_GetItem(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodGet)
+		if r.URL.Path == "/catalog/collections/capella-geo/items/missing" {
+			jsonResponse(w, http.StatusNotFound, map[string]string{"detail": "item not found"})
+			return
+		}
+		requirePath(t, r, "/catalog/collections/capella-geo/items/CAPELLA_C13_SP_GEO_HH")
+		requireAuth(t, r, "test-api-key")
+
+		jsonResponse(w, http.StatusOK, capella.STACItem{
+			ID:         "CAPELLA_C13_SP_GEO_HH",
+			Type:       "Feature",
+			Collection: "capella-geo",
+			Properties: capella.STACProperties{CollectID: "col-1"},
+		})
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	item, err := cli.GetItem(context.Background(), "capella-geo", "CAPELLA_C13_SP_GEO_HH")
+	if err != nil {
+		t.Fatalf("GetItem failed: %v", err)
+	}
+	if item.ID != "CAPELLA_C13_SP_GEO_HH" || item.Properties.CollectID != "col-1" {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	if _, err := cli.GetItem(context.Background(), "capella-geo", "missing"); !capella.IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestCatalogService_GetItemsByCollectIDs(t *testing.T) {
+	var batches [][]string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPost)
+		requirePath(t, r, "/catalog/search")
+
+		var params capella.SearchParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Fatalf("decode search: %v", err)
+		}
+		filter, _ := params.Query["capella:collect_id"].(map[string]any)
+		values, _ := filter["in"].([]any)
+		var batch []string
+		resp := capella.SearchResponse{Type: "FeatureCollection"}
+		for _, v := range values {
+			id := v.(string)
+			batch = append(batch, id)
+			if id == "col-7" {
+				continue // not indexed
+			}
+			resp.Features = append(resp.Features, capella.STACItem{
+				ID: "item-" + id, Type: "Feature", Properties: capella.STACProperties{CollectID: id},
+			})
+		}
+		// col-0 has a second item, returned by every search.
+		resp.Features = append(resp.Features, capella.STACItem{
+			ID: "item-col-0-slc", Type: "Feature", Properties: capella.STACProperties{CollectID: "col-0"},
+		})
+		batches = append(batches, batch)
+		jsonResponse(w, http.StatusOK, resp)
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	var ids []string
+	for i := range 51 {
+		ids = append(ids, fmt.Sprintf("col-%d", i))
+	}
+	ids = append(ids, "col-3", "") // duplicates and blanks are dropped
+
+	seen := make(map[string]int)
+	var unmatched *capella.UnmatchedCollectsError
+	for item, err := range cli.GetItemsByCollectIDs(context.Background(), ids) {
+		if err != nil {
+			if !errors.As(err, &unmatched) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			continue
+		}
+		seen[item.ID]++
+	}
+
+	if len(batches) != 2 || len(batches[0]) != 50 || len(batches[1]) != 1 || batches[1][0] != "col-50" {
+		t.Errorf("expected batches of 50 and 1 IDs, got %d batches", len(batches))
+	}
+	if len(seen) != 51 {
+		t.Errorf("expected 51 distinct items, got %d", len(seen))
+	}
+	for id, n := range seen {
+		if n != 1 {
+			t.Errorf("item %s yielded %d times", id, n)
+		}
+	}
+	if unmatched == nil || len(unmatched.CollectIDs) != 1 || unmatched.CollectIDs[0] != "col-7" {
+		t.Errorf("expected col-7 reported as unmatched, got %v", unmatched)
+	}
+}
+
 func TestCatalogService_ListArchiveExports(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		requireMethod(t, r, http.MethodGet)
@@ -226,6 +332,159 @@ func TestCatalogService_GetArchiveExportURL(t *testing.T) {
 	}
 }
 
+func TestCatalogService_RequestArchiveExport(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPost)
+		requirePath(t, r, "/catalog/archive-export")
+
+		var req capella.ExportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Name != "aoi export" || len(req.BBox) != 4 {
+			t.Errorf("unexpected request: %+v", req)
+		}
+
+		w.Header().Set("Location", "/catalog/archive-export/jobs/job-42")
+		w.WriteHeader(http.StatusAccepted)
+	}
+
+	cli, srv := newTestClient(t, handler)
+
+	job, err := cli.RequestArchiveExport(context.Background(), capella.ExportRequest{
+		Name: "aoi export",
+		BBox: []float64{-110, 39.5, -105, 40.5},
+	})
+	if err != nil {
+		t.Fatalf("RequestArchiveExport failed: %v", err)
+	}
+	if job.ID != "job-42" || job.Status != capella.ExportPending {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if want := srv.URL + "/catalog/archive-export/jobs/job-42"; job.Location != want {
+		t.Errorf("expected location %s, got %s", want, job.Location)
+	}
+}
+
+func TestCatalogService_RequestArchiveExport_BodyAndStatus(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "https://jobs.example.com/exports/ignored")
+		jsonResponse(w, http.StatusAccepted, map[string]string{"id": "job-7", "status": "processing"})
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	job, err := cli.RequestArchiveExport(context.Background(), capella.ExportRequest{Name: "x"})
+	if err != nil {
+		t.Fatalf("RequestArchiveExport failed: %v", err)
+	}
+	if job.ID != "job-7" || job.Status != capella.ExportProcessing || job.Location != "https://jobs.example.com/exports/ignored" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+}
+
+func TestCatalogService_RequestArchiveExport_MissingLocation(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	_, err := cli.RequestArchiveExport(context.Background(), capella.ExportRequest{Name: "x"})
+	if !errors.Is(err, common.ErrNoLocation) {
+		t.Errorf("expected ErrNoLocation, got %v", err)
+	}
+}
+
+func TestCatalogService_RequestArchiveExport_Rejected(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		validationError(w, "bbox", "invalid bbox")
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	_, err := cli.RequestArchiveExport(context.Background(), capella.ExportRequest{Name: "x"})
+	if !capella.IsValidationError(err) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
+
+// exportJobHandler serves an export job that reports processing for the
+// first polls and then the final status.
+func exportJobHandler(t *testing.T, polls int, final capella.ExportJob) http.HandlerFunc {
+	var n int
+	return func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodGet)
+		requirePath(t, r, "/catalog/archive-export/jobs/job-42")
+		n++
+		if n < polls {
+			jsonResponse(w, http.StatusOK, capella.ExportJob{ID: "job-42", Status: capella.ExportProcessing})
+			return
+		}
+		jsonResponse(w, http.StatusOK, final)
+	}
+}
+
+func TestCatalogService_WaitForExport(t *testing.T) {
+	cli, _ := newTestClient(t, exportJobHandler(t, 3, capella.ExportJob{ID: "job-42", Status: capella.ExportCompleted, ExportID: "export-9"}))
+
+	job, err := cli.WaitForExport(context.Background(), "job-42", capella.WaitOptions{PollInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("WaitForExport failed: %v", err)
+	}
+	if job.Status != capella.ExportCompleted || job.ExportID != "export-9" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+}
+
+func TestCatalogService_WaitForExport_Failed(t *testing.T) {
+	cli, _ := newTestClient(t, exportJobHandler(t, 2, capella.ExportJob{ID: "job-42", Status: capella.ExportFailed, Message: "bbox too large"}))
+
+	job, err := cli.WaitForExport(context.Background(), "job-42", capella.WaitOptions{PollInterval: time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "bbox too large") {
+		t.Fatalf("expected failure error, got %v", err)
+	}
+	if job == nil || job.Status != capella.ExportFailed {
+		t.Errorf("expected the failed job to be returned, got %+v", job)
+	}
+}
+
+func TestCatalogService_GetExportJob_Location(t *testing.T) {
+	var polled []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.Header().Set("Location", "/catalog/archive-export/status/job-42")
+			jsonResponse(w, http.StatusAccepted, map[string]string{"id": "job-42"})
+		case http.MethodGet:
+			polled = append(polled, r.URL.EscapedPath())
+			jsonResponse(w, http.StatusOK, capella.ExportJob{ID: "job-42", Status: capella.ExportCompleted})
+		}
+	}
+	cli, _ := newTestClient(t, handler)
+
+	if _, err := cli.RequestArchiveExport(context.Background(), capella.ExportRequest{Name: "x"}); err != nil {
+		t.Fatalf("RequestArchiveExport failed: %v", err)
+	}
+	// The job is polled at its Location until it finishes, then by ID.
+	for range 2 {
+		if _, err := cli.GetExportJob(context.Background(), "job-42"); err != nil {
+			t.Fatalf("GetExportJob failed: %v", err)
+		}
+	}
+	if _, err := cli.GetExportJob(context.Background(), "a/b"); err != nil {
+		t.Fatalf("GetExportJob failed: %v", err)
+	}
+	want := []string{
+		"/catalog/archive-export/status/job-42",
+		"/catalog/archive-export/jobs/job-42",
+		"/catalog/archive-export/jobs/a%2Fb",
+	}
+	if !slices.Equal(polled, want) {
+		t.Errorf("polled %q, want %q", polled, want)
+	}
+}
+
 func TestSearchBuilder(t *testing.T) {
 	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	end := time.Date(2024, 6, 30, 23, 59, 59, 0, time.UTC)
@@ -16,7 +16,12 @@
 package capella
 
 import (
+	"crypto/tls"
+	"fmt"
 	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
@@ -25,11 +30,33 @@ import (
 const (
 	defaultBaseURL = "https://api.capellaspace.com"
 	defaultTimeout = 30 * time.Second
+
+	// SandboxBaseURL is the Capella sandbox API endpoint for testing.
+	SandboxBaseURL = "https://api.sandbox.capellaspace.com"
 )
 
 // Client is the Capella Space API client. It is thread-safe.
 type Client struct {
 	*common.Client
+
+	typesCache *collectionTypesCache
+
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+
+	environment  Environment
+	confirmWrite func() bool
+
+	quota QuotaTracker
+
+	failover *failover
+
+	servedVersion  *atomic.Pointer[string]
+	schemaWarnings func(WarningEvent)
+
+	// exportLocations maps the IDs of export jobs started with
+	// RequestArchiveExport to the job URL from the Location header.
+	exportLocations *sync.Map
 }
 
 // clientConfig holds configuration for building a Client.
@@ -39,6 +66,23 @@ type clientConfig struct {
 	auth       common.Authenticator
 	userAgent  string
 	timeout    time.Duration
+	proxy      *url.URL
+	tlsConfig  *tls.Config
+	typesTTL   time.Duration
+
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+
+	environment  Environment
+	confirmWrite func() bool
+
+	quota QuotaTracker
+
+	fallbackURLs  []string
+	probeInterval time.Duration
+
+	apiVersion     string
+	schemaWarnings func(WarningEvent)
 }
 
 // Option is a function that configures a Client.
@@ -73,6 +117,7 @@ func WithAuth(auth common.Authenticator) Option {
 }
 
 // WithUserAgent sets the User-Agent header for API requests.
+// Defaults to "go-sar-vendor/<version> (+capella)".
 func WithUserAgent(userAgent string) Option {
 	return func(c *clientConfig) {
 		c.userAgent = userAgent
@@ -86,18 +131,108 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithReadTimeout sets the default per-request timeout for GET and HEAD
+// requests, e.g. status polls. Calls can override it with WithRequestTimeout.
+// Per-request timeouts cannot exceed the HTTP client timeout (see
+// WithTimeout). Zero, the default, leaves requests bounded by the HTTP client
+// timeout only.
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(c *clientConfig) {
+		c.readTimeout = timeout
+	}
+}
+
+// WithWriteTimeout sets the default per-request timeout for all other
+// requests, e.g. POST searches and task submissions. It is otherwise like
+// WithReadTimeout.
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(c *clientConfig) {
+		c.writeTimeout = timeout
+	}
+}
+
+// WithProxy routes requests through the given proxy, composing with the
+// default transport (or the transport of a client set via WithHTTPClient).
+func WithProxy(proxy *url.URL) Option {
+	return func(c *clientConfig) {
+		c.proxy = proxy
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for outgoing connections,
+// e.g. to trust a custom CA. It composes with the default transport.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *clientConfig) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// WithCollectionTypesTTL sets how long ValidateTask caches the collection
+// types it fetches. Defaults to one hour.
+func WithCollectionTypesTTL(ttl time.Duration) Option {
+	return func(c *clientConfig) {
+		c.typesTTL = ttl
+	}
+}
+
+// WithEnvironment sets the environment the client reports, e.g. production
+// for a client that reaches production through a proxy. By default it is
+// derived from the base URL.
+func WithEnvironment(env Environment) Option {
+	return func(c *clientConfig) {
+		c.environment = env
+	}
+}
+
+// WithProductionConfirmation makes write operations (CreateTask,
+// CreateAccessRequest and ApproveTask) against production call confirm first
+// and fail with ErrProductionWriteRefused unless it returns true. Clients for
+// other environments are not affected.
+func WithProductionConfirmation(confirm func() bool) Option {
+	return func(c *clientConfig) {
+		c.confirmWrite = confirm
+	}
+}
+
+// WithQuotaTracker makes CreateTask reserve the task's collection tier with t
+// before submitting it, confirming the reservation when the task is created
+// and releasing it when creation fails.
+func WithQuotaTracker(t QuotaTracker) Option {
+	return func(c *clientConfig) {
+		c.quota = t
+	}
+}
+
 // NewClient creates a new Capella Space API client.
 // It uses sensible defaults which can be overridden with functional options.
 func NewClient(opts ...Option) (*Client, error) {
 	cfg := &clientConfig{
-		baseURL: defaultBaseURL,
-		timeout: defaultTimeout,
+		baseURL:   defaultBaseURL,
+		timeout:   defaultTimeout,
+		userAgent: common.DefaultUserAgent("capella"),
 	}
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
-	httpClient := common.EnsureHTTPClient(cfg.httpClient, cfg.timeout)
+	httpClient, err := common.ConfigureTransport(
+		common.EnsureHTTPClient(cfg.httpClient, cfg.timeout), cfg.proxy, cfg.tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, err := url.Parse(cfg.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	var fo *failover
+	if len(cfg.fallbackURLs) > 0 {
+		if fo, err = newFailover(baseURL, cfg.fallbackURLs, cfg.probeInterval); err != nil {
+			return nil, fmt.Errorf("invalid fallback URL: %w", err)
+		}
+	}
+	servedVersion := new(atomic.Pointer[string])
+	httpClient = withVersionTransport(httpClient, fo.hosts(baseURL), cfg.apiVersion, servedVersion)
 
 	c, err := common.NewClient(common.ClientConfig{
 		BaseURL:    cfg.baseURL,
@@ -109,6 +244,35 @@ func NewClient(opts ...Option) (*Client, error) {
 		return nil, err
 	}
 
-	return &Client{Client: c}, nil
+	env := cfg.environment
+	if env == "" {
+		env = environmentOf(cfg.baseURL)
+	}
+
+	return &Client{
+		Client:         c,
+		typesCache:     &collectionTypesCache{ttl: cfg.typesTTL},
+		readTimeout:    cfg.readTimeout,
+		writeTimeout:   cfg.writeTimeout,
+		environment:    env,
+		confirmWrite:   cfg.confirmWrite,
+		quota:          cfg.quota,
+		failover:       fo,
+		servedVersion:  servedVersion,
+		schemaWarnings: cfg.schemaWarnings,
+
+		exportLocations: new(sync.Map),
+	}, nil
 }
 
+// NewSandboxClient creates a client for the Capella sandbox environment,
+// authenticated with the given API key. Token authentication via
+// NewAuthClient uses the sandbox token endpoint, since it derives it from the
+// base URL.
+func NewSandboxClient(apiKey string, opts ...Option) (*Client, error) {
+	sandboxOpts := []Option{
+		WithBaseURL(SandboxBaseURL),
+		WithAPIKey(apiKey),
+	}
+	return NewClient(append(sandboxOpts, opts...)...)
+}
@@ -1,7 +1,11 @@
 package capella_test
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -44,6 +48,9 @@ func TestClient_RequestHeaders(t *testing.T) {
 		if r.Header.Get("Accept") != "application/json" {
 			t.Errorf("expected Accept header 'application/json', got %q", r.Header.Get("Accept"))
 		}
+		if ua := r.Header.Get("User-Agent"); !strings.HasSuffix(ua, "(+capella)") {
+			t.Errorf("expected default User-Agent ending in '(+capella)', got %q", ua)
+		}
 
 		jsonResponse(w, http.StatusOK, map[string]string{"status": "ok"})
 	}
@@ -57,6 +64,28 @@ func TestClient_RequestHeaders(t *testing.T) {
 	}
 }
 
+func TestClient_WithTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, http.StatusOK, map[string]any{"collections": []any{}})
+	}))
+	t.Cleanup(srv.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	cli, err := capella.NewClient(
+		capella.WithBaseURL(srv.URL),
+		capella.WithAPIKey("test-api-key"),
+		capella.WithTLSConfig(&tls.Config{RootCAs: pool}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	if _, err := cli.ListCollections(t.Context()); err != nil {
+		t.Fatalf("unexpected error with custom CA: %v", err)
+	}
+}
+
 func TestClient_ErrorHandling(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -0,0 +1,129 @@
+package capella
+
+import (
+	"slices"
+	"time"
+
+	"github.com/paulmach/orb"
+)
+
+// A single collect is published as several STAC items, one per product type
+// (e.g. GEO, SICD and SIDD). The helpers below group and deduplicate catalog
+// results by capella:collect_id. Items without a collect ID are never grouped
+// with each other.
+
+// GroupItemsByCollect groups items by their collect ID, keeping the order in
+// which they appear. Items without a collect ID are returned, in order, in
+// ungrouped.
+func GroupItemsByCollect(items []STACItem) (groups map[string][]STACItem, ungrouped []STACItem) {
+	groups = make(map[string][]STACItem)
+	for _, item := range items {
+		if id := item.Properties.CollectID; id != "" {
+			groups[id] = append(groups[id], item)
+		} else {
+			ungrouped = append(ungrouped, item)
+		}
+	}
+	return groups, ungrouped
+}
+
+// PreferProductTypes returns a preference for DeduplicateByCollect that picks
+// the item whose product type comes first in order. Items of other product
+// types rank last; ties keep the earlier item.
+func PreferProductTypes(order ...ProductType) func(a, b STACItem) STACItem {
+	rank := func(item STACItem) int {
+		if i := slices.Index(order, item.Properties.ProductType); i >= 0 {
+			return i
+		}
+		return len(order)
+	}
+	return func(a, b STACItem) STACItem {
+		if rank(b) < rank(a) {
+			return b
+		}
+		return a
+	}
+}
+
+// DeduplicateByCollect keeps one item per collect, chosen by folding each
+// collect's items with prefer, which is called with the current pick and the
+// next item. A nil prefer picks the GEO product. The result keeps the
+// position of each collect's first item; items without a collect ID are
+// passed through unchanged.
+func DeduplicateByCollect(items []STACItem, prefer func(a, b STACItem) STACItem) []STACItem {
+	if prefer == nil {
+		prefer = PreferProductTypes(ProductGEO)
+	}
+	out := make([]STACItem, 0, len(items))
+	index := make(map[string]int)
+	for _, item := range items {
+		id := item.Properties.CollectID
+		if id == "" {
+			out = append(out, item)
+			continue
+		}
+		if i, ok := index[id]; ok {
+			out[i] = prefer(out[i], item)
+			continue
+		}
+		index[id] = len(out)
+		out = append(out, item)
+	}
+	return out
+}
+
+// CollectSummary describes a collect for listings, combining the items
+// published for it.
+type CollectSummary struct {
+	CollectID      string
+	DateTime       time.Time
+	Center         orb.Point     // Center of the footprint's bounding box
+	ProductTypes   []ProductType // Sorted product types available
+	IncidenceAngle float64       // Degrees
+	Items          []STACItem
+}
+
+// SummarizeCollect summarizes the items of one collect. Properties are taken
+// from the first item that sets them.
+func SummarizeCollect(items []STACItem) CollectSummary {
+	var s CollectSummary
+	for _, item := range items {
+		if s.CollectID == "" {
+			s.CollectID = item.Properties.CollectID
+		}
+		if s.DateTime.IsZero() {
+			s.DateTime = item.Properties.DateTime
+		}
+		if s.Center == (orb.Point{}) && item.Geometry != nil && item.Geometry.Geometry() != nil {
+			s.Center = item.Geometry.Geometry().Bound().Center()
+		}
+		if s.IncidenceAngle == 0 {
+			s.IncidenceAngle = item.IncidenceAngleDeg()
+		}
+		if pt := item.Properties.ProductType; pt != "" && !slices.Contains(s.ProductTypes, pt) {
+			s.ProductTypes = append(s.ProductTypes, pt)
+		}
+	}
+	slices.Sort(s.ProductTypes)
+	s.Items = items
+	return s
+}
+
+// SummarizeCollects summarizes items per collect, in the order each collect
+// first appears. Each item without a collect ID gets a summary of its own.
+func SummarizeCollects(items []STACItem) []CollectSummary {
+	groups, _ := GroupItemsByCollect(items)
+	var out []CollectSummary
+	seen := make(map[string]bool)
+	for _, item := range items {
+		id := item.Properties.CollectID
+		switch {
+		case id == "":
+			out = append(out, SummarizeCollect([]STACItem{item}))
+		case !seen[id]:
+			seen[id] = true
+			out = append(out, SummarizeCollect(groups[id]))
+		}
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package capella_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+// collectItem returns a catalog item of the given collect and product type.
+func collectItem(id, collectID string, pt capella.ProductType) capella.STACItem {
+	item := capella.STACItem{ID: id, Type: "Feature"}
+	item.Properties.CollectID = collectID
+	item.Properties.ProductType = pt
+	return item
+}
+
+// collectResults mixes three products of collect c1, two of c2 (without a
+// GEO product) and two items without a collect ID.
+func collectResults() []capella.STACItem {
+	return []capella.STACItem{
+		collectItem("c1-sicd", "c1", capella.ProductSICD),
+		collectItem("loose-1", "", capella.ProductGEO),
+		collectItem("c2-sidd", "c2", capella.ProductSIDD),
+		collectItem("c1-geo", "c1", capella.ProductGEO),
+		collectItem("c2-sicd", "c2", capella.ProductSICD),
+		collectItem("c1-sidd", "c1", capella.ProductSIDD),
+		collectItem("loose-2", "", capella.ProductSICD),
+	}
+}
+
+func itemIDs(items []capella.STACItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func TestGroupItemsByCollect(t *testing.T) {
+	groups, ungrouped := capella.GroupItemsByCollect(collectResults())
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 collects, got %d", len(groups))
+	}
+	if got := itemIDs(groups["c1"]); !slices.Equal(got, []string{"c1-sicd", "c1-geo", "c1-sidd"}) {
+		t.Errorf("unexpected c1 items: %v", got)
+	}
+	if _, ok := groups[""]; ok {
+		t.Error("expected items without a collect ID not to be grouped")
+	}
+	if got := itemIDs(ungrouped); !slices.Equal(got, []string{"loose-1", "loose-2"}) {
+		t.Errorf("unexpected ungrouped items: %v", got)
+	}
+}
+
+func TestDeduplicateByCollect(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefer func(a, b capella.STACItem) capella.STACItem
+		want   []string
+	}{
+		{"default prefers GEO", nil, []string{"c1-geo", "loose-1", "c2-sidd", "loose-2"}},
+		{"custom order", capella.PreferProductTypes(capella.ProductSICD, capella.ProductGEO), []string{"c1-sicd", "loose-1", "c2-sicd", "loose-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := itemIDs(capella.DeduplicateByCollect(collectResults(), tt.prefer))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSummarizeCollects(t *testing.T) {
+	items := collectResults()
+	when := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	items[0].Properties.DateTime = when
+	items[0].Geometry = geojson.NewGeometry(orb.Polygon{{{10, 50}, {12, 50}, {12, 52}, {10, 52}, {10, 50}}})
+	items[3].Properties.IncidenceAngle = 35.5
+
+	summaries := capella.SummarizeCollects(items)
+	if len(summaries) != 4 {
+		t.Fatalf("expected 4 summaries, got %d", len(summaries))
+	}
+
+	c1 := summaries[0]
+	if c1.CollectID != "c1" || !c1.DateTime.Equal(when) {
+		t.Errorf("unexpected collect: %s at %v", c1.CollectID, c1.DateTime)
+	}
+	if c1.Center != (orb.Point{11, 51}) {
+		t.Errorf("expected center (11, 51), got %v", c1.Center)
+	}
+	if c1.IncidenceAngle != 35.5 {
+		t.Errorf("expected incidence angle 35.5, got %v", c1.IncidenceAngle)
+	}
+	want := []capella.ProductType{capella.ProductGEO, capella.ProductSICD, capella.ProductSIDD}
+	if !slices.Equal(c1.ProductTypes, want) || len(c1.Items) != 3 {
+		t.Errorf("expected products %v over 3 items, got %v over %d", want, c1.ProductTypes, len(c1.Items))
+	}
+
+	if loose := summaries[1]; loose.CollectID != "" || len(loose.Items) != 1 || loose.Items[0].ID != "loose-1" {
+		t.Errorf("expected a summary of its own for loose-1, got %+v", loose)
+	}
+	if summaries[2].CollectID != "c2" || summaries[3].Items[0].ID != "loose-2" {
+		t.Errorf("unexpected summary order: %s, %s", summaries[2].CollectID, summaries[3].Items[0].ID)
+	}
+}
@@ -0,0 +1,149 @@
+package capella
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geo"
+	"github.com/paulmach/orb/geojson"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// ----------------------------------------------------------------------------
+// Conflict Prediction
+// ----------------------------------------------------------------------------
+
+// activeTaskStatuses are the statuses of tasks that may still be collected.
+var activeTaskStatuses = []TaskStatus{
+	TaskReceived, TaskReview, TaskSubmitted, TaskApproved, TaskAccepted, TaskActive,
+}
+
+// PredictOptions controls PredictConflicts.
+type PredictOptions struct {
+	// Statuses are the statuses of existing tasks considered. Defaults to
+	// the statuses of tasks not yet finished: received, review, submitted,
+	// approved, accepted and active.
+	Statuses []TaskStatus
+
+	// OrgID restricts the search to an organization. Defaults to the
+	// proposed task's OrgID; if both are empty, every task visible to the
+	// caller is considered.
+	OrgID string
+
+	// BufferMeters, if positive, expands the proposed AOI to its bounding
+	// box padded by this distance, so that nearby tasks, such as point
+	// targets a short way apart, count as overlapping.
+	BufferMeters float64
+
+	// PageSize is the search page size (default 25).
+	PageSize int
+}
+
+// PotentialConflict is an existing task that may compete with a proposed
+// task for the same passes.
+type PotentialConflict struct {
+	Task   ConflictingTask
+	Status TaskStatus
+
+	// TemporalOverlap is how long the two windows overlap.
+	TemporalOverlap time.Duration
+	// SpatialOverlap is the fraction, from 0 to 1, of the proposed AOI
+	// covered by the existing task's geometry. A point AOI is either fully
+	// covered or not at all; an existing point task covers no area and
+	// reports 0.
+	SpatialOverlap float64
+}
+
+// PredictConflicts searches the organization's existing tasks for those that
+// may compete with proposed: tasks in one of opts.Statuses whose window
+// overlaps the proposed window and whose geometry intersects the proposed
+// AOI. They are ranked by temporal overlap, then spatial overlap, largest
+// first.
+//
+// This is an approximation computed on the client from the task search
+// endpoint. It does not know the satellites' passes, look angles or
+// capacity, so it reports tasks that Capella may schedule without conflict
+// and, with a small BufferMeters, can miss tasks close enough to share a
+// pass. The ConflictingTasks of the created task remain authoritative.
+func (c *Client) PredictConflicts(ctx context.Context, proposed TaskingRequest, opts PredictOptions) ([]PotentialConflict, error) {
+	props := proposed.Properties
+	if proposed.Geometry == nil || proposed.Geometry.Geometry() == nil {
+		return nil, errors.New("proposed task has no geometry")
+	}
+	if !props.WindowClose.After(props.WindowOpen) {
+		return nil, errors.New("proposed task window must close after it opens")
+	}
+
+	statuses := opts.Statuses
+	if len(statuses) == 0 {
+		statuses = activeTaskStatuses
+	}
+	aoi := proposed.Geometry
+	if opts.BufferMeters > 0 {
+		aoi = geojson.NewGeometry(geo.BoundPad(aoi.Geometry().Bound(), opts.BufferMeters).ToPolygon())
+	}
+
+	// The search can only bound when windows open; tasks that closed before
+	// the proposed window opens are dropped below.
+	qb := NewTaskQuery().Status(statuses...).WindowOpenBetween(time.Time{}, props.WindowClose)
+	if orgID := cmp.Or(opts.OrgID, props.OrgID); orgID != "" {
+		qb.OrgID(orgID)
+	}
+
+	var conflicts []PotentialConflict
+	for task, err := range c.SearchTasksQueryIterator(ctx, qb, opts.PageSize) {
+		if err != nil {
+			return nil, err
+		}
+		p := task.Properties
+		if p.Status != "" && !slices.Contains(statuses, p.Status) {
+			continue
+		}
+		start, end := p.WindowOpen, p.WindowClose
+		if props.WindowOpen.After(start) {
+			start = props.WindowOpen
+		}
+		if props.WindowClose.Before(end) {
+			end = props.WindowClose
+		}
+		if !end.After(start) || !common.Intersects(aoi, task.Geometry) {
+			continue
+		}
+		conflicts = append(conflicts, PotentialConflict{
+			Task: ConflictingTask{
+				TaskingRequestID:   p.TaskingRequestID,
+				TaskingRequestName: p.TaskingRequestName,
+				CollectionTier:     p.CollectionTier,
+				WindowOpen:         p.WindowOpen,
+				WindowClose:        p.WindowClose,
+			},
+			Status:          p.Status,
+			TemporalOverlap: end.Sub(start),
+			SpatialOverlap:  spatialOverlap(aoi, task.Geometry),
+		})
+	}
+
+	slices.SortStableFunc(conflicts, func(a, b PotentialConflict) int {
+		return cmp.Or(
+			cmp.Compare(b.TemporalOverlap, a.TemporalOverlap),
+			cmp.Compare(b.SpatialOverlap, a.SpatialOverlap),
+			cmp.Compare(a.Task.TaskingRequestID, b.Task.TaskingRequestID),
+		)
+	})
+	return conflicts, nil
+}
+
+// spatialOverlap returns the fraction of aoi covered by geometry, which are
+// known to intersect.
+func spatialOverlap(aoi, geometry *geojson.Geometry) float64 {
+	switch aoi.Geometry().(type) {
+	case orb.Point, orb.MultiPoint:
+		return 1
+	}
+	return common.CoveragePercent(aoi, geometry) / 100
+}
@@ -0,0 +1,192 @@
+package capella_test
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+var conflictT0 = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// aoiSquare returns a square AOI with its south-west corner at lon, lat.
+func aoiSquare(lon, lat, side float64) *geojson.Geometry {
+	return geojson.NewGeometry(orb.Polygon{{{lon, lat}, {lon + side, lat}, {lon + side, lat + side}, {lon, lat + side}, {lon, lat}}})
+}
+
+func existingTask(id string, status capella.TaskStatus, geom *geojson.Geometry, openHours, closeHours int) capella.TaskingRequestResponse {
+	var task capella.TaskingRequestResponse
+	task.Geometry = geom
+	task.Properties.TaskingRequestID = id
+	task.Properties.Status = status
+	task.Properties.CollectionTier = capella.TierStandard
+	task.Properties.WindowOpen = conflictT0.Add(time.Duration(openHours) * time.Hour)
+	task.Properties.WindowClose = conflictT0.Add(time.Duration(closeHours) * time.Hour)
+	return task
+}
+
+// conflictServer serves tasks from /tasks/search, one per page, and stores
+// the last query in query.
+func conflictServer(t *testing.T, tasks []capella.TaskingRequestResponse, query *map[string]any) *capella.Client {
+	t.Helper()
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPost)
+		requirePath(t, r, "/tasks/search")
+		var req struct {
+			Page  int            `json:"page"`
+			Query map[string]any `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode search: %v", err)
+		}
+		*query = req.Query
+		var results []capella.TaskingRequestResponse
+		if req.Page <= len(tasks) {
+			results = tasks[req.Page-1 : req.Page]
+		}
+		jsonResponse(w, http.StatusOK, capella.TaskingRequestsPagedResponse{
+			Results: results, CurrentPage: req.Page, TotalPages: len(tasks),
+		})
+	})
+	return cli
+}
+
+func TestTaskingService_PredictConflicts(t *testing.T) {
+	proposed := capella.TaskingRequest{
+		Geometry: aoiSquare(10, 50, 1),
+		Properties: capella.TaskingRequestProperties{
+			OrgID:       "org-1",
+			WindowOpen:  conflictT0.Add(24 * time.Hour),
+			WindowClose: conflictT0.Add(72 * time.Hour),
+		},
+	}
+	tasks := []capella.TaskingRequestResponse{
+		// Overlaps in time only.
+		existingTask("temporal-only", capella.TaskAccepted, aoiSquare(20, 50, 1), 0, 96),
+		// Overlaps in space only.
+		existingTask("spatial-only", capella.TaskAccepted, aoiSquare(10, 50, 1), 72, 96),
+		// Half the AOI for the whole window.
+		existingTask("full-half", capella.TaskActive, aoiSquare(10.5, 50, 1), 0, 96),
+		// The whole AOI for half the window.
+		existingTask("full-short", capella.TaskAccepted, aoiSquare(9, 49, 3), 48, 96),
+		// The whole AOI for the whole window.
+		existingTask("full", capella.TaskReview, aoiSquare(9, 49, 3), 24, 72),
+		// A full overlap in a status not considered.
+		existingTask("completed", capella.TaskCompleted, aoiSquare(9, 49, 3), 0, 96),
+	}
+	var query map[string]any
+	cli := conflictServer(t, tasks, &query)
+
+	conflicts, err := cli.PredictConflicts(context.Background(), proposed, capella.PredictOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if query["organizationId"] != "org-1" || query["windowOpen"] != "../2025-03-04T00:00:00Z" {
+		t.Errorf("unexpected query: %v", query)
+	}
+	if statuses, _ := query["lastStatusCode"].([]any); len(statuses) != 6 {
+		t.Errorf("expected the 6 unfinished statuses, got %v", query["lastStatusCode"])
+	}
+
+	want := []struct {
+		id       string
+		temporal time.Duration
+		spatial  float64
+	}{
+		{"full", 48 * time.Hour, 1},
+		{"full-half", 48 * time.Hour, 0.5},
+		{"full-short", 24 * time.Hour, 1},
+	}
+	if len(conflicts) != len(want) {
+		t.Fatalf("expected %d conflicts, got %+v", len(want), conflicts)
+	}
+	for i, w := range want {
+		c := conflicts[i]
+		if c.Task.TaskingRequestID != w.id || c.TemporalOverlap != w.temporal || math.Abs(c.SpatialOverlap-w.spatial) > 0.01 {
+			t.Errorf("conflict %d = %s %s %.3f, want %s %s %.3f", i,
+				c.Task.TaskingRequestID, c.TemporalOverlap, c.SpatialOverlap, w.id, w.temporal, w.spatial)
+		}
+	}
+	if conflicts[1].Status != capella.TaskActive || conflicts[1].Task.CollectionTier != capella.TierStandard {
+		t.Errorf("expected the task summary to be kept, got %+v", conflicts[1])
+	}
+
+	// With completed tasks considered too, the completed task ranks first.
+	conflicts, err = cli.PredictConflicts(context.Background(), proposed, capella.PredictOptions{
+		Statuses: []capella.TaskStatus{capella.TaskCompleted, capella.TaskReview},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conflicts) != 2 || conflicts[0].Task.TaskingRequestID != "completed" || conflicts[1].Task.TaskingRequestID != "full" {
+		t.Errorf("unexpected conflicts: %+v", conflicts)
+	}
+}
+
+func TestTaskingService_PredictConflicts_Buffer(t *testing.T) {
+	proposed := capella.TaskingRequest{
+		Geometry: geojson.NewGeometry(orb.Point{10, 50}),
+		Properties: capella.TaskingRequestProperties{
+			WindowOpen:  conflictT0,
+			WindowClose: conflictT0.Add(24 * time.Hour),
+		},
+	}
+	// About 700 m east of the proposed point.
+	tasks := []capella.TaskingRequestResponse{
+		existingTask("nearby", capella.TaskAccepted, geojson.NewGeometry(orb.Point{10.01, 50}), 0, 24),
+	}
+	var query map[string]any
+	cli := conflictServer(t, tasks, &query)
+
+	conflicts, err := cli.PredictConflicts(context.Background(), proposed, capella.PredictOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("expected no conflict without a buffer, got %+v", conflicts)
+	}
+	if _, ok := query["organizationId"]; ok {
+		t.Errorf("expected no organization filter, got %v", query)
+	}
+
+	conflicts, err = cli.PredictConflicts(context.Background(), proposed, capella.PredictOptions{BufferMeters: 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Task.TaskingRequestID != "nearby" || conflicts[0].TemporalOverlap != 24*time.Hour {
+		t.Errorf("expected the nearby task within the buffer, got %+v", conflicts)
+	}
+}
+
+func TestTaskingService_PredictConflicts_Invalid(t *testing.T) {
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request expected for an invalid proposal")
+	})
+
+	tests := []struct {
+		name     string
+		proposed capella.TaskingRequest
+	}{
+		{"no geometry", capella.TaskingRequest{Properties: capella.TaskingRequestProperties{
+			WindowOpen: conflictT0, WindowClose: conflictT0.Add(time.Hour),
+		}}},
+		{"empty window", capella.TaskingRequest{Geometry: aoiSquare(10, 50, 1), Properties: capella.TaskingRequestProperties{
+			WindowOpen: conflictT0, WindowClose: conflictT0,
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cli.PredictConflicts(context.Background(), tt.proposed, capella.PredictOptions{}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
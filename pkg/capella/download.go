@@ -0,0 +1,443 @@
+package capella
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultChunkSize   = 64 << 20
+	defaultParallelism = 4
+
+	// maxURLRenewals bounds how often an expired presigned URL is renewed
+	// during a single download.
+	maxURLRenewals = 5
+
+	// maxChunkRetries bounds retries of a chunk whose body fails mid-stream
+	// or ends without making progress.
+	maxChunkRetries = 3
+
+	// maxExpiryBody bounds how much of a 403 body is read looking for the
+	// expiry message.
+	maxExpiryBody = 4 << 10
+)
+
+// ----------------------------------------------------------------------------
+// Asset Download
+// ----------------------------------------------------------------------------
+
+// DownloadAsset downloads the asset assetKey of item into w using ranged
+// requests of opts.ChunkSize, with at most opts.Parallelism in flight.
+//
+// When a chunk request is rejected with 403 because the presigned URL has
+// expired, as reported in the error body or by the URL's X-Amz-Date and
+// X-Amz-Expires parameters, a fresh URL is obtained (opts.RenewURL, or by re-fetching the item
+// from the catalog) and the chunk resumes from its last written byte. If the
+// server does not support range requests the asset is streamed in one piece.
+//
+// It returns the number of bytes in the asset. Checksum verification requires
+// w to also implement io.ReaderAt.
+func (c *Client) DownloadAsset(ctx context.Context, item STACItem, assetKey string, w io.WriterAt, opts *DownloadOptions) (int64, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	asset, ok := item.Assets[assetKey]
+	if !ok || asset.Href == "" {
+		return 0, fmt.Errorf("item %s has no asset %q", item.ID, assetKey)
+	}
+
+	renew := opts.RenewURL
+	if renew == nil {
+		renew = c.assetURLRenewer(item, assetKey)
+	}
+	d := &assetDownload{
+		client:   c.HTTPClient(),
+		src:      &assetSource{url: asset.Href, renew: renew},
+		w:        w,
+		progress: opts.ProgressCallback,
+		href:     asset.Href,
+	}
+
+	size, ranged, err := d.probe(ctx)
+	if err != nil {
+		return 0, err
+	}
+	d.total = size
+	if ranged {
+		err = d.fetchChunks(ctx, size, opts.ChunkSize, opts.Parallelism)
+	}
+	if err != nil {
+		return d.received, err
+	}
+
+	if opts.VerifyChecksum && asset.Checksum != "" {
+		ra, ok := w.(io.ReaderAt)
+		if !ok {
+			return d.received, errors.New("checksum verification requires an io.ReaderAt destination")
+		}
+		if err := verifyChecksum(io.NewSectionReader(ra, 0, d.received), asset.Checksum); err != nil {
+			return d.received, err
+		}
+	}
+	return d.received, nil
+}
+
+// DownloadAssetToFile downloads an item's asset to destPath using DownloadAsset.
+// A partially written file is removed if the download fails.
+func (c *Client) DownloadAssetToFile(ctx context.Context, item STACItem, assetKey, destPath string, opts *DownloadOptions) error {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if !opts.Overwrite {
+		if _, err := os.Stat(destPath); err == nil {
+			return fmt.Errorf("file already exists: %s", destPath)
+		}
+	}
+
+	file, err := os.OpenFile(destPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	_, err = c.DownloadAsset(ctx, item, assetKey, file, opts)
+	if cerr := file.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to close file: %w", cerr)
+	}
+	if err != nil {
+		os.Remove(destPath)
+		return err
+	}
+	return nil
+}
+
+// assetURLRenewer returns a RenewURL func that re-fetches the item from the
+// catalog to obtain a freshly signed asset href.
+func (c *Client) assetURLRenewer(item STACItem, assetKey string) func(context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		params := SearchParams{IDs: []string{item.ID}, Limit: 1}
+		if item.Collection != "" {
+			params.Collections = []string{item.Collection}
+		}
+		resp, err := c.CatalogSearch(ctx, params)
+		if err != nil {
+			return "", fmt.Errorf("renew asset URL: %w", err)
+		}
+		for _, f := range resp.Features {
+			if f.ID != item.ID {
+				continue
+			}
+			if a, ok := f.Assets[assetKey]; ok && a.Href != "" {
+				return a.Href, nil
+			}
+		}
+		return "", fmt.Errorf("renew asset URL: asset %q not found on item %s", assetKey, item.ID)
+	}
+}
+
+// assetSource holds the current presigned URL and renews it at most once per
+// expiry, no matter how many chunks observe the 403.
+type assetSource struct {
+	mu       sync.Mutex
+	url      string
+	gen      int
+	renewals int
+	renew    func(context.Context) (string, error)
+}
+
+func (s *assetSource) current() (string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.url, s.gen
+}
+
+// refresh renews the URL unless another caller already did so since gen.
+func (s *assetSource) refresh(ctx context.Context, gen int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.gen != gen {
+		return nil
+	}
+	if s.renewals >= maxURLRenewals {
+		return fmt.Errorf("presigned URL expired %d times; giving up", s.renewals)
+	}
+	u, err := s.renew(ctx)
+	if err != nil {
+		return err
+	}
+	s.url = u
+	s.gen++
+	s.renewals++
+	return nil
+}
+
+// assetDownload is the state shared by the chunk workers of one download.
+type assetDownload struct {
+	client   *http.Client
+	src      *assetSource
+	w        io.WriterAt
+	progress func(DownloadProgress)
+	href     string
+
+	mu       sync.Mutex
+	total    int64
+	received int64
+}
+
+// get issues a GET for the given byte range, renewing the URL on a 403 that
+// reports it expired. Other 403s are returned as errors.
+func (d *assetDownload) get(ctx context.Context, rangeHeader string) (*http.Response, error) {
+	for {
+		u, gen := d.src.current()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create download request: %w", err)
+		}
+		req.Header.Set("Range", rangeHeader)
+
+		resp, err := d.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute download request: %w", err)
+		}
+		if resp.StatusCode != http.StatusForbidden {
+			return resp, nil
+		}
+		expired := urlExpired(req.URL, resp, time.Now())
+		resp.Body.Close()
+		if !expired {
+			return nil, fmt.Errorf("download failed with status: %d", resp.StatusCode)
+		}
+		if err := d.src.refresh(ctx, gen); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// urlExpired reports whether a 403 response to a presigned URL means the URL
+// has expired: the error body says so, as S3 ("Request has expired") and
+// similar stores do, or the URL's X-Amz-Date plus X-Amz-Expires has passed.
+func urlExpired(u *url.URL, resp *http.Response, now time.Time) bool {
+	q := u.Query()
+	if signed, err := time.Parse("20060102T150405Z", q.Get("X-Amz-Date")); err == nil {
+		if secs, err := strconv.Atoi(q.Get("X-Amz-Expires")); err == nil && !now.Before(signed.Add(time.Duration(secs)*time.Second)) {
+			return true
+		}
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxExpiryBody))
+	return bytes.Contains(bytes.ToLower(body), []byte("expired"))
+}
+
+// probe requests the first byte to learn the asset size and whether ranges
+// are supported. Without range support the whole body is written here.
+func (d *assetDownload) probe(ctx context.Context) (size int64, ranged bool, err error) {
+	resp, err := d.get(ctx, "bytes=0-0")
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		size, err := parseContentRangeSize(resp.Header.Get("Content-Range"))
+		return size, true, err
+	case http.StatusOK:
+		d.total = resp.ContentLength
+		_, err := d.copy(ctx, resp.Body, 0)
+		return d.received, false, err
+	default:
+		return 0, false, fmt.Errorf("download failed with status: %d", resp.StatusCode)
+	}
+}
+
+// fetchChunks downloads [0, size) in chunks with bounded parallelism.
+// The first error cancels the remaining chunks.
+func (d *assetDownload) fetchChunks(ctx context.Context, size, chunkSize int64, parallelism int) error {
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	if parallelism <= 0 {
+		parallelism = defaultParallelism
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	chunks := make(chan [2]int64)
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ch := range chunks {
+				if err := d.fetchChunk(ctx, ch[0], ch[1]); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+
+feed:
+	for start := int64(0); start < size; start += chunkSize {
+		end := min(start+chunkSize, size) - 1
+		select {
+		case chunks <- [2]int64{start, end}:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(chunks)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// fetchChunk downloads bytes [start, end], resuming after the last written
+// byte if the URL expires or the body fails mid-stream. Passes that fail or
+// write nothing count as retries, so a server that keeps ending the body
+// early does not loop forever.
+func (d *assetDownload) fetchChunk(ctx context.Context, start, end int64) error {
+	offset := start
+	for attempt := 0; offset <= end; {
+		resp, err := d.get(ctx, fmt.Sprintf("bytes=%d-%d", offset, end))
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusPartialContent {
+			resp.Body.Close()
+			return fmt.Errorf("download of bytes %d-%d failed with status: %d", offset, end, resp.StatusCode)
+		}
+
+		n, err := d.copy(ctx, resp.Body, offset)
+		resp.Body.Close()
+		offset += n
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil || n == 0 {
+			if attempt++; attempt > maxChunkRetries {
+				if err == nil {
+					err = fmt.Errorf("download of bytes %d-%d made no progress", offset, end)
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copy writes r to the destination at offset, reporting progress.
+func (d *assetDownload) copy(ctx context.Context, r io.Reader, offset int64) (int64, error) {
+	buf := make([]byte, 32*1024)
+	var written int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			if _, err := d.w.WriteAt(buf[:n], offset+written); err != nil {
+				return written, fmt.Errorf("failed to write file: %w", err)
+			}
+			written += int64(n)
+			d.report(int64(n))
+		}
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, fmt.Errorf("failed to read response: %w", readErr)
+		}
+	}
+}
+
+func (d *assetDownload) report(n int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.received += n
+	if d.progress == nil {
+		return
+	}
+	var percent float64
+	if d.total > 0 {
+		percent = float64(d.received) / float64(d.total) * 100
+	}
+	d.progress(DownloadProgress{
+		URL:           d.href,
+		BytesReceived: d.received,
+		TotalBytes:    d.total,
+		Percent:       percent,
+	})
+}
+
+// parseContentRangeSize extracts the complete length from "bytes 0-0/1234".
+func parseContentRangeSize(header string) (int64, error) {
+	i := strings.LastIndexByte(header, '/')
+	if i < 0 || header[i+1:] == "*" {
+		return 0, fmt.Errorf("unsupported Content-Range %q", header)
+	}
+	size, err := strconv.ParseInt(header[i+1:], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Content-Range %q: %w", header, err)
+	}
+	return size, nil
+}
+
+// verifyChecksum compares r against a hex-encoded multihash as used by the
+// STAC file extension. SHA2-256, SHA2-512 and MD5 are supported.
+func verifyChecksum(r io.Reader, checksum string) error {
+	raw, err := hex.DecodeString(checksum)
+	if err != nil {
+		return fmt.Errorf("invalid checksum %q: %w", checksum, err)
+	}
+
+	var h hash.Hash
+	var digest []byte
+	switch {
+	case bytes.HasPrefix(raw, []byte{0x12, 0x20}):
+		h, digest = sha256.New(), raw[2:]
+	case bytes.HasPrefix(raw, []byte{0x13, 0x40}):
+		h, digest = sha512.New(), raw[2:]
+	case bytes.HasPrefix(raw, []byte{0xd5, 0x01, 0x10}):
+		h, digest = md5.New(), raw[3:]
+	default:
+		return fmt.Errorf("unsupported checksum algorithm in %q", checksum)
+	}
+
+	if _, err := io.Copy(h, r); err != nil {
+		return fmt.Errorf("failed to read file for checksum: %w", err)
+	}
+	if sum := h.Sum(nil); !bytes.Equal(sum, digest) {
+		return fmt.Errorf("checksum mismatch: expected %x, got %x", digest, sum)
+	}
+	return nil
+}
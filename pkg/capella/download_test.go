@@ -0,0 +1,335 @@
+package capella_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+// assetServer serves payload at /asset with presigned-style "sig" query
+// parameters. After expireAfter range requests the current signature is
+// revoked and requests using it receive 403.
+type assetServer struct {
+	t           *testing.T
+	payload     []byte
+	expireAfter int32
+
+	mu       sync.Mutex
+	validSig int
+	requests atomic.Int32
+	ranges   []string
+	truncate map[int64]bool // chunk starts whose first response is cut short
+}
+
+func (s *assetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sig, _ := strconv.Atoi(r.URL.Query().Get("sig"))
+
+	s.mu.Lock()
+	if n := s.requests.Add(1); s.expireAfter > 0 && n == s.expireAfter {
+		s.validSig++ // the URL in use expires mid-download
+	}
+	valid := sig == s.validSig
+	rng := r.Header.Get("Range")
+	s.ranges = append(s.ranges, rng)
+	s.mu.Unlock()
+
+	if !valid {
+		http.Error(w, "<Error><Code>AccessDenied</Code><Message>Request has expired</Message></Error>", http.StatusForbidden)
+		return
+	}
+
+	var start, end int64
+	if _, err := fmt.Sscanf(rng, "bytes=%d-%d", &start, &end); err != nil {
+		s.t.Errorf("unexpected Range header %q", rng)
+		return
+	}
+	end = min(end, int64(len(s.payload))-1)
+
+	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(s.payload)))
+	w.Header().Set("Content-Length", strconv.FormatInt(end-start+1, 10))
+	w.WriteHeader(http.StatusPartialContent)
+
+	body := s.payload[start : end+1]
+	s.mu.Lock()
+	cut := s.truncate[start]
+	delete(s.truncate, start)
+	s.mu.Unlock()
+	if cut {
+		// Send half the range, then drop the connection.
+		w.Write(body[:len(body)/2])
+		w.(http.Flusher).Flush()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+		return
+	}
+	w.Write(body)
+}
+
+func (s *assetServer) url(srvURL string, sig int) string {
+	return fmt.Sprintf("%s/asset?sig=%d", srvURL, sig)
+}
+
+func randomPayload(n int) []byte {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(1)).Read(b)
+	return b
+}
+
+func multihashSHA256(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "1220" + hex.EncodeToString(sum[:])
+}
+
+func TestDownloadService_DownloadAsset_RenewsExpiredURL(t *testing.T) {
+	payload := randomPayload(1<<20 + 123)
+	as := &assetServer{t: t, payload: payload, expireAfter: 6}
+	srv := httptest.NewServer(as)
+	t.Cleanup(srv.Close)
+
+	cli, err := capella.NewClient(capella.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	item := capella.STACItem{
+		ID: "CAPELLA_C13_SP_SICD",
+		Assets: map[string]capella.Asset{
+			"HH": {Href: as.url(srv.URL, 0), Checksum: multihashSHA256(payload)},
+		},
+	}
+
+	var renewals atomic.Int32
+	var lastProgress capella.DownloadProgress
+	var progressMu sync.Mutex
+	dest := filepath.Join(t.TempDir(), "image.ntf")
+
+	err = cli.DownloadAssetToFile(context.Background(), item, "HH", dest, &capella.DownloadOptions{
+		ChunkSize:      64 << 10,
+		Parallelism:    4,
+		VerifyChecksum: true,
+		RenewURL: func(ctx context.Context) (string, error) {
+			return as.url(srv.URL, int(renewals.Add(1))), nil
+		},
+		ProgressCallback: func(p capella.DownloadProgress) {
+			progressMu.Lock()
+			lastProgress = p
+			progressMu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatal("downloaded file does not match payload")
+	}
+	if n := renewals.Load(); n != 1 {
+		t.Errorf("expected URL to be renewed once, got %d", n)
+	}
+	if lastProgress.BytesReceived != int64(len(payload)) || lastProgress.TotalBytes != int64(len(payload)) {
+		t.Errorf("unexpected final progress: %+v", lastProgress)
+	}
+}
+
+func TestDownloadService_DownloadAsset_ResumesTruncatedChunk(t *testing.T) {
+	payload := randomPayload(256 << 10)
+	as := &assetServer{t: t, payload: payload, truncate: map[int64]bool{64 << 10: true}}
+	srv := httptest.NewServer(as)
+	t.Cleanup(srv.Close)
+
+	cli, _ := capella.NewClient(capella.WithBaseURL(srv.URL))
+	item := capella.STACItem{ID: "item", Assets: map[string]capella.Asset{"HH": {Href: as.url(srv.URL, 0)}}}
+
+	dest := filepath.Join(t.TempDir(), "image.ntf")
+	err := cli.DownloadAssetToFile(context.Background(), item, "HH", dest, &capella.DownloadOptions{
+		ChunkSize:   64 << 10,
+		Parallelism: 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, payload) {
+		t.Fatal("downloaded file does not match payload")
+	}
+
+	resume := fmt.Sprintf("bytes=%d-%d", 64<<10+32<<10, 128<<10-1)
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	for _, r := range as.ranges {
+		if r == resume {
+			return
+		}
+	}
+	t.Errorf("expected resume request %q, got %v", resume, as.ranges)
+}
+
+func TestDownloadService_DownloadAsset_DefaultRenewalViaCatalog(t *testing.T) {
+	payload := randomPayload(200 << 10)
+	as := &assetServer{t: t, payload: payload, expireAfter: 3}
+
+	mux := http.NewServeMux()
+	mux.Handle("/asset", as)
+	var srvURL string
+	mux.HandleFunc("/catalog/search", func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPost)
+		jsonResponse(w, http.StatusOK, capella.SearchResponse{
+			Features: []capella.STACItem{{
+				ID:     "item-1",
+				Assets: map[string]capella.Asset{"HH": {Href: as.url(srvURL, 1)}},
+			}},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	srvURL = srv.URL
+
+	cli, _ := capella.NewClient(capella.WithBaseURL(srv.URL), capella.WithAPIKey("test-api-key"))
+	item := capella.STACItem{ID: "item-1", Assets: map[string]capella.Asset{"HH": {Href: as.url(srv.URL, 0)}}}
+
+	dest := filepath.Join(t.TempDir(), "image.ntf")
+	err := cli.DownloadAssetToFile(context.Background(), item, "HH", dest, &capella.DownloadOptions{
+		ChunkSize:   32 << 10,
+		Parallelism: 2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, payload) {
+		t.Fatal("downloaded file does not match payload")
+	}
+}
+
+func TestDownloadService_DownloadAsset_ChecksumMismatch(t *testing.T) {
+	payload := randomPayload(10 << 10)
+	as := &assetServer{t: t, payload: payload}
+	srv := httptest.NewServer(as)
+	t.Cleanup(srv.Close)
+
+	cli, _ := capella.NewClient(capella.WithBaseURL(srv.URL))
+	item := capella.STACItem{ID: "item", Assets: map[string]capella.Asset{
+		"HH": {Href: as.url(srv.URL, 0), Checksum: multihashSHA256([]byte("something else"))},
+	}}
+
+	dest := filepath.Join(t.TempDir(), "image.ntf")
+	err := cli.DownloadAssetToFile(context.Background(), item, "HH", dest, &capella.DownloadOptions{VerifyChecksum: true})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("expected partial file to be removed, stat err = %v", err)
+	}
+}
+
+func TestDownloadService_DownloadAsset_Cancellation(t *testing.T) {
+	payload := randomPayload(1 << 20)
+	as := &assetServer{t: t, payload: payload}
+	srv := httptest.NewServer(as)
+	t.Cleanup(srv.Close)
+
+	cli, _ := capella.NewClient(capella.WithBaseURL(srv.URL))
+	item := capella.STACItem{ID: "item", Assets: map[string]capella.Asset{"HH": {Href: as.url(srv.URL, 0)}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dest := filepath.Join(t.TempDir(), "image.ntf")
+	err := cli.DownloadAssetToFile(ctx, item, "HH", dest, &capella.DownloadOptions{
+		ChunkSize:   16 << 10,
+		Parallelism: 2,
+		ProgressCallback: func(p capella.DownloadProgress) {
+			if p.BytesReceived > 100<<10 {
+				cancel()
+			}
+		},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if n := as.requests.Load(); n >= int32(len(payload)/(16<<10)) {
+		t.Errorf("expected download to stop early, server saw %d requests", n)
+	}
+}
+
+func TestDownloadService_DownloadAsset_MissingAsset(t *testing.T) {
+	cli, _ := capella.NewClient()
+	_, err := cli.DownloadAsset(context.Background(), capella.STACItem{ID: "item"}, "HH", nil, nil)
+	if err == nil {
+		t.Fatal("expected error for missing asset")
+	}
+}
+
+func TestDownloadService_DownloadAsset_EmptyChunkBody(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// Every range after the probe gets a 206 with no body.
+		if r.Header.Get("Range") == "bytes=0-0" {
+			w.Header().Set("Content-Range", "bytes 0-0/1024")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte{0})
+			return
+		}
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusPartialContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, _ := capella.NewClient(capella.WithBaseURL(srv.URL))
+	item := capella.STACItem{ID: "item", Assets: map[string]capella.Asset{"HH": {Href: srv.URL + "/asset"}}}
+	dest := filepath.Join(t.TempDir(), "image.ntf")
+
+	err := cli.DownloadAssetToFile(context.Background(), item, "HH", dest, &capella.DownloadOptions{Parallelism: 1})
+	if err == nil || !strings.Contains(err.Error(), "no progress") {
+		t.Fatalf("expected a no-progress error, got %v", err)
+	}
+	if n := requests.Load(); n > 10 {
+		t.Errorf("expected the chunk retries to be bounded, server saw %d requests", n)
+	}
+}
+
+func TestDownloadService_DownloadAsset_ForbiddenNotExpired(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>", http.StatusForbidden)
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, _ := capella.NewClient(capella.WithBaseURL(srv.URL))
+	item := capella.STACItem{ID: "item", Assets: map[string]capella.Asset{"HH": {Href: srv.URL + "/asset"}}}
+
+	var renewals atomic.Int32
+	dest := filepath.Join(t.TempDir(), "image.ntf")
+	err := cli.DownloadAssetToFile(context.Background(), item, "HH", dest, &capella.DownloadOptions{
+		RenewURL: func(ctx context.Context) (string, error) {
+			renewals.Add(1)
+			return srv.URL + "/asset", nil
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected a 403 error, got %v", err)
+	}
+	if n := renewals.Load(); n != 0 {
+		t.Errorf("expected no renewal without an expiry signal, got %d", n)
+	}
+}
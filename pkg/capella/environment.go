@@ -0,0 +1,46 @@
+package capella
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Environment identifies the Capella environment a client talks to.
+type Environment string
+
+const (
+	EnvironmentProduction Environment = "production"
+	EnvironmentSandbox    Environment = "sandbox"
+	// EnvironmentCustom is reported for base URLs other than the production
+	// and sandbox endpoints, e.g. a mock server.
+	EnvironmentCustom Environment = "custom"
+)
+
+// environmentOf derives the environment from a base URL.
+func environmentOf(baseURL string) Environment {
+	switch strings.TrimSuffix(baseURL, "/") {
+	case defaultBaseURL:
+		return EnvironmentProduction
+	case SandboxBaseURL:
+		return EnvironmentSandbox
+	}
+	return EnvironmentCustom
+}
+
+// Environment returns the environment the client talks to, as set with
+// WithEnvironment or derived from its base URL.
+func (c *Client) Environment() Environment {
+	return c.environment
+}
+
+// confirmProductionWrite asks the WithProductionConfirmation callback before
+// a write against production.
+func (c *Client) confirmProductionWrite(op string) error {
+	if c.confirmWrite == nil || c.environment != EnvironmentProduction {
+		return nil
+	}
+	if !c.confirmWrite() {
+		return fmt.Errorf("%s: %w", op, ErrProductionWriteRefused)
+	}
+	return nil
+}
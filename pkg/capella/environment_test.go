@@ -0,0 +1,127 @@
+package capella_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestNewSandboxClient(t *testing.T) {
+	var got *http.Request
+	hc := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = r
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"taskingrequestId":"task-1"}`)),
+		}, nil
+	})}
+
+	cli, err := capella.NewSandboxClient("sandbox-key", capella.WithHTTPClient(hc))
+	if err != nil {
+		t.Fatalf("NewSandboxClient: %v", err)
+	}
+	if env := cli.Environment(); env != capella.EnvironmentSandbox {
+		t.Errorf("expected sandbox environment, got %s", env)
+	}
+	if _, err := cli.GetTask(context.Background(), "task-1"); err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if want := capella.SandboxBaseURL + "/task/task-1"; got.URL.String() != want {
+		t.Errorf("expected request to %s, got %s", want, got.URL)
+	}
+	if auth := got.Header.Get("Authorization"); !strings.Contains(auth, "sandbox-key") {
+		t.Errorf("expected the sandbox API key to be sent, got %q", auth)
+	}
+}
+
+func TestClient_Environment(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []capella.Option
+		want capella.Environment
+	}{
+		{"default", nil, capella.EnvironmentProduction},
+		{"sandbox URL", []capella.Option{capella.WithBaseURL(capella.SandboxBaseURL)}, capella.EnvironmentSandbox},
+		{"custom URL", []capella.Option{capella.WithBaseURL("http://localhost:8080")}, capella.EnvironmentCustom},
+		{"explicit", []capella.Option{capella.WithBaseURL("http://proxy"), capella.WithEnvironment(capella.EnvironmentProduction)}, capella.EnvironmentProduction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, err := capella.NewClient(tt.opts...)
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			if got := cli.Environment(); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithProductionConfirmation(t *testing.T) {
+	var writes atomic.Int32
+	_, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writes.Add(1)
+		switch r.URL.Path {
+		case "/ma/accessrequests":
+			jsonResponse(w, http.StatusOK, capella.AccessRequestResponse{})
+		default:
+			jsonResponse(w, http.StatusOK, capella.TaskingRequestResponse{})
+		}
+	})
+
+	newClient := func(env capella.Environment, confirm bool) *capella.Client {
+		cli, err := capella.NewClient(
+			capella.WithBaseURL(srv.URL),
+			capella.WithAPIKey("test-api-key"),
+			capella.WithEnvironment(env),
+			capella.WithProductionConfirmation(func() bool { return confirm }),
+		)
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+		return cli
+	}
+	calls := func(cli *capella.Client) []error {
+		ctx := context.Background()
+		_, e1 := cli.CreateTask(ctx, capella.TaskingRequest{})
+		_, e2 := cli.CreateAccessRequest(ctx, capella.AccessRequest{})
+		_, e3 := cli.ApproveTask(ctx, "task-1")
+		return []error{e1, e2, e3}
+	}
+
+	for _, err := range calls(newClient(capella.EnvironmentProduction, false)) {
+		if !errors.Is(err, capella.ErrProductionWriteRefused) {
+			t.Errorf("expected ErrProductionWriteRefused, got %v", err)
+		}
+	}
+	if n := writes.Load(); n != 0 {
+		t.Fatalf("expected refused writes not to be sent, got %d requests", n)
+	}
+
+	for _, cli := range []*capella.Client{
+		newClient(capella.EnvironmentProduction, true),
+		newClient(capella.EnvironmentSandbox, false),
+	} {
+		for _, err := range calls(cli) {
+			if err != nil {
+				t.Errorf("expected write to be allowed, got %v", err)
+			}
+		}
+	}
+	if n := writes.Load(); n != 6 {
+		t.Errorf("expected 6 allowed writes, got %d", n)
+	}
+}
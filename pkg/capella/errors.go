@@ -2,8 +2,11 @@ package capella
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
@@ -17,6 +20,65 @@ const (
 	ErrCodeInternalError = "INTERNAL_ERROR"
 )
 
+// ErrQuotePending is returned by GetTaskCost when the quote for a tasking
+// request has not been calculated yet.
+var ErrQuotePending = errors.New("task cost quote is pending")
+
+// ErrProductionWriteRefused is returned by write operations against
+// production when the WithProductionConfirmation callback declines them.
+var ErrProductionWriteRefused = errors.New("write against production was not confirmed")
+
+// CostExceededError is returned by ApproveTaskIfUnder when the quoted cost of
+// a tasking request is over budget. The task is left unapproved.
+type CostExceededError struct {
+	Quote     *TaskCost
+	MaxAmount float64
+}
+
+func (e *CostExceededError) Error() string {
+	return fmt.Sprintf("task %s costs %.2f %s, exceeding budget of %.2f",
+		e.Quote.TaskingRequestID, e.Quote.Total, e.Quote.Currency, e.MaxAmount)
+}
+
+// QuotaExhaustedError is returned when a tier's tasking quota for the
+// current month is used up.
+type QuotaExhaustedError struct {
+	Tier  CollectionTier
+	Limit int
+	// Reset is when the quota next resets, the start of the next month.
+	Reset time.Time
+}
+
+func (e *QuotaExhaustedError) Error() string {
+	return fmt.Sprintf("%s tasking quota of %d tasks exhausted until %s",
+		e.Tier, e.Limit, e.Reset.Format(time.DateOnly))
+}
+
+// NotReviewableError is returned by ApproveTask and RejectTask when the API
+// refuses the decision with 409 Conflict because the tasking request is not
+// in review, e.g. it was already approved, rejected or canceled. It unwraps
+// to the underlying *APIError.
+type NotReviewableError struct {
+	TaskingRequestID string
+	Decision         TaskStatus
+	Err              *APIError
+}
+
+func (e *NotReviewableError) Error() string {
+	msg := fmt.Sprintf("task %s cannot be %s: not in review", e.TaskingRequestID, e.Decision)
+	if e.Err != nil && e.Err.Message != "" {
+		msg += ": " + e.Err.Message
+	}
+	return msg
+}
+
+func (e *NotReviewableError) Unwrap() error {
+	if e.Err == nil {
+		return nil
+	}
+	return e.Err
+}
+
 // APIError is an alias for common.APIError for backwards compatibility.
 type APIError = common.APIError
 
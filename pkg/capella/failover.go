@@ -0,0 +1,223 @@
+package capella
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// DefaultFailoverProbeInterval is how long a client that failed over waits
+// before trying its primary base URL again.
+const DefaultFailoverProbeInterval = 5 * time.Minute
+
+// ----------------------------------------------------------------------------
+// Regional Failover
+// ----------------------------------------------------------------------------
+
+// WithBaseURLs sets the primary base URL and fallbacks, e.g. other regional
+// endpoints. A call that fails on a base URL with a connection error, a
+// per-request timeout or a 5xx response is retried against the next one;
+// 4xx responses are returned as they are. The client keeps using the last
+// base URL that responded, re-probing the primary periodically (see
+// WithFailoverProbeInterval).
+//
+// Only idempotent calls (GET, HEAD, PUT, DELETE and searches) fail over;
+// other calls made with Do, DoRaw or DoAsync can opt in with WithFailover.
+// An AuthClient whose token URL is under the primary base URL, such as the
+// default /token, fetches tokens from the base URL in use too.
+// WithBaseURLs overrides WithBaseURL.
+func WithBaseURLs(primary string, fallbacks ...string) Option {
+	return func(c *clientConfig) {
+		c.baseURL = primary
+		c.fallbackURLs = fallbacks
+	}
+}
+
+// WithFailoverProbeInterval sets how long a client that failed over waits
+// before trying its primary base URL again. Defaults to
+// DefaultFailoverProbeInterval.
+func WithFailoverProbeInterval(d time.Duration) Option {
+	return func(c *clientConfig) {
+		c.probeInterval = d
+	}
+}
+
+// WithFailover lets a non-idempotent call fail over to the fallback base
+// URLs. Use it only for calls that are safe to repeat, since a request that
+// failed with a 5xx or a timeout may still have been applied.
+func WithFailover() CallOption {
+	return func(c *callConfig) {
+		c.failover = true
+	}
+}
+
+// ActiveBaseURL returns the base URL calls are currently sent to: the
+// primary, or the fallback in use after a failover.
+func (c *Client) ActiveBaseURL() *url.URL {
+	if c.failover == nil {
+		return c.BaseURL()
+	}
+	c.failover.mu.Lock()
+	defer c.failover.mu.Unlock()
+	return c.failover.bases[c.failover.active]
+}
+
+// failover tracks which of a client's base URLs is in use.
+type failover struct {
+	bases    []*url.URL // primary first
+	interval time.Duration
+
+	mu     sync.Mutex
+	active int
+	// probeAt is when the primary should be tried again while active != 0.
+	probeAt time.Time
+}
+
+func newFailover(primary *url.URL, fallbacks []string, interval time.Duration) (*failover, error) {
+	f := &failover{bases: []*url.URL{primary}, interval: interval}
+	if f.interval <= 0 {
+		f.interval = DefaultFailoverProbeInterval
+	}
+	for _, s := range fallbacks {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		f.bases = append(f.bases, u)
+	}
+	return f, nil
+}
+
+// hosts returns the hosts of the client's base URLs; f may be nil for a
+// client with only the primary base URL.
+func (f *failover) hosts(primary *url.URL) []string {
+	if f == nil {
+		return []string{primary.Host}
+	}
+	hosts := make([]string, len(f.bases))
+	for i, u := range f.bases {
+		hosts[i] = u.Host
+	}
+	return hosts
+}
+
+// order returns the indexes of the base URLs to try for a call, in order:
+// the active one, or the primary when a re-probe is due, then the others.
+func (f *failover) order() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	first := f.active
+	if first != 0 && !time.Now().Before(f.probeAt) {
+		first = 0
+	}
+	order := []int{first}
+	for i := range f.bases {
+		if i != first {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// succeeded records that base URL i responded.
+func (f *failover) succeeded(i int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if i != 0 && f.active != i {
+		f.probeAt = time.Now().Add(f.interval)
+	}
+	f.active = i
+}
+
+// primaryFailed postpones the next re-probe of the primary base URL.
+func (f *failover) primaryFailed() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.probeAt = time.Now().Add(f.interval)
+}
+
+// rebase returns u moved from whichever base URL it belongs to onto base i.
+// ok is false if u is not under any of the base URLs.
+func (f *failover) rebase(u *url.URL, i int) (rebased *url.URL, ok bool) {
+	for _, from := range f.bases {
+		prefix := strings.TrimSuffix(from.Path, "/")
+		if u.Scheme != from.Scheme || u.Host != from.Host || !strings.HasPrefix(u.Path, prefix) {
+			continue
+		}
+		to := f.bases[i]
+		r := *u
+		r.Scheme, r.Host = to.Scheme, to.Host
+		r.Path = strings.TrimSuffix(to.Path, "/") + strings.TrimPrefix(u.Path, prefix)
+		r.RawPath = ""
+		return &r, true
+	}
+	return nil, false
+}
+
+// idempotent reports whether repeating a request with method has no further
+// effect.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldFailOver reports whether err, returned by a call whose outer context
+// is ctx, means the base URL is unavailable rather than that the request was
+// wrong.
+func shouldFailOver(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *common.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// call performs one API call, bounded by the per-request timeout, failing
+// over across the client's base URLs when configured and allowed for
+// method. send may be called several times, with u moved onto each base URL
+// tried, and must be safe to repeat.
+func (c *Client) call(ctx context.Context, method string, u *url.URL, opts []CallOption, send func(ctx context.Context, u *url.URL) error) error {
+	cfg := c.callConfig(method, opts)
+	attempt := func(u *url.URL) error {
+		ctx, cancel := cfg.context(ctx)
+		defer cancel()
+		return send(ctx, u)
+	}
+
+	f := c.failover
+	if f == nil || !(cfg.failover || idempotent(method)) {
+		return attempt(u)
+	}
+	if _, ok := f.rebase(u, 0); !ok {
+		// Not an API URL, e.g. a presigned download link.
+		return attempt(u)
+	}
+
+	var err error
+	for _, i := range f.order() {
+		target, _ := f.rebase(u, i)
+		if err = attempt(target); !shouldFailOver(ctx, err) {
+			if err == nil || ctx.Err() == nil {
+				f.succeeded(i)
+			}
+			return err
+		}
+		if i == 0 {
+			f.primaryFailed()
+		}
+	}
+	return err
+}
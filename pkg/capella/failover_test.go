@@ -0,0 +1,254 @@
+package capella_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+// regionServer starts a test server standing in for one regional endpoint,
+// counting the requests it receives.
+func regionServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func newFailoverClient(t *testing.T, primary string, fallbacks []string, opts ...capella.Option) *capella.Client {
+	t.Helper()
+	cli, err := capella.NewClient(append([]capella.Option{
+		capella.WithBaseURLs(primary, fallbacks...),
+		capella.WithAPIKey("test-api-key"),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return cli
+}
+
+func statusHandler(status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, status, map[string]string{"message": http.StatusText(status)})
+	}
+}
+
+func TestFailover_PrimaryDown(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	fallback, fallbackHits := regionServer(t, statusHandler(http.StatusOK))
+
+	cli := newFailoverClient(t, down.URL, []string{fallback.URL})
+	for range 2 {
+		if err := cli.Do(context.Background(), http.MethodGet, "/collectiontypes", 0, nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if fallbackHits.Load() != 2 {
+		t.Errorf("expected 2 requests to the fallback, got %d", fallbackHits.Load())
+	}
+	if got := cli.ActiveBaseURL().String(); got != fallback.URL {
+		t.Errorf("ActiveBaseURL() = %s, want %s", got, fallback.URL)
+	}
+}
+
+func TestFailover_StickyAfter5xx(t *testing.T) {
+	primary, primaryHits := regionServer(t, statusHandler(http.StatusServiceUnavailable))
+	secondary, secondaryHits := regionServer(t, statusHandler(http.StatusBadGateway))
+	tertiary, tertiaryHits := regionServer(t, statusHandler(http.StatusOK))
+
+	cli := newFailoverClient(t, primary.URL, []string{secondary.URL, tertiary.URL})
+	if got := cli.ActiveBaseURL().String(); got != primary.URL {
+		t.Errorf("ActiveBaseURL() = %s before any call, want the primary", got)
+	}
+	for range 3 {
+		if err := cli.Do(context.Background(), http.MethodGet, "/orders/o-1", 0, nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if primaryHits.Load() != 1 || secondaryHits.Load() != 1 || tertiaryHits.Load() != 3 {
+		t.Errorf("hits = %d/%d/%d, want 1/1/3", primaryHits.Load(), secondaryHits.Load(), tertiaryHits.Load())
+	}
+	if got := cli.ActiveBaseURL().String(); got != tertiary.URL {
+		t.Errorf("ActiveBaseURL() = %s, want %s", got, tertiary.URL)
+	}
+}
+
+func TestFailover_AllDown(t *testing.T) {
+	primary, _ := regionServer(t, statusHandler(http.StatusServiceUnavailable))
+	fallback, fallbackHits := regionServer(t, statusHandler(http.StatusInternalServerError))
+
+	cli := newFailoverClient(t, primary.URL, []string{fallback.URL})
+	err := cli.Do(context.Background(), http.MethodGet, "/orders/o-1", 0, nil, nil)
+	apiErr, ok := err.(*capella.APIError)
+	if !ok || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected the fallback's 500, got %v", err)
+	}
+	if fallbackHits.Load() != 1 {
+		t.Errorf("expected 1 request to the fallback, got %d", fallbackHits.Load())
+	}
+}
+
+func TestFailover_PrimaryReprobe(t *testing.T) {
+	var healthy atomic.Bool
+	primary, primaryHits := regionServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			statusHandler(http.StatusServiceUnavailable)(w, r)
+			return
+		}
+		statusHandler(http.StatusOK)(w, r)
+	})
+	fallback, _ := regionServer(t, statusHandler(http.StatusOK))
+
+	cli := newFailoverClient(t, primary.URL, []string{fallback.URL},
+		capella.WithFailoverProbeInterval(50*time.Millisecond))
+	get := func() {
+		t.Helper()
+		if err := cli.Do(context.Background(), http.MethodGet, "/task/t-1", 0, nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	get()
+	get()
+	if primaryHits.Load() != 1 {
+		t.Fatalf("expected the primary to be skipped until the probe interval, got %d hits", primaryHits.Load())
+	}
+
+	// Still down at the first re-probe: the fallback stays active.
+	time.Sleep(60 * time.Millisecond)
+	get()
+	if primaryHits.Load() != 2 || cli.ActiveBaseURL().String() != fallback.URL {
+		t.Fatalf("expected a failed re-probe, got %d primary hits and active %s", primaryHits.Load(), cli.ActiveBaseURL())
+	}
+
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	get()
+	if got := cli.ActiveBaseURL().String(); got != primary.URL {
+		t.Errorf("ActiveBaseURL() = %s after recovery, want the primary", got)
+	}
+	get()
+	if primaryHits.Load() != 4 {
+		t.Errorf("expected calls to return to the primary, got %d primary hits", primaryHits.Load())
+	}
+}
+
+func TestFailover_ClientErrorNotRetried(t *testing.T) {
+	primary, _ := regionServer(t, statusHandler(http.StatusNotFound))
+	fallback, fallbackHits := regionServer(t, statusHandler(http.StatusOK))
+
+	cli := newFailoverClient(t, primary.URL, []string{fallback.URL})
+	err := cli.Do(context.Background(), http.MethodGet, "/task/missing", 0, nil, nil)
+	if !capella.IsNotFound(err) {
+		t.Fatalf("expected the primary's 404, got %v", err)
+	}
+	if fallbackHits.Load() != 0 {
+		t.Errorf("a 4xx must not fail over, got %d fallback requests", fallbackHits.Load())
+	}
+	if got := cli.ActiveBaseURL().String(); got != primary.URL {
+		t.Errorf("ActiveBaseURL() = %s, want the primary", got)
+	}
+}
+
+func TestFailover_SearchBodyReplayed(t *testing.T) {
+	var primaryBody []byte
+	primary, _ := regionServer(t, func(w http.ResponseWriter, r *http.Request) {
+		primaryBody, _ = io.ReadAll(r.Body)
+		statusHandler(http.StatusBadGateway)(w, r)
+	})
+	var fallbackBody []byte
+	fallback, _ := regionServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPost)
+		requirePath(t, r, "/catalog/search")
+		fallbackBody, _ = io.ReadAll(r.Body)
+		jsonResponse(w, http.StatusOK, map[string]any{
+			"type":     "FeatureCollection",
+			"features": []map[string]any{{"id": "CAPELLA_C13_SP_SLC_HH_1"}},
+		})
+	})
+
+	cli := newFailoverClient(t, primary.URL, []string{fallback.URL})
+	resp, err := cli.CatalogSearch(context.Background(), capella.SearchParams{
+		Collections: []string{"capella-open-data"},
+		IDs:         []string{"CAPELLA_C13_SP_SLC_HH_1"},
+	})
+	if err != nil {
+		t.Fatalf("CatalogSearch() error = %v", err)
+	}
+	if len(resp.Features) != 1 {
+		t.Errorf("expected 1 feature, got %d", len(resp.Features))
+	}
+	if len(fallbackBody) == 0 || !bytes.Equal(primaryBody, fallbackBody) {
+		t.Errorf("fallback body %q differs from primary body %q", fallbackBody, primaryBody)
+	}
+
+	// DoRaw buffers a streamed body for the retry. A new client starts on
+	// the primary again.
+	cli = newFailoverClient(t, primary.URL, []string{fallback.URL})
+	primaryBody, fallbackBody = nil, nil
+	body := `{"ids":["CAPELLA_C13_SP_SLC_HH_1"]}`
+	err = cli.DoRaw(context.Background(), http.MethodPost, cli.BaseURL().JoinPath("catalog", "search"),
+		io.NopCloser(bytes.NewBufferString(body)), 0, nil, capella.WithFailover())
+	if err != nil {
+		t.Fatalf("DoRaw() error = %v", err)
+	}
+	if string(primaryBody) != body || string(fallbackBody) != body {
+		t.Errorf("bodies = %q, %q; want %q twice", primaryBody, fallbackBody, body)
+	}
+}
+
+func TestFailover_NonIdempotentOptIn(t *testing.T) {
+	primary, _ := regionServer(t, statusHandler(http.StatusServiceUnavailable))
+	fallback, fallbackHits := regionServer(t, statusHandler(http.StatusOK))
+
+	cli := newFailoverClient(t, primary.URL, []string{fallback.URL})
+	payload := map[string]string{"name": "retask"}
+	if err := cli.Do(context.Background(), http.MethodPost, "/task", 0, payload, nil); err == nil {
+		t.Fatal("expected a POST without WithFailover to return the primary's 503")
+	}
+	if fallbackHits.Load() != 0 {
+		t.Fatalf("a POST must not fail over by default, got %d fallback requests", fallbackHits.Load())
+	}
+
+	if err := cli.Do(context.Background(), http.MethodPost, "/task", 0, payload, nil, capella.WithFailover()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fallbackHits.Load() != 1 {
+		t.Errorf("expected the opted-in POST to reach the fallback, got %d requests", fallbackHits.Load())
+	}
+}
+
+func TestFailover_TokenURL(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	fallback, _ := regionServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPost)
+		requirePath(t, r, "/token")
+		jsonResponse(w, http.StatusOK, capella.TokenResponse{AccessToken: "jwt", ExpiresIn: 3600})
+	})
+
+	cli := newFailoverClient(t, down.URL, []string{fallback.URL})
+	auth := capella.NewAuthClient(cli, capella.AuthConfig{Username: "u", Password: "p"})
+	token, err := auth.GetToken(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "jwt" {
+		t.Errorf("GetToken() = %q, want jwt", token)
+	}
+	if got := cli.ActiveBaseURL().String(); got != fallback.URL {
+		t.Errorf("ActiveBaseURL() = %s, want %s", got, fallback.URL)
+	}
+}
@@ -2,12 +2,14 @@ package capella
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"iter"
 	"net/http"
 	"time"
 
 	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
 
 
@@ -80,6 +82,24 @@ type AccessRequestPropertiesResponse struct {
 	UpdatedAt            time.Time           `json:"updatedAt,omitempty"`
 }
 
+// UnmarshalJSON accepts the access request ID as "accessrequestId" or
+// "accessRequestId"; the API has used both.
+func (p *AccessRequestPropertiesResponse) UnmarshalJSON(data []byte) error {
+	type plain AccessRequestPropertiesResponse
+	var v struct {
+		plain
+		CamelID string `json:"accessRequestId"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = AccessRequestPropertiesResponse(v.plain)
+	if p.AccessRequestID == "" {
+		p.AccessRequestID = v.CamelID
+	}
+	return nil
+}
+
 // AccessRequestResponse represents the response for an access request.
 type AccessRequestResponse struct {
 	Type       string                          `json:"type"`
@@ -111,6 +131,10 @@ type AccessRequestDetailResponse struct {
 
 // CreateAccessRequest submits a new access/feasibility request.
 func (c *Client) CreateAccessRequest(ctx context.Context, req AccessRequest) (*AccessRequestResponse, error) {
+	if err := c.confirmProductionWrite("create access request"); err != nil {
+		return nil, err
+	}
+
 	if req.Type == "" {
 		req.Type = "Feature"
 	}
@@ -150,27 +174,34 @@ func (c *Client) DeleteAccessRequest(ctx context.Context, accessRequestID string
 // ----------------------------------------------------------------------------
 
 // WaitForAccessRequest polls the access request status until processing completes.
+// Each poll is bounded by the client's read timeout (see WithReadTimeout); a
+// poll that times out is retried at the next interval, so only ctx bounds the
+// whole wait.
 func (c *Client) WaitForAccessRequest(ctx context.Context, accessRequestID string, pollInterval time.Duration) (*AccessRequestResponse, error) {
-	ticker := time.NewTicker(pollInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
-			resp, err := c.GetAccessRequest(ctx, accessRequestID)
-			if err != nil {
-				return nil, err
-			}
+	return c.WaitForAccessRequestWithOptions(ctx, accessRequestID, WaitOptions{PollInterval: pollInterval})
+}
 
-			switch resp.Properties.ProcessingStatus {
-			case ProcessingCompleted, ProcessingError:
-				return resp, nil
-			}
-			// Continue polling for queued/processing status
+// WaitForAccessRequestWithOptions is like WaitForAccessRequest but accepts a
+// full polling policy, e.g. exponential backoff.
+func (c *Client) WaitForAccessRequestWithOptions(ctx context.Context, accessRequestID string, opts WaitOptions) (*AccessRequestResponse, error) {
+	var resp *AccessRequestResponse
+	err := common.Poll(ctx, opts, pollStep(func(ctx context.Context) (bool, error) {
+		var err error
+		resp, err = c.GetAccessRequest(ctx, accessRequestID)
+		if err != nil {
+			return false, err
 		}
+		switch resp.Properties.ProcessingStatus {
+		case ProcessingCompleted, ProcessingError:
+			return true, nil
+		}
+		// Continue polling for queued/processing status
+		return false, nil
+	}))
+	if err != nil {
+		return nil, err
 	}
+	return resp, nil
 }
 
 // ----------------------------------------------------------------------------
@@ -187,28 +218,16 @@ func (c *Client) CheckFeasibility(ctx context.Context, req AccessRequest, pollIn
 	}
 
 	// Wait for processing to complete
-	ticker := time.NewTicker(pollInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
-			resp, err := c.GetAccessRequest(ctx, created.Properties.AccessRequestID)
-			if err != nil {
-				return nil, err
-			}
-
-			switch resp.Properties.ProcessingStatus {
-			case ProcessingCompleted:
-				// Get detailed response with access windows
-				return c.GetAccessRequestDetail(ctx, created.Properties.AccessRequestID)
-			case ProcessingError:
-				return nil, fmt.Errorf("access request processing failed: %s", resp.Properties.AccessibilityMessage)
-			}
-		}
+	resp, err := c.WaitForAccessRequest(ctx, created.Properties.AccessRequestID, pollInterval)
+	if err != nil {
+		return nil, err
 	}
+	if resp.Properties.ProcessingStatus == ProcessingError {
+		return nil, fmt.Errorf("access request processing failed: %s", resp.Properties.AccessibilityMessage)
+	}
+
+	// Get detailed response with access windows
+	return c.GetAccessRequestDetail(ctx, created.Properties.AccessRequestID)
 }
 
 // ----------------------------------------------------------------------------
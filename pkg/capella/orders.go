@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
 
 
@@ -149,6 +151,22 @@ type DownloadOptions struct {
 
 	// Overwrite existing files (default: false)
 	Overwrite bool
+
+	// ChunkSize is the size of each ranged request made by DownloadAsset
+	// (default: 64 MiB).
+	ChunkSize int64
+
+	// Parallelism bounds the number of concurrent chunk requests made by
+	// DownloadAsset (default: 4).
+	Parallelism int
+
+	// VerifyChecksum verifies the downloaded bytes against the asset's
+	// file:checksum, if present.
+	VerifyChecksum bool
+
+	// RenewURL returns a fresh presigned URL when the current one expires.
+	// If nil, DownloadAsset re-fetches the item from the catalog.
+	RenewURL func(ctx context.Context) (string, error)
 }
 
 // DownloadToFile downloads an asset from a URL to a local file.
@@ -277,26 +295,31 @@ func (c *Client) QuickOrder(ctx context.Context, items []OrderItem) (*Order, err
 
 // WaitForOrder polls the order status until it completes or times out.
 func (c *Client) WaitForOrder(ctx context.Context, orderID string, pollInterval time.Duration) (*Order, error) {
-	ticker := time.NewTicker(pollInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
-			order, err := c.GetOrder(ctx, orderID)
-			if err != nil {
-				return nil, err
-			}
+	return c.WaitForOrderWithOptions(ctx, orderID, WaitOptions{PollInterval: pollInterval})
+}
 
-			switch order.Status {
-			case OrderCompleted:
-				return order, nil
-			case OrderFailed, OrderCanceled:
-				return order, fmt.Errorf("order %s: %s", order.Status, orderID)
-			}
-			// Continue polling for pending/processing status
+// WaitForOrderWithOptions is like WaitForOrder but accepts a full polling
+// policy, e.g. exponential backoff.
+func (c *Client) WaitForOrderWithOptions(ctx context.Context, orderID string, opts WaitOptions) (*Order, error) {
+	var order *Order
+	err := common.Poll(ctx, opts, pollStep(func(ctx context.Context) (bool, error) {
+		var err error
+		order, err = c.GetOrder(ctx, orderID)
+		if err != nil {
+			return false, err
+		}
+		switch order.Status {
+		case OrderCompleted, OrderFailed, OrderCanceled:
+			return true, nil
 		}
+		// Continue polling for pending/processing status
+		return false, nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != OrderCompleted {
+		return order, fmt.Errorf("order %s: %s", order.Status, orderID)
 	}
+	return order, nil
 }
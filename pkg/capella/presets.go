@@ -0,0 +1,187 @@
+package capella
+
+import (
+	"time"
+
+	"github.com/paulmach/orb/geojson"
+)
+
+// Defaults applied by the tasking presets. Every default can be overridden
+// with a TaskOption.
+const (
+	// UrgentSpotlightTier and UrgentSpotlightType are the defaults of
+	// NewUrgentSpotlightTask.
+	UrgentSpotlightTier = TierUrgent
+	UrgentSpotlightType = CollectionSpotlight
+
+	// StandardAreaTier is the default tier of NewStandardAreaTask.
+	StandardAreaTier = TierStandard
+
+	// WeeklyMonitoringTier, WeeklyMonitoringType and
+	// WeeklyMonitoringInterval (in days) are the defaults of
+	// NewWeeklyMonitoring.
+	WeeklyMonitoringTier     = TierStandard
+	WeeklyMonitoringType     = CollectionStripmap20
+	WeeklyMonitoringInterval = 7
+
+	// PresetLookDirection is the look direction all presets request.
+	PresetLookDirection = LookRight
+)
+
+// PresetProductTypes returns the product types all presets request: GEO and
+// SICD.
+func PresetProductTypes() []ProductType {
+	return []ProductType{ProductGEO, ProductSICD}
+}
+
+// taskPreset holds the settings shared by tasking and repeat requests that
+// the presets fill in and TaskOptions override.
+type taskPreset struct {
+	name           string
+	description    string
+	tier           CollectionTier
+	collectionType CollectionType
+	products       []ProductType
+	constraints    *CollectConstraints
+	repeatInterval int
+}
+
+func newTaskPreset(tier CollectionTier, ct CollectionType, opts []TaskOption) *taskPreset {
+	look := PresetLookDirection
+	p := &taskPreset{
+		tier:           tier,
+		collectionType: ct,
+		products:       PresetProductTypes(),
+		constraints:    &CollectConstraints{LookDirection: &look},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// TaskOption overrides a default of the tasking presets.
+type TaskOption func(*taskPreset)
+
+// WithTaskName sets the request name.
+func WithTaskName(name string) TaskOption {
+	return func(p *taskPreset) {
+		p.name = name
+	}
+}
+
+// WithTaskDescription sets the request description.
+func WithTaskDescription(desc string) TaskOption {
+	return func(p *taskPreset) {
+		p.description = desc
+	}
+}
+
+// WithTier sets the collection tier.
+func WithTier(tier CollectionTier) TaskOption {
+	return func(p *taskPreset) {
+		p.tier = tier
+	}
+}
+
+// WithCollectionType sets the collection type.
+func WithCollectionType(ct CollectionType) TaskOption {
+	return func(p *taskPreset) {
+		p.collectionType = ct
+	}
+}
+
+// WithProducts sets the product types to process.
+func WithProducts(products ...ProductType) TaskOption {
+	return func(p *taskPreset) {
+		p.products = products
+	}
+}
+
+// WithConstraints replaces the default collect constraints, including the
+// look direction.
+func WithConstraints(constraints CollectConstraints) TaskOption {
+	return func(p *taskPreset) {
+		p.constraints = &constraints
+	}
+}
+
+// WithLookDirection sets the look direction, keeping other constraints.
+func WithLookDirection(look LookDirection) TaskOption {
+	return func(p *taskPreset) {
+		if p.constraints == nil {
+			p.constraints = &CollectConstraints{}
+		}
+		p.constraints.LookDirection = &look
+	}
+}
+
+// WithRepeatInterval sets the days between collections of a repeat request.
+// It has no effect on single tasking requests.
+func WithRepeatInterval(days int) TaskOption {
+	return func(p *taskPreset) {
+		p.repeatInterval = days
+	}
+}
+
+func (p *taskPreset) taskingRequest(geom *geojson.Geometry, open, close time.Time) TaskingRequest {
+	req := TaskingRequest{
+		Type:     "Feature",
+		Geometry: geom,
+		Properties: TaskingRequestProperties{
+			TaskingRequestName:        p.name,
+			TaskingRequestDescription: p.description,
+			WindowOpen:                open,
+			WindowClose:               close,
+			CollectionTier:            p.tier,
+			CollectionType:            p.collectionType,
+			CollectConstraints:        p.constraints,
+		},
+	}
+	if len(p.products) > 0 {
+		req.Properties.ProcessingConfig = &ProcessingConfig{ProductTypes: p.products}
+	}
+	return req
+}
+
+// NewUrgentSpotlightTask returns an urgent spotlight tasking request over a
+// point, with a window opening now and lasting window.
+func NewUrgentSpotlightTask(lon, lat float64, window time.Duration, opts ...TaskOption) TaskingRequest {
+	open := time.Now().UTC().Truncate(time.Second)
+	p := newTaskPreset(UrgentSpotlightTier, UrgentSpotlightType, opts)
+	return p.taskingRequest(Point(lon, lat), open, open.Add(window))
+}
+
+// NewStandardAreaTask returns a standard-tier tasking request of collection
+// type ct over an area.
+func NewStandardAreaTask(geom *geojson.Geometry, open, close time.Time, ct CollectionType, opts ...TaskOption) TaskingRequest {
+	p := newTaskPreset(StandardAreaTier, ct, opts)
+	return p.taskingRequest(geom, open, close)
+}
+
+// NewWeeklyMonitoring returns a repeat request collecting geom once a week
+// between open and close.
+func NewWeeklyMonitoring(geom *geojson.Geometry, open, close time.Time, opts ...TaskOption) RepeatRequest {
+	p := newTaskPreset(WeeklyMonitoringTier, WeeklyMonitoringType, opts)
+	if p.repeatInterval == 0 {
+		p.repeatInterval = WeeklyMonitoringInterval
+	}
+	req := RepeatRequest{
+		Type:     "Feature",
+		Geometry: geom,
+		Properties: RepeatRequestProperties{
+			RepeatRequestName:        p.name,
+			RepeatRequestDescription: p.description,
+			WindowOpen:               open,
+			WindowClose:              close,
+			RepeatInterval:           p.repeatInterval,
+			CollectionTier:           p.tier,
+			CollectionType:           p.collectionType,
+			CollectConstraints:       p.constraints,
+		},
+	}
+	if len(p.products) > 0 {
+		req.Properties.ProcessingConfig = &ProcessingConfig{ProductTypes: p.products}
+	}
+	return req
+}
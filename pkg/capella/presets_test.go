@@ -0,0 +1,129 @@
+package capella_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+// properties returns the JSON-encoded properties of a request.
+func properties(t *testing.T, req any) map[string]any {
+	t.Helper()
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	var body struct {
+		Type       string         `json:"type"`
+		Properties map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("failed to unmarshal request: %v", err)
+	}
+	if body.Type != "Feature" {
+		t.Errorf("expected type Feature, got %q", body.Type)
+	}
+	return body.Properties
+}
+
+func requireDefaults(t *testing.T, props map[string]any, tier capella.CollectionTier, ct capella.CollectionType) {
+	t.Helper()
+	if props["collectionTier"] != string(tier) || props["collectionType"] != string(ct) {
+		t.Errorf("expected %s %s, got %v %v", tier, ct, props["collectionTier"], props["collectionType"])
+	}
+	constraints, _ := props["collectConstraints"].(map[string]any)
+	if constraints["lookDirection"] != string(capella.PresetLookDirection) {
+		t.Errorf("expected look direction %s, got %v", capella.PresetLookDirection, constraints["lookDirection"])
+	}
+	products, _ := props["processingConfig"].(map[string]any)["productTypes"].([]any)
+	want := capella.PresetProductTypes()
+	if len(products) != len(want) || products[0] != string(want[0]) || products[1] != string(want[1]) {
+		t.Errorf("expected products %v, got %v", want, products)
+	}
+}
+
+func TestNewUrgentSpotlightTask(t *testing.T) {
+	req := capella.NewUrgentSpotlightTask(-118.25, 34.05, 6*time.Hour)
+
+	requireDefaults(t, properties(t, req), capella.UrgentSpotlightTier, capella.UrgentSpotlightType)
+	if got := req.Properties.WindowClose.Sub(req.Properties.WindowOpen); got != 6*time.Hour {
+		t.Errorf("expected a 6h window, got %v", got)
+	}
+	if time.Since(req.Properties.WindowOpen) > time.Minute {
+		t.Errorf("expected the window to open now, got %v", req.Properties.WindowOpen)
+	}
+	if req.Geometry.Geometry() != (orb.Point{-118.25, 34.05}) {
+		t.Errorf("unexpected geometry %v", req.Geometry.Geometry())
+	}
+}
+
+func TestNewStandardAreaTask(t *testing.T) {
+	geom := geojson.NewGeometry(orb.Polygon{{{10, 50}, {11, 50}, {11, 51}, {10, 51}, {10, 50}}})
+	open := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	req := capella.NewStandardAreaTask(geom, open, open.AddDate(0, 0, 3), capella.CollectionStripmap50)
+
+	props := properties(t, req)
+	requireDefaults(t, props, capella.StandardAreaTier, capella.CollectionStripmap50)
+	if props["windowOpen"] != "2025-04-01T00:00:00Z" || props["windowClose"] != "2025-04-04T00:00:00Z" {
+		t.Errorf("unexpected window %v - %v", props["windowOpen"], props["windowClose"])
+	}
+}
+
+func TestNewWeeklyMonitoring(t *testing.T) {
+	geom := capella.Point(30.5, 50.45)
+	open := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	req := capella.NewWeeklyMonitoring(geom, open, open.AddDate(0, 6, 0), capella.WithTaskName("Kyiv weekly"))
+
+	props := properties(t, req)
+	requireDefaults(t, props, capella.WeeklyMonitoringTier, capella.WeeklyMonitoringType)
+	if props["repeatInterval"] != float64(capella.WeeklyMonitoringInterval) {
+		t.Errorf("expected interval %d, got %v", capella.WeeklyMonitoringInterval, props["repeatInterval"])
+	}
+	if props["repeatrequestName"] != "Kyiv weekly" {
+		t.Errorf("expected name, got %v", props["repeatrequestName"])
+	}
+}
+
+func TestPresets_OptionsOverrideDefaults(t *testing.T) {
+	offNadir := 30.0
+	opts := []capella.TaskOption{
+		capella.WithTaskName("flood"),
+		capella.WithTaskDescription("activation 42"),
+		capella.WithTier(capella.TierPriority),
+		capella.WithCollectionType(capella.CollectionSpotlightWide),
+		capella.WithProducts(capella.ProductSLC),
+		capella.WithConstraints(capella.CollectConstraints{OffNadirMax: &offNadir}),
+		capella.WithLookDirection(capella.LookEither),
+		capella.WithRepeatInterval(3),
+	}
+
+	task := capella.NewUrgentSpotlightTask(1, 2, time.Hour, opts...)
+	p := task.Properties
+	if p.TaskingRequestName != "flood" || p.TaskingRequestDescription != "activation 42" {
+		t.Errorf("unexpected name/description %q/%q", p.TaskingRequestName, p.TaskingRequestDescription)
+	}
+	if p.CollectionTier != capella.TierPriority || p.CollectionType != capella.CollectionSpotlightWide {
+		t.Errorf("unexpected tier/type %s/%s", p.CollectionTier, p.CollectionType)
+	}
+	if len(p.ProcessingConfig.ProductTypes) != 1 || p.ProcessingConfig.ProductTypes[0] != capella.ProductSLC {
+		t.Errorf("unexpected products %v", p.ProcessingConfig.ProductTypes)
+	}
+	if *p.CollectConstraints.OffNadirMax != 30 || *p.CollectConstraints.LookDirection != capella.LookEither {
+		t.Errorf("unexpected constraints %+v", p.CollectConstraints)
+	}
+
+	repeat := capella.NewWeeklyMonitoring(capella.Point(1, 2), time.Now(), time.Now().AddDate(0, 1, 0), opts...)
+	if repeat.Properties.RepeatInterval != 3 || repeat.Properties.CollectionTier != capella.TierPriority {
+		t.Errorf("unexpected repeat properties %+v", repeat.Properties)
+	}
+
+	// Presets do not share default state.
+	other := capella.NewUrgentSpotlightTask(1, 2, time.Hour)
+	if *other.Properties.CollectConstraints.LookDirection != capella.PresetLookDirection {
+		t.Errorf("expected the default look direction, got %s", *other.Properties.CollectConstraints.LookDirection)
+	}
+}
@@ -0,0 +1,141 @@
+package capella
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Tasking Quota
+// ----------------------------------------------------------------------------
+
+// QuotaTracker tracks an organization's tasking quota across the clients
+// creating tasks, so that a monthly per-tier cap is not exceeded mid-month.
+// Set one on a client with WithQuotaTracker.
+//
+// Reserve claims one task of the tier or returns an error, typically a
+// *QuotaExhaustedError, if none is left. The returned release function must
+// be called exactly once: with confirm true when the task was created, so
+// the reservation counts against the quota, or false to return it.
+// Implementations backed by a shared store (Redis, Postgres, ...) coordinate
+// clients across processes.
+type QuotaTracker interface {
+	Reserve(ctx context.Context, tier CollectionTier) (release func(confirm bool), err error)
+}
+
+// QuotaUsage is the state of one tier's quota in the current month.
+type QuotaUsage struct {
+	Limit int
+	// Used is the number of confirmed tasks.
+	Used int
+	// Reserved is the number of reservations not yet confirmed or released.
+	Reserved int
+	Reset    time.Time
+}
+
+// MemoryQuotaTracker is an in-process QuotaTracker with monthly per-tier
+// limits, for clients sharing one process. Months are calendar months in
+// UTC. It is safe for concurrent use.
+type MemoryQuotaTracker struct {
+	limits map[CollectionTier]int
+	now    func() time.Time
+
+	mu    sync.Mutex
+	month time.Time
+	usage map[CollectionTier]*QuotaUsage
+}
+
+// MemoryQuotaOption configures a MemoryQuotaTracker.
+type MemoryQuotaOption func(*MemoryQuotaTracker)
+
+// WithQuotaClock sets the clock used to determine the current month.
+// Defaults to time.Now.
+func WithQuotaClock(now func() time.Time) MemoryQuotaOption {
+	return func(t *MemoryQuotaTracker) {
+		t.now = now
+	}
+}
+
+// NewMemoryQuotaTracker creates a tracker with the given monthly limit per
+// tier. Tiers without a limit are not tracked.
+func NewMemoryQuotaTracker(limits map[CollectionTier]int, opts ...MemoryQuotaOption) *MemoryQuotaTracker {
+	t := &MemoryQuotaTracker{
+		limits: make(map[CollectionTier]int, len(limits)),
+		now:    time.Now,
+		usage:  make(map[CollectionTier]*QuotaUsage),
+	}
+	for tier, limit := range limits {
+		t.limits[tier] = limit
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Reserve implements QuotaTracker.
+func (t *MemoryQuotaTracker) Reserve(ctx context.Context, tier CollectionTier) (func(confirm bool), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	limit, tracked := t.limits[tier]
+	if !tracked {
+		return func(bool) {}, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u := t.current(tier)
+	if u.Used+u.Reserved >= limit {
+		return nil, &QuotaExhaustedError{Tier: tier, Limit: limit, Reset: u.Reset}
+	}
+	u.Reserved++
+
+	month := t.month
+	var once sync.Once
+	return func(confirm bool) {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// A reservation from a month that has since rolled over no
+			// longer counts.
+			if !t.month.Equal(month) {
+				return
+			}
+			u := t.usage[tier]
+			u.Reserved--
+			if confirm {
+				u.Used++
+			}
+		})
+	}, nil
+}
+
+// Usage returns the current month's usage of tier. ok is false if the tier
+// has no limit.
+func (t *MemoryQuotaTracker) Usage(tier CollectionTier) (usage QuotaUsage, ok bool) {
+	if _, tracked := t.limits[tier]; !tracked {
+		return QuotaUsage{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return *t.current(tier), true
+}
+
+// current returns the usage of tier in the current month, starting a new
+// month if it has rolled over. The caller must hold t.mu.
+func (t *MemoryQuotaTracker) current(tier CollectionTier) *QuotaUsage {
+	now := t.now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if !month.Equal(t.month) {
+		t.month = month
+		clear(t.usage)
+	}
+	u, ok := t.usage[tier]
+	if !ok {
+		u = &QuotaUsage{Limit: t.limits[tier], Reset: month.AddDate(0, 1, 0)}
+		t.usage[tier] = u
+	}
+	return u
+}
@@ -0,0 +1,175 @@
+package capella_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+func TestMemoryQuotaTracker_Accounting(t *testing.T) {
+	tracker := capella.NewMemoryQuotaTracker(map[capella.CollectionTier]int{capella.TierUrgent: 2})
+	ctx := context.Background()
+
+	r1, err := tracker.Reserve(ctx, capella.TierUrgent)
+	if err != nil {
+		t.Fatalf("first Reserve: %v", err)
+	}
+	r2, err := tracker.Reserve(ctx, capella.TierUrgent)
+	if err != nil {
+		t.Fatalf("second Reserve: %v", err)
+	}
+	if u, _ := tracker.Usage(capella.TierUrgent); u.Reserved != 2 || u.Used != 0 {
+		t.Errorf("after two reservations: %+v", u)
+	}
+
+	// Pending reservations count against the limit.
+	var exhausted *capella.QuotaExhaustedError
+	if _, err := tracker.Reserve(ctx, capella.TierUrgent); !errors.As(err, &exhausted) {
+		t.Fatalf("expected QuotaExhaustedError, got %v", err)
+	}
+	if exhausted.Tier != capella.TierUrgent || exhausted.Limit != 2 {
+		t.Errorf("unexpected error fields: %+v", exhausted)
+	}
+
+	r1(true)
+	r2(false)
+	r2(true) // later calls are ignored
+	if u, _ := tracker.Usage(capella.TierUrgent); u.Reserved != 0 || u.Used != 1 {
+		t.Errorf("after confirm and release: %+v", u)
+	}
+
+	if _, err := tracker.Reserve(ctx, capella.TierUrgent); err != nil {
+		t.Errorf("released quota should be reusable: %v", err)
+	}
+	if _, err := tracker.Reserve(ctx, capella.TierUrgent); err == nil {
+		t.Error("expected the quota to be exhausted")
+	}
+
+	// Tiers without a limit are not tracked.
+	release, err := tracker.Reserve(ctx, capella.TierStandard)
+	if err != nil {
+		t.Fatalf("untracked tier: %v", err)
+	}
+	release(true)
+	if _, ok := tracker.Usage(capella.TierStandard); ok {
+		t.Error("expected no usage for an untracked tier")
+	}
+}
+
+func TestMemoryQuotaTracker_Concurrent(t *testing.T) {
+	const limit = 10
+	tracker := capella.NewMemoryQuotaTracker(map[capella.CollectionTier]int{capella.TierUrgent: limit})
+
+	var granted atomic.Int32
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := tracker.Reserve(context.Background(), capella.TierUrgent)
+			if err != nil {
+				return
+			}
+			granted.Add(1)
+			release(true)
+		}()
+	}
+	wg.Wait()
+
+	if n := granted.Load(); n != limit {
+		t.Errorf("granted %d reservations, want %d", n, limit)
+	}
+	if u, _ := tracker.Usage(capella.TierUrgent); u.Used != limit || u.Reserved != 0 {
+		t.Errorf("unexpected usage: %+v", u)
+	}
+}
+
+func TestMemoryQuotaTracker_MonthRollover(t *testing.T) {
+	now := time.Date(2025, 1, 31, 23, 59, 0, 0, time.UTC)
+	tracker := capella.NewMemoryQuotaTracker(
+		map[capella.CollectionTier]int{capella.TierUrgent: 1},
+		capella.WithQuotaClock(func() time.Time { return now }),
+	)
+	ctx := context.Background()
+
+	release, err := tracker.Reserve(ctx, capella.TierUrgent)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	var exhausted *capella.QuotaExhaustedError
+	if _, err := tracker.Reserve(ctx, capella.TierUrgent); !errors.As(err, &exhausted) {
+		t.Fatalf("expected QuotaExhaustedError, got %v", err)
+	}
+	if want := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC); !exhausted.Reset.Equal(want) {
+		t.Errorf("Reset = %v, want %v", exhausted.Reset, want)
+	}
+
+	now = now.Add(2 * time.Minute)
+	// Confirming January's reservation does not count against February.
+	release(true)
+	if u, _ := tracker.Usage(capella.TierUrgent); u.Used != 0 || u.Reserved != 0 {
+		t.Errorf("expected a fresh month, got %+v", u)
+	}
+	if _, err := tracker.Reserve(ctx, capella.TierUrgent); err != nil {
+		t.Errorf("expected quota in the new month: %v", err)
+	}
+}
+
+func TestCreateTask_QuotaTracker(t *testing.T) {
+	var fail atomic.Bool
+	var hits atomic.Int32
+	srv := func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if fail.Load() {
+			jsonResponse(w, http.StatusBadRequest, map[string]any{"message": "invalid window"})
+			return
+		}
+		jsonResponse(w, http.StatusCreated, map[string]any{
+			"type":       "Feature",
+			"properties": map[string]any{"taskingrequestId": "tr-1", "collectionTier": "urgent"},
+		})
+	}
+	tracker := capella.NewMemoryQuotaTracker(map[capella.CollectionTier]int{capella.TierUrgent: 1})
+	_, server := newTestClient(t, srv)
+	cli, err := capella.NewClient(
+		capella.WithBaseURL(server.URL),
+		capella.WithAPIKey("test-api-key"),
+		capella.WithQuotaTracker(tracker),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	req := capella.TaskingRequest{Properties: capella.TaskingRequestProperties{CollectionTier: capella.TierUrgent}}
+
+	// A failed creation releases its reservation.
+	fail.Store(true)
+	if _, err := cli.CreateTask(t.Context(), req); err == nil {
+		t.Fatal("expected the API error")
+	}
+	if u, _ := tracker.Usage(capella.TierUrgent); u.Used != 0 || u.Reserved != 0 {
+		t.Errorf("after failure: %+v", u)
+	}
+
+	fail.Store(false)
+	if _, err := cli.CreateTask(t.Context(), req); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if u, _ := tracker.Usage(capella.TierUrgent); u.Used != 1 {
+		t.Errorf("after success: %+v", u)
+	}
+
+	// With the quota used up, the task is not submitted.
+	var exhausted *capella.QuotaExhaustedError
+	if _, err := cli.CreateTask(t.Context(), req); !errors.As(err, &exhausted) {
+		t.Fatalf("expected QuotaExhaustedError, got %v", err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
@@ -0,0 +1,75 @@
+package capella
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EstimateRepeatOccurrences returns how many collections a repeat request
+// schedules: one at the start of each RepeatInterval between WindowOpen and
+// WindowClose, including a partial interval at the end of the window. A
+// window shorter than one interval has a single occurrence.
+func EstimateRepeatOccurrences(props RepeatRequestProperties) (int, error) {
+	if props.RepeatInterval <= 0 {
+		return 0, fmt.Errorf("repeatInterval must be positive, got %d", props.RepeatInterval)
+	}
+	if !props.WindowOpen.Before(props.WindowClose) {
+		return 0, fmt.Errorf("windowOpen %s must be before windowClose %s",
+			props.WindowOpen.Format(time.RFC3339), props.WindowClose.Format(time.RFC3339))
+	}
+	interval := time.Duration(props.RepeatInterval) * 24 * time.Hour
+	window := props.WindowClose.Sub(props.WindowOpen)
+	return int((window + interval - 1) / interval), nil
+}
+
+// CollectCostFunc returns the cost of a single collect of the given tier and
+// collection type.
+type CollectCostFunc func(ctx context.Context, tier CollectionTier, collectionType CollectionType) (amount float64, currency string, err error)
+
+// TaskCollectCost returns a CollectCostFunc that prices every collect at the
+// quoted total of an existing single-collect tasking request in review (see
+// GetTaskCost). The reference task should have the tier and collection type
+// being estimated; they are not checked.
+func (c *Client) TaskCollectCost(taskID string) CollectCostFunc {
+	return func(ctx context.Context, _ CollectionTier, _ CollectionType) (float64, string, error) {
+		cost, err := c.GetTaskCost(ctx, taskID)
+		if err != nil {
+			return 0, "", err
+		}
+		return cost.Total, cost.Currency, nil
+	}
+}
+
+// RepeatCostEstimate is the projected cost of a repeat request.
+type RepeatCostEstimate struct {
+	Occurrences   int
+	PerOccurrence float64
+	Total         float64
+	Currency      string
+}
+
+// EstimateRepeatCost projects the total cost of a repeat request by
+// multiplying its number of occurrences (see EstimateRepeatOccurrences) by
+// the cost of one collect of its tier and collection type. It does not
+// account for contract discounts over the series.
+func (c *Client) EstimateRepeatCost(ctx context.Context, req RepeatRequest, cost CollectCostFunc) (*RepeatCostEstimate, error) {
+	if cost == nil {
+		return nil, errors.New("a collect cost function is required")
+	}
+	n, err := EstimateRepeatOccurrences(req.Properties)
+	if err != nil {
+		return nil, err
+	}
+	amount, currency, err := cost(ctx, req.Properties.CollectionTier, req.Properties.CollectionType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collect cost: %w", err)
+	}
+	return &RepeatCostEstimate{
+		Occurrences:   n,
+		PerOccurrence: amount,
+		Total:         amount * float64(n),
+		Currency:      currency,
+	}, nil
+}
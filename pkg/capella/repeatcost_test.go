@@ -0,0 +1,120 @@
+package capella_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+func TestEstimateRepeatOccurrences(t *testing.T) {
+	open := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		window   time.Duration
+		interval int
+		want     int
+		wantErr  bool
+	}{
+		{"shorter than one interval", 3 * day, 7, 1, false},
+		{"exactly one interval", 7 * day, 7, 1, false},
+		{"exact multiple", 28 * day, 7, 4, false},
+		{"partial trailing interval", 28*day + time.Hour, 7, 5, false},
+		{"six months weekly", 182 * day, 7, 26, false},
+		{"zero interval", 7 * day, 0, 0, true},
+		{"negative interval", 7 * day, -1, 0, true},
+		{"empty window", 0, 7, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := capella.EstimateRepeatOccurrences(capella.RepeatRequestProperties{
+				WindowOpen:     open,
+				WindowClose:    open.Add(tt.window),
+				RepeatInterval: tt.interval,
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %d occurrences, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func repeatRequest(days, interval int) capella.RepeatRequest {
+	open := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return capella.RepeatRequest{Properties: capella.RepeatRequestProperties{
+		WindowOpen:     open,
+		WindowClose:    open.AddDate(0, 0, days),
+		RepeatInterval: interval,
+		CollectionTier: capella.TierStandard,
+		CollectionType: capella.CollectionSpotlight,
+	}}
+}
+
+func TestEstimateRepeatCost(t *testing.T) {
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request %s", r.URL.Path)
+	})
+
+	var gotTier capella.CollectionTier
+	var gotType capella.CollectionType
+	cost := func(_ context.Context, tier capella.CollectionTier, ct capella.CollectionType) (float64, string, error) {
+		gotTier, gotType = tier, ct
+		return 1250, "USD", nil
+	}
+
+	est, err := cli.EstimateRepeatCost(context.Background(), repeatRequest(30, 7), cost)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := capella.RepeatCostEstimate{Occurrences: 5, PerOccurrence: 1250, Total: 6250, Currency: "USD"}
+	if *est != want {
+		t.Errorf("expected %+v, got %+v", want, *est)
+	}
+	if gotTier != capella.TierStandard || gotType != capella.CollectionSpotlight {
+		t.Errorf("expected cost lookup for standard spotlight, got %s %s", gotTier, gotType)
+	}
+
+	failing := func(context.Context, capella.CollectionTier, capella.CollectionType) (float64, string, error) {
+		return 0, "", errors.New("no price")
+	}
+	if _, err := cli.EstimateRepeatCost(context.Background(), repeatRequest(30, 7), failing); err == nil {
+		t.Error("expected the cost lookup error")
+	}
+	if _, err := cli.EstimateRepeatCost(context.Background(), repeatRequest(30, 0), cost); err == nil {
+		t.Error("expected an interval error")
+	}
+}
+
+func TestEstimateRepeatCost_TaskCollectCost(t *testing.T) {
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodGet)
+		requirePath(t, r, "/task/ref-task/cost")
+		jsonResponse(w, http.StatusOK, map[string]any{
+			"taskingrequestId": "ref-task",
+			"total":            400.5,
+			"currency":         "EUR",
+		})
+	})
+
+	est, err := cli.EstimateRepeatCost(context.Background(), repeatRequest(14, 7), cli.TaskCollectCost("ref-task"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if est.Occurrences != 2 || est.Total != 801 || est.Currency != "EUR" {
+		t.Errorf("unexpected estimate: %+v", *est)
+	}
+}
@@ -0,0 +1,130 @@
+package capella
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Review Workflow
+// ----------------------------------------------------------------------------
+
+// PendingReviewParams defines parameters for ListPendingReview.
+type PendingReviewParams struct {
+	// OrganizationID limits the listing to one organization. Empty lists
+	// the tasks visible to the caller, i.e. their own organization.
+	OrganizationID string
+	Limit          int
+	Sort           string
+	Order          string // "asc" or "desc"
+}
+
+// ListPendingReview returns an iterator over tasking requests awaiting
+// review, using the search endpoint with the query
+//
+//	{"lastStatusCode": ["review"]}
+//
+// plus "organizationId" when params.OrganizationID is set.
+func (c *Client) ListPendingReview(ctx context.Context, params PendingReviewParams) iter.Seq2[TaskingRequestResponse, error] {
+	qb := NewTaskQuery().Status(TaskReview)
+	if params.OrganizationID != "" {
+		qb.OrgID(params.OrganizationID)
+	}
+	query, err := qb.Build()
+	if err != nil {
+		return func(yield func(TaskingRequestResponse, error) bool) {
+			yield(TaskingRequestResponse{}, err)
+		}
+	}
+	return c.SearchTasksIterator(ctx, TaskSearchRequest{
+		Query: query,
+		Limit: params.Limit,
+		Sort:  params.Sort,
+		Order: params.Order,
+	})
+}
+
+// ReviewDetail is what a reviewer needs to decide on a tasking request.
+type ReviewDetail struct {
+	Task *TaskingRequestResponse
+	// Cost is the quoted cost, or nil while the quote is pending.
+	Cost *TaskCost
+	// RequesterID and OrganizationID identify who submitted the task.
+	RequesterID    string
+	OrganizationID string
+	// InReviewSince is when the task entered review, if its status history
+	// records it.
+	InReviewSince time.Time
+	// Deadline is the time by which the task must be approved: the expiry
+	// of the cost quote, or the opening of the collection window if that
+	// is earlier or the quote has no expiry.
+	Deadline time.Time
+}
+
+// GetReviewDetail returns the cost, requester and review deadline of a
+// tasking request. The task need not be in review; check Task.Properties.Status.
+func (c *Client) GetReviewDetail(ctx context.Context, taskID string) (*ReviewDetail, error) {
+	task, err := c.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &ReviewDetail{
+		Task:           task,
+		RequesterID:    task.Properties.UserID,
+		OrganizationID: task.Properties.OrgID,
+		Deadline:       task.Properties.WindowOpen,
+	}
+	for _, entry := range task.StatusHistory {
+		if entry.Code == TaskReview && (detail.InReviewSince.IsZero() || entry.Time.After(detail.InReviewSince)) {
+			detail.InReviewSince = entry.Time
+		}
+	}
+
+	if task.Properties.Status != TaskReview {
+		return detail, nil
+	}
+	cost, err := c.GetTaskCost(ctx, taskID)
+	if err != nil && !errors.Is(err, ErrQuotePending) {
+		return nil, err
+	}
+	detail.Cost = cost
+	if cost != nil && cost.ExpiresAt != nil && (detail.Deadline.IsZero() || cost.ExpiresAt.Before(detail.Deadline)) {
+		detail.Deadline = *cost.ExpiresAt
+	}
+	return detail, nil
+}
+
+// RejectTask rejects a tasking request in review, recording reason for the
+// requester. It returns a *NotReviewableError if the task is not in review.
+func (c *Client) RejectTask(ctx context.Context, taskID, reason string) (*TaskingRequestResponse, error) {
+	if err := c.confirmProductionWrite("reject task"); err != nil {
+		return nil, err
+	}
+
+	payload := reviewDecision{Status: TaskRejected, Reason: reason}
+	return c.decideReview(ctx, taskID, payload)
+}
+
+// reviewDecision is the PATCH body approving or rejecting a task.
+type reviewDecision struct {
+	Status TaskStatus `json:"status"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+// decideReview sends a review decision, mapping the API's 409 Conflict for
+// tasks that are not in review to a *NotReviewableError.
+func (c *Client) decideReview(ctx context.Context, taskID string, decision reviewDecision) (*TaskingRequestResponse, error) {
+	var resp TaskingRequestResponse
+	if err := c.Do(ctx, http.MethodPatch, "/task/"+taskID, 0, decision, &resp); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+			return nil, &NotReviewableError{TaskingRequestID: taskID, Decision: decision.Status, Err: apiErr}
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,201 @@
+package capella_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+func TestListPendingReview(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPost)
+		requirePath(t, r, "/tasks/search")
+
+		var req capella.TaskSearchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		query, _ := json.Marshal(req.Query)
+		if want := `{"lastStatusCode":["review"],"organizationId":"org-1"}`; string(query) != want {
+			t.Errorf("expected query %s, got %s", want, query)
+		}
+		if req.Sort != "submissionTime" || req.Order != "asc" {
+			t.Errorf("expected sort submissionTime asc, got %s %s", req.Sort, req.Order)
+		}
+
+		jsonResponse(w, http.StatusOK, capella.TaskingRequestsPagedResponse{
+			Results: []capella.TaskingRequestResponse{
+				{Properties: capella.TaskingRequestPropertiesResponse{TaskingRequestID: "tr-1", Status: capella.TaskReview}},
+				{Properties: capella.TaskingRequestPropertiesResponse{TaskingRequestID: "tr-2", Status: capella.TaskReview}},
+			},
+			CurrentPage: 1,
+			TotalPages:  1,
+		})
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	var ids []string
+	params := capella.PendingReviewParams{OrganizationID: "org-1", Sort: "submissionTime", Order: "asc"}
+	for task, err := range cli.ListPendingReview(context.Background(), params) {
+		if err != nil {
+			t.Fatalf("ListPendingReview failed: %v", err)
+		}
+		ids = append(ids, task.Properties.TaskingRequestID)
+	}
+	if len(ids) != 2 || ids[0] != "tr-1" || ids[1] != "tr-2" {
+		t.Errorf("unexpected tasks %v", ids)
+	}
+}
+
+func TestGetReviewDetail(t *testing.T) {
+	windowOpen := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	quoteExpiry := time.Date(2025, 3, 8, 12, 0, 0, 0, time.UTC)
+	enteredReview := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/task/tr-123":
+			resp := capella.TaskingRequestResponse{
+				Properties: capella.TaskingRequestPropertiesResponse{
+					TaskingRequestID: "tr-123",
+					Status:           capella.TaskReview,
+				},
+				StatusHistory: []capella.StatusEntry{
+					{Time: enteredReview, Code: capella.TaskReview},
+					{Time: enteredReview.Add(-time.Minute), Code: capella.TaskReceived},
+				},
+			}
+			resp.Properties.UserID = "user-7"
+			resp.Properties.OrgID = "org-1"
+			resp.Properties.WindowOpen = windowOpen
+			jsonResponse(w, http.StatusOK, resp)
+		case "/task/tr-123/cost":
+			cost := testTaskCost
+			cost.ExpiresAt = &quoteExpiry
+			jsonResponse(w, http.StatusOK, cost)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	detail, err := cli.GetReviewDetail(context.Background(), "tr-123")
+	if err != nil {
+		t.Fatalf("GetReviewDetail failed: %v", err)
+	}
+	if detail.RequesterID != "user-7" || detail.OrganizationID != "org-1" {
+		t.Errorf("unexpected requester %s/%s", detail.OrganizationID, detail.RequesterID)
+	}
+	if detail.Cost == nil || detail.Cost.Total != testTaskCost.Total {
+		t.Errorf("unexpected cost %+v", detail.Cost)
+	}
+	if !detail.InReviewSince.Equal(enteredReview) {
+		t.Errorf("expected in review since %s, got %s", enteredReview, detail.InReviewSince)
+	}
+	if !detail.Deadline.Equal(quoteExpiry) {
+		t.Errorf("expected deadline %s, got %s", quoteExpiry, detail.Deadline)
+	}
+}
+
+func TestRejectTask(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPatch)
+		requirePath(t, r, "/task/tr-123")
+
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if body["status"] != "rejected" || body["reason"] != "over quarterly budget" {
+			t.Errorf("unexpected body %v", body)
+		}
+
+		jsonResponse(w, http.StatusOK, capella.TaskingRequestResponse{
+			Properties: capella.TaskingRequestPropertiesResponse{
+				TaskingRequestID: "tr-123",
+				Status:           capella.TaskRejected,
+			},
+		})
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	resp, err := cli.RejectTask(context.Background(), "tr-123", "over quarterly budget")
+	if err != nil {
+		t.Fatalf("RejectTask failed: %v", err)
+	}
+	if resp.Properties.Status != capella.TaskRejected {
+		t.Errorf("expected status 'rejected', got %q", resp.Properties.Status)
+	}
+}
+
+func TestReviewDecision_NotReviewable(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, http.StatusConflict, map[string]string{"message": "task is not in review"})
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	tests := []struct {
+		name     string
+		decide   func() error
+		decision capella.TaskStatus
+	}{
+		{
+			name: "approve",
+			decide: func() error {
+				_, err := cli.ApproveTask(context.Background(), "tr-123")
+				return err
+			},
+			decision: capella.TaskApproved,
+		},
+		{
+			name: "reject",
+			decide: func() error {
+				_, err := cli.RejectTask(context.Background(), "tr-123", "duplicate")
+				return err
+			},
+			decision: capella.TaskRejected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.decide()
+			var notReviewable *capella.NotReviewableError
+			if !errors.As(err, &notReviewable) {
+				t.Fatalf("expected *NotReviewableError, got %v", err)
+			}
+			if notReviewable.TaskingRequestID != "tr-123" || notReviewable.Decision != tt.decision {
+				t.Errorf("unexpected error fields %+v", notReviewable)
+			}
+			var apiErr *capella.APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+				t.Errorf("expected the 409 APIError to be wrapped, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRejectTask_OtherErrorsPassThrough(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, http.StatusNotFound, map[string]string{"message": "no such task"})
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	_, err := cli.RejectTask(context.Background(), "tr-404", "duplicate")
+	var notReviewable *capella.NotReviewableError
+	if errors.As(err, &notReviewable) {
+		t.Fatalf("expected a plain API error, got %v", err)
+	}
+	if !capella.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
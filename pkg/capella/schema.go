@@ -0,0 +1,224 @@
+package capella
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// APIVersionHeader is the header the client sends the requested API version
+// in (see WithAPIVersion) and reads the served version from.
+const APIVersionHeader = "Api-Version"
+
+// ----------------------------------------------------------------------------
+// API Version and Schema Guard
+// ----------------------------------------------------------------------------
+
+// WithAPIVersion requests API version v on every call, via APIVersionHeader.
+func WithAPIVersion(v string) Option {
+	return func(c *clientConfig) {
+		c.apiVersion = v
+	}
+}
+
+// WithSchemaWarnings calls handler when a decoded response does not match
+// the type it is decoded into: the response has top-level fields the type
+// does not declare, which the SDK would silently drop, or lacks fields the
+// type declares as required (those without omitempty). Responses still
+// decode as before; the warnings flag schema changes early.
+//
+// handler may be called concurrently from calls made in parallel.
+func WithSchemaWarnings(handler func(WarningEvent)) Option {
+	return func(c *clientConfig) {
+		c.schemaWarnings = handler
+	}
+}
+
+// WarningKind classifies a WarningEvent.
+type WarningKind string
+
+const (
+	WarningUnknownField WarningKind = "unknown_field"
+	WarningMissingField WarningKind = "missing_field"
+)
+
+// WarningEvent reports a response that does not match its Go type.
+type WarningEvent struct {
+	Kind   WarningKind
+	Method string
+	Path   string
+	// Field is the JSON name of the unknown or missing field.
+	Field string
+	// Type is the Go type the response was decoded into.
+	Type string
+	// APIVersion is the API version last reported by the server, or "".
+	APIVersion string
+}
+
+func (e WarningEvent) String() string {
+	what := "unknown field"
+	if e.Kind == WarningMissingField {
+		what = "missing field"
+	}
+	return fmt.Sprintf("%s %s: %s %q in %s", e.Method, e.Path, what, e.Field, e.Type)
+}
+
+// APIVersion returns the API version reported by the last response that
+// carried APIVersionHeader, or "" if none has.
+func (c *Client) APIVersion() string {
+	if v := c.servedVersion.Load(); v != nil {
+		return *v
+	}
+	return ""
+}
+
+// versionTransport sends the requested API version to the API hosts and
+// records the version the API reports.
+type versionTransport struct {
+	base    http.RoundTripper
+	hosts   []string
+	version string
+	served  *atomic.Pointer[string]
+}
+
+func (t *versionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	api := slices.Contains(t.hosts, req.URL.Host)
+	if api && t.version != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(APIVersionHeader, t.version)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && api {
+		if v := resp.Header.Get(APIVersionHeader); v != "" {
+			t.served.Store(&v)
+		}
+	}
+	return resp, err
+}
+
+// withVersionTransport returns a copy of client whose transport is wrapped
+// in a versionTransport.
+func withVersionTransport(client *http.Client, hosts []string, version string, served *atomic.Pointer[string]) *http.Client {
+	rt := client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	c := *client
+	c.Transport = &versionTransport{base: rt, hosts: hosts, version: version, served: served}
+	return &c
+}
+
+// guarded reports whether a response decoded into respBody is checked for
+// schema mismatches.
+func (c *Client) guarded(respBody any) bool {
+	if c.schemaWarnings == nil || respBody == nil {
+		return false
+	}
+	_, raw := respBody.(*[]byte)
+	return !raw
+}
+
+// decodeGuarded decodes the response body data of the call method u into v
+// and reports schema mismatches to the WithSchemaWarnings handler.
+func (c *Client) decodeGuarded(method string, u *url.URL, data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var objects []map[string]json.RawMessage
+	switch t.Kind() {
+	case reflect.Struct:
+		var obj map[string]json.RawMessage
+		if json.Unmarshal(data, &obj) == nil {
+			objects = append(objects, obj)
+		}
+	case reflect.Slice:
+		t = t.Elem()
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		json.Unmarshal(data, &objects)
+	}
+	if t.Kind() != reflect.Struct || len(objects) == 0 || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	known, required := schemaFields(t)
+	event := WarningEvent{
+		Method:     method,
+		Path:       "/" + strings.TrimPrefix(u.Path, "/"),
+		Type:       t.String(),
+		APIVersion: c.APIVersion(),
+	}
+	// Field names match case-insensitively, as in encoding/json.
+	var unknown, missing []string
+	for _, obj := range objects {
+		for name := range obj {
+			if !containsFold(known, name) && !slices.Contains(unknown, name) {
+				unknown = append(unknown, name)
+			}
+		}
+		present := slices.Collect(maps.Keys(obj))
+		for _, name := range required {
+			if !containsFold(present, name) && !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+		}
+	}
+	slices.Sort(unknown)
+	for _, name := range unknown {
+		event.Kind, event.Field = WarningUnknownField, name
+		c.schemaWarnings(event)
+	}
+	for _, name := range missing {
+		event.Kind, event.Field = WarningMissingField, name
+		c.schemaWarnings(event)
+	}
+	return nil
+}
+
+var unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+func containsFold(names []string, name string) bool {
+	return slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, name) })
+}
+
+// schemaFields returns the JSON names of the fields of struct type t,
+// including promoted fields, and those without omitempty or omitzero.
+func schemaFields(t reflect.Type) (known, required []string) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			k, r := schemaFields(ft)
+			known, required = append(known, k...), append(required, r...)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		known = append(known, name)
+		if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") {
+			required = append(required, name)
+		}
+	}
+	return known, required
+}
@@ -0,0 +1,150 @@
+package capella_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+func newSchemaClient(t *testing.T, handler http.HandlerFunc, opts ...capella.Option) *capella.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	cli, err := capella.NewClient(append([]capella.Option{
+		capella.WithBaseURL(srv.URL),
+		capella.WithAPIKey("test-api-key"),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return cli
+}
+
+// accessRequestBody returns an access request response whose ID uses the
+// given property name.
+func accessRequestBody(idField string) map[string]any {
+	return map[string]any{
+		"type":     "Feature",
+		"geometry": map[string]any{"type": "Point", "coordinates": []float64{10, 20}},
+		"properties": map[string]any{
+			idField:               "ar-123",
+			"processingStatus":    "completed",
+			"accessibilityStatus": "accessible",
+		},
+	}
+}
+
+func TestAccessRequestIDCasing(t *testing.T) {
+	for _, field := range []string{"accessrequestId", "accessRequestId"} {
+		t.Run(field, func(t *testing.T) {
+			cli := newSchemaClient(t, func(w http.ResponseWriter, r *http.Request) {
+				jsonResponse(w, http.StatusOK, accessRequestBody(field))
+			})
+			resp, err := cli.GetAccessRequest(context.Background(), "ar-123")
+			if err != nil {
+				t.Fatalf("GetAccessRequest() error = %v", err)
+			}
+			if resp.Properties.AccessRequestID != "ar-123" {
+				t.Errorf("AccessRequestID = %q, want ar-123", resp.Properties.AccessRequestID)
+			}
+			if resp.Properties.ProcessingStatus != capella.ProcessingCompleted {
+				t.Errorf("ProcessingStatus = %q, want completed", resp.Properties.ProcessingStatus)
+			}
+		})
+	}
+}
+
+func TestSchemaWarnings(t *testing.T) {
+	body := accessRequestBody("accessRequestId")
+	body["links"] = []any{}
+	body["Geometry"] = body["geometry"] // same field, other casing
+	delete(body, "geometry")
+	delete(body, "type")
+
+	var mu sync.Mutex
+	var events []capella.WarningEvent
+	cli := newSchemaClient(t, func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, http.StatusOK, body)
+	}, capella.WithSchemaWarnings(func(e capella.WarningEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}))
+
+	resp, err := cli.GetAccessRequest(context.Background(), "ar-123")
+	if err != nil {
+		t.Fatalf("GetAccessRequest() error = %v", err)
+	}
+	if resp.Geometry == nil || resp.Properties.AccessRequestID != "ar-123" {
+		t.Errorf("warnings must not change decoding, got %+v", resp)
+	}
+
+	want := []capella.WarningEvent{
+		{Kind: capella.WarningUnknownField, Field: "links"},
+		{Kind: capella.WarningMissingField, Field: "type"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d warnings, got %v", len(want), events)
+	}
+	for i, e := range events {
+		if e.Kind != want[i].Kind || e.Field != want[i].Field {
+			t.Errorf("warning %d = %s %s, want %s %s", i, e.Kind, e.Field, want[i].Kind, want[i].Field)
+		}
+		if e.Method != http.MethodGet || e.Path != "/ma/accessrequests/ar-123" || e.Type != "capella.AccessRequestResponse" {
+			t.Errorf("warning %d has wrong call details: %+v", i, e)
+		}
+	}
+}
+
+func TestSchemaWarnings_ListResponse(t *testing.T) {
+	var events []capella.WarningEvent
+	cli := newSchemaClient(t, func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, http.StatusOK, []map[string]any{
+			{"id": "key-1", "name": "ci", "scope": "read"},
+			{"id": "key-2", "name": "ops", "scope": "read"},
+		})
+	}, capella.WithSchemaWarnings(func(e capella.WarningEvent) {
+		events = append(events, e)
+	}))
+
+	if _, err := cli.ListAPIKeys(context.Background()); err != nil {
+		t.Fatalf("ListAPIKeys() error = %v", err)
+	}
+	var unknown []string
+	for _, e := range events {
+		if e.Kind == capella.WarningUnknownField {
+			unknown = append(unknown, e.Field)
+		}
+	}
+	if len(unknown) != 1 || unknown[0] != "scope" {
+		t.Errorf("expected one unknown field warning for scope, got %v", events)
+	}
+}
+
+func TestAPIVersion(t *testing.T) {
+	cli := newSchemaClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get(capella.APIVersionHeader); got != "2024-01" {
+			t.Errorf("%s = %q, want 2024-01", capella.APIVersionHeader, got)
+		}
+		if got := r.Header.Get("Accept"); got != "application/json" {
+			t.Errorf("Accept = %q, want application/json", got)
+		}
+		w.Header().Set(capella.APIVersionHeader, "2024-03")
+		jsonResponse(w, http.StatusOK, accessRequestBody("accessrequestId"))
+	}, capella.WithAPIVersion("2024-01"))
+
+	if v := cli.APIVersion(); v != "" {
+		t.Errorf("APIVersion() = %q before any call, want empty", v)
+	}
+	if _, err := cli.GetAccessRequest(context.Background(), "ar-123"); err != nil {
+		t.Fatalf("GetAccessRequest() error = %v", err)
+	}
+	if v := cli.APIVersion(); v != "2024-03" {
+		t.Errorf("APIVersion() = %q, want 2024-03", v)
+	}
+}
@@ -0,0 +1,358 @@
+package capella
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/paulmach/orb"
+)
+
+// StaticSTACVersion is the STAC version written for catalogs, and for items
+// and collections that do not carry their own.
+const StaticSTACVersion = "1.0.0"
+
+const (
+	staticCatalogFile    = "catalog.json"
+	staticCollectionFile = "collection.json"
+
+	mediaTypeJSON    = "application/json"
+	mediaTypeGeoJSON = "application/geo+json"
+)
+
+// ----------------------------------------------------------------------------
+// Static STAC Catalog
+// ----------------------------------------------------------------------------
+
+// StaticCatalogOptions configures WriteStaticCatalog.
+type StaticCatalogOptions struct {
+	// ID, Title and Description of the root catalog. ID defaults to
+	// "capella" and Description to a generic one.
+	ID          string
+	Title       string
+	Description string
+
+	// Collections holds collection metadata, e.g. from ListCollections.
+	// Collections of the written items that are not listed are synthesized,
+	// with an extent covering their items.
+	Collections []STACCollection
+
+	// BaseURL is the URL the catalog will be published at. When set, every
+	// file gets an absolute self link below it; otherwise the catalog is
+	// self-contained and has relative links only.
+	BaseURL string
+
+	// AssetPath returns the local file an asset was downloaded to, or "" to
+	// keep the asset's remote href. Local assets are linked relative to the
+	// item file.
+	AssetPath func(item STACItem, assetKey string) string
+}
+
+// StaticCatalogSummary reports what WriteStaticCatalog wrote.
+type StaticCatalogSummary struct {
+	// Items is the number of item files written.
+	Items int
+	// Collections maps the ID of each collection written to its number of
+	// items. Items without a collection are not counted here.
+	Collections map[string]int
+}
+
+// staticCatalog is the root catalog document.
+type staticCatalog struct {
+	Type        string `json:"type"`
+	StacVersion string `json:"stac_version"`
+	ID          string `json:"id"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description"`
+	Links       []Link `json:"links"`
+}
+
+// staticCollection accumulates the items of one collection as they are
+// written, so its collection.json can be written afterwards.
+type staticCollection struct {
+	items      map[string]bool
+	bound      orb.Bound
+	hasBound   bool
+	start, end time.Time
+}
+
+// WriteStaticCatalog writes items to rootDir as a self-contained static STAC
+// catalog, following the STAC best-practice layout:
+//
+//	catalog.json
+//	<collection>/collection.json
+//	<collection>/<item>/<item>.json
+//
+// Items without a collection are placed directly below the root catalog as
+// <item>/<item>.json. The root, parent and collection links of every file
+// are rewritten relative to it; other links and asset hrefs stay absolute
+// unless opts.AssetPath localizes an asset.
+//
+// Items are written as they arrive; only their IDs and extents are kept
+// until the catalog and collection files are written at the end. Paths
+// depend only on IDs and links are sorted, so writing the same items again
+// produces identical files. Existing files at these paths are replaced and
+// other files are left alone. An item seen twice is written once.
+func WriteStaticCatalog(items iter.Seq2[STACItem, error], rootDir string, opts StaticCatalogOptions) (*StaticCatalogSummary, error) {
+	var assetRoot string
+	if opts.AssetPath != nil {
+		var err error
+		if assetRoot, err = filepath.Abs(rootDir); err != nil {
+			return nil, err
+		}
+	}
+
+	collections := make(map[string]*staticCollection)
+	loose := make(map[string]bool)
+	for item, err := range items {
+		if err != nil {
+			return nil, err
+		}
+		if err := checkStaticID("item", item.ID); err != nil {
+			return nil, err
+		}
+		seen := loose
+		if item.Collection != "" {
+			if err := checkStaticID("collection", item.Collection); err != nil {
+				return nil, err
+			}
+			col := collections[item.Collection]
+			if col == nil {
+				col = &staticCollection{items: make(map[string]bool)}
+				collections[item.Collection] = col
+			}
+			col.add(item)
+			seen = col.items
+		}
+		if err := writeStaticItem(rootDir, assetRoot, item, opts); err != nil {
+			return nil, err
+		}
+		seen[item.ID] = true
+	}
+
+	summary := &StaticCatalogSummary{Items: len(loose), Collections: make(map[string]int, len(collections))}
+	metadata := make(map[string]STACCollection, len(opts.Collections))
+	for _, c := range opts.Collections {
+		metadata[c.ID] = c
+	}
+	for id, col := range collections {
+		if err := writeStaticCollection(rootDir, id, col, metadata, opts.BaseURL); err != nil {
+			return nil, err
+		}
+		summary.Items += len(col.items)
+		summary.Collections[id] = len(col.items)
+	}
+
+	cat := staticCatalog{
+		Type:        "Catalog",
+		StacVersion: StaticSTACVersion,
+		ID:          opts.ID,
+		Title:       opts.Title,
+		Description: opts.Description,
+	}
+	if cat.ID == "" {
+		cat.ID = "capella"
+	}
+	if cat.Description == "" {
+		cat.Description = "Capella Space SAR items"
+	}
+	cat.Links = append(cat.Links, Link{Rel: "root", Href: "./" + staticCatalogFile, Type: mediaTypeJSON})
+	cat.Links = appendSelfLink(cat.Links, opts.BaseURL, staticCatalogFile, mediaTypeJSON)
+	for _, id := range slices.Sorted(maps.Keys(collections)) {
+		cat.Links = append(cat.Links, Link{Rel: "child", Href: "./" + path.Join(id, staticCollectionFile), Type: mediaTypeJSON})
+	}
+	for _, id := range slices.Sorted(maps.Keys(loose)) {
+		cat.Links = append(cat.Links, Link{Rel: "item", Href: "./" + path.Join(id, id+".json"), Type: mediaTypeGeoJSON})
+	}
+	if err := writeStaticJSON(filepath.Join(rootDir, staticCatalogFile), cat); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// checkStaticID returns an error for an ID that cannot be used as a
+// directory name.
+func checkStaticID(kind, id string) error {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return fmt.Errorf("%s ID %q cannot be used as a file name", kind, id)
+	}
+	return nil
+}
+
+// add extends the collection's extent by item.
+func (col *staticCollection) add(item STACItem) {
+	var b orb.Bound
+	switch {
+	case len(item.BBox) >= 4:
+		n := len(item.BBox) / 2
+		b = orb.Bound{Min: orb.Point{item.BBox[0], item.BBox[1]}, Max: orb.Point{item.BBox[n], item.BBox[n+1]}}
+	case item.Geometry != nil && item.Geometry.Geometry() != nil:
+		b = item.Geometry.Geometry().Bound()
+	}
+	if b != (orb.Bound{}) {
+		if col.hasBound {
+			b = col.bound.Union(b)
+		}
+		col.bound, col.hasBound = b, true
+	}
+
+	p := item.Properties
+	start, end := p.StartDateTime, p.EndDateTime
+	if start.IsZero() {
+		start = p.DateTime
+	}
+	if end.IsZero() {
+		end = p.DateTime
+	}
+	if !start.IsZero() && (col.start.IsZero() || start.Before(col.start)) {
+		col.start = start
+	}
+	if !end.IsZero() && end.After(col.end) {
+		col.end = end
+	}
+}
+
+// structuralRels are the link relations WriteStaticCatalog rewrites; links
+// with any other relation are kept as they are.
+var structuralRels = []string{"self", "root", "parent", "collection", "child", "item", "items"}
+
+// keepLinks returns the links whose relation is not structural.
+func keepLinks(links []Link) []Link {
+	var out []Link
+	for _, l := range links {
+		if !slices.Contains(structuralRels, l.Rel) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// appendSelfLink appends an absolute self link for the file at rel below
+// baseURL, or nothing if baseURL is empty.
+func appendSelfLink(links []Link, baseURL, rel, mediaType string) []Link {
+	if baseURL == "" {
+		return links
+	}
+	return append(links, Link{Rel: "self", Href: strings.TrimSuffix(baseURL, "/") + "/" + rel, Type: mediaType})
+}
+
+func writeStaticItem(rootDir, assetRoot string, item STACItem, opts StaticCatalogOptions) error {
+	dir := item.ID
+	up := "../"
+	if item.Collection != "" {
+		dir = path.Join(item.Collection, item.ID)
+		up = "../../"
+	}
+	rel := path.Join(dir, item.ID+".json")
+
+	out := item
+	if out.Type == "" {
+		out.Type = "Feature"
+	}
+	if out.StacVersion == "" {
+		out.StacVersion = StaticSTACVersion
+	}
+	out.Links = keepLinks(item.Links)
+	out.Links = append(out.Links, Link{Rel: "root", Href: up + staticCatalogFile, Type: mediaTypeJSON})
+	if item.Collection != "" {
+		out.Links = append(out.Links,
+			Link{Rel: "parent", Href: "../" + staticCollectionFile, Type: mediaTypeJSON},
+			Link{Rel: "collection", Href: "../" + staticCollectionFile, Type: mediaTypeJSON})
+	} else {
+		out.Links = append(out.Links, Link{Rel: "parent", Href: "../" + staticCatalogFile, Type: mediaTypeJSON})
+	}
+	out.Links = appendSelfLink(out.Links, opts.BaseURL, rel, mediaTypeGeoJSON)
+
+	if opts.AssetPath != nil && len(item.Assets) > 0 {
+		itemDir := filepath.Join(assetRoot, filepath.FromSlash(dir))
+		out.Assets = make(map[string]Asset, len(item.Assets))
+		for key, a := range item.Assets {
+			if local := opts.AssetPath(item, key); local != "" {
+				abs, err := filepath.Abs(local)
+				if err != nil {
+					return err
+				}
+				href, err := filepath.Rel(itemDir, abs)
+				if err != nil {
+					return fmt.Errorf("item %s asset %s: %w", item.ID, key, err)
+				}
+				a.Href = filepath.ToSlash(href)
+				if !strings.HasPrefix(a.Href, "../") {
+					a.Href = "./" + a.Href
+				}
+			}
+			out.Assets[key] = a
+		}
+	}
+	return writeStaticJSON(filepath.Join(rootDir, filepath.FromSlash(rel)), out)
+}
+
+func writeStaticCollection(rootDir, id string, col *staticCollection, metadata map[string]STACCollection, baseURL string) error {
+	out, ok := metadata[id]
+	if !ok {
+		out = STACCollection{
+			ID:          id,
+			Description: fmt.Sprintf("Capella Space SAR items of collection %s", id),
+			License:     "proprietary",
+			Providers:   []STACProvider{{Name: "Capella Space", Roles: []string{"producer", "licensor"}, URL: "https://www.capellaspace.com"}},
+			Extent:      col.extent(),
+		}
+	}
+	out.Type = "Collection"
+	if out.StacVersion == "" {
+		out.StacVersion = StaticSTACVersion
+	}
+	if out.License == "" {
+		out.License = "proprietary"
+	}
+	if len(out.Extent.Spatial.BBox) == 0 || len(out.Extent.Temporal.Interval) == 0 {
+		out.Extent = col.extent()
+	}
+
+	out.Links = keepLinks(out.Links)
+	out.Links = append(out.Links,
+		Link{Rel: "root", Href: "../" + staticCatalogFile, Type: mediaTypeJSON},
+		Link{Rel: "parent", Href: "../" + staticCatalogFile, Type: mediaTypeJSON})
+	out.Links = appendSelfLink(out.Links, baseURL, path.Join(id, staticCollectionFile), mediaTypeJSON)
+	for _, itemID := range slices.Sorted(maps.Keys(col.items)) {
+		out.Links = append(out.Links, Link{Rel: "item", Href: "./" + path.Join(itemID, itemID+".json"), Type: mediaTypeGeoJSON})
+	}
+	return writeStaticJSON(filepath.Join(rootDir, id, staticCollectionFile), out)
+}
+
+// extent returns the extent of the collection's items. The spatial extent
+// is the whole globe when no item has a bbox or geometry.
+func (col *staticCollection) extent() STACExtent {
+	bbox := []float64{-180, -90, 180, 90}
+	if col.hasBound {
+		bbox = []float64{col.bound.Min[0], col.bound.Min[1], col.bound.Max[0], col.bound.Max[1]}
+	}
+	interval := make([]string, 2)
+	for i, t := range []time.Time{col.start, col.end} {
+		if !t.IsZero() {
+			interval[i] = t.UTC().Format(time.RFC3339)
+		}
+	}
+	return STACExtent{
+		Spatial:  STACSpatialExtent{BBox: [][]float64{bbox}},
+		Temporal: STACTemporalExtent{Interval: [][]string{interval}},
+	}
+}
+
+func writeStaticJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(data, '\n'), 0644)
+}
@@ -0,0 +1,329 @@
+package capella_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"iter"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+// staticItems yields items followed by err, if any.
+func staticItems(items []capella.STACItem, err error) iter.Seq2[capella.STACItem, error] {
+	return func(yield func(capella.STACItem, error) bool) {
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(capella.STACItem{}, err)
+		}
+	}
+}
+
+func staticItem(id, collection string, lon float64, at time.Time) capella.STACItem {
+	return capella.STACItem{
+		ID:         id,
+		Type:       "Feature",
+		Collection: collection,
+		Geometry:   geojson.NewGeometry(orb.Point{lon, 37.8}),
+		BBox:       []float64{lon, 37.8, lon + 0.02, 37.82},
+		Properties: capella.STACProperties{DateTime: at, Platform: "capella-13"},
+		Links: []capella.Link{
+			{Rel: "self", Href: "https://api.capellaspace.com/catalog/collections/" + collection + "/items/" + id},
+			{Rel: "collection", Href: "https://api.capellaspace.com/catalog/collections/" + collection},
+			{Rel: "license", Href: "https://www.capellaspace.com/license"},
+		},
+		Assets: map[string]capella.Asset{
+			"HH": {Href: "https://api.capellaspace.com/catalog/assets/" + id + ".tif", Roles: []string{"data"}},
+		},
+	}
+}
+
+// stacDoc is the part of a STAC catalog, collection or item the tests check.
+type stacDoc struct {
+	Type        string                   `json:"type"`
+	StacVersion string                   `json:"stac_version"`
+	ID          string                   `json:"id"`
+	Collection  string                   `json:"collection"`
+	Links       []capella.Link           `json:"links"`
+	Assets      map[string]capella.Asset `json:"assets"`
+	Extent      capella.STACExtent       `json:"extent"`
+}
+
+// readTree returns the contents of every file below dir by slash path.
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		rel, _ := filepath.Rel(dir, path)
+		files[filepath.ToSlash(rel)] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func readDoc(t *testing.T, name string) stacDoc {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc stacDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return doc
+}
+
+// checkStaticLinks checks every document below root: each relative link
+// resolves to a document of the type its relation requires, and root links
+// point at the root catalog.
+func checkStaticLinks(t *testing.T, root string) {
+	t.Helper()
+	wantType := map[string][]string{
+		"root":       {"Catalog"},
+		"parent":     {"Catalog", "Collection"},
+		"collection": {"Collection"},
+		"child":      {"Catalog", "Collection"},
+		"item":       {"Feature"},
+	}
+	for name := range readTree(t, root) {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		doc := readDoc(t, file)
+		if doc.StacVersion == "" {
+			t.Errorf("%s has no stac_version", name)
+		}
+		for _, l := range doc.Links {
+			types, structural := wantType[l.Rel]
+			if !structural {
+				continue
+			}
+			if strings.Contains(l.Href, "://") {
+				t.Errorf("%s: %s link %s is not relative", name, l.Rel, l.Href)
+				continue
+			}
+			target := filepath.Join(filepath.Dir(file), filepath.FromSlash(l.Href))
+			linked := readDoc(t, target)
+			if !slices.Contains(types, linked.Type) {
+				t.Errorf("%s: %s link %s is a %s", name, l.Rel, l.Href, linked.Type)
+			}
+			if l.Rel == "root" && target != filepath.Join(root, "catalog.json") {
+				t.Errorf("%s: root link %s does not point at the root catalog", name, l.Href)
+			}
+			if l.Rel == "item" && !slices.ContainsFunc(linked.Links, func(back capella.Link) bool {
+				return back.Rel == "parent" && filepath.Join(filepath.Dir(target), filepath.FromSlash(back.Href)) == file
+			}) {
+				t.Errorf("%s: item %s does not link back to it as parent", name, l.Href)
+			}
+		}
+	}
+}
+
+func TestWriteStaticCatalog(t *testing.T) {
+	at := time.Date(2024, 6, 11, 9, 35, 20, 0, time.UTC)
+	items := []capella.STACItem{
+		staticItem("CAPELLA_C13_SP_GEO_HH_1", "capella-geo", -122.42, at),
+		staticItem("CAPELLA_C13_SP_GEO_HH_2", "capella-geo", -122.30, at.Add(48*time.Hour)),
+		staticItem("CAPELLA_C14_SP_SLC_HH_3", "capella-slc", 10, at),
+		staticItem("CAPELLA_C14_SP_SLC_HH_4", "", 11, at),
+	}
+	slc := capella.STACCollection{
+		ID:          "capella-slc",
+		Type:        "Collection",
+		Description: "Capella single look complex products",
+		License:     "CC-BY-4.0",
+		Extent: capella.STACExtent{
+			Spatial:  capella.STACSpatialExtent{BBox: [][]float64{{-180, -90, 180, 90}}},
+			Temporal: capella.STACTemporalExtent{Interval: [][]string{{"2020-01-01T00:00:00Z", ""}}},
+		},
+		Links: []capella.Link{
+			{Rel: "self", Href: "https://api.capellaspace.com/catalog/collections/capella-slc"},
+			{Rel: "license", Href: "https://creativecommons.org/licenses/by/4.0/"},
+		},
+	}
+
+	root := t.TempDir()
+	summary, err := capella.WriteStaticCatalog(staticItems(items, nil), root, capella.StaticCatalogOptions{
+		ID:          "archive",
+		Collections: []capella.STACCollection{slc},
+	})
+	if err != nil {
+		t.Fatalf("WriteStaticCatalog() error = %v", err)
+	}
+	if want := map[string]int{"capella-geo": 2, "capella-slc": 1}; summary.Items != 4 || !maps.Equal(summary.Collections, want) {
+		t.Errorf("summary = %+v, want 4 items in %v", summary, want)
+	}
+
+	want := []string{
+		"CAPELLA_C14_SP_SLC_HH_4/CAPELLA_C14_SP_SLC_HH_4.json",
+		"capella-geo/CAPELLA_C13_SP_GEO_HH_1/CAPELLA_C13_SP_GEO_HH_1.json",
+		"capella-geo/CAPELLA_C13_SP_GEO_HH_2/CAPELLA_C13_SP_GEO_HH_2.json",
+		"capella-geo/collection.json",
+		"capella-slc/CAPELLA_C14_SP_SLC_HH_3/CAPELLA_C14_SP_SLC_HH_3.json",
+		"capella-slc/collection.json",
+		"catalog.json",
+	}
+	if got := slices.Sorted(maps.Keys(readTree(t, root))); !slices.Equal(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	checkStaticLinks(t, root)
+
+	cat := readDoc(t, filepath.Join(root, "catalog.json"))
+	if cat.Type != "Catalog" || cat.ID != "archive" {
+		t.Errorf("catalog = %+v", cat)
+	}
+	var rels []string
+	for _, l := range cat.Links {
+		rels = append(rels, l.Rel+" "+l.Href)
+	}
+	wantRels := []string{
+		"root ./catalog.json",
+		"child ./capella-geo/collection.json",
+		"child ./capella-slc/collection.json",
+		"item ./CAPELLA_C14_SP_SLC_HH_4/CAPELLA_C14_SP_SLC_HH_4.json",
+	}
+	if !slices.Equal(rels, wantRels) {
+		t.Errorf("catalog links = %v, want %v", rels, wantRels)
+	}
+
+	// Item links to the API are replaced; others and asset hrefs are kept.
+	item := readDoc(t, filepath.Join(root, "capella-geo/CAPELLA_C13_SP_GEO_HH_1/CAPELLA_C13_SP_GEO_HH_1.json"))
+	for _, l := range item.Links {
+		if l.Rel == "self" || (strings.Contains(l.Href, "api.capellaspace.com") && l.Rel != "license") {
+			t.Errorf("item keeps API link %+v", l)
+		}
+	}
+	if !slices.ContainsFunc(item.Links, func(l capella.Link) bool { return l.Rel == "license" }) {
+		t.Error("item lost its license link")
+	}
+	if href := item.Assets["HH"].Href; href != items[0].Assets["HH"].Href {
+		t.Errorf("asset href = %s, want it left absolute", href)
+	}
+
+	// The missing collection is synthesized from its items; the listed one
+	// keeps its metadata.
+	geo := readDoc(t, filepath.Join(root, "capella-geo/collection.json"))
+	if bbox := geo.Extent.Spatial.BBox; len(bbox) != 1 || !slices.Equal(bbox[0], []float64{-122.42, 37.8, -122.28, 37.82}) {
+		t.Errorf("synthesized bbox = %v", bbox)
+	}
+	if iv := geo.Extent.Temporal.Interval; len(iv) != 1 || iv[0][0] != "2024-06-11T09:35:20Z" || iv[0][1] != "2024-06-13T09:35:20Z" {
+		t.Errorf("synthesized interval = %v", iv)
+	}
+	gotSLC := readDoc(t, filepath.Join(root, "capella-slc/collection.json"))
+	if !slices.EqualFunc(gotSLC.Extent.Temporal.Interval, slc.Extent.Temporal.Interval, slices.Equal) {
+		t.Errorf("listed collection extent = %+v, want %+v", gotSLC.Extent, slc.Extent)
+	}
+	var raw struct {
+		Extent struct {
+			Temporal struct {
+				Interval [][]*string `json:"interval"`
+			} `json:"temporal"`
+		} `json:"extent"`
+	}
+	if err := json.Unmarshal([]byte(readTree(t, root)["capella-slc/collection.json"]), &raw); err != nil {
+		t.Fatal(err)
+	}
+	if iv := raw.Extent.Temporal.Interval; len(iv) != 1 || len(iv[0]) != 2 || iv[0][0] == nil || iv[0][1] != nil {
+		t.Errorf("expected the open end of the interval to be null, got %v", iv)
+	}
+	if slices.ContainsFunc(gotSLC.Links, func(l capella.Link) bool { return l.Rel == "self" }) {
+		t.Error("listed collection keeps its API self link")
+	}
+
+	// Writing the same items again, in another order and with a duplicate,
+	// changes nothing.
+	before := readTree(t, root)
+	again := append(slices.Clone(items), items[0])
+	slices.Reverse(again)
+	if _, err := capella.WriteStaticCatalog(staticItems(again, nil), root, capella.StaticCatalogOptions{
+		ID:          "archive",
+		Collections: []capella.STACCollection{slc},
+	}); err != nil {
+		t.Fatalf("second WriteStaticCatalog() error = %v", err)
+	}
+	after := readTree(t, root)
+	for name, data := range after {
+		if before[name] != data {
+			t.Errorf("%s changed on re-run:\n%s\nwant:\n%s", name, data, before[name])
+		}
+	}
+	if len(after) != len(before) {
+		t.Errorf("re-run wrote %d files, want %d", len(after), len(before))
+	}
+}
+
+func TestWriteStaticCatalog_Published(t *testing.T) {
+	at := time.Date(2024, 6, 11, 9, 35, 20, 0, time.UTC)
+	item := staticItem("CAPELLA_C13_SP_GEO_HH_1", "capella-geo", -122.42, at)
+	root := t.TempDir()
+	local := filepath.Join(root, "capella-geo", item.ID, "HH.tif")
+
+	_, err := capella.WriteStaticCatalog(staticItems([]capella.STACItem{item}, nil), root, capella.StaticCatalogOptions{
+		BaseURL: "https://example.com/stac/",
+		AssetPath: func(it capella.STACItem, key string) string {
+			if key == "HH" {
+				return local
+			}
+			return ""
+		},
+	})
+	if err != nil {
+		t.Fatalf("WriteStaticCatalog() error = %v", err)
+	}
+
+	selfs := map[string]string{
+		"catalog.json":                "https://example.com/stac/catalog.json",
+		"capella-geo/collection.json": "https://example.com/stac/capella-geo/collection.json",
+		"capella-geo/CAPELLA_C13_SP_GEO_HH_1/CAPELLA_C13_SP_GEO_HH_1.json": "https://example.com/stac/capella-geo/CAPELLA_C13_SP_GEO_HH_1/CAPELLA_C13_SP_GEO_HH_1.json",
+	}
+	for name, want := range selfs {
+		doc := readDoc(t, filepath.Join(root, filepath.FromSlash(name)))
+		i := slices.IndexFunc(doc.Links, func(l capella.Link) bool { return l.Rel == "self" })
+		if i < 0 || doc.Links[i].Href != want {
+			t.Errorf("%s self link = %v, want %s", name, doc.Links, want)
+		}
+	}
+
+	doc := readDoc(t, filepath.Join(root, "capella-geo", item.ID, item.ID+".json"))
+	if href := doc.Assets["HH"].Href; href != "./HH.tif" {
+		t.Errorf("local asset href = %s, want ./HH.tif", href)
+	}
+}
+
+func TestWriteStaticCatalog_Errors(t *testing.T) {
+	at := time.Date(2024, 6, 11, 9, 35, 20, 0, time.UTC)
+	boom := errors.New("search failed")
+	if _, err := capella.WriteStaticCatalog(staticItems(nil, boom), t.TempDir(), capella.StaticCatalogOptions{}); !errors.Is(err, boom) {
+		t.Errorf("iterator error = %v, want %v", err, boom)
+	}
+
+	for _, item := range []capella.STACItem{
+		staticItem("../escape", "capella-geo", 0, at),
+		staticItem("CAPELLA_1", "..", 0, at),
+		staticItem("", "capella-geo", 0, at),
+	} {
+		root := t.TempDir()
+		if _, err := capella.WriteStaticCatalog(staticItems([]capella.STACItem{item}, nil), root, capella.StaticCatalogOptions{}); err == nil {
+			t.Errorf("item %q in collection %q was written", item.ID, item.Collection)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package capella
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// ----------------------------------------------------------------------------
+// Task and Wait
+// ----------------------------------------------------------------------------
+
+// DefaultTaskPollInterval is how often TaskAndWait polls the task status
+// when TaskAndWaitOptions.Wait sets no interval.
+const DefaultTaskPollInterval = time.Minute
+
+// ErrItemsNotIndexed is returned when a task has completed but the STAC
+// items of some of its collects are not in the catalog yet. The outcome
+// returned with it holds the final task; retry with FindOutcomeItems.
+var ErrItemsNotIndexed = errors.New("task completed but its items are not indexed yet")
+
+// TaskAndWaitOptions configures TaskAndWait. The zero value waits for a
+// manual review and searches the catalog once.
+type TaskAndWaitOptions struct {
+	// Wait is the polling policy of the task status. Timeout and
+	// MaxElapsed bound the whole lifecycle.
+	Wait WaitOptions
+
+	// MaxCost, when positive, approves a task in review if its quote is at
+	// most MaxCost in Currency, as ApproveTaskIfUnder does. A task over
+	// budget is left in review and TaskAndWait returns the
+	// *CostExceededError.
+	MaxCost  float64
+	Currency string
+
+	// IndexWait, when it sets an interval, polls the catalog until the
+	// items of every collect are indexed; otherwise it is searched once.
+	IndexWait WaitOptions
+
+	// OnStatus is called for every status change of the task, including the
+	// one of the submitted task.
+	OnStatus func(TaskStatusChange)
+}
+
+// TaskStatusChange is a status of the task observed while waiting.
+type TaskStatusChange struct {
+	Status           TaskStatus
+	ProcessingStatus ProcessingStatus
+	// At is when the change was observed, not when it happened.
+	At time.Time
+}
+
+// TaskOutcome is the result of TaskAndWait. It is returned with errors
+// after the task was submitted, so callers know the task to follow up on.
+type TaskOutcome struct {
+	// Task is the last response of the task.
+	Task *TaskingRequestResponse
+	// Timeline lists the status changes observed, oldest first.
+	Timeline []TaskStatusChange
+	// CollectIDs are the collects of the completed task.
+	CollectIDs []string
+	// Items are the catalog items of the collects.
+	Items []STACItem
+}
+
+// TaskAndWait submits req and follows it to delivery: it waits for the task
+// to be approved, collected and processed, then searches the catalog by
+// capella:collect_id for the items of the task's collects.
+//
+// A task that ends rejected, expired, canceled or failed returns an error.
+// When the items are not all indexed in time, the outcome is returned with
+// ErrItemsNotIndexed and FindOutcomeItems can be retried later. Outcomes of
+// submitted tasks are returned with every error.
+func (c *Client) TaskAndWait(ctx context.Context, req TaskingRequest, opts *TaskAndWaitOptions) (*TaskOutcome, error) {
+	if opts == nil {
+		opts = &TaskAndWaitOptions{}
+	}
+	wait := opts.Wait
+	if wait.PollInterval <= 0 && wait.InitialInterval <= 0 {
+		wait.PollInterval = DefaultTaskPollInterval
+	}
+
+	task, err := c.CreateTask(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	out := &TaskOutcome{Task: task}
+	out.observe(task, opts.OnStatus)
+	taskID := task.Properties.TaskingRequestID
+
+	approving := opts.MaxCost > 0
+	err = common.Poll(ctx, wait, pollStep(func(ctx context.Context) (bool, error) {
+		task, err := c.GetTask(ctx, taskID)
+		if err != nil {
+			return false, err
+		}
+		out.Task = task
+		out.observe(task, opts.OnStatus)
+
+		props := task.Properties
+		switch props.Status {
+		case TaskReview:
+			if !approving {
+				return false, nil
+			}
+			approved, err := c.ApproveTaskIfUnder(ctx, taskID, opts.MaxCost, opts.Currency)
+			if errors.Is(err, ErrQuotePending) {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			approving = false
+			out.Task = approved
+			out.observe(approved, opts.OnStatus)
+		case TaskRejected, TaskExpired, TaskCanceled, TaskError, TaskFailed:
+			return false, fmt.Errorf("task %s %s", taskID, props.Status)
+		case TaskCompleted:
+			switch props.ProcessingStatus {
+			case "", ProcessingCompleted:
+				return true, nil
+			case ProcessingError:
+				return false, fmt.Errorf("task %s: processing failed", taskID)
+			}
+		}
+		return false, nil
+	}))
+	if err != nil {
+		return out, err
+	}
+
+	return out, c.findOutcomeItems(ctx, out, opts.IndexWait)
+}
+
+// FindOutcomeItems searches the catalog again for the items of a completed
+// task's collects, e.g. after TaskAndWait returned ErrItemsNotIndexed. It
+// fills in out.CollectIDs and out.Items and returns ErrItemsNotIndexed while
+// items are still missing.
+func (c *Client) FindOutcomeItems(ctx context.Context, out *TaskOutcome) error {
+	return c.findOutcomeItems(ctx, out, WaitOptions{})
+}
+
+func (c *Client) findOutcomeItems(ctx context.Context, out *TaskOutcome, wait WaitOptions) error {
+	if out == nil || out.Task == nil {
+		return errors.New("outcome has no task")
+	}
+	find := func(ctx context.Context) (bool, error) {
+		if len(out.CollectIDs) == 0 {
+			collects, err := c.ListTaskCollects(ctx, out.Task.Properties.TaskingRequestID)
+			if err != nil {
+				return false, err
+			}
+			for _, col := range collects {
+				out.CollectIDs = append(out.CollectIDs, col.CollectID)
+			}
+			if len(out.CollectIDs) == 0 {
+				return false, nil
+			}
+		}
+		items, err := c.collectItems(ctx, out.CollectIDs)
+		if err != nil {
+			return false, err
+		}
+		out.Items = items
+		return indexed(out.CollectIDs, items), nil
+	}
+
+	if wait.PollInterval <= 0 && wait.InitialInterval <= 0 {
+		done, err := find(ctx)
+		if err != nil {
+			return err
+		}
+		if !done {
+			return ErrItemsNotIndexed
+		}
+		return nil
+	}
+	err := common.Poll(ctx, wait, pollStep(find))
+	if errors.Is(err, common.ErrWaitTimeout) {
+		return ErrItemsNotIndexed
+	}
+	return err
+}
+
+// collectItems returns the catalog items of the given collects. Collects
+// without items are left to indexed to report.
+func (c *Client) collectItems(ctx context.Context, collectIDs []string) ([]STACItem, error) {
+	var items []STACItem
+	for item, err := range c.GetItemsByCollectIDs(ctx, collectIDs) {
+		var unmatched *UnmatchedCollectsError
+		if errors.As(err, &unmatched) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// indexed reports whether items hold at least one item of every collect.
+func indexed(collectIDs []string, items []STACItem) bool {
+	found := make(map[string]bool, len(items))
+	for _, item := range items {
+		found[item.Properties.CollectID] = true
+	}
+	for _, id := range collectIDs {
+		if !found[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// observe records the status of task if it changed.
+func (o *TaskOutcome) observe(task *TaskingRequestResponse, onStatus func(TaskStatusChange)) {
+	change := TaskStatusChange{
+		Status:           task.Properties.Status,
+		ProcessingStatus: task.Properties.ProcessingStatus,
+		At:               time.Now(),
+	}
+	if n := len(o.Timeline); n > 0 {
+		last := o.Timeline[n-1]
+		if last.Status == change.Status && last.ProcessingStatus == change.ProcessingStatus {
+			return
+		}
+	}
+	o.Timeline = append(o.Timeline, change)
+	if onStatus != nil {
+		onStatus(change)
+	}
+}
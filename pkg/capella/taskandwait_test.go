@@ -0,0 +1,249 @@
+package capella_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+// lifecycleStep is the task status served by one GET of the scripted task.
+type lifecycleStep struct {
+	status     capella.TaskStatus
+	processing capella.ProcessingStatus
+}
+
+// lifecycleServer scripts a task through its lifecycle: each GET serves the
+// next step, holding at a review step until the task is approved. Catalog
+// searches find nothing until the indexAfter'th search.
+type lifecycleServer struct {
+	t          *testing.T
+	steps      []lifecycleStep
+	cost       capella.TaskCost
+	indexAfter int
+
+	mu       sync.Mutex
+	step     int
+	approved bool
+	searches int
+}
+
+func (s *lifecycleServer) task(st lifecycleStep) capella.TaskingRequestResponse {
+	return capella.TaskingRequestResponse{
+		Type: "Feature",
+		Properties: capella.TaskingRequestPropertiesResponse{
+			TaskingRequestID: "tr-1",
+			Status:           st.status,
+			ProcessingStatus: st.processing,
+		},
+	}
+}
+
+func (s *lifecycleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/task":
+		jsonResponse(w, http.StatusCreated, s.task(lifecycleStep{status: capella.TaskReceived}))
+	case r.Method == http.MethodGet && r.URL.Path == "/task/tr-1":
+		st := s.steps[s.step]
+		if st.status != capella.TaskReview || s.approved {
+			if s.step < len(s.steps)-1 {
+				s.step++
+			}
+		}
+		jsonResponse(w, http.StatusOK, s.task(st))
+	case r.Method == http.MethodGet && r.URL.Path == "/task/tr-1/cost":
+		jsonResponse(w, http.StatusOK, s.cost)
+	case r.Method == http.MethodPatch && r.URL.Path == "/task/tr-1":
+		s.approved = true
+		if s.steps[s.step].status == capella.TaskReview && s.step < len(s.steps)-1 {
+			s.step++
+		}
+		jsonResponse(w, http.StatusOK, s.task(lifecycleStep{status: capella.TaskApproved}))
+	case r.Method == http.MethodGet && r.URL.Path == "/collects/list/tr-1":
+		jsonResponse(w, http.StatusOK, []capella.Collect{{CollectID: "col-1"}, {CollectID: "col-2"}})
+	case r.Method == http.MethodPost && r.URL.Path == "/catalog/search":
+		var params capella.SearchParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			s.t.Errorf("decode search: %v", err)
+		}
+		filter, _ := params.Query["capella:collect_id"].(map[string]any)
+		if ids, _ := filter["in"].([]any); len(ids) != 2 || ids[0] != "col-1" || ids[1] != "col-2" {
+			s.t.Errorf("search query = %v, want capella:collect_id in [col-1 col-2]", params.Query)
+		}
+		s.searches++
+		resp := capella.SearchResponse{Type: "FeatureCollection"}
+		if s.searches >= s.indexAfter {
+			for _, id := range []string{"col-1", "col-2"} {
+				resp.Features = append(resp.Features, capella.STACItem{
+					ID:         "item-" + id,
+					Type:       "Feature",
+					Properties: capella.STACProperties{CollectID: id},
+				})
+			}
+		}
+		jsonResponse(w, http.StatusOK, resp)
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+var fullLifecycle = []lifecycleStep{
+	{status: capella.TaskReceived},
+	{status: capella.TaskReview},
+	{status: capella.TaskApproved},
+	{status: capella.TaskActive},
+	{status: capella.TaskCompleted, processing: capella.ProcessingQueued},
+	{status: capella.TaskCompleted, processing: capella.ProcessingProcessing},
+	{status: capella.TaskCompleted, processing: capella.ProcessingCompleted},
+}
+
+func fastWait() capella.WaitOptions {
+	return capella.WaitOptions{PollInterval: time.Millisecond, Timeout: 5 * time.Second}
+}
+
+func TestTaskAndWait(t *testing.T) {
+	srv := &lifecycleServer{
+		t:          t,
+		steps:      fullLifecycle,
+		cost:       capella.TaskCost{TaskingRequestID: "tr-1", Total: 900, Currency: "USD"},
+		indexAfter: 1,
+	}
+	cli, _ := newTestClient(t, srv.ServeHTTP)
+
+	var seen []capella.TaskStatus
+	out, err := cli.TaskAndWait(context.Background(), capella.TaskingRequest{}, &capella.TaskAndWaitOptions{
+		Wait:     fastWait(),
+		MaxCost:  1000,
+		Currency: "USD",
+		OnStatus: func(c capella.TaskStatusChange) { seen = append(seen, c.Status) },
+	})
+	if err != nil {
+		t.Fatalf("TaskAndWait: %v", err)
+	}
+	if !srv.approved {
+		t.Error("task under budget was not approved")
+	}
+	if out.Task.Properties.Status != capella.TaskCompleted || out.Task.Properties.ProcessingStatus != capella.ProcessingCompleted {
+		t.Errorf("final task = %+v", out.Task.Properties)
+	}
+	if !slices.Equal(out.CollectIDs, []string{"col-1", "col-2"}) {
+		t.Errorf("collect IDs = %v", out.CollectIDs)
+	}
+	if len(out.Items) != 2 || out.Items[0].ID != "item-col-1" {
+		t.Errorf("items = %+v", out.Items)
+	}
+
+	// Repeated statuses are recorded once; the approval shows up as soon
+	// as the task is approved.
+	want := []capella.TaskStatus{
+		capella.TaskReceived, capella.TaskReview, capella.TaskApproved, capella.TaskActive,
+		capella.TaskCompleted, capella.TaskCompleted, capella.TaskCompleted,
+	}
+	if !slices.Equal(seen, want) {
+		t.Errorf("status callbacks = %v, want %v", seen, want)
+	}
+	if len(out.Timeline) != len(want) {
+		t.Fatalf("timeline has %d entries, want %d", len(out.Timeline), len(want))
+	}
+	for i := 1; i < len(out.Timeline); i++ {
+		if out.Timeline[i].At.Before(out.Timeline[i-1].At) {
+			t.Errorf("timeline is not in order at %d", i)
+		}
+	}
+}
+
+func TestTaskAndWaitItemsNotIndexed(t *testing.T) {
+	srv := &lifecycleServer{
+		t:          t,
+		steps:      []lifecycleStep{{status: capella.TaskActive}, {status: capella.TaskCompleted, processing: capella.ProcessingCompleted}},
+		indexAfter: 2,
+	}
+	cli, _ := newTestClient(t, srv.ServeHTTP)
+	ctx := context.Background()
+
+	out, err := cli.TaskAndWait(ctx, capella.TaskingRequest{}, &capella.TaskAndWaitOptions{Wait: fastWait()})
+	if !errors.Is(err, capella.ErrItemsNotIndexed) {
+		t.Fatalf("err = %v, want ErrItemsNotIndexed", err)
+	}
+	if out == nil || out.Task.Properties.Status != capella.TaskCompleted || len(out.Items) != 0 {
+		t.Fatalf("partial outcome = %+v", out)
+	}
+
+	if err := cli.FindOutcomeItems(ctx, out); err != nil {
+		t.Fatalf("FindOutcomeItems: %v", err)
+	}
+	if len(out.Items) != 2 {
+		t.Errorf("items after retry = %+v", out.Items)
+	}
+}
+
+func TestTaskAndWaitIndexWait(t *testing.T) {
+	srv := &lifecycleServer{
+		t:          t,
+		steps:      []lifecycleStep{{status: capella.TaskCompleted}},
+		indexAfter: 3,
+	}
+	cli, _ := newTestClient(t, srv.ServeHTTP)
+
+	out, err := cli.TaskAndWait(context.Background(), capella.TaskingRequest{}, &capella.TaskAndWaitOptions{
+		Wait:      fastWait(),
+		IndexWait: fastWait(),
+	})
+	if err != nil {
+		t.Fatalf("TaskAndWait: %v", err)
+	}
+	if len(out.Items) != 2 || srv.searches != 3 {
+		t.Errorf("got %d items after %d searches", len(out.Items), srv.searches)
+	}
+}
+
+func TestTaskAndWaitOverBudget(t *testing.T) {
+	srv := &lifecycleServer{
+		t:     t,
+		steps: []lifecycleStep{{status: capella.TaskReview}},
+		cost:  capella.TaskCost{TaskingRequestID: "tr-1", Total: 5000, Currency: "USD"},
+	}
+	cli, _ := newTestClient(t, srv.ServeHTTP)
+
+	out, err := cli.TaskAndWait(context.Background(), capella.TaskingRequest{}, &capella.TaskAndWaitOptions{
+		Wait:     fastWait(),
+		MaxCost:  1000,
+		Currency: "USD",
+	})
+	var ce *capella.CostExceededError
+	if !errors.As(err, &ce) {
+		t.Fatalf("err = %v, want *CostExceededError", err)
+	}
+	if srv.approved {
+		t.Error("task over budget was approved")
+	}
+	if out == nil || out.Task.Properties.Status != capella.TaskReview {
+		t.Errorf("outcome = %+v, want the task in review", out)
+	}
+}
+
+func TestTaskAndWaitTaskFailed(t *testing.T) {
+	srv := &lifecycleServer{
+		t:     t,
+		steps: []lifecycleStep{{status: capella.TaskActive}, {status: capella.TaskExpired}},
+	}
+	cli, _ := newTestClient(t, srv.ServeHTTP)
+
+	out, err := cli.TaskAndWait(context.Background(), capella.TaskingRequest{}, &capella.TaskAndWaitOptions{Wait: fastWait()})
+	if err == nil || errors.Is(err, capella.ErrItemsNotIndexed) {
+		t.Fatalf("err = %v, want the task failure", err)
+	}
+	if out == nil || out.Task.Properties.Status != capella.TaskExpired {
+		t.Errorf("outcome = %+v, want the expired task", out)
+	}
+}
@@ -1,11 +1,17 @@
 package capella
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"iter"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/paulmach/orb/geojson"
@@ -135,15 +141,33 @@ type TaskingRequestsPagedResponse struct {
 // CRUD Operations
 // ----------------------------------------------------------------------------
 
-// CreateTask submits a new tasking request.
+// CreateTask submits a new tasking request. With WithQuotaTracker, it fails
+// with the tracker's error, e.g. a *QuotaExhaustedError, when the task's
+// tier has no quota left.
 func (c *Client) CreateTask(ctx context.Context, req TaskingRequest) (*TaskingRequestResponse, error) {
+	if err := c.confirmProductionWrite("create task"); err != nil {
+		return nil, err
+	}
+
 	// Set default type if not specified
 	if req.Type == "" {
 		req.Type = "Feature"
 	}
 
+	// With a quota tracker, the task's tier is reserved until the outcome
+	// of the request is known.
+	release := func(bool) {}
+	if c.quota != nil {
+		var err error
+		if release, err = c.quota.Reserve(ctx, req.Properties.CollectionTier); err != nil {
+			return nil, err
+		}
+	}
+
 	var resp TaskingRequestResponse
-	if err := c.Do(ctx, http.MethodPost, "/task", 0, req, &resp); err != nil {
+	err := c.Do(ctx, http.MethodPost, "/task", 0, req, &resp)
+	release(err == nil)
+	if err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -158,11 +182,22 @@ func (c *Client) GetTask(ctx context.Context, taskID string) (*TaskingRequestRes
 	return &resp, nil
 }
 
-// ApproveTask approves a tasking request (cost review).
+// ApproveTask approves a tasking request (cost review). It returns a
+// *NotReviewableError if the task is not in review.
 func (c *Client) ApproveTask(ctx context.Context, taskID string) (*TaskingRequestResponse, error) {
+	if err := c.confirmProductionWrite("approve task"); err != nil {
+		return nil, err
+	}
+
+	payload := reviewDecision{Status: TaskApproved}
+	return c.decideReview(ctx, taskID, payload)
+}
+
+// CancelTask cancels a tasking request.
+func (c *Client) CancelTask(ctx context.Context, taskID string) (*TaskingRequestResponse, error) {
 	payload := struct {
 		Status TaskStatus `json:"status"`
-	}{Status: TaskApproved}
+	}{Status: TaskCanceled}
 
 	var resp TaskingRequestResponse
 	if err := c.Do(ctx, http.MethodPatch, "/task/"+taskID, 0, payload, &resp); err != nil {
@@ -171,11 +206,23 @@ func (c *Client) ApproveTask(ctx context.Context, taskID string) (*TaskingReques
 	return &resp, nil
 }
 
-// CancelTask cancels a tasking request.
-func (c *Client) CancelTask(ctx context.Context, taskID string) (*TaskingRequestResponse, error) {
+// TaskUpdate holds the tasking request properties that can be changed after
+// submission. Nil and empty fields are left unchanged.
+type TaskUpdate struct {
+	TaskingRequestName        string            `json:"taskingrequestName,omitempty"`
+	TaskingRequestDescription string            `json:"taskingrequestDescription,omitempty"`
+	WindowOpen                *time.Time        `json:"windowOpen,omitempty"`
+	WindowClose               *time.Time        `json:"windowClose,omitempty"`
+	ProcessingConfig          *ProcessingConfig `json:"processingConfig,omitempty"`
+	CustomAttribute1          string            `json:"customAttribute1,omitempty"`
+	CustomAttribute2          string            `json:"customAttribute2,omitempty"`
+}
+
+// UpdateTask updates the properties of a tasking request.
+func (c *Client) UpdateTask(ctx context.Context, taskID string, update TaskUpdate) (*TaskingRequestResponse, error) {
 	payload := struct {
-		Status TaskStatus `json:"status"`
-	}{Status: TaskCanceled}
+		Properties TaskUpdate `json:"properties"`
+	}{Properties: update}
 
 	var resp TaskingRequestResponse
 	if err := c.Do(ctx, http.MethodPatch, "/task/"+taskID, 0, payload, &resp); err != nil {
@@ -184,6 +231,90 @@ func (c *Client) CancelTask(ctx context.Context, taskID string) (*TaskingRequest
 	return &resp, nil
 }
 
+// ----------------------------------------------------------------------------
+// Cost Review
+// ----------------------------------------------------------------------------
+
+// TaskCostLineItem is the quoted cost of a single product in a tasking request.
+type TaskCostLineItem struct {
+	ProductType ProductType `json:"productType"`
+	Description string      `json:"description,omitempty"`
+	Amount      float64     `json:"amount"`
+}
+
+// TaskCostDiscount is a contract discount applied to a tasking request quote.
+type TaskCostDiscount struct {
+	ContractID  string  `json:"contractId,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Amount      float64 `json:"amount"`
+}
+
+// TaskCost is the quoted cost of a tasking request awaiting review.
+type TaskCost struct {
+	TaskingRequestID string             `json:"taskingrequestId"`
+	Total            float64            `json:"total"`
+	Currency         string             `json:"currency"`
+	LineItems        []TaskCostLineItem `json:"lineItems,omitempty"`
+	Discounts        []TaskCostDiscount `json:"discounts,omitempty"`
+	ExpiresAt        *time.Time         `json:"expiresAt,omitempty"`
+}
+
+// GetTaskCost returns the quoted cost of a tasking request in review.
+// It returns ErrQuotePending while the quote is still being calculated.
+func (c *Client) GetTaskCost(ctx context.Context, taskID string) (*TaskCost, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, "/task/"+taskID+"/cost", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNoContent {
+		return nil, ErrQuotePending
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, parseError(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, ErrQuotePending
+	}
+
+	var cost TaskCost
+	if err := json.Unmarshal(body, &cost); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if cost.Currency == "" && cost.Total == 0 && len(cost.LineItems) == 0 {
+		return nil, ErrQuotePending
+	}
+	return &cost, nil
+}
+
+// ApproveTaskIfUnder approves a tasking request only if its quoted total is at
+// most maxAmount in currency. Over budget, it returns a *CostExceededError
+// carrying the quote and leaves the task in review. A quote in a different
+// currency is rejected without approving.
+func (c *Client) ApproveTaskIfUnder(ctx context.Context, taskID string, maxAmount float64, currency string) (*TaskingRequestResponse, error) {
+	cost, err := c.GetTaskCost(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(cost.Currency, currency) {
+		return nil, fmt.Errorf("task %s is quoted in %s, budget is in %s", taskID, cost.Currency, currency)
+	}
+	if cost.Total > maxAmount {
+		return nil, &CostExceededError{Quote: cost, MaxAmount: maxAmount}
+	}
+	return c.ApproveTask(ctx, taskID)
+}
+
 // ----------------------------------------------------------------------------
 // Retasking
 // ----------------------------------------------------------------------------
@@ -214,6 +345,130 @@ func (c *Client) RetaskFromCollect(ctx context.Context, collectID string, req Ta
 	return &resp, nil
 }
 
+// ----------------------------------------------------------------------------
+// Collects
+// ----------------------------------------------------------------------------
+
+// Collect is a scheduled acquisition of a tasking request.
+type Collect struct {
+	CollectID        string        `json:"collectId"`
+	TaskingRequestID string        `json:"taskingrequestId,omitempty"`
+	RepeatRequestID  string        `json:"repeatrequestId,omitempty"`
+	SpacecraftID     int           `json:"spacecraftId,omitempty"`
+	WindowOpen       time.Time     `json:"windowOpen,omitempty"`
+	WindowClose      time.Time     `json:"windowClose,omitempty"`
+	StatusHistory    []StatusEntry `json:"collectStatusHistory,omitempty"`
+}
+
+// ListTaskCollects returns the collects scheduled for a tasking request.
+// GET /collects/list/{taskingrequest_id}
+func (c *Client) ListTaskCollects(ctx context.Context, taskID string) ([]Collect, error) {
+	var resp []Collect
+	if err := c.Do(ctx, http.MethodGet, "/collects/list/"+url.PathEscape(taskID), 0, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ----------------------------------------------------------------------------
+// Conflict Resolution
+// ----------------------------------------------------------------------------
+
+// HasConflicts reports whether the tasking request conflicts with other tasks.
+func (r *TaskingRequestResponse) HasConflicts() bool {
+	return len(r.ConflictingTasks) > 0
+}
+
+// ConflictsWithRepeatSeries returns the conflicting tasks that belong to a
+// repeat request series.
+func (r *TaskingRequestResponse) ConflictsWithRepeatSeries() []ConflictingTask {
+	var out []ConflictingTask
+	for _, ct := range r.ConflictingTasks {
+		if ct.RepeatRequestID != "" {
+			out = append(out, ct)
+		}
+	}
+	return out
+}
+
+// ConflictStrategy decides how ResolveConflicts handles a conflicted task.
+type ConflictStrategy interface {
+	resolve(ctx context.Context, c *Client, task *TaskingRequestResponse) error
+}
+
+type conflictStrategyFunc func(ctx context.Context, c *Client, task *TaskingRequestResponse) error
+
+func (f conflictStrategyFunc) resolve(ctx context.Context, c *Client, task *TaskingRequestResponse) error {
+	return f(ctx, c, task)
+}
+
+var (
+	// IgnoreConflicts leaves the task unchanged.
+	IgnoreConflicts ConflictStrategy = conflictStrategyFunc(func(context.Context, *Client, *TaskingRequestResponse) error {
+		return nil
+	})
+
+	// CancelSelf cancels the conflicted task.
+	CancelSelf ConflictStrategy = conflictStrategyFunc(func(ctx context.Context, c *Client, task *TaskingRequestResponse) error {
+		_, err := c.CancelTask(ctx, task.Properties.TaskingRequestID)
+		return err
+	})
+)
+
+// ShiftWindowAfter moves the task's window open to margin after the latest
+// conflicting window close, keeping the original window close. It fails if
+// the shifted window would open at or after the original window close.
+func ShiftWindowAfter(margin time.Duration) ConflictStrategy {
+	return conflictStrategyFunc(func(ctx context.Context, c *Client, task *TaskingRequestResponse) error {
+		open, err := ShiftedWindowOpen(task, margin)
+		if err != nil {
+			return err
+		}
+		if !open.After(task.Properties.WindowOpen) {
+			return nil
+		}
+		_, err = c.UpdateTask(ctx, task.Properties.TaskingRequestID, TaskUpdate{WindowOpen: &open})
+		return err
+	})
+}
+
+// ShiftedWindowOpen returns the window open ShiftWindowAfter would set: the
+// later of the task's window open and the latest conflicting window close
+// plus margin. It returns an error if that is not before the task's window
+// close.
+func ShiftedWindowOpen(task *TaskingRequestResponse, margin time.Duration) (time.Time, error) {
+	open := task.Properties.WindowOpen
+	for _, ct := range task.ConflictingTasks {
+		if shifted := ct.WindowClose.Add(margin); shifted.After(open) {
+			open = shifted
+		}
+	}
+	if !open.Before(task.Properties.WindowClose) {
+		return time.Time{}, fmt.Errorf("failed to shift task %s: window would open at %s, after window close %s",
+			task.Properties.TaskingRequestID, open.Format(time.RFC3339), task.Properties.WindowClose.Format(time.RFC3339))
+	}
+	return open, nil
+}
+
+// ResolveConflicts fetches a tasking request and applies strategy if it
+// conflicts with other tasks. A task without conflicts is left unchanged.
+func (c *Client) ResolveConflicts(ctx context.Context, taskID string, strategy ConflictStrategy) error {
+	if strategy == nil {
+		return fmt.Errorf("conflict strategy is required")
+	}
+	task, err := c.GetTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if !task.HasConflicts() {
+		return nil
+	}
+	if task.Properties.TaskingRequestID == "" {
+		task.Properties.TaskingRequestID = taskID
+	}
+	return strategy.resolve(ctx, c, task)
+}
+
 // ----------------------------------------------------------------------------
 // Pagination and Listing
 // ----------------------------------------------------------------------------
@@ -227,6 +482,26 @@ type ListTasksParams struct {
 	Limit          int
 	Sort           string
 	Order          string // "asc" or "desc"
+
+	// Status matches tasks in any of the given statuses.
+	Status         []TaskStatus
+	CollectionTier CollectionTier
+	CollectionType CollectionType
+	// WindowOpenAfter and WindowCloseBefore match tasks whose window opens
+	// at or after, and closes at or before, the given times.
+	WindowOpenAfter   *time.Time
+	WindowCloseBefore *time.Time
+	// NameContains matches tasks whose name contains the string.
+	NameContains string
+}
+
+// validate checks the filters for combinations that cannot match any task.
+func (p ListTasksParams) validate() error {
+	if p.WindowOpenAfter != nil && p.WindowCloseBefore != nil && p.WindowOpenAfter.After(*p.WindowCloseBefore) {
+		return fmt.Errorf("window open filter %s is after window close filter %s",
+			p.WindowOpenAfter.Format(time.RFC3339), p.WindowCloseBefore.Format(time.RFC3339))
+	}
+	return nil
 }
 
 // fetchTasksPage fetches a single page of tasking requests.
@@ -254,6 +529,24 @@ func (c *Client) fetchTasksPage(ctx context.Context, params ListTasksParams) (*T
 	if params.Order != "" {
 		v.Set("order", params.Order)
 	}
+	for _, status := range params.Status {
+		v.Add("status", string(status))
+	}
+	if params.CollectionTier != "" {
+		v.Set("collectionTier", string(params.CollectionTier))
+	}
+	if params.CollectionType != "" {
+		v.Set("collectionType", string(params.CollectionType))
+	}
+	if params.WindowOpenAfter != nil {
+		v.Set("windowOpen", params.WindowOpenAfter.UTC().Format(time.RFC3339))
+	}
+	if params.WindowCloseBefore != nil {
+		v.Set("windowClose", params.WindowCloseBefore.UTC().Format(time.RFC3339))
+	}
+	if params.NameContains != "" {
+		v.Set("name", params.NameContains)
+	}
 
 	u := c.BuildURL("/tasks/paged")
 	u.RawQuery = v.Encode()
@@ -266,7 +559,10 @@ func (c *Client) fetchTasksPage(ctx context.Context, params ListTasksParams) (*T
 	return &resp, nil
 }
 
-// ListTasks returns an iterator over all tasking requests with automatic pagination.
+// ListTasks returns an iterator over all tasking requests matching the
+// filters in params, with automatic pagination. Filters that cannot match,
+// such as a window opening after it closes, yield an error without sending a
+// request.
 func (c *Client) ListTasks(ctx context.Context, params ListTasksParams) iter.Seq2[TaskingRequestResponse, error] {
 	if params.Page <= 0 {
 		params.Page = 1
@@ -276,6 +572,11 @@ func (c *Client) ListTasks(ctx context.Context, params ListTasksParams) iter.Seq
 	}
 
 	return func(yield func(TaskingRequestResponse, error) bool) {
+		if err := params.validate(); err != nil {
+			yield(TaskingRequestResponse{}, err)
+			return
+		}
+
 		page := params.Page
 
 		for {
@@ -317,7 +618,7 @@ type TaskSearchRequest struct {
 // SearchTasks performs an advanced search on tasking requests.
 func (c *Client) SearchTasks(ctx context.Context, req TaskSearchRequest) (*TaskingRequestsPagedResponse, error) {
 	var resp TaskingRequestsPagedResponse
-	if err := c.Do(ctx, http.MethodPost, "/tasks/search", 0, req, &resp); err != nil {
+	if err := c.Do(ctx, http.MethodPost, "/tasks/search", 0, req, &resp, WithFailover()); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -485,6 +786,12 @@ func (b *TaskingRequestBuilder) BBox(bbox BoundingBox) *TaskingRequestBuilder {
 	return b
 }
 
+// Geometry sets a pre-built geometry for the request, e.g. a MultiPolygon AOI.
+func (b *TaskingRequestBuilder) Geometry(geom *geojson.Geometry) *TaskingRequestBuilder {
+	b.req.Geometry = geom
+	return b
+}
+
 // Name sets the tasking request name.
 func (b *TaskingRequestBuilder) Name(name string) *TaskingRequestBuilder {
 	b.req.Properties.TaskingRequestName = name
@@ -536,7 +843,55 @@ func (b *TaskingRequestBuilder) Products(products ...ProductType) *TaskingReques
 	return b
 }
 
+// Holdback sets the archive holdback period.
+func (b *TaskingRequestBuilder) Holdback(holdback ArchiveHoldback) *TaskingRequestBuilder {
+	b.req.Properties.ArchiveHoldback = holdback
+	return b
+}
+
+// CustomAttributes sets the two free-form custom attributes.
+func (b *TaskingRequestBuilder) CustomAttributes(a1, a2 string) *TaskingRequestBuilder {
+	b.req.Properties.CustomAttribute1 = a1
+	b.req.Properties.CustomAttribute2 = a2
+	return b
+}
+
+// Org sets the organization the request is made for.
+func (b *TaskingRequestBuilder) Org(orgID string) *TaskingRequestBuilder {
+	b.req.Properties.OrgID = orgID
+	return b
+}
+
+// User sets the user the request is made for.
+func (b *TaskingRequestBuilder) User(userID string) *TaskingRequestBuilder {
+	b.req.Properties.UserID = userID
+	return b
+}
+
 // Build returns the constructed TaskingRequest.
 func (b *TaskingRequestBuilder) Build() TaskingRequest {
 	return b.req
 }
+
+// BuildValidated returns the constructed TaskingRequest, or an error listing
+// every required field that is not set: geometry, window, tier and type.
+func (b *TaskingRequestBuilder) BuildValidated() (TaskingRequest, error) {
+	p := b.req.Properties
+	var errs []error
+	if b.req.Geometry == nil || b.req.Geometry.Geometry() == nil {
+		errs = append(errs, errors.New("geometry is required"))
+	}
+	if p.WindowOpen.IsZero() || p.WindowClose.IsZero() {
+		errs = append(errs, errors.New("window is required"))
+	}
+	if p.CollectionTier == "" {
+		errs = append(errs, errors.New("collection tier is required"))
+	}
+	if p.CollectionType == "" {
+		errs = append(errs, errors.New("collection type is required"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return TaskingRequest{}, fmt.Errorf("invalid tasking request: %w", err)
+	}
+	return b.req, nil
+}
@@ -3,12 +3,16 @@ package capella_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+
 	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
 )
 
@@ -126,6 +130,140 @@ func TestTaskingService_ApproveTask(t *testing.T) {
 	}
 }
 
+var testTaskCost = capella.TaskCost{
+	TaskingRequestID: "tr-123",
+	Total:            4500,
+	Currency:         "USD",
+	LineItems: []capella.TaskCostLineItem{
+		{ProductType: capella.ProductSLC, Amount: 3000},
+		{ProductType: capella.ProductGEO, Amount: 2000},
+	},
+	Discounts: []capella.TaskCostDiscount{{ContractID: "contract-1", Amount: 500}},
+}
+
+// costReviewHandler serves the cost quote for tr-123 and records approvals.
+func costReviewHandler(t *testing.T, cost capella.TaskCost, approved *bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/task/tr-123/cost":
+			jsonResponse(w, http.StatusOK, cost)
+		case r.Method == http.MethodPatch && r.URL.Path == "/task/tr-123":
+			*approved = true
+			jsonResponse(w, http.StatusOK, capella.TaskingRequestResponse{
+				Properties: capella.TaskingRequestPropertiesResponse{TaskingRequestID: "tr-123", Status: capella.TaskApproved},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+}
+
+func TestTaskingService_GetTaskCost(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodGet)
+		requirePath(t, r, "/task/tr-123/cost")
+		requireAuth(t, r, "test-api-key")
+		jsonResponse(w, http.StatusOK, testTaskCost)
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	cost, err := cli.GetTaskCost(context.Background(), "tr-123")
+	if err != nil {
+		t.Fatalf("GetTaskCost failed: %v", err)
+	}
+	if cost.Total != 4500 || cost.Currency != "USD" {
+		t.Errorf("unexpected total: %v %s", cost.Total, cost.Currency)
+	}
+	if len(cost.LineItems) != 2 || cost.LineItems[1].ProductType != capella.ProductGEO {
+		t.Errorf("unexpected line items: %+v", cost.LineItems)
+	}
+	if len(cost.Discounts) != 1 || cost.Discounts[0].ContractID != "contract-1" {
+		t.Errorf("unexpected discounts: %+v", cost.Discounts)
+	}
+}
+
+func TestTaskingService_GetTaskCost_Pending(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"202", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusAccepted) }},
+		{"empty body", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }},
+		{"empty quote", func(w http.ResponseWriter, r *http.Request) {
+			jsonResponse(w, http.StatusOK, map[string]any{"taskingrequestId": "tr-123"})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, _ := newTestClient(t, tt.handler)
+			_, err := cli.GetTaskCost(context.Background(), "tr-123")
+			if !errors.Is(err, capella.ErrQuotePending) {
+				t.Fatalf("expected ErrQuotePending, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTaskingService_ApproveTaskIfUnder(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxAmount float64
+		currency  string
+		approved  bool
+		exceeded  bool
+	}{
+		{"under budget", 5000, "USD", true, false},
+		{"at budget", 4500, "usd", true, false},
+		{"over budget", 4000, "USD", false, true},
+		{"currency mismatch", 10000, "EUR", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var approved bool
+			cli, _ := newTestClient(t, costReviewHandler(t, testTaskCost, &approved))
+
+			resp, err := cli.ApproveTaskIfUnder(context.Background(), "tr-123", tt.maxAmount, tt.currency)
+			if approved != tt.approved {
+				t.Errorf("approved = %v, want %v", approved, tt.approved)
+			}
+			if tt.approved {
+				if err != nil || resp.Properties.Status != capella.TaskApproved {
+					t.Fatalf("expected approval, got %v, %v", resp, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			var ce *capella.CostExceededError
+			if errors.As(err, &ce) != tt.exceeded {
+				t.Fatalf("CostExceededError = %v, want %v (err: %v)", ce != nil, tt.exceeded, err)
+			}
+			if tt.exceeded && (ce.Quote.Total != 4500 || ce.MaxAmount != tt.maxAmount) {
+				t.Errorf("unexpected error details: %+v", ce)
+			}
+		})
+	}
+}
+
+func TestTaskingService_ApproveTaskIfUnder_Pending(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("task should not be approved while quote is pending")
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	if _, err := cli.ApproveTaskIfUnder(context.Background(), "tr-123", 5000, "USD"); !errors.Is(err, capella.ErrQuotePending) {
+		t.Fatalf("expected ErrQuotePending, got %v", err)
+	}
+}
+
 func TestTaskingService_CancelTask(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		requireMethod(t, r, http.MethodPatch)
@@ -259,6 +397,85 @@ func TestTaskingService_ListTasks_ErrorPropagates(t *testing.T) {
 	t.Fatal("iterator produced no values")
 }
 
+func TestTaskingService_ListTasks_Filters(t *testing.T) {
+	var queries []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodGet)
+		requirePath(t, r, "/tasks/paged")
+		queries = append(queries, r.URL.RawQuery)
+
+		page := r.URL.Query().Get("page")
+		jsonResponse(w, http.StatusOK, capella.TaskingRequestsPagedResponse{
+			Results: []capella.TaskingRequestResponse{
+				{Properties: capella.TaskingRequestPropertiesResponse{TaskingRequestID: "tr-" + page}},
+			},
+			TotalPages: 2,
+		})
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	open := time.Date(2025, 3, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+	closeBy := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	params := capella.ListTasksParams{
+		Limit:             10,
+		Status:            []capella.TaskStatus{capella.TaskAccepted, capella.TaskActive},
+		CollectionTier:    capella.TierUrgent,
+		CollectionType:    capella.CollectionSpotlight,
+		WindowOpenAfter:   &open,
+		WindowCloseBefore: &closeBy,
+		NameContains:      "port survey",
+	}
+	var ids []string
+	for task, err := range cli.ListTasks(context.Background(), params) {
+		if err != nil {
+			t.Fatalf("iterator error: %v", err)
+		}
+		ids = append(ids, task.Properties.TaskingRequestID)
+	}
+
+	if len(ids) != 2 || ids[0] != "tr-1" || ids[1] != "tr-2" {
+		t.Errorf("expected tasks tr-1 and tr-2, got %v", ids)
+	}
+	want := []string{
+		"collectionTier=urgent&collectionType=spotlight&limit=10&name=port+survey&page=1" +
+			"&status=accepted&status=active&windowClose=2025-03-31T00%3A00%3A00Z&windowOpen=2025-02-28T23%3A00%3A00Z",
+		"collectionTier=urgent&collectionType=spotlight&limit=10&name=port+survey&page=2" +
+			"&status=accepted&status=active&windowClose=2025-03-31T00%3A00%3A00Z&windowOpen=2025-02-28T23%3A00%3A00Z",
+	}
+	if len(queries) != len(want) {
+		t.Fatalf("expected %d requests, got %d", len(want), len(queries))
+	}
+	for i := range want {
+		if queries[i] != want[i] {
+			t.Errorf("request %d: expected query\n%s\ngot\n%s", i+1, want[i], queries[i])
+		}
+	}
+}
+
+func TestTaskingService_ListTasks_InvalidWindowFilter(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected no request to be sent")
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	after := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	before := after.Add(-time.Hour)
+	params := capella.ListTasksParams{WindowOpenAfter: &after, WindowCloseBefore: &before}
+
+	n := 0
+	for _, err := range cli.ListTasks(context.Background(), params) {
+		n++
+		if err == nil || !strings.Contains(err.Error(), "after window close") {
+			t.Errorf("expected a window filter error, got %v", err)
+		}
+	}
+	if n != 1 {
+		t.Errorf("expected a single error, got %d values", n)
+	}
+}
+
 func TestTaskingService_GetCollectionTypes(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		requireMethod(t, r, http.MethodGet)
@@ -364,4 +581,186 @@ func TestTaskingRequestBuilder(t *testing.T) {
 	if req.Properties.ProcessingConfig == nil || len(req.Properties.ProcessingConfig.ProductTypes) != 2 {
 		t.Error("expected 2 product types")
 	}
+
+	aoi := geojson.NewGeometry(orb.MultiPolygon{
+		{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+		{{{2, 2}, {3, 2}, {3, 3}, {2, 2}}},
+	})
+	req, err := capella.NewTaskingRequestBuilder().
+		Geometry(aoi).
+		Window(open, close).
+		Tier(capella.TierStandard).
+		Type(capella.CollectionStripmap20).
+		Holdback(capella.Archive1Year).
+		CustomAttributes("project-x", "cost-center-7").
+		Org("org-1").
+		User("user-1").
+		BuildValidated()
+	if err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	if req.Geometry != aoi {
+		t.Error("expected the pre-built geometry to be used")
+	}
+	p := req.Properties
+	if p.ArchiveHoldback != capella.Archive1Year {
+		t.Errorf("expected holdback '1_year', got %q", p.ArchiveHoldback)
+	}
+	if p.CustomAttribute1 != "project-x" || p.CustomAttribute2 != "cost-center-7" {
+		t.Errorf("unexpected custom attributes %q, %q", p.CustomAttribute1, p.CustomAttribute2)
+	}
+	if p.OrgID != "org-1" || p.UserID != "user-1" {
+		t.Errorf("unexpected org/user %q, %q", p.OrgID, p.UserID)
+	}
+
+	_, err = capella.NewTaskingRequestBuilder().Name("incomplete").BuildValidated()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, field := range []string{"geometry", "window", "collection tier", "collection type"} {
+		if !strings.Contains(err.Error(), field+" is required") {
+			t.Errorf("error %q does not list missing %s", err, field)
+		}
+	}
+}
+
+func conflictedTask(open, close time.Time, conflicts ...capella.ConflictingTask) map[string]any {
+	return map[string]any{
+		"type": "Feature",
+		"properties": map[string]any{
+			"taskingrequestId": "tr-1",
+			"windowOpen":       open,
+			"windowClose":      close,
+		},
+		"conflictingTasks": conflicts,
+	}
+}
+
+func TestTaskingRequestResponse_Conflicts(t *testing.T) {
+	var none capella.TaskingRequestResponse
+	if none.HasConflicts() || len(none.ConflictsWithRepeatSeries()) != 0 {
+		t.Error("expected no conflicts on empty response")
+	}
+
+	r := capella.TaskingRequestResponse{ConflictingTasks: []capella.ConflictingTask{
+		{TaskingRequestID: "tr-a"},
+		{TaskingRequestID: "tr-b", RepeatRequestID: "rr-1"},
+	}}
+	if !r.HasConflicts() {
+		t.Error("expected conflicts")
+	}
+	series := r.ConflictsWithRepeatSeries()
+	if len(series) != 1 || series[0].RepeatRequestID != "rr-1" {
+		t.Errorf("unexpected repeat series conflicts: %+v", series)
+	}
+}
+
+func TestTaskingService_ResolveConflicts(t *testing.T) {
+	open := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	close := open.Add(48 * time.Hour)
+	conflicts := []capella.ConflictingTask{
+		{TaskingRequestID: "tr-a", WindowOpen: open, WindowClose: open.Add(6 * time.Hour)},
+		{TaskingRequestID: "tr-b", RepeatRequestID: "rr-1", WindowOpen: open, WindowClose: open.Add(10 * time.Hour)},
+	}
+
+	tests := []struct {
+		name      string
+		conflicts []capella.ConflictingTask
+		strategy  capella.ConflictStrategy
+		wantPatch string
+		wantErr   bool
+	}{
+		{
+			name:     "no conflicts is a no-op",
+			strategy: capella.CancelSelf,
+		},
+		{
+			name:      "ignore",
+			conflicts: conflicts,
+			strategy:  capella.IgnoreConflicts,
+		},
+		{
+			name:      "cancel self",
+			conflicts: conflicts,
+			strategy:  capella.CancelSelf,
+			wantPatch: `{"status":"canceled"}`,
+		},
+		{
+			name:      "shift after latest conflict",
+			conflicts: conflicts,
+			strategy:  capella.ShiftWindowAfter(30 * time.Minute),
+			wantPatch: `{"properties":{"windowOpen":"2024-06-01T10:30:00Z"}}`,
+		},
+		{
+			name:      "shift past window close",
+			conflicts: conflicts,
+			strategy:  capella.ShiftWindowAfter(38 * time.Hour),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch string
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				requirePath(t, r, "/task/tr-1")
+				switch r.Method {
+				case http.MethodGet:
+					jsonResponse(w, http.StatusOK, conflictedTask(open, close, tt.conflicts...))
+				case http.MethodPatch:
+					body, _ := io.ReadAll(r.Body)
+					patch = string(body)
+					jsonResponse(w, http.StatusOK, conflictedTask(open, close))
+				default:
+					t.Errorf("unexpected method %s", r.Method)
+				}
+			}
+
+			cli, _ := newTestClient(t, handler)
+
+			err := cli.ResolveConflicts(context.Background(), "tr-1", tt.strategy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveConflicts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if patch != tt.wantPatch {
+				t.Errorf("PATCH body = %q, want %q", patch, tt.wantPatch)
+			}
+		})
+	}
+}
+
+func TestShiftedWindowOpen(t *testing.T) {
+	open := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	task := &capella.TaskingRequestResponse{
+		Properties: capella.TaskingRequestPropertiesResponse{
+			TaskingRequestProperties: capella.TaskingRequestProperties{
+				WindowOpen:  open,
+				WindowClose: open.Add(24 * time.Hour),
+			},
+		},
+		ConflictingTasks: []capella.ConflictingTask{
+			{WindowClose: open.Add(-2 * time.Hour)},
+			{WindowClose: open.Add(5 * time.Hour)},
+		},
+	}
+
+	got, err := capella.ShiftedWindowOpen(task, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := open.Add(6 * time.Hour); !got.Equal(want) {
+		t.Errorf("ShiftedWindowOpen() = %v, want %v", got, want)
+	}
+
+	// Conflicts ending before the window opens leave it unchanged.
+	task.ConflictingTasks = task.ConflictingTasks[:1]
+	if got, _ := capella.ShiftedWindowOpen(task, time.Hour); !got.Equal(open) {
+		t.Errorf("ShiftedWindowOpen() = %v, want unchanged %v", got, open)
+	}
+
+	// Landing exactly on window close fails.
+	task.ConflictingTasks = []capella.ConflictingTask{{WindowClose: open.Add(23 * time.Hour)}}
+	if _, err := capella.ShiftedWindowOpen(task, time.Hour); err == nil {
+		t.Error("expected error when shifted window reaches window close")
+	}
 }
@@ -0,0 +1,175 @@
+package capella
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Task Query Builder
+// ----------------------------------------------------------------------------
+
+// TaskQueryBuilder builds the query of a POST /tasks/search request with
+// typed filters. Each method documents the exact JSON member it produces;
+// calling a method twice replaces the earlier filter.
+//
+//	qb := capella.NewTaskQuery().
+//		Status(capella.TaskAccepted, capella.TaskActive).
+//		WindowOpenBetween(from, to)
+type TaskQueryBuilder struct {
+	query map[string]any
+	err   error
+}
+
+// NewTaskQuery returns an empty task query builder.
+func NewTaskQuery() *TaskQueryBuilder {
+	return &TaskQueryBuilder{query: make(map[string]any)}
+}
+
+// Status filters on the latest status code:
+//
+//	"lastStatusCode": ["accepted", "completed"]
+func (qb *TaskQueryBuilder) Status(in ...TaskStatus) *TaskQueryBuilder {
+	return setList(qb, "lastStatusCode", in)
+}
+
+// CollectionTier filters on collection tier:
+//
+//	"collectionTier": ["urgent", "priority"]
+func (qb *TaskQueryBuilder) CollectionTier(in ...CollectionTier) *TaskQueryBuilder {
+	return setList(qb, "collectionTier", in)
+}
+
+// CollectionType filters on collection type:
+//
+//	"collectionType": ["spotlight"]
+func (qb *TaskQueryBuilder) CollectionType(in ...CollectionType) *TaskQueryBuilder {
+	return setList(qb, "collectionType", in)
+}
+
+// WindowOpenBetween filters on window open time as an RFC 3339 interval. A
+// zero from or to leaves that end of the interval open:
+//
+//	"windowOpen": "2024-01-01T00:00:00Z/2024-02-01T00:00:00Z"
+//	"windowOpen": "2024-01-01T00:00:00Z/.."
+func (qb *TaskQueryBuilder) WindowOpenBetween(from, to time.Time) *TaskQueryBuilder {
+	if from.IsZero() && to.IsZero() {
+		return qb.fail(errors.New("windowOpen interval requires from or to"))
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return qb.fail(fmt.Errorf("windowOpen interval end %s is before start %s", formatQueryTime(to), formatQueryTime(from)))
+	}
+	return qb.Custom("windowOpen", queryInterval(from, to))
+}
+
+// CreatedAfter filters on submission time as an open RFC 3339 interval:
+//
+//	"submissionTime": "2024-01-01T00:00:00Z/.."
+func (qb *TaskQueryBuilder) CreatedAfter(t time.Time) *TaskQueryBuilder {
+	if t.IsZero() {
+		return qb.fail(errors.New("submissionTime filter requires a non-zero time"))
+	}
+	return qb.Custom("submissionTime", queryInterval(t, time.Time{}))
+}
+
+// OrgID filters on organization:
+//
+//	"organizationId": "org-123"
+func (qb *TaskQueryBuilder) OrgID(id string) *TaskQueryBuilder {
+	if id == "" {
+		return qb.fail(errors.New("organizationId filter requires a non-empty ID"))
+	}
+	return qb.Custom("organizationId", id)
+}
+
+// Name filters on a case-insensitive substring of the tasking request name:
+//
+//	"taskingrequestName": {"contains": "harbor"}
+func (qb *TaskQueryBuilder) Name(contains string) *TaskQueryBuilder {
+	if contains == "" {
+		return qb.fail(errors.New("taskingrequestName filter requires a non-empty string"))
+	}
+	return qb.Custom("taskingrequestName", map[string]string{"contains": contains})
+}
+
+// Custom sets an arbitrary query member, e.g. a scoped property such as
+// "collectConstraints.imageLength" with an operator object {"gt": "100"}.
+func (qb *TaskQueryBuilder) Custom(key string, value any) *TaskQueryBuilder {
+	if qb.query == nil {
+		qb.query = make(map[string]any)
+	}
+	qb.query[key] = value
+	return qb
+}
+
+// Build returns the query object, or an error if no filters were set or a
+// filter was invalid.
+func (qb *TaskQueryBuilder) Build() (map[string]any, error) {
+	if qb == nil {
+		return nil, errors.New("task query is nil")
+	}
+	if qb.err != nil {
+		return nil, qb.err
+	}
+	if len(qb.query) == 0 {
+		return nil, errors.New("task query has no filters")
+	}
+	return qb.query, nil
+}
+
+func (qb *TaskQueryBuilder) fail(err error) *TaskQueryBuilder {
+	if qb.err == nil {
+		qb.err = err
+	}
+	return qb
+}
+
+func setList[T ~string](qb *TaskQueryBuilder, key string, values []T) *TaskQueryBuilder {
+	if len(values) == 0 {
+		return qb.fail(fmt.Errorf("%s filter requires at least one value", key))
+	}
+	list := make([]string, len(values))
+	for i, v := range values {
+		list[i] = string(v)
+	}
+	return qb.Custom(key, list)
+}
+
+func formatQueryTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func queryInterval(from, to time.Time) string {
+	start, end := "..", ".."
+	if !from.IsZero() {
+		start = formatQueryTime(from)
+	}
+	if !to.IsZero() {
+		end = formatQueryTime(to)
+	}
+	return start + "/" + end
+}
+
+// SearchTasksQuery searches tasking requests matching the query built by qb.
+func (c *Client) SearchTasksQuery(ctx context.Context, qb *TaskQueryBuilder, page, limit int) (*TaskingRequestsPagedResponse, error) {
+	query, err := qb.Build()
+	if err != nil {
+		return nil, err
+	}
+	return c.SearchTasks(ctx, TaskSearchRequest{Query: query, Page: page, Limit: limit})
+}
+
+// SearchTasksQueryIterator returns an iterator over tasking requests matching
+// the query built by qb, with automatic pagination.
+func (c *Client) SearchTasksQueryIterator(ctx context.Context, qb *TaskQueryBuilder, limit int) iter.Seq2[TaskingRequestResponse, error] {
+	query, err := qb.Build()
+	if err != nil {
+		return func(yield func(TaskingRequestResponse, error) bool) {
+			yield(TaskingRequestResponse{}, err)
+		}
+	}
+	return c.SearchTasksIterator(ctx, TaskSearchRequest{Query: query, Limit: limit})
+}
@@ -0,0 +1,230 @@
+package capella_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+func TestTaskQueryBuilder_JSON(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 12, 30, 0, 0, time.FixedZone("EST", -5*3600))
+
+	tests := []struct {
+		name string
+		qb   *capella.TaskQueryBuilder
+		want string
+	}{
+		{
+			name: "status",
+			qb:   capella.NewTaskQuery().Status(capella.TaskAccepted, capella.TaskActive),
+			want: `{"lastStatusCode":["accepted","active"]}`,
+		},
+		{
+			name: "collection tier",
+			qb:   capella.NewTaskQuery().CollectionTier(capella.TierUrgent, capella.TierPriority),
+			want: `{"collectionTier":["urgent","priority"]}`,
+		},
+		{
+			name: "collection type",
+			qb:   capella.NewTaskQuery().CollectionType(capella.CollectionSpotlight),
+			want: `{"collectionType":["spotlight"]}`,
+		},
+		{
+			name: "window open between",
+			qb:   capella.NewTaskQuery().WindowOpenBetween(from, to),
+			want: `{"windowOpen":"2024-01-01T00:00:00Z/2024-02-01T17:30:00Z"}`,
+		},
+		{
+			name: "window open after",
+			qb:   capella.NewTaskQuery().WindowOpenBetween(from, time.Time{}),
+			want: `{"windowOpen":"2024-01-01T00:00:00Z/.."}`,
+		},
+		{
+			name: "window open before",
+			qb:   capella.NewTaskQuery().WindowOpenBetween(time.Time{}, from),
+			want: `{"windowOpen":"../2024-01-01T00:00:00Z"}`,
+		},
+		{
+			name: "created after",
+			qb:   capella.NewTaskQuery().CreatedAfter(from),
+			want: `{"submissionTime":"2024-01-01T00:00:00Z/.."}`,
+		},
+		{
+			name: "org id",
+			qb:   capella.NewTaskQuery().OrgID("org-123"),
+			want: `{"organizationId":"org-123"}`,
+		},
+		{
+			name: "name",
+			qb:   capella.NewTaskQuery().Name("harbor"),
+			want: `{"taskingrequestName":{"contains":"harbor"}}`,
+		},
+		{
+			name: "custom",
+			qb:   capella.NewTaskQuery().Custom("collectConstraints.imageLength", map[string]string{"gt": "100"}),
+			want: `{"collectConstraints.imageLength":{"gt":"100"}}`,
+		},
+		{
+			name: "combined",
+			qb:   capella.NewTaskQuery().Status(capella.TaskCompleted).OrgID("org-1").Name("port"),
+			want: `{"lastStatusCode":["completed"],"organizationId":"org-1","taskingrequestName":{"contains":"port"}}`,
+		},
+		{
+			name: "repeated call replaces filter",
+			qb:   capella.NewTaskQuery().Status(capella.TaskActive).Status(capella.TaskCanceled),
+			want: `{"lastStatusCode":["canceled"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := tt.qb.Build()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := json.Marshal(q)
+			if err != nil {
+				t.Fatalf("failed to marshal query: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskQueryBuilder_Invalid(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		qb   *capella.TaskQueryBuilder
+	}{
+		{"nil", nil},
+		{"empty", capella.NewTaskQuery()},
+		{"zero value", &capella.TaskQueryBuilder{}},
+		{"no statuses", capella.NewTaskQuery().Status()},
+		{"no tiers", capella.NewTaskQuery().CollectionTier()},
+		{"no types", capella.NewTaskQuery().CollectionType()},
+		{"open interval both ends", capella.NewTaskQuery().WindowOpenBetween(time.Time{}, time.Time{})},
+		{"reversed interval", capella.NewTaskQuery().WindowOpenBetween(from, from.Add(-time.Hour))},
+		{"zero created after", capella.NewTaskQuery().CreatedAfter(time.Time{})},
+		{"empty org", capella.NewTaskQuery().OrgID("")},
+		{"empty name", capella.NewTaskQuery().Name("")},
+		{"error sticks", capella.NewTaskQuery().Status().OrgID("org-1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.qb.Build(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestTaskingService_SearchTasksQuery(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPost)
+		requirePath(t, r, "/tasks/search")
+
+		body, _ := io.ReadAll(r.Body)
+		want := `{"page":2,"limit":10,"query":{"lastStatusCode":["accepted"],"organizationId":"org-1"}}`
+		if string(body) != want {
+			t.Errorf("unexpected body:\ngot  %s\nwant %s", body, want)
+		}
+
+		jsonResponse(w, http.StatusOK, map[string]any{
+			"results": []map[string]any{
+				{"properties": map[string]any{"taskingrequestId": "tr-1"}},
+			},
+			"currentPage": 2,
+			"totalPages":  2,
+		})
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	qb := capella.NewTaskQuery().Status(capella.TaskAccepted).OrgID("org-1")
+	resp, err := cli.SearchTasksQuery(context.Background(), qb, 2, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(resp.Results))
+	}
+}
+
+func TestTaskingService_SearchTasksQuery_EmptyBuilder(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request expected for an empty query")
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	if _, err := cli.SearchTasksQuery(context.Background(), capella.NewTaskQuery(), 1, 10); err == nil {
+		t.Error("expected error for empty query")
+	}
+
+	var count int
+	for _, err := range cli.SearchTasksQueryIterator(context.Background(), capella.NewTaskQuery(), 10) {
+		count++
+		if err == nil {
+			t.Error("expected error from iterator")
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected a single error, got %d items", count)
+	}
+}
+
+func TestTaskingService_SearchTasksQueryIterator(t *testing.T) {
+	var pages []int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Page  int            `json:"page"`
+			Limit int            `json:"limit"`
+			Query map[string]any `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Query["collectionTier"] == nil {
+			t.Errorf("expected collectionTier in query, got %v", req.Query)
+		}
+		pages = append(pages, req.Page)
+
+		jsonResponse(w, http.StatusOK, map[string]any{
+			"results": []map[string]any{
+				{"properties": map[string]any{"taskingrequestId": fmt.Sprintf("tr-%d", req.Page)}},
+			},
+			"currentPage": req.Page,
+			"totalPages":  2,
+		})
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	var ids []string
+	qb := capella.NewTaskQuery().CollectionTier(capella.TierStandard)
+	for task, err := range cli.SearchTasksQueryIterator(context.Background(), qb, 1) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, task.Properties.TaskingRequestID)
+	}
+
+	if len(ids) != 2 || ids[0] != "tr-1" || ids[1] != "tr-2" {
+		t.Errorf("unexpected IDs: %v", ids)
+	}
+	if len(pages) != 2 {
+		t.Errorf("expected 2 page requests, got %v", pages)
+	}
+}
@@ -0,0 +1,125 @@
+package capella
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Per-request Timeouts
+// ----------------------------------------------------------------------------
+
+// CallOption configures a single API call made with Do or DoRaw.
+type CallOption func(*callConfig)
+
+type callConfig struct {
+	timeout  time.Duration
+	failover bool
+}
+
+// WithRequestTimeout bounds a single call, overriding the client's read or
+// write timeout. The call is still bounded by the HTTP client timeout (see
+// WithTimeout) and by any deadline already set on its context. When the
+// call fails over (see WithBaseURLs), the timeout applies to each attempt.
+func WithRequestTimeout(d time.Duration) CallOption {
+	return func(c *callConfig) {
+		c.timeout = d
+	}
+}
+
+// callConfig returns the configuration of one call: the timeout from opts if
+// set, otherwise the client's read timeout for GET and HEAD requests and its
+// write timeout for the rest.
+func (c *Client) callConfig(method string, opts []CallOption) callConfig {
+	cfg := callConfig{timeout: c.writeTimeout}
+	if method == http.MethodGet || method == http.MethodHead {
+		cfg.timeout = c.readTimeout
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// context derives the context for one request attempt. Without a timeout
+// ctx is returned unchanged. The returned cancel func must always be called.
+func (cfg callConfig) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if cfg.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, cfg.timeout)
+}
+
+// Do performs a JSON request against path, like common.Client.Do, bounded by
+// the per-request timeout (see WithRequestTimeout, WithReadTimeout and
+// WithWriteTimeout).
+func (c *Client) Do(ctx context.Context, method, path string, expectedStatus int, reqBody, respBody any, opts ...CallOption) error {
+	return c.call(ctx, method, c.BuildURL(path), opts, func(ctx context.Context, u *url.URL) error {
+		if !c.guarded(respBody) {
+			return c.Client.DoURL(ctx, method, u, expectedStatus, reqBody, respBody)
+		}
+		var raw []byte
+		if err := c.Client.DoURL(ctx, method, u, expectedStatus, reqBody, &raw); err != nil {
+			return err
+		}
+		return c.decodeGuarded(method, u, raw, respBody)
+	})
+}
+
+// DoRaw performs a request with a raw body, like common.Client.DoRaw, bounded
+// by the per-request timeout. The response is fully decoded before the
+// derived context is cancelled. When the client has fallback base URLs, the
+// body is buffered so it can be sent again.
+func (c *Client) DoRaw(ctx context.Context, method string, u *url.URL, body io.Reader, expectedStatus int, respBody any, opts ...CallOption) error {
+	var buf []byte
+	if body != nil && c.failover != nil {
+		var err error
+		if buf, err = io.ReadAll(body); err != nil {
+			return fmt.Errorf("read request body: %w", err)
+		}
+	}
+	return c.call(ctx, method, u, opts, func(ctx context.Context, u *url.URL) error {
+		if buf != nil {
+			body = bytes.NewReader(buf)
+		}
+		if !c.guarded(respBody) {
+			return c.Client.DoRaw(ctx, method, u, body, expectedStatus, respBody)
+		}
+		var raw []byte
+		if err := c.Client.DoRaw(ctx, method, u, body, expectedStatus, &raw); err != nil {
+			return err
+		}
+		return c.decodeGuarded(method, u, raw, respBody)
+	})
+}
+
+// DoAsync starts an asynchronous operation, like common.Client.DoAsync,
+// bounded by the per-request timeout.
+func (c *Client) DoAsync(ctx context.Context, method string, u *url.URL, reqBody, respBody any, opts ...CallOption) (*url.URL, error) {
+	var loc *url.URL
+	err := c.call(ctx, method, u, opts, func(ctx context.Context, u *url.URL) (err error) {
+		loc, err = c.Client.DoAsync(ctx, method, u, reqBody, respBody)
+		return err
+	})
+	return loc, err
+}
+
+// pollStep wraps a poll function for common.Poll so that a poll which times
+// out on its own per-request deadline is retried at the next interval instead
+// of ending the wait. Only the outer context (and the wait options) bound the
+// whole wait.
+func pollStep(fn func(ctx context.Context) (bool, error)) func(ctx context.Context) (bool, error) {
+	return func(ctx context.Context) (bool, error) {
+		done, err := fn(ctx)
+		if err != nil && errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return false, nil
+		}
+		return done, err
+	}
+}
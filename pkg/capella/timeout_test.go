@@ -0,0 +1,134 @@
+package capella_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+// slowHandler delays every response by delay, or until the request is
+// cancelled.
+func slowHandler(delay time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		jsonResponse(w, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
+
+func newTimeoutClient(t *testing.T, handler http.Handler, opts ...capella.Option) *capella.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	cli, err := capella.NewClient(append([]capella.Option{
+		capella.WithBaseURL(srv.URL),
+		capella.WithAPIKey("test-api-key"),
+		capella.WithTimeout(5 * time.Second),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return cli
+}
+
+func TestDo_WithRequestTimeout(t *testing.T) {
+	cli := newTimeoutClient(t, slowHandler(200*time.Millisecond))
+
+	var out map[string]string
+	err := cli.Do(context.Background(), http.MethodGet, "/slow", 0, nil, &out, capella.WithRequestTimeout(20*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the per-call timeout to fire, got %v", err)
+	}
+
+	// Without the option, only the 5s client timeout applies.
+	if err := cli.Do(context.Background(), http.MethodGet, "/slow", 0, nil, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out["status"] != "ok" {
+		t.Errorf("expected decoded response, got %v", out)
+	}
+}
+
+func TestDoRaw_WithRequestTimeout(t *testing.T) {
+	cli := newTimeoutClient(t, slowHandler(200*time.Millisecond))
+
+	err := cli.DoRaw(context.Background(), http.MethodGet, cli.BuildURL("/slow"), nil, 0, nil, capella.WithRequestTimeout(20*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the per-call timeout to fire, got %v", err)
+	}
+}
+
+func TestClient_ReadWriteTimeouts(t *testing.T) {
+	cli := newTimeoutClient(t, slowHandler(100*time.Millisecond),
+		capella.WithReadTimeout(20*time.Millisecond),
+		capella.WithWriteTimeout(time.Second),
+	)
+	ctx := context.Background()
+
+	if err := cli.Do(ctx, http.MethodGet, "/status", 0, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected GET to use the read timeout, got %v", err)
+	}
+	if err := cli.Do(ctx, http.MethodPost, "/catalog/search", 0, map[string]any{}, nil); err != nil {
+		t.Errorf("expected POST to use the write timeout, got %v", err)
+	}
+	// A call option overrides the class default.
+	if err := cli.Do(ctx, http.MethodGet, "/status", 0, nil, nil, capella.WithRequestTimeout(time.Second)); err != nil {
+		t.Errorf("expected the call option to override the read timeout, got %v", err)
+	}
+}
+
+func TestWaitForAccessRequest_SurvivesPollTimeouts(t *testing.T) {
+	var polls atomic.Int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		n := polls.Add(1)
+		if n <= 2 {
+			// The first two polls hang past the read timeout.
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		status := capella.ProcessingProcessing
+		if n >= 3 {
+			status = capella.ProcessingCompleted
+		}
+		jsonResponse(w, http.StatusOK, capella.AccessRequestResponse{
+			Properties: capella.AccessRequestPropertiesResponse{AccessRequestID: "ar-123", ProcessingStatus: status},
+		})
+	}
+	cli := newTimeoutClient(t, http.HandlerFunc(handler), capella.WithReadTimeout(30*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := cli.WaitForAccessRequest(ctx, "ar-123", 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForAccessRequest failed: %v", err)
+	}
+	if resp.Properties.ProcessingStatus != capella.ProcessingCompleted {
+		t.Errorf("expected status 'completed', got %q", resp.Properties.ProcessingStatus)
+	}
+	if n := polls.Load(); n != 3 {
+		t.Errorf("expected 3 polls, got %d", n)
+	}
+}
+
+func TestWaitForAccessRequest_OuterContextEndsWait(t *testing.T) {
+	cli := newTimeoutClient(t, slowHandler(time.Second), capella.WithReadTimeout(20*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	if _, err := cli.WaitForAccessRequest(ctx, "ar-123", 10*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the outer deadline to end the wait, got %v", err)
+	}
+}
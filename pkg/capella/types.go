@@ -249,3 +249,6 @@ type Link = common.Link
 
 // Asset is an alias for common.Asset.
 type Asset = common.Asset
+
+// WaitOptions is an alias for common.WaitOptions.
+type WaitOptions = common.WaitOptions
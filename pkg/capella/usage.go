@@ -0,0 +1,251 @@
+package capella
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// MaxUsageRange is the longest reporting window accepted by the usage endpoints.
+const MaxUsageRange = 366 * 24 * time.Hour
+
+// ----------------------------------------------------------------------------
+// Usage Types
+// ----------------------------------------------------------------------------
+//
+// The usage endpoints and their payloads are not covered by the vendored
+// Capella specs in spec/; the paths and JSON field names below are
+// unverified against the live API.
+
+// ContractConsumption reports how much of an organization's contract has been used.
+type ContractConsumption struct {
+	ContractID string     `json:"contractId,omitempty"`
+	Allotment  float64    `json:"allotment"`
+	Consumed   float64    `json:"consumed"`
+	Remaining  float64    `json:"remaining"`
+	Currency   string     `json:"currency,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+}
+
+// UsageSummary aggregates an organization's tasking activity over a time range.
+// Its field names are unverified (see Usage Types).
+type UsageSummary struct {
+	OrganizationID      string                 `json:"organizationId"`
+	From                time.Time              `json:"from"`
+	To                  time.Time              `json:"to"`
+	TotalTasks          int                    `json:"totalTasks"`
+	TasksByTier         map[CollectionTier]int `json:"tasksByTier,omitempty"`
+	TasksByType         map[CollectionType]int `json:"tasksByType,omitempty"`
+	TotalAreaSqKm       float64                `json:"totalAreaSqKm"`
+	TotalSpendUSD       float64                `json:"totalSpendUsd"`
+	ContractConsumption *ContractConsumption   `json:"contractConsumption,omitempty"`
+}
+
+// UsageRecord is a per-task usage line item. Its field names are unverified
+// (see Usage Types); the task fields follow the tasking API's spelling.
+type UsageRecord struct {
+	TaskingRequestID   string         `json:"taskingrequestId"`
+	TaskingRequestName string         `json:"taskingrequestName,omitempty"`
+	RepeatRequestID    string         `json:"repeatrequestId,omitempty"`
+	CollectID          string         `json:"collectId,omitempty"`
+	CollectionTier     CollectionTier `json:"collectionTier"`
+	CollectionType     CollectionType `json:"collectionType"`
+	Status             TaskStatus     `json:"status"`
+	AreaSqKm           float64        `json:"areaSqKm"`
+	CostUSD            float64        `json:"costUsd"`
+	SubmissionTime     time.Time      `json:"submissionTime"`
+	CompletedAt        *time.Time     `json:"completedAt,omitempty"`
+}
+
+// ListUsageParams defines parameters for listing usage records.
+type ListUsageParams struct {
+	From           time.Time
+	To             time.Time
+	OrganizationID string
+	Page           int
+	Limit          int
+}
+
+// UsageRecordsPagedResponse is a paginated list of usage records.
+type UsageRecordsPagedResponse struct {
+	Results     []UsageRecord `json:"results"`
+	CurrentPage int           `json:"currentPage"`
+	TotalPages  int           `json:"totalPages"`
+	TotalItems  int           `json:"totalItems,omitempty"`
+}
+
+// ----------------------------------------------------------------------------
+// Usage Operations
+// ----------------------------------------------------------------------------
+
+// GetOrgUsage returns aggregated usage for the caller's organization between from and to.
+//
+// GET /usage/organization is not in the vendored Capella specs and is
+// unverified.
+func (c *Client) GetOrgUsage(ctx context.Context, from, to time.Time) (*UsageSummary, error) {
+	if err := validateUsageRange(from, to); err != nil {
+		return nil, err
+	}
+
+	u := c.BuildURL("/usage/organization")
+	u.RawQuery = usageRangeQuery(from, to).Encode()
+
+	var resp UsageSummary
+	if err := c.DoRaw(ctx, http.MethodGet, u, nil, 0, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// fetchUsagePage fetches a single page of usage records.
+func (c *Client) fetchUsagePage(ctx context.Context, params ListUsageParams) (*UsageRecordsPagedResponse, error) {
+	v := usageRangeQuery(params.From, params.To)
+	if params.OrganizationID != "" {
+		v.Set("organizationId", params.OrganizationID)
+	}
+	if params.Page > 0 {
+		v.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.Limit > 0 {
+		v.Set("limit", strconv.Itoa(params.Limit))
+	}
+
+	u := c.BuildURL("/usage/records/paged")
+	u.RawQuery = v.Encode()
+
+	var resp UsageRecordsPagedResponse
+	if err := c.DoRaw(ctx, http.MethodGet, u, nil, 0, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListUsageRecords returns an iterator over per-task usage line items with automatic pagination.
+//
+// GET /usage/records/paged is not in the vendored Capella specs and is
+// unverified.
+func (c *Client) ListUsageRecords(ctx context.Context, params ListUsageParams) iter.Seq2[UsageRecord, error] {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 25
+	}
+
+	return func(yield func(UsageRecord, error) bool) {
+		if err := validateUsageRange(params.From, params.To); err != nil {
+			yield(UsageRecord{}, err)
+			return
+		}
+
+		page := params.Page
+		for {
+			params.Page = page
+
+			resp, err := c.fetchUsagePage(ctx, params)
+			if err != nil {
+				yield(UsageRecord{}, err)
+				return
+			}
+
+			for _, rec := range resp.Results {
+				if !yield(rec, nil) {
+					return
+				}
+			}
+
+			if page >= resp.TotalPages {
+				return
+			}
+			page++
+		}
+	}
+}
+
+func usageRangeQuery(from, to time.Time) url.Values {
+	v := url.Values{}
+	v.Set("from", from.UTC().Format(time.RFC3339))
+	v.Set("to", to.UTC().Format(time.RFC3339))
+	return v
+}
+
+func validateUsageRange(from, to time.Time) error {
+	if from.IsZero() || to.IsZero() {
+		return errors.New("usage range requires both from and to")
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("usage range start %s must be before end %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	if to.Sub(from) > MaxUsageRange {
+		return fmt.Errorf("usage range of %s exceeds maximum of %s", to.Sub(from), MaxUsageRange)
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// CSV Export
+// ----------------------------------------------------------------------------
+
+// usageCSVHeader lists the flattened columns written by WriteUsageCSV.
+var usageCSVHeader = []string{
+	"tasking_request_id",
+	"tasking_request_name",
+	"repeat_request_id",
+	"collect_id",
+	"collection_tier",
+	"collection_type",
+	"status",
+	"area_sq_km",
+	"cost_usd",
+	"submission_time",
+	"completed_at",
+}
+
+// WriteUsageCSV writes usage records to w as CSV with a header row.
+// Times are formatted as RFC 3339 in UTC; missing values are left empty.
+func WriteUsageCSV(w io.Writer, records iter.Seq2[UsageRecord, error]) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(usageCSVHeader); err != nil {
+		return err
+	}
+
+	for rec, err := range records {
+		if err != nil {
+			return err
+		}
+		completedAt := ""
+		if rec.CompletedAt != nil {
+			completedAt = rec.CompletedAt.UTC().Format(time.RFC3339)
+		}
+		submitted := ""
+		if !rec.SubmissionTime.IsZero() {
+			submitted = rec.SubmissionTime.UTC().Format(time.RFC3339)
+		}
+		row := []string{
+			rec.TaskingRequestID,
+			rec.TaskingRequestName,
+			rec.RepeatRequestID,
+			rec.CollectID,
+			string(rec.CollectionTier),
+			string(rec.CollectionType),
+			string(rec.Status),
+			strconv.FormatFloat(rec.AreaSqKm, 'f', -1, 64),
+			strconv.FormatFloat(rec.CostUSD, 'f', 2, 64),
+			submitted,
+			completedAt,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,202 @@
+package capella_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+const usageSummaryJSON = `{
+	"organizationId": "org-123",
+	"from": "2024-01-01T00:00:00Z",
+	"to": "2024-04-01T00:00:00Z",
+	"totalTasks": 42,
+	"tasksByTier": {"urgent": 2, "priority": 10, "standard": 30},
+	"tasksByType": {"spotlight": 35, "stripmap_20": 7},
+	"totalAreaSqKm": 1234.5,
+	"totalSpendUsd": 98765.43,
+	"contractConsumption": {
+		"contractId": "contract-1",
+		"allotment": 250000,
+		"consumed": 98765.43,
+		"remaining": 151234.57,
+		"currency": "USD"
+	}
+}`
+
+func TestUsageService_GetOrgUsage(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodGet)
+		requirePath(t, r, "/usage/organization")
+		requireAuth(t, r, "test-api-key")
+
+		q := r.URL.Query()
+		if q.Get("from") != "2024-01-01T00:00:00Z" || q.Get("to") != "2024-04-01T00:00:00Z" {
+			t.Errorf("unexpected range query: %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(usageSummaryJSON))
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	usage, err := cli.GetOrgUsage(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if usage.TotalTasks != 42 {
+		t.Errorf("expected 42 tasks, got %d", usage.TotalTasks)
+	}
+	if usage.TasksByTier[capella.TierPriority] != 10 {
+		t.Errorf("expected 10 priority tasks, got %d", usage.TasksByTier[capella.TierPriority])
+	}
+	if usage.TasksByType[capella.CollectionStripmap20] != 7 {
+		t.Errorf("expected 7 stripmap_20 tasks, got %d", usage.TasksByType[capella.CollectionStripmap20])
+	}
+	if usage.TotalAreaSqKm != 1234.5 {
+		t.Errorf("expected area 1234.5, got %v", usage.TotalAreaSqKm)
+	}
+	if usage.ContractConsumption == nil || usage.ContractConsumption.Remaining != 151234.57 {
+		t.Errorf("unexpected contract consumption: %+v", usage.ContractConsumption)
+	}
+}
+
+func TestUsageService_GetOrgUsage_InvalidRange(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request should not be sent for an invalid range")
+	}
+
+	cli, _ := newTestClient(t, handler)
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+	}{
+		{"reversed", now, now.Add(-time.Hour)},
+		{"empty", now, now},
+		{"zero", time.Time{}, now},
+		{"too long", now.Add(-2 * capella.MaxUsageRange), now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cli.GetOrgUsage(context.Background(), tt.from, tt.to); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestUsageService_ListUsageRecords_Pagination(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodGet)
+		requirePath(t, r, "/usage/records/paged")
+
+		var resp capella.UsageRecordsPagedResponse
+		if r.URL.Query().Get("page") == "2" {
+			resp = capella.UsageRecordsPagedResponse{
+				Results:     []capella.UsageRecord{{TaskingRequestID: "tr-3", CostUSD: 300}},
+				CurrentPage: 2,
+				TotalPages:  2,
+			}
+		} else {
+			resp = capella.UsageRecordsPagedResponse{
+				Results: []capella.UsageRecord{
+					{TaskingRequestID: "tr-1", CostUSD: 100},
+					{TaskingRequestID: "tr-2", CostUSD: 200},
+				},
+				CurrentPage: 1,
+				TotalPages:  2,
+			}
+		}
+		jsonResponse(w, http.StatusOK, resp)
+	}
+
+	cli, _ := newTestClient(t, handler)
+
+	params := capella.ListUsageParams{
+		From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	var ids []string
+	for rec, err := range cli.ListUsageRecords(context.Background(), params) {
+		if err != nil {
+			t.Fatalf("iterator error: %v", err)
+		}
+		ids = append(ids, rec.TaskingRequestID)
+	}
+
+	if !slices.Equal(ids, []string{"tr-1", "tr-2", "tr-3"}) {
+		t.Errorf("unexpected records: %v", ids)
+	}
+}
+
+func TestWriteUsageCSV(t *testing.T) {
+	completed := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)
+	records := []capella.UsageRecord{
+		{
+			TaskingRequestID: "tr-1",
+			CollectionTier:   capella.TierStandard,
+			CollectionType:   capella.CollectionSpotlight,
+			Status:           capella.TaskCompleted,
+			AreaSqKm:         25,
+			CostUSD:          1500,
+			SubmissionTime:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			CompletedAt:      &completed,
+		},
+		{TaskingRequestID: "tr-2", Status: capella.TaskActive},
+	}
+
+	var buf bytes.Buffer
+	seq := func(yield func(capella.UsageRecord, error) bool) {
+		for _, r := range records {
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+	if err := capella.WriteUsageCSV(&buf, seq); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected header + 2 rows, got %d", len(rows))
+	}
+	want := []string{"tr-1", "", "", "", "standard", "spotlight", "completed", "25", "1500.00", "2024-01-01T00:00:00Z", "2024-01-03T12:00:00Z"}
+	if !slices.Equal(rows[1], want) {
+		t.Errorf("unexpected row:\n got %v\nwant %v", rows[1], want)
+	}
+	if rows[2][9] != "" || rows[2][10] != "" {
+		t.Errorf("expected empty times for second row, got %v", rows[2])
+	}
+}
+
+func TestUsageRecord_FieldNames(t *testing.T) {
+	// The usage endpoints name task fields like the tasking API does.
+	var rec capella.UsageRecord
+	body := `{"taskingrequestId": "tr-1", "taskingrequestName": "port", "repeatrequestId": "rr-1"}`
+	if err := json.Unmarshal([]byte(body), &rec); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if rec.TaskingRequestID != "tr-1" || rec.TaskingRequestName != "port" || rec.RepeatRequestID != "rr-1" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+}
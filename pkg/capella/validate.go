@@ -0,0 +1,125 @@
+package capella
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/paulmach/orb/geo"
+)
+
+// defaultCollectionTypesTTL is how long ValidateTask reuses the collection
+// types fetched from the API.
+const defaultCollectionTypesTTL = time.Hour
+
+// ----------------------------------------------------------------------------
+// Tasking Request Validation
+// ----------------------------------------------------------------------------
+
+// collectionTypesCache holds the collection types last fetched by
+// ValidateTask. The zero value is ready to use.
+type collectionTypesCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	fetched time.Time
+	types   map[CollectionType]CollectionTypeInfo
+}
+
+// collectionTypes returns the cached collection types keyed by ID, fetching
+// them when the cache is empty or older than its TTL.
+func (c *Client) collectionTypes(ctx context.Context) (map[CollectionType]CollectionTypeInfo, error) {
+	cache := c.typesCache
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	ttl := cache.ttl
+	if ttl <= 0 {
+		ttl = defaultCollectionTypesTTL
+	}
+	if cache.types != nil && time.Since(cache.fetched) < ttl {
+		return cache.types, nil
+	}
+
+	list, err := c.GetCollectionTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection types: %w", err)
+	}
+	types := make(map[CollectionType]CollectionTypeInfo, len(list))
+	for _, ct := range list {
+		types[CollectionType(ct.ID)] = ct
+	}
+	cache.types, cache.fetched = types, time.Now()
+	return types, nil
+}
+
+// ValidateTask checks a tasking request against the limits of its collection
+// type before it is submitted: the AOI area against MinArea and MaxArea (in
+// km², skipped for point geometries), a window that opens before it closes and
+// closes in the future, and off-nadir and grazing angle ranges that are not
+// inverted.
+//
+// Collection types are fetched on first use and cached for an hour (see
+// WithCollectionTypesTTL). All violations are returned together as a single
+// error built with errors.Join; a failure to fetch collection types is
+// returned on its own.
+func (c *Client) ValidateTask(ctx context.Context, req TaskingRequest) error {
+	types, err := c.collectionTypes(ctx)
+	if err != nil {
+		return err
+	}
+
+	p := req.Properties
+	var errs []error
+
+	info, ok := types[p.CollectionType]
+	switch {
+	case p.CollectionType == "":
+		errs = append(errs, errors.New("collectionType is required"))
+	case !ok:
+		errs = append(errs, fmt.Errorf("unknown collectionType %q", p.CollectionType))
+	}
+
+	if req.Geometry == nil || req.Geometry.Geometry() == nil {
+		errs = append(errs, errors.New("geometry is required"))
+	} else if area := geo.Area(req.Geometry.Geometry()) / 1e6; ok && area > 0 {
+		if info.MinArea > 0 && area < info.MinArea {
+			errs = append(errs, fmt.Errorf("AOI area %.2f km² is below the %s minimum of %.2f km²", area, p.CollectionType, info.MinArea))
+		}
+		if info.MaxArea > 0 && area > info.MaxArea {
+			errs = append(errs, fmt.Errorf("AOI area %.2f km² exceeds the %s maximum of %.2f km²", area, p.CollectionType, info.MaxArea))
+		}
+	}
+
+	if !p.WindowOpen.Before(p.WindowClose) {
+		errs = append(errs, fmt.Errorf("windowOpen %s must be before windowClose %s",
+			p.WindowOpen.Format(time.RFC3339), p.WindowClose.Format(time.RFC3339)))
+	}
+	if !p.WindowClose.After(time.Now()) {
+		errs = append(errs, fmt.Errorf("windowClose %s is in the past", p.WindowClose.Format(time.RFC3339)))
+	}
+
+	if cc := p.CollectConstraints; cc != nil {
+		errs = appendRangeError(errs, "offNadir", cc.OffNadirMin, cc.OffNadirMax)
+		errs = appendRangeError(errs, "grazingAngle", cc.GrazingAngleMin, cc.GrazingAngleMax)
+	}
+
+	return errors.Join(errs...)
+}
+
+func appendRangeError(errs []error, name string, min, max *float64) []error {
+	if min != nil && max != nil && *min > *max {
+		return append(errs, fmt.Errorf("%sMin %g is greater than %sMax %g", name, *min, name, *max))
+	}
+	return errs
+}
+
+// CreateTaskValidated runs ValidateTask and submits the request with
+// CreateTask only if it passes.
+func (c *Client) CreateTaskValidated(ctx context.Context, req TaskingRequest) (*TaskingRequestResponse, error) {
+	if err := c.ValidateTask(ctx, req); err != nil {
+		return nil, err
+	}
+	return c.CreateTask(ctx, req)
+}
@@ -0,0 +1,227 @@
+package capella_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+// collectionTypesHandler serves /collectiontypes and counts fetches; any other
+// path is treated as a task submission and counted in creates.
+func collectionTypesHandler(t *testing.T, fetches, creates *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/collectiontypes":
+			fetches.Add(1)
+			jsonResponse(w, http.StatusOK, []capella.CollectionTypeInfo{
+				{ID: "spotlight", Name: "Spotlight", MaxArea: 25},
+				{ID: "stripmap_20", Name: "Stripmap 20", MinArea: 200, MaxArea: 10000},
+			})
+		case "/task":
+			creates.Add(1)
+			jsonResponse(w, http.StatusCreated, map[string]any{
+				"properties": map[string]any{"taskingrequestId": "tr-1"},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+}
+
+// square returns a square polygon of the given side in degrees at the equator.
+func square(side float64) *geojson.Geometry {
+	return geojson.NewGeometry(orb.Polygon{{{0, 0}, {side, 0}, {side, side}, {0, side}, {0, 0}}})
+}
+
+func validTask(ct capella.CollectionType, geom *geojson.Geometry) capella.TaskingRequest {
+	open := time.Now().Add(time.Hour)
+	return capella.TaskingRequest{
+		Geometry: geom,
+		Properties: capella.TaskingRequestProperties{
+			WindowOpen:     open,
+			WindowClose:    open.Add(24 * time.Hour),
+			CollectionType: ct,
+		},
+	}
+}
+
+func TestValidateTask(t *testing.T) {
+	ptr := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name   string
+		modify func(*capella.TaskingRequest)
+		want   []string
+	}{
+		{name: "valid spotlight", modify: func(r *capella.TaskingRequest) {}},
+		{
+			name:   "point geometry skips area check",
+			modify: func(r *capella.TaskingRequest) { r.Geometry = geojson.NewGeometry(orb.Point{10, 10}) },
+		},
+		{
+			name:   "area over limit",
+			modify: func(r *capella.TaskingRequest) { r.Geometry = square(0.1) },
+			want:   []string{"exceeds the spotlight maximum of 25.00"},
+		},
+		{
+			name: "area under limit",
+			modify: func(r *capella.TaskingRequest) {
+				r.Properties.CollectionType = capella.CollectionStripmap20
+				r.Geometry = square(0.1)
+			},
+			want: []string{"is below the stripmap_20 minimum of 200.00"},
+		},
+		{
+			name: "inverted window",
+			modify: func(r *capella.TaskingRequest) {
+				r.Properties.WindowOpen, r.Properties.WindowClose = r.Properties.WindowClose, r.Properties.WindowOpen
+			},
+			want: []string{"must be before windowClose"},
+		},
+		{
+			name: "window in the past",
+			modify: func(r *capella.TaskingRequest) {
+				r.Properties.WindowOpen = time.Now().Add(-48 * time.Hour)
+				r.Properties.WindowClose = time.Now().Add(-24 * time.Hour)
+			},
+			want: []string{"is in the past"},
+		},
+		{
+			name: "inverted angle ranges",
+			modify: func(r *capella.TaskingRequest) {
+				r.Properties.CollectConstraints = &capella.CollectConstraints{
+					OffNadirMin:     ptr(40),
+					OffNadirMax:     ptr(20),
+					GrazingAngleMin: ptr(60),
+					GrazingAngleMax: ptr(30),
+				}
+			},
+			want: []string{"offNadirMin 40 is greater than offNadirMax 20", "grazingAngleMin 60 is greater than grazingAngleMax 30"},
+		},
+		{
+			name:   "unknown collection type",
+			modify: func(r *capella.TaskingRequest) { r.Properties.CollectionType = "sliding_spotlight" },
+			want:   []string{`unknown collectionType "sliding_spotlight"`},
+		},
+		{
+			name: "violations aggregated",
+			modify: func(r *capella.TaskingRequest) {
+				r.Geometry = square(0.1)
+				r.Properties.WindowClose = r.Properties.WindowOpen
+				r.Properties.CollectConstraints = &capella.CollectConstraints{OffNadirMin: ptr(30), OffNadirMax: ptr(10)}
+			},
+			want: []string{"exceeds the spotlight maximum", "must be before windowClose", "offNadirMin 30"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetches, creates atomic.Int32
+			cli, _ := newTestClient(t, collectionTypesHandler(t, &fetches, &creates))
+
+			req := validTask(capella.CollectionSpotlight, square(0.01))
+			tt.modify(&req)
+
+			err := cli.ValidateTask(context.Background(), req)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected validation error")
+			}
+			joined, ok := err.(interface{ Unwrap() []error })
+			if !ok || len(joined.Unwrap()) != len(tt.want) {
+				t.Fatalf("expected %d violations, got: %v", len(tt.want), err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTask_CachesCollectionTypes(t *testing.T) {
+	var fetches, creates atomic.Int32
+	cli, _ := newTestClient(t, collectionTypesHandler(t, &fetches, &creates))
+
+	req := validTask(capella.CollectionSpotlight, square(0.01))
+	for range 3 {
+		if err := cli.ValidateTask(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("expected collection types to be fetched once, got %d", n)
+	}
+}
+
+func TestValidateTask_CacheExpires(t *testing.T) {
+	var fetches, creates atomic.Int32
+	_, srv := newTestClient(t, collectionTypesHandler(t, &fetches, &creates))
+	cli, err := capella.NewClient(
+		capella.WithBaseURL(srv.URL),
+		capella.WithAPIKey("test-api-key"),
+		capella.WithCollectionTypesTTL(time.Nanosecond),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := validTask(capella.CollectionSpotlight, square(0.01))
+	for range 2 {
+		time.Sleep(time.Millisecond)
+		if err := cli.ValidateTask(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("expected an expired cache to refetch, got %d fetches", n)
+	}
+}
+
+func TestCreateTaskValidated(t *testing.T) {
+	var fetches, creates atomic.Int32
+	cli, _ := newTestClient(t, collectionTypesHandler(t, &fetches, &creates))
+
+	bad := validTask(capella.CollectionSpotlight, square(0.1))
+	if _, err := cli.CreateTaskValidated(context.Background(), bad); err == nil {
+		t.Fatal("expected validation error")
+	}
+	if creates.Load() != 0 {
+		t.Fatal("invalid task must not be submitted")
+	}
+
+	resp, err := cli.CreateTaskValidated(context.Background(), validTask(capella.CollectionSpotlight, square(0.01)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Properties.TaskingRequestID != "tr-1" || creates.Load() != 1 {
+		t.Errorf("expected one submission, got %+v after %d creates", resp, creates.Load())
+	}
+}
+
+func TestValidateTask_CollectionTypesError(t *testing.T) {
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{"message": "boom"})
+	})
+
+	err := cli.ValidateTask(context.Background(), validTask(capella.CollectionSpotlight, square(0.01)))
+	var apiErr *capella.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected API error, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package capella
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Access Window Selection
+// ----------------------------------------------------------------------------
+
+// AccessWindowPredicate reports whether an access window should be kept.
+type AccessWindowPredicate func(AccessWindow) bool
+
+// FilterAccessWindows returns the windows for which every predicate returns
+// true, preserving order.
+func FilterAccessWindows(ws []AccessWindow, preds ...func(AccessWindow) bool) []AccessWindow {
+	var out []AccessWindow
+	for _, w := range ws {
+		if !slices.ContainsFunc(preds, func(p func(AccessWindow) bool) bool { return !p(w) }) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// OffNadirBetween keeps windows whose off-nadir angle lies in [min, max]
+// degrees.
+func OffNadirBetween(min, max float64) AccessWindowPredicate {
+	return func(w AccessWindow) bool {
+		return w.OffNadir >= min && w.OffNadir <= max
+	}
+}
+
+// LookDirectionIs keeps windows with look direction d. LookEither keeps all
+// windows.
+func LookDirectionIs(d LookDirection) AccessWindowPredicate {
+	return func(w AccessWindow) bool {
+		return d == LookEither || strings.EqualFold(w.LookDirection, string(d))
+	}
+}
+
+// AscDescIs keeps windows on orbit state d. OrbitEither keeps all windows.
+func AscDescIs(d OrbitState) AccessWindowPredicate {
+	return func(w AccessWindow) bool {
+		return d == OrbitEither || strings.EqualFold(w.AscDesc, string(d))
+	}
+}
+
+// WindowAfter keeps windows that open at or after t.
+func WindowAfter(t time.Time) AccessWindowPredicate {
+	return func(w AccessWindow) bool {
+		return !w.WindowOpen.Before(t)
+	}
+}
+
+// OrbitalPlaneIn keeps windows on one of the given orbital planes.
+func OrbitalPlaneIn(planes ...string) AccessWindowPredicate {
+	return func(w AccessWindow) bool {
+		return slices.Contains(planes, w.OrbitalPlane)
+	}
+}
+
+// RankAccessWindows returns a copy of ws sorted by descending score. Windows
+// with equal scores keep their original relative order.
+func RankAccessWindows(ws []AccessWindow, scorer func(AccessWindow) float64) []AccessWindow {
+	type scored struct {
+		w     AccessWindow
+		score float64
+	}
+	s := make([]scored, len(ws))
+	for i, w := range ws {
+		s[i] = scored{w, scorer(w)}
+	}
+	slices.SortStableFunc(s, func(a, b scored) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		default:
+			return 0
+		}
+	})
+	out := make([]AccessWindow, len(s))
+	for i := range s {
+		out[i] = s[i].w
+	}
+	return out
+}
+
+// DefaultAccessWindowScorer returns a scorer for windows drawn from ws.
+// Scores lie in [0, 1], higher is better. It weighs equally how close a
+// window's off-nadir angle is to the middle of the range seen in ws and how
+// early the window opens relative to the others.
+func DefaultAccessWindowScorer(ws []AccessWindow) func(AccessWindow) float64 {
+	var lo, hi float64
+	var first, last time.Time
+	for i, w := range ws {
+		if i == 0 {
+			lo, hi = w.OffNadir, w.OffNadir
+			first, last = w.WindowOpen, w.WindowOpen
+			continue
+		}
+		lo, hi = min(lo, w.OffNadir), max(hi, w.OffNadir)
+		if w.WindowOpen.Before(first) {
+			first = w.WindowOpen
+		}
+		if w.WindowOpen.After(last) {
+			last = w.WindowOpen
+		}
+	}
+	mid, half := (lo+hi)/2, (hi-lo)/2
+	span := last.Sub(first)
+
+	return func(w AccessWindow) float64 {
+		var angle, lateness float64
+		if half > 0 {
+			angle = min(math.Abs(w.OffNadir-mid)/half, 1)
+		}
+		if span > 0 {
+			lateness = min(max(float64(w.WindowOpen.Sub(first))/float64(span), 0), 1)
+		}
+		return 1 - (angle+lateness)/2
+	}
+}
+
+// EarliestWindow returns the window that opens first. Ties go to the window
+// listed first; ok is false if ws is empty.
+func EarliestWindow(ws []AccessWindow) (w AccessWindow, ok bool) {
+	if len(ws) == 0 {
+		return AccessWindow{}, false
+	}
+	w = ws[0]
+	for _, c := range ws[1:] {
+		if c.WindowOpen.Before(w.WindowOpen) {
+			w = c
+		}
+	}
+	return w, true
+}
+
+// NewTaskWindowFromAccessWindow returns the window to request in a tasking
+// request for access window w, widened by padding on each side to absorb
+// small differences between the access computation and tasking. Negative
+// padding is treated as zero.
+func NewTaskWindowFromAccessWindow(w AccessWindow, padding time.Duration) (windowOpen, windowClose time.Time) {
+	padding = max(padding, 0)
+	return w.WindowOpen.Add(-padding), w.WindowClose.Add(padding)
+}
@@ -0,0 +1,132 @@
+package capella_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/capella"
+)
+
+var accessBase = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func testAccessWindows() []capella.AccessWindow {
+	return []capella.AccessWindow{
+		{OrbitalPlane: "45", LookDirection: "right", AscDesc: "ascending", OffNadir: 10, WindowOpen: accessBase.Add(1 * time.Hour), WindowClose: accessBase.Add(1*time.Hour + time.Minute)},
+		{OrbitalPlane: "53", LookDirection: "left", AscDesc: "descending", OffNadir: 25, WindowOpen: accessBase.Add(2 * time.Hour), WindowClose: accessBase.Add(2*time.Hour + time.Minute)},
+		{OrbitalPlane: "97", LookDirection: "right", AscDesc: "descending", OffNadir: 40, WindowOpen: accessBase.Add(3 * time.Hour), WindowClose: accessBase.Add(3*time.Hour + time.Minute)},
+		{OrbitalPlane: "45", LookDirection: "Right", AscDesc: "ascending", OffNadir: 25, WindowOpen: accessBase.Add(5 * time.Hour), WindowClose: accessBase.Add(5*time.Hour + time.Minute)},
+	}
+}
+
+func accessPlanes(ws []capella.AccessWindow) []string {
+	planes := make([]string, len(ws))
+	for i, w := range ws {
+		planes[i] = w.OrbitalPlane + "@" + w.WindowOpen.Sub(accessBase).String()
+	}
+	return planes
+}
+
+func TestFilterAccessWindows(t *testing.T) {
+	tests := []struct {
+		name  string
+		preds []func(capella.AccessWindow) bool
+		want  []string
+	}{
+		{"no predicates", nil, []string{"45@1h0m0s", "53@2h0m0s", "97@3h0m0s", "45@5h0m0s"}},
+		{"off-nadir inclusive", []func(capella.AccessWindow) bool{capella.OffNadirBetween(25, 40)}, []string{"53@2h0m0s", "97@3h0m0s", "45@5h0m0s"}},
+		{"look direction ignores case", []func(capella.AccessWindow) bool{capella.LookDirectionIs(capella.LookRight)}, []string{"45@1h0m0s", "97@3h0m0s", "45@5h0m0s"}},
+		{"look either", []func(capella.AccessWindow) bool{capella.LookDirectionIs(capella.LookEither)}, []string{"45@1h0m0s", "53@2h0m0s", "97@3h0m0s", "45@5h0m0s"}},
+		{"descending", []func(capella.AccessWindow) bool{capella.AscDescIs(capella.OrbitDescending)}, []string{"53@2h0m0s", "97@3h0m0s"}},
+		{"after is inclusive", []func(capella.AccessWindow) bool{capella.WindowAfter(accessBase.Add(3 * time.Hour))}, []string{"97@3h0m0s", "45@5h0m0s"}},
+		{"orbital planes", []func(capella.AccessWindow) bool{capella.OrbitalPlaneIn("53", "97")}, []string{"53@2h0m0s", "97@3h0m0s"}},
+		{"combined", []func(capella.AccessWindow) bool{capella.OrbitalPlaneIn("45"), capella.OffNadirBetween(20, 30)}, []string{"45@5h0m0s"}},
+		{"none match", []func(capella.AccessWindow) bool{capella.OrbitalPlaneIn("nope")}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := capella.FilterAccessWindows(testAccessWindows(), tt.preds...)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if planes := accessPlanes(got); !slices.Equal(planes, tt.want) {
+				t.Errorf("got %v, want %v", planes, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankAccessWindows(t *testing.T) {
+	ws := testAccessWindows()
+	scorer := capella.DefaultAccessWindowScorer(ws)
+
+	// Off-nadir 10..40 puts the middle at 25; opens span 1h..5h.
+	if s := scorer(ws[1]); s != 1-(0+0.25)/2 {
+		t.Errorf("mid off-nadir score = %v", s)
+	}
+	if s := scorer(ws[0]); s != 0.5 {
+		t.Errorf("edge off-nadir, earliest score = %v", s)
+	}
+
+	got := accessPlanes(capella.RankAccessWindows(ws, scorer))
+	if want := []string{"53@2h0m0s", "45@1h0m0s", "45@5h0m0s", "97@3h0m0s"}; !slices.Equal(got, want) {
+		t.Errorf("ranked %v, want %v", got, want)
+	}
+	if !slices.Equal(accessPlanes(ws), accessPlanes(testAccessWindows())) {
+		t.Error("RankAccessWindows modified its input")
+	}
+}
+
+func TestRankAccessWindows_TiesAreStable(t *testing.T) {
+	ws := testAccessWindows()
+	constant := func(capella.AccessWindow) float64 { return 1 }
+	for range 5 {
+		if got := capella.RankAccessWindows(ws, constant); !slices.Equal(accessPlanes(got), accessPlanes(ws)) {
+			t.Fatalf("tied windows reordered: %v", accessPlanes(got))
+		}
+	}
+
+	// Windows identical in every scored field keep input order too.
+	same := []capella.AccessWindow{
+		{OrbitalPlane: "b", OffNadir: 20, WindowOpen: accessBase},
+		{OrbitalPlane: "a", OffNadir: 20, WindowOpen: accessBase},
+	}
+	got := capella.RankAccessWindows(same, capella.DefaultAccessWindowScorer(same))
+	if got[0].OrbitalPlane != "b" || got[1].OrbitalPlane != "a" {
+		t.Errorf("got %v", accessPlanes(got))
+	}
+}
+
+func TestEarliestWindow(t *testing.T) {
+	if _, ok := capella.EarliestWindow(nil); ok {
+		t.Error("expected no window for an empty set")
+	}
+	ws := testAccessWindows()
+	slices.Reverse(ws)
+	w, ok := capella.EarliestWindow(ws)
+	if !ok || !w.WindowOpen.Equal(accessBase.Add(time.Hour)) {
+		t.Errorf("got %+v, %v", w, ok)
+	}
+}
+
+func TestNewTaskWindowFromAccessWindow(t *testing.T) {
+	w := capella.AccessWindow{WindowOpen: accessBase, WindowClose: accessBase.Add(90 * time.Second)}
+	tests := []struct {
+		name      string
+		padding   time.Duration
+		wantOpen  time.Time
+		wantClose time.Time
+	}{
+		{"no padding", 0, accessBase, accessBase.Add(90 * time.Second)},
+		{"padded", 5 * time.Minute, accessBase.Add(-5 * time.Minute), accessBase.Add(6*time.Minute + 30*time.Second)},
+		{"negative is zero", -time.Minute, accessBase, accessBase.Add(90 * time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, close := capella.NewTaskWindowFromAccessWindow(w, tt.padding)
+			if !open.Equal(tt.wantOpen) || !close.Equal(tt.wantClose) {
+				t.Errorf("got [%v, %v], want [%v, %v]", open, close, tt.wantOpen, tt.wantClose)
+			}
+		})
+	}
+}
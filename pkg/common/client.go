@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -39,6 +40,10 @@ type ClientConfig struct {
 	Auth       Authenticator
 	UserAgent  string
 	Timeout    time.Duration
+
+	// ErrorParser converts a non-2xx response into an error. Defaults to
+	// ParseErrorResponse.
+	ErrorParser func(*http.Response) error
 }
 
 // Client is a base HTTP client for API requests.
@@ -47,6 +52,7 @@ type Client struct {
 	httpClient *http.Client
 	auth       Authenticator
 	userAgent  string
+	parseError func(*http.Response) error
 }
 
 // NewClient creates a new HTTP client with the given configuration.
@@ -70,6 +76,7 @@ func NewClient(cfg ClientConfig) (*Client, error) {
 		httpClient: httpClient,
 		auth:       cfg.Auth,
 		userAgent:  cfg.UserAgent,
+		parseError: cfg.ErrorParser,
 	}, nil
 }
 
@@ -124,17 +131,93 @@ func (c *Client) DoURL(ctx context.Context, method string, u *url.URL, expectedS
 	return c.DoRaw(ctx, method, u, body, expectedStatus, respBody)
 }
 
-// DoRaw performs an HTTP request with a raw body reader.
+// DoRaw performs an HTTP request with a raw body reader. A *[]byte respBody
+// receives the response body undecoded.
 func (c *Client) DoRaw(ctx context.Context, method string, u *url.URL, body io.Reader, expectedStatus int, respBody any) error {
+	resp, err := c.send(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Check for expected status (0 means accept any 2xx)
+	if expectedStatus == 0 {
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return c.errorFromResponse(resp)
+		}
+	} else if resp.StatusCode != expectedStatus {
+		return c.errorFromResponse(resp)
+	}
+
+	// Decode response body
+	if raw, ok := respBody.(*[]byte); ok {
+		if *raw, err = io.ReadAll(resp.Body); err != nil {
+			return fmt.Errorf("read response: %w", err)
+		}
+		return nil
+	}
+	if respBody != nil {
+		if err := json.NewDecoder(resp.Body).Decode(respBody); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// DoAsync starts an asynchronous operation: it sends reqBody as JSON and
+// expects 202 Accepted with a Location header naming the resource to poll.
+// The Location is resolved against u. If respBody is non-nil and the response
+// has a body, it is decoded into respBody. A 202 without a Location returns
+// ErrNoLocation.
+func (c *Client) DoAsync(ctx context.Context, method string, u *url.URL, reqBody, respBody any) (*url.URL, error) {
+	var body io.Reader
+	if reqBody != nil {
+		b, err := json.Marshal(reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request body: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	resp, err := c.send(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		return nil, c.errorFromResponse(resp)
+	}
+	loc := resp.Header.Get("Location")
+	if loc == "" {
+		return nil, ErrNoLocation
+	}
+	location, err := u.Parse(loc)
+	if err != nil {
+		return nil, fmt.Errorf("parse location %q: %w", loc, err)
+	}
+
+	if respBody != nil {
+		if err := json.NewDecoder(resp.Body).Decode(respBody); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return location, nil
+}
+
+// send builds an authenticated JSON request and performs it. The caller must
+// close the response body.
+func (c *Client) send(ctx context.Context, method string, u *url.URL, body io.Reader) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
 	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	// Apply authentication
 	if c.auth != nil {
 		if err := c.auth.Apply(ctx, req); err != nil {
-			return fmt.Errorf("authenticate: %w", err)
+			return nil, fmt.Errorf("authenticate: %w", err)
 		}
 	}
 
@@ -149,27 +232,9 @@ func (c *Client) DoRaw(ctx context.Context, method string, u *url.URL, body io.R
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check for expected status (0 means accept any 2xx)
-	if expectedStatus == 0 {
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			return ParseErrorResponse(resp)
-		}
-	} else if resp.StatusCode != expectedStatus {
-		return ParseErrorResponse(resp)
-	}
-
-	// Decode response body
-	if respBody != nil {
-		if err := json.NewDecoder(resp.Body).Decode(respBody); err != nil {
-			return fmt.Errorf("decode response: %w", err)
-		}
+		return nil, fmt.Errorf("do request: %w", err)
 	}
-
-	return nil
+	return resp, nil
 }
 
 // DoRawResponse performs an HTTP request and returns the raw response body.
@@ -203,6 +268,11 @@ func (c *Client) DoRawResponse(ctx context.Context, method string, u *url.URL, b
 	}
 
 	// Check for expected status
+	if c.parseError != nil && (expectedStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode >= 300) ||
+		expectedStatus != 0 && resp.StatusCode != expectedStatus) {
+		resp.Body = io.NopCloser(bytes.NewReader(buf))
+		return nil, c.parseError(resp)
+	}
 	if expectedStatus == 0 {
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			apiErr := ParseErrorResponse(resp)
@@ -221,6 +291,15 @@ func (c *Client) DoRawResponse(ctx context.Context, method string, u *url.URL, b
 	return buf, nil
 }
 
+// errorFromResponse converts a non-2xx response using the configured
+// ErrorParser, falling back to ParseErrorResponse.
+func (c *Client) errorFromResponse(resp *http.Response) error {
+	if c.parseError != nil {
+		return c.parseError(resp)
+	}
+	return ParseErrorResponse(resp)
+}
+
 // NewRequest creates a new HTTP request with authentication headers.
 func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
 	u := c.BuildURL(path)
@@ -9,6 +9,10 @@ import (
 	"net/http"
 )
 
+// ErrNoLocation is returned by DoAsync when a 202 Accepted response has no
+// Location header to poll.
+var ErrNoLocation = errors.New("202 Accepted response has no Location header")
+
 // APIError represents a standard API error response.
 type APIError struct {
 	// StatusCode is the HTTP status code.
@@ -25,9 +29,21 @@ type APIError struct {
 
 	// RawBody contains the raw response body for debugging.
 	RawBody string `json:"-"`
+
+	// RequestID is the server's request or trace ID for the failed call, if
+	// the response carried one. Vendor support teams ask for it.
+	RequestID string `json:"-"`
 }
 
 func (e *APIError) Error() string {
+	msg := e.message()
+	if e.RequestID != "" {
+		msg += " [request-id " + e.RequestID + "]"
+	}
+	return msg
+}
+
+func (e *APIError) message() string {
 	if e.Code != "" && e.Message != "" {
 		return fmt.Sprintf("%s (%d): %s", e.Code, e.StatusCode, e.Message)
 	}
@@ -40,6 +56,21 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, http.StatusText(e.StatusCode))
 }
 
+// requestIDHeaders are the response headers checked, in order, for a server
+// request or trace ID.
+var requestIDHeaders = []string{"X-Request-Id", "X-Amzn-Requestid", "X-Amzn-Trace-Id", "X-Trace-Id"}
+
+// RequestIDFromHeader returns the server request or trace ID found in h, or
+// "" if there is none.
+func RequestIDFromHeader(h http.Header) string {
+	for _, name := range requestIDHeaders {
+		if v := h.Get(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 // IsNotFound returns true if the error is a 404 Not Found error.
 func IsNotFound(err error) bool {
 	var apiErr *APIError
@@ -129,6 +160,7 @@ func ParseErrorResponse(resp *http.Response) *APIError {
 		StatusCode: resp.StatusCode,
 		Code:       http.StatusText(resp.StatusCode),
 		RawBody:    string(body),
+		RequestID:  RequestIDFromHeader(resp.Header),
 	}
 
 	// Try to parse as JSON error response
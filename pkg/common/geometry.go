@@ -0,0 +1,414 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/wkt"
+	"github.com/paulmach/orb/geo"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/planar"
+)
+
+// ErrEmptyGeometry is returned by the geometry helpers for a nil or empty
+// geometry.
+var ErrEmptyGeometry = errors.New("geometry is empty")
+
+// ErrNotConvex is returned by Difference for a subtrahend that is not made
+// of convex polygons.
+var ErrNotConvex = errors.New("geometry is not convex")
+
+// orbGeometry returns the orb geometry of g, or ErrEmptyGeometry.
+func orbGeometry(g *geojson.Geometry) (orb.Geometry, error) {
+	if g == nil || g.Geometry() == nil {
+		return nil, ErrEmptyGeometry
+	}
+	return g.Geometry(), nil
+}
+
+// Centroid returns the planar centroid of g in lon/lat. Footprints and AOIs
+// are small enough for the planar centroid to be a good approximation.
+func Centroid(g *geojson.Geometry) (lon, lat float64, err error) {
+	og, err := orbGeometry(g)
+	if err != nil {
+		return 0, 0, err
+	}
+	c, _ := planar.CentroidArea(og)
+	return c.Lon(), c.Lat(), nil
+}
+
+// AreaSqKm returns the geodesic area of g in square kilometres. Points and
+// lines have no area.
+func AreaSqKm(g *geojson.Geometry) (float64, error) {
+	og, err := orbGeometry(g)
+	if err != nil {
+		return 0, err
+	}
+	return geo.Area(og) / 1e6, nil
+}
+
+// GeometryToWKT encodes g as Well-Known Text.
+func GeometryToWKT(g *geojson.Geometry) (string, error) {
+	og, err := orbGeometry(g)
+	if err != nil {
+		return "", err
+	}
+	return wkt.MarshalString(og), nil
+}
+
+// WKTToGeometry parses Well-Known Text such as POINT, POLYGON or
+// MULTIPOLYGON into a GeoJSON geometry.
+func WKTToGeometry(s string) (*geojson.Geometry, error) {
+	og, err := wkt.Unmarshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("parse WKT: %w", err)
+	}
+	return geojson.NewGeometry(og), nil
+}
+
+// polygons returns the polygons of a polygonal geometry. Bounds are treated
+// as polygons; other geometry types have none.
+func polygons(g orb.Geometry) orb.MultiPolygon {
+	switch g := g.(type) {
+	case orb.Polygon:
+		return orb.MultiPolygon{g}
+	case orb.MultiPolygon:
+		return g
+	case orb.Bound:
+		return orb.MultiPolygon{g.ToPolygon()}
+	}
+	return nil
+}
+
+// points returns the points of a point geometry.
+func points(g orb.Geometry) []orb.Point {
+	switch g := g.(type) {
+	case orb.Point:
+		return []orb.Point{g}
+	case orb.MultiPoint:
+		return g
+	}
+	return nil
+}
+
+// Intersects reports whether a and b share at least one point. Point,
+// MultiPoint, Polygon and MultiPolygon geometries are supported; any other
+// type never intersects.
+func Intersects(a, b *geojson.Geometry) bool {
+	ga, err := orbGeometry(a)
+	if err != nil {
+		return false
+	}
+	gb, err := orbGeometry(b)
+	if err != nil {
+		return false
+	}
+	if !ga.Bound().Intersects(gb.Bound()) {
+		return false
+	}
+
+	pa, pb := polygons(ga), polygons(gb)
+	switch {
+	case pa != nil && pb != nil:
+		return polygonsIntersect(pa, pb)
+	case pa != nil:
+		return anyContained(pa, points(gb))
+	case pb != nil:
+		return anyContained(pb, points(ga))
+	}
+	for _, p := range points(ga) {
+		for _, q := range points(gb) {
+			if p.Equal(q) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func anyContained(mp orb.MultiPolygon, pts []orb.Point) bool {
+	for _, p := range pts {
+		if planar.MultiPolygonContains(mp, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// polygonsIntersect reports whether two multipolygons intersect: either one
+// has a vertex inside the other or two of their edges cross.
+func polygonsIntersect(a, b orb.MultiPolygon) bool {
+	for _, pa := range a {
+		for _, pb := range b {
+			if len(pa) == 0 || len(pb) == 0 || len(pa[0]) == 0 || len(pb[0]) == 0 {
+				continue
+			}
+			if planar.PolygonContains(pb, pa[0][0]) || planar.PolygonContains(pa, pb[0][0]) {
+				return true
+			}
+			for _, ra := range pa {
+				for _, rb := range pb {
+					if ringsCross(ra, rb) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func ringsCross(a, b orb.Ring) bool {
+	for i := 1; i < len(a); i++ {
+		for j := 1; j < len(b); j++ {
+			if segmentsIntersect(a[i-1], a[i], b[j-1], b[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cross returns the z component of (b-a) x (c-a): positive when c is to the
+// left of a→b.
+func cross(a, b, c orb.Point) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+func segmentsIntersect(p1, p2, q1, q2 orb.Point) bool {
+	d1, d2 := cross(q1, q2, p1), cross(q1, q2, p2)
+	d3, d4 := cross(p1, p2, q1), cross(p1, p2, q2)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	onSegment := func(a, b, p orb.Point) bool {
+		return min(a[0], b[0]) <= p[0] && p[0] <= max(a[0], b[0]) &&
+			min(a[1], b[1]) <= p[1] && p[1] <= max(a[1], b[1])
+	}
+	return (d1 == 0 && onSegment(q1, q2, p1)) || (d2 == 0 && onSegment(q1, q2, p2)) ||
+		(d3 == 0 && onSegment(p1, p2, q1)) || (d4 == 0 && onSegment(p1, p2, q2))
+}
+
+// coverageSamples is the number of grid cells per axis used to estimate
+// coverage when the footprint is not convex.
+const coverageSamples = 200
+
+// CoveragePercent estimates how much of aoi, in percent of its area, is
+// covered by footprint. Both must be polygonal; otherwise it returns 0.
+//
+// Footprints made of convex polygons without holes, which covers SAR scene
+// footprints, are measured exactly by subtracting them from the AOI, so
+// overlapping polygons count once. Other footprints are sampled on a grid
+// over the AOI, accurate to about a percent.
+func CoveragePercent(aoi, footprint *geojson.Geometry) float64 {
+	ga, err := orbGeometry(aoi)
+	if err != nil {
+		return 0
+	}
+	gf, err := orbGeometry(footprint)
+	if err != nil {
+		return 0
+	}
+	pa, pf := polygons(ga), polygons(gf)
+	if pa == nil || pf == nil || !ga.Bound().Intersects(gf.Bound()) {
+		return 0
+	}
+	total := geo.Area(pa)
+	if total <= 0 {
+		return 0
+	}
+
+	var covered float64
+	if convexPolygons(pf) {
+		covered = 100 * (total - geo.Area(difference(pa, pf))) / total
+	} else {
+		covered = sampleCoverage(pa, pf)
+	}
+	return min(max(covered, 0), 100)
+}
+
+// clipHalfPlane clips subject to the half-plane left of the line a→b, or
+// right of it if left is false. The result is closed, or empty.
+func clipHalfPlane(subject orb.Ring, a, b orb.Point, left bool) orb.Ring {
+	inside := func(p orb.Point) bool {
+		if left {
+			return cross(a, b, p) >= 0
+		}
+		return cross(a, b, p) <= 0
+	}
+	var out orb.Ring
+	for j := range subject {
+		cur, prev := subject[j], subject[(j+len(subject)-1)%len(subject)]
+		curIn, prevIn := inside(cur), inside(prev)
+		if curIn != prevIn {
+			out = append(out, lineIntersection(prev, cur, a, b))
+		}
+		if curIn {
+			out = append(out, cur)
+		}
+	}
+	if len(out) > 0 && !out[0].Equal(out[len(out)-1]) {
+		out = append(out, out[0])
+	}
+	return out
+}
+
+// counterclockwise returns r wound counterclockwise, so its interior is to
+// the left of each edge.
+func counterclockwise(r orb.Ring) orb.Ring {
+	if r.Orientation() == orb.CW {
+		r = r.Clone()
+		r.Reverse()
+	}
+	return r
+}
+
+// lineIntersection returns the point where segment p1→p2 crosses the line
+// through a and b.
+func lineIntersection(p1, p2, a, b orb.Point) orb.Point {
+	d1, d2 := cross(a, b, p1), cross(a, b, p2)
+	t := d1 / (d1 - d2)
+	return orb.Point{p1[0] + t*(p2[0]-p1[0]), p1[1] + t*(p2[1]-p1[1])}
+}
+
+// Difference returns the part of a not covered by b, or nil if nothing
+// remains. a must be polygonal and b made of convex polygons without holes,
+// which covers SAR scene footprints; ErrNotConvex is returned otherwise.
+//
+// The remainder is built from the pieces of a outside each edge of b, so it
+// may be split into more polygons than strictly necessary.
+func Difference(a, b *geojson.Geometry) (*geojson.Geometry, error) {
+	ga, err := orbGeometry(a)
+	if err != nil {
+		return nil, err
+	}
+	gb, err := orbGeometry(b)
+	if err != nil {
+		return nil, err
+	}
+	pa, pb := polygons(ga), polygons(gb)
+	if pa == nil {
+		return nil, fmt.Errorf("difference of %s geometry: not polygonal", ga.GeoJSONType())
+	}
+	if pb == nil || !convexPolygons(pb) {
+		return nil, ErrNotConvex
+	}
+
+	rest := difference(pa, pb)
+	switch len(rest) {
+	case 0:
+		return nil, nil
+	case 1:
+		return geojson.NewGeometry(rest[0]), nil
+	}
+	return geojson.NewGeometry(rest), nil
+}
+
+// difference returns the pieces of a outside the convex polygons of b.
+// Subtracting each polygon of b from what is left of a counts overlaps
+// between them once.
+func difference(a, b orb.MultiPolygon) orb.MultiPolygon {
+	rest := a
+	for _, clip := range b {
+		var next orb.MultiPolygon
+		for _, p := range rest {
+			next = append(next, subtractConvex(p, clip[0])...)
+		}
+		rest = next
+	}
+	return rest
+}
+
+// subtractConvex returns the pieces of p outside the convex ring clip: for
+// each edge of clip, the part of p beyond that edge but within the edges
+// already visited.
+func subtractConvex(p orb.Polygon, clip orb.Ring) orb.MultiPolygon {
+	if len(p) == 0 || !p.Bound().Intersects(clip.Bound()) {
+		return orb.MultiPolygon{p}
+	}
+	clip = counterclockwise(clip)
+	var pieces orb.MultiPolygon
+	rest := p
+	for i := 1; i < len(clip) && rest != nil; i++ {
+		a, b := clip[i-1], clip[i]
+		if piece := clipPolygonHalfPlane(rest, a, b, false); piece != nil {
+			pieces = append(pieces, piece)
+		}
+		rest = clipPolygonHalfPlane(rest, a, b, true)
+	}
+	return pieces
+}
+
+// clipPolygonHalfPlane clips p's exterior and holes to a half-plane, as
+// clipHalfPlane does. It returns nil if no area is left.
+func clipPolygonHalfPlane(p orb.Polygon, a, b orb.Point, left bool) orb.Polygon {
+	var out orb.Polygon
+	for i, r := range p {
+		c := clipHalfPlane(r, a, b, left)
+		if len(c) < 4 || planar.Area(c) == 0 {
+			if i == 0 {
+				return nil
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// convexPolygons reports whether every polygon of mp is a convex ring
+// without holes.
+func convexPolygons(mp orb.MultiPolygon) bool {
+	for _, p := range mp {
+		if len(p) != 1 || !convexRing(p[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+func convexRing(r orb.Ring) bool {
+	if len(r) < 4 {
+		return false
+	}
+	var sign float64
+	n := len(r) - 1 // closing point repeats the first
+	for i := range n {
+		c := cross(r[i], r[(i+1)%n], r[(i+2)%n])
+		switch {
+		case c == 0:
+			continue
+		case sign == 0:
+			sign = c
+		case (c > 0) != (sign > 0):
+			return false
+		}
+	}
+	return sign != 0
+}
+
+// sampleCoverage estimates the percentage of aoi covered by footprint by
+// testing the centres of a grid over the AOI's bounding box.
+func sampleCoverage(aoi, footprint orb.MultiPolygon) float64 {
+	b := aoi.Bound()
+	dx := (b.Max[0] - b.Min[0]) / coverageSamples
+	dy := (b.Max[1] - b.Min[1]) / coverageSamples
+	var inside, covered int
+	for i := range coverageSamples {
+		for j := range coverageSamples {
+			p := orb.Point{b.Min[0] + (float64(i)+0.5)*dx, b.Min[1] + (float64(j)+0.5)*dy}
+			if !planar.MultiPolygonContains(aoi, p) {
+				continue
+			}
+			inside++
+			if planar.MultiPolygonContains(footprint, p) {
+				covered++
+			}
+		}
+	}
+	if inside == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(inside)
+}
@@ -0,0 +1,190 @@
+package common
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+)
+
+// square returns a closed ring around (lon, lat) with the given half-size in
+// degrees.
+func square(lon, lat, half float64) orb.Ring {
+	return orb.Ring{
+		{lon - half, lat - half}, {lon + half, lat - half},
+		{lon + half, lat + half}, {lon - half, lat + half},
+		{lon - half, lat - half},
+	}
+}
+
+func geom(g orb.Geometry) *geojson.Geometry { return geojson.NewGeometry(g) }
+
+func TestCentroid(t *testing.T) {
+	lon, lat, err := Centroid(geom(orb.Polygon{square(10, 50, 1)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(lon-10) > 1e-9 || math.Abs(lat-50) > 1e-9 {
+		t.Errorf("expected centroid (10, 50), got (%v, %v)", lon, lat)
+	}
+
+	if _, _, err := Centroid(nil); !errors.Is(err, ErrEmptyGeometry) {
+		t.Errorf("expected ErrEmptyGeometry, got %v", err)
+	}
+}
+
+func TestAreaSqKm(t *testing.T) {
+	// A 1°×1° cell at the equator is about 111.32 km × 110.57 km.
+	area, err := AreaSqKm(geom(orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(area-12308) > 0.01*12308 {
+		t.Errorf("expected about 12308 km², got %.0f", area)
+	}
+
+	if area, _ := AreaSqKm(geom(orb.Point{1, 2})); area != 0 {
+		t.Errorf("expected a point to have no area, got %v", area)
+	}
+}
+
+func TestWKTRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		wkt  string
+	}{
+		{"point", "POINT(24.94 60.17)"},
+		{"polygon", "POLYGON((0 0,2 0,2 2,0 2,0 0),(0.5 0.5,1 0.5,1 1,0.5 0.5))"},
+		{"multipolygon", "MULTIPOLYGON(((0 0,1 0,1 1,0 0)),((5 5,6 5,6 6,5 5)))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := WKTToGeometry(tt.wkt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := GeometryToWKT(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.wkt {
+				t.Errorf("expected %s, got %s", tt.wkt, got)
+			}
+		})
+	}
+
+	if _, err := WKTToGeometry("POLYGON((0 0, 1"); err == nil {
+		t.Error("expected an error for malformed WKT")
+	}
+}
+
+func TestIntersects(t *testing.T) {
+	a := geom(orb.Polygon{square(0, 0, 1)})
+	tests := []struct {
+		name string
+		b    orb.Geometry
+		want bool
+	}{
+		{"overlapping", orb.Polygon{square(1.5, 0, 1)}, true},
+		{"contained", orb.Polygon{square(0, 0, 0.2)}, true},
+		{"crossing without vertices inside", orb.Polygon{{{-2, -0.1}, {2, -0.1}, {2, 0.1}, {-2, 0.1}, {-2, -0.1}}}, true},
+		{"disjoint", orb.Polygon{square(5, 5, 1)}, false},
+		{"point inside", orb.Point{0.5, 0.5}, true},
+		{"point outside", orb.Point{3, 3}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Intersects(a, geom(tt.b)); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+	if Intersects(a, nil) {
+		t.Error("expected nil geometry not to intersect")
+	}
+}
+
+func TestCoveragePercent(t *testing.T) {
+	aoi := geom(orb.Polygon{square(0, 0, 1)})
+	// An L-shaped footprint covering the left half and the bottom-right
+	// quarter of the AOI: 75%.
+	lShape := orb.Polygon{{{-2, -2}, {2, -2}, {2, 0}, {0, 0}, {0, 2}, {-2, 2}, {-2, -2}}}
+
+	tests := []struct {
+		name      string
+		footprint orb.Geometry
+		want      float64
+		tolerance float64
+	}{
+		{"covers all", orb.Polygon{square(0, 0, 2)}, 100, 1e-6},
+		{"right half", orb.Polygon{square(1, 0, 1)}, 50, 0.1},
+		{"quarter, clockwise ring", orb.Polygon{{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}}}, 25, 0.1},
+		{"disjoint", orb.Polygon{square(5, 5, 1)}, 0, 0},
+		{"non-convex", lShape, 75, 1},
+		{"multipolygon quarters", orb.MultiPolygon{{square(-0.5, 0, 0.5)}, {square(0.5, 0.5, 0.5)}}, 50, 0.1},
+		{"overlapping polygons", orb.MultiPolygon{{square(1, 0, 1)}, {square(0.5, 0, 1)}}, 75, 0.1},
+		{"duplicate polygons", orb.MultiPolygon{{square(1, 0, 1)}, {square(1, 0, 1)}}, 50, 0.1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CoveragePercent(aoi, geom(tt.footprint))
+			if math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("expected %.2f%%, got %.2f%%", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDifference(t *testing.T) {
+	aoi := geom(orb.Polygon{square(0, 0, 1)})
+	area := func(g *geojson.Geometry) float64 {
+		if g == nil {
+			return 0
+		}
+		a, err := AreaSqKm(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	total := area(aoi)
+
+	tests := []struct {
+		name      string
+		footprint orb.Geometry
+		remaining float64 // fraction of the AOI
+	}{
+		{"covers all", orb.Polygon{square(0, 0, 2)}, 0},
+		{"right half", orb.Polygon{square(1, 0, 1)}, 0.5},
+		{"centre, clockwise ring", orb.Polygon{{{-0.5, -0.5}, {-0.5, 0.5}, {0.5, 0.5}, {0.5, -0.5}, {-0.5, -0.5}}}, 0.75},
+		{"disjoint", orb.Polygon{square(5, 5, 1)}, 1},
+		{"overlapping parts", orb.MultiPolygon{{square(-0.5, 0, 0.5)}, {square(0, 0, 0.5)}}, 0.625},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Difference(aoi, geom(tt.footprint))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.remaining == 0 && got != nil {
+				t.Fatalf("expected nothing to remain, got %v", got.Geometry())
+			}
+			if frac := area(got) / total; math.Abs(frac-tt.remaining) > 0.01 {
+				t.Errorf("remaining fraction %.3f, want %.3f", frac, tt.remaining)
+			}
+			if got != nil && CoveragePercent(got, geom(tt.footprint)) > 0.1 {
+				t.Error("remainder overlaps the footprint")
+			}
+		})
+	}
+
+	lShape := orb.Polygon{{{-2, -2}, {2, -2}, {2, 0}, {0, 0}, {0, 2}, {-2, 2}, {-2, -2}}}
+	if _, err := Difference(aoi, geom(lShape)); !errors.Is(err, ErrNotConvex) {
+		t.Errorf("expected ErrNotConvex, got %v", err)
+	}
+	if _, err := Difference(geom(orb.Point{0, 0}), aoi); err == nil {
+		t.Error("expected an error for a point")
+	}
+}
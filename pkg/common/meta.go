@@ -0,0 +1,75 @@
+package common
+
+import (
+	"context"
+	"sync"
+)
+
+// ResponseMeta records the response details of the last call made with a
+// context returned by WithResponseMeta. Vendor transports fill it in with
+// Record, for successful and failed calls alike.
+type ResponseMeta struct {
+	mu              sync.Mutex
+	statusCode      int
+	requestID       string
+	clientRequestID string
+	extra           any
+}
+
+// StatusCode returns the HTTP status code of the response, or 0 if no
+// response was received.
+func (m *ResponseMeta) StatusCode() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statusCode
+}
+
+// RequestID returns the server request or correlation ID of the response, or
+// "" if the server did not return one.
+func (m *ResponseMeta) RequestID() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.requestID
+}
+
+// ClientRequestID returns the correlation ID sent with the call, or "" if
+// the client does not send one.
+func (m *ResponseMeta) ClientRequestID() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.clientRequestID
+}
+
+// Extra returns the vendor-specific details recorded with the response, such
+// as a parsed rate limit, or nil.
+func (m *ResponseMeta) Extra() any {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.extra
+}
+
+// Record replaces the recorded details with those of a new response.
+func (m *ResponseMeta) Record(statusCode int, requestID, clientRequestID string, extra any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statusCode = statusCode
+	m.requestID = requestID
+	m.clientRequestID = clientRequestID
+	m.extra = extra
+}
+
+type responseMetaKey struct{}
+
+// WithResponseMeta returns a context that records the response details of
+// calls made with it, and the ResponseMeta they are recorded in.
+func WithResponseMeta(ctx context.Context) (context.Context, *ResponseMeta) {
+	meta := &ResponseMeta{}
+	return context.WithValue(ctx, responseMetaKey{}, meta), meta
+}
+
+// MetaFromContext returns the ResponseMeta attached by WithResponseMeta, or
+// nil if there is none.
+func MetaFromContext(ctx context.Context) *ResponseMeta {
+	meta, _ := ctx.Value(responseMetaKey{}).(*ResponseMeta)
+	return meta
+}
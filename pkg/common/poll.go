@@ -0,0 +1,103 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// ErrWaitTimeout is returned by Poll when WaitOptions.Timeout or MaxElapsed
+// expires before the condition is met.
+var ErrWaitTimeout = errors.New("wait timed out")
+
+// PollFunc performs one polling attempt. It returns done once the awaited
+// condition holds; a non-nil error stops polling immediately.
+type PollFunc func(ctx context.Context) (done bool, err error)
+
+// Poll calls fn immediately and then after each delay configured by opts
+// until fn reports done, fn returns an error, the context is cancelled, or
+// the Timeout/MaxElapsed budget runs out (ErrWaitTimeout). The last delay is
+// shortened so a final attempt happens when the budget expires.
+func Poll(ctx context.Context, opts WaitOptions, fn PollFunc) error {
+	return poll(ctx, opts, fn, realClock{}, rand.Float64)
+}
+
+// clock abstracts time for tests.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func poll(ctx context.Context, opts WaitOptions, fn PollFunc, clk clock, random func() float64) error {
+	interval := opts.PollInterval
+	if opts.InitialInterval > 0 {
+		interval = opts.InitialInterval
+	}
+	if interval <= 0 {
+		return errors.New("poll interval must be positive")
+	}
+
+	limit := opts.Timeout
+	if opts.MaxElapsed > 0 && (limit <= 0 || opts.MaxElapsed < limit) {
+		limit = opts.MaxElapsed
+	}
+
+	start := clk.Now()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		done, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		wait := jitter(interval, opts.Jitter, random)
+		if limit > 0 {
+			remaining := limit - clk.Now().Sub(start)
+			if remaining <= 0 {
+				return ErrWaitTimeout
+			}
+			wait = min(wait, remaining)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clk.After(wait):
+		}
+
+		if opts.InitialInterval > 0 {
+			interval = nextInterval(interval, opts)
+		}
+	}
+}
+
+func nextInterval(d time.Duration, opts WaitOptions) time.Duration {
+	m := opts.Multiplier
+	if m == 0 {
+		m = 2
+	}
+	next := time.Duration(float64(d) * max(m, 1))
+	if opts.MaxInterval > 0 && next > opts.MaxInterval {
+		next = opts.MaxInterval
+	}
+	return next
+}
+
+func jitter(d time.Duration, j float64, random func() float64) time.Duration {
+	j = min(max(j, 0), 1)
+	if j == 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + j*(2*random()-1)))
+}
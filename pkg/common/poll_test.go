@@ -0,0 +1,181 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeClock advances instantly and records every requested delay.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// doneAfter returns a PollFunc that reports done on attempt n.
+func doneAfter(n int, calls *int) PollFunc {
+	return func(context.Context) (bool, error) {
+		*calls++
+		return *calls >= n, nil
+	}
+}
+
+func noJitter() float64 { return 0.5 }
+
+func TestPoll_ImmediateSuccess(t *testing.T) {
+	clk := &fakeClock{}
+	var calls int
+	err := poll(context.Background(), WaitOptions{PollInterval: time.Second}, doneAfter(1, &calls), clk, noJitter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 || len(clk.sleeps) != 0 {
+		t.Errorf("expected one attempt and no sleeps, got %d attempts, sleeps %v", calls, clk.sleeps)
+	}
+}
+
+func TestPoll_FixedInterval(t *testing.T) {
+	clk := &fakeClock{}
+	var calls int
+	err := poll(context.Background(), WaitOptions{PollInterval: 30 * time.Second, Timeout: time.Hour}, doneAfter(4, &calls), clk, noJitter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []time.Duration{30 * time.Second, 30 * time.Second, 30 * time.Second}
+	if !slices.Equal(clk.sleeps, want) {
+		t.Errorf("sleeps = %v, want %v", clk.sleeps, want)
+	}
+}
+
+func TestPoll_BackoffSequence(t *testing.T) {
+	clk := &fakeClock{}
+	var calls int
+	opts := WaitOptions{
+		InitialInterval: time.Second,
+		Multiplier:      3,
+		MaxInterval:     20 * time.Second,
+	}
+	if err := poll(context.Background(), opts, doneAfter(6, &calls), clk, noJitter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []time.Duration{time.Second, 3 * time.Second, 9 * time.Second, 20 * time.Second, 20 * time.Second}
+	if !slices.Equal(clk.sleeps, want) {
+		t.Errorf("sleeps = %v, want %v", clk.sleeps, want)
+	}
+}
+
+func TestPoll_BackoffDefaultMultiplier(t *testing.T) {
+	clk := &fakeClock{}
+	var calls int
+	if err := poll(context.Background(), WaitOptions{InitialInterval: time.Second}, doneAfter(4, &calls), clk, noJitter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	if !slices.Equal(clk.sleeps, want) {
+		t.Errorf("sleeps = %v, want %v", clk.sleeps, want)
+	}
+}
+
+func TestPoll_JitterBounds(t *testing.T) {
+	clk := &fakeClock{}
+	var calls int
+	r := rand.New(rand.NewPCG(1, 2))
+	opts := WaitOptions{PollInterval: 10 * time.Second, Jitter: 0.2}
+	if err := poll(context.Background(), opts, doneAfter(200, &calls), clk, r.Float64); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var varied bool
+	for _, d := range clk.sleeps {
+		if d < 8*time.Second || d > 12*time.Second {
+			t.Fatalf("sleep %v outside ±20%% of 10s", d)
+		}
+		if d != 10*time.Second {
+			varied = true
+		}
+	}
+	if !varied {
+		t.Error("expected jitter to vary the delay")
+	}
+
+	for _, r := range []float64{0, 0.999999} {
+		if d := jitter(10*time.Second, 0.2, func() float64 { return r }); d < 8*time.Second || d > 12*time.Second {
+			t.Errorf("jitter(%v) = %v outside bounds", r, d)
+		}
+	}
+}
+
+func TestPoll_MaxElapsed(t *testing.T) {
+	clk := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var calls int
+	opts := WaitOptions{InitialInterval: 10 * time.Second, Multiplier: 2, MaxElapsed: time.Minute}
+	err := poll(context.Background(), opts, doneAfter(100, &calls), clk, noJitter)
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("expected ErrWaitTimeout, got %v", err)
+	}
+	// 10s + 20s, then the 40s delay is clipped to the remaining 30s.
+	want := []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second}
+	if !slices.Equal(clk.sleeps, want) {
+		t.Errorf("sleeps = %v, want %v", clk.sleeps, want)
+	}
+	if calls != 4 {
+		t.Errorf("expected a final attempt at the deadline, got %d attempts", calls)
+	}
+}
+
+func TestPoll_TimeoutAndMaxElapsedUseSmaller(t *testing.T) {
+	clk := &fakeClock{}
+	var calls int
+	opts := WaitOptions{PollInterval: time.Second, Timeout: 5 * time.Second, MaxElapsed: time.Hour}
+	if err := poll(context.Background(), opts, doneAfter(100, &calls), clk, noJitter); !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("expected ErrWaitTimeout, got %v", err)
+	}
+	if calls != 6 {
+		t.Errorf("expected 6 attempts within 5s, got %d", calls)
+	}
+}
+
+func TestPoll_StopsOnError(t *testing.T) {
+	clk := &fakeClock{}
+	boom := errors.New("boom")
+	err := poll(context.Background(), WaitOptions{PollInterval: time.Second}, func(context.Context) (bool, error) {
+		return false, boom
+	}, clk, noJitter)
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+}
+
+func TestPoll_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	err := Poll(ctx, WaitOptions{PollInterval: time.Hour}, func(context.Context) (bool, error) {
+		calls++
+		cancel()
+		return false, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 attempt, got %d", calls)
+	}
+}
+
+func TestPoll_RequiresInterval(t *testing.T) {
+	if err := Poll(context.Background(), WaitOptions{}, func(context.Context) (bool, error) { return true, nil }); err == nil {
+		t.Error("expected error for zero interval")
+	}
+}
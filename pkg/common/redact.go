@@ -0,0 +1,26 @@
+package common
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Redacted replaces credentials in logged headers and request dumps.
+const Redacted = "[REDACTED]"
+
+// sensitiveHeaders are the request and response headers carrying
+// credentials.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// RedactHeaders returns a copy of h with the values of credential headers,
+// matched case-insensitively, replaced by Redacted.
+func RedactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for name := range out {
+		if slices.ContainsFunc(sensitiveHeaders, func(s string) bool { return strings.EqualFold(name, s) }) {
+			out[name] = []string{Redacted}
+		}
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+)
+
+// modulePath is the import path of this module, used to detect its version.
+const modulePath = "github.com/robert-malhotra/go-sar-vendor"
+
+// ModuleVersion returns the version of this module as recorded in the build
+// info, or "devel" when it cannot be determined (e.g. in tests or local builds).
+func ModuleVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	version := ""
+	if bi.Main.Path == modulePath {
+		version = bi.Main.Version
+	} else {
+		for _, dep := range bi.Deps {
+			if dep.Path == modulePath {
+				version = dep.Version
+				if dep.Replace != nil && dep.Replace.Version != "" {
+					version = dep.Replace.Version
+				}
+				break
+			}
+		}
+	}
+	if version == "" || version == "(devel)" {
+		return "devel"
+	}
+	return version
+}
+
+// DefaultUserAgent returns the User-Agent sent by a vendor client when none is
+// configured, e.g. "go-sar-vendor/v1.2.0 (+umbra)".
+func DefaultUserAgent(vendor string) string {
+	return fmt.Sprintf("go-sar-vendor/%s (+%s)", ModuleVersion(), vendor)
+}
+
+// ConfigureTransport returns a shallow copy of client whose transport routes
+// through proxy and uses tlsConfig. Nil arguments leave the corresponding
+// setting untouched; if both are nil, client is returned unchanged.
+//
+// The client's transport must be nil (http.DefaultTransport is cloned) or an
+// *http.Transport, which is cloned so the caller's transport is not mutated.
+func ConfigureTransport(client *http.Client, proxy *url.URL, tlsConfig *tls.Config) (*http.Client, error) {
+	if proxy == nil && tlsConfig == nil {
+		return client, nil
+	}
+
+	var base *http.Transport
+	switch rt := client.Transport.(type) {
+	case nil:
+		base = http.DefaultTransport.(*http.Transport)
+	case *http.Transport:
+		base = rt
+	default:
+		return nil, fmt.Errorf("cannot apply proxy or TLS config to transport of type %T", client.Transport)
+	}
+
+	tr := base.Clone()
+	if proxy != nil {
+		tr.Proxy = http.ProxyURL(proxy)
+	}
+	if tlsConfig != nil {
+		tr.TLSClientConfig = tlsConfig.Clone()
+	}
+
+	c := *client
+	c.Transport = tr
+	return &c, nil
+}
@@ -0,0 +1,67 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// TTLCache is a concurrency-safe map whose entries expire after the TTL
+// they were stored with. Expired entries are dropped when next looked up.
+// A nil *TTLCache caches nothing.
+type TTLCache[V any] struct {
+	// Now returns the current time. It defaults to time.Now and may be
+	// replaced in tests.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]ttlEntry[V]
+}
+
+type ttlEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// NewTTLCache returns an empty cache.
+func NewTTLCache[V any]() *TTLCache[V] {
+	return &TTLCache[V]{Now: time.Now, entries: make(map[string]ttlEntry[V])}
+}
+
+// Get returns the value stored under key, if it has not expired.
+func (c *TTLCache[V]) Get(key string) (V, bool) {
+	var zero V
+	if c == nil {
+		return zero, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+	if !c.Now().Before(e.expires) {
+		delete(c.entries, key)
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Put stores v under key for ttl. A non-positive ttl stores nothing.
+func (c *TTLCache[V]) Put(key string, v V, ttl time.Duration) {
+	if c == nil || ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = ttlEntry[V]{value: v, expires: c.Now().Add(ttl)}
+}
+
+// Clear drops every entry.
+func (c *TTLCache[V]) Clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
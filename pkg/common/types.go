@@ -61,6 +61,15 @@ type Asset struct {
 	Description string   `json:"description,omitempty"`
 	Type        string   `json:"type,omitempty"` // MIME type
 	Roles       []string `json:"roles,omitempty"`
+
+	// STAC file extension fields, when provided by the vendor.
+	Size     int64  `json:"file:size,omitempty"`
+	Checksum string `json:"file:checksum,omitempty"` // Multihash, hex-encoded
+
+	// STAC SAR and view extension fields some vendors set per asset.
+	Polarizations        []string `json:"sar:polarizations,omitempty"`
+	ObservationDirection string   `json:"sar:observation_direction,omitempty"`
+	IncidenceAngle       float64  `json:"view:incidence_angle,omitempty"`
 }
 
 // Link represents a STAC/web link.
@@ -83,10 +92,26 @@ type ListOptions struct {
 	Offset int `url:"offset,omitempty"`
 }
 
-// WaitOptions configures polling behavior for async operations.
+// WaitOptions configures polling behavior for async operations. See Poll.
 type WaitOptions struct {
+	// PollInterval is the fixed delay between attempts when no backoff is
+	// configured.
 	PollInterval time.Duration
-	Timeout      time.Duration
+	// Timeout bounds the total time spent polling. Zero means no limit
+	// beyond the context.
+	Timeout time.Duration
+
+	// InitialInterval enables exponential backoff: the first delay is
+	// InitialInterval, and each later delay is the previous one times
+	// Multiplier (default 2), capped at MaxInterval when set.
+	InitialInterval time.Duration
+	Multiplier      float64
+	MaxInterval     time.Duration
+	// Jitter randomizes each delay by up to ±Jitter of its value, in [0, 1].
+	Jitter float64
+	// MaxElapsed bounds the total time spent polling like Timeout; when both
+	// are set the smaller applies.
+	MaxElapsed time.Duration
 }
 
 // Point creates an orb.Point from longitude and latitude.
@@ -0,0 +1,142 @@
+package iceye
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+)
+
+// ErrCrossesAntimeridian is returned by ThumbnailCoords.ToPolygon when the
+// corners span the antimeridian, where a single lon/lat polygon would wrap
+// the wrong way around the globe.
+var ErrCrossesAntimeridian = errors.New("iceye: thumbnail corners cross the antimeridian")
+
+// FetchAsset downloads an asset, e.g. a thumbnail, to w and returns its
+// content type. Relative hrefs are resolved against the base URL. Requests
+// to the API host are authenticated like API calls; if the token is rejected
+// with 401 it is refreshed and the download retried once. Assets on other
+// hosts, such as presigned storage URLs, are fetched without credentials.
+func (c *Client) FetchAsset(ctx context.Context, asset ItemAsset, w io.Writer) (contentType string, err error) {
+	if asset.Href == "" {
+		return "", errors.New("iceye: asset has no href")
+	}
+	ref, err := url.Parse(asset.Href)
+	if err != nil {
+		return "", fmt.Errorf("parse asset href: %w", err)
+	}
+	u := c.BaseURL().ResolveReference(ref)
+
+	resp, err := c.getAsset(ctx, u)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.isAPIHost(u) {
+		if inv, ok := c.auth.(interface{ Invalidate() }); ok {
+			resp.Body.Close()
+			inv.Invalidate()
+			if resp, err = c.getAsset(ctx, u); err != nil {
+				return "", err
+			}
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", parseError(resp)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return "", fmt.Errorf("read asset: %w", err)
+	}
+	contentType = resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = asset.Type
+	}
+	return contentType, nil
+}
+
+func (c *Client) getAsset(ctx context.Context, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.isAPIHost(u) {
+		if err := c.Client.ApplyAuth(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := c.HTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	return resp, nil
+}
+
+// isAPIHost reports whether u is on the API's host, the only host the API
+// token is sent to.
+func (c *Client) isAPIHost(u *url.URL) bool {
+	return strings.EqualFold(u.Host, c.BaseURL().Host)
+}
+
+// ThumbnailAsset returns the item's asset with the "thumbnail" role.
+func (i *STACItem) ThumbnailAsset() (ItemAsset, bool) {
+	// Map iteration order is random; check keys in order so the result is
+	// stable if several assets have the role.
+	keys := make([]string, 0, len(i.Assets))
+	for k := range i.Assets {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		if a := i.Assets[k]; slices.Contains(a.Roles, "thumbnail") {
+			return a, true
+		}
+	}
+	return ItemAsset{}, false
+}
+
+// Footprint returns the item's geometry, if it has one.
+func (i *STACItem) Footprint() (*geojson.Geometry, bool) {
+	if i.Geometry == nil || i.Geometry.Geometry() == nil {
+		return nil, false
+	}
+	return i.Geometry, true
+}
+
+// CrossesAntimeridian reports whether the corners span more than 180° of
+// longitude, which for a thumbnail means it straddles the antimeridian.
+func (t *ThumbnailCoords) CrossesAntimeridian() bool {
+	lons := []float64{t.TopLeft[0], t.TopRight[0], t.BottomRight[0], t.BottomLeft[0]}
+	return slices.Max(lons)-slices.Min(lons) > 180
+}
+
+// ToPolygon returns the thumbnail's corners ([lon, lat]) as a closed GeoJSON
+// polygon, wound counterclockwise as RFC 7946 requires, for placing the
+// thumbnail on a map. It returns ErrCrossesAntimeridian for thumbnails that
+// straddle the antimeridian.
+func (t *ThumbnailCoords) ToPolygon() (*geojson.Geometry, error) {
+	if t.CrossesAntimeridian() {
+		return nil, ErrCrossesAntimeridian
+	}
+	ring := orb.Ring{
+		orb.Point(t.TopLeft),
+		orb.Point(t.BottomLeft),
+		orb.Point(t.BottomRight),
+		orb.Point(t.TopRight),
+		orb.Point(t.TopLeft),
+	}
+	if ring.Orientation() == orb.CW {
+		ring.Reverse()
+	}
+	return geojson.NewGeometry(orb.Polygon{ring}), nil
+}
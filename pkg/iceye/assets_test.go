@@ -0,0 +1,153 @@
+package iceye_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchAsset_RetriesOn401(t *testing.T) {
+	var assetHits atomic.Int32
+	cli, srv, authHits := newTestClient(t, func(mux *http.ServeMux, authHits *atomic.Int32) {
+		// Each token request issues a new token; only the second is accepted.
+		mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+			n := authHits.Add(1)
+			json.NewEncoder(w).Encode(map[string]any{
+				"access_token": fmt.Sprintf("token-%d", n),
+				"expires_in":   3600,
+			})
+		})
+		mux.HandleFunc("/assets/thumb.png", func(w http.ResponseWriter, r *http.Request) {
+			assetHits.Add(1)
+			if r.Header.Get("Authorization") != "Bearer token-2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("PNG"))
+		})
+	})
+
+	var buf bytes.Buffer
+	ct, err := cli.FetchAsset(context.Background(), iceye.ItemAsset{Href: srv.URL + "/assets/thumb.png"}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", ct)
+	assert.Equal(t, "PNG", buf.String())
+	assert.Equal(t, int32(2), authHits.Load())
+	assert.Equal(t, int32(2), assetHits.Load())
+}
+
+func TestFetchAsset_ErrorAfterRetry(t *testing.T) {
+	var assetHits atomic.Int32
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/assets/thumb.png", func(w http.ResponseWriter, r *http.Request) {
+			assetHits.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	})
+
+	var buf bytes.Buffer
+	_, err := cli.FetchAsset(context.Background(), iceye.ItemAsset{Href: "/assets/thumb.png"}, &buf)
+	require.Error(t, err)
+	assert.Equal(t, int32(2), assetHits.Load(), "should retry once")
+	assert.Zero(t, buf.Len())
+}
+
+func TestFetchAsset_ForeignHostUnauthenticated(t *testing.T) {
+	var authorization atomic.Value
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("PNG"))
+	}))
+	t.Cleanup(foreign.Close)
+
+	cli, _, authHits := newTestClient(t, func(mux *http.ServeMux, authHits *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(authHits))
+	})
+
+	var buf bytes.Buffer
+	_, err := cli.FetchAsset(context.Background(), iceye.ItemAsset{Href: foreign.URL + "/bucket/thumb.png"}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "PNG", buf.String())
+	assert.Equal(t, "", authorization.Load(), "the API token must not reach another host")
+	assert.Zero(t, authHits.Load())
+}
+
+func TestSTACItem_ThumbnailAndFootprint(t *testing.T) {
+	item := iceye.STACItem{Assets: map[string]iceye.ItemAsset{
+		"data":  {Href: "https://example.com/data.tif", Roles: []string{"data"}},
+		"thumb": {Href: "https://example.com/thumb.png", Roles: []string{"thumbnail"}},
+	}}
+	thumb, ok := item.ThumbnailAsset()
+	require.True(t, ok)
+	assert.Equal(t, "https://example.com/thumb.png", thumb.Href)
+
+	_, ok = item.Footprint()
+	assert.False(t, ok)
+
+	item = iceye.STACItem{Geometry: iceye.GeoJSONPoint(1, 2)}
+	_, ok = item.ThumbnailAsset()
+	assert.False(t, ok)
+	fp, ok := item.Footprint()
+	require.True(t, ok)
+	assert.Equal(t, orb.Point{1, 2}, fp.Geometry())
+}
+
+func TestThumbnailCoords_ToPolygon(t *testing.T) {
+	tests := []struct {
+		name   string
+		coords iceye.ThumbnailCoords
+		want   orb.Ring
+	}{
+		{
+			name: "north up",
+			coords: iceye.ThumbnailCoords{
+				TopLeft: [2]float64{10, 51}, TopRight: [2]float64{11, 51},
+				BottomLeft: [2]float64{10, 50}, BottomRight: [2]float64{11, 50},
+			},
+			want: orb.Ring{{10, 51}, {10, 50}, {11, 50}, {11, 51}, {10, 51}},
+		},
+		{
+			// A mirrored image (e.g. flipped east-west) lists its corners
+			// clockwise, so the ring must be reversed.
+			name: "mirrored",
+			coords: iceye.ThumbnailCoords{
+				TopLeft: [2]float64{11, 51}, TopRight: [2]float64{10, 51},
+				BottomLeft: [2]float64{11, 50}, BottomRight: [2]float64{10, 50},
+			},
+			want: orb.Ring{{11, 51}, {10, 51}, {10, 50}, {11, 50}, {11, 51}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := tt.coords.ToPolygon()
+			require.NoError(t, err)
+			poly, ok := g.Geometry().(orb.Polygon)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, poly[0])
+			assert.Equal(t, orb.CCW, poly[0].Orientation())
+		})
+	}
+}
+
+func TestThumbnailCoords_Antimeridian(t *testing.T) {
+	coords := iceye.ThumbnailCoords{
+		TopLeft: [2]float64{179.5, -16}, TopRight: [2]float64{-179.5, -16},
+		BottomLeft: [2]float64{179.5, -17}, BottomRight: [2]float64{-179.5, -17},
+	}
+	assert.True(t, coords.CrossesAntimeridian())
+	_, err := coords.ToPolygon()
+	assert.ErrorIs(t, err, iceye.ErrCrossesAntimeridian)
+}
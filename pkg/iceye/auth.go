@@ -40,6 +40,15 @@ func NewOAuth2Auth(tokenURL, clientID, clientSecret string, httpClient *http.Cli
 	}
 }
 
+// Invalidate discards the cached token so the next Apply fetches a new one.
+// Use it when the server rejects a token before its local expiry.
+func (a *OAuth2Auth) Invalidate() {
+	a.mu.Lock()
+	a.token = ""
+	a.exp = time.Time{}
+	a.mu.Unlock()
+}
+
 // Apply implements common.Authenticator.
 func (a *OAuth2Auth) Apply(ctx context.Context, req *http.Request) error {
 	if err := a.refreshIfNeeded(ctx); err != nil {
@@ -62,7 +71,7 @@ func (a *OAuth2Auth) refreshIfNeeded(ctx context.Context) error {
 	body := []byte("grant_type=client_credentials")
 	b64 := base64.StdEncoding.EncodeToString([]byte(a.clientID + ":" + a.clientSecret))
 
-	tokenReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, bytes.NewReader(body))
+	tokenReq, err := http.NewRequestWithContext(withTokenFetch(ctx), http.MethodPost, a.tokenURL, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("create token request: %w", err)
 	}
@@ -132,6 +141,15 @@ func NewResourceOwnerAuth(tokenURL, apiKey, username, password string, httpClien
 	}
 }
 
+// Invalidate discards the cached token so the next Apply fetches a new one.
+// Use it when the server rejects a token before its local expiry.
+func (a *ResourceOwnerAuth) Invalidate() {
+	a.mu.Lock()
+	a.token = ""
+	a.exp = time.Time{}
+	a.mu.Unlock()
+}
+
 // Apply implements common.Authenticator.
 func (a *ResourceOwnerAuth) Apply(ctx context.Context, req *http.Request) error {
 	if err := a.refreshIfNeeded(ctx); err != nil {
@@ -156,7 +174,7 @@ func (a *ResourceOwnerAuth) refreshIfNeeded(ctx context.Context) error {
 	form.Set("username", a.username)
 	form.Set("password", a.password)
 
-	tokenReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, bytes.NewBufferString(form.Encode()))
+	tokenReq, err := http.NewRequestWithContext(withTokenFetch(ctx), http.MethodPost, a.tokenURL, bytes.NewBufferString(form.Encode()))
 	if err != nil {
 		return fmt.Errorf("create token request: %w", err)
 	}
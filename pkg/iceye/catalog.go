@@ -2,6 +2,8 @@ package iceye
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"iter"
 	"net/http"
 	"net/url"
@@ -10,6 +12,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
 
@@ -22,14 +26,145 @@ type STACItem struct {
 	ID          string               `json:"id"`
 	Type        string               `json:"type"` // "Feature"
 	StacVersion string               `json:"stac_version"`
-	Geometry    *Geometry            `json:"geometry,omitempty"`
-	BBox        BoundingBox          `json:"bbox"`
+	Geometry    *geojson.Geometry    `json:"geometry,omitempty"`
+	BBox        common.BoundingBox   `json:"bbox"`
 	Collection  string               `json:"collection"`
 	Properties  ItemProperties       `json:"properties"`
 	Assets      map[string]ItemAsset `json:"assets"`
 	Links       []Link               `json:"links,omitempty"`
 }
 
+// UnmarshalJSON decodes a STAC item, tolerating the geometry shapes the
+// catalog has emitted over time: a null or empty geometry decodes to nil, a
+// geometry wrapped in a Feature is unwrapped, a 3D bbox loses its elevation
+// and a missing bbox is derived from the geometry.
+func (i *STACItem) UnmarshalJSON(data []byte) error {
+	type plain STACItem
+	var raw struct {
+		plain
+		Geometry json.RawMessage `json:"geometry"`
+		BBox     []float64       `json:"bbox"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*i = STACItem(raw.plain)
+
+	geom, err := decodeItemGeometry(raw.Geometry)
+	if err != nil {
+		return fmt.Errorf("iceye: item %s geometry: %w", i.ID, err)
+	}
+	i.Geometry = geom
+
+	switch len(raw.BBox) {
+	case 0:
+		if geom != nil {
+			i.BBox = common.BoundingBoxFromOrb(geom.Geometry().Bound())
+		}
+	case 4:
+		i.BBox = common.BoundingBox(raw.BBox)
+	case 6:
+		i.BBox = common.BoundingBox{raw.BBox[0], raw.BBox[1], raw.BBox[3], raw.BBox[4]}
+	default:
+		return fmt.Errorf("iceye: item %s bbox has %d coordinates", i.ID, len(raw.BBox))
+	}
+	return nil
+}
+
+// decodeItemGeometry decodes an item's geometry, returning nil for a null
+// or empty one.
+func decodeItemGeometry(data json.RawMessage) (*geojson.Geometry, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var probe struct {
+		Type     string          `json:"type"`
+		Geometry json.RawMessage `json:"geometry"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+	if probe.Type == "Feature" {
+		return decodeItemGeometry(probe.Geometry)
+	}
+	var g geojson.Geometry
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, err
+	}
+	if isEmptyGeometry(g.Geometry()) {
+		return nil, nil
+	}
+	return &g, nil
+}
+
+// isEmptyGeometry reports whether g has no coordinates, e.g. a polygon
+// decoded from "coordinates": [].
+func isEmptyGeometry(g orb.Geometry) bool {
+	switch g := g.(type) {
+	case nil:
+		return true
+	case orb.MultiPoint:
+		return len(g) == 0
+	case orb.LineString:
+		return len(g) == 0
+	case orb.MultiLineString:
+		return len(g) == 0
+	case orb.Polygon:
+		return len(g) == 0
+	case orb.MultiPolygon:
+		return len(g) == 0
+	case orb.Collection:
+		return len(g) == 0
+	}
+	return false
+}
+
+// ObservationDirection is the side of the satellite ground track a catalog
+// image was acquired on.
+type ObservationDirection string
+
+const (
+	ObservationDirectionLeft  ObservationDirection = "left"
+	ObservationDirectionRight ObservationDirection = "right"
+)
+
+// IsKnown reports whether d is an observation direction known to this
+// package. Directions decoded from responses are kept as sent.
+func (d ObservationDirection) IsKnown() bool {
+	return d == ObservationDirectionLeft || d == ObservationDirectionRight
+}
+
+// Validate returns an error if d is not a known observation direction.
+func (d ObservationDirection) Validate() error {
+	if !d.IsKnown() {
+		return fmt.Errorf("iceye: unknown observation direction %q", d)
+	}
+	return nil
+}
+
+// OrbitStateEnum is the orbit state of the satellite when a catalog image
+// was acquired.
+type OrbitStateEnum string
+
+const (
+	OrbitStateAscending  OrbitStateEnum = "ascending"
+	OrbitStateDescending OrbitStateEnum = "descending"
+)
+
+// IsKnown reports whether s is an orbit state known to this package. States
+// decoded from responses are kept as sent.
+func (s OrbitStateEnum) IsKnown() bool {
+	return s == OrbitStateAscending || s == OrbitStateDescending
+}
+
+// Validate returns an error if s is not a known orbit state.
+func (s OrbitStateEnum) Validate() error {
+	if !s.IsKnown() {
+		return fmt.Errorf("iceye: unknown orbit state %q", s)
+	}
+	return nil
+}
+
 // ItemProperties contains STAC item properties.
 type ItemProperties struct {
 	// Temporal
@@ -37,14 +172,14 @@ type ItemProperties struct {
 	EndTime   time.Time `json:"end_time,omitempty"`
 
 	// Imaging parameters
-	InstrumentMode       string   `json:"instrument_mode,omitempty"`
-	ProductType          string   `json:"product_type,omitempty"`
-	ObservationDirection string   `json:"observation_direction,omitempty"`
-	SatelliteLookAngle   float64  `json:"satellite_look_angle,omitempty"`
-	IncidenceAngle       float64  `json:"incidence_angle,omitempty"`
-	Polarizations        []string `json:"polarizations,omitempty"`
-	ImageMode            string   `json:"image_mode,omitempty"`
-	OrbitState           string   `json:"orbit_state,omitempty"`
+	InstrumentMode       string               `json:"instrument_mode,omitempty"`
+	ProductType          string               `json:"product_type,omitempty"`
+	ObservationDirection ObservationDirection `json:"observation_direction,omitempty"`
+	SatelliteLookAngle   float64              `json:"satellite_look_angle,omitempty"`
+	IncidenceAngle       float64              `json:"incidence_angle,omitempty"`
+	Polarizations        []string             `json:"polarizations,omitempty"`
+	ImageMode            string               `json:"image_mode,omitempty"`
+	OrbitState           OrbitStateEnum       `json:"orbit_state,omitempty"`
 }
 
 // ItemAsset represents an asset in a STAC item.
@@ -74,21 +209,31 @@ type Link struct {
 
 // ListItemsOptions for GET /catalog/v1/items.
 type ListItemsOptions struct {
-	IDs      []string     // Specific item IDs to retrieve
-	BBox     *BoundingBox // Bounding box filter [minLon, minLat, maxLon, maxLat]
-	Datetime string       // RFC 3339 datetime or interval (e.g., "2021-02-12T00:00:00Z/2021-03-18T12:31:12Z")
-	SortBy   []string     // Properties with +/- prefix for asc/desc (e.g., "+start_time", "-end_time")
+	IDs      []string            // Specific item IDs to retrieve
+	BBox     *common.BoundingBox // Bounding box filter [minLon, minLat, maxLon, maxLat]
+	Datetime string              // RFC 3339 datetime or interval (e.g., "2021-02-12T00:00:00Z/2021-03-18T12:31:12Z")
+	SortBy   []string            // Properties with +/- prefix for asc/desc (e.g., "+start_time", "-end_time")
+
+	// PageValidators makes page requests conditional on the validators
+	// stored from an earlier listing, keyed by page cursor; see
+	// CatalogResponse.PageValidator.
+	PageValidators map[string]PageValidator
 }
 
 // SearchRequest for POST /catalog/v1/search.
 // All fields are optional.
 type SearchRequest struct {
 	IDs      []string               `json:"ids,omitempty"`
-	BBox     *BoundingBox           `json:"bbox,omitempty"`
+	BBox     *common.BoundingBox    `json:"bbox,omitempty"`
 	Datetime string                 `json:"datetime,omitempty"`
 	Limit    int                    `json:"limit,omitempty"`
 	Query    map[string]QueryFilter `json:"query,omitempty"`
 	SortBy   []SortCondition        `json:"sortby,omitempty"`
+
+	// PageValidators makes the GET requests for the pages after the first
+	// conditional, like ListItemsOptions.PageValidators. The first page is
+	// a POST and is always fetched.
+	PageValidators map[string]PageValidator `json:"-"`
 }
 
 // QueryFilter for advanced STAC queries using the Query Extension.
@@ -117,6 +262,15 @@ type SortCondition struct {
 type CatalogResponse struct {
 	Data   []STACItem `json:"data"`
 	Cursor string     `json:"cursor,omitempty"`
+
+	// PageCursor is the cursor the page was requested with, "" for the
+	// first page.
+	PageCursor string `json:"-"`
+	// Validator holds the page's ETag and Last-Modified validators.
+	Validator CacheValidator `json:"-"`
+	// NotModified is true when the page is unchanged since the validator
+	// sent for it. Data is then empty and Cursor is the stored NextCursor.
+	NotModified bool `json:"-"`
 }
 
 // PurchaseStatus represents the status of a catalog purchase.
@@ -152,12 +306,20 @@ type PurchaseRequest struct {
 // PurchaseResponse is returned when creating a purchase.
 type PurchaseResponse struct {
 	PurchaseID string `json:"purchaseId"`
+
+	// IdempotencyKey is the idempotency key PurchaseCatalogItems sent, if
+	// any.
+	IdempotencyKey string `json:"-"`
 }
 
 // PurchasesResponse is the paginated response for listing purchases.
 type PurchasesResponse struct {
 	Data   []Purchase `json:"data"`
 	Cursor string     `json:"cursor,omitempty"`
+
+	// PageCursor is the cursor the page was requested with, "" for the
+	// first page.
+	PageCursor string `json:"-"`
 }
 
 // ----------------------------------------------------------------------------
@@ -169,12 +331,28 @@ const catalogBasePath = "/catalog/v1"
 
 // ListCatalogItems lists catalog items with optional filters.
 // Returns an iterator that yields pages of STAC items.
-// Use the cursor from previous response to get subsequent pages.
+// Each page carries the Cursor of the next one; see ListCatalogItemsFrom to
+// resume a listing.
 //
 // GET /catalog/v1/items
 func (c *Client) ListCatalogItems(ctx context.Context, pageSize int, opts *ListItemsOptions) iter.Seq2[CatalogResponse, error] {
+	return c.ListCatalogItemsFrom(ctx, pageSize, opts, "")
+}
+
+// ListCatalogItemsFrom is like ListCatalogItems but starts at startCursor,
+// the Cursor of a page yielded earlier, so that a long sync can checkpoint
+// the cursor after each page and resume after a crash. An empty startCursor
+// starts at the first page. After a failed page, resume from its
+// PageCursor. A cursor the API no longer accepts fails with a
+// *StaleCursorError.
+func (c *Client) ListCatalogItemsFrom(ctx context.Context, pageSize int, opts *ListItemsOptions, startCursor string) iter.Seq2[CatalogResponse, error] {
 	return func(yield func(CatalogResponse, error) bool) {
-		seq := common.Paginate(func(cur *string) ([]STACItem, *string, error) {
+		var validators map[string]PageValidator
+		if opts != nil {
+			validators = opts.PageValidators
+		}
+		cursor := startCursor
+		for {
 			u := &url.URL{Path: path.Join(catalogBasePath, "items")}
 			q := u.Query()
 
@@ -195,43 +373,55 @@ func (c *Client) ListCatalogItems(ctx context.Context, pageSize int, opts *ListI
 					q.Set("sortby", strings.Join(opts.SortBy, ","))
 				}
 			}
-			if cur != nil && *cur != "" {
-				q.Set("cursor", *cur)
+			if cursor != "" {
+				q.Set("cursor", cursor)
 			}
 			u.RawQuery = q.Encode()
 
-			var resp CatalogResponse
-			err := c.do(ctx, http.MethodGet, u.String(), nil, &resp)
-			return resp.Data, &resp.Cursor, err
-		})
-		for data, err := range seq {
-			if !yield(CatalogResponse{Data: data}, err) {
+			resp, err := c.fetchCatalogPage(ctx, u.String(), cursor, validators)
+			if !yield(resp, err) {
+				return
+			}
+			if err != nil || resp.Cursor == "" {
 				return
 			}
+			cursor = resp.Cursor
 		}
 	}
 }
 
 // SearchCatalogItems performs an advanced catalog search.
-// Returns an iterator that yields pages of STAC items.
-// The cursor from a search response should be used with ListCatalogItems for pagination.
+// Returns an iterator that yields pages of STAC items, each carrying the
+// Cursor of the next one; see SearchCatalogItemsFrom to resume a search.
 //
-// POST /catalog/v1/search
+// POST /catalog/v1/search (first page), then GET /catalog/v1/items with cursor.
 func (c *Client) SearchCatalogItems(ctx context.Context, req *SearchRequest) iter.Seq2[CatalogResponse, error] {
+	return c.SearchCatalogItemsFrom(ctx, req, "")
+}
+
+// SearchCatalogItemsFrom is like SearchCatalogItems but resumes the search
+// at startCursor, the Cursor of a page yielded earlier. The cursor carries
+// the search, so only req.Limit and req.PageValidators apply to a resumed
+// search. An empty startCursor runs the search from the start. A cursor the
+// API no longer accepts fails with a *StaleCursorError.
+func (c *Client) SearchCatalogItemsFrom(ctx context.Context, req *SearchRequest, startCursor string) iter.Seq2[CatalogResponse, error] {
 	return func(yield func(CatalogResponse, error) bool) {
-		// First page via POST /search
-		var resp CatalogResponse
-		u := &url.URL{Path: path.Join(catalogBasePath, "search")}
-		err := c.do(ctx, http.MethodPost, u.String(), req, &resp)
-		if !yield(resp, err) {
-			return
-		}
-		if err != nil || resp.Cursor == "" {
-			return
+		cursor := startCursor
+		if cursor == "" {
+			// First page via POST /search
+			var resp CatalogResponse
+			u := &url.URL{Path: path.Join(catalogBasePath, "search")}
+			err := c.do(ctx, http.MethodPost, u.String(), req, &resp)
+			if !yield(resp, err) {
+				return
+			}
+			if err != nil || resp.Cursor == "" {
+				return
+			}
+			cursor = resp.Cursor
 		}
 
 		// Subsequent pages via GET /items with cursor
-		cursor := resp.Cursor
 		for cursor != "" {
 			u := &url.URL{Path: path.Join(catalogBasePath, "items")}
 			q := u.Query()
@@ -241,8 +431,7 @@ func (c *Client) SearchCatalogItems(ctx context.Context, req *SearchRequest) ite
 			}
 			u.RawQuery = q.Encode()
 
-			var pageResp CatalogResponse
-			err := c.do(ctx, http.MethodGet, u.String(), nil, &pageResp)
+			pageResp, err := c.fetchCatalogPage(ctx, u.String(), cursor, req.PageValidators)
 			if !yield(pageResp, err) {
 				return
 			}
@@ -255,15 +444,18 @@ func (c *Client) SearchCatalogItems(ctx context.Context, req *SearchRequest) ite
 }
 
 // PurchaseCatalogItems purchases catalog items.
-// Returns the purchase ID for tracking.
+// Returns the purchase ID for tracking. Like CreateTask, it is retried
+// safely when sent with an idempotency key.
 //
 // POST /catalog/v1/purchases
-func (c *Client) PurchaseCatalogItems(ctx context.Context, req *PurchaseRequest) (*PurchaseResponse, error) {
+func (c *Client) PurchaseCatalogItems(ctx context.Context, req *PurchaseRequest, opts ...CallOption) (*PurchaseResponse, error) {
 	var resp PurchaseResponse
 	u := &url.URL{Path: path.Join(catalogBasePath, "purchases")}
-	if err := c.do(ctx, http.MethodPost, u.String(), req, &resp); err != nil {
+	key := c.idempotencyKey(opts)
+	if err := c.doIdempotent(ctx, http.MethodPost, u.String(), req, &resp, key); err != nil {
 		return nil, err
 	}
+	resp.IdempotencyKey = key
 	return &resp, nil
 }
 
@@ -280,31 +472,43 @@ func (c *Client) GetPurchase(ctx context.Context, purchaseID string) (*Purchase,
 }
 
 // ListPurchases lists all purchases for the authenticated user.
-// Returns an iterator that yields pages of purchases.
+// Returns an iterator that yields pages of purchases, each carrying the
+// Cursor of the next one.
 //
 // GET /catalog/v1/purchases
 func (c *Client) ListPurchases(ctx context.Context, pageSize int) iter.Seq2[PurchasesResponse, error] {
+	return c.ListPurchasesFrom(ctx, pageSize, "")
+}
+
+// ListPurchasesFrom is like ListPurchases but starts at startCursor, the
+// Cursor of a page yielded earlier. A cursor the API no longer accepts fails
+// with a *StaleCursorError.
+func (c *Client) ListPurchasesFrom(ctx context.Context, pageSize int, startCursor string) iter.Seq2[PurchasesResponse, error] {
 	return func(yield func(PurchasesResponse, error) bool) {
-		seq := common.Paginate(func(cur *string) ([]Purchase, *string, error) {
+		cursor := startCursor
+		for {
 			u := &url.URL{Path: path.Join(catalogBasePath, "purchases")}
 			q := u.Query()
 
 			if pageSize > 0 {
 				q.Set("limit", strconv.Itoa(pageSize))
 			}
-			if cur != nil && *cur != "" {
-				q.Set("cursor", *cur)
+			if cursor != "" {
+				q.Set("cursor", cursor)
 			}
 			u.RawQuery = q.Encode()
 
 			var resp PurchasesResponse
 			err := c.do(ctx, http.MethodGet, u.String(), nil, &resp)
-			return resp.Data, &resp.Cursor, err
-		})
-		for data, err := range seq {
-			if !yield(PurchasesResponse{Data: data}, err) {
+			if err != nil {
+				resp = PurchasesResponse{}
+				err = staleCursor(err, cursor)
+			}
+			resp.PageCursor = cursor
+			if !yield(resp, err) || err != nil || resp.Cursor == "" {
 				return
 			}
+			cursor = resp.Cursor
 		}
 	}
 }
@@ -325,7 +529,7 @@ func (c *Client) ListPurchasedProducts(ctx context.Context, purchaseID string) (
 }
 
 // formatBBox formats a bounding box as a comma-separated string.
-func formatBBox(bbox BoundingBox) string {
+func formatBBox(bbox common.BoundingBox) string {
 	return strconv.FormatFloat(bbox[0], 'f', -1, 64) + "," +
 		strconv.FormatFloat(bbox[1], 'f', -1, 64) + "," +
 		strconv.FormatFloat(bbox[2], 'f', -1, 64) + "," +
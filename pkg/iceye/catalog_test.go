@@ -3,44 +3,42 @@ package iceye_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"os"
 	"sync/atomic"
 	"testing"
 
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye/iceyetest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestListCatalogItemsPagination(t *testing.T) {
-	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
-		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
-		mux.HandleFunc("/catalog/v1/items", func(w http.ResponseWriter, r *http.Request) {
-			cursor := r.URL.Query().Get("cursor")
-			switch cursor {
-			case "":
-				json.NewEncoder(w).Encode(iceye.CatalogResponse{
-					Data:   []iceye.STACItem{{ID: "item-1", Type: "Feature"}},
-					Cursor: "next",
-				})
-			case "next":
-				json.NewEncoder(w).Encode(iceye.CatalogResponse{
-					Data:   []iceye.STACItem{{ID: "item-2", Type: "Feature"}},
-					Cursor: "",
-				})
-			}
-		})
-	})
+	srv := iceyetest.NewServer(iceyetest.WithCatalogItems(
+		iceye.STACItem{ID: "item-1", Type: "Feature"},
+		iceye.STACItem{ID: "item-2", Type: "Feature"},
+	))
+	t.Cleanup(srv.Close)
+	cli, err := srv.NewClient()
+	require.NoError(t, err)
 
 	var ids []string
+	var pages int
 	for resp, err := range cli.ListCatalogItems(context.Background(), 1, nil) {
 		require.NoError(t, err)
+		pages++
 		for _, item := range resp.Data {
 			ids = append(ids, item.ID)
 		}
 	}
 
 	assert.Equal(t, []string{"item-1", "item-2"}, ids)
+	assert.Equal(t, 2, pages)
 }
 
 func TestListCatalogItemsWithFilters(t *testing.T) {
@@ -166,19 +164,17 @@ func TestPurchaseCatalogItems(t *testing.T) {
 }
 
 func TestGetPurchase(t *testing.T) {
-	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
-		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
-		mux.HandleFunc("/catalog/v1/purchases/P-789", func(w http.ResponseWriter, r *http.Request) {
-			assert.Equal(t, http.MethodGet, r.Method)
-			json.NewEncoder(w).Encode(iceye.Purchase{
-				ID:           "P-789",
-				CustomerName: "Acme Corp",
-				ContractName: "Test Contract",
-				Status:       iceye.PurchaseStatusClosed,
-				Reference:    "my-ref",
-			})
-		})
+	srv := iceyetest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddPurchase(iceye.Purchase{
+		ID:           "P-789",
+		CustomerName: "Acme Corp",
+		ContractName: "Test Contract",
+		Status:       iceye.PurchaseStatusClosed,
+		Reference:    "my-ref",
 	})
+	cli, err := srv.NewClient()
+	require.NoError(t, err)
 
 	purchase, err := cli.GetPurchase(context.Background(), "P-789")
 
@@ -191,35 +187,12 @@ func TestGetPurchase(t *testing.T) {
 }
 
 func TestListPurchases(t *testing.T) {
-	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
-		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
-		mux.HandleFunc("/catalog/v1/purchases", func(w http.ResponseWriter, r *http.Request) {
-			assert.Equal(t, http.MethodGet, r.Method)
-			cursor := r.URL.Query().Get("cursor")
-			switch cursor {
-			case "":
-				json.NewEncoder(w).Encode(iceye.PurchasesResponse{
-					Data: []iceye.Purchase{{
-						ID:           "P-1",
-						CustomerName: "Acme Corp",
-						ContractName: "Contract A",
-						Status:       iceye.PurchaseStatusActive,
-					}},
-					Cursor: "next",
-				})
-			case "next":
-				json.NewEncoder(w).Encode(iceye.PurchasesResponse{
-					Data: []iceye.Purchase{{
-						ID:           "P-2",
-						CustomerName: "Acme Corp",
-						ContractName: "Contract B",
-						Status:       iceye.PurchaseStatusClosed,
-					}},
-					Cursor: "",
-				})
-			}
-		})
-	})
+	srv := iceyetest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddPurchase(iceye.Purchase{ID: "P-1", CustomerName: "Acme Corp", ContractName: "Contract A", Status: iceye.PurchaseStatusActive})
+	srv.AddPurchase(iceye.Purchase{ID: "P-2", CustomerName: "Acme Corp", ContractName: "Contract B", Status: iceye.PurchaseStatusClosed})
+	cli, err := srv.NewClient()
+	require.NoError(t, err)
 
 	var ids []string
 	for resp, err := range cli.ListPurchases(context.Background(), 1) {
@@ -230,6 +203,7 @@ func TestListPurchases(t *testing.T) {
 	}
 
 	assert.Equal(t, []string{"P-1", "P-2"}, ids)
+	assert.Equal(t, 2, srv.Hits(iceyetest.EndpointListPurchases))
 }
 
 func TestListPurchasedProducts(t *testing.T) {
@@ -250,3 +224,217 @@ func TestListPurchasedProducts(t *testing.T) {
 	assert.Equal(t, "product-item-1", items[0].ID)
 	assert.Equal(t, "product-item-2", items[1].ID)
 }
+
+func TestSTACItem_UnmarshalGeometry(t *testing.T) {
+	data, err := os.ReadFile("testdata/catalog_items.json")
+	require.NoError(t, err)
+	var resp iceye.CatalogResponse
+	require.NoError(t, json.Unmarshal(data, &resp))
+	require.Len(t, resp.Data, 5)
+
+	polygon := resp.Data[0]
+	require.NotNil(t, polygon.Geometry)
+	assert.IsType(t, orb.Polygon{}, polygon.Geometry.Geometry())
+	assert.Equal(t, common.BoundingBox{24.91, 60.14, 24.99, 60.19}, polygon.BBox)
+
+	// Footprints split at the antimeridian come as MultiPolygons with a 3D
+	// bbox.
+	multi := resp.Data[1]
+	require.NotNil(t, multi.Geometry)
+	mp, ok := multi.Geometry.Geometry().(orb.MultiPolygon)
+	require.True(t, ok, "got %T", multi.Geometry.Geometry())
+	assert.Len(t, mp, 2)
+	assert.Equal(t, common.BoundingBox{179.2, -16.9, -179.6, -16.1}, multi.BBox)
+
+	null := resp.Data[2]
+	assert.Nil(t, null.Geometry)
+	assert.Zero(t, null.BBox)
+	_, ok = null.Footprint()
+	assert.False(t, ok)
+
+	// Legacy items wrapped the geometry in a Feature and omitted the bbox.
+	wrapped := resp.Data[3]
+	require.NotNil(t, wrapped.Geometry)
+	assert.IsType(t, orb.Polygon{}, wrapped.Geometry.Geometry())
+	assert.Equal(t, common.BoundingBox{24.5, 60.0, 25.1, 60.4}, wrapped.BBox)
+
+	empty := resp.Data[4]
+	assert.Nil(t, empty.Geometry)
+
+	// Items round-trip through the shared types.
+	out, err := json.Marshal(multi)
+	require.NoError(t, err)
+	var again iceye.STACItem
+	require.NoError(t, json.Unmarshal(out, &again))
+	assert.Equal(t, multi.BBox, again.BBox)
+	assert.Equal(t, mp, again.Geometry.Geometry())
+}
+
+func TestSTACItem_UnmarshalInvalidBBox(t *testing.T) {
+	var item iceye.STACItem
+	err := json.Unmarshal([]byte(`{"id":"x","bbox":[1,2,3]}`), &item)
+	assert.ErrorContains(t, err, "bbox has 3 coordinates")
+}
+
+// The deprecated aliases stay interchangeable with the shared types.
+var (
+	_ *geojson.Geometry  = (*iceye.Geometry)(nil)
+	_ common.BoundingBox = iceye.BoundingBox{}
+	_ *iceye.Geometry    = iceye.STACItem{}.Geometry
+	_ iceye.BoundingBox  = iceye.STACItem{}.BBox
+)
+
+func TestListCatalogItemsResume(t *testing.T) {
+	srv := iceyetest.NewServer(iceyetest.WithCatalogItems(
+		iceye.STACItem{ID: "item-1", Type: "Feature"},
+		iceye.STACItem{ID: "item-2", Type: "Feature"},
+		iceye.STACItem{ID: "item-3", Type: "Feature"},
+	))
+	t.Cleanup(srv.Close)
+	cli, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// The sync dies after checkpointing the first page.
+	var checkpoint string
+	for resp, err := range cli.ListCatalogItems(ctx, 1, nil) {
+		require.NoError(t, err)
+		assert.Empty(t, resp.PageCursor)
+		require.NotEmpty(t, resp.Cursor, "page carries the next cursor")
+		checkpoint = resp.Cursor
+		break
+	}
+
+	var ids, pageCursors []string
+	prev := checkpoint
+	for resp, err := range cli.ListCatalogItemsFrom(ctx, 1, nil, checkpoint) {
+		require.NoError(t, err)
+		assert.Equal(t, prev, resp.PageCursor)
+		pageCursors = append(pageCursors, resp.PageCursor)
+		prev = resp.Cursor
+		for _, item := range resp.Data {
+			ids = append(ids, item.ID)
+		}
+	}
+	assert.Equal(t, []string{"item-2", "item-3"}, ids)
+	assert.Len(t, pageCursors, 2)
+	assert.Empty(t, prev, "last page has no cursor")
+}
+
+func TestSearchCatalogItemsResume(t *testing.T) {
+	srv := iceyetest.NewServer(iceyetest.WithCatalogItems(
+		iceye.STACItem{ID: "item-1", Type: "Feature"},
+		iceye.STACItem{ID: "item-2", Type: "Feature"},
+		iceye.STACItem{ID: "item-3", Type: "Feature"},
+	))
+	t.Cleanup(srv.Close)
+	cli, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+	req := &iceye.SearchRequest{Limit: 1}
+
+	var checkpoint string
+	for resp, err := range cli.SearchCatalogItems(ctx, req) {
+		require.NoError(t, err)
+		require.Equal(t, "item-1", resp.Data[0].ID)
+		checkpoint = resp.Cursor
+		break
+	}
+	require.NotEmpty(t, checkpoint)
+
+	var ids []string
+	for resp, err := range cli.SearchCatalogItemsFrom(ctx, req, checkpoint) {
+		require.NoError(t, err)
+		for _, item := range resp.Data {
+			ids = append(ids, item.ID)
+		}
+	}
+	assert.Equal(t, []string{"item-2", "item-3"}, ids)
+	assert.Equal(t, 1, srv.Hits(iceyetest.EndpointCatalogSearch), "resuming does not search again")
+}
+
+func TestListPurchasesResume(t *testing.T) {
+	srv := iceyetest.NewServer()
+	t.Cleanup(srv.Close)
+	for _, id := range []string{"P-1", "P-2", "P-3"} {
+		srv.AddPurchase(iceye.Purchase{ID: id, Status: iceye.PurchaseStatusActive})
+	}
+	cli, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var cursors []string
+	for resp, err := range cli.ListPurchases(ctx, 1) {
+		require.NoError(t, err)
+		cursors = append(cursors, resp.Cursor)
+	}
+	require.Len(t, cursors, 3)
+	assert.NotEmpty(t, cursors[0])
+	assert.NotEmpty(t, cursors[1])
+	assert.Empty(t, cursors[2])
+
+	var ids []string
+	for resp, err := range cli.ListPurchasesFrom(ctx, 1, cursors[1]) {
+		require.NoError(t, err)
+		assert.Equal(t, cursors[1], resp.PageCursor)
+		for _, p := range resp.Data {
+			ids = append(ids, p.ID)
+		}
+	}
+	assert.Equal(t, []string{"P-3"}, ids)
+}
+
+func TestStaleCursor(t *testing.T) {
+	srv := iceyetest.NewServer(iceyetest.WithCatalogItems(iceye.STACItem{ID: "item-1", Type: "Feature"}))
+	t.Cleanup(srv.Close)
+	cli, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("catalog items", func(t *testing.T) {
+		for resp, err := range cli.ListCatalogItemsFrom(ctx, 1, nil, "expired") {
+			var stale *iceye.StaleCursorError
+			require.ErrorAs(t, err, &stale)
+			assert.Equal(t, "expired", stale.Cursor)
+			assert.Equal(t, "expired", resp.PageCursor)
+
+			var apiErr *iceye.Error
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+		}
+	})
+
+	t.Run("purchases", func(t *testing.T) {
+		for _, err := range cli.ListPurchasesFrom(ctx, 1, "not-a-cursor") {
+			var stale *iceye.StaleCursorError
+			assert.ErrorAs(t, err, &stale)
+		}
+	})
+
+	t.Run("other errors are not stale", func(t *testing.T) {
+		srv.Fail(iceyetest.EndpointCatalogItems, iceyetest.ServerError(http.StatusServiceUnavailable))
+		for _, err := range cli.ListCatalogItemsFrom(ctx, 1, nil, "expired") {
+			var stale *iceye.StaleCursorError
+			require.Error(t, err)
+			assert.False(t, errors.As(err, &stale), "a server error")
+			assert.True(t, iceye.IsServerError(err))
+		}
+	})
+}
+
+func TestStaleCursorGone(t *testing.T) {
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/catalog/v1/items", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusGone)
+			json.NewEncoder(w).Encode(iceye.Error{Code: "ERR_GONE", Status: http.StatusGone})
+		})
+	})
+
+	for _, err := range cli.SearchCatalogItemsFrom(context.Background(), &iceye.SearchRequest{}, "old") {
+		var stale *iceye.StaleCursorError
+		require.ErrorAs(t, err, &stale)
+		assert.Equal(t, "old", stale.Cursor)
+	}
+}
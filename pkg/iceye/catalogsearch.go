@@ -0,0 +1,173 @@
+package iceye
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// ----------------------------------------------------------------------------
+// Catalog Search Builder
+// ----------------------------------------------------------------------------
+
+// Query properties supported by the catalog search Query Extension.
+const (
+	QueryStartTime            = "start_time"
+	QueryEndTime              = "end_time"
+	QueryImageMode            = "image_mode"
+	QueryInstrumentMode       = "instrument_mode"
+	QueryProductType          = "product_type"
+	QueryObservationDirection = "observation_direction"
+	QueryOrbitState           = "orbit_state"
+	QueryIncidenceAngle       = "incidence_angle"
+	QuerySatelliteLookAngle   = "satellite_look_angle"
+)
+
+// queryProperties is the documented set of Query Extension properties.
+var queryProperties = []string{
+	QueryStartTime, QueryEndTime, QueryImageMode, QueryInstrumentMode, QueryProductType,
+	QueryObservationDirection, QueryOrbitState, QueryIncidenceAngle, QuerySatelliteLookAngle,
+}
+
+// CatalogSearchBuilder builds a SearchRequest with typed query filters.
+// Problems such as unsupported property names or conflicting filters are
+// collected and reported by Build.
+type CatalogSearchBuilder struct {
+	req      SearchRequest
+	acquired bool // Datetime was set by AcquiredBetween
+	errs     []error
+}
+
+// NewCatalogSearchBuilder creates a new catalog search builder.
+func NewCatalogSearchBuilder() *CatalogSearchBuilder {
+	return &CatalogSearchBuilder{}
+}
+
+// Where adds a filter on property, which must be one of the documented query
+// properties (see the Query* constants). Each property can be filtered once.
+func (b *CatalogSearchBuilder) Where(property string, f QueryFilter) *CatalogSearchBuilder {
+	if !slices.Contains(queryProperties, property) {
+		b.errs = append(b.errs, fmt.Errorf("unsupported query property %q", property))
+		return b
+	}
+	if _, ok := b.req.Query[property]; ok {
+		b.errs = append(b.errs, fmt.Errorf("conflicting filters for %s", property))
+		return b
+	}
+	if b.req.Query == nil {
+		b.req.Query = make(map[string]QueryFilter)
+	}
+	b.req.Query[property] = f
+	return b
+}
+
+// InstrumentMode filters by instrument mode.
+func (b *CatalogSearchBuilder) InstrumentMode(eq string) *CatalogSearchBuilder {
+	return b.Where(QueryInstrumentMode, QueryFilter{Eq: eq})
+}
+
+// ProductType filters by any of the given product types.
+func (b *CatalogSearchBuilder) ProductType(in ...string) *CatalogSearchBuilder {
+	if len(in) == 0 {
+		b.errs = append(b.errs, errors.New("product type filter needs at least one value"))
+		return b
+	}
+	values := make([]any, len(in))
+	for i, v := range in {
+		values[i] = v
+	}
+	return b.Where(QueryProductType, QueryFilter{In: values})
+}
+
+// IncidenceAngleBetween filters by incidence angle, in degrees, inclusive.
+func (b *CatalogSearchBuilder) IncidenceAngleBetween(min, max float64) *CatalogSearchBuilder {
+	if min < 0 || max > 90 || min > max {
+		b.errs = append(b.errs, fmt.Errorf("invalid incidence angle range %g-%g", min, max))
+		return b
+	}
+	return b.Where(QueryIncidenceAngle, QueryFilter{Gte: min, Lte: max})
+}
+
+// OrbitState filters by orbit state, e.g. "ascending" or "descending".
+func (b *CatalogSearchBuilder) OrbitState(s string) *CatalogSearchBuilder {
+	return b.Where(QueryOrbitState, QueryFilter{Eq: s})
+}
+
+// AcquiredBetween restricts results to acquisitions within [from, to]. It sets
+// both the datetime interval and matching start_time and end_time filters,
+// so the two cannot disagree.
+func (b *CatalogSearchBuilder) AcquiredBetween(from, to time.Time) *CatalogSearchBuilder {
+	if from.IsZero() || to.IsZero() || from.After(to) {
+		b.errs = append(b.errs, fmt.Errorf("invalid acquisition interval %s/%s",
+			from.Format(time.RFC3339), to.Format(time.RFC3339)))
+		return b
+	}
+	if b.req.Datetime != "" {
+		b.errs = append(b.errs, errors.New("conflicting filters for datetime"))
+		return b
+	}
+	start, end := from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339)
+	b.req.Datetime = start + "/" + end
+	b.acquired = true
+	b.Where(QueryStartTime, QueryFilter{Gte: start})
+	return b.Where(QueryEndTime, QueryFilter{Lte: end})
+}
+
+// Datetime sets a raw RFC 3339 datetime or interval. Prefer AcquiredBetween.
+func (b *CatalogSearchBuilder) Datetime(datetime string) *CatalogSearchBuilder {
+	if b.req.Datetime != "" {
+		b.errs = append(b.errs, errors.New("conflicting filters for datetime"))
+		return b
+	}
+	b.req.Datetime = datetime
+	return b
+}
+
+// BBox sets the bounding box filter.
+func (b *CatalogSearchBuilder) BBox(bbox common.BoundingBox) *CatalogSearchBuilder {
+	b.req.BBox = &bbox
+	return b
+}
+
+// IDs restricts results to the given item IDs.
+func (b *CatalogSearchBuilder) IDs(ids ...string) *CatalogSearchBuilder {
+	b.req.IDs = ids
+	return b
+}
+
+// SortByStartTimeDesc sorts results newest first.
+func (b *CatalogSearchBuilder) SortByStartTimeDesc() *CatalogSearchBuilder {
+	b.req.SortBy = append(b.req.SortBy, SortCondition{Field: QueryStartTime, Direction: "desc"})
+	return b
+}
+
+// Limit sets the maximum results per page.
+func (b *CatalogSearchBuilder) Limit(limit int) *CatalogSearchBuilder {
+	b.req.Limit = limit
+	return b
+}
+
+// Build returns the search request, or the problems found while building it.
+// A raw Datetime combined with an eq filter on start_time or end_time is
+// rejected as conflicting.
+func (b *CatalogSearchBuilder) Build() (*SearchRequest, error) {
+	errs := slices.Clone(b.errs)
+	if b.req.Datetime != "" && !b.acquired {
+		for _, p := range []string{QueryStartTime, QueryEndTime} {
+			if f, ok := b.req.Query[p]; ok && f.Eq != nil {
+				errs = append(errs, fmt.Errorf("conflicting filters: datetime and %s eq", p))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("iceye: invalid catalog search: %w", err)
+	}
+	req := b.req
+	req.Query = maps.Clone(b.req.Query)
+	req.SortBy = slices.Clone(b.req.SortBy)
+	return &req, nil
+}
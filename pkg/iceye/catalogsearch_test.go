@@ -0,0 +1,96 @@
+package iceye_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogSearchBuilder(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 1, 12, 0, 0, 0, time.FixedZone("EEST", 3*3600))
+
+	req, err := iceye.NewCatalogSearchBuilder().
+		BBox(iceye.BoundingBox{24.0, 59.5, 25.5, 60.5}).
+		AcquiredBetween(from, to).
+		InstrumentMode("spotlight").
+		ProductType("GRD", "SLC").
+		IncidenceAngleBetween(20, 35.5).
+		OrbitState("descending").
+		SortByStartTimeDesc().
+		Limit(20).
+		Build()
+	require.NoError(t, err)
+
+	got, err := json.MarshalIndent(req, "", "  ")
+	require.NoError(t, err)
+	golden, err := os.ReadFile("testdata/catalog_search.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, string(golden), string(got))
+	assert.Equal(t, string(golden), string(got)+"\n", "query map should serialize deterministically")
+}
+
+func TestCatalogSearchBuilderValidation(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name  string
+		build func(b *iceye.CatalogSearchBuilder)
+		want  string
+	}{
+		{"camelCase property", func(b *iceye.CatalogSearchBuilder) {
+			b.Where("incidenceAngle", iceye.QueryFilter{Gte: 10})
+		}, `unsupported query property "incidenceAngle"`},
+		{"prefixed property", func(b *iceye.CatalogSearchBuilder) {
+			b.Where("sar:product_type", iceye.QueryFilter{Eq: "GRD"})
+		}, `unsupported query property "sar:product_type"`},
+		{"datetime and start_time eq", func(b *iceye.CatalogSearchBuilder) {
+			b.Datetime("2024-01-01T00:00:00Z/2024-02-01T00:00:00Z").
+				Where(iceye.QueryStartTime, iceye.QueryFilter{Eq: "2024-01-05T00:00:00Z"})
+		}, "datetime and start_time eq"},
+		{"acquired twice", func(b *iceye.CatalogSearchBuilder) {
+			b.AcquiredBetween(from, to).AcquiredBetween(from, to)
+		}, "conflicting filters for datetime"},
+		{"acquired and start_time", func(b *iceye.CatalogSearchBuilder) {
+			b.Where(iceye.QueryStartTime, iceye.QueryFilter{Gte: "2024-01-05T00:00:00Z"}).AcquiredBetween(from, to)
+		}, "conflicting filters for start_time"},
+		{"duplicate property", func(b *iceye.CatalogSearchBuilder) {
+			b.OrbitState("ascending").OrbitState("descending")
+		}, "conflicting filters for orbit_state"},
+		{"reversed interval", func(b *iceye.CatalogSearchBuilder) {
+			b.AcquiredBetween(to, from)
+		}, "invalid acquisition interval"},
+		{"incidence out of range", func(b *iceye.CatalogSearchBuilder) {
+			b.IncidenceAngleBetween(30, 95)
+		}, "invalid incidence angle range"},
+		{"empty product types", func(b *iceye.CatalogSearchBuilder) {
+			b.ProductType()
+		}, "product type filter needs at least one value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := iceye.NewCatalogSearchBuilder()
+			tt.build(b)
+			req, err := b.Build()
+			assert.Nil(t, req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestCatalogSearchBuilderWhere(t *testing.T) {
+	req, err := iceye.NewCatalogSearchBuilder().
+		Datetime("2024-01-01T00:00:00Z/..").
+		Where(iceye.QuerySatelliteLookAngle, iceye.QueryFilter{Lte: 25}).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-01T00:00:00Z/..", req.Datetime)
+	assert.Equal(t, iceye.QueryFilter{Lte: 25}, req.Query["satellite_look_angle"])
+}
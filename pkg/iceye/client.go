@@ -42,7 +42,18 @@ const (
 // Client is the ICEYE API client. It is thread-safe.
 type Client struct {
 	*common.Client
+	auth      common.Authenticator
 	userAgent string
+	prices    *priceCache
+
+	// autoIdempotency makes keyed calls generate a key when none is given.
+	autoIdempotency   bool
+	idempotentRetries int
+	idempotentBackoff time.Duration
+
+	// compressMin is the body size from which requests are gzipped; 0
+	// disables request compression.
+	compressMin int
 }
 
 // Option configures a Client.
@@ -55,6 +66,17 @@ type clientConfig struct {
 	timeout    time.Duration
 	userAgent  string
 	auth       common.Authenticator
+	priceTTL   time.Duration
+
+	compressMin int
+
+	autoIdempotency   bool
+	idempotentRetries int
+
+	middlewares []Middleware
+	// chained is set once the middlewares wrap httpClient, so the second
+	// pass over the options does not add them again.
+	chained bool
 }
 
 // WithBaseURL sets a custom base URL.
@@ -74,7 +96,9 @@ func WithTokenURL(tokenURL string) Option {
 // WithHTTPClient sets a custom HTTP client.
 func WithHTTPClient(client *http.Client) Option {
 	return func(c *clientConfig) {
-		c.httpClient = client
+		if !c.chained {
+			c.httpClient = client
+		}
 	}
 }
 
@@ -106,6 +130,28 @@ func WithResourceOwner(apiKey, username, password string) Option {
 	}
 }
 
+// WithPriceCache caches GetTaskPrice quotes in memory for ttl, or until the
+// quote's ExpiresAt if that is sooner, keyed on the full set of request
+// parameters. Use InvalidatePriceCache to drop them early.
+func WithPriceCache(ttl time.Duration) Option {
+	return func(c *clientConfig) {
+		c.priceTTL = ttl
+	}
+}
+
+// WithRequestCompression gzips request bodies of at least minSize bytes and
+// sets Content-Encoding accordingly. A minSize of zero or less uses a default
+// of 8 KiB. Responses are always accepted and decoded gzipped, whatever the
+// HTTP client's transport settings.
+func WithRequestCompression(minSize int) Option {
+	return func(c *clientConfig) {
+		if minSize <= 0 {
+			minSize = defaultCompressionMinSize
+		}
+		c.compressMin = minSize
+	}
+}
+
 // WithAuth sets a custom authenticator.
 func WithAuth(auth common.Authenticator) Option {
 	return func(c *clientConfig) {
@@ -121,6 +167,8 @@ func NewClient(opts ...Option) (*Client, error) {
 		tokenURL:  DefaultTokenURL,
 		timeout:   defaultTimeout,
 		userAgent: defaultUserAgent,
+
+		idempotentRetries: DefaultIdempotentRetries,
 	}
 
 	// First pass: set base config values
@@ -129,6 +177,10 @@ func NewClient(opts ...Option) (*Client, error) {
 	}
 
 	httpClient := common.EnsureHTTPClient(cfg.httpClient, cfg.timeout)
+	if len(cfg.middlewares) > 0 {
+		httpClient = chain(httpClient, cfg.middlewares)
+		cfg.chained = true
+	}
 	cfg.httpClient = httpClient
 
 	// Second pass: apply auth options that depend on tokenURL and httpClient
@@ -150,68 +202,122 @@ func NewClient(opts ...Option) (*Client, error) {
 		return nil, err
 	}
 
-	return &Client{
-		Client:    c,
-		userAgent: cfg.userAgent,
-	}, nil
+	cli := &Client{
+		Client:      c,
+		auth:        cfg.auth,
+		userAgent:   cfg.userAgent,
+		compressMin: cfg.compressMin,
+
+		autoIdempotency:   cfg.autoIdempotency,
+		idempotentRetries: cfg.idempotentRetries,
+		idempotentBackoff: defaultIdempotentBackoff,
+	}
+	if cfg.priceTTL > 0 {
+		cli.prices = newPriceCache(cfg.priceTTL)
+	}
+	return cli, nil
 }
 
 // do performs an HTTP request with JSON encode/decode and ICEYE-specific error handling.
 func (c *Client) do(ctx context.Context, method, urlStr string, in any, out any) error {
+	_, _, err := c.doConditional(ctx, method, urlStr, in, out, CacheValidator{})
+	return err
+}
+
+// doConditional is do with conditional request headers from v. If v is set
+// and the server responds 304, out is left untouched and notModified is
+// true. The validators of the response are returned either way.
+func (c *Client) doConditional(ctx context.Context, method, urlStr string, in any, out any, v CacheValidator) (validator CacheValidator, notModified bool, err error) {
+	return c.send(ctx, method, urlStr, in, out, v, nil)
+}
+
+// send performs a request like doConditional, adding header to the request
+// headers.
+func (c *Client) send(ctx context.Context, method, urlStr string, in any, out any, v CacheValidator, header http.Header) (validator CacheValidator, notModified bool, err error) {
 	// Parse the path as a URL (may contain query string)
 	pathURL, err := url.Parse(urlStr)
 	if err != nil {
-		return fmt.Errorf("parse URL path: %w", err)
+		return validator, false, fmt.Errorf("parse URL path: %w", err)
 	}
 
 	// Resolve against base URL
 	fullURL := c.BaseURL().ResolveReference(pathURL)
 
-	var body io.Reader
+	var (
+		body       io.Reader
+		compressed bool
+	)
 	if in != nil {
 		b, err := json.Marshal(in)
 		if err != nil {
-			return fmt.Errorf("marshal request body: %w", err)
+			return validator, false, fmt.Errorf("marshal request body: %w", err)
+		}
+		if c.compressMin > 0 && len(b) >= c.compressMin {
+			if b, err = gzipBytes(b); err != nil {
+				return validator, false, fmt.Errorf("compress request body: %w", err)
+			}
+			compressed = true
 		}
 		body = bytes.NewReader(b)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, fullURL.String(), body)
 	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+		return validator, false, fmt.Errorf("create request: %w", err)
 	}
 
 	// Set headers
 	req.Header.Set("Accept", "application/json, application/problem+json")
+	// Setting Accept-Encoding ourselves keeps the transport from decoding
+	// gzip, so responses are decoded below whether or not the transport
+	// would have.
+	req.Header.Set("Accept-Encoding", "gzip")
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 	if c.userAgent != "" {
 		req.Header.Set("User-Agent", c.userAgent)
 	}
+	v.apply(req.Header)
+	for k, vals := range header {
+		req.Header[k] = vals
+	}
 
 	// Apply auth
 	if err := c.Client.ApplyAuth(ctx, req); err != nil {
-		return err
+		return validator, false, err
 	}
 
 	resp, err := c.HTTPClient().Do(req)
 	if err != nil {
-		return fmt.Errorf("do request: %w", err)
+		return validator, false, fmt.Errorf("do request: %w", err)
+	}
+	// decompressResponse may replace the body; close whichever is current.
+	defer func() { resp.Body.Close() }()
+
+	if err := decompressResponse(resp); err != nil {
+		return validator, false, err
+	}
+
+	validator = validatorFromHeader(resp.Header)
+	if resp.StatusCode == http.StatusNotModified && !v.IsZero() {
+		return validator.or(v), true, nil
 	}
-	defer resp.Body.Close()
 
 	// Check for error status
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return parseError(resp)
+		return validator, false, parseError(resp)
 	}
 
 	// Decode response
 	if out != nil {
 		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
-			return fmt.Errorf("decode response: %w", err)
+			return validator, false, fmt.Errorf("decode response: %w", err)
 		}
 	}
 
-	return nil
+	return validator, false, nil
 }
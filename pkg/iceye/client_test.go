@@ -3,8 +3,10 @@ package iceye_test
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -117,6 +119,99 @@ func TestDoParsesProblemJSON(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, apiErr.Status)
 }
 
+func TestDoParsesProblemDocument(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		check       func(t *testing.T, e *iceye.Error)
+	}{
+		{
+			name:        "full problem document",
+			contentType: "application/problem+json",
+			body: `{
+				"type": "https://docs.iceye.com/errors/validation",
+				"title": "Validation failed",
+				"status": 400,
+				"code": "ERR_VALIDATION",
+				"detail": "request has invalid fields",
+				"instance": "/api/tasking/v2/tasks/req-8f3a",
+				"errors": [
+					{"field": "acquisitionWindow.end", "reason": "must be after start"},
+					{"pointer": "contractID", "message": "unknown contract"}
+				]
+			}`,
+			check: func(t *testing.T, e *iceye.Error) {
+				assert.Equal(t, "ERR_VALIDATION", e.Code)
+				assert.Equal(t, "Validation failed", e.Title)
+				assert.Equal(t, "/api/tasking/v2/tasks/req-8f3a", e.Instance)
+				require.Len(t, e.Violations, 2)
+				assert.True(t, e.IsValidation())
+
+				v, ok := e.Violation("contractID")
+				require.True(t, ok)
+				assert.Equal(t, "unknown contract", v.Reason)
+				_, ok = e.Violation("missing")
+				assert.False(t, ok)
+
+				assert.Equal(t, "iceye: ERR_VALIDATION (400) – request has invalid fields [acquisitionWindow.end: must be after start]", e.Error())
+			},
+		},
+		{
+			name:        "minimal document",
+			contentType: "application/problem+json",
+			body:        `{"title": "Bad Request", "status": 400}`,
+			check: func(t *testing.T, e *iceye.Error) {
+				assert.Equal(t, "Bad Request", e.Code)
+				assert.Equal(t, "Bad Request", e.Title)
+				assert.Empty(t, e.Detail)
+				assert.False(t, e.IsValidation())
+				assert.Equal(t, "iceye: Bad Request (400)", e.Error())
+			},
+		},
+		{
+			name:        "HTML body",
+			contentType: "text/html",
+			body:        "<html><body>" + strings.Repeat("502 Bad Gateway ", 100) + "</body></html>",
+			check: func(t *testing.T, e *iceye.Error) {
+				assert.Equal(t, "Bad Request", e.Code)
+				assert.True(t, strings.HasPrefix(e.Detail, "<html><body>502 Bad Gateway"))
+				assert.LessOrEqual(t, len(e.Detail), 520)
+				assert.Empty(t, e.Violations)
+			},
+		},
+		{
+			name: "empty body",
+			check: func(t *testing.T, e *iceye.Error) {
+				assert.Equal(t, "Bad Request", e.Code)
+				assert.Empty(t, e.Detail)
+				assert.Equal(t, "iceye: Bad Request (400)", e.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+				mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+				mux.HandleFunc("/company/v1/contracts/bad", func(w http.ResponseWriter, r *http.Request) {
+					if tt.contentType != "" {
+						w.Header().Set("Content-Type", tt.contentType)
+					}
+					w.WriteHeader(http.StatusBadRequest)
+					io.WriteString(w, tt.body)
+				})
+			})
+
+			_, err := cli.GetContract(context.Background(), "bad")
+			var apiErr *iceye.Error
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+			tt.check(t, apiErr)
+		})
+	}
+}
+
 func TestTokenRefreshAfterExpiry(t *testing.T) {
 	tokenCalls := &atomic.Int32{}
 	mux := http.NewServeMux()
@@ -6,10 +6,10 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
-
-	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
 
 // ----------------------------------------------------------------------------
@@ -37,6 +37,32 @@ type OptionConfig struct {
 	Default string   `json:"default"`
 }
 
+// ActiveAt reports whether t lies within the contract period. A zero End
+// means the contract does not expire.
+func (c Contract) ActiveAt(t time.Time) bool {
+	return !t.Before(c.Start) && (c.End.IsZero() || t.Before(c.End))
+}
+
+// AllowsMode reports whether the contract permits imaging mode m. A contract
+// without an imaging mode policy does not restrict modes.
+func (c Contract) AllowsMode(m ImagingMode) bool {
+	if c.ImagingModes == nil || len(c.ImagingModes.Allowed) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(c.ImagingModes.Allowed, func(a string) bool {
+		return strings.EqualFold(a, string(m))
+	})
+}
+
+// DefaultPriority returns the contract's default task priority, or "" if the
+// contract has no priority policy.
+func (c Contract) DefaultPriority() Priority {
+	if c.Priority == nil {
+		return ""
+	}
+	return Priority(c.Priority.Default)
+}
+
 // Summary represents contract budget information.
 type Summary struct {
 	ContractID        string `json:"contractID"`
@@ -60,34 +86,76 @@ type ContractsResponse struct {
 const companyBasePath = "/company/v1"
 
 // ListContracts retrieves all contracts for the authenticated company.
-// Returns an iterator that yields pages of contracts.
+// Returns an iterator that yields pages of contracts; each page carries the
+// cursor of the next page, empty on the last page.
 //
 // GET /company/v1/contracts
 func (c *Client) ListContracts(ctx context.Context, pageSize int) iter.Seq2[ContractsResponse, error] {
 	return func(yield func(ContractsResponse, error) bool) {
-		seq := common.Paginate(func(cur *string) ([]Contract, *string, error) {
+		var cursor string
+		for {
 			u := &url.URL{Path: path.Join(companyBasePath, "contracts")}
 			q := u.Query()
 			if pageSize > 0 {
 				q.Set("limit", strconv.Itoa(pageSize))
 			}
-			if cur != nil && *cur != "" {
-				q.Set("cursor", *cur)
+			if cursor != "" {
+				q.Set("cursor", cursor)
 			}
 			u.RawQuery = q.Encode()
 
 			var resp ContractsResponse
 			err := c.do(ctx, http.MethodGet, u.String(), nil, &resp)
-			return resp.Data, &resp.Cursor, err
-		})
-		for data, err := range seq {
-			if !yield(ContractsResponse{Data: data}, err) {
+			if !yield(resp, err) || err != nil || resp.Cursor == "" {
 				return
 			}
+			cursor = resp.Cursor
 		}
 	}
 }
 
+// ListContractsIter returns an iterator over all contracts of the
+// authenticated company, fetching pages as needed. Stopping the iteration
+// stops fetching.
+func (c *Client) ListContractsIter(ctx context.Context) iter.Seq2[Contract, error] {
+	return func(yield func(Contract, error) bool) {
+		for page, err := range c.ListContracts(ctx, 0) {
+			if err != nil {
+				yield(Contract{}, err)
+				return
+			}
+			for _, contract := range page.Data {
+				if !yield(contract, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// FindContract returns the first contract matching pred. It stops fetching
+// pages once a match is found, and reports false if no contract matches.
+func (c *Client) FindContract(ctx context.Context, pred func(Contract) bool) (*Contract, bool, error) {
+	for contract, err := range c.ListContractsIter(ctx) {
+		if err != nil {
+			return nil, false, err
+		}
+		if pred(contract) {
+			return &contract, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// ActiveContractByName returns the contract with the given name that is
+// active now, skipping expired and not yet started contracts of that name.
+func (c *Client) ActiveContractByName(ctx context.Context, name string) (*Contract, bool, error) {
+	now := time.Now()
+	return c.FindContract(ctx, func(contract Contract) bool {
+		return contract.Name == name && contract.ActiveAt(now)
+	})
+}
+
 // GetContract retrieves a specific contract by ID.
 //
 // GET /company/v1/contracts/{contractID}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
 	"github.com/stretchr/testify/assert"
@@ -45,6 +47,104 @@ func TestListContractsPagination(t *testing.T) {
 	assert.Equal(t, []string{"c1", "c2"}, ids)
 }
 
+// contractPages serves the given pages of contracts in order and counts the
+// pages fetched.
+func contractPages(hits *atomic.Int32, pages ...[]iceye.Contract) func(*http.ServeMux, *atomic.Int32) {
+	return func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/company/v1/contracts", func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			i := 0
+			if c := r.URL.Query().Get("cursor"); c != "" {
+				i, _ = strconv.Atoi(c)
+			}
+			resp := iceye.ContractsResponse{Data: pages[i]}
+			if i+1 < len(pages) {
+				resp.Cursor = strconv.Itoa(i + 1)
+			}
+			json.NewEncoder(w).Encode(resp)
+		})
+	}
+}
+
+func TestListContractsIter(t *testing.T) {
+	var hits atomic.Int32
+	cli, _, _ := newTestClient(t, contractPages(&hits,
+		[]iceye.Contract{{ID: "c1"}, {ID: "c2"}},
+		[]iceye.Contract{{ID: "c3"}},
+		[]iceye.Contract{{ID: "c4"}},
+	))
+
+	var ids []string
+	for c, err := range cli.ListContractsIter(context.Background()) {
+		require.NoError(t, err)
+		ids = append(ids, c.ID)
+	}
+
+	assert.Equal(t, []string{"c1", "c2", "c3", "c4"}, ids)
+	assert.Equal(t, int32(3), hits.Load())
+}
+
+func TestFindContractStopsPaging(t *testing.T) {
+	var hits atomic.Int32
+	cli, _, _ := newTestClient(t, contractPages(&hits,
+		[]iceye.Contract{{ID: "c1"}},
+		[]iceye.Contract{{ID: "c2"}},
+		[]iceye.Contract{{ID: "c3"}},
+	))
+
+	c, ok, err := cli.FindContract(context.Background(), func(c iceye.Contract) bool { return c.ID == "c2" })
+
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "c2", c.ID)
+	assert.Equal(t, int32(2), hits.Load(), "pages after the match must not be fetched")
+
+	_, ok, err = cli.FindContract(context.Background(), func(iceye.Contract) bool { return false })
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestActiveContractByName(t *testing.T) {
+	now := time.Now()
+	var hits atomic.Int32
+	cli, _, _ := newTestClient(t, contractPages(&hits,
+		[]iceye.Contract{
+			{ID: "expired", Name: "Ops", Start: now.Add(-48 * time.Hour), End: now.Add(-24 * time.Hour)},
+			{ID: "future", Name: "Ops", Start: now.Add(24 * time.Hour), End: now.Add(48 * time.Hour)},
+		},
+		[]iceye.Contract{
+			{ID: "other", Name: "Research", Start: now.Add(-time.Hour), End: now.Add(time.Hour)},
+			{ID: "active", Name: "Ops", Start: now.Add(-time.Hour), End: now.Add(time.Hour)},
+		},
+	))
+
+	c, ok, err := cli.ActiveContractByName(context.Background(), "Ops")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "active", c.ID)
+
+	_, ok, err = cli.ActiveContractByName(context.Background(), "Missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestContractPolicyHelpers(t *testing.T) {
+	c := iceye.Contract{
+		ImagingModes: &iceye.OptionConfig{Allowed: []string{"SPOTLIGHT", "STRIPMAP"}},
+		Priority:     &iceye.OptionConfig{Allowed: []string{"BACKGROUND", "COMMERCIAL"}, Default: "COMMERCIAL"},
+	}
+
+	assert.True(t, c.AllowsMode(iceye.ImagingModeSpotlight))
+	assert.True(t, c.AllowsMode("stripmap"))
+	assert.False(t, c.AllowsMode(iceye.ImagingModeScan))
+	assert.Equal(t, iceye.PriorityCommercial, c.DefaultPriority())
+
+	var open iceye.Contract
+	assert.True(t, open.AllowsMode(iceye.ImagingModeScan), "no policy means no restriction")
+	assert.Empty(t, open.DefaultPriority())
+}
+
 func TestGetContract(t *testing.T) {
 	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
 		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
@@ -0,0 +1,126 @@
+package iceye_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCompressionClient returns a client whose transport never decodes gzip
+// itself, as with a tracing transport that disables compression.
+func newCompressionClient(t *testing.T, mux *http.ServeMux, opts ...iceye.Option) *iceye.Client {
+	t.Helper()
+	mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	hc := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	cli, err := iceye.NewClient(append([]iceye.Option{
+		iceye.WithBaseURL(srv.URL),
+		iceye.WithTokenURL(srv.URL + "/oauth2/token"),
+		iceye.WithHTTPClient(hc),
+		iceye.WithCredentials("test", "secret"),
+	}, opts...)...)
+	require.NoError(t, err)
+	return cli
+}
+
+// writeGzipJSON writes v as a gzip-encoded JSON response.
+func writeGzipJSON(t *testing.T, w http.ResponseWriter, contentType string, status int, v any) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	require.NoError(t, json.NewEncoder(zw).Encode(v))
+	require.NoError(t, zw.Close())
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Encoding", "gzip")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// readBody returns the request body, decoding it if gzip-encoded.
+func readBody(t *testing.T, r *http.Request) []byte {
+	t.Helper()
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body = zr
+	}
+	b, err := io.ReadAll(body)
+	require.NoError(t, err)
+	return b
+}
+
+func TestGzipResponse(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/catalog/v1/purchases/p-1", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Accept-Encoding"))
+		writeGzipJSON(t, w, "application/json", http.StatusOK, map[string]any{"id": "p-1", "status": "active"})
+	})
+	cli := newCompressionClient(t, mux)
+
+	p, err := cli.GetPurchase(context.Background(), "p-1")
+	require.NoError(t, err)
+	assert.Equal(t, "p-1", p.ID)
+}
+
+func TestGzipErrorBody(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/catalog/v1/purchases/p-1", func(w http.ResponseWriter, r *http.Request) {
+		writeGzipJSON(t, w, "application/problem+json", http.StatusNotFound, map[string]any{
+			"code":   "ERR_NOT_FOUND",
+			"detail": "purchase p-1 not found",
+		})
+	})
+	cli := newCompressionClient(t, mux)
+
+	_, err := cli.GetPurchase(context.Background(), "p-1")
+	var apiErr *iceye.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.Status)
+	assert.Equal(t, "purchase p-1 not found", apiErr.Detail)
+}
+
+func TestRequestCompression(t *testing.T) {
+	long := &iceye.PurchaseRequest{ContractID: "c-1", ItemIDs: []string{strings.Repeat("x", 200)}}
+	short := &iceye.PurchaseRequest{ContractID: "c-1", ItemIDs: []string{"item-1"}}
+
+	tests := []struct {
+		name     string
+		opts     []iceye.Option
+		req      *iceye.PurchaseRequest
+		wantGzip bool
+	}{
+		{"disabled", nil, long, false},
+		{"below threshold", []iceye.Option{iceye.WithRequestCompression(100)}, short, false},
+		{"above threshold", []iceye.Option{iceye.WithRequestCompression(100)}, long, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/catalog/v1/purchases", func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.wantGzip, r.Header.Get("Content-Encoding") == "gzip")
+				var got iceye.PurchaseRequest
+				require.NoError(t, json.Unmarshal(readBody(t, r), &got))
+				assert.Equal(t, *tt.req, got)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"purchaseId":"p-1"}`))
+			})
+			cli := newCompressionClient(t, mux, tt.opts...)
+
+			_, err := cli.PurchaseCatalogItems(context.Background(), tt.req)
+			require.NoError(t, err)
+		})
+	}
+}
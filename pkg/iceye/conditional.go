@@ -0,0 +1,125 @@
+package iceye
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+// ----------------------------------------------------------------------------
+// Conditional Requests
+// ----------------------------------------------------------------------------
+
+// CacheValidator holds the ETag and Last-Modified validators of a previously
+// fetched response. Sending it with a request lets the server answer 304 Not
+// Modified instead of the full body when nothing has changed. Persist the
+// validators returned with each response and send them on the next fetch.
+type CacheValidator struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// IsZero reports whether v holds no validators, in which case requests are
+// sent unconditionally.
+func (v CacheValidator) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// apply sets the conditional request headers for v.
+func (v CacheValidator) apply(h http.Header) {
+	if v.ETag != "" {
+		h.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		h.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// or fills the validators missing from v with those of fallback; a 304
+// response need not repeat them.
+func (v CacheValidator) or(fallback CacheValidator) CacheValidator {
+	return CacheValidator{
+		ETag:         cmp.Or(v.ETag, fallback.ETag),
+		LastModified: cmp.Or(v.LastModified, fallback.LastModified),
+	}
+}
+
+func validatorFromHeader(h http.Header) CacheValidator {
+	return CacheValidator{ETag: h.Get("ETag"), LastModified: h.Get("Last-Modified")}
+}
+
+// Conditional is the result of a conditional fetch. If the resource is
+// unchanged since the validator sent, NotModified is true and Value is nil;
+// otherwise Value holds the current resource. Validator holds the validators
+// to send next time; it is zero if the server sends neither header.
+type Conditional[T any] struct {
+	Value       *T
+	NotModified bool
+	Validator   CacheValidator
+}
+
+// GetTaskConditional is GetTask sent with the validators of an earlier
+// fetch, for cheap status polls: an unchanged task comes back as
+// NotModified without a body. A zero validator fetches unconditionally.
+func (c *Client) GetTaskConditional(ctx context.Context, taskID string, v CacheValidator) (*Conditional[Task], error) {
+	u := &url.URL{Path: path.Join(taskingBasePath, "tasks", taskID)}
+	return getConditional[Task](ctx, c, u.String(), v)
+}
+
+// GetPurchaseConditional is GetPurchase sent with the validators of an
+// earlier fetch: an unchanged purchase comes back as NotModified without a
+// body. A zero validator fetches unconditionally.
+func (c *Client) GetPurchaseConditional(ctx context.Context, purchaseID string, v CacheValidator) (*Conditional[Purchase], error) {
+	u := &url.URL{Path: path.Join(catalogBasePath, "purchases", purchaseID)}
+	return getConditional[Purchase](ctx, c, u.String(), v)
+}
+
+func getConditional[T any](ctx context.Context, c *Client, urlStr string, v CacheValidator) (*Conditional[T], error) {
+	var out T
+	validator, notModified, err := c.doConditional(ctx, http.MethodGet, urlStr, nil, &out, v)
+	if err != nil {
+		return nil, err
+	}
+	res := &Conditional[T]{NotModified: notModified, Validator: validator}
+	if !notModified {
+		res.Value = &out
+	}
+	return res, nil
+}
+
+// PageValidator is the stored validator of a catalog page, keyed in
+// PageValidators by the cursor the page was requested with ("" for the
+// first page). NextCursor is the page's cursor to the following page, so
+// pagination continues past a page that comes back unchanged and without a
+// body.
+type PageValidator struct {
+	CacheValidator
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// PageValidator returns the validator to store for the page under
+// r.PageCursor.
+func (r CatalogResponse) PageValidator() PageValidator {
+	return PageValidator{CacheValidator: r.Validator, NextCursor: r.Cursor}
+}
+
+// fetchCatalogPage GETs a catalog page conditionally on the validator stored
+// for cursor, if any.
+func (c *Client) fetchCatalogPage(ctx context.Context, urlStr, cursor string, validators map[string]PageValidator) (CatalogResponse, error) {
+	stored := validators[cursor]
+
+	var resp CatalogResponse
+	validator, notModified, err := c.doConditional(ctx, http.MethodGet, urlStr, nil, &resp, stored.CacheValidator)
+	if err != nil {
+		return CatalogResponse{PageCursor: cursor}, staleCursor(err, cursor)
+	}
+	if notModified {
+		resp.Cursor = stored.NextCursor
+	}
+	resp.PageCursor = cursor
+	resp.Validator = validator
+	resp.NotModified = notModified
+	return resp, nil
+}
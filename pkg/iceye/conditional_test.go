@@ -0,0 +1,157 @@
+package iceye_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lastModified = "Wed, 01 Jan 2025 00:00:00 GMT"
+
+// etagHandler serves body with the given ETag, answering 304 when the
+// request's If-None-Match matches it.
+func etagHandler(t *testing.T, etag string, body any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		if r.Header.Get("If-None-Match") == etag {
+			assert.Equal(t, lastModified, r.Header.Get("If-Modified-Since"))
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+func TestGetTaskConditional(t *testing.T) {
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/tasking/v1/tasks/task-1", etagHandler(t, `"v2"`, iceye.Task{ID: "task-1", Status: iceye.TaskStatusActive}))
+	})
+	ctx := context.Background()
+
+	// First fetch is unconditional and returns the validators to persist.
+	res, err := cli.GetTaskConditional(ctx, "task-1", iceye.CacheValidator{})
+	require.NoError(t, err)
+	assert.False(t, res.NotModified)
+	require.NotNil(t, res.Value)
+	assert.Equal(t, iceye.TaskStatusActive, res.Value.Status)
+	assert.Equal(t, iceye.CacheValidator{ETag: `"v2"`, LastModified: lastModified}, res.Validator)
+
+	// Sending them back yields NotModified rather than an error.
+	res, err = cli.GetTaskConditional(ctx, "task-1", res.Validator)
+	require.NoError(t, err)
+	assert.True(t, res.NotModified)
+	assert.Nil(t, res.Value)
+	assert.Equal(t, `"v2"`, res.Validator.ETag)
+
+	// A stale validator gets the new representation and its new ETag.
+	res, err = cli.GetTaskConditional(ctx, "task-1", iceye.CacheValidator{ETag: `"v1"`, LastModified: lastModified})
+	require.NoError(t, err)
+	assert.False(t, res.NotModified)
+	require.NotNil(t, res.Value)
+	assert.Equal(t, `"v2"`, res.Validator.ETag)
+}
+
+func TestGetPurchaseConditional_NoValidatorHeaders(t *testing.T) {
+	var conditional atomic.Bool
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/catalog/v1/purchases/p-1", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") != "" {
+				conditional.Store(true)
+			}
+			json.NewEncoder(w).Encode(iceye.Purchase{ID: "p-1", Status: iceye.PurchaseStatusActive})
+		})
+	})
+
+	res, err := cli.GetPurchaseConditional(context.Background(), "p-1", iceye.CacheValidator{})
+	require.NoError(t, err)
+	require.NotNil(t, res.Value)
+	assert.Equal(t, "p-1", res.Value.ID)
+	assert.True(t, res.Validator.IsZero())
+	assert.False(t, conditional.Load(), "a zero validator should not send conditional headers")
+
+	// A server that ignores validators always returns the full body.
+	res, err = cli.GetPurchaseConditional(context.Background(), "p-1", iceye.CacheValidator{ETag: `"old"`})
+	require.NoError(t, err)
+	assert.False(t, res.NotModified)
+	require.NotNil(t, res.Value)
+	assert.True(t, conditional.Load())
+}
+
+func TestListCatalogItems_PageValidators(t *testing.T) {
+	var requests atomic.Int32
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/catalog/v1/items", func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			switch r.URL.Query().Get("cursor") {
+			case "":
+				etagHandler(t, `"page-1"`, iceye.CatalogResponse{
+					Data:   []iceye.STACItem{{ID: "item-1"}},
+					Cursor: "c2",
+				})(w, r)
+			case "c2":
+				// The second page changes between syncs.
+				etagHandler(t, `"page-2-v2"`, iceye.CatalogResponse{
+					Data: []iceye.STACItem{{ID: "item-2"}, {ID: "item-3"}},
+				})(w, r)
+			default:
+				t.Errorf("unexpected cursor %q", r.URL.Query().Get("cursor"))
+			}
+		})
+	})
+
+	// A previous sync stored both pages' validators.
+	stored := map[string]iceye.PageValidator{
+		"":   {CacheValidator: iceye.CacheValidator{ETag: `"page-1"`, LastModified: lastModified}, NextCursor: "c2"},
+		"c2": {CacheValidator: iceye.CacheValidator{ETag: `"page-2-v1"`, LastModified: lastModified}},
+	}
+
+	var pages []iceye.CatalogResponse
+	for resp, err := range cli.ListCatalogItems(context.Background(), 10, &iceye.ListItemsOptions{PageValidators: stored}) {
+		require.NoError(t, err)
+		pages = append(pages, resp)
+		stored[resp.PageCursor] = resp.PageValidator()
+	}
+
+	require.Len(t, pages, 2)
+	assert.True(t, pages[0].NotModified)
+	assert.Empty(t, pages[0].Data)
+	assert.Equal(t, "c2", pages[0].Cursor, "pagination should continue from the stored cursor")
+
+	assert.False(t, pages[1].NotModified)
+	assert.Len(t, pages[1].Data, 2)
+	assert.Equal(t, "c2", pages[1].PageCursor)
+	assert.Equal(t, `"page-2-v2"`, stored["c2"].ETag)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestSearchCatalogItems_PageValidators(t *testing.T) {
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/catalog/v1/search", func(w http.ResponseWriter, r *http.Request) {
+			assert.Empty(t, r.Header.Get("If-None-Match"), "the POST must not be conditional")
+			json.NewEncoder(w).Encode(iceye.CatalogResponse{Data: []iceye.STACItem{{ID: "item-1"}}, Cursor: "c2"})
+		})
+		mux.HandleFunc("/catalog/v1/items", etagHandler(t, `"page-2"`, iceye.CatalogResponse{Data: []iceye.STACItem{{ID: "item-2"}}}))
+	})
+
+	req := &iceye.SearchRequest{PageValidators: map[string]iceye.PageValidator{
+		"":   {CacheValidator: iceye.CacheValidator{ETag: `"page-1"`}},
+		"c2": {CacheValidator: iceye.CacheValidator{ETag: `"page-2"`, LastModified: lastModified}},
+	}}
+	var notModified []bool
+	for resp, err := range cli.SearchCatalogItems(context.Background(), req) {
+		require.NoError(t, err)
+		notModified = append(notModified, resp.NotModified)
+	}
+	assert.Equal(t, []bool{false, true}, notModified)
+}
@@ -0,0 +1,248 @@
+package iceye
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// ----------------------------------------------------------------------------
+// Footprint Coverage
+// ----------------------------------------------------------------------------
+
+// CoverageReport describes how much of a task's area of interest the
+// delivered footprints cover.
+type CoverageReport struct {
+	TaskID string
+	AOI    *geojson.Geometry
+	// Footprints lists the coverage of each footprint considered: the
+	// products' footprints, or the scene's when no product has one.
+	Footprints []FootprintCoverage
+	// Percent is the share of the AOI covered by the footprints together.
+	Percent float64
+	// Uncovered is the part of the AOI no footprint covers, nil when it is
+	// fully covered or when a footprint is not convex.
+	Uncovered *geojson.Geometry
+}
+
+// FootprintCoverage is the coverage of the AOI by one footprint.
+type FootprintCoverage struct {
+	// Source is the product type, or "scene" for the task scene.
+	Source    string
+	Footprint *geojson.Geometry
+	Percent   float64
+}
+
+// InsufficientCoverageError is returned by CoverageReport.Require when the
+// footprints cover less of the AOI than required.
+type InsufficientCoverageError struct {
+	TaskID   string
+	Percent  float64
+	Required float64
+}
+
+func (e *InsufficientCoverageError) Error() string {
+	return fmt.Sprintf("iceye: task %s covers %.1f%% of the AOI, %.1f%% required", e.TaskID, e.Percent, e.Required)
+}
+
+// Require returns an *InsufficientCoverageError if the footprints cover less
+// than percent of the AOI.
+func (r *CoverageReport) Require(percent float64) error {
+	if r.Percent < percent {
+		return &InsufficientCoverageError{TaskID: r.TaskID, Percent: r.Percent, Required: percent}
+	}
+	return nil
+}
+
+// CoverageOf returns the percentage of aoi covered by footprint. A nil aoi
+// defaults to the task's area or point of interest; a point is either fully
+// covered or not at all.
+func (t *Task) CoverageOf(aoi, footprint *geojson.Geometry) (float64, error) {
+	if aoi == nil {
+		aoi = t.aoi()
+	}
+	if aoi == nil || aoi.Geometry() == nil || footprint == nil || footprint.Geometry() == nil {
+		return 0, common.ErrEmptyGeometry
+	}
+	return coverageOf(aoi, footprint)
+}
+
+func coverageOf(aoi, footprint *geojson.Geometry) (float64, error) {
+	if _, ok := footprintPolygons(footprint); !ok {
+		return 0, fmt.Errorf("iceye: footprint is a %s, not a polygon", footprint.Geometry().GeoJSONType())
+	}
+	switch g := aoi.Geometry().(type) {
+	case orb.Point:
+		if common.Intersects(aoi, footprint) {
+			return 100, nil
+		}
+		return 0, nil
+	case orb.Polygon, orb.MultiPolygon:
+		return common.CoveragePercent(aoi, footprint), nil
+	default:
+		return 0, fmt.Errorf("iceye: cannot compute coverage of a %s AOI", g.GeoJSONType())
+	}
+}
+
+// aoi returns the task's area of interest, or its point of interest.
+func (t *Task) aoi() *geojson.Geometry {
+	if t.AreaOfInterest != nil {
+		return t.AreaOfInterest
+	}
+	if t.PointOfInterest != nil {
+		return GeoJSONPoint(t.PointOfInterest.Lon, t.PointOfInterest.Lat)
+	}
+	return nil
+}
+
+// GetTaskCoverage reports how much of aoi the task's product footprints
+// cover, falling back to the scene footprint when no product carries one.
+// A nil aoi defaults to the task's area or point of interest.
+func (c *Client) GetTaskCoverage(ctx context.Context, taskID string, aoi *geojson.Geometry) (*CoverageReport, error) {
+	if aoi == nil {
+		task, err := c.GetTask(ctx, taskID)
+		if err != nil {
+			return nil, err
+		}
+		if aoi = task.aoi(); aoi == nil {
+			return nil, fmt.Errorf("iceye: task %s has no area or point of interest", taskID)
+		}
+	}
+
+	products, err := c.ListTaskProducts(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	report := &CoverageReport{TaskID: taskID, AOI: aoi}
+	for _, p := range products {
+		if p.Geometry != nil {
+			report.Footprints = append(report.Footprints, FootprintCoverage{Source: p.Type, Footprint: p.Geometry})
+		}
+	}
+	if len(report.Footprints) == 0 {
+		scene, err := c.GetTaskScene(ctx, taskID)
+		if err != nil {
+			return nil, err
+		}
+		if scene.Footprint == nil {
+			return nil, fmt.Errorf("iceye: task %s has no footprint", taskID)
+		}
+		report.Footprints = []FootprintCoverage{{Source: "scene", Footprint: scene.Footprint}}
+	}
+
+	var union orb.MultiPolygon
+	for i := range report.Footprints {
+		f := &report.Footprints[i]
+		if f.Percent, err = coverageOf(aoi, f.Footprint); err != nil {
+			return nil, err
+		}
+		polys, _ := footprintPolygons(f.Footprint)
+		union = append(union, polys...)
+	}
+	if err := report.combine(geojson.NewGeometry(union)); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// combine sets the report's total coverage and uncovered remainder for the
+// union of its footprints.
+func (r *CoverageReport) combine(union *geojson.Geometry) error {
+	if _, ok := r.AOI.Geometry().(orb.Point); ok {
+		for _, f := range r.Footprints {
+			r.Percent = max(r.Percent, f.Percent)
+		}
+		if r.Percent == 0 {
+			r.Uncovered = r.AOI
+		}
+		return nil
+	}
+
+	uncovered, err := common.Difference(r.AOI, union)
+	switch {
+	case errors.Is(err, common.ErrNotConvex):
+		// CoveragePercent samples non-convex footprints, which also
+		// counts overlapping footprints once.
+		r.Percent = common.CoveragePercent(r.AOI, union)
+		return nil
+	case err != nil:
+		return err
+	}
+	r.Uncovered = uncovered
+	r.Percent = 100
+	if uncovered != nil {
+		total, _ := common.AreaSqKm(r.AOI)
+		left, _ := common.AreaSqKm(uncovered)
+		if total > 0 {
+			r.Percent = min(max(100*(1-left/total), 0), 100)
+		}
+	}
+	return nil
+}
+
+// footprintPolygons returns the polygons of a polygonal footprint.
+func footprintPolygons(g *geojson.Geometry) (orb.MultiPolygon, bool) {
+	switch g := g.Geometry().(type) {
+	case orb.Polygon:
+		return orb.MultiPolygon{g}, true
+	case orb.MultiPolygon:
+		return g, true
+	}
+	return nil, false
+}
+
+// decodeFootprint decodes a footprint given as GeoJSON or, as some older
+// scene payloads do, as a WKT string.
+func decodeFootprint(data json.RawMessage) (*geojson.Geometry, error) {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		if s == "" {
+			return nil, nil
+		}
+		return common.WKTToGeometry(s)
+	}
+	return decodeItemGeometry(data)
+}
+
+// UnmarshalJSON accepts the footprint as GeoJSON or WKT.
+func (s *TaskScene) UnmarshalJSON(data []byte) error {
+	type plain TaskScene
+	var raw struct {
+		plain
+		Footprint json.RawMessage `json:"footprint"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	footprint, err := decodeFootprint(raw.Footprint)
+	if err != nil {
+		return fmt.Errorf("iceye: scene footprint: %w", err)
+	}
+	*s = TaskScene(raw.plain)
+	s.Footprint = footprint
+	return nil
+}
+
+// UnmarshalJSON accepts the geometry as GeoJSON or WKT.
+func (p *TaskProduct) UnmarshalJSON(data []byte) error {
+	type plain TaskProduct
+	var raw struct {
+		plain
+		Geometry json.RawMessage `json:"geometry"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	geometry, err := decodeFootprint(raw.Geometry)
+	if err != nil {
+		return fmt.Errorf("iceye: product geometry: %w", err)
+	}
+	*p = TaskProduct(raw.plain)
+	p.Geometry = geometry
+	return nil
+}
@@ -0,0 +1,124 @@
+package iceye_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+)
+
+// coverageAOI is a 0.2° square around (25, 60).
+var coverageAOI = geojson.NewGeometry(orb.Polygon{{{24.9, 59.9}, {25.1, 59.9}, {25.1, 60.1}, {24.9, 60.1}, {24.9, 59.9}}})
+
+func TestTaskCoverageOf(t *testing.T) {
+	task := &iceye.Task{ID: "T-1", AreaOfInterest: coverageAOI}
+	tests := []struct {
+		name      string
+		footprint orb.Geometry
+		want      float64
+	}{
+		{"full", orb.Polygon{{{24.8, 59.8}, {25.2, 59.8}, {25.2, 60.2}, {24.8, 60.2}, {24.8, 59.8}}}, 100},
+		{"east half", orb.Polygon{{{25.0, 59.8}, {25.2, 59.8}, {25.2, 60.2}, {25.0, 60.2}, {25.0, 59.8}}}, 50},
+		{"disjoint", orb.Polygon{{{30, 60}, {31, 60}, {31, 61}, {30, 61}, {30, 60}}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := task.CoverageOf(nil, geojson.NewGeometry(tt.footprint))
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 0.5)
+		})
+	}
+
+	point := &iceye.Task{PointOfInterest: &iceye.Point{Lat: 60, Lon: 25}}
+	got, err := point.CoverageOf(nil, coverageAOI)
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, got)
+
+	_, err = task.CoverageOf(nil, nil)
+	assert.Error(t, err)
+	_, err = task.CoverageOf(nil, iceye.GeoJSONPoint(25, 60))
+	assert.Error(t, err, "a point footprint has no coverage")
+}
+
+func coverageServer(t *testing.T, products, scene string) *iceye.Client {
+	t.Helper()
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/tasking/v1/tasks/T-1", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(iceye.Task{ID: "T-1", AreaOfInterest: coverageAOI})
+		})
+		mux.HandleFunc("/tasking/v1/tasks/T-1/products", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(products))
+		})
+		mux.HandleFunc("/tasking/v1/tasks/T-1/scene", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(scene))
+		})
+	})
+	return cli
+}
+
+func TestGetTaskCoverage_Products(t *testing.T) {
+	// Two overlapping products: the west 3/4 and the south half.
+	cli := coverageServer(t, `[
+		{"type": "GRD", "assets": {}, "geometry": {"type": "Polygon", "coordinates": [[[24.8,59.8],[25.05,59.8],[25.05,60.2],[24.8,60.2],[24.8,59.8]]]}},
+		{"type": "SLC", "assets": {}, "geometry": {"type": "Polygon", "coordinates": [[[24.8,59.8],[25.2,59.8],[25.2,60.0],[24.8,60.0],[24.8,59.8]]]}},
+		{"type": "QLK", "assets": {}}
+	]`, `{}`)
+
+	report, err := cli.GetTaskCoverage(context.Background(), "T-1", nil)
+	require.NoError(t, err)
+
+	require.Len(t, report.Footprints, 2)
+	assert.Equal(t, "GRD", report.Footprints[0].Source)
+	assert.InDelta(t, 75, report.Footprints[0].Percent, 0.5)
+	assert.InDelta(t, 50, report.Footprints[1].Percent, 0.5)
+	// Only the north-east eighth is left.
+	assert.InDelta(t, 87.5, report.Percent, 0.5)
+	require.NotNil(t, report.Uncovered)
+	assert.InDelta(t, 25.075, report.Uncovered.Geometry().Bound().Center().Lon(), 0.001)
+
+	assert.NoError(t, report.Require(80))
+	var insufficient *iceye.InsufficientCoverageError
+	require.True(t, errors.As(report.Require(95), &insufficient))
+	assert.Equal(t, "T-1", insufficient.TaskID)
+	assert.Equal(t, 95.0, insufficient.Required)
+}
+
+func TestGetTaskCoverage_SceneWKT(t *testing.T) {
+	cli := coverageServer(t, `[{"type": "GRD", "assets": {}}]`, `{
+		"duration": 10,
+		"footprint": "POLYGON((24.8 59.8,25.2 59.8,25.2 60.2,24.8 60.2,24.8 59.8))"
+	}`)
+
+	report, err := cli.GetTaskCoverage(context.Background(), "T-1", coverageAOI)
+	require.NoError(t, err)
+	require.Len(t, report.Footprints, 1)
+	assert.Equal(t, "scene", report.Footprints[0].Source)
+	assert.InDelta(t, 100, report.Percent, 1e-6)
+	assert.Nil(t, report.Uncovered)
+}
+
+func TestGetTaskCoverage_Disjoint(t *testing.T) {
+	cli := coverageServer(t, `[{"type": "GRD", "assets": {}, "geometry": "POLYGON((30 60,31 60,31 61,30 61,30 60))"}]`, `{}`)
+
+	report, err := cli.GetTaskCoverage(context.Background(), "T-1", coverageAOI)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, report.Percent)
+	assert.Equal(t, coverageAOI.Geometry(), report.Uncovered.Geometry())
+	assert.Error(t, report.Require(1))
+}
+
+func TestGetTaskCoverage_NoFootprint(t *testing.T) {
+	cli := coverageServer(t, `[]`, `{"duration": 10}`)
+	_, err := cli.GetTaskCoverage(context.Background(), "T-1", coverageAOI)
+	assert.Error(t, err)
+}
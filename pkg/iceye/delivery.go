@@ -32,6 +32,9 @@ const (
 	DeliveryLocationConfigStatusInactive DeliveryLocationConfigStatus = "inactive"
 )
 
+// DeliveryMethodS3 is the method of S3 delivery location configs.
+const DeliveryMethodS3 = "s3"
+
 // DeliveryLocationConfig represents a delivery location configuration.
 type DeliveryLocationConfig struct {
 	ID     string                       `json:"id"`
@@ -40,6 +43,12 @@ type DeliveryLocationConfig struct {
 	Status DeliveryLocationConfigStatus `json:"status"`
 }
 
+// Location returns a reference to this config for use in task and delivery
+// requests, delivering under path.
+func (l DeliveryLocationConfig) Location(path string) DeliveryLocation {
+	return DeliveryLocation{ConfigID: l.ID, Path: path}
+}
+
 // S3Config contains S3-specific delivery configuration.
 type S3Config struct {
 	Endpoint string `json:"endpoint"`
@@ -105,6 +114,25 @@ func (c *Client) ListDeliveryLocationConfigs(ctx context.Context) ([]DeliveryLoc
 	return resp, nil
 }
 
+// ListDeliveryLocations returns an iterator over the delivery location
+// configs of the company.
+//
+// GET /delivery/v1/deliveries/location-configs
+func (c *Client) ListDeliveryLocations(ctx context.Context) iter.Seq2[DeliveryLocationConfig, error] {
+	return func(yield func(DeliveryLocationConfig, error) bool) {
+		configs, err := c.ListDeliveryLocationConfigs(ctx)
+		if err != nil {
+			yield(DeliveryLocationConfig{}, err)
+			return
+		}
+		for _, cfg := range configs {
+			if !yield(cfg, nil) {
+				return
+			}
+		}
+	}
+}
+
 // ListDeliveries lists deliveries with optional filters.
 // Returns an iterator that yields pages of deliveries.
 //
@@ -0,0 +1,55 @@
+package iceye_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListDeliveryLocations(t *testing.T) {
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("GET /delivery/v1/deliveries/location-configs", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[
+				{"id": "loc-1", "method": "s3", "config": {"bucket": "sar-archive", "region": "eu-west-1", "keyID": "AKIA123"}, "status": "active"},
+				{"id": "loc-2", "method": "s3", "config": {"bucket": "sar-ops"}, "status": "inactive"}
+			]`))
+		})
+	})
+
+	var locs []iceye.DeliveryLocationConfig
+	for loc, err := range cli.ListDeliveryLocations(context.Background()) {
+		require.NoError(t, err)
+		locs = append(locs, loc)
+	}
+	require.Len(t, locs, 2)
+	assert.Equal(t, "sar-archive", locs[0].Config.Bucket)
+	assert.Equal(t, iceye.DeliveryLocationConfigStatusInactive, locs[1].Status)
+	assert.Equal(t, iceye.DeliveryLocation{ConfigID: "loc-2", Path: "ops/"}, locs[1].Location("ops/"))
+}
+
+func TestCreateTaskDeliveryLocationByReference(t *testing.T) {
+	loc := iceye.DeliveryLocationConfig{ID: "loc-1", Method: iceye.DeliveryMethodS3}
+	req := iceye.CreateTaskRequest{
+		ContractID:        "C-1",
+		ImagingMode:       "SPOTLIGHT",
+		DeliveryLocations: []iceye.DeliveryLocation{loc.Location("tasks/"), iceye.NewDeliveryLocation("loc-2", "ops/")},
+	}
+
+	b, err := json.Marshal(req)
+	require.NoError(t, err)
+	var got struct {
+		DeliveryLocations []map[string]any `json:"deliveryLocations"`
+	}
+	require.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, []map[string]any{
+		{"configID": "loc-1", "path": "tasks/"},
+		{"configID": "loc-2", "path": "ops/"},
+	}, got.DeliveryLocations)
+}
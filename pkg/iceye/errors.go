@@ -1,10 +1,12 @@
 package iceye
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 )
 
 // Common error codes returned by the ICEYE API.
@@ -15,33 +17,137 @@ const (
 	ErrCodeSceneUnavailable  = "ERR_SCENE_UNAVAILABLE"
 )
 
+// maxErrorDetail caps how much of a non-JSON error body is kept in Detail.
+const maxErrorDetail = 512
+
 // Error represents an ICEYE API error (RFC 7807 Problem Details).
 type Error struct {
-	Status int    `json:"status"`
-	Code   string `json:"code"`
-	Detail string `json:"detail"`
-	Title  string `json:"title,omitempty"`
-	Type   string `json:"type,omitempty"`
+	Status     int              `json:"status"`
+	Code       string           `json:"code"`
+	Detail     string           `json:"detail"`
+	Title      string           `json:"title,omitempty"`
+	Type       string           `json:"type,omitempty"`
+	Instance   string           `json:"instance,omitempty"`
+	Violations []FieldViolation `json:"errors,omitempty"`
+}
+
+// FieldViolation is a field-level validation failure reported in the
+// "errors" member of a problem document.
+type FieldViolation struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// UnmarshalJSON accepts the "pointer"/"name" and "message"/"detail" spellings
+// some ICEYE services use for violations.
+func (v *FieldViolation) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Field   string `json:"field"`
+		Pointer string `json:"pointer"`
+		Name    string `json:"name"`
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+		Detail  string `json:"detail"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	v.Field = cmp.Or(raw.Field, raw.Pointer, raw.Name)
+	v.Reason = cmp.Or(raw.Reason, raw.Message, raw.Detail)
+	return nil
 }
 
 func (e *Error) Error() string {
+	msg := fmt.Sprintf("iceye: %s (%d)", e.Code, e.Status)
 	if e.Detail != "" {
-		return fmt.Sprintf("iceye: %s (%d) – %s", e.Code, e.Status, e.Detail)
+		msg += " – " + e.Detail
+	}
+	if len(e.Violations) > 0 {
+		msg += fmt.Sprintf(" [%s: %s]", e.Violations[0].Field, e.Violations[0].Reason)
 	}
-	return fmt.Sprintf("iceye: %s (%d)", e.Code, e.Status)
+	return msg
+}
+
+// IsValidation reports whether the error describes invalid request input.
+func (e *Error) IsValidation() bool {
+	return len(e.Violations) > 0 || e.Status == http.StatusUnprocessableEntity
+}
+
+// Violation returns the first violation reported for field.
+func (e *Error) Violation(field string) (FieldViolation, bool) {
+	for _, v := range e.Violations {
+		if v.Field == field {
+			return v, true
+		}
+	}
+	return FieldViolation{}, false
 }
 
 func parseError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+
 	var e Error
+	if json.Unmarshal(body, &e) != nil {
+		// Not a problem document, e.g. an HTML page from a gateway.
+		e = Error{Detail: truncate(strings.TrimSpace(string(body)), maxErrorDetail)}
+	} else if e.Detail == "" {
+		// The tasking API documents "message" rather than "detail".
+		var alt struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(body, &alt)
+		e.Detail = alt.Message
+	}
 	e.Status = resp.StatusCode
-	body, _ := io.ReadAll(resp.Body)
-	if json.Unmarshal(body, &e) != nil || e.Code == "" {
+	if e.Code == "" {
 		e.Code = http.StatusText(resp.StatusCode)
-		e.Detail = string(body)
 	}
 	return &e
 }
 
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "") + "…"
+}
+
+// StaleCursorError is returned by the resumable iterators, such as
+// ListCatalogItemsFrom, when the API rejects a pagination cursor, typically
+// one that expired since it was checkpointed. The listing cannot continue
+// from that point; restart it from the first page. It unwraps to the *Error.
+type StaleCursorError struct {
+	Cursor string
+	Err    *Error
+}
+
+func (e *StaleCursorError) Error() string {
+	return fmt.Sprintf("iceye: pagination cursor rejected, restart the listing: %v", e.Err)
+}
+
+func (e *StaleCursorError) Unwrap() error { return e.Err }
+
+// staleCursor maps the API's rejection of a non-empty cursor to a
+// *StaleCursorError: 410 Gone, or a 400 or 422 that names the cursor
+// parameter. Other errors are returned unchanged.
+func staleCursor(err error, cursor string) error {
+	var e *Error
+	if cursor == "" || !isError(err, &e) {
+		return err
+	}
+	switch e.Status {
+	case http.StatusGone:
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		_, named := e.Violation("cursor")
+		if !named && !strings.Contains(strings.ToLower(e.Code+" "+e.Detail), "cursor") {
+			return err
+		}
+	default:
+		return err
+	}
+	return &StaleCursorError{Cursor: cursor, Err: e}
+}
+
 // Error checking helpers using errors.As for compatibility.
 
 // IsNotFound returns true if the error is a 404 Not Found error.
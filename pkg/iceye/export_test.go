@@ -0,0 +1,14 @@
+package iceye
+
+import "time"
+
+// SetPriceCacheClock replaces the clock used by the client's price cache.
+func SetPriceCacheClock(c *Client, now func() time.Time) {
+	c.prices.Now = now
+}
+
+// SetIdempotentBackoff replaces the delay before the first retry of a keyed
+// call.
+func SetIdempotentBackoff(c *Client, d time.Duration) {
+	c.idempotentBackoff = d
+}
@@ -0,0 +1,388 @@
+package iceyetest
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+)
+
+// ----------------------------------------------------------------------------
+// Catalog Fixtures
+// ----------------------------------------------------------------------------
+
+//go:embed fixtures/catalog_items.json
+var sampleItems []byte
+
+// SampleItems returns a small set of catalog items over Helsinki, in
+// SPOTLIGHT and STRIPMAP, for seeding a Server with WithCatalogItems.
+func SampleItems() []iceye.STACItem {
+	items, err := LoadItems(bytes.NewReader(sampleItems))
+	if err != nil {
+		panic("iceyetest: invalid sample items: " + err.Error())
+	}
+	return items
+}
+
+// LoadItems reads catalog items from a JSON fixture: an array of STAC
+// items, a catalog response {"data": [...]}, or a STAC FeatureCollection
+// {"features": [...]}.
+func LoadItems(r io.Reader) ([]iceye.STACItem, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var items []iceye.STACItem
+	if json.Unmarshal(data, &items) == nil {
+		return items, nil
+	}
+	var doc struct {
+		Data     []iceye.STACItem `json:"data"`
+		Features []iceye.STACItem `json:"features"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("iceyetest: decode catalog items: %w", err)
+	}
+	if doc.Data != nil {
+		return doc.Data, nil
+	}
+	if doc.Features != nil {
+		return doc.Features, nil
+	}
+	return nil, errors.New("iceyetest: fixture has no data or features member")
+}
+
+// ----------------------------------------------------------------------------
+// Catalog
+// ----------------------------------------------------------------------------
+
+// cursorState is the rest of a listing or search, keyed by the cursor
+// returned with the previous page.
+type cursorState struct {
+	items []iceye.STACItem
+	limit int
+}
+
+// itemFilter holds the item filters shared by listing and search.
+type itemFilter struct {
+	ids      []string
+	bbox     *common.BoundingBox
+	from, to time.Time
+}
+
+// parseDatetime parses an RFC 3339 instant or interval, with ".." or an
+// empty string for an open end.
+func parseDatetime(s string) (from, to time.Time, err error) {
+	start, end, interval := strings.Cut(s, "/")
+	if !interval {
+		t, err := time.Parse(time.RFC3339, s)
+		return t, t, err
+	}
+	if start != "" && start != ".." {
+		if from, err = time.Parse(time.RFC3339, start); err != nil {
+			return from, to, err
+		}
+	}
+	if end != "" && end != ".." {
+		if to, err = time.Parse(time.RFC3339, end); err != nil {
+			return from, to, err
+		}
+	}
+	return from, to, nil
+}
+
+func (f itemFilter) match(item iceye.STACItem) bool {
+	if len(f.ids) > 0 && !slices.Contains(f.ids, item.ID) {
+		return false
+	}
+	if f.bbox != nil {
+		bound := item.BBox.ToOrbBound()
+		if item.BBox == (common.BoundingBox{}) && item.Geometry != nil {
+			bound = item.Geometry.Geometry().Bound()
+		}
+		if !bound.Intersects(f.bbox.ToOrbBound()) {
+			return false
+		}
+	}
+	start := item.Properties.StartTime
+	if !f.from.IsZero() && start.Before(f.from) {
+		return false
+	}
+	if !f.to.IsZero() && start.After(f.to) {
+		return false
+	}
+	return true
+}
+
+// writeItems writes the first page of items and stores a cursor for the
+// rest.
+func (s *Server) writeItems(w http.ResponseWriter, items []iceye.STACItem, limit int) {
+	if limit <= 0 {
+		limit = 100
+	}
+	n := min(limit, len(items))
+	resp := iceye.CatalogResponse{Data: items[:n]}
+	if resp.Data == nil {
+		resp.Data = []iceye.STACItem{}
+	}
+	if n < len(items) {
+		resp.Cursor = s.nextID("cursor")
+		s.cursors[resp.Cursor] = cursorState{items: items[n:], limit: limit}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleCatalogItems(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 {
+			writeValidation(w, r, iceye.FieldViolation{Field: "limit", Reason: "must be a positive integer"})
+			return
+		}
+		limit = l
+	}
+
+	if c := q.Get("cursor"); c != "" {
+		state, ok := s.cursors[c]
+		if !ok {
+			writeValidation(w, r, iceye.FieldViolation{Field: "cursor", Reason: "unknown or expired cursor"})
+			return
+		}
+		s.writeItems(w, state.items, cmpOrInt(limit, state.limit))
+		return
+	}
+
+	var f itemFilter
+	if v := q.Get("ids"); v != "" {
+		f.ids = strings.Split(v, ",")
+	}
+	if v := q.Get("bbox"); v != "" {
+		var bbox common.BoundingBox
+		parts := strings.Split(v, ",")
+		if len(parts) != 4 {
+			writeValidation(w, r, iceye.FieldViolation{Field: "bbox", Reason: "must have four coordinates"})
+			return
+		}
+		for i, p := range parts {
+			c, err := strconv.ParseFloat(p, 64)
+			if err != nil {
+				writeValidation(w, r, iceye.FieldViolation{Field: "bbox", Reason: "must have four coordinates"})
+				return
+			}
+			bbox[i] = c
+		}
+		f.bbox = &bbox
+	}
+	if v := q.Get("datetime"); v != "" {
+		var err error
+		if f.from, f.to, err = parseDatetime(v); err != nil {
+			writeValidation(w, r, iceye.FieldViolation{Field: "datetime", Reason: err.Error()})
+			return
+		}
+	}
+	s.writeItems(w, s.filterItems(f), limit)
+}
+
+func (s *Server) handleCatalogSearch(w http.ResponseWriter, r *http.Request) {
+	var req iceye.SearchRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	f := itemFilter{ids: req.IDs, bbox: req.BBox}
+	if req.Datetime != "" {
+		var err error
+		if f.from, f.to, err = parseDatetime(req.Datetime); err != nil {
+			writeValidation(w, r, iceye.FieldViolation{Field: "datetime", Reason: err.Error()})
+			return
+		}
+	}
+	s.writeItems(w, s.filterItems(f), req.Limit)
+}
+
+func (s *Server) filterItems(f itemFilter) []iceye.STACItem {
+	var out []iceye.STACItem
+	for _, item := range s.items {
+		if f.match(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func (s *Server) item(id string) (iceye.STACItem, bool) {
+	i := slices.IndexFunc(s.items, func(item iceye.STACItem) bool { return item.ID == id })
+	if i < 0 {
+		return iceye.STACItem{}, false
+	}
+	return s.items[i], true
+}
+
+func cmpOrInt(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+// ----------------------------------------------------------------------------
+// Purchases
+// ----------------------------------------------------------------------------
+
+type purchase struct {
+	iceye.Purchase
+	itemIDs []string
+}
+
+// AddPurchase stores a purchase of the given catalog items and returns it.
+// An empty ID is generated and an empty status is closed.
+func (s *Server) AddPurchase(p iceye.Purchase, itemIDs ...string) iceye.Purchase {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.ID == "" {
+		p.ID = s.nextID("purchase")
+	}
+	if p.Status == "" {
+		p.Status = iceye.PurchaseStatusClosed
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = s.cfg.now()
+	}
+	if _, ok := s.purchases[p.ID]; !ok {
+		s.purchOrd = append(s.purchOrd, p.ID)
+	}
+	s.purchases[p.ID] = &purchase{Purchase: p, itemIDs: itemIDs}
+	return p
+}
+
+func (s *Server) handleCreatePurchase(w http.ResponseWriter, r *http.Request) {
+	var req iceye.PurchaseRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	var v []iceye.FieldViolation
+	if len(req.ItemIDs) == 0 {
+		v = append(v, iceye.FieldViolation{Field: "itemIds", Reason: "at least one item is required"})
+	}
+	if len([]rune(req.Reference)) > 256 {
+		v = append(v, iceye.FieldViolation{Field: "reference", Reason: "must be at most 256 characters"})
+	}
+	if len(v) > 0 {
+		writeValidation(w, r, v...)
+		return
+	}
+	if !s.checkContract(w, r, "contractId", req.ContractID) {
+		return
+	}
+	key := idempotencyKey(r)
+	if id, ok := s.created[key]; ok {
+		writeJSON(w, http.StatusCreated, iceye.PurchaseResponse{PurchaseID: id})
+		return
+	}
+	for _, id := range req.ItemIDs {
+		if _, ok := s.item(id); !ok {
+			writeProblem(w, r, http.StatusBadRequest, iceye.ErrCodeSceneUnavailable, "item "+id+" is not available for purchase")
+			return
+		}
+	}
+
+	// Purchases are fulfilled at once: their products can be listed right
+	// away.
+	p := &purchase{
+		Purchase: iceye.Purchase{
+			ID:           s.nextID("purchase"),
+			CustomerName: req.CompanyName,
+			ContractName: req.ContractID,
+			CreatedAt:    s.cfg.now(),
+			Status:       iceye.PurchaseStatusClosed,
+			Reference:    req.Reference,
+		},
+		itemIDs: slices.Clone(req.ItemIDs),
+	}
+	s.purchases[p.ID] = p
+	s.purchOrd = append(s.purchOrd, p.ID)
+	s.remember(key, p.ID)
+	writeJSON(w, http.StatusCreated, iceye.PurchaseResponse{PurchaseID: p.ID})
+}
+
+func (s *Server) handleListPurchases(w http.ResponseWriter, r *http.Request) {
+	start, end, next, ok := page(r, len(s.purchOrd))
+	if !ok {
+		writeValidation(w, r, iceye.FieldViolation{Field: "cursor", Reason: "invalid limit or cursor"})
+		return
+	}
+	resp := iceye.PurchasesResponse{Data: []iceye.Purchase{}, Cursor: next}
+	for _, id := range s.purchOrd[start:end] {
+		resp.Data = append(resp.Data, s.purchases[id].Purchase)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) lookupPurchase(w http.ResponseWriter, r *http.Request) (*purchase, bool) {
+	p, ok := s.purchases[r.PathValue("id")]
+	if !ok {
+		writeProblem(w, r, http.StatusNotFound, "ERR_PURCHASE_NOT_FOUND", "purchase "+r.PathValue("id")+" not found")
+	}
+	return p, ok
+}
+
+func (s *Server) handleGetPurchase(w http.ResponseWriter, r *http.Request) {
+	if p, ok := s.lookupPurchase(w, r); ok {
+		writeJSON(w, http.StatusOK, p.Purchase)
+	}
+}
+
+func (s *Server) handlePurchaseProducts(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.lookupPurchase(w, r)
+	if !ok {
+		return
+	}
+	if p.Status != iceye.PurchaseStatusClosed {
+		writeProblem(w, r, http.StatusConflict, "ERR_PURCHASE_NOT_FULFILLED", fmt.Sprintf("purchase %s is %s", p.ID, p.Status))
+		return
+	}
+	resp := struct {
+		Data []iceye.STACItem `json:"data"`
+	}{Data: []iceye.STACItem{}}
+	for _, id := range p.itemIDs {
+		if item, ok := s.item(id); ok {
+			resp.Data = append(resp.Data, item)
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ----------------------------------------------------------------------------
+// Assets
+// ----------------------------------------------------------------------------
+
+const assetsPath = "/assets/"
+
+// assetBody is the content served for an asset URL.
+func assetBody(href string) string {
+	return "iceyetest asset " + path.Base(href) + "\n"
+}
+
+func (s *Server) handleAsset(w http.ResponseWriter, r *http.Request) {
+	switch path.Ext(r.URL.Path) {
+	case ".json":
+		w.Header().Set("Content-Type", "application/json")
+	case ".tif":
+		w.Header().Set("Content-Type", "image/tiff")
+	default:
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	io.WriteString(w, assetBody(s.URL+r.URL.Path))
+}
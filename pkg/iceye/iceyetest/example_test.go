@@ -0,0 +1,66 @@
+package iceyetest_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye/iceyetest"
+)
+
+// A full tasking flow against the mock: create a task, wait for the
+// constellation to work through it, then list its products.
+func Example() {
+	srv := iceyetest.NewServer(
+		iceyetest.WithContracts("C-1"),
+		iceyetest.WithAutoAdvance(20*time.Millisecond),
+	)
+	defer srv.Close()
+
+	cli, err := srv.NewClient()
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+
+	now := time.Now()
+	task, err := cli.CreateTask(ctx, &iceye.CreateTaskRequest{
+		ContractID:        "C-1",
+		PointOfInterest:   &iceye.Point{Lat: 60.1699, Lon: 24.9384},
+		AcquisitionWindow: iceye.TimeWindow{Start: now.Add(24 * time.Hour), End: now.Add(72 * time.Hour)},
+		ImagingMode:       iceye.ImagingModeSpotlight,
+		Priority:          iceye.PriorityCommercial,
+		EULA:              iceye.EULAStandard,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("created:", task.Status)
+
+	err = common.Poll(ctx, common.WaitOptions{PollInterval: 10 * time.Millisecond, Timeout: 5 * time.Second},
+		func(ctx context.Context) (bool, error) {
+			task, err = cli.GetTask(ctx, task.ID)
+			return err == nil && task.Status == iceye.TaskStatusDone, err
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("finished:", task.Status)
+
+	products, err := cli.ListTaskProducts(ctx, task.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range products {
+		fmt.Println("product:", p.Type)
+	}
+
+	// Output:
+	// created: RECEIVED
+	// finished: DONE
+	// product: GRD
+	// product: SLC
+}
@@ -0,0 +1,471 @@
+// Package iceyetest provides an in-memory ICEYE API server for integration
+// tests of code built on the iceye package.
+//
+// A Server implements the endpoints the iceye client calls for OAuth2 token
+// issuance, tasking, price quotes, catalog browsing and purchases. Tasks move
+// through a scripted status progression, either on demand with Advance or
+// on a timer with WithAutoAdvance. Invalid input is answered with RFC 7807
+// problem documents like the real API's, and Fail injects rate limits or
+// server errors on individual endpoints.
+//
+//	srv := iceyetest.NewServer(iceyetest.WithContracts("C-1"))
+//	defer srv.Close()
+//
+//	cli, err := srv.NewClient()
+package iceyetest
+
+import (
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+)
+
+// Client credentials accepted by default.
+const (
+	DefaultClientID     = "iceyetest-client"
+	DefaultClientSecret = "iceyetest-secret"
+)
+
+// TokenPath is the path of the OAuth2 token endpoint on the server.
+const TokenPath = "/oauth2/token"
+
+// Server is an in-memory ICEYE API. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu  sync.Mutex
+	cfg config
+
+	tokens map[string]bool
+	seq    int
+
+	tasks     map[string]*task
+	taskOrder []string
+
+	items     []iceye.STACItem
+	cursors   map[string]cursorState
+	purchases map[string]*purchase
+	purchOrd  []string
+
+	// created maps the idempotency keys of create requests to the ID of
+	// the resource they created.
+	created map[string]string
+
+	faults map[Endpoint][]Fault
+	hits   map[Endpoint]int
+}
+
+type config struct {
+	clientID, clientSecret string
+	contracts              map[string]bool
+	progression            []iceye.TaskStatus
+	autoAdvance            time.Duration
+	prices                 map[iceye.PriceKey]iceye.TaskPrice
+	now                    func() time.Time
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithCredentials sets the client ID and secret the token endpoint accepts.
+// The defaults are DefaultClientID and DefaultClientSecret.
+func WithCredentials(clientID, clientSecret string) Option {
+	return func(s *Server) {
+		s.cfg.clientID, s.cfg.clientSecret = clientID, clientSecret
+	}
+}
+
+// WithContracts restricts the contract IDs accepted by tasking, pricing and
+// purchases. By default any non-empty contract ID is accepted.
+func WithContracts(ids ...string) Option {
+	return func(s *Server) {
+		s.cfg.contracts = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			s.cfg.contracts[id] = true
+		}
+	}
+}
+
+// WithProgression sets the statuses new tasks move through. The default is
+// RECEIVED, ACTIVE, DONE. Products become available once a task is
+// FULFILLED or DONE.
+func WithProgression(statuses ...iceye.TaskStatus) Option {
+	return func(s *Server) {
+		s.cfg.progression = statuses
+	}
+}
+
+// WithAutoAdvance advances every task one step of the progression each time
+// interval elapses, as if the constellation were working through it. Tasks
+// can still be advanced manually.
+func WithAutoAdvance(interval time.Duration) Option {
+	return func(s *Server) {
+		s.cfg.autoAdvance = interval
+	}
+}
+
+// WithPrices sets the price table used for quotes. Combinations missing from
+// the table fall back to DefaultPrices.
+func WithPrices(prices map[iceye.PriceKey]iceye.TaskPrice) Option {
+	return func(s *Server) {
+		s.cfg.prices = prices
+	}
+}
+
+// WithCatalogItems seeds the catalog, e.g. with items from LoadItems.
+func WithCatalogItems(items ...iceye.STACItem) Option {
+	return func(s *Server) {
+		s.items = append(s.items, items...)
+	}
+}
+
+// WithClock sets the server's clock, used for timestamps and auto advance.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.cfg.now = now
+	}
+}
+
+// NewServer starts a Server. Callers should Close it when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		cfg: config{
+			clientID:     DefaultClientID,
+			clientSecret: DefaultClientSecret,
+			progression:  []iceye.TaskStatus{iceye.TaskStatusReceived, iceye.TaskStatusActive, iceye.TaskStatusDone},
+			now:          time.Now,
+		},
+		tokens:    make(map[string]bool),
+		tasks:     make(map[string]*task),
+		cursors:   make(map[string]cursorState),
+		purchases: make(map[string]*purchase),
+		created:   make(map[string]string),
+		faults:    make(map[Endpoint][]Fault),
+		hits:      make(map[Endpoint]int),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.Server = httptest.NewServer(s.routes())
+	return s
+}
+
+// NewClient returns an iceye client for the server, authenticated with the
+// server's credentials. Further options are applied after the defaults.
+func (s *Server) NewClient(opts ...iceye.Option) (*iceye.Client, error) {
+	return iceye.NewClient(append([]iceye.Option{
+		iceye.WithBaseURL(s.URL),
+		iceye.WithTokenURL(s.URL + TokenPath),
+		iceye.WithHTTPClient(s.Client()),
+		iceye.WithCredentials(s.cfg.clientID, s.cfg.clientSecret),
+	}, opts...)...)
+}
+
+// Hits returns the number of requests made to ep, including failed ones.
+func (s *Server) Hits(ep Endpoint) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[ep]
+}
+
+// nextID returns a new identifier with the given prefix.
+func (s *Server) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s-%06d", prefix, s.seq)
+}
+
+// ----------------------------------------------------------------------------
+// Routing
+// ----------------------------------------------------------------------------
+
+// Endpoint names an API operation of the server, for Fail and Hits.
+type Endpoint string
+
+const (
+	EndpointToken            Endpoint = "token"
+	EndpointCreateTask       Endpoint = "tasks.create"
+	EndpointListTasks        Endpoint = "tasks.list"
+	EndpointGetTask          Endpoint = "tasks.get"
+	EndpointUpdateTask       Endpoint = "tasks.update"
+	EndpointTaskScene        Endpoint = "tasks.scene"
+	EndpointTaskProducts     Endpoint = "tasks.products"
+	EndpointTaskProduct      Endpoint = "tasks.product"
+	EndpointPrice            Endpoint = "price"
+	EndpointCatalogItems     Endpoint = "catalog.items"
+	EndpointCatalogSearch    Endpoint = "catalog.search"
+	EndpointCreatePurchase   Endpoint = "purchases.create"
+	EndpointListPurchases    Endpoint = "purchases.list"
+	EndpointGetPurchase      Endpoint = "purchases.get"
+	EndpointPurchaseProducts Endpoint = "purchases.products"
+)
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("POST "+TokenPath, s.endpoint(EndpointToken, false, s.handleToken))
+
+	mux.Handle("POST /tasking/v1/tasks", s.endpoint(EndpointCreateTask, true, s.handleCreateTask))
+	mux.Handle("GET /tasking/v1/tasks", s.endpoint(EndpointListTasks, true, s.handleListTasks))
+	mux.Handle("GET /tasking/v1/tasks/{id}", s.endpoint(EndpointGetTask, true, s.handleGetTask))
+	mux.Handle("PATCH /tasking/v1/tasks/{id}", s.endpoint(EndpointUpdateTask, true, s.handleUpdateTask))
+	mux.Handle("GET /tasking/v1/tasks/{id}/scene", s.endpoint(EndpointTaskScene, true, s.handleTaskScene))
+	mux.Handle("GET /tasking/v1/tasks/{id}/products", s.endpoint(EndpointTaskProducts, true, s.handleTaskProducts))
+	mux.Handle("GET /tasking/v1/tasks/{id}/products/{type}", s.endpoint(EndpointTaskProduct, true, s.handleTaskProduct))
+	mux.Handle("GET /tasking/v1/price", s.endpoint(EndpointPrice, true, s.handlePrice))
+
+	mux.Handle("GET /catalog/v1/items", s.endpoint(EndpointCatalogItems, true, s.handleCatalogItems))
+	mux.Handle("POST /catalog/v1/search", s.endpoint(EndpointCatalogSearch, true, s.handleCatalogSearch))
+	mux.Handle("POST /catalog/v1/purchases", s.endpoint(EndpointCreatePurchase, true, s.handleCreatePurchase))
+	mux.Handle("GET /catalog/v1/purchases", s.endpoint(EndpointListPurchases, true, s.handleListPurchases))
+	mux.Handle("GET /catalog/v1/purchases/{id}", s.endpoint(EndpointGetPurchase, true, s.handleGetPurchase))
+	mux.Handle("GET /catalog/v1/purchases/{id}/products", s.endpoint(EndpointPurchaseProducts, true, s.handlePurchaseProducts))
+
+	// Product assets are served without authentication, like presigned URLs.
+	mux.HandleFunc("GET "+assetsPath+"{path...}", s.handleAsset)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, r, http.StatusNotFound, "ERR_NOT_FOUND", "no such endpoint: "+r.Method+" "+r.URL.Path)
+	})
+	return mux
+}
+
+// endpoint wraps h with hit counting, fault injection and, if auth is set,
+// bearer token checks. h runs with the server locked.
+func (s *Server) endpoint(ep Endpoint, auth bool, h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.hits[ep]++
+		if f, ok := s.takeFault(ep); ok {
+			f.write(w, r)
+			return
+		}
+		if auth {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || !s.tokens[token] {
+				writeProblem(w, r, http.StatusUnauthorized, "ERR_UNAUTHORIZED", "missing or invalid access token")
+				return
+			}
+		}
+		h(w, r)
+	})
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if gt := r.PostFormValue("grant_type"); gt != "client_credentials" {
+		writeProblem(w, r, http.StatusBadRequest, "unsupported_grant_type", fmt.Sprintf("grant type %q is not supported", gt))
+		return
+	}
+	basic, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Basic ")
+	creds, _ := base64.StdEncoding.DecodeString(basic)
+	if string(creds) != s.cfg.clientID+":"+s.cfg.clientSecret {
+		writeProblem(w, r, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		return
+	}
+
+	token := s.nextID("token")
+	s.tokens[token] = true
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+// idempotencyKey returns the Idempotency-Key of a create request, scoped to
+// its path, or "" if it has none.
+func idempotencyKey(r *http.Request) string {
+	key := r.Header.Get(iceye.IdempotencyKeyHeader)
+	if key == "" {
+		return ""
+	}
+	return r.URL.Path + " " + key
+}
+
+// remember records that the request with key created the resource id, so
+// repeats of the request return it instead of creating another.
+func (s *Server) remember(key, id string) {
+	if key != "" {
+		s.created[key] = id
+	}
+}
+
+// checkContract reports a problem if id is not an accepted contract.
+func (s *Server) checkContract(w http.ResponseWriter, r *http.Request, field, id string) bool {
+	if id == "" {
+		writeValidation(w, r, iceye.FieldViolation{Field: field, Reason: "is required"})
+		return false
+	}
+	if s.cfg.contracts != nil && !s.cfg.contracts[id] {
+		writeProblem(w, r, http.StatusBadRequest, iceye.ErrCodeInvalidContract, fmt.Sprintf("contract %s does not exist or is not active", id))
+		return false
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+// Fault Injection
+// ----------------------------------------------------------------------------
+
+// Fault is an error response injected in place of an endpoint's normal
+// handling.
+type Fault struct {
+	Status int
+	Code   string
+	Detail string
+	// RetryAfter, if set, is sent as the Retry-After header in seconds.
+	RetryAfter time.Duration
+	// Times is the number of requests the fault applies to; zero means
+	// one, and a negative value means every request until ClearFaults.
+	Times int
+}
+
+// RateLimit returns a 429 Too Many Requests fault.
+func RateLimit(retryAfter time.Duration) Fault {
+	return Fault{
+		Status:     http.StatusTooManyRequests,
+		Code:       "ERR_RATE_LIMIT_EXCEEDED",
+		Detail:     "rate limit exceeded",
+		RetryAfter: retryAfter,
+	}
+}
+
+// ServerError returns a 5xx fault with the given status.
+func ServerError(status int) Fault {
+	return Fault{Status: status, Code: "ERR_INTERNAL", Detail: "injected failure"}
+}
+
+// Fail queues f for the next requests to ep. Faults queued for the same
+// endpoint apply in order.
+func (s *Server) Fail(ep Endpoint, f Fault) {
+	if f.Times == 0 {
+		f.Times = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[ep] = append(s.faults[ep], f)
+}
+
+// ClearFaults removes all queued faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.faults)
+}
+
+func (s *Server) takeFault(ep Endpoint) (Fault, bool) {
+	queue := s.faults[ep]
+	if len(queue) == 0 {
+		return Fault{}, false
+	}
+	f := queue[0]
+	if queue[0].Times > 0 {
+		queue[0].Times--
+		if queue[0].Times == 0 {
+			s.faults[ep] = queue[1:]
+		}
+	}
+	return f, true
+}
+
+func (f Fault) write(w http.ResponseWriter, r *http.Request) {
+	if f.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter.Round(time.Second)/time.Second)))
+	}
+	status := f.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	writeProblem(w, r, status, f.Code, f.Detail)
+}
+
+// ----------------------------------------------------------------------------
+// Responses
+// ----------------------------------------------------------------------------
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeProblem writes an RFC 7807 problem document.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string, violations ...iceye.FieldViolation) {
+	if code == "" {
+		code = http.StatusText(status)
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(iceye.Error{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Code:       code,
+		Detail:     detail,
+		Instance:   r.URL.Path,
+		Violations: violations,
+	})
+}
+
+// writeValidation writes a 400 problem listing field violations.
+func writeValidation(w http.ResponseWriter, r *http.Request, violations ...iceye.FieldViolation) {
+	writeProblem(w, r, http.StatusBadRequest, "ERR_VALIDATION", "request validation failed", violations...)
+}
+
+// decodeBody decodes a JSON request body, reporting a problem on failure.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	body := io.Reader(r.Body)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, "ERR_MALFORMED_BODY", "request body is not valid gzip: "+err.Error())
+			return false
+		}
+		defer zr.Close()
+		body = zr
+	}
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "ERR_MALFORMED_BODY", "request body is not valid JSON: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// page returns the window of n elements starting at the request's cursor,
+// and the cursor of the next window, or "" at the end. Cursors are offsets.
+func page(r *http.Request, n int) (start, end int, next string, ok bool) {
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 {
+			return 0, 0, "", false
+		}
+		limit = l
+	}
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		c, err := strconv.Atoi(v)
+		if err != nil || c < 0 || c > n {
+			return 0, 0, "", false
+		}
+		start = c
+	}
+	end = min(start+limit, n)
+	if end < n {
+		next = strconv.Itoa(end)
+	}
+	return start, end, next, true
+}
@@ -0,0 +1,297 @@
+package iceyetest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye/iceyetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T, opts ...iceyetest.Option) (*iceyetest.Server, *iceye.Client) {
+	t.Helper()
+	srv := iceyetest.NewServer(opts...)
+	t.Cleanup(srv.Close)
+	cli, err := srv.NewClient()
+	require.NoError(t, err)
+	return srv, cli
+}
+
+func taskRequest() *iceye.CreateTaskRequest {
+	now := time.Now()
+	return &iceye.CreateTaskRequest{
+		ContractID:        "C-1",
+		PointOfInterest:   &iceye.Point{Lat: 60.1699, Lon: 24.9384},
+		AcquisitionWindow: iceye.TimeWindow{Start: now.Add(24 * time.Hour), End: now.Add(48 * time.Hour)},
+		ImagingMode:       iceye.ImagingModeSpotlight,
+	}
+}
+
+// manualClock is a settable clock for WithClock.
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestServer_ManualProgression(t *testing.T) {
+	srv, cli := newServer(t, iceyetest.WithProgression(
+		iceye.TaskStatusReceived, iceye.TaskStatusActive, iceye.TaskStatusFulfilled, iceye.TaskStatusDone))
+	ctx := context.Background()
+
+	req := taskRequest()
+	req.AdditionalProductTypes = []iceye.AdditionalProductType{iceye.AdditionalProductTypeSICD}
+	task, err := cli.CreateTask(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, iceye.TaskStatusReceived, task.Status)
+
+	_, err = cli.ListTaskProducts(ctx, task.ID)
+	var apiErr *iceye.Error
+	require.True(t, errors.As(err, &apiErr), "expected *iceye.Error, got %v", err)
+	assert.Equal(t, http.StatusConflict, apiErr.Status)
+
+	for _, want := range []iceye.TaskStatus{iceye.TaskStatusActive, iceye.TaskStatusFulfilled} {
+		status, err := srv.Advance(task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, want, status)
+	}
+
+	products, err := cli.ListTaskProducts(ctx, task.ID)
+	require.NoError(t, err)
+	var types []string
+	for _, p := range products {
+		types = append(types, p.Type)
+	}
+	assert.Equal(t, []string{"GRD", "SLC", "SICD"}, types)
+
+	// Asset links point at the mock and can be downloaded.
+	resp, err := http.Get(products[0].Assets["data"].Href)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/tiff", resp.Header.Get("Content-Type"))
+
+	_, err = srv.Advance(task.ID)
+	require.NoError(t, err)
+	_, err = srv.Advance(task.ID)
+	assert.Error(t, err, "DONE is the end of the progression")
+}
+
+func TestServer_AutoAdvance(t *testing.T) {
+	clock := &manualClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	_, cli := newServer(t, iceyetest.WithClock(clock.Now), iceyetest.WithAutoAdvance(time.Hour))
+	ctx := context.Background()
+
+	task, err := cli.CreateTask(ctx, taskRequest())
+	require.NoError(t, err)
+
+	clock.Add(59 * time.Minute)
+	task, err = cli.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, iceye.TaskStatusReceived, task.Status)
+
+	clock.Add(time.Minute)
+	task, err = cli.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, iceye.TaskStatusActive, task.Status)
+
+	// Several intervals at once advance several steps, stopping at the end.
+	clock.Add(5 * time.Hour)
+	task, err = cli.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, iceye.TaskStatusDone, task.Status)
+	assert.Equal(t, time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC), task.UpdatedAt.UTC())
+}
+
+func TestServer_CancelFinishedTask(t *testing.T) {
+	srv, cli := newServer(t)
+	ctx := context.Background()
+
+	done := srv.AddTask(iceye.Task{ID: "T-done", Status: iceye.TaskStatusDone})
+	_, err := cli.CancelTask(ctx, done.ID)
+	var apiErr *iceye.Error
+	require.True(t, errors.As(err, &apiErr), "expected *iceye.Error, got %v", err)
+	assert.Equal(t, http.StatusConflict, apiErr.Status)
+
+	task, err := cli.CreateTask(ctx, taskRequest())
+	require.NoError(t, err)
+	task, err = cli.CancelTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, iceye.TaskStatusCanceled, task.Status)
+	_, err = srv.Advance(task.ID)
+	assert.Error(t, err, "a canceled task leaves the progression")
+}
+
+func TestServer_ValidationProblems(t *testing.T) {
+	_, cli := newServer(t, iceyetest.WithContracts("C-1"))
+	ctx := context.Background()
+
+	// Bypass client-side validation by sending a request the SDK accepts
+	// but the API rejects.
+	req := taskRequest()
+	req.AcquisitionWindow.End = req.AcquisitionWindow.Start.Add(-time.Hour)
+	req.Priority = "URGENT"
+	_, err := cli.CreateTask(ctx, req)
+
+	var apiErr *iceye.Error
+	require.True(t, errors.As(err, &apiErr), "expected *iceye.Error, got %v", err)
+	assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+	assert.True(t, apiErr.IsValidation())
+	assert.Equal(t, "Bad Request", apiErr.Title)
+	assert.Equal(t, "/tasking/v1/tasks", apiErr.Instance)
+	_, ok := apiErr.Violation("acquisitionWindow.end")
+	assert.True(t, ok, "missing acquisitionWindow.end violation in %v", apiErr.Violations)
+	_, ok = apiErr.Violation("priority")
+	assert.True(t, ok, "missing priority violation in %v", apiErr.Violations)
+
+	req = taskRequest()
+	req.ContractID = "C-unknown"
+	_, err = cli.CreateTask(ctx, req)
+	require.True(t, errors.As(err, &apiErr), "expected *iceye.Error, got %v", err)
+	assert.Equal(t, iceye.ErrCodeInvalidContract, apiErr.Code)
+
+	_, err = cli.GetTask(ctx, "T-missing")
+	assert.True(t, iceye.IsNotFound(err))
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, iceye.ErrCodeTaskNotFound, apiErr.Code)
+}
+
+func TestServer_Auth(t *testing.T) {
+	srv := iceyetest.NewServer()
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/tasking/v1/tasks")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+	cli, err := srv.NewClient(iceye.WithCredentials("someone", "wrong"))
+	require.NoError(t, err)
+	_, err = cli.GetTask(context.Background(), "T-1")
+	assert.True(t, iceye.IsUnauthorized(err), "expected 401, got %v", err)
+}
+
+func TestServer_Faults(t *testing.T) {
+	srv, cli := newServer(t)
+	ctx := context.Background()
+	task := srv.AddTask(iceye.Task{ID: "T-1", Status: iceye.TaskStatusActive})
+
+	srv.Fail(iceyetest.EndpointGetTask, iceyetest.RateLimit(2*time.Second))
+	srv.Fail(iceyetest.EndpointGetTask, func() iceyetest.Fault {
+		f := iceyetest.ServerError(http.StatusBadGateway)
+		f.Times = 2
+		return f
+	}())
+
+	_, err := cli.GetTask(ctx, task.ID)
+	assert.True(t, iceye.IsRateLimited(err), "expected 429, got %v", err)
+	for range 2 {
+		_, err = cli.GetTask(ctx, task.ID)
+		assert.True(t, iceye.IsServerError(err), "expected 502, got %v", err)
+	}
+	got, err := cli.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "T-1", got.ID)
+	assert.Equal(t, 4, srv.Hits(iceyetest.EndpointGetTask))
+
+	// Faults are per endpoint.
+	srv.Fail(iceyetest.EndpointPrice, iceyetest.Fault{Status: http.StatusServiceUnavailable, Times: -1})
+	_, err = cli.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	for range 3 {
+		_, err = cli.GetTaskPrice(ctx, &iceye.TaskPriceRequest{ContractID: "C-1", PointOfInterest: &iceye.Point{Lat: 1, Lon: 2}, ImagingMode: "SCAN"})
+		assert.True(t, iceye.IsServerError(err))
+	}
+	srv.ClearFaults()
+	_, err = cli.GetTaskPrice(ctx, &iceye.TaskPriceRequest{ContractID: "C-1", PointOfInterest: &iceye.Point{Lat: 1, Lon: 2}, ImagingMode: "SCAN"})
+	assert.NoError(t, err)
+}
+
+func TestServer_Prices(t *testing.T) {
+	key := iceye.PriceKey{ImagingMode: iceye.ImagingModeSpotlight, Priority: iceye.PriorityCommercial, Exclusivity: iceye.ExclusivityPrivate}
+	_, cli := newServer(t, iceyetest.WithPrices(map[iceye.PriceKey]iceye.TaskPrice{
+		key: {Amount: 123456, Currency: "USD"},
+	}))
+
+	m, err := cli.GetPriceMatrix(context.Background(), "C-1", iceye.Point{Lat: 60, Lon: 25}, iceye.PriceMatrixDims{})
+	require.NoError(t, err)
+	assert.Empty(t, m.Failed())
+
+	p, err := m.Get(key.ImagingMode, key.Priority, key.Exclusivity)
+	require.NoError(t, err)
+	assert.Equal(t, iceye.TaskPrice{Amount: 123456, Currency: "USD"}, *p)
+
+	p, err = m.Get(iceye.ImagingModeScan, iceye.PriorityBackground, iceye.ExclusivityPublic)
+	require.NoError(t, err)
+	assert.Equal(t, iceyetest.DefaultPrices[iceye.PriceKey{
+		ImagingMode: iceye.ImagingModeScan, Priority: iceye.PriorityBackground, Exclusivity: iceye.ExclusivityPublic,
+	}], *p)
+}
+
+func TestServer_CatalogAndPurchases(t *testing.T) {
+	items := iceyetest.SampleItems()
+	require.Len(t, items, 3)
+	_, cli := newServer(t, iceyetest.WithCatalogItems(items...))
+	ctx := context.Background()
+
+	// A search's filters carry over to the pages fetched by cursor.
+	var ids []string
+	for resp, err := range cli.SearchCatalogItems(ctx, &iceye.SearchRequest{
+		Datetime: "2024-01-01T00:00:00Z/2024-04-01T00:00:00Z",
+		Limit:    1,
+	}) {
+		require.NoError(t, err)
+		for _, item := range resp.Data {
+			ids = append(ids, item.ID)
+		}
+	}
+	assert.Equal(t, []string{items[0].ID, items[1].ID}, ids)
+
+	bbox := iceye.BoundingBox{25.0, 60.0, 25.2, 60.4}
+	ids = nil
+	for resp, err := range cli.ListCatalogItems(ctx, 10, &iceye.ListItemsOptions{BBox: &bbox}) {
+		require.NoError(t, err)
+		for _, item := range resp.Data {
+			ids = append(ids, item.ID)
+		}
+	}
+	assert.Equal(t, []string{items[2].ID}, ids)
+
+	_, err := cli.PurchaseCatalogItems(ctx, &iceye.PurchaseRequest{ContractID: "C-1", ItemIDs: []string{"nope"}})
+	var apiErr *iceye.Error
+	require.True(t, errors.As(err, &apiErr), "expected *iceye.Error, got %v", err)
+	assert.Equal(t, iceye.ErrCodeSceneUnavailable, apiErr.Code)
+
+	_, err = cli.PurchaseCatalogItems(ctx, &iceye.PurchaseRequest{ContractID: "C-1", Reference: strings.Repeat("x", 300)})
+	require.True(t, errors.As(err, &apiErr), "expected *iceye.Error, got %v", err)
+	assert.Len(t, apiErr.Violations, 2)
+
+	purchase, err := cli.PurchaseCatalogItems(ctx, &iceye.PurchaseRequest{ContractID: "C-1", ItemIDs: []string{items[1].ID}})
+	require.NoError(t, err)
+	p, err := cli.GetPurchase(ctx, purchase.PurchaseID)
+	require.NoError(t, err)
+	assert.Equal(t, iceye.PurchaseStatusClosed, p.Status)
+	products, err := cli.ListPurchasedProducts(ctx, purchase.PurchaseID)
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	assert.Equal(t, items[1].ID, products[0].ID)
+}
@@ -0,0 +1,505 @@
+package iceyetest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+)
+
+// ----------------------------------------------------------------------------
+// Tasks
+// ----------------------------------------------------------------------------
+
+// task is a stored task and its place in the progression.
+type task struct {
+	iceye.Task
+	// step indexes the progression; -1 if the task left it, e.g. when
+	// canceled or set to a status outside it.
+	step       int
+	advancedAt time.Time
+}
+
+// AddTask stores t as if it had been created through the API and returns
+// it. An empty ID is generated, an empty status is the first of the
+// progression, and zero timestamps are set to now.
+func (s *Server) AddTask(t iceye.Task) iceye.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.cfg.now()
+	if t.ID == "" {
+		t.ID = s.nextID("task")
+	}
+	if t.Status == "" {
+		t.Status = s.cfg.progression[0]
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+	}
+	if t.UpdatedAt.IsZero() {
+		t.UpdatedAt = t.CreatedAt
+	}
+	if _, ok := s.tasks[t.ID]; !ok {
+		s.taskOrder = append(s.taskOrder, t.ID)
+	}
+	s.tasks[t.ID] = &task{Task: t, step: slices.Index(s.cfg.progression, t.Status), advancedAt: now}
+	return t
+}
+
+// Task returns the stored task with the given ID.
+func (s *Server) Task(id string) (iceye.Task, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[id]
+	if !ok {
+		return iceye.Task{}, false
+	}
+	s.tick(t)
+	return t.Task, true
+}
+
+// Advance moves a task to the next status of the progression and returns
+// it. It fails if the task is unknown or at the end of its progression.
+func (s *Server) Advance(id string) (iceye.TaskStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[id]
+	if !ok {
+		return "", fmt.Errorf("iceyetest: task %s not found", id)
+	}
+	s.tick(t)
+	if t.step < 0 || t.step >= len(s.cfg.progression)-1 {
+		return t.Status, fmt.Errorf("iceyetest: task %s is %s and cannot advance", id, t.Status)
+	}
+	s.setStep(t, t.step+1, s.cfg.now())
+	return t.Status, nil
+}
+
+// AdvanceAll moves every task that can advance to its next status.
+func (s *Server) AdvanceAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.cfg.now()
+	for _, id := range s.taskOrder {
+		t := s.tasks[id]
+		s.tick(t)
+		if t.step >= 0 && t.step < len(s.cfg.progression)-1 {
+			s.setStep(t, t.step+1, now)
+		}
+	}
+}
+
+// SetStatus sets the status of a task, e.g. to FAILED or REJECTED. A status
+// outside the progression takes the task out of it.
+func (s *Server) SetStatus(id string, status iceye.TaskStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[id]
+	if !ok {
+		return fmt.Errorf("iceyetest: task %s not found", id)
+	}
+	now := s.cfg.now()
+	t.Status = status
+	t.step = slices.Index(s.cfg.progression, status)
+	t.advancedAt = now
+	t.UpdatedAt = now
+	return nil
+}
+
+func (s *Server) setStep(t *task, step int, at time.Time) {
+	t.step = step
+	t.Status = s.cfg.progression[step]
+	t.advancedAt = at
+	t.UpdatedAt = at
+}
+
+// tick applies the auto advance steps due since the task last advanced.
+func (s *Server) tick(t *task) {
+	interval := s.cfg.autoAdvance
+	if interval <= 0 {
+		return
+	}
+	now := s.cfg.now()
+	for t.step >= 0 && t.step < len(s.cfg.progression)-1 && now.Sub(t.advancedAt) >= interval {
+		s.setStep(t, t.step+1, t.advancedAt.Add(interval))
+	}
+}
+
+// lookupTask returns the task named by the request path, reporting a
+// problem if it does not exist.
+func (s *Server) lookupTask(w http.ResponseWriter, r *http.Request) (*task, bool) {
+	t, ok := s.tasks[r.PathValue("id")]
+	if !ok {
+		writeProblem(w, r, http.StatusNotFound, iceye.ErrCodeTaskNotFound, "task "+r.PathValue("id")+" not found")
+		return nil, false
+	}
+	s.tick(t)
+	return t, true
+}
+
+// fulfilled reports whether a task has products.
+func fulfilled(s iceye.TaskStatus) bool {
+	return s == iceye.TaskStatusFulfilled || s == iceye.TaskStatusDone
+}
+
+// open reports whether a task can still be canceled or updated.
+func open(s iceye.TaskStatus) bool {
+	return s == iceye.TaskStatusReceived || s == iceye.TaskStatusActive
+}
+
+func validImagingMode(mode string) bool {
+	switch iceye.ImagingMode(mode) {
+	case iceye.ImagingModeSpotlight, iceye.ImagingModeStripmap, iceye.ImagingModeScan:
+		return true
+	}
+	return false
+}
+
+// validateCreateTask checks a task request as the API does, independently
+// of CreateTaskRequest.Validate.
+func validateCreateTask(req *iceye.CreateTaskRequest) []iceye.FieldViolation {
+	var v []iceye.FieldViolation
+	add := func(field, reason string) {
+		v = append(v, iceye.FieldViolation{Field: field, Reason: reason})
+	}
+
+	if req.ImagingMode == "" {
+		add("imagingMode", "is required")
+	} else if !validImagingMode(string(req.ImagingMode)) {
+		add("imagingMode", fmt.Sprintf("unknown imaging mode %q", req.ImagingMode))
+	}
+
+	w := req.AcquisitionWindow
+	switch {
+	case w.Start.IsZero() || w.End.IsZero():
+		add("acquisitionWindow", "start and end are required")
+	case !w.End.After(w.Start):
+		add("acquisitionWindow.end", "must be after start")
+	}
+
+	hasPoint, hasArea := req.PointOfInterest != nil, req.AreaOfInterest != nil
+	switch {
+	case hasPoint && hasArea:
+		add("areaOfInterest", "cannot be combined with pointOfInterest")
+	case !hasPoint && !hasArea:
+		add("pointOfInterest", "one of pointOfInterest or areaOfInterest is required")
+	case hasPoint:
+		if p := *req.PointOfInterest; p.Lat < -90 || p.Lat > 90 || p.Lon < -180 || p.Lon > 180 {
+			add("pointOfInterest", "coordinates out of range")
+		}
+	case req.ImagingMode == iceye.ImagingModeSpotlight:
+		add("areaOfInterest", "not supported for SPOTLIGHT")
+	default:
+		switch req.AreaOfInterest.Geometry().(type) {
+		case orb.Polygon, orb.MultiPolygon:
+		default:
+			add("areaOfInterest", "must be a Polygon or MultiPolygon")
+		}
+	}
+
+	switch req.Priority {
+	case "", iceye.PriorityBackground, iceye.PriorityCommercial:
+	default:
+		add("priority", fmt.Sprintf("unknown priority %q", req.Priority))
+	}
+	switch req.Exclusivity {
+	case "", iceye.ExclusivityPublic, iceye.ExclusivityPrivate:
+	default:
+		add("exclusivity", fmt.Sprintf("unknown exclusivity %q", req.Exclusivity))
+	}
+	switch req.EULA {
+	case "", iceye.EULAStandard, iceye.EULAGovernment, iceye.EULAMulti:
+	default:
+		add("eula", fmt.Sprintf("unknown EULA %q", req.EULA))
+	}
+	return v
+}
+
+func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
+	var req iceye.CreateTaskRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if v := validateCreateTask(&req); len(v) > 0 {
+		writeValidation(w, r, v...)
+		return
+	}
+	if !s.checkContract(w, r, "contractID", req.ContractID) {
+		return
+	}
+	key := idempotencyKey(r)
+	if id, ok := s.created[key]; ok {
+		writeJSON(w, http.StatusCreated, s.tasks[id].Task)
+		return
+	}
+
+	now := s.cfg.now()
+	t := &task{
+		Task: iceye.Task{
+			ID:                     s.nextID("task"),
+			ContractID:             req.ContractID,
+			PointOfInterest:        req.PointOfInterest,
+			AreaOfInterest:         req.AreaOfInterest,
+			AcquisitionWindow:      req.AcquisitionWindow,
+			ImagingMode:            req.ImagingMode,
+			Exclusivity:            req.Exclusivity,
+			Priority:               req.Priority,
+			SLA:                    req.SLA,
+			EULA:                   req.EULA,
+			AdditionalProductTypes: req.AdditionalProductTypes,
+			IncidenceAngle:         req.IncidenceAngle,
+			LookSide:               req.LookSide,
+			PassDirection:          req.PassDirection,
+			DeliveryLocations:      req.DeliveryLocations,
+			CreatedAt:              now,
+			UpdatedAt:              now,
+		},
+		advancedAt: now,
+	}
+	s.setStep(t, 0, now)
+	s.tasks[t.ID] = t
+	s.taskOrder = append(s.taskOrder, t.ID)
+	s.remember(key, t.ID)
+	writeJSON(w, http.StatusCreated, t.Task)
+}
+
+func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var statuses []iceye.TaskStatus
+	for _, st := range q["status"] {
+		if !iceye.TaskStatus(st).IsKnown() {
+			writeValidation(w, r, iceye.FieldViolation{Field: "status", Reason: fmt.Sprintf("unknown status %q", st)})
+			return
+		}
+		statuses = append(statuses, iceye.TaskStatus(st))
+	}
+
+	var tasks []iceye.Task
+	for _, id := range s.taskOrder {
+		t := s.tasks[id]
+		s.tick(t)
+		if c := q.Get("contractID"); c != "" && t.ContractID != c {
+			continue
+		}
+		if len(statuses) > 0 && !slices.Contains(statuses, t.Status) {
+			continue
+		}
+		tasks = append(tasks, t.Task)
+	}
+
+	start, end, next, ok := page(r, len(tasks))
+	if !ok {
+		writeValidation(w, r, iceye.FieldViolation{Field: "cursor", Reason: "invalid limit or cursor"})
+		return
+	}
+	writeJSON(w, http.StatusOK, iceye.TasksResponse{Data: tasks[start:end], Cursor: next})
+}
+
+func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	if t, ok := s.lookupTask(w, r); ok {
+		writeJSON(w, http.StatusOK, t.Task)
+	}
+}
+
+func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.lookupTask(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Status iceye.TaskStatus `json:"status"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Status != iceye.TaskStatusCanceled {
+		writeValidation(w, r, iceye.FieldViolation{Field: "status", Reason: "must be CANCELED"})
+		return
+	}
+	if !open(t.Status) {
+		writeProblem(w, r, http.StatusConflict, "ERR_TASK_NOT_MODIFIABLE",
+			fmt.Sprintf("task %s is %s and can no longer be modified", t.ID, t.Status))
+		return
+	}
+
+	t.Status = iceye.TaskStatusCanceled
+	t.step = -1
+	t.UpdatedAt = s.cfg.now()
+	writeJSON(w, http.StatusOK, t.Task)
+}
+
+func (s *Server) handleTaskScene(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.lookupTask(w, r)
+	if !ok {
+		return
+	}
+	if t.Status == iceye.TaskStatusReceived || !open(t.Status) && !fulfilled(t.Status) {
+		writeProblem(w, r, http.StatusNotFound, "ERR_SCENE_NOT_FOUND", fmt.Sprintf("task %s is %s and has no planned scene", t.ID, t.Status))
+		return
+	}
+
+	const duration = 10
+	start := t.AcquisitionWindow.Start
+	writeJSON(w, http.StatusOK, iceye.TaskScene{
+		ImagingTime:   iceye.TimeWindow{Start: start, End: start.Add(duration * time.Second)},
+		Duration:      duration,
+		LookSide:      cmpOr(t.LookSide, iceye.LookSideAny, iceye.LookSideRight),
+		PassDirection: cmpOr(t.PassDirection, iceye.PassDirectionAny, iceye.PassDirectionAscending),
+		Footprint:     t.AreaOfInterest,
+	})
+}
+
+// cmpOr returns v unless it is empty or unset, in which case it returns def.
+func cmpOr[T ~string](v, unset, def T) T {
+	if v == "" || v == unset {
+		return def
+	}
+	return v
+}
+
+// taskProducts returns the products of a fulfilled task: GRD and SLC, plus
+// the additional product types ordered.
+func (s *Server) taskProducts(t *task) []iceye.TaskProduct {
+	types := []string{"GRD", "SLC"}
+	for _, pt := range t.AdditionalProductTypes {
+		types = append(types, string(pt))
+	}
+	products := make([]iceye.TaskProduct, len(types))
+	for i, pt := range types {
+		base := s.URL + assetsPath + "tasks/" + t.ID + "/" + pt
+		products[i] = iceye.TaskProduct{
+			Type: pt,
+			Assets: map[string]iceye.Asset{
+				"data": {
+					Href:  base + ".tif",
+					Title: pt + " image",
+					Type:  "image/tiff",
+					Roles: []string{"data"},
+					Size:  int64(len(assetBody(base + ".tif"))),
+				},
+				"metadata": {
+					Href:  base + ".json",
+					Title: pt + " metadata",
+					Type:  "application/json",
+					Roles: []string{"metadata"},
+					Size:  int64(len(assetBody(base + ".json"))),
+				},
+			},
+		}
+	}
+	return products
+}
+
+func (s *Server) fulfilledTask(w http.ResponseWriter, r *http.Request) (*task, bool) {
+	t, ok := s.lookupTask(w, r)
+	if !ok {
+		return nil, false
+	}
+	if !fulfilled(t.Status) {
+		writeProblem(w, r, http.StatusConflict, "ERR_TASK_NOT_FULFILLED", fmt.Sprintf("task %s is %s and has no products yet", t.ID, t.Status))
+		return nil, false
+	}
+	return t, true
+}
+
+func (s *Server) handleTaskProducts(w http.ResponseWriter, r *http.Request) {
+	if t, ok := s.fulfilledTask(w, r); ok {
+		writeJSON(w, http.StatusOK, s.taskProducts(t))
+	}
+}
+
+func (s *Server) handleTaskProduct(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.fulfilledTask(w, r)
+	if !ok {
+		return
+	}
+	for _, p := range s.taskProducts(t) {
+		if p.Type == r.PathValue("type") {
+			writeJSON(w, http.StatusOK, p)
+			return
+		}
+	}
+	writeProblem(w, r, http.StatusNotFound, "ERR_PRODUCT_NOT_FOUND", fmt.Sprintf("task %s has no %s product", t.ID, r.PathValue("type")))
+}
+
+// ----------------------------------------------------------------------------
+// Pricing
+// ----------------------------------------------------------------------------
+
+// DefaultPrices are the quotes, in euro cents, for combinations missing
+// from the WithPrices table.
+var DefaultPrices = defaultPrices()
+
+func defaultPrices() map[iceye.PriceKey]iceye.TaskPrice {
+	base := map[iceye.ImagingMode]int64{
+		iceye.ImagingModeSpotlight: 500000,
+		iceye.ImagingModeStripmap:  300000,
+		iceye.ImagingModeScan:      400000,
+	}
+	prices := make(map[iceye.PriceKey]iceye.TaskPrice)
+	for mode, amount := range base {
+		for _, priority := range []iceye.Priority{iceye.PriorityBackground, iceye.PriorityCommercial} {
+			for _, excl := range []iceye.Exclusivity{iceye.ExclusivityPublic, iceye.ExclusivityPrivate} {
+				a := amount
+				if priority == iceye.PriorityCommercial {
+					a = a * 3 / 2
+				}
+				if excl == iceye.ExclusivityPrivate {
+					a *= 2
+				}
+				prices[iceye.PriceKey{ImagingMode: mode, Priority: priority, Exclusivity: excl}] = iceye.TaskPrice{Amount: a, Currency: "EUR"}
+			}
+		}
+	}
+	return prices
+}
+
+func (s *Server) handlePrice(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var v []iceye.FieldViolation
+	mode := q.Get("imagingMode")
+	if !validImagingMode(mode) {
+		v = append(v, iceye.FieldViolation{Field: "imagingMode", Reason: fmt.Sprintf("unknown imaging mode %q", mode)})
+	}
+	if q.Get("areaOfInterest") == "" {
+		for _, field := range []string{"pointOfInterest[lat]", "pointOfInterest[lon]"} {
+			if _, err := strconv.ParseFloat(q.Get(field), 64); err != nil {
+				v = append(v, iceye.FieldViolation{Field: field, Reason: "must be a number"})
+			}
+		}
+	}
+	if len(v) > 0 {
+		writeValidation(w, r, v...)
+		return
+	}
+	if !s.checkContract(w, r, "contractID", q.Get("contractID")) {
+		return
+	}
+
+	key := iceye.PriceKey{
+		ImagingMode: iceye.ImagingMode(mode),
+		Priority:    iceye.Priority(q.Get("priority")),
+		Exclusivity: iceye.Exclusivity(q.Get("exclusivity")),
+	}
+	if key.Priority == "" {
+		key.Priority = iceye.PriorityCommercial
+	}
+	if key.Exclusivity == "" {
+		key.Exclusivity = iceye.ExclusivityPublic
+	}
+	price, ok := s.cfg.prices[key]
+	if !ok {
+		price, ok = DefaultPrices[key]
+	}
+	if !ok {
+		writeProblem(w, r, http.StatusBadRequest, "ERR_PRICE_UNAVAILABLE", "no price for "+key.String())
+		return
+	}
+	writeJSON(w, http.StatusOK, price)
+}
@@ -0,0 +1,123 @@
+package iceye
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the idempotency
+	// key of a create call. The server answers repeats of a keyed request
+	// with the resource created by the first.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// DefaultIdempotentRetries is the number of times a call sent with an
+	// idempotency key is retried after a retryable failure.
+	DefaultIdempotentRetries = 2
+
+	defaultIdempotentBackoff = 500 * time.Millisecond
+)
+
+// ----------------------------------------------------------------------------
+// Idempotency Keys
+// ----------------------------------------------------------------------------
+
+// CallOption configures a single call to CreateTask or PurchaseCatalogItems.
+type CallOption func(*callConfig)
+
+type callConfig struct {
+	idempotencyKey string
+}
+
+// WithIdempotencyKey sends key as the call's Idempotency-Key, taking
+// precedence over a key generated by WithAutoIdempotency. Persist the key
+// before the call to retry it safely from another process after a crash or
+// an unknown outcome.
+func WithIdempotencyKey(key string) CallOption {
+	return func(c *callConfig) {
+		c.idempotencyKey = key
+	}
+}
+
+// WithAutoIdempotency makes CreateTask and PurchaseCatalogItems send a
+// generated idempotency key when the call does not set one. The key used is
+// returned in the result's IdempotencyKey field.
+func WithAutoIdempotency() Option {
+	return func(c *clientConfig) {
+		c.autoIdempotency = true
+	}
+}
+
+// WithIdempotentRetries sets how many times a call sent with an idempotency
+// key is retried after a network error, a 429 or a 5xx response, reusing the
+// same key so the retries cannot create a second resource. Calls without a
+// key are never retried. Zero disables the retries.
+func WithIdempotentRetries(n int) Option {
+	return func(c *clientConfig) {
+		c.idempotentRetries = max(n, 0)
+	}
+}
+
+// NewIdempotencyKey returns a random (version 4) UUID for use as an
+// idempotency key.
+func NewIdempotencyKey() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// idempotencyKey returns the key to send for a call with opts, or "" if it
+// should be sent without one.
+func (c *Client) idempotencyKey(opts []CallOption) string {
+	var cfg callConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.idempotencyKey == "" && c.autoIdempotency {
+		return NewIdempotencyKey()
+	}
+	return cfg.idempotencyKey
+}
+
+// doIdempotent performs a request like do, sending key as the
+// Idempotency-Key and retrying retryable failures with the same key. Without
+// a key the request is sent once.
+func (c *Client) doIdempotent(ctx context.Context, method, urlStr string, in, out any, key string) error {
+	if key == "" {
+		return c.do(ctx, method, urlStr, in, out)
+	}
+	header := http.Header{IdempotencyKeyHeader: {key}}
+	backoff := c.idempotentBackoff
+	for attempt := 0; ; attempt++ {
+		_, _, err := c.send(ctx, method, urlStr, in, out, CacheValidator{}, header)
+		if err == nil || attempt >= c.idempotentRetries || !retryable(ctx, err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether a request that failed with err may have failed
+// transiently: a network error or timeout while ctx is still live, a 429 or
+// a 5xx response. Other failures, such as an undecodable response, would
+// fail again.
+func retryable(ctx context.Context, err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Status == http.StatusTooManyRequests || e.Status >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && ctx.Err() == nil
+}
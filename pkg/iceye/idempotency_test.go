@@ -0,0 +1,216 @@
+package iceye_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye/iceyetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func idempotentTaskRequest() *iceye.CreateTaskRequest {
+	now := time.Now()
+	return &iceye.CreateTaskRequest{
+		ContractID:        "C-1",
+		PointOfInterest:   &iceye.Point{Lat: 60.1699, Lon: 24.9384},
+		AcquisitionWindow: iceye.TimeWindow{Start: now.Add(24 * time.Hour), End: now.Add(48 * time.Hour)},
+		ImagingMode:       iceye.ImagingModeSpotlight,
+	}
+}
+
+// keyRecorder records the Idempotency-Key header of each request.
+type keyRecorder struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (k *keyRecorder) record(r *http.Request) int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = append(k.keys, r.Header.Get(iceye.IdempotencyKeyHeader))
+	return len(k.keys)
+}
+
+func (k *keyRecorder) all() []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return append([]string(nil), k.keys...)
+}
+
+func TestCreateTaskIdempotencyKey(t *testing.T) {
+	var rec keyRecorder
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("POST /tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			rec.record(r)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(iceye.Task{ID: "T-1"})
+		})
+	})
+
+	task, err := cli.CreateTask(context.Background(), idempotentTaskRequest(), iceye.WithIdempotencyKey("key-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", task.IdempotencyKey)
+
+	task, err = cli.CreateTask(context.Background(), idempotentTaskRequest())
+	require.NoError(t, err)
+	assert.Empty(t, task.IdempotencyKey)
+
+	assert.Equal(t, []string{"key-1", ""}, rec.all())
+}
+
+func TestAutoIdempotency(t *testing.T) {
+	var rec keyRecorder
+	_, srv, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("POST /tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			rec.record(r)
+			json.NewEncoder(w).Encode(iceye.Task{ID: "T-1"})
+		})
+	})
+	cli, err := iceye.NewClient(
+		iceye.WithBaseURL(srv.URL),
+		iceye.WithTokenURL(srv.URL+"/oauth2/token"),
+		iceye.WithHTTPClient(srv.Client()),
+		iceye.WithCredentials("test", "secret"),
+		iceye.WithAutoIdempotency(),
+	)
+	require.NoError(t, err)
+
+	first, err := cli.CreateTask(context.Background(), idempotentTaskRequest())
+	require.NoError(t, err)
+	second, err := cli.CreateTask(context.Background(), idempotentTaskRequest())
+	require.NoError(t, err)
+	caller, err := cli.CreateTask(context.Background(), idempotentTaskRequest(), iceye.WithIdempotencyKey("mine"))
+	require.NoError(t, err)
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	assert.Regexp(t, uuid, first.IdempotencyKey)
+	assert.NotEqual(t, first.IdempotencyKey, second.IdempotencyKey, "each call gets its own key")
+	assert.Equal(t, "mine", caller.IdempotencyKey, "caller key takes precedence")
+	assert.Equal(t, []string{first.IdempotencyKey, second.IdempotencyKey, "mine"}, rec.all())
+}
+
+func TestIdempotentRetryAfterTimeout(t *testing.T) {
+	var rec keyRecorder
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+	mux.HandleFunc("POST /tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+		if rec.record(r) == 1 {
+			// The task is created but the response is lost.
+			time.Sleep(300 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(iceye.Task{ID: "T-1"})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	newClient := func(opts ...iceye.Option) *iceye.Client {
+		cli, err := iceye.NewClient(append([]iceye.Option{
+			iceye.WithBaseURL(srv.URL),
+			iceye.WithTokenURL(srv.URL + "/oauth2/token"),
+			iceye.WithHTTPClient(&http.Client{Timeout: 100 * time.Millisecond}),
+			iceye.WithCredentials("test", "secret"),
+		}, opts...)...)
+		require.NoError(t, err)
+		iceye.SetIdempotentBackoff(cli, time.Millisecond)
+		return cli
+	}
+
+	t.Run("keyed call is retried with the same key", func(t *testing.T) {
+		rec.keys = nil
+		task, err := newClient(iceye.WithAutoIdempotency()).CreateTask(context.Background(), idempotentTaskRequest())
+		require.NoError(t, err)
+		keys := rec.all()
+		require.Len(t, keys, 2)
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+		assert.Equal(t, keys[0], task.IdempotencyKey)
+	})
+
+	t.Run("call without key is not retried", func(t *testing.T) {
+		rec.keys = nil
+		_, err := newClient().CreateTask(context.Background(), idempotentTaskRequest())
+		require.Error(t, err)
+		assert.Len(t, rec.all(), 1)
+	})
+
+	t.Run("retries disabled", func(t *testing.T) {
+		rec.keys = nil
+		_, err := newClient(iceye.WithIdempotentRetries(0)).CreateTask(context.Background(), idempotentTaskRequest(), iceye.WithIdempotencyKey("k"))
+		require.Error(t, err)
+		assert.Len(t, rec.all(), 1)
+	})
+}
+
+func TestIdempotentRetryStopsOnClientError(t *testing.T) {
+	var rec keyRecorder
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("POST /tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			rec.record(r)
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(iceye.Error{Code: "ERR_CONFLICT", Status: http.StatusConflict})
+		})
+	})
+
+	_, err := cli.CreateTask(context.Background(), idempotentTaskRequest(), iceye.WithIdempotencyKey("k"))
+	require.Error(t, err)
+	assert.Len(t, rec.all(), 1)
+}
+
+func TestIdempotentRetryStopsOnDecodeError(t *testing.T) {
+	var rec keyRecorder
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("POST /tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			rec.record(r)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("not json"))
+		})
+	})
+
+	_, err := cli.CreateTask(context.Background(), idempotentTaskRequest(), iceye.WithIdempotencyKey("k"))
+	require.Error(t, err)
+	assert.Len(t, rec.all(), 1)
+}
+
+func TestPurchaseCatalogItemsIdempotency(t *testing.T) {
+	srv := iceyetest.NewServer(iceyetest.WithCatalogItems(iceye.STACItem{ID: "item-1"}))
+	t.Cleanup(srv.Close)
+	cli, err := srv.NewClient(iceye.WithAutoIdempotency())
+	require.NoError(t, err)
+	iceye.SetIdempotentBackoff(cli, time.Millisecond)
+	ctx := context.Background()
+	req := &iceye.PurchaseRequest{ContractID: "C-1", ItemIDs: []string{"item-1"}}
+
+	srv.Fail(iceyetest.EndpointCreatePurchase, iceyetest.ServerError(http.StatusServiceUnavailable))
+	first, err := cli.PurchaseCatalogItems(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, srv.Hits(iceyetest.EndpointCreatePurchase))
+	assert.NotEmpty(t, first.IdempotencyKey)
+
+	// Repeating the purchase with its key, as after a crash, returns the
+	// original purchase instead of buying the items again.
+	again, err := cli.PurchaseCatalogItems(ctx, req, iceye.WithIdempotencyKey(first.IdempotencyKey))
+	require.NoError(t, err)
+	assert.Equal(t, first.PurchaseID, again.PurchaseID)
+
+	var n int
+	for page, err := range cli.ListPurchases(ctx, 10) {
+		require.NoError(t, err)
+		n += len(page.Data)
+	}
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,139 @@
+package iceye
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// ----------------------------------------------------------------------------
+// HTTP Middleware
+// ----------------------------------------------------------------------------
+
+// RoundTripFunc executes an HTTP request, like http.RoundTripper.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// Middleware wraps the execution of every HTTP request the client sends,
+// for logging, tracing or metrics. It sees the request, including its
+// context, and the response or error returned by next.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware adds middlewares around the client's HTTP requests, API
+// calls and token fetches of the built-in authenticators alike. The first
+// middleware is the outermost: it sees the request first and the response
+// last. Repeated options append to the chain.
+//
+// The chain wraps a copy of the HTTP client, so the http.Client given to
+// WithHTTPClient is not modified. Authenticators passed to WithAuth use
+// their own HTTP client and bypass the chain.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *clientConfig) {
+		if !c.chained {
+			c.middlewares = append(c.middlewares, mw...)
+		}
+	}
+}
+
+// chain returns a copy of hc whose transport runs mws around the original
+// one.
+func chain(hc *http.Client, mws []Middleware) *http.Client {
+	base := hc.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	next := RoundTripFunc(base.RoundTrip)
+	for i := len(mws) - 1; i >= 0; i-- {
+		next = mws[i](next)
+	}
+	wrapped := *hc
+	wrapped.Transport = next
+	return &wrapped
+}
+
+type tokenFetchKey struct{}
+
+// withTokenFetch marks ctx as that of an OAuth2 token request.
+func withTokenFetch(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tokenFetchKey{}, true)
+}
+
+// IsTokenFetch reports whether req is a token request of the built-in
+// authenticators rather than an API call, for middlewares to tell them
+// apart.
+func IsTokenFetch(req *http.Request) bool {
+	marked, _ := req.Context().Value(tokenFetchKey{}).(bool)
+	return marked
+}
+
+// LoggingMiddleware logs every request at debug level and failures at warn
+// level: method, URL path, status, duration and whether it fetched a token.
+// Request headers are logged with credentials redacted; bodies are never
+// logged.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+				slog.Duration("duration", time.Since(start)),
+				slog.Bool("token_fetch", IsTokenFetch(req)),
+				slog.Any("headers", common.RedactHeaders(req.Header)),
+			}
+			level := slog.LevelDebug
+			switch {
+			case err != nil:
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.Any("error", err))
+			case resp.StatusCode >= 400:
+				level = slog.LevelWarn
+				fallthrough
+			default:
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			}
+			logger.LogAttrs(req.Context(), level, "iceye request", attrs...)
+			return resp, err
+		}
+	}
+}
+
+// RequestMetrics describes a completed HTTP request.
+type RequestMetrics struct {
+	Method string
+	Path   string
+	// Status is the response status code, or 0 if the request failed.
+	Status     int
+	Duration   time.Duration
+	Err        error
+	TokenFetch bool
+}
+
+// MetricsMiddleware calls record after every request with its latency and
+// status, e.g. to feed a histogram.
+func MetricsMiddleware(record func(context.Context, RequestMetrics)) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			m := RequestMetrics{
+				Method:     req.Method,
+				Path:       req.URL.Path,
+				Duration:   time.Since(start),
+				Err:        err,
+				TokenFetch: IsTokenFetch(req),
+			}
+			if resp != nil {
+				m.Status = resp.StatusCode
+			}
+			record(req.Context(), m)
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package iceye_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMiddlewareClient returns a client for srv with the given middlewares.
+func newMiddlewareClient(t *testing.T, srv *httptest.Server, mw ...iceye.Middleware) *iceye.Client {
+	t.Helper()
+	cli, err := iceye.NewClient(
+		iceye.WithBaseURL(srv.URL),
+		iceye.WithTokenURL(srv.URL+"/oauth2/token"),
+		iceye.WithHTTPClient(srv.Client()),
+		iceye.WithCredentials("test", "secret"),
+		iceye.WithMiddleware(mw...),
+	)
+	require.NoError(t, err)
+	return cli
+}
+
+func newContractServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+	mux.HandleFunc("/company/v1/contracts/C-1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"C-1","name":"Test"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	srv := newContractServer(t)
+
+	var (
+		mu    sync.Mutex
+		trace []string
+	)
+	layer := func(name string) iceye.Middleware {
+		return func(next iceye.RoundTripFunc) iceye.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				trace = append(trace, name+">"+req.URL.Path)
+				mu.Unlock()
+				resp, err := next(req)
+				mu.Lock()
+				trace = append(trace, name+"<")
+				mu.Unlock()
+				return resp, err
+			}
+		}
+	}
+	cli := newMiddlewareClient(t, srv, layer("outer"), layer("inner"))
+
+	_, err := cli.GetContract(context.Background(), "C-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"outer>/oauth2/token", "inner>/oauth2/token", "inner<", "outer<",
+		"outer>/company/v1/contracts/C-1", "inner>/company/v1/contracts/C-1", "inner<", "outer<",
+	}, trace)
+}
+
+func TestMiddlewareErrorPropagation(t *testing.T) {
+	srv := newContractServer(t)
+	errBlocked := errors.New("blocked by policy")
+
+	var seen []error
+	observe := func(next iceye.RoundTripFunc) iceye.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			seen = append(seen, err)
+			return resp, err
+		}
+	}
+	block := func(next iceye.RoundTripFunc) iceye.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if !iceye.IsTokenFetch(req) {
+				return nil, errBlocked
+			}
+			return next(req)
+		}
+	}
+	cli := newMiddlewareClient(t, srv, observe, block)
+
+	_, err := cli.GetContract(context.Background(), "C-1")
+	require.ErrorIs(t, err, errBlocked)
+	require.Len(t, seen, 2)
+	assert.NoError(t, seen[0], "token fetch")
+	assert.ErrorIs(t, seen[1], errBlocked)
+}
+
+func TestLoggingMiddlewareRedacts(t *testing.T) {
+	srv := newContractServer(t)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cli := newMiddlewareClient(t, srv, iceye.LoggingMiddleware(logger))
+
+	_, err := cli.GetContract(context.Background(), "C-1")
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, `"path":"/company/v1/contracts/C-1"`)
+	assert.Contains(t, out, `"status":200`)
+	assert.Contains(t, out, `"Authorization":["[REDACTED]"]`)
+	assert.NotContains(t, out, "test-token")
+	assert.NotContains(t, out, "dGVzdDpzZWNyZXQ=", "basic credentials of the token fetch")
+	assert.Equal(t, 2, strings.Count(out, "\n"))
+}
+
+func TestMetricsMiddlewareTokenFetch(t *testing.T) {
+	srv := newContractServer(t)
+	var metrics []iceye.RequestMetrics
+	cli := newMiddlewareClient(t, srv, iceye.MetricsMiddleware(func(_ context.Context, m iceye.RequestMetrics) {
+		metrics = append(metrics, m)
+	}))
+
+	_, err := cli.GetContract(context.Background(), "C-1")
+	require.NoError(t, err)
+	_, err = cli.GetContract(context.Background(), "C-1")
+	require.NoError(t, err)
+
+	require.Len(t, metrics, 3, "one token fetch and two API calls")
+	assert.True(t, metrics[0].TokenFetch)
+	assert.Equal(t, "/oauth2/token", metrics[0].Path)
+	for _, m := range metrics[1:] {
+		assert.False(t, m.TokenFetch)
+		assert.Equal(t, http.MethodGet, m.Method)
+		assert.Equal(t, http.StatusOK, m.Status)
+		assert.Positive(t, m.Duration)
+	}
+}
+
+func TestMiddlewareLeavesHTTPClient(t *testing.T) {
+	srv := newContractServer(t)
+	hc := srv.Client()
+	transport := hc.Transport
+	newMiddlewareClient(t, srv, iceye.MetricsMiddleware(func(context.Context, iceye.RequestMetrics) {}))
+	assert.Equal(t, transport, hc.Transport)
+}
@@ -0,0 +1,236 @@
+package iceye
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// ----------------------------------------------------------------------------
+// Price Matrix
+// ----------------------------------------------------------------------------
+
+// defaultPriceConcurrency bounds concurrent price queries in GetPriceMatrix.
+const defaultPriceConcurrency = 4
+
+// PriceMatrixDims selects the dimensions quoted by GetPriceMatrix. Empty
+// slices default to every known value.
+type PriceMatrixDims struct {
+	ImagingModes  []ImagingMode
+	Priorities    []Priority
+	Exclusivities []Exclusivity
+	SLA           string
+	EULA          EULA // defaults to EULAStandard
+
+	// Concurrency bounds the number of in-flight price queries (default 4).
+	Concurrency int
+}
+
+// PriceKey identifies a cell of a PriceMatrix.
+type PriceKey struct {
+	ImagingMode ImagingMode
+	Priority    Priority
+	Exclusivity Exclusivity
+}
+
+// PriceCell holds the quote for one PriceKey, or the error returned for it.
+type PriceCell struct {
+	Price *TaskPrice
+	Err   error
+}
+
+// PriceMatrix holds price quotes for every combination of imaging mode,
+// priority and exclusivity.
+type PriceMatrix struct {
+	ContractID string
+	Cells      map[PriceKey]PriceCell
+}
+
+// Get returns the quote for the given combination.
+func (m *PriceMatrix) Get(mode ImagingMode, priority Priority, exclusivity Exclusivity) (*TaskPrice, error) {
+	cell, ok := m.Cells[PriceKey{mode, priority, exclusivity}]
+	if !ok {
+		return nil, fmt.Errorf("iceye: no price quoted for %s/%s/%s", mode, priority, exclusivity)
+	}
+	return cell.Price, cell.Err
+}
+
+// Failed returns the keys of cells whose quote failed.
+func (m *PriceMatrix) Failed() []PriceKey {
+	var keys []PriceKey
+	for k, cell := range m.Cells {
+		if cell.Err != nil {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, func(a, b PriceKey) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return keys
+}
+
+// String returns "MODE/PRIORITY/EXCLUSIVITY".
+func (k PriceKey) String() string {
+	return string(k.ImagingMode) + "/" + string(k.Priority) + "/" + string(k.Exclusivity)
+}
+
+// GetPriceMatrix quotes every combination selected by dims for a task at poi,
+// running up to dims.Concurrency queries at once. A failed quote is recorded
+// in its cell and does not fail the matrix; an error is returned only if ctx
+// is done before all cells are quoted.
+func (c *Client) GetPriceMatrix(ctx context.Context, contractID string, poi Point, dims PriceMatrixDims) (*PriceMatrix, error) {
+	modes := dims.ImagingModes
+	if len(modes) == 0 {
+		modes = []ImagingMode{ImagingModeSpotlight, ImagingModeStripmap, ImagingModeScan}
+	}
+	priorities := dims.Priorities
+	if len(priorities) == 0 {
+		priorities = []Priority{PriorityBackground, PriorityCommercial}
+	}
+	exclusivities := dims.Exclusivities
+	if len(exclusivities) == 0 {
+		exclusivities = []Exclusivity{ExclusivityPublic, ExclusivityPrivate}
+	}
+	eula := dims.EULA
+	if eula == "" {
+		eula = EULAStandard
+	}
+	concurrency := dims.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultPriceConcurrency
+	}
+
+	m := &PriceMatrix{
+		ContractID: contractID,
+		Cells:      make(map[PriceKey]PriceCell, len(modes)*len(priorities)*len(exclusivities)),
+	}
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+
+	for _, mode := range modes {
+		for _, priority := range priorities {
+			for _, exclusivity := range exclusivities {
+				key := PriceKey{mode, priority, exclusivity}
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					wg.Wait()
+					return m, ctx.Err()
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-sem }()
+					price, err := c.GetTaskPrice(ctx, &TaskPriceRequest{
+						ContractID:      contractID,
+						PointOfInterest: &poi,
+						ImagingMode:     key.ImagingMode,
+						Priority:        key.Priority,
+						Exclusivity:     key.Exclusivity,
+						SLA:             dims.SLA,
+						EULA:            eula,
+					})
+					mu.Lock()
+					m.Cells[key] = PriceCell{Price: price, Err: err}
+					mu.Unlock()
+				}()
+			}
+		}
+	}
+	wg.Wait()
+	return m, ctx.Err()
+}
+
+// ----------------------------------------------------------------------------
+// Price Cache
+// ----------------------------------------------------------------------------
+
+// priceCache memoizes GetTaskPrice responses for a fixed TTL, or until the
+// quote expires if that is sooner.
+type priceCache struct {
+	*common.TTLCache[TaskPrice]
+	ttl time.Duration
+}
+
+func newPriceCache(ttl time.Duration) *priceCache {
+	return &priceCache{TTLCache: common.NewTTLCache[TaskPrice](), ttl: ttl}
+}
+
+func (pc *priceCache) get(key string) (*TaskPrice, bool) {
+	p, ok := pc.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return &p, true
+}
+
+func (pc *priceCache) put(key string, price *TaskPrice) {
+	ttl := pc.ttl
+	if price.ExpiresAt != nil {
+		ttl = min(ttl, price.ExpiresAt.Sub(pc.Now()))
+	}
+	pc.Put(key, *price, ttl)
+}
+
+func (pc *priceCache) clear() {
+	pc.Clear()
+}
+
+// priceCacheKey returns a key covering every parameter of req.
+func priceCacheKey(req *TaskPriceRequest) (string, error) {
+	aoi, err := aoiJSON(req.AreaOfInterest)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{
+		req.ContractID,
+		poiString(req.PointOfInterest),
+		aoi,
+		string(req.ImagingMode),
+		string(req.Exclusivity),
+		string(req.Priority),
+		req.SLA,
+		string(req.EULA),
+	}, "\x00"), nil
+}
+
+// poiString formats p exactly, or returns "" if it is nil, for comparing
+// price requests.
+func poiString(p *Point) string {
+	if p == nil {
+		return ""
+	}
+	return strconv.FormatFloat(p.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(p.Lon, 'f', -1, 64)
+}
+
+// aoiJSON encodes g, or returns "" if it is nil, for comparing price
+// requests.
+func aoiJSON(g *geojson.Geometry) (string, error) {
+	if g == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(g)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// InvalidatePriceCache discards all cached price quotes. It is a no-op when
+// the client was created without WithPriceCache.
+func (c *Client) InvalidatePriceCache() {
+	if c.prices != nil {
+		c.prices.clear()
+	}
+}
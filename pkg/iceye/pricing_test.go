@@ -0,0 +1,185 @@
+package iceye_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+)
+
+// priceHandler quotes 100 per mode letter, doubled for PRIVATE, and fails
+// SCAN/COMMERCIAL quotes.
+func priceHandler(hits *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		q := r.URL.Query()
+		if q.Get("imagingMode") == "SCAN" && q.Get("priority") == "COMMERCIAL" {
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"ERR_PRICE","detail":"not available"}`))
+			return
+		}
+		amount := int64(100 * len(q.Get("imagingMode")))
+		if q.Get("exclusivity") == "PRIVATE" {
+			amount *= 2
+		}
+		json.NewEncoder(w).Encode(iceye.TaskPrice{Amount: amount, Currency: "EUR"})
+	}
+}
+
+func newPriceClient(t *testing.T, handler http.HandlerFunc, opts ...iceye.Option) *iceye.Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+	mux.HandleFunc("/tasking/v1/price", handler)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cli, err := iceye.NewClient(append([]iceye.Option{
+		iceye.WithBaseURL(srv.URL),
+		iceye.WithTokenURL(srv.URL + "/oauth2/token"),
+		iceye.WithHTTPClient(srv.Client()),
+		iceye.WithCredentials("test", "secret"),
+	}, opts...)...)
+	require.NoError(t, err)
+	return cli
+}
+
+func TestGetPriceMatrix(t *testing.T) {
+	var hits atomic.Int32
+	cli := newPriceClient(t, priceHandler(&hits))
+
+	m, err := cli.GetPriceMatrix(context.Background(), "C-1", iceye.Point{Lat: 60.1, Lon: 24.9}, iceye.PriceMatrixDims{SLA: "SLA_8H"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(12), hits.Load())
+	assert.Len(t, m.Cells, 12)
+
+	p, err := m.Get(iceye.ImagingModeSpotlight, iceye.PriorityCommercial, iceye.ExclusivityPrivate)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1800), p.Amount)
+
+	p, err = m.Get(iceye.ImagingModeScan, iceye.PriorityBackground, iceye.ExclusivityPublic)
+	require.NoError(t, err)
+	assert.Equal(t, int64(400), p.Amount)
+
+	_, err = m.Get(iceye.ImagingModeScan, iceye.PriorityCommercial, iceye.ExclusivityPublic)
+	var apiErr *iceye.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "ERR_PRICE", apiErr.Code)
+
+	assert.Equal(t, []iceye.PriceKey{
+		{ImagingMode: iceye.ImagingModeScan, Priority: iceye.PriorityCommercial, Exclusivity: iceye.ExclusivityPrivate},
+		{ImagingMode: iceye.ImagingModeScan, Priority: iceye.PriorityCommercial, Exclusivity: iceye.ExclusivityPublic},
+	}, m.Failed())
+
+	_, err = m.Get("UNKNOWN", iceye.PriorityCommercial, iceye.ExclusivityPublic)
+	assert.Error(t, err)
+}
+
+func TestGetPriceMatrix_ConcurrencyBound(t *testing.T) {
+	var (
+		mu             sync.Mutex
+		inFlight, peak int
+	)
+	cli := newPriceClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		json.NewEncoder(w).Encode(iceye.TaskPrice{Amount: 1, Currency: "EUR"})
+	})
+
+	m, err := cli.GetPriceMatrix(context.Background(), "C-1", iceye.Point{}, iceye.PriceMatrixDims{Concurrency: 2})
+	require.NoError(t, err)
+	assert.Len(t, m.Cells, 12)
+	assert.LessOrEqual(t, peak, 2)
+	assert.Equal(t, 2, peak, "expected queries to run in parallel")
+}
+
+func TestPriceCache(t *testing.T) {
+	var hits atomic.Int32
+	cli := newPriceClient(t, priceHandler(&hits), iceye.WithPriceCache(time.Minute))
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	iceye.SetPriceCacheClock(cli, func() time.Time { return now })
+
+	req := &iceye.TaskPriceRequest{
+		ContractID:      "C-1",
+		PointOfInterest: &iceye.Point{Lat: 60.1, Lon: 24.9},
+		ImagingMode:     "SPOTLIGHT",
+		Priority:        iceye.PriorityCommercial,
+		Exclusivity:     iceye.ExclusivityPublic,
+		EULA:            iceye.EULAStandard,
+	}
+	ctx := context.Background()
+
+	_, err := cli.GetTaskPrice(ctx, req)
+	require.NoError(t, err)
+	p, err := cli.GetTaskPrice(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(900), p.Amount)
+	assert.Equal(t, int32(1), hits.Load(), "second quote should be served from cache")
+
+	// A different parameter is a cache miss.
+	other := *req
+	other.Exclusivity = iceye.ExclusivityPrivate
+	_, err = cli.GetTaskPrice(ctx, &other)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), hits.Load())
+
+	// Entries expire after the TTL.
+	now = now.Add(59 * time.Second)
+	_, err = cli.GetTaskPrice(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), hits.Load())
+	now = now.Add(time.Second)
+	_, err = cli.GetTaskPrice(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), hits.Load())
+
+	// Invalidation drops everything.
+	cli.InvalidatePriceCache()
+	_, err = cli.GetTaskPrice(ctx, &other)
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), hits.Load())
+}
+
+func TestPriceCache_ErrorsNotCached(t *testing.T) {
+	var hits atomic.Int32
+	cli := newPriceClient(t, priceHandler(&hits), iceye.WithPriceCache(time.Minute))
+
+	req := &iceye.TaskPriceRequest{ContractID: "C-1", ImagingMode: "SCAN", Priority: iceye.PriorityCommercial}
+	for range 2 {
+		_, err := cli.GetTaskPrice(context.Background(), req)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestGetPriceMatrix_UsesCache(t *testing.T) {
+	var hits atomic.Int32
+	cli := newPriceClient(t, priceHandler(&hits), iceye.WithPriceCache(time.Minute))
+	dims := iceye.PriceMatrixDims{ImagingModes: []iceye.ImagingMode{iceye.ImagingModeStripmap}}
+
+	for range 2 {
+		m, err := cli.GetPriceMatrix(context.Background(), "C-1", iceye.Point{Lat: 1, Lon: 2}, dims)
+		require.NoError(t, err)
+		assert.Len(t, m.Cells, 4)
+	}
+	assert.Equal(t, int32(4), hits.Load())
+}
@@ -0,0 +1,215 @@
+package iceye
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Price Quotes
+// ----------------------------------------------------------------------------
+
+// Quote is a task price together with the parameters it was quoted for and
+// when it was obtained.
+type Quote struct {
+	TaskPrice
+	Request  TaskPriceRequest
+	QuotedAt time.Time
+}
+
+// Expired reports whether the quote's ExpiresAt has passed at now. A quote
+// the API gave no expiry for never expires by itself; see
+// StaleQuotePolicy.MaxAge.
+func (q *Quote) Expired(now time.Time) bool {
+	return q.ExpiresAt != nil && !now.Before(*q.ExpiresAt)
+}
+
+// GetTaskPriceQuote gets a price quotation for task parameters as a Quote.
+// Unlike GetTaskPrice it always asks the API, bypassing WithPriceCache, so
+// the quote is as fresh as its QuotedAt.
+//
+// GET /tasking/v1/price
+func (c *Client) GetTaskPriceQuote(ctx context.Context, req *TaskPriceRequest) (*Quote, error) {
+	price, err := c.fetchTaskPrice(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	quoted := *req
+	if p := req.PointOfInterest; p != nil {
+		// Keep the quoted point when the caller reuses req.
+		poi := *p
+		quoted.PointOfInterest = &poi
+	}
+	return &Quote{TaskPrice: *price, Request: quoted, QuotedAt: time.Now()}, nil
+}
+
+// PriceRequest returns the price request for the task parameters of r.
+func (r *CreateTaskRequest) PriceRequest() *TaskPriceRequest {
+	return &TaskPriceRequest{
+		ContractID:      r.ContractID,
+		PointOfInterest: r.PointOfInterest,
+		AreaOfInterest:  r.AreaOfInterest,
+		ImagingMode:     r.ImagingMode,
+		Exclusivity:     r.Exclusivity,
+		Priority:        r.Priority,
+		SLA:             r.SLA,
+		EULA:            r.EULA,
+	}
+}
+
+// StaleQuoteAction says what CreateTaskWithQuote does with a stale quote.
+type StaleQuoteAction int
+
+const (
+	// StaleQuoteFail refuses to create the task with a *StaleQuoteError.
+	StaleQuoteFail StaleQuoteAction = iota
+	// StaleQuoteRequote gets a fresh quote and creates the task if the new
+	// amount is within the policy's tolerance of the old one.
+	StaleQuoteRequote
+	// StaleQuoteProceed creates the task regardless.
+	StaleQuoteProceed
+)
+
+// StaleQuotePolicy controls how CreateTaskWithQuote treats a quote that has
+// expired. The zero value refuses stale quotes and treats quotes without an
+// expiry as always valid.
+type StaleQuotePolicy struct {
+	Action StaleQuoteAction
+
+	// TolerancePercent is, for StaleQuoteRequote, how much the fresh quote
+	// may exceed the stale one, in percent of the stale amount. A fresh
+	// quote in another currency is never within tolerance.
+	TolerancePercent float64
+
+	// MaxAge, if positive, makes a quote without ExpiresAt stale once it is
+	// older than MaxAge.
+	MaxAge time.Duration
+}
+
+// stale reports whether q is stale at now under the policy.
+func (p StaleQuotePolicy) stale(q *Quote, now time.Time) bool {
+	if q.ExpiresAt != nil {
+		return q.Expired(now)
+	}
+	return p.MaxAge > 0 && now.Sub(q.QuotedAt) >= p.MaxAge
+}
+
+// withinTolerance reports whether fresh is at most TolerancePercent above
+// stale. Amounts are in minor units, so the limit is rounded down.
+func (p StaleQuotePolicy) withinTolerance(stale, fresh TaskPrice) bool {
+	if !strings.EqualFold(stale.Currency, fresh.Currency) {
+		return false
+	}
+	limit := stale.Amount + int64(float64(stale.Amount)*p.TolerancePercent/100)
+	return fresh.Amount <= limit
+}
+
+// StaleQuoteError is returned by CreateTaskWithQuote when the quote has
+// expired and the policy does not allow creating the task. Requote is the
+// fresh quote under StaleQuoteRequote, and nil otherwise.
+type StaleQuoteError struct {
+	Quote   *Quote
+	Requote *Quote
+}
+
+func (e *StaleQuoteError) Error() string {
+	if e.Requote != nil {
+		return fmt.Sprintf("iceye: price quote is stale and the new quote %d %s is outside tolerance of %d %s",
+			e.Requote.Amount, e.Requote.Currency, e.Quote.Amount, e.Quote.Currency)
+	}
+	if e.Quote.ExpiresAt != nil {
+		return fmt.Sprintf("iceye: price quote expired at %s", e.Quote.ExpiresAt.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("iceye: price quote from %s is stale", e.Quote.QuotedAt.Format(time.RFC3339))
+}
+
+// QuoteMismatchError is returned by CreateTaskWithQuote when the task
+// parameters differ from those the quote was obtained for. Fields names the
+// differing parameters, e.g. "priority".
+type QuoteMismatchError struct {
+	Fields []string
+}
+
+func (e *QuoteMismatchError) Error() string {
+	return fmt.Sprintf("iceye: task does not match the price quote: %s differs", strings.Join(e.Fields, ", "))
+}
+
+// checkQuote returns a *QuoteMismatchError if req differs from the
+// price-relevant parameters the quote was obtained for.
+func checkQuote(quote *Quote, req *CreateTaskRequest) error {
+	quoted, task := quote.Request, req.PriceRequest()
+	quotedAOI, err := aoiJSON(quoted.AreaOfInterest)
+	if err != nil {
+		return err
+	}
+	taskAOI, err := aoiJSON(task.AreaOfInterest)
+	if err != nil {
+		return err
+	}
+	var fields []string
+	for _, f := range []struct {
+		name         string
+		quoted, task string
+	}{
+		{"contractID", quoted.ContractID, task.ContractID},
+		{"pointOfInterest", poiString(quoted.PointOfInterest), poiString(task.PointOfInterest)},
+		{"areaOfInterest", quotedAOI, taskAOI},
+		{"imagingMode", string(quoted.ImagingMode), string(task.ImagingMode)},
+		{"priority", string(quoted.Priority), string(task.Priority)},
+		{"exclusivity", string(quoted.Exclusivity), string(task.Exclusivity)},
+		{"sla", quoted.SLA, task.SLA},
+		{"eula", string(quoted.EULA), string(task.EULA)},
+	} {
+		if f.quoted != f.task {
+			fields = append(fields, f.name)
+		}
+	}
+	if len(fields) > 0 {
+		return &QuoteMismatchError{Fields: fields}
+	}
+	return nil
+}
+
+// CreateTaskWithQuote creates a task priced by quote. The task must have the
+// contract, point or area of interest, imaging mode, priority, exclusivity, SLA and EULA the quote was
+// obtained for, or a *QuoteMismatchError is returned. A stale quote is
+// handled per policy: refused with a *StaleQuoteError, re-quoted, or
+// ignored. It returns the task and the quote it was created under, which is
+// the fresh quote after a re-quote.
+func (c *Client) CreateTaskWithQuote(ctx context.Context, req *CreateTaskRequest, quote *Quote, policy StaleQuotePolicy, opts ...CallOption) (*Task, *Quote, error) {
+	if quote == nil {
+		return nil, nil, errors.New("iceye: quote is required")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := checkQuote(quote, req); err != nil {
+		return nil, nil, err
+	}
+
+	if policy.stale(quote, time.Now()) {
+		switch policy.Action {
+		case StaleQuoteRequote:
+			fresh, err := c.GetTaskPriceQuote(ctx, req.PriceRequest())
+			if err != nil {
+				return nil, nil, fmt.Errorf("iceye: re-quote: %w", err)
+			}
+			if !policy.withinTolerance(quote.TaskPrice, fresh.TaskPrice) {
+				return nil, nil, &StaleQuoteError{Quote: quote, Requote: fresh}
+			}
+			quote = fresh
+		case StaleQuoteProceed:
+		default:
+			return nil, nil, &StaleQuoteError{Quote: quote}
+		}
+	}
+
+	task, err := c.CreateTask(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return task, quote, nil
+}
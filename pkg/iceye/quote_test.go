@@ -0,0 +1,228 @@
+package iceye_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+)
+
+// quoteClient serves price quotes from *price and counts quotes and created
+// tasks.
+func quoteClient(t *testing.T, price *iceye.TaskPrice, quotes, creates *atomic.Int32) *iceye.Client {
+	t.Helper()
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("GET /tasking/v1/price", func(w http.ResponseWriter, r *http.Request) {
+			quotes.Add(1)
+			json.NewEncoder(w).Encode(price)
+		})
+		mux.HandleFunc("POST /tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			creates.Add(1)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(iceye.Task{ID: "task-1", Status: iceye.TaskStatusReceived})
+		})
+	})
+	return cli
+}
+
+func quoteTaskRequest() *iceye.CreateTaskRequest {
+	start := time.Now().Add(24 * time.Hour)
+	return &iceye.CreateTaskRequest{
+		ContractID:        "C-1",
+		PointOfInterest:   &iceye.Point{Lat: 60.17, Lon: 24.94},
+		AcquisitionWindow: iceye.TimeWindow{Start: start, End: start.Add(48 * time.Hour)},
+		ImagingMode:       iceye.ImagingModeSpotlight,
+		Priority:          iceye.PriorityCommercial,
+	}
+}
+
+func TestGetTaskPriceQuote(t *testing.T) {
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	var quotes, creates atomic.Int32
+	cli := quoteClient(t, &iceye.TaskPrice{Amount: 1000, Currency: "EUR", ExpiresAt: &expires, QuoteID: "Q-1"}, &quotes, &creates)
+
+	req := quoteTaskRequest().PriceRequest()
+	before := time.Now()
+	quote, err := cli.GetTaskPriceQuote(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), quote.Amount)
+	assert.Equal(t, "Q-1", quote.QuoteID)
+	require.NotNil(t, quote.ExpiresAt)
+	assert.True(t, quote.ExpiresAt.Equal(expires))
+	assert.Equal(t, *req, quote.Request)
+	assert.False(t, quote.QuotedAt.Before(before))
+
+	assert.False(t, quote.Expired(expires.Add(-time.Second)))
+	assert.True(t, quote.Expired(expires))
+	assert.False(t, (&iceye.Quote{}).Expired(time.Now()), "a quote without expiry does not expire")
+}
+
+func TestCreateTaskWithQuote(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	quote := func(amount int64, expires *time.Time, quotedAt time.Time) *iceye.Quote {
+		return &iceye.Quote{
+			TaskPrice: iceye.TaskPrice{Amount: amount, Currency: "EUR", ExpiresAt: expires},
+			Request:   *quoteTaskRequest().PriceRequest(),
+			QuotedAt:  quotedAt,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		quote      *iceye.Quote
+		policy     iceye.StaleQuotePolicy
+		fresh      iceye.TaskPrice
+		wantStale  bool
+		wantQuotes int32
+		wantAmount int64
+	}{
+		{name: "valid quote", quote: quote(1000, &future, time.Now()), wantAmount: 1000},
+		{name: "no expiry", quote: quote(1000, nil, time.Now().Add(-24*time.Hour)), wantAmount: 1000},
+		{name: "expired, fail", quote: quote(1000, &past, time.Now()), wantStale: true},
+		{
+			name: "no expiry past max age, fail", quote: quote(1000, nil, time.Now().Add(-2*time.Hour)),
+			policy: iceye.StaleQuotePolicy{MaxAge: time.Hour}, wantStale: true,
+		},
+		{
+			name: "expired, proceed", quote: quote(1000, &past, time.Now()),
+			policy: iceye.StaleQuotePolicy{Action: iceye.StaleQuoteProceed}, wantAmount: 1000,
+		},
+		{
+			name: "expired, requote within tolerance", quote: quote(1000, &past, time.Now()),
+			policy: iceye.StaleQuotePolicy{Action: iceye.StaleQuoteRequote, TolerancePercent: 5},
+			fresh:  iceye.TaskPrice{Amount: 1050, Currency: "EUR"}, wantQuotes: 1, wantAmount: 1050,
+		},
+		{
+			name: "expired, requote cheaper", quote: quote(1000, &past, time.Now()),
+			policy: iceye.StaleQuotePolicy{Action: iceye.StaleQuoteRequote},
+			fresh:  iceye.TaskPrice{Amount: 900, Currency: "EUR"}, wantQuotes: 1, wantAmount: 900,
+		},
+		{
+			name: "expired, requote over tolerance", quote: quote(1000, &past, time.Now()),
+			policy: iceye.StaleQuotePolicy{Action: iceye.StaleQuoteRequote, TolerancePercent: 5},
+			fresh:  iceye.TaskPrice{Amount: 1051, Currency: "EUR"}, wantQuotes: 1, wantStale: true,
+		},
+		{
+			name: "expired, requote rounds the limit down", quote: quote(999, &past, time.Now()),
+			policy: iceye.StaleQuotePolicy{Action: iceye.StaleQuoteRequote, TolerancePercent: 10},
+			fresh:  iceye.TaskPrice{Amount: 1099, Currency: "EUR"}, wantQuotes: 1, wantStale: true,
+		},
+		{
+			name: "expired, requote in another currency", quote: quote(1000, &past, time.Now()),
+			policy: iceye.StaleQuotePolicy{Action: iceye.StaleQuoteRequote, TolerancePercent: 50},
+			fresh:  iceye.TaskPrice{Amount: 1000, Currency: "USD"}, wantQuotes: 1, wantStale: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var quotes, creates atomic.Int32
+			cli := quoteClient(t, &tt.fresh, &quotes, &creates)
+
+			task, used, err := cli.CreateTaskWithQuote(context.Background(), quoteTaskRequest(), tt.quote, tt.policy)
+			assert.Equal(t, tt.wantQuotes, quotes.Load(), "quotes requested")
+			if tt.wantStale {
+				var stale *iceye.StaleQuoteError
+				require.ErrorAs(t, err, &stale)
+				assert.Same(t, tt.quote, stale.Quote)
+				if tt.wantQuotes > 0 {
+					require.NotNil(t, stale.Requote)
+					assert.Equal(t, tt.fresh.Amount, stale.Requote.Amount)
+				}
+				assert.Zero(t, creates.Load(), "task must not be created")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "task-1", task.ID)
+			assert.Equal(t, tt.wantAmount, used.Amount)
+			assert.Equal(t, int32(1), creates.Load())
+		})
+	}
+}
+
+func TestCreateTaskWithQuote_Mismatch(t *testing.T) {
+	var quotes, creates atomic.Int32
+	cli := quoteClient(t, &iceye.TaskPrice{Amount: 1000, Currency: "EUR"}, &quotes, &creates)
+
+	quote := &iceye.Quote{
+		TaskPrice: iceye.TaskPrice{Amount: 1000, Currency: "EUR"},
+		Request:   *quoteTaskRequest().PriceRequest(),
+		QuotedAt:  time.Now(),
+	}
+	req := quoteTaskRequest()
+	req.ContractID = "C-2"
+	req.Priority = iceye.PriorityBackground
+
+	_, _, err := cli.CreateTaskWithQuote(context.Background(), req, quote,
+		iceye.StaleQuotePolicy{Action: iceye.StaleQuoteProceed})
+	var mismatch *iceye.QuoteMismatchError
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, []string{"contractID", "priority"}, mismatch.Fields)
+	assert.Zero(t, creates.Load())
+
+	req = quoteTaskRequest()
+	req.ImagingMode = iceye.ImagingModeStripmap
+	_, _, err = cli.CreateTaskWithQuote(context.Background(), req, quote, iceye.StaleQuotePolicy{})
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, []string{"imagingMode"}, mismatch.Fields)
+
+	req = quoteTaskRequest()
+	req.PointOfInterest.Lon = 25.5
+	_, _, err = cli.CreateTaskWithQuote(context.Background(), req, quote, iceye.StaleQuotePolicy{})
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, []string{"pointOfInterest"}, mismatch.Fields)
+
+	aoiQuote := *quote
+	aoiQuote.Request.AreaOfInterest = geojson.NewGeometry(orb.Polygon{{{24, 60}, {25, 60}, {25, 61}, {24, 60}}})
+	aoiQuote.Request.ImagingMode = iceye.ImagingModeStripmap
+	req = quoteTaskRequest()
+	req.ImagingMode = iceye.ImagingModeStripmap
+	req.PointOfInterest = nil
+	req.AreaOfInterest = geojson.NewGeometry(orb.Polygon{{{24, 60}, {26, 60}, {26, 61}, {24, 60}}})
+	_, _, err = cli.CreateTaskWithQuote(context.Background(), req, &aoiQuote, iceye.StaleQuotePolicy{})
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, []string{"pointOfInterest", "areaOfInterest"}, mismatch.Fields)
+
+	_, _, err = cli.CreateTaskWithQuote(context.Background(), quoteTaskRequest(), nil, iceye.StaleQuotePolicy{})
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &mismatch))
+	assert.Zero(t, creates.Load())
+	assert.Zero(t, quotes.Load())
+}
+
+func TestPriceCache_QuoteExpiry(t *testing.T) {
+	var hits atomic.Int32
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	expires := now.Add(time.Minute)
+	cli := newPriceClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		json.NewEncoder(w).Encode(iceye.TaskPrice{Amount: 1000, Currency: "EUR", ExpiresAt: &expires})
+	}, iceye.WithPriceCache(time.Hour))
+	iceye.SetPriceCacheClock(cli, func() time.Time { return now })
+
+	req := quoteTaskRequest().PriceRequest()
+	for range 2 {
+		_, err := cli.GetTaskPrice(context.Background(), req)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), hits.Load())
+
+	// Past the quote's expiry the cached price is dropped, though the TTL
+	// has not passed.
+	now = now.Add(2 * time.Minute)
+	_, err := cli.GetTaskPrice(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), hits.Load())
+}
@@ -0,0 +1,76 @@
+package iceye
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// slaPendingStatuses are the statuses of tasks whose SLA can still be
+// breached: not yet acquired, or acquired (FULFILLED) but not delivered.
+var slaPendingStatuses = []TaskStatus{TaskStatusReceived, TaskStatusActive, TaskStatusFulfilled}
+
+// Duration returns the delivery time the SLA guarantees, and false for an
+// SLA unknown to this package.
+func (s SLA) Duration() (time.Duration, bool) {
+	switch s {
+	case SLA8Hours:
+		return 8 * time.Hour, true
+	case SLA3Hours:
+		return 3 * time.Hour, true
+	}
+	return 0, false
+}
+
+// SLADeadline returns when the task's products are due. ICEYE counts the
+// SLA from the end of the acquisition window, so the deadline is the window
+// end plus the SLA duration. It returns false when the task has no SLA, an
+// SLA unknown to this package, or no acquisition window end.
+func (t *Task) SLADeadline() (time.Time, bool) {
+	d, ok := SLA(t.SLA).Duration()
+	if !ok || t.AcquisitionWindow.End.IsZero() {
+		return time.Time{}, false
+	}
+	return t.AcquisitionWindow.End.Add(d), true
+}
+
+// SLARemaining returns the time left at now until the SLA deadline,
+// negative once the deadline has passed, and zero when the task has no
+// deadline (see SLADeadline).
+func (t *Task) SLARemaining(now time.Time) time.Duration {
+	deadline, ok := t.SLADeadline()
+	if !ok {
+		return 0
+	}
+	return deadline.Sub(now)
+}
+
+// ListTasksAtRisk returns the tasks of a contract that are not yet done and
+// whose SLA deadline is within the given horizon from now, including tasks
+// already past their deadline, ordered by deadline. Tasks are filtered by
+// contract and status server-side; the API cannot filter on the deadline,
+// so that is done client-side. Tasks without a deadline are left out.
+func (c *Client) ListTasksAtRisk(ctx context.Context, contractID string, within time.Duration) ([]Task, error) {
+	horizon := time.Now().Add(within)
+	var atRisk []Task
+	for page, err := range c.ListTasks(ctx, 0, &ListTasksOptions{ContractID: contractID, Status: slaPendingStatuses}) {
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range page {
+			// Statuses are also checked here in case the filter is ignored.
+			if !slices.Contains(slaPendingStatuses, t.Status) {
+				continue
+			}
+			if deadline, ok := t.SLADeadline(); ok && !deadline.After(horizon) {
+				atRisk = append(atRisk, t)
+			}
+		}
+	}
+	slices.SortStableFunc(atRisk, func(a, b Task) int {
+		da, _ := a.SLADeadline()
+		db, _ := b.SLADeadline()
+		return da.Compare(db)
+	})
+	return atRisk, nil
+}
@@ -0,0 +1,92 @@
+package iceye_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTask_SLADeadline(t *testing.T) {
+	end := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	window := iceye.TimeWindow{Start: end.Add(-24 * time.Hour), End: end}
+
+	tests := []struct {
+		name   string
+		task   iceye.Task
+		want   time.Time
+		wantOK bool
+	}{
+		{"8 hours", iceye.Task{SLA: string(iceye.SLA8Hours), AcquisitionWindow: window}, end.Add(8 * time.Hour), true},
+		{"3 hours", iceye.Task{SLA: string(iceye.SLA3Hours), AcquisitionWindow: window}, end.Add(3 * time.Hour), true},
+		{"no SLA", iceye.Task{AcquisitionWindow: window}, time.Time{}, false},
+		{"unknown SLA", iceye.Task{SLA: "SLA_1H", AcquisitionWindow: window}, time.Time{}, false},
+		{"no window end", iceye.Task{SLA: string(iceye.SLA8Hours)}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.task.SLADeadline()
+			assert.Equal(t, tt.wantOK, ok)
+			assert.True(t, tt.want.Equal(got), "deadline = %s, want %s", got, tt.want)
+
+			now := end.Add(time.Hour)
+			if tt.wantOK {
+				assert.Equal(t, tt.want.Sub(now), tt.task.SLARemaining(now))
+			} else {
+				assert.Zero(t, tt.task.SLARemaining(now))
+			}
+		})
+	}
+
+	// Past the deadline the remaining time is negative.
+	task := iceye.Task{SLA: string(iceye.SLA3Hours), AcquisitionWindow: window}
+	assert.Equal(t, -time.Hour, task.SLARemaining(end.Add(4*time.Hour)))
+}
+
+func TestListTasksAtRisk(t *testing.T) {
+	now := time.Now().UTC()
+	task := func(id string, status iceye.TaskStatus, sla iceye.SLA, windowEnd time.Time) iceye.Task {
+		return iceye.Task{
+			ID: id, ContractID: "C-1", Status: status, SLA: string(sla),
+			AcquisitionWindow: iceye.TimeWindow{Start: windowEnd.Add(-48 * time.Hour), End: windowEnd},
+		}
+	}
+	tasks := []iceye.Task{
+		task("due-in-2h", iceye.TaskStatusActive, iceye.SLA3Hours, now.Add(-time.Hour)),
+		task("due-in-7h", iceye.TaskStatusFulfilled, iceye.SLA8Hours, now.Add(-time.Hour)),
+		task("breached", iceye.TaskStatusReceived, iceye.SLA3Hours, now.Add(-5*time.Hour)),
+		task("due-in-2d", iceye.TaskStatusActive, iceye.SLA8Hours, now.Add(40*time.Hour)),
+		task("no-sla", iceye.TaskStatusActive, "", now.Add(-time.Hour)),
+		task("done", iceye.TaskStatusDone, iceye.SLA3Hours, now.Add(-time.Hour)),
+	}
+
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			assert.Equal(t, "C-1", q.Get("contractID"))
+			assert.ElementsMatch(t, []string{"RECEIVED", "ACTIVE", "FULFILLED"}, q["status"])
+			// The server ignores the status filter; the client checks it too.
+			json.NewEncoder(w).Encode(iceye.TasksResponse{Data: tasks})
+		})
+	})
+
+	got, err := cli.ListTasksAtRisk(context.Background(), "C-1", 8*time.Hour)
+	require.NoError(t, err)
+	ids := make([]string, len(got))
+	for i, task := range got {
+		ids[i] = task.ID
+	}
+	assert.Equal(t, []string{"breached", "due-in-2h", "due-in-7h"}, ids)
+
+	got, err = cli.ListTasksAtRisk(context.Background(), "C-1", time.Hour)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "breached", got[0].ID)
+}
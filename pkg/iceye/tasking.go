@@ -2,13 +2,19 @@ package iceye
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"iter"
 	"net/http"
 	"net/url"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
 
@@ -25,6 +31,31 @@ const (
 	ImagingModeScan      ImagingMode = "SCAN"
 )
 
+// IsKnown reports whether m is an imaging mode known to this package. Modes
+// decoded from responses are kept as sent, so m may be a newer mode.
+func (m ImagingMode) IsKnown() bool {
+	switch m {
+	case ImagingModeSpotlight, ImagingModeStripmap, ImagingModeScan:
+		return true
+	}
+	return false
+}
+
+// Validate returns an error if m is not a known imaging mode, e.g. a typo.
+func (m ImagingMode) Validate() error {
+	if !m.IsKnown() {
+		return fmt.Errorf("iceye: unknown imaging mode %q", m)
+	}
+	return nil
+}
+
+// ParseImagingMode converts s, in any case, to an ImagingMode, returning an
+// error if it is not a known mode.
+func ParseImagingMode(s string) (ImagingMode, error) {
+	m := ImagingMode(strings.ToUpper(strings.TrimSpace(s)))
+	return m, m.Validate()
+}
+
 // TaskStatus represents the lifecycle state of a task.
 type TaskStatus string
 
@@ -38,6 +69,24 @@ const (
 	TaskStatusFailed    TaskStatus = "FAILED"
 )
 
+// IsKnown reports whether s is a task status known to the API.
+func (s TaskStatus) IsKnown() bool {
+	switch s {
+	case TaskStatusReceived, TaskStatusActive, TaskStatusRejected, TaskStatusFulfilled,
+		TaskStatusDone, TaskStatusCanceled, TaskStatusFailed:
+		return true
+	}
+	return false
+}
+
+// SortOrder represents the direction of a sorted listing.
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
 // Priority represents task scheduling priority.
 type Priority string
 
@@ -80,6 +129,23 @@ const (
 	LookSideRight LookSide = "RIGHT"
 )
 
+// IsKnown reports whether l is a look side known to this package.
+func (l LookSide) IsKnown() bool {
+	switch l {
+	case LookSideAny, LookSideLeft, LookSideRight:
+		return true
+	}
+	return false
+}
+
+// Validate returns an error if l is not a known look side.
+func (l LookSide) Validate() error {
+	if !l.IsKnown() {
+		return fmt.Errorf("iceye: unknown look side %q", l)
+	}
+	return nil
+}
+
 // PassDirection represents orbital pass direction.
 type PassDirection string
 
@@ -89,6 +155,23 @@ const (
 	PassDirectionDescending PassDirection = "DESCENDING"
 )
 
+// IsKnown reports whether d is a pass direction known to this package.
+func (d PassDirection) IsKnown() bool {
+	switch d {
+	case PassDirectionAny, PassDirectionAscending, PassDirectionDescending:
+		return true
+	}
+	return false
+}
+
+// Validate returns an error if d is not a known pass direction.
+func (d PassDirection) Validate() error {
+	if !d.IsKnown() {
+		return fmt.Errorf("iceye: unknown pass direction %q", d)
+	}
+	return nil
+}
+
 // AdditionalProductType represents additional SAR product types beyond standard.
 type AdditionalProductType string
 
@@ -101,9 +184,11 @@ const (
 type Task struct {
 	ID                     string                  `json:"id"`
 	ContractID             string                  `json:"contractID"`
-	PointOfInterest        Point                   `json:"pointOfInterest"`
+	PointOfInterest        *Point                  `json:"pointOfInterest,omitempty"`
+	AreaOfInterest         *geojson.Geometry       `json:"areaOfInterest,omitempty"`
+	Footprint              *geojson.Geometry       `json:"footprint,omitempty"`
 	AcquisitionWindow      TimeWindow              `json:"acquisitionWindow"`
-	ImagingMode            string                  `json:"imagingMode"`
+	ImagingMode            ImagingMode             `json:"imagingMode"`
 	Status                 TaskStatus              `json:"status"`
 	Exclusivity            Exclusivity             `json:"exclusivity,omitempty"`
 	Priority               Priority                `json:"priority,omitempty"`
@@ -116,15 +201,23 @@ type Task struct {
 	DeliveryLocations      []DeliveryLocation      `json:"deliveryLocations,omitempty"`
 	CreatedAt              time.Time               `json:"createdAt"`
 	UpdatedAt              time.Time               `json:"updatedAt"`
+
+	// IdempotencyKey is the idempotency key CreateTask sent, if any.
+	IdempotencyKey string `json:"-"`
 }
 
 // CreateTaskRequest represents parameters for creating a new task.
 type CreateTaskRequest struct {
 	// Required fields
-	ContractID        string     `json:"contractID"`
-	PointOfInterest   Point      `json:"pointOfInterest"`
-	AcquisitionWindow TimeWindow `json:"acquisitionWindow"`
-	ImagingMode       string     `json:"imagingMode"`
+	ContractID        string      `json:"contractID"`
+	AcquisitionWindow TimeWindow  `json:"acquisitionWindow"`
+	ImagingMode       ImagingMode `json:"imagingMode"`
+
+	// Exactly one of PointOfInterest or AreaOfInterest must be set.
+	// AreaOfInterest (Polygon or MultiPolygon) is only accepted for
+	// STRIPMAP and SCAN; SPOTLIGHT requires a point.
+	PointOfInterest *Point            `json:"pointOfInterest,omitempty"`
+	AreaOfInterest  *geojson.Geometry `json:"areaOfInterest,omitempty"`
 
 	// Optional fields
 	Exclusivity            Exclusivity             `json:"exclusivity,omitempty"`
@@ -140,29 +233,56 @@ type CreateTaskRequest struct {
 
 // TaskScene represents imaging parameters for a scheduled task.
 type TaskScene struct {
-	ImagingTime   TimeWindow    `json:"imagingTime"`
-	Duration      int           `json:"duration"` // seconds
-	LookSide      LookSide      `json:"lookSide"`
-	PassDirection PassDirection `json:"passDirection"`
+	ImagingTime   TimeWindow        `json:"imagingTime"`
+	Duration      int               `json:"duration"` // seconds
+	LookSide      LookSide          `json:"lookSide"`
+	PassDirection PassDirection     `json:"passDirection"`
+	Footprint     *geojson.Geometry `json:"footprint,omitempty"`
 }
 
 // TaskProduct represents a SAR data product from a completed task.
 type TaskProduct struct {
-	Type   string           `json:"type"`
-	Assets map[string]Asset `json:"assets"`
+	Type     string            `json:"type"`
+	Assets   map[string]Asset  `json:"assets"`
+	Geometry *geojson.Geometry `json:"geometry,omitempty"` // product footprint
 }
 
-// TaskPrice represents a price quotation for a task.
+// TaskPrice represents a price quotation for a task. ExpiresAt and QuoteID
+// are set only when the API returns them.
 type TaskPrice struct {
-	Amount   int64  `json:"amount"`   // Minor currency unit (e.g., cents)
-	Currency string `json:"currency"` // ISO 4217 code
+	Amount    int64      `json:"amount"`   // Minor currency unit (e.g., cents)
+	Currency  string     `json:"currency"` // ISO 4217 code
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	QuoteID   string     `json:"quoteID,omitempty"`
 }
 
 // ListTasksOptions for filtering task lists.
+// Filters are applied server-side; Status values are sent as repeated
+// status query parameters.
 type ListTasksOptions struct {
 	ContractID    string
+	Status        []TaskStatus
 	CreatedAfter  *time.Time
 	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	SortBy        string    // e.g. "createdAt", "updatedAt"
+	SortOrder     SortOrder // Defaults to the API's ordering when empty
+}
+
+// validate rejects statuses and sort orders unknown to the API.
+func (o *ListTasksOptions) validate() error {
+	for _, s := range o.Status {
+		if !s.IsKnown() {
+			return fmt.Errorf("iceye: unknown task status %q", s)
+		}
+	}
+	switch o.SortOrder {
+	case "", SortOrderAsc, SortOrderDesc:
+	default:
+		return fmt.Errorf("iceye: unknown sort order %q", o.SortOrder)
+	}
+	return nil
 }
 
 // TasksResponse is the paginated response for listing tasks.
@@ -174,14 +294,55 @@ type TasksResponse struct {
 // TaskPriceRequest contains all parameters for getting a task price quote.
 type TaskPriceRequest struct {
 	ContractID      string
-	PointOfInterest Point
-	ImagingMode     string
+	PointOfInterest *Point
+	AreaOfInterest  *geojson.Geometry // Sent instead of PointOfInterest when set
+	ImagingMode     ImagingMode
 	Exclusivity     Exclusivity
 	Priority        Priority
 	SLA             string
 	EULA            EULA
 }
 
+// Validate checks that the imaging mode, and the look side and pass direction
+// if set, are known values, and the area-of-interest rules for the request:
+// exactly one of PointOfInterest or AreaOfInterest must be set, the area must
+// be a Polygon or MultiPolygon, and SPOTLIGHT tasks require a point.
+func (r *CreateTaskRequest) Validate() error {
+	if err := r.ImagingMode.Validate(); err != nil {
+		return err
+	}
+	if r.LookSide != "" {
+		if err := r.LookSide.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.PassDirection != "" {
+		if err := r.PassDirection.Validate(); err != nil {
+			return err
+		}
+	}
+
+	hasPoint := r.PointOfInterest != nil
+	hasArea := r.AreaOfInterest != nil
+
+	switch {
+	case hasPoint && hasArea:
+		return errors.New("iceye: pointOfInterest and areaOfInterest are mutually exclusive")
+	case !hasPoint && !hasArea:
+		return errors.New("iceye: one of pointOfInterest or areaOfInterest is required")
+	case hasArea:
+		if r.ImagingMode == ImagingModeSpotlight {
+			return fmt.Errorf("iceye: imaging mode %s requires a pointOfInterest", r.ImagingMode)
+		}
+		switch r.AreaOfInterest.Geometry().(type) {
+		case orb.Polygon, orb.MultiPolygon:
+		default:
+			return fmt.Errorf("iceye: areaOfInterest must be a Polygon or MultiPolygon, got %s", r.AreaOfInterest.Type)
+		}
+	}
+	return nil
+}
+
 // ----------------------------------------------------------------------------
 // Tasking API Methods
 // Endpoints: https://docs.iceye.com/constellation/api/1.0/
@@ -190,14 +351,22 @@ type TaskPriceRequest struct {
 const taskingBasePath = "/tasking/v1"
 
 // CreateTask creates a new satellite imaging task.
+// The request is validated with CreateTaskRequest.Validate before it is sent.
+// Send it with an idempotency key (WithIdempotencyKey or WithAutoIdempotency)
+// to have it retried safely after network errors.
 //
 // POST /tasking/v1/tasks
-func (c *Client) CreateTask(ctx context.Context, req *CreateTaskRequest) (*Task, error) {
+func (c *Client) CreateTask(ctx context.Context, req *CreateTaskRequest, opts ...CallOption) (*Task, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
 	var resp Task
 	u := &url.URL{Path: path.Join(taskingBasePath, "tasks")}
-	if err := c.do(ctx, http.MethodPost, u.String(), req, &resp); err != nil {
+	key := c.idempotencyKey(opts)
+	if err := c.doIdempotent(ctx, http.MethodPost, u.String(), req, &resp, key); err != nil {
 		return nil, err
 	}
+	resp.IdempotencyKey = key
 	return &resp, nil
 }
 
@@ -218,6 +387,13 @@ func (c *Client) GetTask(ctx context.Context, taskID string) (*Task, error) {
 //
 // GET /tasking/v1/tasks
 func (c *Client) ListTasks(ctx context.Context, pageSize int, opts *ListTasksOptions) iter.Seq2[[]Task, error] {
+	if opts != nil {
+		if err := opts.validate(); err != nil {
+			return func(yield func([]Task, error) bool) {
+				yield(nil, err)
+			}
+		}
+	}
 	return common.Paginate(func(cur *string) ([]Task, *string, error) {
 		u := &url.URL{Path: path.Join(taskingBasePath, "tasks")}
 		q := u.Query()
@@ -235,6 +411,21 @@ func (c *Client) ListTasks(ctx context.Context, pageSize int, opts *ListTasksOpt
 			if opts.CreatedBefore != nil {
 				q.Set("createdBefore", opts.CreatedBefore.Format(time.RFC3339))
 			}
+			if opts.UpdatedAfter != nil {
+				q.Set("updatedAfter", opts.UpdatedAfter.Format(time.RFC3339))
+			}
+			if opts.UpdatedBefore != nil {
+				q.Set("updatedBefore", opts.UpdatedBefore.Format(time.RFC3339))
+			}
+			for _, s := range opts.Status {
+				q.Add("status", string(s))
+			}
+			if opts.SortBy != "" {
+				q.Set("sortBy", opts.SortBy)
+			}
+			if opts.SortOrder != "" {
+				q.Set("sortOrder", string(opts.SortOrder))
+			}
 		}
 		if cur != nil && *cur != "" {
 			q.Set("cursor", *cur)
@@ -247,6 +438,17 @@ func (c *Client) ListTasks(ctx context.Context, pageSize int, opts *ListTasksOpt
 	})
 }
 
+// ListActiveTasks lists the ACTIVE tasks of a contract, filtered server-side.
+// Returns an iterator that yields pages of tasks.
+//
+// GET /tasking/v1/tasks?contractID={contractID}&status=ACTIVE
+func (c *Client) ListActiveTasks(ctx context.Context, contractID string) iter.Seq2[[]Task, error] {
+	return c.ListTasks(ctx, 0, &ListTasksOptions{
+		ContractID: contractID,
+		Status:     []TaskStatus{TaskStatusActive},
+	})
+}
+
 // CancelTask cancels an active task by setting its status to CANCELED.
 //
 // PATCH /tasking/v1/tasks/{taskID}
@@ -296,19 +498,52 @@ func (c *Client) GetTaskProduct(ctx context.Context, taskID string, productType
 	return &resp, nil
 }
 
-// GetTaskPrice gets a price quotation for task parameters.
-// All parameters are passed via query string.
+// GetTaskPrice gets a price quotation for task parameters. With
+// WithPriceCache, quotes are served from the cache until they expire.
+// All parameters are passed via query string; an AreaOfInterest is sent as
+// GeoJSON in place of the point coordinates.
 //
 // GET /tasking/v1/price
 func (c *Client) GetTaskPrice(ctx context.Context, req *TaskPriceRequest) (*TaskPrice, error) {
+	var cacheKey string
+	if c.prices != nil {
+		var err error
+		if cacheKey, err = priceCacheKey(req); err != nil {
+			return nil, fmt.Errorf("iceye: marshal areaOfInterest: %w", err)
+		}
+		if p, ok := c.prices.get(cacheKey); ok {
+			return p, nil
+		}
+	}
+
+	resp, err := c.fetchTaskPrice(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if c.prices != nil {
+		c.prices.put(cacheKey, resp)
+	}
+	return resp, nil
+}
+
+// fetchTaskPrice requests a price quotation from the API.
+func (c *Client) fetchTaskPrice(ctx context.Context, req *TaskPriceRequest) (*TaskPrice, error) {
 	u := &url.URL{Path: path.Join(taskingBasePath, "price")}
 	q := u.Query()
 
 	// Required parameters per API spec
 	q.Set("contractID", req.ContractID)
-	q.Set("pointOfInterest[lat]", strconv.FormatFloat(req.PointOfInterest.Lat, 'f', -1, 64))
-	q.Set("pointOfInterest[lon]", strconv.FormatFloat(req.PointOfInterest.Lon, 'f', -1, 64))
-	q.Set("imagingMode", req.ImagingMode)
+	if req.AreaOfInterest != nil {
+		aoi, err := json.Marshal(req.AreaOfInterest)
+		if err != nil {
+			return nil, fmt.Errorf("iceye: marshal areaOfInterest: %w", err)
+		}
+		q.Set("areaOfInterest", string(aoi))
+	} else if req.PointOfInterest != nil {
+		q.Set("pointOfInterest[lat]", strconv.FormatFloat(req.PointOfInterest.Lat, 'f', -1, 64))
+		q.Set("pointOfInterest[lon]", strconv.FormatFloat(req.PointOfInterest.Lon, 'f', -1, 64))
+	}
+	q.Set("imagingMode", string(req.ImagingMode))
 	q.Set("exclusivity", string(req.Exclusivity))
 	q.Set("priority", string(req.Priority))
 	q.Set("sla", req.SLA)
@@ -8,7 +8,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/paulmach/orb"
 	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye/iceyetest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,11 +22,13 @@ func TestListTasksPagination(t *testing.T) {
 			cursor := r.URL.Query().Get("cursor")
 			switch cursor {
 			case "":
+				assert.Equal(t, "limit=1", r.URL.RawQuery)
 				json.NewEncoder(w).Encode(map[string]any{
 					"data":   []iceye.Task{{ID: "t1"}},
 					"cursor": "next",
 				})
 			case "next":
+				assert.Equal(t, "cursor=next&limit=1", r.URL.RawQuery)
 				json.NewEncoder(w).Encode(map[string]any{
 					"data":   []iceye.Task{{ID: "t2"}},
 					"cursor": nil,
@@ -67,6 +71,90 @@ func TestListTasksWithFilters(t *testing.T) {
 	}
 }
 
+func TestListTasksServerSideFilters(t *testing.T) {
+	helsinki := time.FixedZone("EET", 2*60*60)
+	updatedAfter := time.Date(2025, 3, 1, 9, 30, 0, 0, helsinki)
+	updatedBefore := time.Date(2025, 3, 31, 18, 0, 0, 0, time.UTC)
+
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			// The + in the offset must be escaped, or servers decode it as a space.
+			assert.Contains(t, r.URL.RawQuery, "updatedAfter=2025-03-01T09%3A30%3A00%2B02%3A00")
+
+			q := r.URL.Query()
+			assert.Equal(t, []string{"ACTIVE", "RECEIVED"}, q["status"])
+			assert.Equal(t, "C-123", q.Get("contractID"))
+			assert.Equal(t, "updatedAt", q.Get("sortBy"))
+			assert.Equal(t, "desc", q.Get("sortOrder"))
+			assert.Equal(t, "25", q.Get("limit"))
+
+			got, err := time.Parse(time.RFC3339, q.Get("updatedAfter"))
+			require.NoError(t, err)
+			assert.True(t, got.Equal(updatedAfter), "updatedAfter round-trip: got %s", got)
+			got, err = time.Parse(time.RFC3339, q.Get("updatedBefore"))
+			require.NoError(t, err)
+			assert.True(t, got.Equal(updatedBefore), "updatedBefore round-trip: got %s", got)
+
+			json.NewEncoder(w).Encode(map[string]any{"data": []iceye.Task{{ID: "t1"}}})
+		})
+	})
+
+	opts := &iceye.ListTasksOptions{
+		ContractID:    "C-123",
+		Status:        []iceye.TaskStatus{iceye.TaskStatusActive, iceye.TaskStatusReceived},
+		UpdatedAfter:  &updatedAfter,
+		UpdatedBefore: &updatedBefore,
+		SortBy:        "updatedAt",
+		SortOrder:     iceye.SortOrderDesc,
+	}
+
+	var count int
+	for tasks, err := range cli.ListTasks(context.Background(), 25, opts) {
+		require.NoError(t, err)
+		count += len(tasks)
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestListTasksRejectsUnknownStatus(t *testing.T) {
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("request should not be sent")
+		})
+	})
+
+	for _, opts := range []*iceye.ListTasksOptions{
+		{Status: []iceye.TaskStatus{"active"}},
+		{SortOrder: "sideways"},
+	} {
+		var gotErr error
+		for _, err := range cli.ListTasks(context.Background(), 10, opts) {
+			gotErr = err
+		}
+		assert.Error(t, gotErr)
+	}
+}
+
+func TestListActiveTasks(t *testing.T) {
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "contractID=C-9&status=ACTIVE", r.URL.RawQuery)
+			json.NewEncoder(w).Encode(map[string]any{
+				"data": []iceye.Task{{ID: "t1", Status: iceye.TaskStatusActive}},
+			})
+		})
+	})
+
+	for tasks, err := range cli.ListActiveTasks(context.Background(), "C-9") {
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, iceye.TaskStatusActive, tasks[0].Status)
+	}
+}
+
 func TestCreateTask(t *testing.T) {
 	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
 		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
@@ -76,7 +164,7 @@ func TestCreateTask(t *testing.T) {
 			var req iceye.CreateTaskRequest
 			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
 			assert.Equal(t, "C-1", req.ContractID)
-			assert.Equal(t, "SPOTLIGHT", req.ImagingMode)
+			assert.Equal(t, iceye.ImagingModeSpotlight, req.ImagingMode)
 
 			json.NewEncoder(w).Encode(iceye.Task{
 				ID:          "T-123",
@@ -90,7 +178,7 @@ func TestCreateTask(t *testing.T) {
 	now := time.Now()
 	req := &iceye.CreateTaskRequest{
 		ContractID:        "C-1",
-		PointOfInterest:   iceye.Point{Lat: 60.1699, Lon: 24.9384},
+		PointOfInterest:   &iceye.Point{Lat: 60.1699, Lon: 24.9384},
 		AcquisitionWindow: iceye.TimeWindow{Start: now.Add(48 * time.Hour), End: now.Add(72 * time.Hour)},
 		ImagingMode:       "SPOTLIGHT",
 		Priority:          iceye.PriorityCommercial,
@@ -105,16 +193,11 @@ func TestCreateTask(t *testing.T) {
 }
 
 func TestGetTask(t *testing.T) {
-	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
-		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
-		mux.HandleFunc("/tasking/v1/tasks/T-123", func(w http.ResponseWriter, r *http.Request) {
-			assert.Equal(t, http.MethodGet, r.Method)
-			json.NewEncoder(w).Encode(iceye.Task{
-				ID:     "T-123",
-				Status: iceye.TaskStatusActive,
-			})
-		})
-	})
+	srv := iceyetest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddTask(iceye.Task{ID: "T-123", Status: iceye.TaskStatusActive})
+	cli, err := srv.NewClient()
+	require.NoError(t, err)
 
 	task, err := cli.GetTask(context.Background(), "T-123")
 
@@ -147,6 +230,23 @@ func TestCancelTask(t *testing.T) {
 	assert.Equal(t, iceye.TaskStatusCanceled, task.Status)
 }
 
+func TestCancelTask_Server(t *testing.T) {
+	srv := iceyetest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddTask(iceye.Task{ID: "T-1", Status: iceye.TaskStatusActive})
+	cli, err := srv.NewClient()
+	require.NoError(t, err)
+
+	task, err := cli.CancelTask(context.Background(), "T-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "T-1", task.ID)
+	assert.Equal(t, iceye.TaskStatusCanceled, task.Status)
+
+	stored, _ := srv.Task("T-1")
+	assert.Equal(t, iceye.TaskStatusCanceled, stored.Status)
+}
+
 func TestGetTaskScene(t *testing.T) {
 	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
 		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
@@ -191,7 +291,7 @@ func TestGetTaskPrice(t *testing.T) {
 
 	price, err := cli.GetTaskPrice(context.Background(), &iceye.TaskPriceRequest{
 		ContractID:      "C-1",
-		PointOfInterest: iceye.Point{Lat: 60.1699, Lon: 24.9384},
+		PointOfInterest: &iceye.Point{Lat: 60.1699, Lon: 24.9384},
 		ImagingMode:     "SPOTLIGHT",
 		Priority:        iceye.PriorityCommercial,
 		SLA:             "SLA_8H",
@@ -203,18 +303,204 @@ func TestGetTaskPrice(t *testing.T) {
 	assert.Equal(t, "USD", price.Currency)
 }
 
-func TestListTaskProducts(t *testing.T) {
+func TestCreateTaskWithAreaOfInterest(t *testing.T) {
 	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
 		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
-		mux.HandleFunc("/tasking/v1/tasks/T/products", func(w http.ResponseWriter, r *http.Request) {
-			assert.Equal(t, http.MethodGet, r.Method)
-			json.NewEncoder(w).Encode([]iceye.TaskProduct{
-				{Type: "GRD"},
-				{Type: "SLC"},
-			})
+		mux.HandleFunc("/tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			var raw map[string]json.RawMessage
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&raw))
+			assert.NotContains(t, raw, "pointOfInterest")
+			require.Contains(t, raw, "areaOfInterest")
+
+			var aoi iceye.Geometry
+			require.NoError(t, json.Unmarshal(raw["areaOfInterest"], &aoi))
+			assert.Equal(t, "Polygon", aoi.Type)
+
+			w.Write([]byte(`{
+				"id": "T-AOI",
+				"imagingMode": "SCAN",
+				"status": "RECEIVED",
+				"areaOfInterest": ` + string(raw["areaOfInterest"]) + `
+			}`))
+		})
+	})
+
+	now := time.Now()
+	task, err := cli.CreateTask(context.Background(), &iceye.CreateTaskRequest{
+		ContractID:        "C-1",
+		AreaOfInterest:    iceye.BBoxToPolygon(iceye.BoundingBox{24.8, 60.1, 25.1, 60.3}),
+		AcquisitionWindow: iceye.TimeWindow{Start: now.Add(48 * time.Hour), End: now.Add(72 * time.Hour)},
+		ImagingMode:       iceye.ImagingModeScan,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "T-AOI", task.ID)
+	require.NotNil(t, task.AreaOfInterest)
+	assert.Equal(t, "Polygon", task.AreaOfInterest.Type)
+}
+
+func TestCreateTaskAtNullIsland(t *testing.T) {
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			var raw map[string]json.RawMessage
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&raw))
+			assert.JSONEq(t, `{"lat": 0, "lon": 0}`, string(raw["pointOfInterest"]))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": "T-0", "status": "RECEIVED", "pointOfInterest": {"lat": 0, "lon": 0}}`))
+		})
+	})
+
+	now := time.Now()
+	task, err := cli.CreateTask(context.Background(), &iceye.CreateTaskRequest{
+		ContractID:        "C-1",
+		PointOfInterest:   &iceye.Point{},
+		AcquisitionWindow: iceye.TimeWindow{Start: now.Add(48 * time.Hour), End: now.Add(72 * time.Hour)},
+		ImagingMode:       iceye.ImagingModeSpotlight,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, &iceye.Point{}, task.PointOfInterest)
+}
+
+func TestCreateTaskValidation(t *testing.T) {
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/tasking/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("invalid request should not be sent")
+		})
+	})
+
+	poi := &iceye.Point{Lat: 60.1699, Lon: 24.9384}
+	aoi := iceye.BBoxToPolygon(iceye.BoundingBox{24.8, 60.1, 25.1, 60.3})
+
+	tests := []struct {
+		name string
+		req  iceye.CreateTaskRequest
+	}{
+		{"both point and area", iceye.CreateTaskRequest{ImagingMode: "SCAN", PointOfInterest: poi, AreaOfInterest: aoi}},
+		{"neither point nor area", iceye.CreateTaskRequest{ImagingMode: "SCAN"}},
+		{"spotlight with area", iceye.CreateTaskRequest{ImagingMode: "SPOTLIGHT", AreaOfInterest: aoi}},
+		{"non-polygon area", iceye.CreateTaskRequest{ImagingMode: "STRIPMAP", AreaOfInterest: iceye.GeoJSONPoint(24.9, 60.2)}},
+		{"misspelled mode", iceye.CreateTaskRequest{ImagingMode: "SPOTLITE", PointOfInterest: poi}},
+		{"lowercase mode", iceye.CreateTaskRequest{ImagingMode: "spotlight", PointOfInterest: poi}},
+		{"missing mode", iceye.CreateTaskRequest{PointOfInterest: poi}},
+		{"unknown look side", iceye.CreateTaskRequest{ImagingMode: iceye.ImagingModeSpotlight, PointOfInterest: poi, LookSide: "LEFTT"}},
+		{"unknown pass direction", iceye.CreateTaskRequest{ImagingMode: iceye.ImagingModeScan, PointOfInterest: poi, PassDirection: "NORTH"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cli.CreateTask(context.Background(), &tt.req)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestImagingModeValidation(t *testing.T) {
+	mode, err := iceye.ParseImagingMode(" stripmap ")
+	require.NoError(t, err)
+	assert.Equal(t, iceye.ImagingModeStripmap, mode)
+
+	_, err = iceye.ParseImagingMode("SPOTLITE")
+	assert.ErrorContains(t, err, `unknown imaging mode "SPOTLITE"`)
+
+	assert.NoError(t, iceye.ImagingModeScan.Validate())
+	assert.NoError(t, iceye.LookSideAny.Validate())
+	assert.NoError(t, iceye.PassDirectionDescending.Validate())
+	assert.Error(t, iceye.ImagingMode("").Validate())
+	assert.Error(t, iceye.LookSide("left").Validate())
+	assert.Error(t, iceye.PassDirection("UP").Validate())
+}
+
+func TestDecodeUnknownEnumValues(t *testing.T) {
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/tasking/v1/tasks/T-NEW", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{
+				"id": "T-NEW",
+				"imagingMode": "DWELL",
+				"status": "ACTIVE",
+				"lookSide": "BOTH",
+				"passDirection": "ASCENDING"
+			}`))
+		})
+	})
+
+	task, err := cli.GetTask(context.Background(), "T-NEW")
+	require.NoError(t, err)
+	assert.Equal(t, iceye.ImagingMode("DWELL"), task.ImagingMode)
+	assert.False(t, task.ImagingMode.IsKnown())
+	assert.Equal(t, iceye.LookSide("BOTH"), task.LookSide)
+	assert.False(t, task.LookSide.IsKnown())
+	assert.True(t, task.PassDirection.IsKnown())
+
+	var props iceye.ItemProperties
+	require.NoError(t, json.Unmarshal([]byte(`{"observation_direction": "nadir", "orbit_state": "descending"}`), &props))
+	assert.Equal(t, iceye.ObservationDirection("nadir"), props.ObservationDirection)
+	assert.ErrorContains(t, props.ObservationDirection.Validate(), `unknown observation direction "nadir"`)
+	assert.Equal(t, iceye.OrbitStateDescending, props.OrbitState)
+	assert.NoError(t, props.OrbitState.Validate())
+}
+
+func TestGetTaskSceneFootprint(t *testing.T) {
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/tasking/v1/tasks/T-1/scene", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{
+				"duration": 10,
+				"lookSide": "LEFT",
+				"passDirection": "DESCENDING",
+				"footprint": {
+					"type": "Polygon",
+					"coordinates": [[[24.8,60.1],[25.1,60.1],[25.1,60.3],[24.8,60.3],[24.8,60.1]]]
+				}
+			}`))
 		})
 	})
 
+	scene, err := cli.GetTaskScene(context.Background(), "T-1")
+
+	require.NoError(t, err)
+	require.NotNil(t, scene.Footprint)
+	poly, ok := scene.Footprint.Geometry().(orb.Polygon)
+	require.True(t, ok, "expected polygon footprint, got %T", scene.Footprint.Geometry())
+	assert.Len(t, poly[0], 5)
+	assert.InDelta(t, 25.1, poly[0][1][0], 1e-9)
+}
+
+func TestGetTaskPriceWithAreaOfInterest(t *testing.T) {
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/tasking/v1/price", func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			assert.Empty(t, q.Get("pointOfInterest[lat]"))
+
+			var aoi iceye.Geometry
+			require.NoError(t, json.Unmarshal([]byte(q.Get("areaOfInterest")), &aoi))
+			assert.Equal(t, "Polygon", aoi.Type)
+
+			json.NewEncoder(w).Encode(iceye.TaskPrice{Amount: 900000, Currency: "EUR"})
+		})
+	})
+
+	price, err := cli.GetTaskPrice(context.Background(), &iceye.TaskPriceRequest{
+		ContractID:     "C-1",
+		AreaOfInterest: iceye.BBoxToPolygon(iceye.BoundingBox{24.8, 60.1, 25.1, 60.3}),
+		ImagingMode:    "SCAN",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(900000), price.Amount)
+}
+
+func TestListTaskProducts(t *testing.T) {
+	srv := iceyetest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddTask(iceye.Task{ID: "T", Status: iceye.TaskStatusDone})
+	cli, err := srv.NewClient()
+	require.NoError(t, err)
+
 	products, err := cli.ListTaskProducts(context.Background(), "T")
 
 	require.NoError(t, err)
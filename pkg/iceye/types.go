@@ -26,6 +26,9 @@ type IncidenceAngle struct {
 }
 
 // BoundingBox is an alias for common.BoundingBox.
+//
+// Deprecated: use common.BoundingBox. The alias will be removed in the next
+// release.
 type BoundingBox = common.BoundingBox
 
 // Price is an alias for common.Price.
@@ -39,6 +42,9 @@ type Asset = common.Asset
 // ----------------------------------------------------------------------------
 
 // Geometry is an alias for geojson.Geometry for backwards compatibility.
+//
+// Deprecated: use geojson.Geometry. The alias will be removed in the next
+// release.
 type Geometry = geojson.Geometry
 
 // GeoJSONPoint creates a GeoJSON Point geometry.
@@ -52,7 +58,7 @@ func GeoJSONPolygon(coordinates [][][]float64) *geojson.Geometry {
 }
 
 // BBoxToPolygon converts a bounding box to a GeoJSON polygon.
-func BBoxToPolygon(bbox BoundingBox) *geojson.Geometry {
+func BBoxToPolygon(bbox common.BoundingBox) *geojson.Geometry {
 	return common.NewGeoJSONFromBBox(bbox)
 }
 
@@ -60,11 +66,20 @@ func BBoxToPolygon(bbox BoundingBox) *geojson.Geometry {
 // Shared Types (used across Company, Tasking, and Delivery APIs)
 // ----------------------------------------------------------------------------
 
-// DeliveryLocation specifies where products are delivered.
+// DeliveryLocation specifies where products are delivered. It refers to a
+// delivery location config by ID (see Client.ListDeliveryLocationConfigs)
+// and never carries credentials.
 type DeliveryLocation struct {
 	ConfigID string `json:"configID,omitempty"`
-	Method   string `json:"method"` // "s3"
+	Method   string `json:"method,omitempty"` // "s3"
 	Path     string `json:"path"`
+	SubPath  string `json:"subPath,omitempty"`
+}
+
+// NewDeliveryLocation returns a delivery location referring to the delivery
+// location config configID, delivering under path.
+func NewDeliveryLocation(configID, path string) DeliveryLocation {
+	return DeliveryLocation{ConfigID: configID, Path: path}
 }
 
 // NotificationConfig specifies webhook notifications.
@@ -0,0 +1,186 @@
+package iceye
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// ----------------------------------------------------------------------------
+// Usage Reporting
+// ----------------------------------------------------------------------------
+
+// UsageItemType distinguishes the line items of a usage report.
+type UsageItemType string
+
+const (
+	UsageItemTask     UsageItemType = "task"
+	UsageItemPurchase UsageItemType = "purchase"
+)
+
+// UsageLineItem is a billed task or catalog purchase.
+type UsageLineItem struct {
+	Type        UsageItemType `json:"type"`
+	ID          string        `json:"id"`                    // Task or purchase ID
+	ImagingMode string        `json:"imagingMode,omitempty"` // Tasks only
+	ItemIDs     []string      `json:"itemIds,omitempty"`     // Purchases only: catalog item IDs
+	Price       TaskPrice     `json:"price"`
+	Date        time.Time     `json:"date"` // Acquisition date of a task, creation date of a purchase
+}
+
+// UsageTotals aggregates the line items of a usage report.
+type UsageTotals struct {
+	Tasks       int
+	Purchases   int
+	TasksByMode map[string]int
+	Amounts     map[string]int64 // Minor currency unit, keyed by currency
+}
+
+// UsageReport lists the billed usage of a contract over a period.
+type UsageReport struct {
+	ContractID string
+	From, To   time.Time
+	Tasks      []UsageLineItem
+	Purchases  []UsageLineItem
+	Totals     UsageTotals
+}
+
+// usageResponse is the paginated response of the usage endpoint.
+type usageResponse struct {
+	Data   []UsageLineItem `json:"data"`
+	Cursor string          `json:"cursor,omitempty"`
+}
+
+func validateUsagePeriod(from, to time.Time) error {
+	switch {
+	case from.IsZero() || to.IsZero():
+		return errors.New("iceye: usage period requires from and to")
+	case from.After(to):
+		return errors.New("iceye: usage period from is after to")
+	case to.After(from.AddDate(1, 0, 0)):
+		return errors.New("iceye: usage period exceeds one year")
+	}
+	return nil
+}
+
+// UsageLineItems returns an iterator over the billed tasks and catalog
+// purchases of a contract between from and to, fetching pages as needed. The
+// period must not be longer than a year; an invalid period is yielded as the
+// only error.
+//
+// GET /company/v1/contracts/{contractID}/usage
+func (c *Client) UsageLineItems(ctx context.Context, contractID string, from, to time.Time) iter.Seq2[UsageLineItem, error] {
+	return func(yield func(UsageLineItem, error) bool) {
+		if err := validateUsagePeriod(from, to); err != nil {
+			yield(UsageLineItem{}, err)
+			return
+		}
+		seq := common.Paginate(func(cur *string) ([]UsageLineItem, *string, error) {
+			u := &url.URL{Path: path.Join(companyBasePath, "contracts", contractID, "usage")}
+			q := u.Query()
+			q.Set("from", from.UTC().Format(time.RFC3339))
+			q.Set("to", to.UTC().Format(time.RFC3339))
+			if cur != nil && *cur != "" {
+				q.Set("cursor", *cur)
+			}
+			u.RawQuery = q.Encode()
+
+			var resp usageResponse
+			err := c.do(ctx, http.MethodGet, u.String(), nil, &resp)
+			return resp.Data, &resp.Cursor, err
+		})
+		for items, err := range seq {
+			if err != nil {
+				yield(UsageLineItem{}, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GetUsageReport collects the billed usage of a contract between from and
+// to, with totals. Use UsageLineItems to stream very large periods.
+func (c *Client) GetUsageReport(ctx context.Context, contractID string, from, to time.Time) (*UsageReport, error) {
+	report := &UsageReport{ContractID: contractID, From: from, To: to}
+	for item, err := range c.UsageLineItems(ctx, contractID, from, to) {
+		if err != nil {
+			return nil, err
+		}
+		switch item.Type {
+		case UsageItemTask:
+			report.Tasks = append(report.Tasks, item)
+		case UsageItemPurchase:
+			report.Purchases = append(report.Purchases, item)
+		}
+	}
+	report.Totals = totalUsage(report.Tasks, report.Purchases)
+	return report, nil
+}
+
+func totalUsage(tasks, purchases []UsageLineItem) UsageTotals {
+	t := UsageTotals{
+		Tasks:       len(tasks),
+		Purchases:   len(purchases),
+		TasksByMode: make(map[string]int),
+		Amounts:     make(map[string]int64),
+	}
+	for _, item := range tasks {
+		t.TasksByMode[item.ImagingMode]++
+		t.Amounts[item.Price.Currency] += item.Price.Amount
+	}
+	for _, item := range purchases {
+		t.Amounts[item.Price.Currency] += item.Price.Amount
+	}
+	return t
+}
+
+// usageCSVHeader is the column order written by WriteCSV.
+var usageCSVHeader = []string{"type", "id", "date", "imaging_mode", "item_ids", "amount", "currency"}
+
+// WriteCSV writes the report's line items as CSV, tasks first and then
+// purchases, each ordered by date and ID. Amounts are in the minor currency
+// unit and dates are RFC 3339 UTC; purchased item IDs are joined with ";".
+func (r *UsageReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(usageCSVHeader); err != nil {
+		return err
+	}
+	for _, items := range [][]UsageLineItem{r.Tasks, r.Purchases} {
+		sorted := slices.SortedFunc(slices.Values(items), func(a, b UsageLineItem) int {
+			return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.ID, b.ID))
+		})
+		for _, item := range sorted {
+			row := []string{
+				string(item.Type),
+				item.ID,
+				item.Date.UTC().Format(time.RFC3339),
+				item.ImagingMode,
+				strings.Join(item.ItemIDs, ";"),
+				strconv.FormatInt(item.Price.Amount, 10),
+				item.Price.Currency,
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
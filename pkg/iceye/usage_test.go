@@ -0,0 +1,129 @@
+package iceye_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/iceye"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usagePages is a realistic two-page usage response for contract C-123.
+var usagePages = map[string]string{
+	"": `{
+		"data": [
+			{"type": "task", "id": "task-1", "imagingMode": "STRIPMAP", "price": {"amount": 180000, "currency": "EUR"}, "date": "2025-03-09T21:40:00Z"},
+			{"type": "task", "id": "task-2", "imagingMode": "SPOTLIGHT", "price": {"amount": 450000, "currency": "EUR"}, "date": "2025-03-02T08:15:00Z"},
+			{"type": "purchase", "id": "purchase-2", "itemIds": ["item-c"], "price": {"amount": 25000, "currency": "USD"}, "date": "2025-03-20T09:30:00Z"}
+		],
+		"cursor": "page-2"
+	}`,
+	"page-2": `{
+		"data": [
+			{"type": "task", "id": "task-3", "imagingMode": "SPOTLIGHT", "price": {"amount": 450000, "currency": "EUR"}, "date": "2025-03-09T22:40:00+01:00"},
+			{"type": "purchase", "id": "purchase-1", "itemIds": ["item-a", "item-b"], "price": {"amount": 60000, "currency": "EUR"}, "date": "2025-03-15T12:00:00Z"}
+		]
+	}`,
+}
+
+var (
+	usageFrom = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	usageTo   = time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func usageClient(t *testing.T, hits *atomic.Int32) *iceye.Client {
+	cli, _, _ := newTestClient(t, func(mux *http.ServeMux, _ *atomic.Int32) {
+		mux.HandleFunc("/oauth2/token", mockAuthHandler(nil))
+		mux.HandleFunc("/company/v1/contracts/C-123/usage", func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "2025-03-01T00:00:00Z", r.URL.Query().Get("from"))
+			page, ok := usagePages[r.URL.Query().Get("cursor")]
+			require.True(t, ok, "unexpected cursor")
+			w.Write([]byte(page))
+		})
+	})
+	return cli
+}
+
+func TestGetUsageReport(t *testing.T) {
+	var hits atomic.Int32
+	cli := usageClient(t, &hits)
+
+	report, err := cli.GetUsageReport(context.Background(), "C-123", usageFrom, usageTo)
+	require.NoError(t, err)
+
+	assert.Equal(t, "C-123", report.ContractID)
+	require.Len(t, report.Tasks, 3)
+	require.Len(t, report.Purchases, 2)
+	assert.Equal(t, "STRIPMAP", report.Tasks[0].ImagingMode)
+	assert.Equal(t, iceye.TaskPrice{Amount: 180000, Currency: "EUR"}, report.Tasks[0].Price)
+	assert.Equal(t, []string{"item-a", "item-b"}, report.Purchases[1].ItemIDs)
+
+	assert.Equal(t, 3, report.Totals.Tasks)
+	assert.Equal(t, 2, report.Totals.Purchases)
+	assert.Equal(t, map[string]int{"SPOTLIGHT": 2, "STRIPMAP": 1}, report.Totals.TasksByMode)
+	assert.Equal(t, map[string]int64{"EUR": 1140000, "USD": 25000}, report.Totals.Amounts)
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestUsageLineItems_StopEarly(t *testing.T) {
+	var hits atomic.Int32
+	cli := usageClient(t, &hits)
+
+	var ids []string
+	for item, err := range cli.UsageLineItems(context.Background(), "C-123", usageFrom, usageTo) {
+		require.NoError(t, err)
+		ids = append(ids, item.ID)
+		if len(ids) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"task-1", "task-2"}, ids)
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+func TestUsageReportCSV(t *testing.T) {
+	var hits atomic.Int32
+	cli := usageClient(t, &hits)
+
+	report, err := cli.GetUsageReport(context.Background(), "C-123", usageFrom, usageTo)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteCSV(&buf))
+
+	golden, err := os.ReadFile("testdata/usage_report.csv")
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), buf.String())
+}
+
+func TestGetUsageReport_PeriodValidation(t *testing.T) {
+	var hits atomic.Int32
+	cli := usageClient(t, &hits)
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+	}{
+		{"missing from", time.Time{}, usageTo},
+		{"from after to", usageTo, usageFrom},
+		{"longer than a year", usageFrom, usageFrom.AddDate(1, 0, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cli.GetUsageReport(context.Background(), "C-123", tt.from, tt.to)
+			assert.Error(t, err)
+		})
+	}
+	assert.Zero(t, hits.Load(), "invalid periods must not reach the API")
+
+	_, err := cli.GetUsageReport(context.Background(), "C-123", usageFrom, usageFrom.AddDate(1, 0, 0))
+	require.NoError(t, err)
+}
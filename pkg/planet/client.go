@@ -6,7 +6,8 @@
 //
 // Key features:
 //   - API key authentication
-//   - Full coverage of Tasking API v2, Imaging Windows API, and Orders API v2
+//   - Full coverage of Tasking API v2, Imaging Windows API, Orders API v2,
+//     and Subscriptions API v1
 //   - GeoJSON geometry support via paulmach/orb
 //   - Idiomatic Go types with comprehensive type safety
 //   - Thread-safe; safe for concurrent goroutines
@@ -33,6 +34,9 @@ const (
 	// OrdersBasePath is the base path for the Orders API v2.
 	OrdersBasePath = "/compute/ops/orders/v2"
 
+	// SubscriptionsBasePath is the base path for the Subscriptions API v1.
+	SubscriptionsBasePath = "/subscriptions/v1"
+
 	defaultTimeout   = 30 * time.Second
 	defaultUserAgent = "go-sar-vendor/planet"
 )
@@ -40,8 +44,14 @@ const (
 // Client represents a Planet API client.
 type Client struct {
 	*common.Client
-	taskingBaseURL *url.URL
-	ordersBaseURL  *url.URL
+	taskingBaseURL       *url.URL
+	ordersBaseURL        *url.URL
+	subscriptionsBaseURL *url.URL
+
+	followAbsoluteNext bool
+	maxPages           int
+	assuredLeadTime    time.Duration
+	products           *productsCache
 }
 
 // Option configures a Client.
@@ -52,6 +62,11 @@ type clientConfig struct {
 	httpClient *http.Client
 	timeout    time.Duration
 	userAgent  string
+
+	followAbsoluteNext bool
+	maxPages           int
+	assuredLeadTime    time.Duration
+	productsTTL        time.Duration
 }
 
 // WithHTTPClient sets a custom HTTP client.
@@ -82,18 +97,62 @@ func WithUserAgent(userAgent string) Option {
 	}
 }
 
+// WithFollowAbsoluteNextLinks makes list iterators follow next links exactly
+// as returned by the API. By default only their path and query are kept and
+// rebased onto the base URL, since Planet returns its public hostname even
+// when the client talks to it through a proxy.
+func WithFollowAbsoluteNextLinks() Option {
+	return func(c *clientConfig) {
+		c.followAbsoluteNext = true
+	}
+}
+
+// WithMaxPages bounds the number of pages a list iterator fetches before it
+// stops with a *PaginationError. Defaults to 1000; non-positive values keep
+// the default.
+func WithMaxPages(n int) Option {
+	return func(c *clientConfig) {
+		c.maxPages = n
+	}
+}
+
+// WithAssuredLeadTime sets how far in the future an imaging window must
+// start for CreateAssuredOrder to book it. Defaults to
+// DefaultAssuredLeadTime; negative values keep the default.
+func WithAssuredLeadTime(d time.Duration) Option {
+	return func(c *clientConfig) {
+		c.assuredLeadTime = d
+	}
+}
+
+// WithProductsTTL sets how long ValidateOrderRequest caches the products
+// of a PL number. Defaults to one hour.
+func WithProductsTTL(ttl time.Duration) Option {
+	return func(c *clientConfig) {
+		c.productsTTL = ttl
+	}
+}
+
 // NewClient creates a new Planet API client.
 func NewClient(apiKey string, opts ...Option) (*Client, error) {
 	cfg := &clientConfig{
-		baseURL:   DefaultBaseURL,
-		timeout:   defaultTimeout,
-		userAgent: defaultUserAgent,
+		baseURL:         DefaultBaseURL,
+		timeout:         defaultTimeout,
+		userAgent:       defaultUserAgent,
+		maxPages:        defaultMaxPages,
+		assuredLeadTime: DefaultAssuredLeadTime,
 	}
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
 	httpClient := common.EnsureHTTPClient(cfg.httpClient, cfg.timeout)
+	if cfg.maxPages <= 0 {
+		cfg.maxPages = defaultMaxPages
+	}
+	if cfg.assuredLeadTime < 0 {
+		cfg.assuredLeadTime = DefaultAssuredLeadTime
+	}
 
 	baseURL, err := url.Parse(cfg.baseURL)
 	if err != nil {
@@ -102,6 +161,7 @@ func NewClient(apiKey string, opts ...Option) (*Client, error) {
 
 	taskingBaseURL := baseURL.JoinPath(TaskingBasePath)
 	ordersBaseURL := baseURL.JoinPath(OrdersBasePath)
+	subscriptionsBaseURL := baseURL.JoinPath(SubscriptionsBasePath)
 
 	c, err := common.NewClient(common.ClientConfig{
 		BaseURL:    cfg.baseURL,
@@ -114,9 +174,14 @@ func NewClient(apiKey string, opts ...Option) (*Client, error) {
 	}
 
 	return &Client{
-		Client:         c,
-		taskingBaseURL: taskingBaseURL,
-		ordersBaseURL:  ordersBaseURL,
+		Client:               c,
+		taskingBaseURL:       taskingBaseURL,
+		ordersBaseURL:        ordersBaseURL,
+		subscriptionsBaseURL: subscriptionsBaseURL,
+		followAbsoluteNext:   cfg.followAbsoluteNext,
+		maxPages:             cfg.maxPages,
+		assuredLeadTime:      cfg.assuredLeadTime,
+		products:             &productsCache{ttl: cfg.productsTTL},
 	}, nil
 }
 
@@ -130,6 +195,11 @@ func (c *Client) OrdersURL(path ...string) *url.URL {
 	return c.ordersBaseURL.JoinPath(path...)
 }
 
+// SubscriptionsURL returns the full URL for a subscriptions API path.
+func (c *Client) SubscriptionsURL(path ...string) *url.URL {
+	return c.subscriptionsBaseURL.JoinPath(path...)
+}
+
 // apiKeyAuth implements the common.Authenticator interface for Planet API key authentication.
 type apiKeyAuth struct {
 	apiKey string
@@ -2,6 +2,7 @@ package planet
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -38,33 +39,25 @@ func (c *Client) WaitForImagingWindowSearch(ctx context.Context, id string, opts
 		}
 	}
 
-	deadline := time.Now().Add(opts.Timeout)
-	ticker := time.NewTicker(opts.PollInterval)
-	defer ticker.Stop()
-
-	for {
-		search, err := c.GetImagingWindowSearch(ctx, id)
+	var search *ImagingWindowSearch
+	err := common.Poll(ctx, *opts, func(ctx context.Context) (bool, error) {
+		var err error
+		search, err = c.GetImagingWindowSearch(ctx, id)
 		if err != nil {
-			return nil, err
-		}
-
-		if search.Status.IsTerminal() {
-			if search.Status == ImagingWindowSearchStatusFailed {
-				return search, fmt.Errorf("imaging window search failed: %s", search.ErrorMessage)
-			}
-			return search, nil
-		}
-
-		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("timeout waiting for imaging window search %s to complete", id)
-		}
-
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
+			return false, err
 		}
+		return search.Status.IsTerminal(), nil
+	})
+	if errors.Is(err, common.ErrWaitTimeout) {
+		return nil, fmt.Errorf("timeout waiting for imaging window search %s to complete: %w", id, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if search.Status == ImagingWindowSearchStatusFailed {
+		return search, fmt.Errorf("imaging window search failed: %s", search.ErrorMessage)
 	}
+	return search, nil
 }
 
 // SearchImagingWindows is a convenience method that creates an imaging window search
@@ -2,6 +2,7 @@ package planet
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"net/http"
@@ -42,65 +43,39 @@ func (c *Client) CancelOrder(ctx context.Context, id string) error {
 // Returns an iterator that handles pagination automatically.
 // GET /compute/ops/orders/v2
 func (c *Client) ListOrders(ctx context.Context, opts *ListOrdersOptions) iter.Seq2[Order, error] {
-	return func(yield func(Order, error) bool) {
-		u := c.ordersBaseURL
-
-		for {
-			q := u.Query()
-			if opts != nil {
-				if opts.Limit > 0 {
-					q.Set("limit", fmt.Sprintf("%d", opts.Limit))
-				} else {
-					q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
-				}
-				if opts.Name != "" {
-					q.Set("name", opts.Name)
-				}
-				if opts.SourceType != "" {
-					q.Set("source_type", string(opts.SourceType))
-				}
-				if opts.DestinationRef != "" {
-					q.Set("destination_ref", opts.DestinationRef)
-				}
-				if opts.Hosting != nil {
-					q.Set("hosting", fmt.Sprintf("%t", *opts.Hosting))
-				}
-				for _, s := range opts.State {
-					q.Add("state", string(s))
-				}
-			} else {
-				q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
-			}
-			u.RawQuery = q.Encode()
-
-			var resp ordersListResponse
-			if err := c.DoRaw(ctx, http.MethodGet, u, nil, http.StatusOK, &resp); err != nil {
-				var zero Order
-				yield(zero, err)
-				return
-			}
-
-			for _, order := range resp.Orders {
-				if !yield(order, nil) {
-					return
-				}
-			}
-
-			// Check if there are more pages
-			if resp.Links.Next == "" {
-				return
-			}
-
-			nextURL, err := url.Parse(resp.Links.Next)
-			if err != nil {
-				var zero Order
-				yield(zero, err)
-				return
-			}
-			u = nextURL
-			opts = nil
+	u := c.OrdersURL()
+	q := u.Query()
+	if opts != nil {
+		if opts.Limit > 0 {
+			q.Set("limit", fmt.Sprintf("%d", opts.Limit))
+		} else {
+			q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
 		}
+		if opts.Name != "" {
+			q.Set("name", opts.Name)
+		}
+		if opts.SourceType != "" {
+			q.Set("source_type", string(opts.SourceType))
+		}
+		if opts.DestinationRef != "" {
+			q.Set("destination_ref", opts.DestinationRef)
+		}
+		if opts.Hosting != nil {
+			q.Set("hosting", fmt.Sprintf("%t", *opts.Hosting))
+		}
+		for _, s := range opts.State {
+			q.Add("state", string(s))
+		}
+	} else {
+		q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
 	}
+	u.RawQuery = q.Encode()
+
+	return listPages(ctx, c, u, func(u *url.URL) ([]Order, string, error) {
+		var resp ordersListResponse
+		err := c.DoRaw(ctx, http.MethodGet, u, nil, http.StatusOK, &resp)
+		return resp.Orders, resp.Links.Next, err
+	})
 }
 
 // ordersListResponse is the response structure for listing orders.
@@ -121,30 +96,22 @@ func (c *Client) WaitForOrder(ctx context.Context, id string, opts *WaitOptions)
 		}
 	}
 
-	deadline := time.Now().Add(opts.Timeout)
-	ticker := time.NewTicker(opts.PollInterval)
-	defer ticker.Stop()
-
-	for {
-		order, err := c.GetOrder(ctx, id)
+	var order *Order
+	err := common.Poll(ctx, *opts, func(ctx context.Context) (bool, error) {
+		var err error
+		order, err = c.GetOrder(ctx, id)
 		if err != nil {
-			return nil, err
-		}
-
-		if order.State.IsTerminal() {
-			return order, nil
-		}
-
-		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("timeout waiting for order %s to reach terminal state", id)
-		}
-
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
+			return false, err
 		}
+		return order.State.IsTerminal(), nil
+	})
+	if errors.Is(err, common.ErrWaitTimeout) {
+		return nil, fmt.Errorf("timeout waiting for order %s to reach terminal state: %w", id, err)
 	}
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
 }
 
 // WaitForOrderSuccess polls until the order succeeds or fails.
@@ -0,0 +1,88 @@
+package planet
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"strings"
+)
+
+// defaultMaxPages bounds the pages a list iterator fetches (see WithMaxPages).
+const defaultMaxPages = 1000
+
+// PaginationError is returned by list iterators that stop following next
+// links: either a next link repeats an already fetched page, or the client's
+// page limit was reached.
+type PaginationError struct {
+	Next     string // The next link that was not followed
+	Pages    int    // Pages fetched so far
+	MaxPages int    // The page limit; 0 for a loop
+	Loop     bool   // The next link was already fetched
+}
+
+func (e *PaginationError) Error() string {
+	if e.Loop {
+		return fmt.Sprintf("pagination loop: next link %s was already fetched", e.Next)
+	}
+	return fmt.Sprintf("pagination stopped after %d pages (limit %d)", e.Pages, e.MaxPages)
+}
+
+// listPages returns an iterator over the results of a list endpoint, starting
+// at first and following next links. fetch retrieves one page and returns its
+// results and next link.
+func listPages[T any](ctx context.Context, c *Client, first *url.URL, fetch func(u *url.URL) ([]T, string, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		u := first
+		seen := map[string]bool{}
+		for pages := 1; ; pages++ {
+			seen[u.String()] = true
+			results, next, err := fetch(u)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, r := range results {
+				if !yield(r, nil) {
+					return
+				}
+			}
+			if next == "" {
+				return
+			}
+
+			if u, err = c.nextPageURL(next); err != nil {
+				yield(zero, err)
+				return
+			}
+			switch {
+			case seen[u.String()]:
+				yield(zero, &PaginationError{Next: next, Pages: pages, Loop: true})
+				return
+			case pages >= c.maxPages:
+				yield(zero, &PaginationError{Next: next, Pages: pages, MaxPages: c.maxPages})
+				return
+			}
+		}
+	}
+}
+
+// nextPageURL parses a next link. Unless the client follows absolute links,
+// only its path and query are kept and rebased onto the client's base URL, so
+// pages are fetched through the same host (e.g. a proxy) as the first one.
+func (c *Client) nextPageURL(next string) (*url.URL, error) {
+	nextURL, err := url.Parse(next)
+	if err != nil {
+		return nil, fmt.Errorf("parse next link: %w", err)
+	}
+	if c.followAbsoluteNext {
+		return c.BaseURL().ResolveReference(nextURL), nil
+	}
+	u := *c.BaseURL()
+	u.Path = strings.TrimSuffix(u.Path, "/") + nextURL.Path
+	u.RawPath = ""
+	u.RawQuery = nextURL.RawQuery
+	u.Fragment = ""
+	return &u, nil
+}
@@ -0,0 +1,111 @@
+package planet_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/planet"
+)
+
+// pagedOrders serves one tasking order per page, with next links built by
+// next from the requested offset. Pages after the last have no next link.
+func pagedOrders(t *testing.T, last int, next func(offset int) string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requirePath(t, r, "/tasking/v2/orders")
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		resp := map[string]any{"results": []planet.TaskingOrder{{ID: fmt.Sprintf("order-%d", offset)}}}
+		if offset < last {
+			resp["next"] = next(offset + 1)
+		}
+		jsonResponse(w, http.StatusOK, resp)
+	}
+}
+
+func collectOrders(cli *planet.Client) ([]string, error) {
+	var ids []string
+	for order, err := range cli.ListTaskingOrders(context.Background(), &planet.ListTaskingOrdersOptions{Limit: 1}) {
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, order.ID)
+	}
+	return ids, nil
+}
+
+func TestListTaskingOrders_RebasesNextLinks(t *testing.T) {
+	cli, _ := newTestClient(t, pagedOrders(t, 2, func(offset int) string {
+		return fmt.Sprintf("https://api.planet.com/tasking/v2/orders?limit=1&offset=%d", offset)
+	}))
+
+	ids, err := collectOrders(cli)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(ids) != "[order-0 order-1 order-2]" {
+		t.Errorf("unexpected orders: %v", ids)
+	}
+}
+
+func TestListTaskingOrders_FollowAbsoluteNextLinks(t *testing.T) {
+	var publicHits int
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		publicHits++
+		jsonResponse(w, http.StatusOK, map[string]any{"results": []planet.TaskingOrder{{ID: "public"}}})
+	}))
+	t.Cleanup(public.Close)
+
+	_, srv := newTestClient(t, pagedOrders(t, 1, func(offset int) string {
+		return fmt.Sprintf("%s/tasking/v2/orders?offset=%d", public.URL, offset)
+	}))
+	cli, err := planet.NewClient("test-api-key", planet.WithBaseURL(srv.URL), planet.WithFollowAbsoluteNextLinks())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ids, err := collectOrders(cli)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(ids) != "[order-0 public]" || publicHits != 1 {
+		t.Errorf("expected the absolute next link to be followed, got %v", ids)
+	}
+}
+
+func TestListTaskingOrders_DetectsLoop(t *testing.T) {
+	cli, _ := newTestClient(t, pagedOrders(t, 5, func(int) string {
+		return "/tasking/v2/orders?limit=1&offset=1"
+	}))
+
+	ids, err := collectOrders(cli)
+	var pagErr *planet.PaginationError
+	if !errors.As(err, &pagErr) || !pagErr.Loop {
+		t.Fatalf("expected a pagination loop error, got %v", err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("expected the repeated page to be fetched once, got %v", ids)
+	}
+}
+
+func TestListTaskingOrders_MaxPages(t *testing.T) {
+	_, srv := newTestClient(t, pagedOrders(t, 100, func(offset int) string {
+		return fmt.Sprintf("/tasking/v2/orders?limit=1&offset=%d", offset)
+	}))
+	cli, err := planet.NewClient("test-api-key", planet.WithBaseURL(srv.URL), planet.WithMaxPages(3))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ids, err := collectOrders(cli)
+	var pagErr *planet.PaginationError
+	if !errors.As(err, &pagErr) || pagErr.Loop || pagErr.Pages != 3 || pagErr.MaxPages != 3 {
+		t.Fatalf("expected a page limit error after 3 pages, got %v", err)
+	}
+	if len(ids) != 3 {
+		t.Errorf("expected 3 orders, got %v", ids)
+	}
+}
@@ -0,0 +1,224 @@
+package planet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultProductsTTL is how long ValidateOrderRequest reuses the products
+// fetched for a PL number.
+const defaultProductsTTL = time.Hour
+
+// ----------------------------------------------------------------------------
+// Product Capabilities
+// ----------------------------------------------------------------------------
+
+// GSDRange is the ground sample distance range of a product, in meters.
+type GSDRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// ProductInfo describes a tasking product a PL number is entitled to and
+// the order parameters it supports.
+type ProductInfo struct {
+	Name            string           `json:"name"`
+	SatelliteTypes  []SatelliteType  `json:"satellite_types,omitempty"`
+	SchedulingTypes []SchedulingType `json:"scheduling_types,omitempty"`
+	GSD             GSDRange         `json:"gsd"`
+	// AssuredTasking reports whether the product can be ordered with the
+	// ASSURED scheduling type.
+	AssuredTasking bool `json:"assured_tasking,omitempty"`
+}
+
+// SupportsSatellite reports whether the product can be collected by st.
+func (p ProductInfo) SupportsSatellite(st SatelliteType) bool {
+	return slices.Contains(p.SatelliteTypes, st)
+}
+
+// SupportsScheduling reports whether the product can be ordered with st.
+func (p ProductInfo) SupportsScheduling(st SchedulingType) bool {
+	if st == SchedulingTypeAssured {
+		return p.AssuredTasking
+	}
+	return slices.Contains(p.SchedulingTypes, st)
+}
+
+// ListProducts returns the tasking products plNumber is entitled to and
+// refreshes the products cached for ValidateOrderRequest.
+//
+// The endpoint is not described in spec/ and the capability field names are
+// unverified. A product whose capabilities fail to decode is returned with
+// only its name.
+// GET /tasking/v2/products/?pl_number=
+func (c *Client) ListProducts(ctx context.Context, plNumber string) ([]ProductInfo, error) {
+	if plNumber == "" {
+		return nil, errors.New("pl_number is required")
+	}
+	u := c.TaskingURL("products", "")
+	q := u.Query()
+	q.Set("pl_number", plNumber)
+	q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
+	u.RawQuery = q.Encode()
+
+	var products []ProductInfo
+	seq := listPages(ctx, c, u, func(u *url.URL) ([]json.RawMessage, string, error) {
+		var resp paginatedResponse[json.RawMessage]
+		err := c.DoRaw(ctx, http.MethodGet, u, nil, http.StatusOK, &resp)
+		return resp.Results, resp.Next, err
+	})
+	for raw, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, decodeProduct(raw))
+	}
+	c.products.store(plNumber, products)
+	return products, nil
+}
+
+// decodeProduct decodes a product, keeping only its name when its
+// capabilities do not decode.
+func decodeProduct(raw json.RawMessage) ProductInfo {
+	var p ProductInfo
+	if err := json.Unmarshal(raw, &p); err != nil {
+		var named struct {
+			Name string `json:"name"`
+		}
+		_ = json.Unmarshal(raw, &named)
+		return ProductInfo{Name: named.Name}
+	}
+	// The ASSURED scheduling type implies assured tasking.
+	if slices.Contains(p.SchedulingTypes, SchedulingTypeAssured) {
+		p.AssuredTasking = true
+	}
+	return p
+}
+
+// productsCache holds the products last fetched per PL number.
+type productsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]productsEntry
+}
+
+type productsEntry struct {
+	fetched  time.Time
+	products map[string]ProductInfo
+}
+
+func (pc *productsCache) store(plNumber string, products []ProductInfo) {
+	byName := make(map[string]ProductInfo, len(products))
+	for _, p := range products {
+		byName[p.Name] = p
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if pc.entries == nil {
+		pc.entries = make(map[string]productsEntry)
+	}
+	pc.entries[plNumber] = productsEntry{fetched: time.Now(), products: byName}
+}
+
+func (pc *productsCache) load(plNumber string) (map[string]ProductInfo, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	ttl := pc.ttl
+	if ttl <= 0 {
+		ttl = defaultProductsTTL
+	}
+	e, ok := pc.entries[plNumber]
+	if !ok || time.Since(e.fetched) >= ttl {
+		return nil, false
+	}
+	return e.products, true
+}
+
+// productsFor returns the cached products of plNumber keyed by name,
+// fetching them when they are missing or older than the cache TTL.
+func (c *Client) productsFor(ctx context.Context, plNumber string) (map[string]ProductInfo, error) {
+	if products, ok := c.products.load(plNumber); ok {
+		return products, nil
+	}
+	if _, err := c.ListProducts(ctx, plNumber); err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+	products, _ := c.products.load(plNumber)
+	return products, nil
+}
+
+// ValidateOrderRequest checks that req's product is one its PL number is
+// entitled to and that the product supports its satellite types and
+// scheduling type, so invalid combinations fail before the order is placed.
+//
+// Products are fetched on first use and cached per PL number for an hour
+// (see WithProductsTTL). All incompatibilities are returned together as a
+// single error built with errors.Join; a failure to fetch products is
+// returned on its own.
+//
+// Since the products endpoint is unverified (see ListProducts), validation
+// fails open: a check is skipped when the products it relies on are
+// missing. Entitlement is not checked when no products are listed or one
+// has no name, and satellite or scheduling types are not checked when the
+// product lists none.
+func (c *Client) ValidateOrderRequest(ctx context.Context, req *CreateTaskingOrderRequest) error {
+	if req == nil {
+		return errors.New("order request is nil")
+	}
+	return c.validateCapabilities(ctx, req.PLNumber, req.Product, req.SatelliteTypes, req.SchedulingType)
+}
+
+// ValidateImagingWindowSearch checks req's product and satellite types like
+// ValidateOrderRequest. Imaging window searches carry no scheduling type.
+func (c *Client) ValidateImagingWindowSearch(ctx context.Context, req *ImagingWindowSearchRequest) error {
+	if req == nil {
+		return errors.New("imaging window search request is nil")
+	}
+	return c.validateCapabilities(ctx, req.PLNumber, req.Product, req.SatelliteTypes, "")
+}
+
+func (c *Client) validateCapabilities(ctx context.Context, plNumber, product string, satellites []SatelliteType, scheduling SchedulingType) error {
+	if plNumber == "" {
+		return errors.New("pl_number is required to validate against product capabilities")
+	}
+	if product == "" {
+		return errors.New("product is required to validate against product capabilities")
+	}
+	products, err := c.productsFor(ctx, plNumber)
+	if err != nil {
+		return err
+	}
+
+	_, unnamed := products[""]
+	info, ok := products[product]
+	if !ok && len(products) > 0 && !unnamed {
+		names := make([]string, 0, len(products))
+		for name := range products {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return fmt.Errorf("PL number %s is not entitled to product %q (available: %v)", plNumber, product, names)
+	}
+
+	var errs []error
+	for _, st := range satellites {
+		if len(info.SatelliteTypes) > 0 && !info.SupportsSatellite(st) {
+			errs = append(errs, fmt.Errorf("product %q does not support satellite type %s (supported: %v)", product, st, info.SatelliteTypes))
+		}
+	}
+	switch {
+	case scheduling == "", len(info.SchedulingTypes) == 0 && !info.AssuredTasking:
+	case scheduling == SchedulingTypeAssured && !info.AssuredTasking:
+		errs = append(errs, fmt.Errorf("product %q is not available for assured tasking", product))
+	case !info.SupportsScheduling(scheduling):
+		errs = append(errs, fmt.Errorf("product %q does not support scheduling type %s (supported: %v)", product, scheduling, info.SchedulingTypes))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,258 @@
+package planet_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/planet"
+)
+
+// productsHandler serves the products of PL-1 and counts the requests.
+func productsHandler(t *testing.T, hits *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodGet)
+		requirePath(t, r, "/tasking/v2/products")
+		if got := r.URL.Query().Get("pl_number"); got != "PL-1" {
+			t.Errorf("expected pl_number=PL-1, got %q", got)
+		}
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"count": 2,
+			"results": [
+				{
+					"name": "SkySat",
+					"satellite_types": ["SKYSAT"],
+					"scheduling_types": ["FLEXIBLE", "ASSURED", "MONITORING"],
+					"gsd": {"min": 0.5, "max": 0.8}
+				},
+				{
+					"name": "Pelican",
+					"satellite_types": ["PELICAN"],
+					"scheduling_types": ["FLEXIBLE"],
+					"gsd": {"min": 0.3, "max": 0.5}
+				}
+			]
+		}`))
+	}
+}
+
+func TestListProducts(t *testing.T) {
+	var hits atomic.Int32
+	cli, _ := newTestClient(t, productsHandler(t, &hits))
+
+	products, err := cli.ListProducts(context.Background(), "PL-1")
+	if err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if len(products) != 2 {
+		t.Fatalf("got %d products, want 2", len(products))
+	}
+
+	sky := products[0]
+	if sky.Name != "SkySat" || sky.GSD != (planet.GSDRange{Min: 0.5, Max: 0.8}) {
+		t.Errorf("unexpected product: %+v", sky)
+	}
+	if !sky.AssuredTasking {
+		t.Error("ASSURED scheduling type should imply assured tasking")
+	}
+	if !sky.SupportsSatellite(planet.SatelliteTypeSkySat) || sky.SupportsSatellite(planet.SatelliteTypePelican) {
+		t.Errorf("unexpected satellite support: %v", sky.SatelliteTypes)
+	}
+	if !sky.SupportsScheduling(planet.SchedulingTypeMonitoring) || sky.SupportsScheduling(planet.SchedulingTypeExpress) {
+		t.Errorf("unexpected scheduling support: %v", sky.SchedulingTypes)
+	}
+	if products[1].AssuredTasking {
+		t.Error("Pelican should not offer assured tasking")
+	}
+
+	if _, err := cli.ListProducts(context.Background(), ""); err == nil {
+		t.Error("expected an error without a PL number")
+	}
+}
+
+func TestValidateOrderRequest(t *testing.T) {
+	var hits atomic.Int32
+	cli, _ := newTestClient(t, productsHandler(t, &hits))
+
+	tests := []struct {
+		name    string
+		req     planet.CreateTaskingOrderRequest
+		wantErr []string
+	}{
+		{
+			name: "valid",
+			req: planet.CreateTaskingOrderRequest{
+				PLNumber: "PL-1", Product: "SkySat",
+				SatelliteTypes: []planet.SatelliteType{planet.SatelliteTypeSkySat},
+				SchedulingType: planet.SchedulingTypeAssured,
+			},
+		},
+		{
+			name:    "product not entitled",
+			req:     planet.CreateTaskingOrderRequest{PLNumber: "PL-1", Product: "Tanager"},
+			wantErr: []string{`not entitled to product "Tanager"`, "[Pelican SkySat]"},
+		},
+		{
+			name: "unsupported satellite type",
+			req: planet.CreateTaskingOrderRequest{
+				PLNumber: "PL-1", Product: "SkySat",
+				SatelliteTypes: []planet.SatelliteType{planet.SatelliteTypeSkySat, planet.SatelliteTypeTanager},
+			},
+			wantErr: []string{"does not support satellite type TANAGER"},
+		},
+		{
+			name:    "unsupported scheduling type",
+			req:     planet.CreateTaskingOrderRequest{PLNumber: "PL-1", Product: "Pelican", SchedulingType: planet.SchedulingTypeMonitoring},
+			wantErr: []string{"does not support scheduling type MONITORING"},
+		},
+		{
+			name:    "no assured tasking",
+			req:     planet.CreateTaskingOrderRequest{PLNumber: "PL-1", Product: "Pelican", SchedulingType: planet.SchedulingTypeAssured},
+			wantErr: []string{"not available for assured tasking"},
+		},
+		{
+			name: "every incompatibility is named",
+			req: planet.CreateTaskingOrderRequest{
+				PLNumber: "PL-1", Product: "Pelican",
+				SatelliteTypes: []planet.SatelliteType{planet.SatelliteTypeSkySat, planet.SatelliteTypeTanager},
+				SchedulingType: planet.SchedulingTypeLockIn,
+			},
+			wantErr: []string{"satellite type SKYSAT", "satellite type TANAGER", "scheduling type LOCK_IN"},
+		},
+		{
+			name:    "missing product",
+			req:     planet.CreateTaskingOrderRequest{PLNumber: "PL-1"},
+			wantErr: []string{"product is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cli.ValidateOrderRequest(context.Background(), &tt.req)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateImagingWindowSearch(t *testing.T) {
+	var hits atomic.Int32
+	cli, _ := newTestClient(t, productsHandler(t, &hits))
+
+	err := cli.ValidateImagingWindowSearch(context.Background(), &planet.ImagingWindowSearchRequest{
+		PLNumber:       "PL-1",
+		Product:        "Pelican",
+		SatelliteTypes: []planet.SatelliteType{planet.SatelliteTypeSkySat},
+	})
+	if err == nil || !strings.Contains(err.Error(), "satellite type SKYSAT") {
+		t.Errorf("expected a satellite type incompatibility, got %v", err)
+	}
+}
+
+func TestValidateOrderRequestFailsOpen(t *testing.T) {
+	tests := []struct {
+		name    string
+		results string
+	}{
+		{"capabilities do not decode", `[{"name": "Pelican", "satellite_types": "PELICAN", "scheduling_types": {"FLEXIBLE": true}}]`},
+		{"capabilities missing", `[{"name": "Pelican"}]`},
+		{"names missing", `[{"product_name": "Pelican", "satellite_types": ["PELICAN"]}]`},
+		{"no products", `[]`},
+	}
+	req := &planet.CreateTaskingOrderRequest{
+		PLNumber: "PL-1", Product: "Pelican",
+		SatelliteTypes: []planet.SatelliteType{planet.SatelliteTypeSkySat},
+		SchedulingType: planet.SchedulingTypeAssured,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"results": ` + tt.results + `}`))
+			})
+			if err := cli.ValidateOrderRequest(context.Background(), req); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results": [{"name": "Pelican", "satellite_types": "PELICAN"}]}`))
+	})
+	products, err := cli.ListProducts(context.Background(), "PL-1")
+	if err != nil || len(products) != 1 || products[0].Name != "Pelican" || products[0].SatelliteTypes != nil {
+		t.Errorf("expected the product with only its name, got %+v (err %v)", products, err)
+	}
+}
+
+func TestValidateOrderRequestCache(t *testing.T) {
+	var hits atomic.Int32
+	srv := newProductsServer(t, &hits)
+	req := &planet.CreateTaskingOrderRequest{PLNumber: "PL-1", Product: "SkySat"}
+	ctx := context.Background()
+
+	cli, err := planet.NewClient("test-api-key", planet.WithBaseURL(srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if err := cli.ValidateOrderRequest(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("products fetched %d times, want once", got)
+	}
+
+	// ListProducts always fetches and refreshes the cache.
+	if _, err := cli.ListProducts(ctx, "PL-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.ValidateOrderRequest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("products fetched %d times, want 2", got)
+	}
+
+	// Expired entries are fetched again.
+	hits.Store(0)
+	cli, err = planet.NewClient("test-api-key", planet.WithBaseURL(srv), planet.WithProductsTTL(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.ValidateOrderRequest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := cli.ValidateOrderRequest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("products fetched %d times after expiry, want 2", got)
+	}
+}
+
+func newProductsServer(t *testing.T, hits *atomic.Int32) string {
+	t.Helper()
+	_, srv := newTestClient(t, productsHandler(t, hits))
+	return srv.URL
+}
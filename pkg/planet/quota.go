@@ -0,0 +1,95 @@
+package planet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// QuotaUsage reports the tasking quota of one PL number, in the same units
+// as TaskingOrderPricing.EstimatedQuotaCost.
+type QuotaUsage struct {
+	PLNumber  string  `json:"pl_number"`
+	Product   string  `json:"product,omitempty"`
+	Units     string  `json:"units"`
+	Allocated float64 `json:"allocated"`
+	Used      float64 `json:"used"`
+	Remaining float64 `json:"remaining"`
+}
+
+// QuotaExceededError is returned by CreateTaskingOrderWithQuotaCheck when an
+// order's estimated cost would leave less than the required quota. The order
+// is not submitted.
+type QuotaExceededError struct {
+	PLNumber          string
+	Units             string
+	Remaining         float64
+	EstimatedCost     float64
+	MinRemainingAfter float64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("order would cost %.2f %s of %.2f remaining on %s, leaving less than %.2f",
+		e.EstimatedCost, e.Units, e.Remaining, e.PLNumber, e.MinRemainingAfter)
+}
+
+// GetQuotaUsage retrieves the allocated, used and remaining quota of a PL
+// number. An account can hold several PL numbers, so plNumber is required;
+// product narrows the figures to one product where quota is split by product.
+// GET /tasking/v2/quota/
+func (c *Client) GetQuotaUsage(ctx context.Context, plNumber, product string) (*QuotaUsage, error) {
+	if plNumber == "" {
+		return nil, errors.New("pl_number is required")
+	}
+	u := c.TaskingURL("quota", "")
+	q := u.Query()
+	q.Set("pl_number", plNumber)
+	if product != "" {
+		q.Set("product", product)
+	}
+	u.RawQuery = q.Encode()
+
+	var usage QuotaUsage
+	if err := c.DoRaw(ctx, http.MethodGet, u, nil, http.StatusOK, &usage); err != nil {
+		return nil, err
+	}
+	if usage.Remaining == 0 && usage.Allocated > usage.Used {
+		usage.Remaining = usage.Allocated - usage.Used
+	}
+	return &usage, nil
+}
+
+// CreateTaskingOrderWithQuotaCheck creates a tasking order only if the quota
+// left on req.PLNumber after paying its estimated cost is at least
+// minRemainingAfter. The estimate comes from PreviewPricing. The check fails
+// closed: if the quota or the estimate cannot be fetched, or they are given
+// in different units, the order is not submitted and the error is returned.
+func (c *Client) CreateTaskingOrderWithQuotaCheck(ctx context.Context, req *CreateTaskingOrderRequest, minRemainingAfter float64) (*TaskingOrder, error) {
+	if req == nil || req.PLNumber == "" {
+		return nil, errors.New("pl_number is required for a quota check")
+	}
+
+	usage, err := c.GetQuotaUsage(ctx, req.PLNumber, req.Product)
+	if err != nil {
+		return nil, fmt.Errorf("get quota usage: %w", err)
+	}
+	pricing, err := c.PreviewPricing(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("preview pricing: %w", err)
+	}
+	if usage.Units != "" && pricing.Units != "" && usage.Units != pricing.Units {
+		return nil, fmt.Errorf("quota is in %s but the estimate is in %s", usage.Units, pricing.Units)
+	}
+
+	if usage.Remaining-pricing.EstimatedQuotaCost < minRemainingAfter {
+		return nil, &QuotaExceededError{
+			PLNumber:          req.PLNumber,
+			Units:             usage.Units,
+			Remaining:         usage.Remaining,
+			EstimatedCost:     pricing.EstimatedQuotaCost,
+			MinRemainingAfter: minRemainingAfter,
+		}
+	}
+	return c.CreateTaskingOrder(ctx, req)
+}
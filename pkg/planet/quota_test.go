@@ -0,0 +1,127 @@
+package planet_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/planet"
+)
+
+// quotaHandler serves quota usage and a pricing estimate; creates counts
+// submitted tasking orders. A pricingStatus other than 200 fails the estimate.
+func quotaHandler(t *testing.T, remaining, cost float64, pricingStatus int, creates *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tasking/v2/quota":
+			requireMethod(t, r, http.MethodGet)
+			if got := r.URL.Query().Get("pl_number"); got != "PL-1" {
+				t.Errorf("expected pl_number=PL-1, got %q", got)
+			}
+			if got := r.URL.Query().Get("product"); got != "SkySat" {
+				t.Errorf("expected product=SkySat, got %q", got)
+			}
+			jsonResponse(w, http.StatusOK, map[string]any{
+				"pl_number": "PL-1",
+				"units":     "sqkm",
+				"allocated": 1000,
+				"used":      1000 - remaining,
+			})
+		case "/tasking/v2/pricing":
+			requireMethod(t, r, http.MethodPost)
+			if pricingStatus != http.StatusOK {
+				jsonResponse(w, pricingStatus, map[string]string{"detail": "pricing unavailable"})
+				return
+			}
+			jsonResponse(w, http.StatusOK, map[string]any{"units": "sqkm", "estimated_quota_cost": cost})
+		case "/tasking/v2/orders":
+			creates.Add(1)
+			jsonResponse(w, http.StatusCreated, map[string]any{"id": "order-1", "status": "RECEIVED"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+}
+
+func quotaOrder() *planet.CreateTaskingOrderRequest {
+	return &planet.CreateTaskingOrderRequest{
+		Name:     "harbor",
+		Geometry: planet.NewPointGeometry(-122.4, 37.8),
+		PLNumber: "PL-1",
+		Product:  "SkySat",
+	}
+}
+
+func TestGetQuotaUsage(t *testing.T) {
+	var creates atomic.Int32
+	cli, _ := newTestClient(t, quotaHandler(t, 250, 0, http.StatusOK, &creates))
+
+	usage, err := cli.GetQuotaUsage(context.Background(), "PL-1", "SkySat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Allocated != 1000 || usage.Used != 750 || usage.Remaining != 250 || usage.Units != "sqkm" {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+
+	if _, err := cli.GetQuotaUsage(context.Background(), "", "SkySat"); err == nil {
+		t.Error("expected error for missing PL number")
+	}
+}
+
+func TestCreateTaskingOrderWithQuotaCheck_Sufficient(t *testing.T) {
+	var creates atomic.Int32
+	cli, _ := newTestClient(t, quotaHandler(t, 250, 100, http.StatusOK, &creates))
+
+	order, err := cli.CreateTaskingOrderWithQuotaCheck(context.Background(), quotaOrder(), 150)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if order.ID != "order-1" || creates.Load() != 1 {
+		t.Errorf("expected one submitted order, got %+v after %d creates", order, creates.Load())
+	}
+}
+
+func TestCreateTaskingOrderWithQuotaCheck_Insufficient(t *testing.T) {
+	var creates atomic.Int32
+	cli, _ := newTestClient(t, quotaHandler(t, 250, 100, http.StatusOK, &creates))
+
+	_, err := cli.CreateTaskingOrderWithQuotaCheck(context.Background(), quotaOrder(), 200)
+	var quotaErr *planet.QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("expected *QuotaExceededError, got %v", err)
+	}
+	if quotaErr.Remaining != 250 || quotaErr.EstimatedCost != 100 || quotaErr.PLNumber != "PL-1" {
+		t.Errorf("unexpected quota error: %+v", quotaErr)
+	}
+	if creates.Load() != 0 {
+		t.Error("order must not be submitted when quota is insufficient")
+	}
+}
+
+func TestCreateTaskingOrderWithQuotaCheck_EstimateFailure(t *testing.T) {
+	var creates atomic.Int32
+	cli, _ := newTestClient(t, quotaHandler(t, 250, 100, http.StatusServiceUnavailable, &creates))
+
+	_, err := cli.CreateTaskingOrderWithQuotaCheck(context.Background(), quotaOrder(), 0)
+	if !planet.IsServerError(err) {
+		t.Fatalf("expected the pricing error to be returned, got %v", err)
+	}
+	if creates.Load() != 0 {
+		t.Error("order must not be submitted when the estimate fails")
+	}
+}
+
+func TestCreateTaskingOrderWithQuotaCheck_RequiresPLNumber(t *testing.T) {
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+
+	req := quotaOrder()
+	req.PLNumber = ""
+	if _, err := cli.CreateTaskingOrderWithQuotaCheck(context.Background(), req, 0); err == nil {
+		t.Error("expected error for missing PL number")
+	}
+}
@@ -0,0 +1,520 @@
+package planet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// =============================================================================
+// Subscriptions API v1 Types
+// =============================================================================
+
+// SubscriptionStatus represents the status of a subscription.
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusPreparing SubscriptionStatus = "preparing"
+	SubscriptionStatusPending   SubscriptionStatus = "pending"
+	SubscriptionStatusRunning   SubscriptionStatus = "running"
+	SubscriptionStatusSuspended SubscriptionStatus = "suspended"
+	SubscriptionStatusCompleted SubscriptionStatus = "completed"
+	SubscriptionStatusCancelled SubscriptionStatus = "cancelled"
+	SubscriptionStatusFailed    SubscriptionStatus = "failed"
+)
+
+// IsTerminal returns true if the subscription status is a terminal state.
+func (s SubscriptionStatus) IsTerminal() bool {
+	switch s {
+	case SubscriptionStatusCompleted, SubscriptionStatusCancelled, SubscriptionStatusFailed:
+		return true
+	}
+	return false
+}
+
+// SubscriptionResultStatus represents the status of a subscription result.
+type SubscriptionResultStatus string
+
+const (
+	SubscriptionResultStatusCreated    SubscriptionResultStatus = "created"
+	SubscriptionResultStatusQueued     SubscriptionResultStatus = "queued"
+	SubscriptionResultStatusProcessing SubscriptionResultStatus = "processing"
+	SubscriptionResultStatusSuccess    SubscriptionResultStatus = "success"
+	SubscriptionResultStatusFailed     SubscriptionResultStatus = "failed"
+	SubscriptionResultStatusCancelled  SubscriptionResultStatus = "cancelled"
+)
+
+// IsTerminal returns true if the result status is a terminal state.
+func (s SubscriptionResultStatus) IsTerminal() bool {
+	switch s {
+	case SubscriptionResultStatusSuccess, SubscriptionResultStatusFailed, SubscriptionResultStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// Subscription represents a subscription that continuously delivers new
+// imagery matching its source. It is used both to create and update
+// subscriptions and to read them back; ID, Status, Created and Updated are
+// set by the API.
+//
+// Delivery and Hosting reuse the Orders API types; they are converted to the
+// typed form the Subscriptions API expects when marshaled.
+type Subscription struct {
+	ID       string             `json:"id,omitempty"`
+	Name     string             `json:"name"`
+	Source   SubscriptionSource `json:"source"`
+	Tools    []Tool             `json:"tools,omitempty"`
+	Delivery *DeliveryConfig    `json:"-"`
+	Hosting  *HostingConfig     `json:"-"`
+	Status   SubscriptionStatus `json:"status,omitempty"`
+	Created  *time.Time         `json:"created,omitempty"`
+	Updated  *time.Time         `json:"updated,omitempty"`
+}
+
+// SubscriptionSource selects the imagery a subscription delivers.
+type SubscriptionSource struct {
+	Type       string                       `json:"type,omitempty"` // "catalog" (default)
+	Parameters SubscriptionSourceParameters `json:"parameters"`
+}
+
+// SubscriptionSourceParameters holds the catalog source parameters.
+type SubscriptionSourceParameters struct {
+	ItemTypes        []string          `json:"item_types"`
+	AssetTypes       []string          `json:"asset_types"`
+	Geometry         *geojson.Geometry `json:"geometry"`
+	StartTime        time.Time         `json:"start_time"`
+	EndTime          *time.Time        `json:"end_time,omitempty"`
+	TimeRangeType    string            `json:"time_range_type,omitempty"` // "acquired" or "published"
+	RRule            string            `json:"rrule,omitempty"`
+	Filter           *Filter           `json:"filter,omitempty"`
+	PublishingStages []string          `json:"publishing_stages,omitempty"`
+}
+
+// typedConfig is the {"type", "parameters"} form the Subscriptions API uses
+// for delivery and hosting.
+type typedConfig struct {
+	Type       string          `json:"type"`
+	Parameters json.RawMessage `json:"parameters"`
+}
+
+// subscriptionJSON has the fields of Subscription but not its JSON methods.
+type subscriptionJSON Subscription
+
+// MarshalJSON encodes the subscription with its delivery and hosting in the
+// Subscriptions API form.
+func (s Subscription) MarshalJSON() ([]byte, error) {
+	out := struct {
+		subscriptionJSON
+		Delivery *typedConfig `json:"delivery,omitempty"`
+		Hosting  *typedConfig `json:"hosting,omitempty"`
+	}{subscriptionJSON: subscriptionJSON(s)}
+
+	var err error
+	if out.Delivery, err = subscriptionDelivery(s.Delivery); err != nil {
+		return nil, err
+	}
+	if out.Hosting, err = subscriptionHosting(s.Hosting); err != nil {
+		return nil, err
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a subscription, converting its delivery and hosting
+// to the Orders API types.
+func (s *Subscription) UnmarshalJSON(data []byte) error {
+	var in struct {
+		subscriptionJSON
+		Delivery *typedConfig `json:"delivery,omitempty"`
+		Hosting  *typedConfig `json:"hosting,omitempty"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*s = Subscription(in.subscriptionJSON)
+
+	// The provider names match the DeliveryConfig and HostingConfig keys.
+	if in.Delivery != nil {
+		s.Delivery = new(DeliveryConfig)
+		if err := unmarshalTyped(in.Delivery, s.Delivery); err != nil {
+			return fmt.Errorf("decode delivery: %w", err)
+		}
+	}
+	if in.Hosting != nil {
+		s.Hosting = new(HostingConfig)
+		if err := unmarshalTyped(in.Hosting, s.Hosting); err != nil {
+			return fmt.Errorf("decode hosting: %w", err)
+		}
+	}
+	return nil
+}
+
+// unmarshalTyped decodes a typed config into v, a struct keyed by provider.
+func unmarshalTyped(t *typedConfig, v any) error {
+	keyed, err := json.Marshal(map[string]json.RawMessage{t.Type: t.Parameters})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(keyed, v)
+}
+
+// subscriptionDelivery converts an Orders API delivery config to the typed
+// form. Exactly one destination must be set; archive options are not
+// supported by subscriptions.
+func subscriptionDelivery(d *DeliveryConfig) (*typedConfig, error) {
+	if d == nil {
+		return nil, nil
+	}
+	if d.ArchiveType != "" || d.ArchiveFilename != "" || d.SingleArchive {
+		return nil, errors.New("subscription delivery does not support archive options")
+	}
+	return oneTyped("delivery", map[string]any{
+		"amazon_s3":            d.AmazonS3,
+		"azure_blob_storage":   d.AzureBlobStorage,
+		"google_cloud_storage": d.GoogleCloudStorage,
+		"google_earth_engine":  d.GoogleEarthEngine,
+		"oracle_cloud_storage": d.OracleCloudStorage,
+		"destination":          d.Destination,
+	})
+}
+
+// subscriptionHosting converts a hosting config to the typed form.
+func subscriptionHosting(h *HostingConfig) (*typedConfig, error) {
+	if h == nil {
+		return nil, nil
+	}
+	return oneTyped("hosting", map[string]any{"sentinel_hub": h.SentinelHub})
+}
+
+// oneTyped returns the typed config of the single non-nil provider.
+func oneTyped(what string, providers map[string]any) (*typedConfig, error) {
+	var out *typedConfig
+	for typ, p := range providers {
+		if isNilProvider(p) {
+			continue
+		}
+		if out != nil {
+			return nil, fmt.Errorf("subscription %s must have exactly one destination", what)
+		}
+		b, err := json.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+		out = &typedConfig{Type: typ, Parameters: b}
+	}
+	if out == nil {
+		return nil, fmt.Errorf("subscription %s has no destination", what)
+	}
+	return out, nil
+}
+
+// isNilProvider reports whether p is a nil pointer of one of the provider
+// types.
+func isNilProvider(p any) bool {
+	switch v := p.(type) {
+	case *AmazonS3Delivery:
+		return v == nil
+	case *AzureBlobStorageDelivery:
+		return v == nil
+	case *GoogleCloudStorageDelivery:
+		return v == nil
+	case *GoogleEarthEngineDelivery:
+		return v == nil
+	case *OracleCloudStorageDelivery:
+		return v == nil
+	case *DestinationRef:
+		return v == nil
+	case *SentinelHubHosting:
+		return v == nil
+	}
+	return p == nil
+}
+
+// SubscriptionResult is an item delivered (or being delivered) by a
+// subscription.
+type SubscriptionResult struct {
+	ID         string                       `json:"id"`
+	Status     SubscriptionResultStatus     `json:"status"`
+	Properties SubscriptionResultProperties `json:"properties"`
+	Created    time.Time                    `json:"created"`
+	Updated    time.Time                    `json:"updated"`
+	Completed  *time.Time                   `json:"completed,omitempty"`
+	Outputs    []string                     `json:"outputs,omitempty"`
+	Errors     map[string]any               `json:"errors,omitempty"`
+}
+
+// SubscriptionResultProperties identifies the item a result delivers.
+type SubscriptionResultProperties struct {
+	ItemID    string   `json:"item_id"`
+	ItemTypes []string `json:"item_types,omitempty"`
+}
+
+// ListSubscriptionsOptions represents options for listing subscriptions.
+type ListSubscriptionsOptions struct {
+	Status []SubscriptionStatus `url:"status,omitempty"`
+	Limit  int                  `url:"limit,omitempty"`
+}
+
+// ListSubscriptionResultsOptions represents options for listing the results
+// of a subscription.
+type ListSubscriptionResultsOptions struct {
+	Status  []SubscriptionResultStatus `url:"status,omitempty"`
+	Created string                     `url:"created,omitempty"` // RFC 3339 interval, e.g. "2024-01-01T00:00:00Z/.."
+	Limit   int                        `url:"limit,omitempty"`
+}
+
+// =============================================================================
+// Data API Filters
+// =============================================================================
+
+// Filter types of the Planet Data API filter language.
+const (
+	FilterTypeAnd       = "AndFilter"
+	FilterTypeOr        = "OrFilter"
+	FilterTypeNot       = "NotFilter"
+	FilterTypeDateRange = "DateRangeFilter"
+	FilterTypeRange     = "RangeFilter"
+	FilterTypeStringIn  = "StringInFilter"
+)
+
+// Filter is a Data API search filter, as used by subscription sources. Config
+// holds []Filter for logical filters, *DateRangeConfig, *RangeConfig, or
+// []string for StringInFilter; other filter types keep their raw config.
+type Filter struct {
+	Type      string `json:"type"`
+	FieldName string `json:"field_name,omitempty"`
+	Config    any    `json:"config"`
+}
+
+// DateRangeConfig bounds a DateRangeFilter.
+type DateRangeConfig struct {
+	GT  *time.Time `json:"gt,omitempty"`
+	GTE *time.Time `json:"gte,omitempty"`
+	LT  *time.Time `json:"lt,omitempty"`
+	LTE *time.Time `json:"lte,omitempty"`
+}
+
+// RangeConfig bounds a RangeFilter.
+type RangeConfig struct {
+	GT  *float64 `json:"gt,omitempty"`
+	GTE *float64 `json:"gte,omitempty"`
+	LT  *float64 `json:"lt,omitempty"`
+	LTE *float64 `json:"lte,omitempty"`
+}
+
+// AndFilter matches items matching all filters.
+func AndFilter(filters ...Filter) Filter {
+	return Filter{Type: FilterTypeAnd, Config: filters}
+}
+
+// OrFilter matches items matching any of the filters.
+func OrFilter(filters ...Filter) Filter {
+	return Filter{Type: FilterTypeOr, Config: filters}
+}
+
+// DateRangeFilter matches items whose field lies in [from, to]. A zero time
+// leaves that side open.
+func DateRangeFilter(field string, from, to time.Time) Filter {
+	cfg := &DateRangeConfig{}
+	if !from.IsZero() {
+		cfg.GTE = &from
+	}
+	if !to.IsZero() {
+		cfg.LTE = &to
+	}
+	return Filter{Type: FilterTypeDateRange, FieldName: field, Config: cfg}
+}
+
+// RangeFilter matches items whose numeric field lies in [min, max].
+func RangeFilter(field string, min, max float64) Filter {
+	return Filter{Type: FilterTypeRange, FieldName: field, Config: &RangeConfig{GTE: &min, LTE: &max}}
+}
+
+// StringInFilter matches items whose field is one of values.
+func StringInFilter(field string, values ...string) Filter {
+	return Filter{Type: FilterTypeStringIn, FieldName: field, Config: values}
+}
+
+// UnmarshalJSON decodes the config according to the filter type.
+func (f *Filter) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type      string          `json:"type"`
+		FieldName string          `json:"field_name,omitempty"`
+		Config    json.RawMessage `json:"config"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	f.Type, f.FieldName = raw.Type, raw.FieldName
+
+	var cfg any
+	switch raw.Type {
+	case FilterTypeAnd, FilterTypeOr:
+		cfg = &[]Filter{}
+	case FilterTypeNot:
+		cfg = &Filter{}
+	case FilterTypeDateRange:
+		cfg = &DateRangeConfig{}
+	case FilterTypeRange:
+		cfg = &RangeConfig{}
+	case FilterTypeStringIn:
+		cfg = &[]string{}
+	default:
+		f.Config = raw.Config
+		return nil
+	}
+	if err := json.Unmarshal(raw.Config, cfg); err != nil {
+		return fmt.Errorf("decode %s config: %w", raw.Type, err)
+	}
+	switch v := cfg.(type) {
+	case *[]Filter:
+		f.Config = *v
+	case *[]string:
+		f.Config = *v
+	case *Filter:
+		f.Config = *v
+	default:
+		f.Config = v
+	}
+	return nil
+}
+
+// =============================================================================
+// Subscriptions API v1 Methods
+// =============================================================================
+
+// ErrSubscriptionTerminal is returned by CancelSubscription when the
+// subscription has already completed or failed and can no longer be
+// cancelled.
+var ErrSubscriptionTerminal = errors.New("subscription is in a terminal state")
+
+// CreateSubscription creates a new subscription.
+// POST /subscriptions/v1
+func (c *Client) CreateSubscription(ctx context.Context, sub *Subscription) (*Subscription, error) {
+	body, err := common.MarshalBody(sub)
+	if err != nil {
+		return nil, err
+	}
+	var out Subscription
+	err = c.DoRaw(ctx, http.MethodPost, c.SubscriptionsURL(), body, common.StatusAny2xx, &out)
+	return &out, err
+}
+
+// GetSubscription retrieves a subscription by ID.
+// GET /subscriptions/v1/{id}
+func (c *Client) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	var out Subscription
+	err := c.DoRaw(ctx, http.MethodGet, c.SubscriptionsURL(id), nil, http.StatusOK, &out)
+	return &out, err
+}
+
+// UpdateSubscription replaces a subscription's definition.
+// PUT /subscriptions/v1/{id}
+func (c *Client) UpdateSubscription(ctx context.Context, id string, sub *Subscription) (*Subscription, error) {
+	body, err := common.MarshalBody(sub)
+	if err != nil {
+		return nil, err
+	}
+	var out Subscription
+	err = c.DoRaw(ctx, http.MethodPut, c.SubscriptionsURL(id), body, http.StatusOK, &out)
+	return &out, err
+}
+
+// CancelSubscription cancels a subscription. Cancelling an already cancelled
+// subscription succeeds; a subscription that has completed or failed cannot
+// be cancelled and yields an error wrapping ErrSubscriptionTerminal.
+// POST /subscriptions/v1/{id}/cancel
+func (c *Client) CancelSubscription(ctx context.Context, id string) error {
+	err := c.DoRaw(ctx, http.MethodPost, c.SubscriptionsURL(id, "cancel"), nil, common.StatusAny2xx, nil)
+	if !common.IsConflict(err) {
+		return err
+	}
+
+	// The API rejects cancelling a subscription that is no longer active.
+	sub, getErr := c.GetSubscription(ctx, id)
+	if getErr != nil {
+		return err
+	}
+	switch {
+	case sub.Status == SubscriptionStatusCancelled:
+		return nil
+	case sub.Status.IsTerminal():
+		return fmt.Errorf("cancel subscription %s: already %s: %w", id, sub.Status, ErrSubscriptionTerminal)
+	}
+	return err
+}
+
+// ListSubscriptions retrieves all subscriptions with optional filtering.
+// Returns an iterator that handles pagination automatically.
+// GET /subscriptions/v1
+func (c *Client) ListSubscriptions(ctx context.Context, opts *ListSubscriptionsOptions) iter.Seq2[Subscription, error] {
+	u := c.SubscriptionsURL()
+	q := u.Query()
+	limit := defaultSearchLimit
+	if opts != nil {
+		if opts.Limit > 0 {
+			limit = opts.Limit
+		}
+		for _, s := range opts.Status {
+			q.Add("status", string(s))
+		}
+	}
+	q.Set("limit", strconv.Itoa(limit))
+	u.RawQuery = q.Encode()
+
+	return listPages(ctx, c, u, func(u *url.URL) ([]Subscription, string, error) {
+		var resp struct {
+			Subscriptions []Subscription    `json:"subscriptions"`
+			Links         subscriptionLinks `json:"_links"`
+		}
+		err := c.DoRaw(ctx, http.MethodGet, u, nil, http.StatusOK, &resp)
+		return resp.Subscriptions, resp.Links.Next, err
+	})
+}
+
+// ListSubscriptionResults retrieves the results delivered by a subscription,
+// optionally filtered by status. Returns an iterator that handles pagination
+// automatically.
+// GET /subscriptions/v1/{id}/results
+func (c *Client) ListSubscriptionResults(ctx context.Context, id string, opts *ListSubscriptionResultsOptions) iter.Seq2[SubscriptionResult, error] {
+	u := c.SubscriptionsURL(id, "results")
+	q := u.Query()
+	limit := defaultSearchLimit
+	if opts != nil {
+		if opts.Limit > 0 {
+			limit = opts.Limit
+		}
+		for _, s := range opts.Status {
+			q.Add("status", string(s))
+		}
+		if opts.Created != "" {
+			q.Set("created", opts.Created)
+		}
+	}
+	q.Set("limit", strconv.Itoa(limit))
+	u.RawQuery = q.Encode()
+
+	return listPages(ctx, c, u, func(u *url.URL) ([]SubscriptionResult, string, error) {
+		var resp struct {
+			Results []SubscriptionResult `json:"results"`
+			Links   subscriptionLinks    `json:"_links"`
+		}
+		err := c.DoRaw(ctx, http.MethodGet, u, nil, http.StatusOK, &resp)
+		return resp.Results, resp.Links.Next, err
+	})
+}
+
+// subscriptionLinks holds the pagination links of subscriptions responses.
+type subscriptionLinks struct {
+	Self string `json:"_self,omitempty"`
+	Next string `json:"next,omitempty"`
+}
@@ -0,0 +1,225 @@
+package planet_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/planet"
+)
+
+func testSubscription() *planet.Subscription {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &planet.Subscription{
+		Name: "harbor watch",
+		Source: planet.SubscriptionSource{
+			Type: "catalog",
+			Parameters: planet.SubscriptionSourceParameters{
+				ItemTypes:  []string{"PSScene"},
+				AssetTypes: []string{"ortho_analytic_4b"},
+				Geometry:   geojson.NewGeometry(orb.Point{-122.4, 37.8}),
+				StartTime:  start,
+				Filter: ptr(planet.AndFilter(
+					planet.RangeFilter("cloud_cover", 0, 0.2),
+					planet.StringInFilter("instrument", "PSB.SD"),
+				)),
+			},
+		},
+		Delivery: &planet.DeliveryConfig{
+			AmazonS3: &planet.AmazonS3Delivery{Bucket: "bucket", AWSRegion: "us-east-1"},
+		},
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// decodeBody decodes a request body into a generic map.
+func decodeBody(t *testing.T, r *http.Request) map[string]any {
+	t.Helper()
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	return m
+}
+
+func TestCreateSubscription(t *testing.T) {
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPost)
+		requirePath(t, r, "/subscriptions/v1")
+		body := decodeBody(t, r)
+
+		delivery := body["delivery"].(map[string]any)
+		if delivery["type"] != "amazon_s3" {
+			t.Errorf("expected delivery type amazon_s3, got %v", delivery["type"])
+		}
+		if params := delivery["parameters"].(map[string]any); params["bucket"] != "bucket" {
+			t.Errorf("expected bucket in delivery parameters, got %v", params)
+		}
+		if _, ok := body["hosting"]; ok {
+			t.Error("expected no hosting")
+		}
+
+		src := body["source"].(map[string]any)["parameters"].(map[string]any)
+		filter := src["filter"].(map[string]any)
+		if filter["type"] != planet.FilterTypeAnd {
+			t.Errorf("expected AndFilter, got %v", filter["type"])
+		}
+		nested := filter["config"].([]any)
+		if len(nested) != 2 {
+			t.Fatalf("expected 2 nested filters, got %d", len(nested))
+		}
+		rng := nested[0].(map[string]any)
+		if rng["field_name"] != "cloud_cover" || rng["config"].(map[string]any)["lte"] != 0.2 {
+			t.Errorf("unexpected range filter: %v", rng)
+		}
+		if in := nested[1].(map[string]any); in["config"].([]any)[0] != "PSB.SD" {
+			t.Errorf("unexpected string filter: %v", in)
+		}
+
+		body["id"] = "sub-1"
+		body["status"] = "preparing"
+		jsonResponse(w, http.StatusOK, body)
+	})
+
+	sub, err := cli.CreateSubscription(context.Background(), testSubscription())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sub.ID != "sub-1" || sub.Status != planet.SubscriptionStatusPreparing {
+		t.Errorf("unexpected subscription: %+v", sub)
+	}
+	if sub.Delivery == nil || sub.Delivery.AmazonS3 == nil || sub.Delivery.AmazonS3.Bucket != "bucket" {
+		t.Errorf("expected S3 delivery to round-trip, got %+v", sub.Delivery)
+	}
+	f := sub.Source.Parameters.Filter
+	if f == nil || f.Type != planet.FilterTypeAnd {
+		t.Fatalf("expected AndFilter to round-trip, got %+v", f)
+	}
+	nested, ok := f.Config.([]planet.Filter)
+	if !ok || len(nested) != 2 {
+		t.Fatalf("expected 2 nested filters, got %#v", f.Config)
+	}
+	if rc, ok := nested[0].Config.(*planet.RangeConfig); !ok || rc.LTE == nil || *rc.LTE != 0.2 {
+		t.Errorf("unexpected range config: %#v", nested[0].Config)
+	}
+}
+
+func TestUpdateSubscription(t *testing.T) {
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPut)
+		requirePath(t, r, "/subscriptions/v1/sub-1")
+		body := decodeBody(t, r)
+		hosting := body["hosting"].(map[string]any)
+		if hosting["type"] != "sentinel_hub" {
+			t.Errorf("expected hosting type sentinel_hub, got %v", hosting["type"])
+		}
+		if _, ok := body["delivery"]; ok {
+			t.Error("expected no delivery")
+		}
+		jsonResponse(w, http.StatusOK, body)
+	})
+
+	sub := testSubscription()
+	sub.Delivery = nil
+	sub.Hosting = &planet.HostingConfig{SentinelHub: &planet.SentinelHubHosting{CollectionID: "col-1"}}
+	got, err := cli.UpdateSubscription(context.Background(), "sub-1", sub)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Hosting == nil || got.Hosting.SentinelHub == nil || got.Hosting.SentinelHub.CollectionID != "col-1" {
+		t.Errorf("expected hosting to round-trip, got %+v", got.Hosting)
+	}
+}
+
+func TestSubscription_MarshalRejectsArchive(t *testing.T) {
+	sub := testSubscription()
+	sub.Delivery.ArchiveType = "zip"
+	if _, err := json.Marshal(sub); err == nil {
+		t.Error("expected archive options to be rejected")
+	}
+}
+
+func TestListSubscriptionResults(t *testing.T) {
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodGet)
+		requirePath(t, r, "/subscriptions/v1/sub-1/results")
+		if got := r.URL.Query()["status"]; len(got) != 1 || got[0] != "success" {
+			t.Errorf("expected status=success, got %v", got)
+		}
+		if r.URL.Query().Get("page") == "" {
+			jsonResponse(w, http.StatusOK, map[string]any{
+				"results": []map[string]any{
+					{"id": "r-1", "status": "success", "properties": map[string]any{"item_id": "item-1"}},
+					{"id": "r-2", "status": "success", "properties": map[string]any{"item_id": "item-2"}},
+				},
+				"_links": map[string]any{"next": "/subscriptions/v1/sub-1/results?status=success&page=2"},
+			})
+			return
+		}
+		jsonResponse(w, http.StatusOK, map[string]any{
+			"results": []map[string]any{
+				{"id": "r-3", "status": "success", "properties": map[string]any{"item_id": "item-3"}},
+			},
+			"_links": map[string]any{},
+		})
+	})
+
+	var items []string
+	opts := &planet.ListSubscriptionResultsOptions{Status: []planet.SubscriptionResultStatus{planet.SubscriptionResultStatusSuccess}}
+	for res, err := range cli.ListSubscriptionResults(context.Background(), "sub-1", opts) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		items = append(items, res.Properties.ItemID)
+	}
+	if len(items) != 3 || items[2] != "item-3" {
+		t.Errorf("expected 3 results across pages, got %v", items)
+	}
+}
+
+func TestCancelSubscription(t *testing.T) {
+	tests := []struct {
+		name   string
+		status planet.SubscriptionStatus
+		check  func(t *testing.T, err error)
+	}{
+		{"already cancelled", planet.SubscriptionStatusCancelled, func(t *testing.T, err error) {
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+		}},
+		{"completed", planet.SubscriptionStatusCompleted, func(t *testing.T, err error) {
+			if !errors.Is(err, planet.ErrSubscriptionTerminal) {
+				t.Errorf("expected ErrSubscriptionTerminal, got %v", err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/subscriptions/v1/sub-1/cancel":
+					requireMethod(t, r, http.MethodPost)
+					errorResponse(w, http.StatusConflict, "subscription cannot be cancelled")
+				case "/subscriptions/v1/sub-1":
+					requireMethod(t, r, http.MethodGet)
+					jsonResponse(w, http.StatusOK, map[string]any{"id": "sub-1", "status": tt.status})
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			})
+			tt.check(t, cli.CancelSubscription(context.Background(), "sub-1"))
+		})
+	}
+}
@@ -2,6 +2,7 @@ package planet
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"net/http"
@@ -53,88 +54,60 @@ func (c *Client) CancelTaskingOrder(ctx context.Context, id string) error {
 // Returns an iterator that handles pagination automatically.
 // GET /tasking/v2/orders/
 func (c *Client) ListTaskingOrders(ctx context.Context, opts *ListTaskingOrdersOptions) iter.Seq2[TaskingOrder, error] {
-	return func(yield func(TaskingOrder, error) bool) {
-		u := c.TaskingURL("orders", "")
-
-		for {
-			// Build query parameters
-			q := u.Query()
-			if opts != nil {
-				if opts.Limit > 0 {
-					q.Set("limit", fmt.Sprintf("%d", opts.Limit))
-				} else {
-					q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
-				}
-				if opts.Offset > 0 {
-					q.Set("offset", fmt.Sprintf("%d", opts.Offset))
-				}
-				if opts.SchedulingType != "" {
-					q.Set("scheduling_type", string(opts.SchedulingType))
-				}
-				if opts.PLNumber != "" {
-					q.Set("pl_number", opts.PLNumber)
-				}
-				if opts.Product != "" {
-					q.Set("product", opts.Product)
-				}
-				if opts.NameContains != "" {
-					q.Set("name__icontains", opts.NameContains)
-				}
-				if opts.Ordering != "" {
-					q.Set("ordering", opts.Ordering)
-				}
-				if opts.GeometryIntersects != "" {
-					q.Set("geometry__intersects", opts.GeometryIntersects)
-				}
-				for _, s := range opts.Status {
-					q.Add("status__in", string(s))
-				}
-				if opts.CreatedTimeGTE != nil {
-					q.Set("created_time__gte", opts.CreatedTimeGTE.Format(time.RFC3339))
-				}
-				if opts.CreatedTimeLTE != nil {
-					q.Set("created_time__lte", opts.CreatedTimeLTE.Format(time.RFC3339))
-				}
-				if opts.StartTimeGTE != nil {
-					q.Set("start_time__gte", opts.StartTimeGTE.Format(time.RFC3339))
-				}
-				if opts.StartTimeLTE != nil {
-					q.Set("start_time__lte", opts.StartTimeLTE.Format(time.RFC3339))
-				}
-			} else {
-				q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
-			}
-			u.RawQuery = q.Encode()
-
-			var resp paginatedResponse[TaskingOrder]
-			if err := c.DoRaw(ctx, http.MethodGet, u, nil, http.StatusOK, &resp); err != nil {
-				var zero TaskingOrder
-				yield(zero, err)
-				return
-			}
-
-			for _, order := range resp.Results {
-				if !yield(order, nil) {
-					return
-				}
-			}
-
-			// Check if there are more pages
-			if resp.Next == "" {
-				return
-			}
-
-			// Parse the next URL for the next iteration
-			nextURL, err := url.Parse(resp.Next)
-			if err != nil {
-				var zero TaskingOrder
-				yield(zero, err)
-				return
-			}
-			u = nextURL
-			opts = nil // Use the next URL directly, don't rebuild params
+	u := c.TaskingURL("orders", "")
+	q := u.Query()
+	if opts != nil {
+		if opts.Limit > 0 {
+			q.Set("limit", fmt.Sprintf("%d", opts.Limit))
+		} else {
+			q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
+		}
+		if opts.Offset > 0 {
+			q.Set("offset", fmt.Sprintf("%d", opts.Offset))
+		}
+		if opts.SchedulingType != "" {
+			q.Set("scheduling_type", string(opts.SchedulingType))
+		}
+		if opts.PLNumber != "" {
+			q.Set("pl_number", opts.PLNumber)
+		}
+		if opts.Product != "" {
+			q.Set("product", opts.Product)
+		}
+		if opts.NameContains != "" {
+			q.Set("name__icontains", opts.NameContains)
+		}
+		if opts.Ordering != "" {
+			q.Set("ordering", opts.Ordering)
+		}
+		if opts.GeometryIntersects != "" {
+			q.Set("geometry__intersects", opts.GeometryIntersects)
+		}
+		for _, s := range opts.Status {
+			q.Add("status__in", string(s))
 		}
+		if opts.CreatedTimeGTE != nil {
+			q.Set("created_time__gte", opts.CreatedTimeGTE.Format(time.RFC3339))
+		}
+		if opts.CreatedTimeLTE != nil {
+			q.Set("created_time__lte", opts.CreatedTimeLTE.Format(time.RFC3339))
+		}
+		if opts.StartTimeGTE != nil {
+			q.Set("start_time__gte", opts.StartTimeGTE.Format(time.RFC3339))
+		}
+		if opts.StartTimeLTE != nil {
+			q.Set("start_time__lte", opts.StartTimeLTE.Format(time.RFC3339))
+		}
+	} else {
+		q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
 	}
+	u.RawQuery = q.Encode()
+
+	return listPages(ctx, c, u, func(u *url.URL) ([]TaskingOrder, string, error) {
+		var resp paginatedResponse[TaskingOrder]
+		err := c.DoRaw(ctx, http.MethodGet, u, nil, http.StatusOK, &resp)
+		return resp.Results, resp.Next, err
+	})
 }
 
 // GetTaskingOrderPricing retrieves pricing for a tasking order.
@@ -169,64 +142,39 @@ func (c *Client) GetCapture(ctx context.Context, id string) (*Capture, error) {
 // Returns an iterator that handles pagination automatically.
 // GET /tasking/v2/captures/
 func (c *Client) ListCaptures(ctx context.Context, opts *ListCapturesOptions) iter.Seq2[Capture, error] {
-	return func(yield func(Capture, error) bool) {
-		u := c.TaskingURL("captures", "")
-
-		for {
-			q := u.Query()
-			if opts != nil {
-				if opts.Limit > 0 {
-					q.Set("limit", fmt.Sprintf("%d", opts.Limit))
-				} else {
-					q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
-				}
-				if opts.Offset > 0 {
-					q.Set("offset", fmt.Sprintf("%d", opts.Offset))
-				}
-				if opts.OrderID != "" {
-					q.Set("order_id", opts.OrderID)
-				}
-				if opts.Ordering != "" {
-					q.Set("ordering", opts.Ordering)
-				}
-				if opts.Fulfilling != nil {
-					q.Set("fulfilling", fmt.Sprintf("%t", *opts.Fulfilling))
-				}
-				for _, s := range opts.Status {
-					q.Add("status__in", string(s))
-				}
-			} else {
-				q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
-			}
-			u.RawQuery = q.Encode()
-
-			var resp paginatedResponse[Capture]
-			if err := c.DoRaw(ctx, http.MethodGet, u, nil, http.StatusOK, &resp); err != nil {
-				var zero Capture
-				yield(zero, err)
-				return
-			}
-
-			for _, capture := range resp.Results {
-				if !yield(capture, nil) {
-					return
-				}
-			}
-
-			if resp.Next == "" {
-				return
-			}
-
-			nextURL, err := url.Parse(resp.Next)
-			if err != nil {
-				var zero Capture
-				yield(zero, err)
-				return
-			}
-			u = nextURL
-			opts = nil
+	u := c.TaskingURL("captures", "")
+	q := u.Query()
+	if opts != nil {
+		if opts.Limit > 0 {
+			q.Set("limit", fmt.Sprintf("%d", opts.Limit))
+		} else {
+			q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
+		}
+		if opts.Offset > 0 {
+			q.Set("offset", fmt.Sprintf("%d", opts.Offset))
+		}
+		if opts.OrderID != "" {
+			q.Set("order_id", opts.OrderID)
 		}
+		if opts.Ordering != "" {
+			q.Set("ordering", opts.Ordering)
+		}
+		if opts.Fulfilling != nil {
+			q.Set("fulfilling", fmt.Sprintf("%t", *opts.Fulfilling))
+		}
+		for _, s := range opts.Status {
+			q.Add("status__in", string(s))
+		}
+	} else {
+		q.Set("limit", fmt.Sprintf("%d", defaultSearchLimit))
 	}
+	u.RawQuery = q.Encode()
+
+	return listPages(ctx, c, u, func(u *url.URL) ([]Capture, string, error) {
+		var resp paginatedResponse[Capture]
+		err := c.DoRaw(ctx, http.MethodGet, u, nil, http.StatusOK, &resp)
+		return resp.Results, resp.Next, err
+	})
 }
 
 // WaitForTaskingOrder polls until the tasking order reaches a terminal state.
@@ -238,30 +186,22 @@ func (c *Client) WaitForTaskingOrder(ctx context.Context, id string, opts *WaitO
 		}
 	}
 
-	deadline := time.Now().Add(opts.Timeout)
-	ticker := time.NewTicker(opts.PollInterval)
-	defer ticker.Stop()
-
-	for {
-		order, err := c.GetTaskingOrder(ctx, id)
+	var order *TaskingOrder
+	err := common.Poll(ctx, *opts, func(ctx context.Context) (bool, error) {
+		var err error
+		order, err = c.GetTaskingOrder(ctx, id)
 		if err != nil {
-			return nil, err
-		}
-
-		if order.Status.IsTerminal() {
-			return order, nil
-		}
-
-		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("timeout waiting for tasking order %s to reach terminal state", id)
-		}
-
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
+			return false, err
 		}
+		return order.Status.IsTerminal(), nil
+	})
+	if errors.Is(err, common.ErrWaitTimeout) {
+		return nil, fmt.Errorf("timeout waiting for tasking order %s to reach terminal state: %w", id, err)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return order, nil
 }
 
 // WaitForTaskingOrderFulfilled polls until the tasking order is fulfilled or reaches a terminal state.
@@ -273,28 +213,20 @@ func (c *Client) WaitForTaskingOrderFulfilled(ctx context.Context, id string, op
 		}
 	}
 
-	deadline := time.Now().Add(opts.Timeout)
-	ticker := time.NewTicker(opts.PollInterval)
-	defer ticker.Stop()
-
-	for {
-		order, err := c.GetTaskingOrder(ctx, id)
+	var order *TaskingOrder
+	err := common.Poll(ctx, *opts, func(ctx context.Context) (bool, error) {
+		var err error
+		order, err = c.GetTaskingOrder(ctx, id)
 		if err != nil {
-			return nil, err
-		}
-
-		if order.Status == TaskingOrderStatusFulfilled || order.Status.IsTerminal() {
-			return order, nil
-		}
-
-		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("timeout waiting for tasking order %s to be fulfilled", id)
-		}
-
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
+			return false, err
 		}
+		return order.Status == TaskingOrderStatusFulfilled || order.Status.IsTerminal(), nil
+	})
+	if errors.Is(err, common.ErrWaitTimeout) {
+		return nil, fmt.Errorf("timeout waiting for tasking order %s to be fulfilled: %w", id, err)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return order, nil
 }
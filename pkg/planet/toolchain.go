@@ -0,0 +1,253 @@
+package planet
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/paulmach/orb/geojson"
+)
+
+// Tool types.
+const (
+	ToolTypeClip       = "clip"
+	ToolTypeReproject  = "reproject"
+	ToolTypeComposite  = "composite"
+	ToolTypeFileFormat = "file_format"
+	ToolTypeBandMath   = "bandmath"
+	ToolTypeHarmonize  = "harmonize"
+	ToolTypeTOAR       = "toar"
+	ToolTypeTile       = "tile"
+)
+
+// BandMathTool creates a band math tool configuration. Each expression
+// computes one output band (b1, b2, ...) from the input bands, e.g.
+// "(b4-b3)/(b4+b3)"; pixelType is the output pixel type, e.g. "32R".
+func BandMathTool(pixelType string, expressions ...string) Tool {
+	params := map[string]interface{}{}
+	for i, expr := range expressions {
+		params["b"+strconv.Itoa(i+1)] = expr
+	}
+	if pixelType != "" {
+		params["pixel_type"] = pixelType
+	}
+	return Tool{Type: ToolTypeBandMath, Parameters: params}
+}
+
+// HarmonizeTool creates a harmonization tool configuration that
+// radiometrically harmonizes imagery to targetSensor, e.g. "Sentinel-2".
+func HarmonizeTool(targetSensor string) Tool {
+	return Tool{
+		Type: ToolTypeHarmonize,
+		Parameters: map[string]interface{}{
+			"target_sensor": targetSensor,
+		},
+	}
+}
+
+// TOARTool creates a top of atmosphere reflectance tool configuration.
+// Reflectance values are multiplied by scaleFactor; zero uses the API
+// default.
+func TOARTool(scaleFactor int) Tool {
+	params := map[string]interface{}{}
+	if scaleFactor > 0 {
+		params["scale_factor"] = scaleFactor
+	}
+	return Tool{Type: ToolTypeTOAR, Parameters: params}
+}
+
+// TileTool creates a tiling tool configuration that cuts the output into
+// tiles of tileSize pixels at the given zoom level.
+func TileTool(zoom, tileSize int) Tool {
+	return Tool{
+		Type: ToolTypeTile,
+		Parameters: map[string]interface{}{
+			"zoom":      zoom,
+			"tile_size": tileSize,
+		},
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Tool Chains
+// ----------------------------------------------------------------------------
+
+// toolRuleKind is the kind of constraint a toolRule expresses.
+type toolRuleKind int
+
+const (
+	// ruleOrder requires tool First to come before tool Second.
+	ruleOrder toolRuleKind = iota
+	// ruleLast requires tool First to be the last tool.
+	ruleLast
+	// ruleIncompatible forbids tools First and Second in the same chain.
+	ruleIncompatible
+)
+
+// toolRule is one ordering or compatibility constraint the Orders API
+// enforces on tool chains.
+type toolRule struct {
+	Kind          toolRuleKind
+	First, Second string
+	// Bundles limits the rule to orders for these product bundles; the
+	// rule applies to every order if empty.
+	Bundles []string
+	Reason  string
+}
+
+// toolRules are the tool chain constraints checked by ToolChain.Build.
+var toolRules = []toolRule{
+	{Kind: ruleOrder, First: ToolTypeClip, Second: ToolTypeComposite,
+		Reason: "clip must precede composite"},
+	{Kind: ruleOrder, First: ToolTypeTOAR, Second: ToolTypeHarmonize,
+		Reason: "toar must precede harmonize"},
+	{Kind: ruleOrder, First: ToolTypeTOAR, Second: ToolTypeBandMath,
+		Reason: "toar must precede bandmath"},
+	{Kind: ruleOrder, First: ToolTypeReproject, Second: ToolTypeTile,
+		Reason: "reproject must precede tile"},
+	{Kind: ruleLast, First: ToolTypeFileFormat,
+		Reason: "file_format must be the last tool"},
+	{Kind: ruleIncompatible, First: ToolTypeBandMath, Second: ToolTypeComposite,
+		Bundles: []string{"analytic_udm2", "analytic_sr_udm2", "analytic_8b_udm2", "analytic_8b_sr_udm2"},
+		Reason:  "bandmath cannot be combined with composite for analytic bundles"},
+}
+
+// ToolChainError reports a tool chain that breaks an ordering or
+// compatibility rule. Second is empty for rules about a single tool.
+type ToolChainError struct {
+	First, Second string
+	Rule          string
+}
+
+func (e *ToolChainError) Error() string {
+	if e.Second == "" {
+		return fmt.Sprintf("invalid tool chain: %s: %s", e.First, e.Rule)
+	}
+	return fmt.Sprintf("invalid tool chain: %s and %s: %s", e.First, e.Second, e.Rule)
+}
+
+// ToolChain builds the tools of an order, checking at Build the ordering and
+// compatibility rules the Orders API would otherwise only enforce at
+// submission:
+//
+//	tools, err := planet.NewToolChain().
+//		Clip(aoi).
+//		Reproject("EPSG:32633").
+//		Composite().
+//		FileFormat("COG").
+//		Build()
+type ToolChain struct {
+	tools   []Tool
+	bundles []string
+}
+
+// NewToolChain returns an empty tool chain.
+func NewToolChain() *ToolChain {
+	return &ToolChain{}
+}
+
+// Add appends tool to the chain.
+func (c *ToolChain) Add(tool Tool) *ToolChain {
+	c.tools = append(c.tools, tool)
+	return c
+}
+
+// Clip appends a clip tool.
+func (c *ToolChain) Clip(aoi *geojson.Geometry) *ToolChain { return c.Add(ClipTool(aoi)) }
+
+// Reproject appends a reproject tool.
+func (c *ToolChain) Reproject(projection string) *ToolChain {
+	return c.Add(ReprojectTool(projection))
+}
+
+// Composite appends a composite tool.
+func (c *ToolChain) Composite() *ToolChain { return c.Add(CompositeTool()) }
+
+// FileFormat appends a file format tool.
+func (c *ToolChain) FileFormat(format string) *ToolChain { return c.Add(FileFormatTool(format)) }
+
+// BandMath appends a band math tool.
+func (c *ToolChain) BandMath(pixelType string, expressions ...string) *ToolChain {
+	return c.Add(BandMathTool(pixelType, expressions...))
+}
+
+// Harmonize appends a harmonization tool.
+func (c *ToolChain) Harmonize(targetSensor string) *ToolChain {
+	return c.Add(HarmonizeTool(targetSensor))
+}
+
+// TOAR appends a top of atmosphere reflectance tool.
+func (c *ToolChain) TOAR(scaleFactor int) *ToolChain { return c.Add(TOARTool(scaleFactor)) }
+
+// Tile appends a tiling tool.
+func (c *ToolChain) Tile(zoom, tileSize int) *ToolChain { return c.Add(TileTool(zoom, tileSize)) }
+
+// ForBundles sets the product bundles the chain will be applied to, which
+// enables the compatibility rules specific to those bundles.
+func (c *ToolChain) ForBundles(bundles ...string) *ToolChain {
+	c.bundles = append(c.bundles, bundles...)
+	return c
+}
+
+// Build validates the chain and returns its tools. The error is a
+// *ToolChainError naming the offending tools and the rule they break.
+func (c *ToolChain) Build() ([]Tool, error) {
+	if err := validateTools(c.tools, c.bundles); err != nil {
+		return nil, err
+	}
+	return slices.Clone(c.tools), nil
+}
+
+func validateTools(tools []Tool, bundles []string) error {
+	index := make(map[string]int, len(tools))
+	for i, t := range tools {
+		if t.Type == "" {
+			return fmt.Errorf("invalid tool chain: tool %d has no type", i+1)
+		}
+		if _, dup := index[t.Type]; dup {
+			return &ToolChainError{First: t.Type, Second: t.Type, Rule: "a tool may appear only once"}
+		}
+		index[t.Type] = i
+	}
+
+	for _, r := range toolRules {
+		if len(r.Bundles) > 0 && !slices.ContainsFunc(bundles, func(b string) bool { return slices.Contains(r.Bundles, b) }) {
+			continue
+		}
+		first, hasFirst := index[r.First]
+		second, hasSecond := index[r.Second]
+		switch r.Kind {
+		case ruleOrder:
+			if hasFirst && hasSecond && first > second {
+				return &ToolChainError{First: r.Second, Second: r.First, Rule: r.Reason}
+			}
+		case ruleLast:
+			if hasFirst && first != len(tools)-1 {
+				return &ToolChainError{First: r.First, Second: tools[first+1].Type, Rule: r.Reason}
+			}
+		case ruleIncompatible:
+			if hasFirst && hasSecond {
+				return &ToolChainError{First: r.First, Second: r.Second, Rule: r.Reason}
+			}
+		}
+	}
+	return nil
+}
+
+// SetToolChain validates chain against the request's product bundles and
+// sets the request's tools.
+func (r *CreateOrderRequest) SetToolChain(chain *ToolChain) error {
+	if chain == nil {
+		return errors.New("tool chain is required")
+	}
+	bundles := slices.Clone(chain.bundles)
+	for _, p := range r.Products {
+		bundles = append(bundles, p.ProductBundle)
+	}
+	if err := validateTools(chain.tools, bundles); err != nil {
+		return err
+	}
+	r.Tools = slices.Clone(chain.tools)
+	return nil
+}
@@ -0,0 +1,217 @@
+package planet_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/planet"
+)
+
+var toolAOI = geojson.NewGeometry(orb.Polygon{{{10, 50}, {11, 50}, {11, 51}, {10, 51}, {10, 50}}})
+
+func toolTypes(tools []planet.Tool) []string {
+	types := make([]string, len(tools))
+	for i, t := range tools {
+		types[i] = t.Type
+	}
+	return types
+}
+
+func TestToolChain_Valid(t *testing.T) {
+	tests := []struct {
+		name  string
+		chain *planet.ToolChain
+		want  []string
+	}{
+		{
+			name:  "empty",
+			chain: planet.NewToolChain(),
+			want:  []string{},
+		},
+		{
+			name:  "clip reproject composite file format",
+			chain: planet.NewToolChain().Clip(toolAOI).Reproject("EPSG:32633").Composite().FileFormat("COG"),
+			want:  []string{"clip", "reproject", "composite", "file_format"},
+		},
+		{
+			name:  "toar harmonize bandmath",
+			chain: planet.NewToolChain().TOAR(10000).Harmonize("Sentinel-2").BandMath("32R", "(b4-b3)/(b4+b3)"),
+			want:  []string{"toar", "harmonize", "bandmath"},
+		},
+		{
+			name:  "reproject tile",
+			chain: planet.NewToolChain().Reproject("EPSG:3857").Tile(12, 256),
+			want:  []string{"reproject", "tile"},
+		},
+		{
+			name:  "bandmath with composite outside restricted bundles",
+			chain: planet.NewToolChain().BandMath("8U", "b1").Composite().ForBundles("visual"),
+			want:  []string{"bandmath", "composite"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tools, err := tt.chain.Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			got := toolTypes(tools)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected tools %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expected tools %v, got %v", tt.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestToolChain_Rules(t *testing.T) {
+	tests := []struct {
+		name          string
+		chain         *planet.ToolChain
+		first, second string
+		rule          string
+	}{
+		{
+			name:   "composite before clip",
+			chain:  planet.NewToolChain().Composite().Clip(toolAOI),
+			first:  "composite",
+			second: "clip",
+			rule:   "clip must precede composite",
+		},
+		{
+			name:   "harmonize before toar",
+			chain:  planet.NewToolChain().Harmonize("Sentinel-2").TOAR(0),
+			first:  "harmonize",
+			second: "toar",
+			rule:   "toar must precede harmonize",
+		},
+		{
+			name:   "bandmath before toar",
+			chain:  planet.NewToolChain().BandMath("", "b1").TOAR(0),
+			first:  "bandmath",
+			second: "toar",
+			rule:   "toar must precede bandmath",
+		},
+		{
+			name:   "tile before reproject",
+			chain:  planet.NewToolChain().Tile(10, 512).Reproject("EPSG:3857"),
+			first:  "tile",
+			second: "reproject",
+			rule:   "reproject must precede tile",
+		},
+		{
+			name:   "file format not last",
+			chain:  planet.NewToolChain().Clip(toolAOI).FileFormat("COG").Reproject("EPSG:4326"),
+			first:  "file_format",
+			second: "reproject",
+			rule:   "file_format must be the last tool",
+		},
+		{
+			name:   "bandmath with composite for analytic bundle",
+			chain:  planet.NewToolChain().BandMath("", "b1").Composite().ForBundles("analytic_sr_udm2"),
+			first:  "bandmath",
+			second: "composite",
+			rule:   "bandmath cannot be combined with composite for analytic bundles",
+		},
+		{
+			name:   "duplicate tool",
+			chain:  planet.NewToolChain().Clip(toolAOI).Clip(toolAOI),
+			first:  "clip",
+			second: "clip",
+			rule:   "a tool may appear only once",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.chain.Build()
+			var chainErr *planet.ToolChainError
+			if !errors.As(err, &chainErr) {
+				t.Fatalf("expected *ToolChainError, got %v", err)
+			}
+			if chainErr.First != tt.first || chainErr.Second != tt.second || chainErr.Rule != tt.rule {
+				t.Errorf("expected %s/%s %q, got %s/%s %q", tt.first, tt.second, tt.rule,
+					chainErr.First, chainErr.Second, chainErr.Rule)
+			}
+		})
+	}
+}
+
+func TestToolChain_Serialization(t *testing.T) {
+	tests := []struct {
+		name string
+		tool planet.Tool
+		want string
+	}{
+		{
+			name: "bandmath",
+			tool: planet.BandMathTool("32R", "(b4-b3)/(b4+b3)", "b2"),
+			want: `{"type":"bandmath","parameters":{"b1":"(b4-b3)/(b4+b3)","b2":"b2","pixel_type":"32R"}}`,
+		},
+		{
+			name: "harmonize",
+			tool: planet.HarmonizeTool("Sentinel-2"),
+			want: `{"type":"harmonize","parameters":{"target_sensor":"Sentinel-2"}}`,
+		},
+		{
+			name: "toar",
+			tool: planet.TOARTool(10000),
+			want: `{"type":"toar","parameters":{"scale_factor":10000}}`,
+		},
+		{
+			name: "toar default scale",
+			tool: planet.TOARTool(0),
+			want: `{"type":"toar"}`,
+		},
+		{
+			name: "tile",
+			tool: planet.TileTool(12, 256),
+			want: `{"type":"tile","parameters":{"tile_size":256,"zoom":12}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.tool)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, data)
+			}
+		})
+	}
+}
+
+func TestCreateOrderRequest_SetToolChain(t *testing.T) {
+	chain := planet.NewToolChain().BandMath("", "b1").Composite()
+
+	// The chain alone is valid, but not for the request's analytic bundle.
+	if _, err := chain.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	req := &planet.CreateOrderRequest{
+		Name:     "order",
+		Products: []planet.ProductSpec{{ItemIDs: []string{"item-1"}, ItemType: "PSScene", ProductBundle: "analytic_udm2"}},
+	}
+	var chainErr *planet.ToolChainError
+	if err := req.SetToolChain(chain); !errors.As(err, &chainErr) {
+		t.Fatalf("expected *ToolChainError, got %v", err)
+	}
+	if req.Tools != nil {
+		t.Errorf("expected tools to be left unset, got %v", req.Tools)
+	}
+
+	if err := req.SetToolChain(planet.NewToolChain().Clip(toolAOI).FileFormat("COG")); err != nil {
+		t.Fatalf("SetToolChain() error = %v", err)
+	}
+	if got := toolTypes(req.Tools); len(got) != 2 || got[0] != "clip" || got[1] != "file_format" {
+		t.Errorf("unexpected tools %v", got)
+	}
+}
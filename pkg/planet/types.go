@@ -494,7 +494,7 @@ type Tool struct {
 // ClipTool creates a clip tool configuration.
 func ClipTool(aoi *geojson.Geometry) Tool {
 	return Tool{
-		Type: "clip",
+		Type: ToolTypeClip,
 		Parameters: map[string]interface{}{
 			"aoi": aoi,
 		},
@@ -504,7 +504,7 @@ func ClipTool(aoi *geojson.Geometry) Tool {
 // ReprojectTool creates a reproject tool configuration.
 func ReprojectTool(projection string) Tool {
 	return Tool{
-		Type: "reproject",
+		Type: ToolTypeReproject,
 		Parameters: map[string]interface{}{
 			"projection": projection,
 		},
@@ -514,7 +514,7 @@ func ReprojectTool(projection string) Tool {
 // CompositeTool creates a composite tool configuration.
 func CompositeTool() Tool {
 	return Tool{
-		Type:       "composite",
+		Type:       ToolTypeComposite,
 		Parameters: map[string]interface{}{},
 	}
 }
@@ -522,7 +522,7 @@ func CompositeTool() Tool {
 // FileFormatTool creates a file format tool configuration.
 func FileFormatTool(format string) Tool {
 	return Tool{
-		Type: "file_format",
+		Type: ToolTypeFileFormat,
 		Parameters: map[string]interface{}{
 			"format": format,
 		},
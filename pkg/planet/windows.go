@@ -0,0 +1,368 @@
+package planet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// WindowPredicate reports whether an imaging window should be kept.
+type WindowPredicate func(ImagingWindow) bool
+
+// UnknownCloudPolicy controls how windows without a usable cloud forecast are
+// treated by cloud-based predicates and scorers.
+type UnknownCloudPolicy int
+
+const (
+	// UnknownCloudPessimistic treats a missing forecast as the worst case.
+	UnknownCloudPessimistic UnknownCloudPolicy = iota
+	// UnknownCloudOptimistic treats a missing forecast as the best case.
+	UnknownCloudOptimistic
+)
+
+// FilterWindows returns the windows for which pred returns true, preserving order.
+func FilterWindows(ws []ImagingWindow, pred func(ImagingWindow) bool) []ImagingWindow {
+	var out []ImagingWindow
+	for _, w := range ws {
+		if pred(w) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// AllOf returns a predicate that is true when every pred is true.
+func AllOf(preds ...WindowPredicate) WindowPredicate {
+	return func(w ImagingWindow) bool {
+		for _, p := range preds {
+			if !p(w) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// CloudPrediction returns the worst (highest) cloud prediction across the
+// window's forecasts. Forecasts carrying an error message are ignored; ok is
+// false when no usable forecast remains.
+func (w ImagingWindow) CloudPrediction() (prediction float64, ok bool) {
+	for _, f := range w.CloudForecast {
+		if f.ErrorMessage != "" {
+			continue
+		}
+		if !ok || f.Prediction > prediction {
+			prediction = f.Prediction
+		}
+		ok = true
+	}
+	return prediction, ok
+}
+
+// MaxCloudPrediction keeps windows whose cloud prediction is at most p.
+// Windows without a usable forecast are rejected.
+func MaxCloudPrediction(p float64) WindowPredicate {
+	return MaxCloudPredictionWithPolicy(p, UnknownCloudPessimistic)
+}
+
+// MaxCloudPredictionWithPolicy is like MaxCloudPrediction but keeps windows
+// without a usable forecast when policy is UnknownCloudOptimistic.
+func MaxCloudPredictionWithPolicy(p float64, policy UnknownCloudPolicy) WindowPredicate {
+	return func(w ImagingWindow) bool {
+		pred, ok := w.CloudPrediction()
+		if !ok {
+			return policy == UnknownCloudOptimistic
+		}
+		return pred <= p
+	}
+}
+
+// MaxGSD keeps windows whose ground sample distance is at most meters. When the
+// window reports a GSD range, its upper bound is used.
+func MaxGSD(meters float64) WindowPredicate {
+	return func(w ImagingWindow) bool {
+		return windowGSD(w) <= meters
+	}
+}
+
+// AssuredTierAtLeast keeps windows whose assured tasking tier is at least tier,
+// ordered NOT_APPLICABLE < STANDARD < EXPRESS.
+func AssuredTierAtLeast(tier AssuredTaskingTier) WindowPredicate {
+	return func(w ImagingWindow) bool {
+		return assuredTierRank(w.AssuredTaskingTier) >= assuredTierRank(tier)
+	}
+}
+
+// NotLowLight keeps windows that are not flagged as low light.
+func NotLowLight() WindowPredicate {
+	return func(w ImagingWindow) bool {
+		return !w.LowLight
+	}
+}
+
+// WindowWithin keeps windows that lie entirely within [start, end].
+func WindowWithin(start, end time.Time) WindowPredicate {
+	return func(w ImagingWindow) bool {
+		return !w.StartTime.Before(start) && !w.EndTime.After(end)
+	}
+}
+
+func windowGSD(w ImagingWindow) float64 {
+	return max(w.GroundSampleDistance, w.GroundSampleDistanceMax)
+}
+
+func assuredTierRank(t AssuredTaskingTier) int {
+	switch t {
+	case AssuredTaskingTierStandard:
+		return 1
+	case AssuredTaskingTierExpress:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// RankWindows returns a copy of ws sorted by descending score. Windows with
+// equal scores keep their original relative order.
+func RankWindows(ws []ImagingWindow, scorer func(ImagingWindow) float64) []ImagingWindow {
+	type scored struct {
+		w     ImagingWindow
+		score float64
+	}
+	s := make([]scored, len(ws))
+	for i, w := range ws {
+		s[i] = scored{w, scorer(w)}
+	}
+	slices.SortStableFunc(s, func(a, b scored) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		default:
+			return 0
+		}
+	})
+	out := make([]ImagingWindow, len(s))
+	for i := range s {
+		out[i] = s[i].w
+	}
+	return out
+}
+
+// ScoreWeights configures NewWindowScorer. Each weight applies to a metric
+// normalized to [0, 1] across the candidate set, where 0 is the best value
+// seen and 1 the worst.
+type ScoreWeights struct {
+	Cloud float64
+	GSD   float64
+	Cost  float64
+
+	// Unknown decides whether a missing cloud forecast or missing pricing
+	// details scores as the worst (pessimistic) or best (optimistic) value.
+	Unknown UnknownCloudPolicy
+}
+
+// DefaultScoreWeights favours clear skies, then resolution, then quota cost.
+var DefaultScoreWeights = ScoreWeights{Cloud: 0.5, GSD: 0.3, Cost: 0.2}
+
+// DefaultWindowScorer returns a scorer for ws using DefaultScoreWeights.
+func DefaultWindowScorer(ws []ImagingWindow) func(ImagingWindow) float64 {
+	return NewWindowScorer(ws, DefaultScoreWeights)
+}
+
+// NewWindowScorer returns a scorer for windows drawn from ws. Scores lie in
+// [0, 1], higher is better. Cloud prediction, GSD and estimated quota cost are
+// min-max normalized over ws so the weights are comparable regardless of units.
+func NewWindowScorer(ws []ImagingWindow, weights ScoreWeights) func(ImagingWindow) float64 {
+	var cloud, gsd, cost metricRange
+	for _, w := range ws {
+		if p, ok := w.CloudPrediction(); ok {
+			cloud.add(p)
+		}
+		gsd.add(windowGSD(w))
+		if w.PricingDetails != nil {
+			cost.add(w.PricingDetails.EstimatedQuotaCost)
+		}
+	}
+
+	unknown := 1.0
+	if weights.Unknown == UnknownCloudOptimistic {
+		unknown = 0
+	}
+	total := weights.Cloud + weights.GSD + weights.Cost
+
+	return func(w ImagingWindow) float64 {
+		if total <= 0 {
+			return 0
+		}
+		c := unknown
+		if p, ok := w.CloudPrediction(); ok {
+			c = cloud.normalize(p)
+		}
+		q := unknown
+		if w.PricingDetails != nil {
+			q = cost.normalize(w.PricingDetails.EstimatedQuotaCost)
+		}
+		penalty := weights.Cloud*c + weights.GSD*gsd.normalize(windowGSD(w)) + weights.Cost*q
+		return 1 - penalty/total
+	}
+}
+
+type metricRange struct {
+	lo, hi float64
+	seen   bool
+}
+
+func (r *metricRange) add(v float64) {
+	if !r.seen {
+		r.lo, r.hi, r.seen = v, v, true
+		return
+	}
+	r.lo = min(r.lo, v)
+	r.hi = max(r.hi, v)
+}
+
+func (r *metricRange) normalize(v float64) float64 {
+	if !r.seen || r.hi == r.lo {
+		return 0
+	}
+	return min(max((v-r.lo)/(r.hi-r.lo), 0), 1)
+}
+
+// CreateTaskingOrderFromWindow returns a copy of base that books the imaging
+// window windowID from search. The order's imaging window and start/end times
+// are taken from the window; geometry, PL number and product are filled from
+// the search or window only when base leaves them empty.
+func CreateTaskingOrderFromWindow(search *ImagingWindowSearch, windowID string, base CreateTaskingOrderRequest) (*CreateTaskingOrderRequest, error) {
+	if search == nil {
+		return nil, errors.New("imaging window search is nil")
+	}
+	w, ok := search.window(windowID)
+	if !ok {
+		return nil, &WindowNotFoundError{SearchID: search.ID, WindowID: windowID}
+	}
+
+	req := base
+	id := w.ID
+	start, end := w.StartTime, w.EndTime
+	req.ImagingWindow = &id
+	req.StartTime = &start
+	req.EndTime = &end
+
+	if req.Geometry == nil {
+		req.Geometry = search.Geometry
+	}
+	if req.PLNumber == "" {
+		req.PLNumber = firstNonEmpty(w.PLNumber, search.PLNumber)
+	}
+	if req.Product == "" {
+		req.Product = firstNonEmpty(w.Product, search.Product)
+	}
+	if req.SchedulingType == "" && w.AssuredTaskingTier != "" && w.AssuredTaskingTier != AssuredTaskingTierNotApplicable {
+		req.SchedulingType = SchedulingTypeAssured
+	}
+	return &req, nil
+}
+
+// window returns the imaging window id of the search.
+func (s *ImagingWindowSearch) window(id string) (ImagingWindow, bool) {
+	i := slices.IndexFunc(s.ImagingWindows, func(w ImagingWindow) bool { return w.ID == id })
+	if i < 0 {
+		return ImagingWindow{}, false
+	}
+	return s.ImagingWindows[i], true
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ----------------------------------------------------------------------------
+// Assured Orders
+// ----------------------------------------------------------------------------
+
+// DefaultAssuredLeadTime is the minimum time between now and an imaging
+// window's start for CreateAssuredOrder to book it, unless changed with
+// WithAssuredLeadTime to match the lead time of your contract.
+const DefaultAssuredLeadTime = 30 * time.Minute
+
+// WindowNotFoundError is returned when an imaging window is not part of the
+// imaging window search it is booked from.
+type WindowNotFoundError struct {
+	SearchID string
+	WindowID string
+}
+
+func (e *WindowNotFoundError) Error() string {
+	return fmt.Sprintf("imaging window %q not found in search %s", e.WindowID, e.SearchID)
+}
+
+// WindowExpiredError is returned by CreateAssuredOrder for an imaging window
+// that starts too soon, or has already started, to be booked.
+type WindowExpiredError struct {
+	WindowID  string
+	StartTime time.Time
+	LeadTime  time.Duration
+}
+
+func (e *WindowExpiredError) Error() string {
+	return fmt.Sprintf("imaging window %q starts at %s, less than %s from now", e.WindowID, e.StartTime.Format(time.RFC3339), e.LeadTime)
+}
+
+// WindowNotAssuredError is returned by CreateAssuredOrder for an imaging
+// window that cannot be booked with assured tasking.
+type WindowNotAssuredError struct {
+	WindowID string
+	Tier     AssuredTaskingTier
+}
+
+func (e *WindowNotAssuredError) Error() string {
+	tier := e.Tier
+	if tier == "" {
+		tier = AssuredTaskingTierNotApplicable
+	}
+	return fmt.Sprintf("imaging window %q has assured tasking tier %s", e.WindowID, tier)
+}
+
+// CreateAssuredOrder creates a tasking order locked to the imaging window
+// windowID of search, as CreateTaskingOrderFromWindow composes it. The
+// scheduling type follows the window's tier: ASSURED for STANDARD windows
+// and EXPRESS for EXPRESS windows. The order is not submitted, and a typed
+// error returned, if the window is not in the search (*WindowNotFoundError),
+// starts within the client's assured lead time (*WindowExpiredError), or
+// has no assured tasking tier (*WindowNotAssuredError).
+func (c *Client) CreateAssuredOrder(ctx context.Context, search *ImagingWindowSearch, windowID string, base CreateTaskingOrderRequest) (*TaskingOrder, error) {
+	if search == nil {
+		return nil, errors.New("imaging window search is nil")
+	}
+	w, ok := search.window(windowID)
+	if !ok {
+		return nil, &WindowNotFoundError{SearchID: search.ID, WindowID: windowID}
+	}
+
+	switch w.AssuredTaskingTier {
+	case AssuredTaskingTierStandard:
+		base.SchedulingType = SchedulingTypeAssured
+	case AssuredTaskingTierExpress:
+		base.SchedulingType = SchedulingTypeExpress
+	default:
+		return nil, &WindowNotAssuredError{WindowID: w.ID, Tier: w.AssuredTaskingTier}
+	}
+	if time.Until(w.StartTime) < c.assuredLeadTime {
+		return nil, &WindowExpiredError{WindowID: w.ID, StartTime: w.StartTime, LeadTime: c.assuredLeadTime}
+	}
+
+	req, err := CreateTaskingOrderFromWindow(search, windowID, base)
+	if err != nil {
+		return nil, err
+	}
+	return c.CreateTaskingOrder(ctx, req)
+}
@@ -0,0 +1,268 @@
+package planet_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/planet"
+)
+
+var windowBase = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func testWindows() []planet.ImagingWindow {
+	return []planet.ImagingWindow{
+		{
+			ID:                   "clear-expensive",
+			StartTime:            windowBase.Add(1 * time.Hour),
+			EndTime:              windowBase.Add(2 * time.Hour),
+			GroundSampleDistance: 0.5,
+			AssuredTaskingTier:   planet.AssuredTaskingTierExpress,
+			CloudForecast:        []planet.CloudForecast{{Prediction: 0.1}},
+			PricingDetails:       &planet.PricingDetails{EstimatedQuotaCost: 300},
+		},
+		{
+			ID:                   "cloudy-cheap",
+			StartTime:            windowBase.Add(3 * time.Hour),
+			EndTime:              windowBase.Add(4 * time.Hour),
+			GroundSampleDistance: 0.5,
+			AssuredTaskingTier:   planet.AssuredTaskingTierStandard,
+			CloudForecast:        []planet.CloudForecast{{Prediction: 0.2}, {Prediction: 0.8}},
+			PricingDetails:       &planet.PricingDetails{EstimatedQuotaCost: 100},
+		},
+		{
+			ID:                      "no-forecast",
+			StartTime:               windowBase.Add(5 * time.Hour),
+			EndTime:                 windowBase.Add(6 * time.Hour),
+			GroundSampleDistance:    0.5,
+			GroundSampleDistanceMax: 1.2,
+			LowLight:                true,
+			CloudForecast:           []planet.CloudForecast{{ErrorMessage: "forecast unavailable"}},
+		},
+	}
+}
+
+func windowIDs(ws []planet.ImagingWindow) []string {
+	ids := make([]string, len(ws))
+	for i, w := range ws {
+		ids[i] = w.ID
+	}
+	return ids
+}
+
+func TestFilterWindows(t *testing.T) {
+	tests := []struct {
+		name string
+		pred planet.WindowPredicate
+		want []string
+	}{
+		{"max cloud pessimistic", planet.MaxCloudPrediction(0.5), []string{"clear-expensive"}},
+		{"max cloud optimistic", planet.MaxCloudPredictionWithPolicy(0.5, planet.UnknownCloudOptimistic), []string{"clear-expensive", "no-forecast"}},
+		{"max gsd uses upper bound", planet.MaxGSD(1), []string{"clear-expensive", "cloudy-cheap"}},
+		{"assured standard", planet.AssuredTierAtLeast(planet.AssuredTaskingTierStandard), []string{"clear-expensive", "cloudy-cheap"}},
+		{"assured express", planet.AssuredTierAtLeast(planet.AssuredTaskingTierExpress), []string{"clear-expensive"}},
+		{"assured not applicable", planet.AssuredTierAtLeast(planet.AssuredTaskingTierNotApplicable), []string{"clear-expensive", "cloudy-cheap", "no-forecast"}},
+		{"not low light", planet.NotLowLight(), []string{"clear-expensive", "cloudy-cheap"}},
+		{"within", planet.WindowWithin(windowBase.Add(2*time.Hour), windowBase.Add(6*time.Hour)), []string{"cloudy-cheap", "no-forecast"}},
+		{"all of", planet.AllOf(planet.NotLowLight(), planet.MaxCloudPrediction(0.9), planet.WindowWithin(windowBase.Add(2*time.Hour), windowBase.Add(24*time.Hour))), []string{"cloudy-cheap"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := windowIDs(planet.FilterWindows(testWindows(), tt.pred))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankWindows(t *testing.T) {
+	ws := testWindows()
+
+	tests := []struct {
+		name    string
+		weights planet.ScoreWeights
+		want    []string
+	}{
+		{"default", planet.DefaultScoreWeights, []string{"clear-expensive", "cloudy-cheap", "no-forecast"}},
+		{"cost only", planet.ScoreWeights{Cost: 1}, []string{"cloudy-cheap", "clear-expensive", "no-forecast"}},
+		{"cost only optimistic", planet.ScoreWeights{Cost: 1, Unknown: planet.UnknownCloudOptimistic}, []string{"cloudy-cheap", "no-forecast", "clear-expensive"}},
+		{"cloud only optimistic", planet.ScoreWeights{Cloud: 1, Unknown: planet.UnknownCloudOptimistic}, []string{"clear-expensive", "no-forecast", "cloudy-cheap"}},
+		{"zero weights keep order", planet.ScoreWeights{}, []string{"clear-expensive", "cloudy-cheap", "no-forecast"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := windowIDs(planet.RankWindows(ws, planet.NewWindowScorer(ws, tt.weights)))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !slices.Equal(windowIDs(ws), []string{"clear-expensive", "cloudy-cheap", "no-forecast"}) {
+		t.Error("RankWindows modified its input")
+	}
+}
+
+func TestCreateTaskingOrderFromWindow(t *testing.T) {
+	geom := geojson.NewGeometry(orb.Point{10, 20})
+	search := &planet.ImagingWindowSearch{
+		ID:             "search-1",
+		Geometry:       geom,
+		PLNumber:       "PL-123",
+		Product:        "SkySat Standard",
+		ImagingWindows: testWindows(),
+	}
+
+	req, err := planet.CreateTaskingOrderFromWindow(search, "clear-expensive", planet.CreateTaskingOrderRequest{
+		Name:    "From window",
+		Product: "SkySat Priority",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if req.ImagingWindow == nil || *req.ImagingWindow != "clear-expensive" {
+		t.Errorf("unexpected imaging window: %v", req.ImagingWindow)
+	}
+	if req.StartTime == nil || !req.StartTime.Equal(windowBase.Add(time.Hour)) {
+		t.Errorf("unexpected start time: %v", req.StartTime)
+	}
+	if req.EndTime == nil || !req.EndTime.Equal(windowBase.Add(2*time.Hour)) {
+		t.Errorf("unexpected end time: %v", req.EndTime)
+	}
+	if req.Geometry != geom {
+		t.Error("expected geometry from search")
+	}
+	if req.PLNumber != "PL-123" {
+		t.Errorf("expected PL number from search, got %q", req.PLNumber)
+	}
+	if req.Product != "SkySat Priority" {
+		t.Errorf("expected base product to be kept, got %q", req.Product)
+	}
+	if req.SchedulingType != planet.SchedulingTypeAssured {
+		t.Errorf("expected assured scheduling, got %q", req.SchedulingType)
+	}
+	if req.Name != "From window" {
+		t.Errorf("expected base name to be kept, got %q", req.Name)
+	}
+
+	if _, err := planet.CreateTaskingOrderFromWindow(search, "missing", planet.CreateTaskingOrderRequest{}); err == nil {
+		t.Error("expected error for unknown window")
+	}
+	if _, err := planet.CreateTaskingOrderFromWindow(nil, "clear-expensive", planet.CreateTaskingOrderRequest{}); err == nil {
+		t.Error("expected error for nil search")
+	}
+}
+
+func assuredSearch(start time.Time) *planet.ImagingWindowSearch {
+	return &planet.ImagingWindowSearch{
+		ID:       "search-1",
+		Geometry: geojson.NewGeometry(orb.Point{10, 20}),
+		PLNumber: "PL-123",
+		ImagingWindows: []planet.ImagingWindow{
+			{ID: "standard", StartTime: start, EndTime: start.Add(time.Minute), AssuredTaskingTier: planet.AssuredTaskingTierStandard, Product: "SkySat Assured", PLNumber: "PL-456"},
+			{ID: "express", StartTime: start, EndTime: start.Add(time.Minute), AssuredTaskingTier: planet.AssuredTaskingTierExpress},
+			{ID: "flexible", StartTime: start, EndTime: start.Add(time.Minute), AssuredTaskingTier: planet.AssuredTaskingTierNotApplicable},
+			{ID: "untiered", StartTime: start, EndTime: start.Add(time.Minute)},
+			{ID: "soon", StartTime: time.Now().Add(10 * time.Minute), EndTime: time.Now().Add(11 * time.Minute), AssuredTaskingTier: planet.AssuredTaskingTierStandard},
+		},
+	}
+}
+
+func TestCreateAssuredOrder(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	var body map[string]any
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPost)
+		requirePath(t, r, "/tasking/v2/orders")
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		jsonResponse(w, http.StatusCreated, planet.TaskingOrder{ID: "order-1", SchedulingType: planet.SchedulingTypeAssured})
+	})
+
+	base := planet.CreateTaskingOrderRequest{Name: "Assured", SchedulingType: planet.SchedulingTypeFlexible}
+	order, err := cli.CreateAssuredOrder(context.Background(), assuredSearch(start), "standard", base)
+	if err != nil {
+		t.Fatalf("CreateAssuredOrder: %v", err)
+	}
+	if order.ID != "order-1" {
+		t.Errorf("unexpected order %+v", order)
+	}
+	want := map[string]any{
+		"name":            "Assured",
+		"geometry":        map[string]any{"type": "Point", "coordinates": []any{10.0, 20.0}},
+		"imaging_window":  "standard",
+		"pl_number":       "PL-456",
+		"product":         "SkySat Assured",
+		"scheduling_type": "ASSURED",
+		"start_time":      start.Format(time.RFC3339),
+		"end_time":        start.Add(time.Minute).Format(time.RFC3339),
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("request body\n got %v\nwant %v", body, want)
+	}
+
+	if _, err := cli.CreateAssuredOrder(context.Background(), assuredSearch(start), "express", planet.CreateTaskingOrderRequest{Name: "Express"}); err != nil {
+		t.Fatalf("CreateAssuredOrder: %v", err)
+	}
+	if body["scheduling_type"] != "EXPRESS" {
+		t.Errorf("expected EXPRESS scheduling, got %v", body["scheduling_type"])
+	}
+}
+
+func TestCreateAssuredOrder_Rejected(t *testing.T) {
+	var requests int
+	cli, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		jsonResponse(w, http.StatusCreated, planet.TaskingOrder{ID: "order-1"})
+	})
+	search := assuredSearch(time.Now().Add(24 * time.Hour))
+
+	var notFound *planet.WindowNotFoundError
+	if _, err := cli.CreateAssuredOrder(context.Background(), search, "missing", planet.CreateTaskingOrderRequest{}); !errors.As(err, &notFound) {
+		t.Errorf("expected WindowNotFoundError, got %v", err)
+	} else if notFound.SearchID != "search-1" || notFound.WindowID != "missing" {
+		t.Errorf("unexpected error fields %+v", notFound)
+	}
+
+	var expired *planet.WindowExpiredError
+	if _, err := cli.CreateAssuredOrder(context.Background(), search, "soon", planet.CreateTaskingOrderRequest{}); !errors.As(err, &expired) {
+		t.Errorf("expected WindowExpiredError, got %v", err)
+	} else if expired.LeadTime != planet.DefaultAssuredLeadTime {
+		t.Errorf("unexpected lead time %v", expired.LeadTime)
+	}
+
+	for _, id := range []string{"flexible", "untiered"} {
+		var notAssured *planet.WindowNotAssuredError
+		if _, err := cli.CreateAssuredOrder(context.Background(), search, id, planet.CreateTaskingOrderRequest{}); !errors.As(err, &notAssured) {
+			t.Errorf("%s: expected WindowNotAssuredError, got %v", id, err)
+		}
+	}
+
+	if _, err := cli.CreateAssuredOrder(context.Background(), nil, "standard", planet.CreateTaskingOrderRequest{}); err == nil {
+		t.Error("expected an error for a nil search")
+	}
+	if requests != 0 {
+		t.Errorf("expected no order to be submitted, got %d requests", requests)
+	}
+
+	// A shorter lead time accepts the window.
+	short, err := planet.NewClient("test-api-key", planet.WithBaseURL(srv.URL), planet.WithAssuredLeadTime(5*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := short.CreateAssuredOrder(context.Background(), search, "soon", planet.CreateTaskingOrderRequest{}); err != nil {
+		t.Errorf("expected the window to be accepted with a 5m lead time: %v", err)
+	}
+}
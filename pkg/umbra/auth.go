@@ -0,0 +1,91 @@
+package umbra
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httputil"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// ----------------------------------------------------------------------------
+// Authentication
+// ----------------------------------------------------------------------------
+
+// APIKeyHeader is the request header carrying a service account API key.
+const APIKeyHeader = "X-API-Key"
+
+// AuthProvider authenticates requests. Apply is called for every request,
+// including retries, and sets the request's credentials; an error fails the
+// request without sending it.
+type AuthProvider = common.Authenticator
+
+// APIKeyAuth authenticates requests with a long-lived API key sent in the
+// APIKeyHeader header.
+type APIKeyAuth struct {
+	key string
+}
+
+// NewAPIKeyAuth returns an AuthProvider for an Umbra API key.
+func NewAPIKeyAuth(key string) *APIKeyAuth {
+	return &APIKeyAuth{key: key}
+}
+
+// Apply implements AuthProvider.
+func (a *APIKeyAuth) Apply(ctx context.Context, req *http.Request) error {
+	req.Header.Set(APIKeyHeader, a.key)
+	return nil
+}
+
+// WithAuthProvider authenticates requests with p instead of the credentials
+// passed to the constructor, e.g. to plug in a custom signing scheme.
+func WithAuthProvider(p AuthProvider) Option {
+	return func(c *clientConfig) {
+		c.auth = p
+	}
+}
+
+// NewClientWithAPIKey creates a new Canopy API client for production that
+// authenticates with a service account API key rather than a bearer token.
+func NewClientWithAPIKey(key string, opts ...Option) (*Client, error) {
+	if key == "" {
+		return nil, errors.New("api key is required")
+	}
+	return newClient(NewAPIKeyAuth(key), opts...)
+}
+
+// ----------------------------------------------------------------------------
+// Redaction
+// ----------------------------------------------------------------------------
+
+// DumpRequest returns the wire representation of req, like
+// httputil.DumpRequestOut, with credentials replaced by "[REDACTED]". Use it
+// rather than httputil directly when logging requests or attaching them to
+// error reports. If the body is dumped and req cannot rebuild it, it is read
+// and replaced with a copy, so req can still be sent.
+func DumpRequest(req *http.Request, body bool) ([]byte, error) {
+	clone := req.Clone(req.Context())
+	switch {
+	case !body:
+		clone.Body = nil
+	case req.GetBody != nil:
+		b, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = b
+	case req.Body != nil && req.Body != http.NoBody:
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		clone.Body = io.NopCloser(bytes.NewReader(data))
+	}
+	clone.Header = common.RedactHeaders(clone.Header)
+	return httputil.DumpRequestOut(clone, body)
+}
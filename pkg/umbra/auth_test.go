@@ -0,0 +1,157 @@
+package umbra_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+func TestNewClientWithAPIKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get(umbra.APIKeyHeader); got != "key-123" {
+			t.Errorf("%s = %q, want key-123", umbra.APIKeyHeader, got)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Authorization = %q, want none", got)
+		}
+		jsonResponse(w, http.StatusOK, umbra.Task{ID: "test"})
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := umbra.NewClientWithAPIKey("key-123", umbra.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("NewClientWithAPIKey: %v", err)
+	}
+	if _, err := cli.GetTask(context.Background(), "test"); err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+}
+
+func TestNewClientWithAPIKey_Empty(t *testing.T) {
+	if _, err := umbra.NewClientWithAPIKey(""); err == nil {
+		t.Fatal("expected error for empty API key")
+	}
+}
+
+type countingAuth struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (a *countingAuth) Apply(ctx context.Context, req *http.Request) error {
+	if a.err != nil {
+		return a.err
+	}
+	n := a.calls.Add(1)
+	req.Header.Set("X-Signature", "sig-"+strconv.Itoa(int(n)))
+	return nil
+}
+
+func TestWithAuthProvider(t *testing.T) {
+	var sigs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sigs = append(sigs, r.Header.Get("X-Signature"))
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Authorization = %q, want none", got)
+		}
+		jsonResponse(w, http.StatusOK, umbra.Task{ID: "test"})
+	}))
+	t.Cleanup(srv.Close)
+
+	auth := &countingAuth{}
+	cli, err := umbra.NewClient("unused-token", umbra.WithBaseURL(srv.URL), umbra.WithAuthProvider(auth))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	for range 2 {
+		if _, err := cli.GetTask(context.Background(), "test"); err != nil {
+			t.Fatalf("GetTask: %v", err)
+		}
+	}
+
+	if n := auth.calls.Load(); n != 2 {
+		t.Errorf("provider called %d times, want 2", n)
+	}
+	if strings.Join(sigs, ",") != "sig-1,sig-2" {
+		t.Errorf("signatures = %v, want a fresh signature per request", sigs)
+	}
+}
+
+func TestWithAuthProvider_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent despite auth failure")
+	}))
+	t.Cleanup(srv.Close)
+
+	errSign := errors.New("signing key unavailable")
+	cli, err := umbra.NewClient("", umbra.WithBaseURL(srv.URL), umbra.WithAuthProvider(&countingAuth{err: errSign}))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := cli.GetTask(context.Background(), "test"); !errors.Is(err, errSign) {
+		t.Fatalf("err = %v, want %v", err, errSign)
+	}
+}
+
+func TestDumpRequest_RedactsCredentials(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://api.canopy.umbra.space/tasking/tasks", strings.NewReader(`{"taskName":"t"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set(umbra.APIKeyHeader, "secret-key")
+	req.Header.Set("Cookie", "session=secret-cookie")
+	req.Header.Set("X-Request-Id", "req-1")
+
+	dump, err := umbra.DumpRequest(req, true)
+	if err != nil {
+		t.Fatalf("DumpRequest: %v", err)
+	}
+	out := string(dump)
+	for _, secret := range []string{"secret-token", "secret-key", "secret-cookie"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("dump leaks %q:\n%s", secret, out)
+		}
+	}
+	for _, want := range []string{"Authorization: [REDACTED]", "X-Api-Key: [REDACTED]", "X-Request-Id: req-1", `{"taskName":"t"}`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dump missing %q:\n%s", want, out)
+		}
+	}
+
+	// The original request is untouched.
+	if got := req.Header.Get("Authorization"); got != "Bearer secret-token" {
+		t.Errorf("original Authorization = %q", got)
+	}
+}
+
+func TestDumpRequest_KeepsBody(t *testing.T) {
+	const body = `{"taskName":"t"}`
+	req, err := http.NewRequest(http.MethodPost, "https://api.canopy.umbra.space/tasking/tasks", io.NopCloser(strings.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.GetBody != nil {
+		t.Fatal("expected a request that cannot rebuild its body")
+	}
+
+	dump, err := umbra.DumpRequest(req, true)
+	if err != nil {
+		t.Fatalf("DumpRequest: %v", err)
+	}
+	if !strings.Contains(string(dump), body) {
+		t.Errorf("dump missing the body:\n%s", dump)
+	}
+	got, err := io.ReadAll(req.Body)
+	if err != nil || string(got) != body {
+		t.Errorf("expected the body to be restored, got %q, %v", got, err)
+	}
+}
@@ -6,7 +6,7 @@
 // manage delivery configurations, and access collected imagery via STAC-compliant endpoints.
 //
 // Key features:
-//   - Bearer token authentication
+//   - Bearer token or API key authentication, or a custom AuthProvider
 //   - Full coverage of Tasking, Feasibility, Collects, Delivery, STAC, and Archive APIs
 //   - STAC (Spatio-Temporal Asset Catalog) compliant catalog search
 //   - CQL2 filter builder for advanced queries
@@ -17,7 +17,10 @@
 package umbra
 
 import (
+	"crypto/tls"
+	"errors"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
@@ -35,6 +38,14 @@ const (
 // Client represents a Canopy API client.
 type Client struct {
 	*common.Client
+
+	validateTasks          bool
+	lintTasks              bool
+	skipOnConstraintsError bool
+	constraintsWarn        func(error)
+	constraints            *constraintsCache
+	feasibilities          *feasibilityCache
+	strictDecoding         bool
 }
 
 // Option configures a Client.
@@ -44,6 +55,18 @@ type clientConfig struct {
 	baseURL    string
 	httpClient *http.Client
 	timeout    time.Duration
+	userAgent  string
+	proxy      *url.URL
+	tlsConfig  *tls.Config
+	auth       AuthProvider
+
+	validateTasks          bool
+	lintTasks              bool
+	skipOnConstraintsError bool
+	constraintsWarn        func(error)
+	feasibilityTTL         time.Duration
+	feasibilityMaxEntries  int
+	strictDecoding         bool
 }
 
 // WithHTTPClient sets a custom HTTP client.
@@ -67,51 +90,124 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
-// NewClient creates a new Canopy API client configured for production.
-func NewClient(accessToken string, opts ...Option) (*Client, error) {
-	cfg := &clientConfig{
-		baseURL: ProductionBaseURL,
-		timeout: defaultTimeout,
+// WithUserAgent sets the User-Agent header sent with every request.
+// Defaults to "go-sar-vendor/<version> (+umbra)".
+func WithUserAgent(userAgent string) Option {
+	return func(c *clientConfig) {
+		c.userAgent = userAgent
 	}
-	for _, opt := range opts {
-		opt(cfg)
+}
+
+// WithProxy routes requests through the given proxy, composing with the
+// default transport (or the transport of a client set via WithHTTPClient).
+func WithProxy(proxy *url.URL) Option {
+	return func(c *clientConfig) {
+		c.proxy = proxy
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for outgoing connections,
+// e.g. to trust a custom CA. It composes with the default transport.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *clientConfig) {
+		c.tlsConfig = tlsConfig
 	}
+}
 
-	httpClient := common.EnsureHTTPClient(cfg.httpClient, cfg.timeout)
+// WithTaskValidation makes CreateTask validate every task against the product
+// constraints of its imaging mode before submitting it, as CreateTaskValidated
+// does.
+func WithTaskValidation() Option {
+	return func(c *clientConfig) {
+		c.validateTasks = true
+	}
+}
 
-	c, err := common.NewClient(common.ClientConfig{
-		BaseURL:    cfg.baseURL,
-		HTTPClient: httpClient,
-		Auth:       common.NewBearerAuth(accessToken),
-	})
-	if err != nil {
-		return nil, err
+// WithTaskLint makes CreateTask check every task with LintTaskRequest before
+// submitting it, without contacting the API, and refuse tasks with
+// error-severity findings with a *LintError. Warnings do not stop the task.
+func WithTaskLint() Option {
+	return func(c *clientConfig) {
+		c.lintTasks = true
 	}
+}
 
-	return &Client{Client: c}, nil
+// WithSkipValidationOnConstraintsError makes task validation pass when the
+// product constraints cannot be fetched, instead of failing the task. The
+// fetch error is passed to warn, which may be nil.
+func WithSkipValidationOnConstraintsError(warn func(error)) Option {
+	return func(c *clientConfig) {
+		c.skipOnConstraintsError = true
+		c.constraintsWarn = warn
+	}
 }
 
-// NewSandboxClient creates a new Canopy API client configured for the sandbox environment.
-func NewSandboxClient(accessToken string, opts ...Option) (*Client, error) {
+// NewClient creates a new Canopy API client configured for production that
+// authenticates with a bearer access token.
+func NewClient(accessToken string, opts ...Option) (*Client, error) {
+	return newClient(common.NewBearerAuth(accessToken), opts...)
+}
+
+// newClient creates a client authenticating with auth unless WithAuthProvider
+// overrides it.
+func newClient(auth AuthProvider, opts ...Option) (*Client, error) {
 	cfg := &clientConfig{
-		baseURL: SandboxBaseURL,
-		timeout: defaultTimeout,
+		baseURL:   ProductionBaseURL,
+		timeout:   defaultTimeout,
+		userAgent: common.DefaultUserAgent("umbra"),
+		auth:      auth,
 	}
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	if cfg.auth == nil {
+		return nil, errors.New("auth provider is required")
+	}
+
+	httpClient, err := common.ConfigureTransport(
+		common.EnsureHTTPClient(cfg.httpClient, cfg.timeout), cfg.proxy, cfg.tlsConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	httpClient := common.EnsureHTTPClient(cfg.httpClient, cfg.timeout)
+	// Every call carries a client request ID for correlation with Umbra support.
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	apiClient := *httpClient
+	apiClient.Transport = &requestIDTransport{base: base}
 
 	c, err := common.NewClient(common.ClientConfig{
 		BaseURL:    cfg.baseURL,
-		HTTPClient: httpClient,
-		Auth:       common.NewBearerAuth(accessToken),
+		HTTPClient: &apiClient,
+		Auth:       cfg.auth,
+		UserAgent:  cfg.userAgent,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{Client: c}, nil
+	cli := &Client{
+		Client:                 c,
+		validateTasks:          cfg.validateTasks,
+		lintTasks:              cfg.lintTasks,
+		skipOnConstraintsError: cfg.skipOnConstraintsError,
+		constraintsWarn:        cfg.constraintsWarn,
+		constraints:            &constraintsCache{},
+		strictDecoding:         cfg.strictDecoding,
+	}
+	if cfg.feasibilityTTL > 0 {
+		cli.feasibilities = newFeasibilityCache(cfg.feasibilityTTL, cfg.feasibilityMaxEntries)
+	}
+	return cli, nil
+}
+
+// NewSandboxClient creates a new Canopy API client configured for the sandbox environment.
+func NewSandboxClient(accessToken string, opts ...Option) (*Client, error) {
+	sandboxOpts := []Option{
+		WithBaseURL(SandboxBaseURL),
+	}
+	return NewClient(accessToken, append(sandboxOpts, opts...)...)
 }
 
@@ -2,7 +2,14 @@ package umbra_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -66,6 +73,150 @@ func TestClientWithBaseURL(t *testing.T) {
 	_ = srv
 }
 
+func TestClientDefaultUserAgent(t *testing.T) {
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		ua := r.Header.Get("User-Agent")
+		if !strings.HasPrefix(ua, "go-sar-vendor/") || !strings.HasSuffix(ua, " (+umbra)") {
+			t.Errorf("unexpected default User-Agent %q", ua)
+		}
+		jsonResponse(w, http.StatusOK, umbra.Task{ID: "test"})
+	})
+
+	if _, err := cli.GetTask(context.Background(), "test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientWithUserAgent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != "acme-ops/2.1" {
+			t.Errorf("expected User-Agent acme-ops/2.1, got %q", ua)
+		}
+		jsonResponse(w, http.StatusOK, umbra.Task{ID: "test"})
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := umbra.NewClient("test-token",
+		umbra.WithBaseURL(srv.URL),
+		umbra.WithUserAgent("acme-ops/2.1"),
+	)
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	if _, err := cli.GetTask(context.Background(), "test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// newConnectProxy starts a minimal HTTP CONNECT proxy and reports the
+// targets it tunnels to.
+func newConnectProxy(t *testing.T) (*httptest.Server, <-chan string) {
+	t.Helper()
+	targets := make(chan string, 10)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "CONNECT only", http.StatusMethodNotAllowed)
+			return
+		}
+		targets <- r.Host
+
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+
+		go func() {
+			io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy, targets
+}
+
+func TestClientWithProxyAndTLSConfig(t *testing.T) {
+	// The TLS test server uses a self-signed certificate, so requests only
+	// succeed if the custom CA from WithTLSConfig is applied.
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, http.StatusOK, umbra.Task{ID: "via-proxy"})
+	}))
+	t.Cleanup(srv.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	proxy, targets := newConnectProxy(t)
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	cli, err := umbra.NewClient("test-token",
+		umbra.WithBaseURL(srv.URL),
+		umbra.WithProxy(proxyURL),
+		umbra.WithTLSConfig(&tls.Config{RootCAs: pool}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+
+	task, err := cli.GetTask(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.ID != "via-proxy" {
+		t.Errorf("expected task via-proxy, got %s", task.ID)
+	}
+
+	select {
+	case target := <-targets:
+		if target != srv.Listener.Addr().String() {
+			t.Errorf("expected CONNECT to %s, got %s", srv.Listener.Addr(), target)
+		}
+	default:
+		t.Error("expected request to be tunnelled through the proxy")
+	}
+}
+
+func TestClientWithTLSConfig_UntrustedCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, http.StatusOK, umbra.Task{ID: "test"})
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := umbra.NewClient("test-token",
+		umbra.WithBaseURL(srv.URL),
+		umbra.WithTLSConfig(&tls.Config{RootCAs: x509.NewCertPool()}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	if _, err := cli.GetTask(context.Background(), "test"); err == nil {
+		t.Fatal("expected certificate verification error, got nil")
+	}
+}
+
+func TestClientWithProxy_CustomRoundTripper(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy.example.com:3128")
+	_, err := umbra.NewClient("test-token",
+		umbra.WithHTTPClient(&http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}),
+		umbra.WithProxy(proxyURL),
+	)
+	if err == nil {
+		t.Fatal("expected error combining WithProxy with a non-*http.Transport round tripper")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
 func TestClientAuthHeader(t *testing.T) {
 	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
 		auth := r.Header.Get("Authorization")
@@ -131,6 +282,15 @@ func TestAPIErrorString(t *testing.T) {
 			},
 			expected: "Internal error (500)",
 		},
+		{
+			name: "with request ID",
+			err: &umbra.APIError{
+				StatusCode: 503,
+				Message:    "Unavailable",
+				RequestID:  "req-42",
+			},
+			expected: "Unavailable (503) [request-id req-42]",
+		},
 	}
 
 	for _, tt := range tests {
@@ -142,6 +302,84 @@ func TestAPIErrorString(t *testing.T) {
 	}
 }
 
+func TestClientRequestIDHeader(t *testing.T) {
+	var ids []string
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(umbra.ClientRequestIDHeader))
+		jsonResponse(w, http.StatusOK, umbra.Task{ID: "test"})
+	})
+
+	for range 2 {
+		if _, err := cli.GetTask(context.Background(), "test"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(ids) != 2 || len(ids[0]) != 36 || ids[0] == ids[1] {
+		t.Errorf("expected a distinct UUID per call, got %q", ids)
+	}
+
+	ctx := umbra.WithClientRequestID(context.Background(), "op-1")
+	for range 2 {
+		if _, err := cli.GetTask(ctx, "test"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if ids[2] != "op-1" || ids[3] != "op-1" {
+		t.Errorf("expected the operation ID to be reused, got %q", ids[2:])
+	}
+}
+
+func TestAPIErrorRequestID(t *testing.T) {
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "srv-123")
+		errorResponse(w, http.StatusInternalServerError, "boom")
+	})
+
+	ctx, meta := umbra.WithResponseMeta(context.Background())
+	_, err := cli.GetTask(ctx, "test")
+
+	apiErr, ok := err.(*umbra.APIError)
+	if !ok {
+		t.Fatalf("expected APIError, got %T", err)
+	}
+	if apiErr.RequestID != "srv-123" {
+		t.Errorf("expected request ID srv-123, got %q", apiErr.RequestID)
+	}
+	if !strings.Contains(err.Error(), "srv-123") {
+		t.Errorf("expected request ID in %q", err)
+	}
+	if meta.RequestID() != "srv-123" || meta.StatusCode() != http.StatusInternalServerError {
+		t.Errorf("unexpected meta: request ID %q, status %d", meta.RequestID(), meta.StatusCode())
+	}
+}
+
+func TestResponseMeta(t *testing.T) {
+	var sent string
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get(umbra.ClientRequestIDHeader)
+		w.Header().Set("X-Amzn-Trace-Id", "Root=1-abc")
+		jsonResponse(w, http.StatusOK, umbra.Task{ID: "test"})
+	})
+
+	if umbra.MetaFromContext(context.Background()) != nil {
+		t.Fatal("expected no meta on a plain context")
+	}
+
+	ctx, meta := umbra.WithResponseMeta(context.Background())
+	if _, err := cli.GetTask(ctx, "test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if umbra.MetaFromContext(ctx) != meta {
+		t.Fatal("expected MetaFromContext to return the attached meta")
+	}
+	if meta.ClientRequestID() == "" || meta.ClientRequestID() != sent {
+		t.Errorf("expected client request ID %q, got %q", sent, meta.ClientRequestID())
+	}
+	if meta.RequestID() != "Root=1-abc" || meta.StatusCode() != http.StatusOK {
+		t.Errorf("unexpected meta: request ID %q, status %d", meta.RequestID(), meta.StatusCode())
+	}
+}
+
 func TestErrorHelpers(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 )
 
@@ -70,6 +72,43 @@ type Collect struct {
 	CollectEnd   time.Time     `json:"collectEnd,omitempty"`
 	CreatedAt    time.Time     `json:"createdAt"`
 	UpdatedAt    time.Time     `json:"updatedAt"`
+
+	// Geometry is the collect's footprint: a Point while it is planned, a
+	// Polygon or MultiPolygon once imaged, or a long strip for scan modes.
+	Geometry *geojson.Geometry `json:"geometry,omitempty"`
+
+	// ArchiveIDs are the IDs of the archive (STAC) items delivered from the
+	// collect; see GetCollectProducts.
+	ArchiveIDs []string `json:"archiveIds,omitempty"`
+
+	// Extra holds top-level fields the API returned that Collect does not
+	// declare. They are re-emitted when the collect is marshaled.
+	Extra map[string]any `json:"-"`
+}
+
+// Centroid returns the centre of the collect's geometry, for callers that
+// only need a point.
+func (c *Collect) Centroid() (lon, lat float64, err error) {
+	return common.Centroid(c.Geometry)
+}
+
+// FootprintArea returns the area of the collect's footprint in square
+// kilometres; a point geometry has none.
+func (c *Collect) FootprintArea() (float64, error) {
+	return common.AreaSqKm(c.Geometry)
+}
+
+// CoversAOI reports whether the collect's footprint covers at least
+// minFraction, between 0 and 1, of aoi. A point AOI is covered if it lies in
+// the footprint; a point footprint covers no polygon.
+func (c *Collect) CoversAOI(aoi *geojson.Geometry, minFraction float64) (bool, error) {
+	if c.Geometry == nil || c.Geometry.Geometry() == nil || aoi == nil || aoi.Geometry() == nil {
+		return false, common.ErrEmptyGeometry
+	}
+	if _, ok := aoi.Geometry().(orb.Point); ok {
+		return common.Intersects(aoi, c.Geometry), nil
+	}
+	return common.CoveragePercent(aoi, c.Geometry)/100 >= minFraction, nil
 }
 
 // ListCollectsOptions contains optional filters for listing collects.
@@ -2,10 +2,15 @@ package umbra_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
 )
 
@@ -214,3 +219,107 @@ func TestCollectStatusIsTerminal(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectGeometry(t *testing.T) {
+	tests := []struct {
+		name     string
+		geometry string
+		kind     string
+		lon, lat float64
+		hasArea  bool
+	}{
+		{"point", `{"type":"Point","coordinates":[10.5,20.5]}`, "Point", 10.5, 20.5, false},
+		{"polygon", `{"type":"Polygon","coordinates":[[[10,20],[11,20],[11,21],[10,21],[10,20]]]}`, "Polygon", 10.5, 20.5, true},
+		{"multipolygon", `{"type":"MultiPolygon","coordinates":[[[[10,20],[11,20],[11,21],[10,21],[10,20]]],[[[12,20],[13,20],[13,21],[12,21],[12,20]]]]}`, "MultiPolygon", 11.5, 20.5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"id":"col-1","status":"DELIVERED","geometry":%s}`, tt.geometry)
+			})
+
+			col, err := cli.GetCollect(context.Background(), "col-1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if col.Geometry == nil || col.Geometry.Type != tt.kind {
+				t.Fatalf("geometry = %+v, want a %s", col.Geometry, tt.kind)
+			}
+			if _, ok := col.Extra["geometry"]; ok {
+				t.Error("geometry should not be kept in Extra")
+			}
+
+			lon, lat, err := col.Centroid()
+			if err != nil {
+				t.Fatalf("Centroid: %v", err)
+			}
+			if math.Abs(lon-tt.lon) > 1e-9 || math.Abs(lat-tt.lat) > 1e-9 {
+				t.Errorf("Centroid() = %v, %v, want %v, %v", lon, lat, tt.lon, tt.lat)
+			}
+
+			area, err := col.FootprintArea()
+			if err != nil {
+				t.Fatalf("FootprintArea: %v", err)
+			}
+			if (area > 0) != tt.hasArea {
+				t.Errorf("FootprintArea() = %v", area)
+			}
+		})
+	}
+}
+
+func TestCollectFootprintArea(t *testing.T) {
+	// About 111 km x 104 km at 20°N.
+	col := umbra.Collect{Geometry: umbra.NewPolygonGeometry([][][2]float64{{{10, 20}, {11, 20}, {11, 21}, {10, 21}, {10, 20}}})}
+	area, err := col.FootprintArea()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if area < 11000 || area > 12000 {
+		t.Errorf("FootprintArea() = %.0f km², want about 11,600", area)
+	}
+
+	if _, err := (&umbra.Collect{}).FootprintArea(); !errors.Is(err, common.ErrEmptyGeometry) {
+		t.Errorf("FootprintArea() without geometry: err = %v", err)
+	}
+}
+
+func TestCollectCoversAOI(t *testing.T) {
+	col := umbra.Collect{Geometry: umbra.NewPolygonGeometry([][][2]float64{{{10, 20}, {11, 20}, {11, 21}, {10, 21}, {10, 20}}})}
+	// The AOI's western half lies in the footprint.
+	aoi := umbra.NewPolygonGeometry([][][2]float64{{{10.5, 20.2}, {11.5, 20.2}, {11.5, 20.8}, {10.5, 20.8}, {10.5, 20.2}}})
+
+	tests := []struct {
+		name        string
+		aoi         *geojson.Geometry
+		minFraction float64
+		want        bool
+	}{
+		{"half covered, half required", aoi, 0.45, true},
+		{"half covered, most required", aoi, 0.9, false},
+		{"point inside", umbra.NewPointGeometry(10.2, 20.2), 1, true},
+		{"point outside", umbra.NewPointGeometry(12, 20.2), 1, false},
+		{"disjoint", umbra.NewPolygonGeometry([][][2]float64{{{30, 0}, {31, 0}, {31, 1}, {30, 1}, {30, 0}}}), 0.01, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := col.CoversAOI(tt.aoi, tt.minFraction)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("CoversAOI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	point := umbra.Collect{Geometry: umbra.NewPointGeometry(10.5, 20.5)}
+	if ok, err := point.CoversAOI(aoi, 0.01); err != nil || ok {
+		t.Errorf("point footprint CoversAOI() = %v, %v, want false", ok, err)
+	}
+	if _, err := (&umbra.Collect{}).CoversAOI(aoi, 0.5); !errors.Is(err, common.ErrEmptyGeometry) {
+		t.Errorf("CoversAOI() without geometry: err = %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package umbra
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// maxDecodeSnippet caps how much of a response body a DecodeError quotes.
+const maxDecodeSnippet = 256
+
+// ----------------------------------------------------------------------------
+// Response Decoding
+// ----------------------------------------------------------------------------
+
+// WithStrictDecoding makes responses carrying a TaskStatus, ImagingMode or
+// ProductType this package does not know fail to decode with a
+// *DecodeError, instead of keeping the value for callers to check with
+// IsKnown.
+func WithStrictDecoding() Option {
+	return func(c *clientConfig) {
+		c.strictDecoding = true
+	}
+}
+
+// DecodeError is returned when a response body cannot be decoded. It names
+// the call and, where the decoder reports it, the JSON path of the
+// offending value.
+type DecodeError struct {
+	Method string
+	Path   string
+	// Field is the dotted JSON path of the offending value, or "".
+	Field string
+	// Snippet is the start of the response body, or the text around a
+	// syntax error, truncated.
+	Snippet string
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "decode %s %s response", e.Method, e.Path)
+	if e.Field != "" {
+		fmt.Fprintf(&b, " at %s", e.Field)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+	if e.Snippet != "" {
+		fmt.Fprintf(&b, " (body: %s)", e.Snippet)
+	}
+	return b.String()
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// UnknownValueError is the DecodeError cause for an enum value unknown to
+// this package, returned with WithStrictDecoding.
+type UnknownValueError struct {
+	Type  string
+	Value string
+}
+
+func (e *UnknownValueError) Error() string {
+	return fmt.Sprintf("unknown %s %q", e.Type, e.Value)
+}
+
+// DoRaw performs a request like common.Client.DoRaw, decoding the response
+// into respBody. Decode failures are returned as a *DecodeError.
+func (c *Client) DoRaw(ctx context.Context, method string, u *url.URL, body io.Reader, expectedStatus int, respBody any) error {
+	if respBody == nil {
+		return c.Client.DoRaw(ctx, method, u, body, expectedStatus, nil)
+	}
+	var raw []byte
+	if err := c.Client.DoRaw(ctx, method, u, body, expectedStatus, &raw); err != nil {
+		return err
+	}
+	return c.decode(method, u, raw, respBody)
+}
+
+// decode unmarshals a response body of the call method u into v.
+func (c *Client) decode(method string, u *url.URL, data []byte, v any) error {
+	path := "/" + strings.TrimPrefix(u.Path, "/")
+	err := json.Unmarshal(data, v)
+	if err == nil && c.strictDecoding {
+		if field, value, ok := unknownEnum(reflect.ValueOf(v), ""); ok {
+			return &DecodeError{Method: method, Path: path, Field: field, Err: value}
+		}
+	}
+	if err == nil {
+		return nil
+	}
+
+	de := &DecodeError{Method: method, Path: path, Err: err, Snippet: snippet(data, 0)}
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		de.Field = typeErr.Field
+	case errors.As(err, &syntaxErr):
+		de.Snippet = snippet(data, syntaxErr.Offset)
+	}
+	return de
+}
+
+// snippet returns up to maxDecodeSnippet bytes of data centred on offset,
+// or from the start if offset is near 0.
+func snippet(data []byte, offset int64) string {
+	start := max(int(offset)-maxDecodeSnippet/2, 0)
+	end := min(start+maxDecodeSnippet, len(data))
+	s := strings.ToValidUTF8(string(data[start:end]), "")
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(data) {
+		s += "…"
+	}
+	return s
+}
+
+// knowable is implemented by the enum types with an IsKnown method.
+type knowable interface {
+	IsKnown() bool
+}
+
+var knowableType = reflect.TypeFor[knowable]()
+
+// unknownEnum finds the first non-empty value in v whose type has an
+// IsKnown method returning false, and returns its JSON path.
+func unknownEnum(v reflect.Value, path string) (field string, err *UnknownValueError, ok bool) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() > 0 && v.Type().Implements(knowableType) && !v.Interface().(knowable).IsKnown() {
+			return path, &UnknownValueError{Type: v.Type().Name(), Value: v.String()}, true
+		}
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			return unknownEnum(v.Elem(), path)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			p := path
+			if !f.Anonymous || name != "" {
+				p = joinPath(path, cmp.Or(name, f.Name))
+			}
+			if field, err, ok := unknownEnum(v.Field(i), p); ok {
+				return field, err, ok
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if scalarKind(v.Type().Elem().Kind()) {
+			return "", nil, false
+		}
+		for i := range v.Len() {
+			if field, err, ok := unknownEnum(v.Index(i), path+"["+strconv.Itoa(i)+"]"); ok {
+				return field, err, ok
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return "", nil, false
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if field, err, ok := unknownEnum(iter.Value(), joinPath(path, iter.Key().String())); ok {
+				return field, err, ok
+			}
+		}
+	}
+	return "", nil, false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// scalarKind reports whether values of kind k cannot hold an enum, so
+// slices of them, such as coordinates, need not be walked.
+func scalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package umbra_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+const taskWithNewStatus = `{
+	"id": "task-1",
+	"status": "QUEUED_FOR_DOWNLINK",
+	"imagingMode": "SPOTLIGHT",
+	"productTypes": ["GEC", "CSI"],
+	"statusHistory": [{"status": "RECEIVED", "timestamp": "2025-01-01T00:00:00Z"}]
+}`
+
+func TestDecode_UnknownEnumPreserved(t *testing.T) {
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(taskWithNewStatus))
+	})
+
+	task, err := cli.GetTask(context.Background(), "task-1")
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if task.Status != "QUEUED_FOR_DOWNLINK" || task.Status.IsKnown() {
+		t.Errorf("expected the new status to be kept and flagged, got %q (known %v)", task.Status, task.Status.IsKnown())
+	}
+	if !task.ImagingMode.IsKnown() || !task.ProductTypes[0].IsKnown() || task.ProductTypes[1].IsKnown() {
+		t.Errorf("unexpected IsKnown results for %q and %v", task.ImagingMode, task.ProductTypes)
+	}
+	if !umbra.TaskStatusDelivered.IsKnown() || umbra.TaskStatus("").IsKnown() {
+		t.Error("unexpected IsKnown for the constants")
+	}
+}
+
+func TestDecode_StrictMode(t *testing.T) {
+	srv := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(taskWithNewStatus))
+	}
+	_, server := newTestClient(t, srv)
+	cli, err := umbra.NewClient("test-token", umbra.WithBaseURL(server.URL), umbra.WithStrictDecoding())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cli.GetTask(context.Background(), "task-1")
+	var decodeErr *umbra.DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected DecodeError, got %v", err)
+	}
+	var unknown *umbra.UnknownValueError
+	if !errors.As(err, &unknown) || unknown.Type != "TaskStatus" || unknown.Value != "QUEUED_FOR_DOWNLINK" {
+		t.Errorf("unexpected cause %v", decodeErr.Err)
+	}
+	if decodeErr.Field != "status" || decodeErr.Method != http.MethodGet || decodeErr.Path != "/tasking/tasks/task-1" {
+		t.Errorf("unexpected error context %+v", decodeErr)
+	}
+}
+
+func TestDecode_MalformedBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+		wantInMsg []string
+	}{
+		{
+			name:      "wrong type",
+			body:      `{"id": "task-1", "statusHistory": [{"status": 7}]}`,
+			wantField: "statusHistory.0.status",
+			wantInMsg: []string{"GET /tasking/tasks/task-1", "statusHistory.0.status", `(body: {"id": "task-1"`},
+		},
+		{
+			name:      "truncated",
+			body:      `{"id": "task-1", "status": "ACTIVE", "taskName": "` + strings.Repeat("x", 500),
+			wantInMsg: []string{"GET /tasking/tasks/task-1", "unexpected end of JSON input", "…"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			})
+			_, err := cli.GetTask(context.Background(), "task-1")
+			var decodeErr *umbra.DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("expected DecodeError, got %v", err)
+			}
+			if decodeErr.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q", decodeErr.Field, tt.wantField)
+			}
+			if len(decodeErr.Snippet) > 300 {
+				t.Errorf("snippet not truncated: %d bytes", len(decodeErr.Snippet))
+			}
+			for _, want := range tt.wantInMsg {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
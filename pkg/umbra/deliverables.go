@@ -0,0 +1,126 @@
+package umbra
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Delivered Products
+// ----------------------------------------------------------------------------
+
+// CollectProduct is a product delivered from a collect: one asset of one of
+// the collect's archive items.
+type CollectProduct struct {
+	CollectID string
+	// ItemID is the ID of the archive (STAC) item holding the product, for
+	// GetArchiveItem.
+	ItemID string
+	// ProductType is the asset key upper-cased, e.g. GEC or SICD; check it
+	// with IsKnown.
+	ProductType ProductType
+	Href        string
+	MediaType   string
+	// DeliveredAt is when the asset was created, zero if not reported.
+	DeliveredAt time.Time
+}
+
+// GetCollectProducts returns the products delivered from a collect, read
+// from the assets of its archive items and ordered by item and product
+// type. A collect without products, e.g. one whose processing failed,
+// returns an empty slice.
+func (c *Client) GetCollectProducts(ctx context.Context, collectID string) ([]CollectProduct, error) {
+	col, err := c.GetCollect(ctx, collectID)
+	if err != nil {
+		return nil, err
+	}
+	return c.collectProducts(ctx, collectID, col.ArchiveIDs)
+}
+
+func (c *Client) collectProducts(ctx context.Context, collectID string, itemIDs []string) ([]CollectProduct, error) {
+	products := []CollectProduct{}
+	for _, itemID := range itemIDs {
+		item, err := c.GetArchiveItem(ctx, itemID)
+		if err != nil {
+			return nil, fmt.Errorf("archive item %s of collect %s: %w", itemID, collectID, err)
+		}
+		keys := make([]string, 0, len(item.Assets))
+		for key := range item.Assets {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			asset := item.Assets[key]
+			products = append(products, CollectProduct{
+				CollectID:   collectID,
+				ItemID:      item.ID,
+				ProductType: ProductType(strings.ToUpper(key)),
+				Href:        asset.Href,
+				MediaType:   asset.Type,
+				DeliveredAt: asset.Created,
+			})
+		}
+	}
+	return products, nil
+}
+
+// CollectDeliverables are the products of one collect of a task.
+type CollectDeliverables struct {
+	CollectID string
+	// ItemIDs are the IDs of the collect's archive (STAC) items.
+	ItemIDs  []string
+	Products []CollectProduct
+	// Err is why the collect's products could not be retrieved, e.g. a 404
+	// for a collect that no longer exists. Products is then empty.
+	Err error
+}
+
+// TaskDeliverables are the products delivered for a task, grouped by
+// collect.
+type TaskDeliverables struct {
+	TaskID string
+	// Collects has an entry for each of the task's CollectIDs, in order,
+	// including collects without products.
+	Collects []CollectDeliverables
+}
+
+// Products returns the products of all collects.
+func (d *TaskDeliverables) Products() []CollectProduct {
+	var all []CollectProduct
+	for _, col := range d.Collects {
+		all = append(all, col.Products...)
+	}
+	return all
+}
+
+// GetTaskDeliverables returns the products delivered for each of a task's
+// collects. A collect whose products cannot be retrieved keeps its entry
+// with Err set, so one missing collect does not hide the others; only a
+// failure to get the task is returned as an error.
+func (c *Client) GetTaskDeliverables(ctx context.Context, taskID string) (*TaskDeliverables, error) {
+	task, err := c.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	out := &TaskDeliverables{TaskID: taskID, Collects: make([]CollectDeliverables, 0, len(task.CollectIDs))}
+	for _, collectID := range task.CollectIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		entry := CollectDeliverables{CollectID: collectID, ItemIDs: []string{}, Products: []CollectProduct{}}
+		col, err := c.GetCollect(ctx, collectID)
+		if err == nil {
+			var products []CollectProduct
+			if products, err = c.collectProducts(ctx, collectID, col.ArchiveIDs); err == nil {
+				entry.ItemIDs = append(entry.ItemIDs, col.ArchiveIDs...)
+				entry.Products = products
+			}
+		}
+		entry.Err = err
+		out.Collects = append(out.Collects, entry)
+	}
+	return out, nil
+}
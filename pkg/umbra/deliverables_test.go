@@ -0,0 +1,111 @@
+package umbra_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+var deliveredAt = time.Date(2025, 6, 3, 12, 0, 0, 0, time.UTC)
+
+// deliverablesHandler serves task T-1 with four collects: C-1 with one
+// archive item, C-2 with two, C-3 with none (processing failed) and C-4,
+// which does not exist.
+func deliverablesHandler(t *testing.T) http.HandlerFunc {
+	archive := map[string][]string{"C-1": {"A-1"}, "C-2": {"A-2", "A-3"}, "C-3": nil}
+	assets := map[string]map[string]umbra.STACAsset{
+		"A-1": {
+			"GEC":  {Href: "https://example.com/A-1/gec.tif", Type: "image/tiff", Created: deliveredAt},
+			"SICD": {Href: "https://example.com/A-1/sicd.nitf", Type: "application/octet-stream", Created: deliveredAt},
+		},
+		"A-2": {"GEC": {Href: "https://example.com/A-2/gec.tif", Type: "image/tiff"}},
+		"A-3": {"metadata": {Href: "https://example.com/A-3/meta.json", Type: "application/json"}},
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodGet)
+		switch path := r.URL.Path; {
+		case path == "/tasking/tasks/T-1":
+			jsonResponse(w, http.StatusOK, map[string]any{"id": "T-1", "collectIds": []string{"C-1", "C-2", "C-3", "C-4"}})
+		case strings.HasPrefix(path, "/tasking/collects/"):
+			id := strings.TrimPrefix(path, "/tasking/collects/")
+			ids, ok := archive[id]
+			if !ok {
+				jsonResponse(w, http.StatusNotFound, map[string]string{"detail": "collect not found"})
+				return
+			}
+			jsonResponse(w, http.StatusOK, map[string]any{"id": id, "taskId": "T-1", "archiveIds": ids})
+		case strings.HasPrefix(path, "/archive/items/"):
+			id := strings.TrimPrefix(path, "/archive/items/")
+			jsonResponse(w, http.StatusOK, umbra.STACItem{ID: id, Type: "Feature", Assets: assets[id]})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestGetCollectProducts(t *testing.T) {
+	cli, _ := newTestClient(t, deliverablesHandler(t))
+
+	products, err := cli.GetCollectProducts(context.Background(), "C-1")
+	if err != nil {
+		t.Fatalf("GetCollectProducts: %v", err)
+	}
+	want := []umbra.CollectProduct{
+		{CollectID: "C-1", ItemID: "A-1", ProductType: umbra.ProductTypeGEC, Href: "https://example.com/A-1/gec.tif", MediaType: "image/tiff", DeliveredAt: deliveredAt},
+		{CollectID: "C-1", ItemID: "A-1", ProductType: umbra.ProductTypeSICD, Href: "https://example.com/A-1/sicd.nitf", MediaType: "application/octet-stream", DeliveredAt: deliveredAt},
+	}
+	if !slices.EqualFunc(products, want, func(a, b umbra.CollectProduct) bool {
+		return a.CollectID == b.CollectID && a.ItemID == b.ItemID && a.ProductType == b.ProductType &&
+			a.Href == b.Href && a.MediaType == b.MediaType && a.DeliveredAt.Equal(b.DeliveredAt)
+	}) {
+		t.Errorf("products = %+v, want %+v", products, want)
+	}
+
+	products, err = cli.GetCollectProducts(context.Background(), "C-3")
+	if err != nil || products == nil || len(products) != 0 {
+		t.Errorf("collect without products = %v, %v; want an empty slice", products, err)
+	}
+
+	if _, err := cli.GetCollectProducts(context.Background(), "C-4"); !umbra.IsNotFound(err) {
+		t.Errorf("missing collect err = %v, want 404", err)
+	}
+}
+
+func TestGetTaskDeliverables(t *testing.T) {
+	cli, _ := newTestClient(t, deliverablesHandler(t))
+
+	d, err := cli.GetTaskDeliverables(context.Background(), "T-1")
+	if err != nil {
+		t.Fatalf("GetTaskDeliverables: %v", err)
+	}
+	if d.TaskID != "T-1" || len(d.Collects) != 4 {
+		t.Fatalf("deliverables = %+v, want an entry per collect", d)
+	}
+
+	c1, c2, c3, c4 := d.Collects[0], d.Collects[1], d.Collects[2], d.Collects[3]
+	if c1.CollectID != "C-1" || c1.Err != nil || len(c1.Products) != 2 || !slices.Equal(c1.ItemIDs, []string{"A-1"}) {
+		t.Errorf("C-1 = %+v", c1)
+	}
+	if c2.CollectID != "C-2" || c2.Err != nil || !slices.Equal(c2.ItemIDs, []string{"A-2", "A-3"}) {
+		t.Errorf("C-2 = %+v", c2)
+	}
+	if len(c2.Products) != 2 || c2.Products[1].ProductType != umbra.ProductTypeMetadata {
+		t.Errorf("C-2 products = %+v", c2.Products)
+	}
+	if c3.CollectID != "C-3" || c3.Err != nil || c3.Products == nil || len(c3.Products) != 0 {
+		t.Errorf("C-3 = %+v, want an empty product list", c3)
+	}
+	if c4.CollectID != "C-4" || !umbra.IsNotFound(c4.Err) || len(c4.Products) != 0 {
+		t.Errorf("C-4 = %+v, want a 404 on the entry", c4)
+	}
+
+	if got := len(d.Products()); got != 4 {
+		t.Errorf("Products() has %d products, want 4", got)
+	}
+}
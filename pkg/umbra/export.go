@@ -0,0 +1,160 @@
+package umbra
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+)
+
+// icsTimeFormat is the iCalendar UTC date-time format.
+const icsTimeFormat = "20060102T150405Z"
+
+// OpportunitiesToICS renders the opportunities of a feasibility request as
+// an iCalendar file with one event per opportunity, for sharing collection
+// windows in team calendars. Event UIDs are derived from the satellite and
+// window, so re-importing an updated export replaces existing events.
+func OpportunitiesToICS(feas *Feasibility, calendarName string) ([]byte, error) {
+	if feas == nil {
+		return nil, errors.New("feasibility is nil")
+	}
+
+	// DTSTAMP is required; the feasibility's own timestamps keep the output
+	// stable across exports of the same result.
+	stamp := feas.UpdatedAt
+	if stamp.IsZero() {
+		stamp = feas.CreatedAt
+	}
+
+	var buf bytes.Buffer
+	w := func(name, value string) { writeICSLine(&buf, name+":"+value) }
+	w("BEGIN", "VCALENDAR")
+	w("VERSION", "2.0")
+	w("PRODID", "-//go-sar-vendor//Umbra opportunities//EN")
+	w("CALSCALE", "GREGORIAN")
+	if calendarName != "" {
+		w("X-WR-CALNAME", escapeICSText(calendarName))
+	}
+	for _, o := range feas.Opportunities {
+		start, end := o.WindowStartAt.UTC(), o.WindowEndAt.UTC()
+		satellite := cmp.Or(o.SatelliteID, "unknown satellite")
+		eventStamp := stamp
+		if eventStamp.IsZero() {
+			eventStamp = start
+		}
+
+		w("BEGIN", "VEVENT")
+		w("UID", opportunityUID(o))
+		w("DTSTAMP", eventStamp.UTC().Format(icsTimeFormat))
+		w("DTSTART", start.Format(icsTimeFormat))
+		w("DTEND", end.Format(icsTimeFormat))
+		w("SUMMARY", escapeICSText(fmt.Sprintf("Umbra %s, grazing %.1f-%.1f°",
+			satellite, o.GrazingAngleStartDegrees, o.GrazingAngleEndDegrees)))
+		w("DESCRIPTION", escapeICSText(fmt.Sprintf(
+			"Feasibility: %s\nImaging mode: %s\nDuration: %.0f s\nTarget azimuth: %.1f-%.1f°\nSlant range: %.1f-%.1f km",
+			feas.ID, feas.ImagingMode, o.DurationSec,
+			o.TargetAzimuthAngleStartDegrees, o.TargetAzimuthAngleEndDegrees,
+			o.SlantRangeStartKm, o.SlantRangeEndKm)))
+		w("END", "VEVENT")
+	}
+	w("END", "VCALENDAR")
+	return buf.Bytes(), nil
+}
+
+// opportunityUID identifies an opportunity by satellite and window.
+func opportunityUID(o Opportunity) string {
+	sat := strings.ToLower(cmp.Or(o.SatelliteID, "unknown"))
+	return fmt.Sprintf("%s-%s-%s@umbra-opportunities",
+		strings.ReplaceAll(sat, " ", "-"),
+		o.WindowStartAt.UTC().Format(icsTimeFormat), o.WindowEndAt.UTC().Format(icsTimeFormat))
+}
+
+// escapeICSText escapes a TEXT value per RFC 5545 section 3.3.11.
+func escapeICSText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// writeICSLine writes a content line terminated by CRLF, folding it after
+// 75 octets without splitting UTF-8 sequences.
+func writeICSLine(buf *bytes.Buffer, line string) {
+	const limit = 75
+	width := limit
+	for len(line) > width {
+		cut := width
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		width = limit - 1 // the leading space counts toward the limit
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// OpportunitiesToGeoJSON returns one feature per opportunity of a
+// feasibility request, for plotting windows on a map. Each feature has the
+// request geometry (the spotlight target, or a line from the scan start to
+// end point) and the opportunity metrics as properties.
+func OpportunitiesToGeoJSON(feas *Feasibility) (*geojson.FeatureCollection, error) {
+	if feas == nil {
+		return nil, errors.New("feasibility is nil")
+	}
+	geom := feasibilityGeometry(feas)
+
+	fc := geojson.NewFeatureCollection()
+	for i, o := range feas.Opportunities {
+		f := geojson.NewFeature(geom)
+		f.ID = opportunityUID(o)
+		f.Properties = geojson.Properties{
+			"feasibilityId":                  feas.ID,
+			"imagingMode":                    string(feas.ImagingMode),
+			"index":                          i,
+			"satelliteId":                    o.SatelliteID,
+			"windowStartAt":                  o.WindowStartAt.UTC().Format(time.RFC3339),
+			"windowEndAt":                    o.WindowEndAt.UTC().Format(time.RFC3339),
+			"durationSec":                    o.DurationSec,
+			"grazingAngleStartDegrees":       o.GrazingAngleStartDegrees,
+			"grazingAngleEndDegrees":         o.GrazingAngleEndDegrees,
+			"targetAzimuthAngleStartDegrees": o.TargetAzimuthAngleStartDegrees,
+			"targetAzimuthAngleEndDegrees":   o.TargetAzimuthAngleEndDegrees,
+			"squintAngleStartDegrees":        o.SquintAngleStartDegrees,
+			"squintAngleEndDegrees":          o.SquintAngleEndDegrees,
+			"slantRangeStartKm":              o.SlantRangeStartKm,
+			"slantRangeEndKm":                o.SlantRangeEndKm,
+			"groundRangeStartKm":             o.GroundRangeStartKm,
+			"groundRangeEndKm":               o.GroundRangeEndKm,
+		}
+		fc.Append(f)
+	}
+	return fc, nil
+}
+
+// feasibilityGeometry returns the target geometry of a feasibility request,
+// or nil if it has none.
+func feasibilityGeometry(feas *Feasibility) orb.Geometry {
+	if sc := feas.SpotlightConstraints; sc != nil && sc.Geometry != nil {
+		return sc.Geometry.Geometry()
+	}
+	if sc := feas.ScanConstraints; sc != nil && sc.StartPoint != nil && sc.EndPoint != nil {
+		start, ok1 := sc.StartPoint.Geometry().(orb.Point)
+		end, ok2 := sc.EndPoint.Geometry().(orb.Point)
+		if ok1 && ok2 {
+			return orb.LineString{start, end}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package umbra_test
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+func exportFeasibility() *umbra.Feasibility {
+	start := time.Date(2025, 3, 10, 14, 2, 0, 0, time.UTC)
+	return &umbra.Feasibility{
+		ID:          "feas-1",
+		ImagingMode: umbra.ImagingModeSpotlight,
+		SpotlightConstraints: &umbra.SpotlightConstraints{
+			Geometry: geojson.NewGeometry(orb.Point{-119.7, 34.4}),
+		},
+		UpdatedAt: time.Date(2025, 3, 9, 8, 0, 0, 0, time.UTC),
+		Opportunities: []umbra.Opportunity{
+			{
+				SatelliteID:                    "UMBRA_05",
+				WindowStartAt:                  start,
+				WindowEndAt:                    start.Add(40 * time.Second),
+				DurationSec:                    40,
+				GrazingAngleStartDegrees:       42.5,
+				GrazingAngleEndDegrees:         47.25,
+				TargetAzimuthAngleStartDegrees: 80,
+				TargetAzimuthAngleEndDegrees:   95.5,
+				SlantRangeStartKm:              712.3,
+				SlantRangeEndKm:                690.1,
+			},
+			{
+				WindowStartAt:            start.Add(26 * time.Hour),
+				WindowEndAt:              start.Add(26*time.Hour + 30*time.Second),
+				DurationSec:              30,
+				GrazingAngleStartDegrees: 30,
+				GrazingAngleEndDegrees:   33,
+			},
+		},
+	}
+}
+
+func TestOpportunitiesToICS_Golden(t *testing.T) {
+	got, err := umbra.OpportunitiesToICS(exportFeasibility(), "Ops; Santa Barbara, CA")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	golden, err := os.ReadFile("testdata/opportunities.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(golden) {
+		t.Errorf("ICS output differs from golden file:\n%s", got)
+	}
+}
+
+func TestOpportunitiesToICS_NoOpportunities(t *testing.T) {
+	got, err := umbra.OpportunitiesToICS(&umbra.Feasibility{ID: "feas-2"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//go-sar-vendor//Umbra opportunities//EN\r\nCALSCALE:GREGORIAN\r\nEND:VCALENDAR\r\n"
+	if string(got) != want {
+		t.Errorf("expected an empty calendar, got %q", got)
+	}
+
+	if _, err := umbra.OpportunitiesToICS(nil, "x"); err == nil {
+		t.Error("expected an error for a nil feasibility")
+	}
+}
+
+func TestOpportunitiesToICS_LineLength(t *testing.T) {
+	feas := exportFeasibility()
+	feas.ID = strings.Repeat("é", 60)
+	got, err := umbra.OpportunitiesToICS(feas, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range strings.Split(string(got), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+}
+
+func TestOpportunitiesToGeoJSON(t *testing.T) {
+	fc, err := umbra.OpportunitiesToGeoJSON(exportFeasibility())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fc.Features) != 2 {
+		t.Fatalf("expected 2 features, got %d", len(fc.Features))
+	}
+
+	// Every opportunity field must appear as a property.
+	var fields map[string]any
+	data, _ := json.Marshal(umbra.Opportunity{})
+	json.Unmarshal(data, &fields)
+	for _, f := range fc.Features {
+		if f.Geometry != (orb.Point{-119.7, 34.4}) {
+			t.Errorf("expected the spotlight target geometry, got %v", f.Geometry)
+		}
+		for name := range fields {
+			if _, ok := f.Properties[name]; !ok {
+				t.Errorf("feature %v is missing property %q", f.ID, name)
+			}
+		}
+	}
+	p := fc.Features[0].Properties
+	if p["feasibilityId"] != "feas-1" || p["satelliteId"] != "UMBRA_05" || p["windowStartAt"] != "2025-03-10T14:02:00Z" {
+		t.Errorf("unexpected properties: %v", p)
+	}
+
+	// Scan requests are drawn as a line from start to end point.
+	scan := &umbra.Feasibility{
+		ImagingMode: umbra.ImagingModeScan,
+		ScanConstraints: &umbra.ScanConstraints{
+			StartPoint: geojson.NewGeometry(orb.Point{1, 2}),
+			EndPoint:   geojson.NewGeometry(orb.Point{3, 4}),
+		},
+		Opportunities: []umbra.Opportunity{{}},
+	}
+	fc, err = umbra.OpportunitiesToGeoJSON(scan)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (orb.LineString{{1, 2}, {3, 4}}); !reflect.DeepEqual(fc.Features[0].Geometry, want) {
+		t.Errorf("expected %v, got %v", want, fc.Features[0].Geometry)
+	}
+
+	fc, err = umbra.OpportunitiesToGeoJSON(&umbra.Feasibility{})
+	if err != nil || len(fc.Features) != 0 {
+		t.Errorf("expected an empty collection, got %v, %v", fc, err)
+	}
+	if data, _ := json.Marshal(fc); !strings.Contains(string(data), `"features":[]`) {
+		t.Errorf("expected an empty features array, got %s", data)
+	}
+}
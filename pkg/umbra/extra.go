@@ -0,0 +1,141 @@
+package umbra
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ----------------------------------------------------------------------------
+// Unknown Field Preservation
+// ----------------------------------------------------------------------------
+
+// taskJSON and collectJSON have the fields of Task and Collect but not their
+// JSON methods, so they decode and encode the known fields as usual.
+type (
+	taskJSON    Task
+	collectJSON Collect
+)
+
+// UnmarshalJSON decodes the known fields of a task and keeps any other
+// top-level fields in Extra.
+func (t *Task) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, (*taskJSON)(t), &t.Extra)
+}
+
+// MarshalJSON encodes the task together with the fields in Extra.
+func (t Task) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(taskJSON(t), t.Extra)
+}
+
+// UnmarshalJSON decodes the known fields of a collect and keeps any other
+// top-level fields in Extra.
+func (c *Collect) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, (*collectJSON)(c), &c.Extra)
+}
+
+// MarshalJSON encodes the collect together with the fields in Extra.
+func (c Collect) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(collectJSON(c), c.Extra)
+}
+
+// unmarshalWithExtra decodes data into v, a pointer to a struct, and stores
+// the top-level keys v does not declare in extra. Numbers in extra are kept
+// as json.Number so they re-encode unchanged.
+func unmarshalWithExtra(data []byte, v any, extra *map[string]any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		// A JSON null leaves the value unchanged.
+		return err
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v).Elem())
+	*extra = nil
+	for k, raw := range fields {
+		if known[strings.ToLower(k)] {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var val any
+		if err := dec.Decode(&val); err != nil {
+			return err
+		}
+		if *extra == nil {
+			*extra = make(map[string]any)
+		}
+		(*extra)[k] = val
+	}
+	return nil
+}
+
+// marshalWithExtra encodes v, a struct, and appends the fields in extra that
+// v does not declare, sorted by key. Declared fields take precedence.
+func marshalWithExtra(v any, extra map[string]any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v))
+	buf := bytes.NewBuffer(b[:len(b)-1]) // drop the closing brace
+	empty := len(b) == 2
+	for _, k := range slices.Sorted(maps.Keys(extra)) {
+		if known[strings.ToLower(k)] {
+			continue
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(extra[k])
+		if err != nil {
+			return nil, err
+		}
+		if !empty {
+			buf.WriteByte(',')
+		}
+		empty = false
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// fieldNames caches jsonFieldNames per struct type.
+var fieldNames sync.Map // reflect.Type -> map[string]bool
+
+// jsonFieldNames returns the lower-cased JSON names of the fields of struct
+// type t, matching encoding/json's case-insensitive key matching.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	if names, ok := fieldNames.Load(t); ok {
+		return names.(map[string]bool)
+	}
+	names := make(map[string]bool)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		names[strings.ToLower(name)] = true
+	}
+	fieldNames.Store(t, names)
+	return names
+}
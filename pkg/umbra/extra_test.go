@@ -0,0 +1,200 @@
+package umbra_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+const taskWithUnknownFields = `{
+	"id": "task-1",
+	"taskName": "Port of Oakland",
+	"status": "SCHEDULED",
+	"imagingMode": "SPOTLIGHT",
+	"windowStartAt": "2025-06-01T00:00:00Z",
+	"windowEndAt": "2025-06-08T00:00:00Z",
+	"productTypes": ["GEC", "SICD"],
+	"collectIds": ["collect-1"],
+	"createdAt": "2025-05-30T12:00:00Z",
+	"updatedAt": "2025-05-31T08:30:00.5Z",
+	"statusHistory": [{"status": "SUBMITTED", "timestamp": "2025-05-30T12:00:00Z"}],
+	"priorityTier": "STANDARD",
+	"cloudCoverEstimate": 12.50,
+	"routing": {"region": "us-west", "hops": [1, 2]}
+}`
+
+// requireSameJSON fails unless a and b hold the same top-level keys with
+// byte-identical (compacted) values.
+func requireSameJSON(t *testing.T, want, got []byte) {
+	t.Helper()
+	var w, g map[string]json.RawMessage
+	if err := json.Unmarshal(want, &w); err != nil {
+		t.Fatalf("decode want: %v", err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("decode got: %v", err)
+	}
+	if len(w) != len(g) {
+		t.Errorf("expected %d keys, got %d: %s", len(w), len(g), got)
+	}
+	for k, wv := range w {
+		gv, ok := g[k]
+		if !ok {
+			t.Errorf("key %q was dropped", k)
+			continue
+		}
+		if compact(t, wv) != compact(t, gv) {
+			t.Errorf("key %q: expected %s, got %s", k, compact(t, wv), compact(t, gv))
+		}
+	}
+}
+
+func compact(t *testing.T, raw json.RawMessage) string {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	// Re-encoding sorts object keys; json.Number keeps numbers' text.
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func TestTask_RoundTripsUnknownFields(t *testing.T) {
+	var task umbra.Task
+	if err := json.Unmarshal([]byte(taskWithUnknownFields), &task); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	// Known fields still decode as before.
+	if task.ID != "task-1" || task.Status != umbra.TaskStatusScheduled || task.ImagingMode != umbra.ImagingModeSpotlight {
+		t.Errorf("unexpected known fields: %+v", task)
+	}
+	if want := time.Date(2025, 5, 31, 8, 30, 0, 5e8, time.UTC); !task.UpdatedAt.Equal(want) {
+		t.Errorf("expected updatedAt %v, got %v", want, task.UpdatedAt)
+	}
+	if len(task.StatusHistory) != 1 || task.StatusHistory[0].Status != umbra.TaskStatusSubmitted {
+		t.Errorf("unexpected status history: %+v", task.StatusHistory)
+	}
+
+	if len(task.Extra) != 3 {
+		t.Fatalf("expected 3 extra fields, got %v", task.Extra)
+	}
+	if task.Extra["priorityTier"] != "STANDARD" {
+		t.Errorf("unexpected priorityTier: %v", task.Extra["priorityTier"])
+	}
+
+	out, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	requireSameJSON(t, []byte(taskWithUnknownFields), out)
+}
+
+func TestTask_KnownFieldsWinOverExtra(t *testing.T) {
+	task := umbra.Task{ID: "task-1", Extra: map[string]any{"id": "other", "Status": "X", "tier": "gold"}}
+	out, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("invalid JSON %s: %v", out, err)
+	}
+	if got["id"] != "task-1" || got["tier"] != "gold" || got["Status"] != nil {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
+
+func TestTask_NoUnknownFields(t *testing.T) {
+	var task umbra.Task
+	if err := json.Unmarshal([]byte(`{"id":"task-1","status":"ACTIVE"}`), &task); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if task.Extra != nil {
+		t.Errorf("expected no extra fields, got %v", task.Extra)
+	}
+}
+
+func TestCollect_RoundTripsUnknownFields(t *testing.T) {
+	payload := `{
+		"id": "collect-1",
+		"taskId": "task-1",
+		"status": "DELIVERED",
+		"satelliteId": "UMBRA_08",
+		"collectStart": "2025-06-02T10:00:00Z",
+		"collectEnd": "2025-06-02T10:00:30Z",
+		"createdAt": "2025-06-02T09:00:00Z",
+		"updatedAt": "2025-06-02T11:00:00Z",
+		"cloudCoverEstimate": 0,
+		"downlinkStation": "SVALBARD",
+		"quality": {"nesz": -20.5}
+	}`
+	var collect umbra.Collect
+	if err := json.Unmarshal([]byte(payload), &collect); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if collect.Status != umbra.CollectStatusDelivered || collect.SatelliteID != "UMBRA_08" {
+		t.Errorf("unexpected known fields: %+v", collect)
+	}
+	if keys := reflect.ValueOf(collect.Extra).MapKeys(); len(keys) != 3 {
+		t.Errorf("expected 3 extra fields, got %v", collect.Extra)
+	}
+
+	out, err := json.Marshal(collect)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	requireSameJSON(t, []byte(payload), out)
+}
+
+// plainTask has Task's fields but the default JSON decoding.
+type plainTask umbra.Task
+
+func BenchmarkTaskUnmarshal(b *testing.B) {
+	data := []byte(taskWithUnknownFields)
+	b.Run("extra", func(b *testing.B) {
+		for b.Loop() {
+			var task umbra.Task
+			if err := json.Unmarshal(data, &task); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("plain", func(b *testing.B) {
+		for b.Loop() {
+			var task plainTask
+			if err := json.Unmarshal(data, &task); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkTaskMarshal(b *testing.B) {
+	var task umbra.Task
+	if err := json.Unmarshal([]byte(taskWithUnknownFields), &task); err != nil {
+		b.Fatal(err)
+	}
+	b.Run("extra", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := json.Marshal(task); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("plain", func(b *testing.B) {
+		plain := plainTask(task)
+		for b.Loop() {
+			if _, err := json.Marshal(plain); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
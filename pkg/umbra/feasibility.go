@@ -1,9 +1,16 @@
 package umbra
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"iter"
+	"maps"
 	"net/http"
+	"net/url"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
@@ -67,9 +74,18 @@ type FeasibilityListResponse struct {
 	Offset        int           `json:"offset"`
 }
 
-// CreateFeasibility submits a new feasibility request.
+// CreateFeasibility submits a new feasibility request. With
+// WithFeasibilityCache, a logically identical recent request is returned
+// instead.
 // POST /tasking/feasibilities
 func (c *Client) CreateFeasibility(ctx context.Context, req *CreateFeasibilityRequest) (*Feasibility, error) {
+	if c.feasibilities != nil {
+		return c.createFeasibilityCached(ctx, req)
+	}
+	return c.createFeasibility(ctx, req)
+}
+
+func (c *Client) createFeasibility(ctx context.Context, req *CreateFeasibilityRequest) (*Feasibility, error) {
 	body, err := common.MarshalBody(req)
 	if err != nil {
 		return nil, err
@@ -84,6 +100,9 @@ func (c *Client) CreateFeasibility(ctx context.Context, req *CreateFeasibilityRe
 func (c *Client) GetFeasibility(ctx context.Context, id string) (*Feasibility, error) {
 	var out Feasibility
 	err := c.DoRaw(ctx, http.MethodGet, c.BaseURL().JoinPath("tasking", "feasibilities", id), nil, http.StatusOK, &out)
+	if err == nil && c.feasibilities != nil {
+		c.feasibilities.update(&out)
+	}
 	return &out, err
 }
 
@@ -115,28 +134,204 @@ func (c *Client) WaitForFeasibilityCompletion(ctx context.Context, id string, op
 		}
 	}
 
-	deadline := time.Now().Add(opts.Timeout)
-	ticker := time.NewTicker(opts.PollInterval)
-	defer ticker.Stop()
-
-	for {
-		f, err := c.GetFeasibility(ctx, id)
+	var f *Feasibility
+	err := common.Poll(ctx, *opts, func(ctx context.Context) (bool, error) {
+		var err error
+		f, err = c.GetFeasibility(ctx, id)
 		if err != nil {
-			return nil, err
+			return false, err
 		}
+		return isFeasibilityDone(f), nil
+	})
+	if errors.Is(err, common.ErrWaitTimeout) {
+		return nil, fmt.Errorf("timeout waiting for feasibility %s: %w", id, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// BatchOptions configures CreateFeasibilityBatch.
+type BatchOptions struct {
+	// Concurrency bounds the number of requests submitted or polled at once.
+	// Defaults to 4.
+	Concurrency int
+	// PollInterval is the interval of the ticker shared by all in-flight
+	// requests. Defaults to 2 seconds.
+	PollInterval time.Duration
+	// Timeout bounds how long each request may take to complete after it is
+	// submitted. Defaults to 5 minutes.
+	Timeout time.Duration
+	// FailFast stops the batch after the first per-request error.
+	FailFast bool
+}
+
+// FeasibilityResult is one completed request of a feasibility batch.
+type FeasibilityResult struct {
+	// Index is the position of the request in the batch.
+	Index int
+	// ID is the feasibility ID, empty if submission failed.
+	ID string
+	// Feasibility is the last state fetched, nil if submission failed.
+	Feasibility *Feasibility
+	// Opportunities are the opportunities of a completed request.
+	Opportunities []Opportunity
+}
+
+// batchUpdate is the outcome of submitting or polling one batch request.
+type batchUpdate struct {
+	index int
+	f     *Feasibility
+	err   error
+}
 
-		if f.Status == FeasibilityStatusCompleted || f.Status == FeasibilityStatusError {
-			return f, nil
+// CreateFeasibilityBatch submits many feasibility requests and yields each
+// result as soon as it completes, so results arrive in completion order rather
+// than input order; FeasibilityResult.Index identifies the request.
+//
+// Requests are submitted with bounded concurrency, and all in-flight requests
+// are polled on one shared ticker. A poll that fails with a network error, a
+// 429 or a 5xx response is retried on the next tick until opts.Timeout. A
+// request that fails, finishes with status ERROR or exceeds opts.Timeout is
+// yielded with its error and the rest of the batch continues, unless
+// opts.FailFast is set. Cancelling ctx or stopping the
+// iteration abandons outstanding requests.
+func (c *Client) CreateFeasibilityBatch(ctx context.Context, reqs []*CreateFeasibilityRequest, opts BatchOptions) iter.Seq2[FeasibilityResult, error] {
+	concurrency := cmp.Or(max(opts.Concurrency, 0), 4)
+	interval := cmp.Or(opts.PollInterval, 2*time.Second)
+	timeout := cmp.Or(opts.Timeout, 5*time.Minute)
+
+	return func(yield func(FeasibilityResult, error) bool) {
+		if len(reqs) == 0 {
+			return
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// Buffered so workers never block once the iteration has stopped.
+		submitted := make(chan batchUpdate, len(reqs))
+		work := make(chan int)
+		go func() {
+			defer close(work)
+			for i := range reqs {
+				select {
+				case work <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		for range min(concurrency, len(reqs)) {
+			go func() {
+				for i := range work {
+					f, err := c.CreateFeasibility(ctx, reqs[i])
+					submitted <- batchUpdate{index: i, f: f, err: err}
+				}
+			}()
 		}
 
-		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("timeout waiting for feasibility %s", id)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		inFlight := make(map[int]*Feasibility)
+		deadlines := make(map[int]time.Time)
+		pending := len(reqs)
+
+		// finish yields a terminal update and reports whether to continue.
+		finish := func(u batchUpdate) bool {
+			pending--
+			delete(inFlight, u.index)
+			res := FeasibilityResult{Index: u.index, Feasibility: u.f}
+			if u.f != nil {
+				res.ID = u.f.ID
+			}
+			err := u.err
+			if err == nil && u.f.Status == FeasibilityStatusError {
+				err = fmt.Errorf("feasibility %s finished with status %s", u.f.ID, u.f.Status)
+			}
+			if err != nil {
+				return yield(res, fmt.Errorf("feasibility request %d: %w", u.index, err)) && !opts.FailFast
+			}
+			res.Opportunities = u.f.Opportunities
+			return yield(res, nil)
 		}
 
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
+		for pending > 0 {
+			select {
+			case <-ctx.Done():
+				yield(FeasibilityResult{Index: -1}, ctx.Err())
+				return
+
+			case u := <-submitted:
+				if u.err != nil {
+					u.f = nil
+				}
+				if u.err != nil || isFeasibilityDone(u.f) {
+					if !finish(u) {
+						return
+					}
+					continue
+				}
+				inFlight[u.index] = u.f
+				deadlines[u.index] = time.Now().Add(timeout)
+
+			case now := <-ticker.C:
+				for _, u := range c.pollFeasibilities(ctx, inFlight, concurrency) {
+					switch {
+					case u.err != nil && retryablePollError(ctx, u.err) && !now.After(deadlines[u.index]):
+						// Try again on the next tick, within the request's timeout.
+						continue
+					case u.err == nil && !isFeasibilityDone(u.f) && now.After(deadlines[u.index]):
+						u.err = fmt.Errorf("timeout waiting for feasibility %s: %w", u.f.ID, common.ErrWaitTimeout)
+					case u.err == nil && !isFeasibilityDone(u.f):
+						inFlight[u.index] = u.f
+						continue
+					}
+					if !finish(u) {
+						return
+					}
+				}
+			}
 		}
 	}
 }
+
+// pollFeasibilities fetches every in-flight request, at most concurrency at
+// a time, and returns the updates ordered by batch index. A failed fetch keeps
+// the last known state so the caller can report its ID.
+func (c *Client) pollFeasibilities(ctx context.Context, inFlight map[int]*Feasibility, concurrency int) []batchUpdate {
+	indexes := slices.Sorted(maps.Keys(inFlight))
+	updates := make([]batchUpdate, len(indexes))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for n, i := range indexes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			f, err := c.GetFeasibility(ctx, inFlight[i].ID)
+			if err != nil {
+				f = inFlight[i]
+			}
+			updates[n] = batchUpdate{index: i, f: f, err: err}
+		}()
+	}
+	wg.Wait()
+	return updates
+}
+
+// retryablePollError reports whether a poll that failed with err may succeed
+// on the next tick: a network error while ctx is still live, a 429 or a 5xx
+// response.
+func retryablePollError(ctx context.Context, err error) bool {
+	if common.IsRateLimited(err) || common.IsServerError(err) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && ctx.Err() == nil
+}
+
+func isFeasibilityDone(f *Feasibility) bool {
+	return f.Status == FeasibilityStatusCompleted || f.Status == FeasibilityStatusError
+}
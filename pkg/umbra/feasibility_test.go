@@ -1,11 +1,22 @@
 package umbra_test
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/paulmach/orb"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
 	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
 )
 
@@ -145,3 +156,189 @@ func TestGetFeasibility_NotFound(t *testing.T) {
 		t.Errorf("expected not found error, got %v", err)
 	}
 }
+
+// batchServer serves feasibility submissions whose index is encoded as the
+// longitude of the point geometry. Request i completes after polls[i] polls
+// with status final[i] (COMPLETED if unset); requests in failSubmit are
+// rejected with 400.
+func batchServer(t *testing.T, polls map[int]int, final map[int]umbra.FeasibilityStatus, failSubmit map[int]bool) *umbra.Client {
+	var mu sync.Mutex
+	seen := make(map[string]int)
+
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var req umbra.CreateFeasibilityRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+			i := int(req.SpotlightConstraints.Geometry.Coordinates.(orb.Point).Lon())
+			if failSubmit[i] {
+				errorResponse(w, http.StatusBadRequest, "invalid geometry")
+				return
+			}
+			jsonResponse(w, http.StatusCreated, umbra.Feasibility{ID: fmt.Sprintf("f-%d", i), Status: umbra.FeasibilityStatusReceived})
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/tasking/feasibilities/")
+		var i int
+		fmt.Sscanf(id, "f-%d", &i)
+
+		mu.Lock()
+		seen[id]++
+		n := seen[id]
+		mu.Unlock()
+
+		f := umbra.Feasibility{ID: id, Status: umbra.FeasibilityStatusReceived}
+		if n >= polls[i] {
+			f.Status = cmp.Or(final[i], umbra.FeasibilityStatusCompleted)
+			f.Opportunities = []umbra.Opportunity{{SatelliteID: id}}
+		}
+		jsonResponse(w, http.StatusOK, f)
+	})
+	return cli
+}
+
+func batchRequests(n int) []*umbra.CreateFeasibilityRequest {
+	reqs := make([]*umbra.CreateFeasibilityRequest, n)
+	for i := range reqs {
+		reqs[i] = &umbra.CreateFeasibilityRequest{
+			ImagingMode:          umbra.ImagingModeSpotlight,
+			SpotlightConstraints: &umbra.SpotlightConstraints{Geometry: umbra.NewPointGeometry(float64(i), 0)},
+		}
+	}
+	return reqs
+}
+
+func TestCreateFeasibilityBatch_CompletionOrder(t *testing.T) {
+	cli := batchServer(t, map[int]int{0: 6, 1: 1, 2: 3}, nil, nil)
+
+	var order []int
+	for res, err := range cli.CreateFeasibilityBatch(context.Background(), batchRequests(3), umbra.BatchOptions{PollInterval: 10 * time.Millisecond}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.ID != fmt.Sprintf("f-%d", res.Index) || len(res.Opportunities) != 1 {
+			t.Errorf("unexpected result: %+v", res)
+		}
+		order = append(order, res.Index)
+	}
+	if !slices.Equal(order, []int{1, 2, 0}) {
+		t.Errorf("expected completion order [1 2 0], got %v", order)
+	}
+}
+
+func TestCreateFeasibilityBatch_OneFailing(t *testing.T) {
+	cli := batchServer(t, map[int]int{0: 1, 1: 1, 2: 2, 3: 1}, map[int]umbra.FeasibilityStatus{3: umbra.FeasibilityStatusError}, map[int]bool{1: true})
+
+	failed := make(map[int]error)
+	var succeeded []int
+	for res, err := range cli.CreateFeasibilityBatch(context.Background(), batchRequests(4), umbra.BatchOptions{PollInterval: 5 * time.Millisecond, Concurrency: 2}) {
+		if err != nil {
+			failed[res.Index] = err
+			continue
+		}
+		succeeded = append(succeeded, res.Index)
+	}
+
+	slices.Sort(succeeded)
+	if !slices.Equal(succeeded, []int{0, 2}) {
+		t.Errorf("expected requests 0 and 2 to succeed, got %v", succeeded)
+	}
+	if len(failed) != 2 || !umbra.IsBadRequest(failed[1]) || failed[3] == nil {
+		t.Errorf("expected requests 1 and 3 to fail, got %v", failed)
+	}
+}
+
+func TestCreateFeasibilityBatch_FailFast(t *testing.T) {
+	cli := batchServer(t, map[int]int{0: 5, 1: 1, 2: 5}, map[int]umbra.FeasibilityStatus{1: umbra.FeasibilityStatusError}, nil)
+
+	var results []umbra.FeasibilityResult
+	var errs []error
+	opts := umbra.BatchOptions{PollInterval: 5 * time.Millisecond, FailFast: true}
+	for res, err := range cli.CreateFeasibilityBatch(context.Background(), batchRequests(3), opts) {
+		results = append(results, res)
+		errs = append(errs, err)
+	}
+
+	if len(results) != 1 || results[0].Index != 1 || errs[0] == nil {
+		t.Fatalf("expected the batch to stop after request 1 failed, got %+v (%v)", results, errs)
+	}
+	if results[0].Feasibility.Status != umbra.FeasibilityStatusError {
+		t.Errorf("expected ERROR status, got %s", results[0].Feasibility.Status)
+	}
+}
+
+func TestCreateFeasibilityBatch_TransientPollError(t *testing.T) {
+	var polls atomic.Int32
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			jsonResponse(w, http.StatusCreated, umbra.Feasibility{ID: "f-0", Status: umbra.FeasibilityStatusReceived})
+			return
+		}
+		switch polls.Add(1) {
+		case 1:
+			errorResponse(w, http.StatusServiceUnavailable, "unavailable")
+		case 2:
+			errorResponse(w, http.StatusTooManyRequests, "slow down")
+		default:
+			jsonResponse(w, http.StatusOK, umbra.Feasibility{ID: "f-0", Status: umbra.FeasibilityStatusCompleted})
+		}
+	})
+
+	opts := umbra.BatchOptions{PollInterval: 5 * time.Millisecond}
+	var count int
+	for res, err := range cli.CreateFeasibilityBatch(context.Background(), batchRequests(1), opts) {
+		count++
+		if err != nil {
+			t.Errorf("expected transient poll errors to be retried, got %v", err)
+		}
+		if res.ID != "f-0" {
+			t.Errorf("unexpected result: %+v", res)
+		}
+	}
+	if count != 1 || polls.Load() != 3 {
+		t.Errorf("expected one result after 3 polls, got %d results and %d polls", count, polls.Load())
+	}
+}
+
+func TestCreateFeasibilityBatch_PollNotFound(t *testing.T) {
+	var polls atomic.Int32
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			jsonResponse(w, http.StatusCreated, umbra.Feasibility{ID: "f-0", Status: umbra.FeasibilityStatusReceived})
+			return
+		}
+		polls.Add(1)
+		errorResponse(w, http.StatusNotFound, "not found")
+	})
+
+	opts := umbra.BatchOptions{PollInterval: 5 * time.Millisecond}
+	for _, err := range cli.CreateFeasibilityBatch(context.Background(), batchRequests(1), opts) {
+		if !umbra.IsNotFound(err) {
+			t.Errorf("expected not found error, got %v", err)
+		}
+	}
+	if n := polls.Load(); n != 1 {
+		t.Errorf("expected a 404 not to be retried, got %d polls", n)
+	}
+}
+
+func TestCreateFeasibilityBatch_Timeout(t *testing.T) {
+	cli := batchServer(t, map[int]int{0: 1000}, nil, nil)
+
+	opts := umbra.BatchOptions{PollInterval: 5 * time.Millisecond, Timeout: 20 * time.Millisecond}
+	var count int
+	for res, err := range cli.CreateFeasibilityBatch(context.Background(), batchRequests(1), opts) {
+		count++
+		if !errors.Is(err, common.ErrWaitTimeout) {
+			t.Errorf("expected timeout error, got %v", err)
+		}
+		if res.ID != "f-0" {
+			t.Errorf("expected the timed-out ID to be reported, got %+v", res)
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected one result, got %d", count)
+	}
+}
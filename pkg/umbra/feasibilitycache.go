@@ -0,0 +1,287 @@
+package umbra
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+)
+
+// defaultFeasibilityCacheEntries is the cache size used when
+// WithFeasibilityCache is given no positive maxEntries.
+const defaultFeasibilityCacheEntries = 128
+
+// ----------------------------------------------------------------------------
+// Feasibility Cache
+// ----------------------------------------------------------------------------
+
+// WithFeasibilityCache makes CreateFeasibility reuse the feasibility request
+// created for a logically identical request within ttl instead of starting,
+// and paying for, a new computation. Requests are identical when they have
+// the same imaging mode and constraints, the same geometry up to coordinate
+// rounding and ring order, and the same window to the minute. Stale entries
+// are refreshed transparently; beyond maxEntries, the least recently used
+// entry is evicted.
+//
+// Cached requests are kept up to date by GetFeasibility, so a request that
+// was polled to completion is served with its opportunities. Failed requests
+// are not cached. Use WithFeasibilityRefresh to bypass the cache for a call.
+func WithFeasibilityCache(ttl time.Duration, maxEntries int) Option {
+	return func(c *clientConfig) {
+		c.feasibilityTTL = ttl
+		c.feasibilityMaxEntries = maxEntries
+	}
+}
+
+type feasibilityRefreshKey struct{}
+
+// WithFeasibilityRefresh returns a context whose CreateFeasibility calls
+// skip the feasibility cache lookup and replace any cached entry with the
+// fresh result. Bypassed lookups count as misses.
+func WithFeasibilityRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, feasibilityRefreshKey{}, true)
+}
+
+// FeasibilityCacheStats are the counters of the feasibility cache.
+type FeasibilityCacheStats struct {
+	Hits      int
+	Misses    int
+	Evictions int
+	Entries   int
+}
+
+// FeasibilityCacheStats returns the feasibility cache counters, or zero
+// stats if the cache is disabled.
+func (c *Client) FeasibilityCacheStats() FeasibilityCacheStats {
+	if c.feasibilities == nil {
+		return FeasibilityCacheStats{}
+	}
+	fc := c.feasibilities
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	stats := fc.stats
+	stats.Entries = fc.order.Len()
+	return stats
+}
+
+// feasibilityCache is an LRU cache of feasibility requests by canonical
+// request key.
+type feasibilityCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	byID    map[string]*list.Element
+	order   *list.List // front is most recently used
+	stats   FeasibilityCacheStats
+}
+
+type feasibilityEntry struct {
+	key    string
+	feas   *Feasibility
+	stored time.Time
+}
+
+func newFeasibilityCache(ttl time.Duration, maxEntries int) *feasibilityCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultFeasibilityCacheEntries
+	}
+	return &feasibilityCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		byID:       make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// get returns a copy of the fresh entry for key, counting a hit or a miss.
+// With refresh, the lookup is skipped and counted as a miss.
+func (fc *feasibilityCache) get(key string, refresh bool) (*Feasibility, bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if el, ok := fc.entries[key]; ok && !refresh {
+		e := el.Value.(*feasibilityEntry)
+		if time.Since(e.stored) < fc.ttl {
+			fc.order.MoveToFront(el)
+			fc.stats.Hits++
+			return cloneFeasibility(e.feas), true
+		}
+		fc.remove(el)
+	}
+	fc.stats.Misses++
+	return nil, false
+}
+
+// put stores f under key, evicting the least recently used entries beyond
+// maxEntries.
+func (fc *feasibilityCache) put(key string, f *Feasibility) {
+	if f.ID == "" || f.Status == FeasibilityStatusError {
+		return
+	}
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if el, ok := fc.entries[key]; ok {
+		fc.remove(el)
+	}
+	el := fc.order.PushFront(&feasibilityEntry{key: key, feas: cloneFeasibility(f), stored: time.Now()})
+	fc.entries[key] = el
+	fc.byID[f.ID] = el
+	for fc.order.Len() > fc.maxEntries {
+		fc.remove(fc.order.Back())
+		fc.stats.Evictions++
+	}
+}
+
+// update refreshes the cached copy of a feasibility request fetched by ID,
+// keeping its storage time: the window it was computed for does not move.
+func (fc *feasibilityCache) update(f *Feasibility) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	el, ok := fc.byID[f.ID]
+	if !ok {
+		return
+	}
+	if f.Status == FeasibilityStatusError {
+		fc.remove(el)
+		return
+	}
+	el.Value.(*feasibilityEntry).feas = cloneFeasibility(f)
+}
+
+// remove deletes an entry. The caller must hold fc.mu.
+func (fc *feasibilityCache) remove(el *list.Element) {
+	e := fc.order.Remove(el).(*feasibilityEntry)
+	delete(fc.entries, e.key)
+	delete(fc.byID, e.feas.ID)
+}
+
+func cloneFeasibility(f *Feasibility) *Feasibility {
+	out := *f
+	out.Opportunities = slices.Clone(f.Opportunities)
+	return &out
+}
+
+// ----------------------------------------------------------------------------
+// Canonical Request Keys
+// ----------------------------------------------------------------------------
+
+// feasibilityCacheKey returns a hash of the canonical form of req: windows
+// truncated to the minute in UTC, geometries rounded and with rings in a
+// fixed orientation and starting vertex. The canonical form is hashed as
+// JSON with sorted keys, so it does not depend on struct field order.
+func feasibilityCacheKey(req *CreateFeasibilityRequest) (string, error) {
+	if req == nil {
+		return "", errors.New("feasibility request is nil")
+	}
+	canon := *req
+	canon.WindowStartAt = req.WindowStartAt.UTC().Truncate(time.Minute)
+	canon.WindowEndAt = req.WindowEndAt.UTC().Truncate(time.Minute)
+	if sc := req.SpotlightConstraints; sc != nil {
+		c := *sc
+		c.Geometry = canonicalGeometry(sc.Geometry)
+		canon.SpotlightConstraints = &c
+	}
+	if sc := req.ScanConstraints; sc != nil {
+		c := *sc
+		c.StartPoint = canonicalGeometry(sc.StartPoint)
+		c.EndPoint = canonicalGeometry(sc.EndPoint)
+		canon.ScanConstraints = &c
+	}
+
+	data, err := json.Marshal(canon)
+	if err != nil {
+		return "", err
+	}
+	// Re-encoding the generic form sorts object keys.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return "", err
+	}
+	if data, err = json.Marshal(generic); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalGeometry rounds g to about 10 cm and normalizes its polygon
+// rings.
+func canonicalGeometry(g *geojson.Geometry) *geojson.Geometry {
+	if g == nil || g.Geometry() == nil {
+		return g
+	}
+	// orb.Round rounds in place.
+	geom := orb.Round(orb.Clone(g.Geometry()), 1e6)
+	switch geom := geom.(type) {
+	case orb.Polygon:
+		canonicalPolygon(geom)
+	case orb.MultiPolygon:
+		for _, p := range geom {
+			canonicalPolygon(p)
+		}
+	}
+	return geojson.NewGeometry(geom)
+}
+
+// canonicalPolygon winds the exterior ring counterclockwise and the holes
+// clockwise, as RFC 7946 requires, each starting at its smallest vertex.
+func canonicalPolygon(p orb.Polygon) {
+	for i, r := range p {
+		want := orb.CCW
+		if i > 0 {
+			want = orb.CW
+		}
+		if r.Orientation() != want && r.Orientation() != 0 {
+			r.Reverse()
+		}
+		if len(r) < 2 || !r.Closed() {
+			continue
+		}
+		// Rotate the open ring, then close it again.
+		open := r[:len(r)-1]
+		first := 0
+		for j, pt := range open {
+			if pt[0] < open[first][0] || pt[0] == open[first][0] && pt[1] < open[first][1] {
+				first = j
+			}
+		}
+		rotated := append(slices.Clone(open[first:]), open[:first]...)
+		copy(r, rotated)
+		r[len(r)-1] = r[0]
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Cached Calls
+// ----------------------------------------------------------------------------
+
+// createFeasibilityCached serves CreateFeasibility from the cache.
+func (c *Client) createFeasibilityCached(ctx context.Context, req *CreateFeasibilityRequest) (*Feasibility, error) {
+	key, err := feasibilityCacheKey(req)
+	if err != nil {
+		return nil, err
+	}
+	refresh, _ := ctx.Value(feasibilityRefreshKey{}).(bool)
+	if f, ok := c.feasibilities.get(key, refresh); ok {
+		return f, nil
+	}
+	f, err := c.createFeasibility(ctx, req)
+	if err != nil {
+		return f, err
+	}
+	c.feasibilities.put(key, f)
+	return f, nil
+}
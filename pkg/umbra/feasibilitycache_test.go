@@ -0,0 +1,243 @@
+package umbra_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+// newFeasibilityCacheClient returns a client with a feasibility cache whose
+// server creates a new feasibility request per POST, completing it on GET.
+func newFeasibilityCacheClient(t *testing.T, ttl time.Duration, maxEntries int) (*umbra.Client, *atomic.Int32) {
+	t.Helper()
+	var created atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			n := created.Add(1)
+			jsonResponse(w, http.StatusCreated, umbra.Feasibility{ID: fmt.Sprintf("feas-%d", n), Status: umbra.FeasibilityStatusReceived})
+			return
+		}
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		jsonResponse(w, http.StatusOK, umbra.Feasibility{
+			ID:            id,
+			Status:        umbra.FeasibilityStatusCompleted,
+			Opportunities: []umbra.Opportunity{{SatelliteID: "UMBRA_08"}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := umbra.NewClient("test-token", umbra.WithBaseURL(srv.URL), umbra.WithFeasibilityCache(ttl, maxEntries))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return cli, &created
+}
+
+func spotlightFeasibility(geom orb.Geometry, start time.Time) *umbra.CreateFeasibilityRequest {
+	return &umbra.CreateFeasibilityRequest{
+		ImagingMode: umbra.ImagingModeSpotlight,
+		SpotlightConstraints: &umbra.SpotlightConstraints{
+			Geometry:               geojson.NewGeometry(geom),
+			GrazingAngleMinDegrees: 40,
+			GrazingAngleMaxDegrees: 70,
+		},
+		WindowStartAt: start,
+		WindowEndAt:   start.Add(48 * time.Hour),
+	}
+}
+
+func TestFeasibilityCache_IdenticalRequests(t *testing.T) {
+	cli, created := newFeasibilityCacheClient(t, time.Hour, 0)
+	ctx := context.Background()
+	start := time.Date(2025, 7, 1, 9, 30, 12, 0, time.UTC)
+
+	first, err := cli.CreateFeasibility(ctx, spotlightFeasibility(orb.Point{-122.4194, 37.7749}, start))
+	if err != nil {
+		t.Fatalf("CreateFeasibility: %v", err)
+	}
+
+	// Logically identical: seconds and time zone differ, and coordinates
+	// only below the rounding precision.
+	same := []*umbra.CreateFeasibilityRequest{
+		spotlightFeasibility(orb.Point{-122.41940001, 37.7749}, start.Add(40*time.Second)),
+		spotlightFeasibility(orb.Point{-122.4194, 37.7749}, start.In(time.FixedZone("PDT", -7*3600))),
+	}
+	for _, req := range same {
+		got, err := cli.CreateFeasibility(ctx, req)
+		if err != nil {
+			t.Fatalf("CreateFeasibility: %v", err)
+		}
+		if got.ID != first.ID {
+			t.Errorf("expected cached %s, got %s", first.ID, got.ID)
+		}
+	}
+
+	// The same polygon, starting at another vertex with the opposite
+	// winding.
+	square := orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}
+	rotated := orb.Polygon{{{1, 1}, {1, 0}, {0, 0}, {0, 1}, {1, 1}}}
+	a, err := cli.CreateFeasibility(ctx, spotlightFeasibility(square, start))
+	if err != nil {
+		t.Fatalf("CreateFeasibility: %v", err)
+	}
+	b, err := cli.CreateFeasibility(ctx, spotlightFeasibility(rotated, start))
+	if err != nil {
+		t.Fatalf("CreateFeasibility: %v", err)
+	}
+	if a.ID != b.ID {
+		t.Errorf("expected equivalent polygons to share %s, got %s", a.ID, b.ID)
+	}
+
+	if n := created.Load(); n != 2 {
+		t.Errorf("expected 2 feasibility computations, got %d", n)
+	}
+	if stats := cli.FeasibilityCacheStats(); stats.Hits != 3 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestFeasibilityCache_DifferentMinuteMisses(t *testing.T) {
+	cli, created := newFeasibilityCacheClient(t, time.Hour, 0)
+	ctx := context.Background()
+	start := time.Date(2025, 7, 1, 9, 30, 59, 0, time.UTC)
+
+	for _, s := range []time.Time{start, start.Add(2 * time.Second)} {
+		if _, err := cli.CreateFeasibility(ctx, spotlightFeasibility(orb.Point{10, 50}, s)); err != nil {
+			t.Fatalf("CreateFeasibility: %v", err)
+		}
+	}
+	if n := created.Load(); n != 2 {
+		t.Errorf("expected windows in different minutes to miss, got %d computations", n)
+	}
+}
+
+func TestFeasibilityCache_NilRequest(t *testing.T) {
+	cli, created := newFeasibilityCacheClient(t, time.Hour, 0)
+
+	if _, err := cli.CreateFeasibility(context.Background(), nil); err == nil {
+		t.Error("expected an error for a nil request")
+	}
+	if n := created.Load(); n != 0 {
+		t.Errorf("expected no request to be sent, got %d", n)
+	}
+}
+
+func TestFeasibilityCache_ServesCompletedResults(t *testing.T) {
+	cli, created := newFeasibilityCacheClient(t, time.Hour, 0)
+	ctx := context.Background()
+	req := spotlightFeasibility(orb.Point{10, 50}, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC))
+
+	f, err := cli.CreateFeasibility(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateFeasibility: %v", err)
+	}
+	if _, err := cli.GetFeasibility(ctx, f.ID); err != nil {
+		t.Fatalf("GetFeasibility: %v", err)
+	}
+	cached, err := cli.CreateFeasibility(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateFeasibility: %v", err)
+	}
+	if cached.Status != umbra.FeasibilityStatusCompleted || len(cached.Opportunities) != 1 {
+		t.Errorf("expected the completed result, got %+v", cached)
+	}
+	if n := created.Load(); n != 1 {
+		t.Errorf("expected 1 computation, got %d", n)
+	}
+}
+
+func TestFeasibilityCache_Eviction(t *testing.T) {
+	cli, created := newFeasibilityCacheClient(t, time.Hour, 2)
+	ctx := context.Background()
+	start := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	req := func(lon float64) *umbra.CreateFeasibilityRequest {
+		return spotlightFeasibility(orb.Point{lon, 50}, start)
+	}
+
+	for _, lon := range []float64{1, 2, 1, 3} { // 1 is used again before 3 evicts 2
+		if _, err := cli.CreateFeasibility(ctx, req(lon)); err != nil {
+			t.Fatalf("CreateFeasibility: %v", err)
+		}
+	}
+	if stats := cli.FeasibilityCacheStats(); stats.Entries != 2 || stats.Evictions != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	before := created.Load()
+	if _, err := cli.CreateFeasibility(ctx, req(1)); err != nil {
+		t.Fatal(err)
+	}
+	if created.Load() != before {
+		t.Error("expected the recently used entry to survive")
+	}
+	if _, err := cli.CreateFeasibility(ctx, req(2)); err != nil {
+		t.Fatal(err)
+	}
+	if created.Load() != before+1 {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+}
+
+func TestFeasibilityCache_StaleAndBypass(t *testing.T) {
+	cli, created := newFeasibilityCacheClient(t, 250*time.Millisecond, 0)
+	ctx := context.Background()
+	req := spotlightFeasibility(orb.Point{10, 50}, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC))
+
+	first, err := cli.CreateFeasibility(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err := cli.CreateFeasibility(umbra.WithFeasibilityRefresh(ctx), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.ID == first.ID {
+		t.Error("expected the bypass to create a new feasibility request")
+	}
+	cached, err := cli.CreateFeasibility(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.ID != refreshed.ID {
+		t.Errorf("expected the refreshed result to be cached, got %s", cached.ID)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	stale, err := cli.CreateFeasibility(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stale.ID == refreshed.ID {
+		t.Error("expected a stale entry to be refreshed")
+	}
+	if n := created.Load(); n != 3 {
+		t.Errorf("expected 3 computations, got %d", n)
+	}
+}
+
+func TestFeasibilityCache_Disabled(t *testing.T) {
+	var created atomic.Int32
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		created.Add(1)
+		jsonResponse(w, http.StatusCreated, umbra.Feasibility{ID: "feas"})
+	})
+	req := spotlightFeasibility(orb.Point{10, 50}, time.Now())
+	for range 2 {
+		if _, err := cli.CreateFeasibility(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if created.Load() != 2 || cli.FeasibilityCacheStats() != (umbra.FeasibilityCacheStats{}) {
+		t.Errorf("expected no caching without WithFeasibilityCache")
+	}
+}
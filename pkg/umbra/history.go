@@ -0,0 +1,136 @@
+package umbra
+
+import (
+	"slices"
+	"time"
+)
+
+// SLAReport describes a task's delivery time measured from acceptance.
+type SLAReport struct {
+	// AcceptedAt is when the SLA clock started. It is zero when the task was
+	// never accepted, in which case the SLA does not apply.
+	AcceptedAt time.Time
+	// Delivered reports whether the task has been delivered.
+	Delivered bool
+	// DeliveredIn is the time from acceptance to delivery, if delivered.
+	DeliveredIn time.Duration
+	// Elapsed is the time from acceptance to delivery, to the task reaching
+	// another terminal status, or to now for tasks still in flight.
+	Elapsed time.Duration
+	// Breached reports whether Elapsed exceeds the SLA.
+	Breached bool
+}
+
+// History returns the task's status history sorted by time, with duplicate
+// entries and repeated consecutive statuses removed. The API occasionally
+// returns history out of order or with duplicates.
+func (t *Task) History() []StatusChange {
+	h := make([]StatusChange, 0, len(t.StatusHistory))
+	for _, sc := range t.StatusHistory {
+		if !sc.Timestamp.IsZero() {
+			h = append(h, sc)
+		}
+	}
+	slices.SortStableFunc(h, func(a, b StatusChange) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return slices.CompactFunc(h, func(a, b StatusChange) bool {
+		return a.Status == b.Status
+	})
+}
+
+// Durations returns the total time the task spent in each status. Time in
+// the current status is counted up to now unless the status is terminal.
+func (t *Task) Durations() map[TaskStatus]time.Duration {
+	h := t.History()
+	d := make(map[TaskStatus]time.Duration, len(h))
+	for i, sc := range h {
+		var end time.Time
+		switch {
+		case i+1 < len(h):
+			end = h[i+1].Timestamp
+		case sc.Status.IsTerminal():
+			end = sc.Timestamp
+		default:
+			end = time.Now()
+		}
+		d[sc.Status] += end.Sub(sc.Timestamp)
+	}
+	return d
+}
+
+// TimeOf returns the time of the first transition into status.
+func (t *Task) TimeOf(status TaskStatus) (time.Time, bool) {
+	for _, sc := range t.History() {
+		if sc.Status == status {
+			return sc.Timestamp, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Age returns the time since the task was created.
+func (t *Task) Age() time.Duration {
+	created := t.CreatedAt
+	if created.IsZero() {
+		h := t.History()
+		if len(h) == 0 {
+			return 0
+		}
+		created = h[0].Timestamp
+	}
+	return time.Since(created)
+}
+
+// SLAReport measures delivery time against slaFromAccepted. Tasks that have
+// not been delivered are only reported as breached once the elapsed time
+// already exceeds the SLA.
+//
+// When the history lacks an ACCEPTED entry, the first transition into a
+// status that implies acceptance (e.g. ACTIVE or TASKED) starts the clock.
+func (t *Task) SLAReport(slaFromAccepted time.Duration) SLAReport {
+	var r SLAReport
+	h := t.History()
+
+	if i := slices.IndexFunc(h, func(sc StatusChange) bool { return sc.Status == TaskStatusAccepted }); i >= 0 {
+		r.AcceptedAt = h[i].Timestamp
+	} else if i := slices.IndexFunc(h, func(sc StatusChange) bool { return impliesAccepted(sc.Status) }); i >= 0 {
+		r.AcceptedAt = h[i].Timestamp
+	} else {
+		return r
+	}
+
+	end := time.Now()
+	for _, sc := range h {
+		if sc.Timestamp.Before(r.AcceptedAt) {
+			continue
+		}
+		if sc.Status == TaskStatusDelivered || sc.Status == TaskStatusCompleted {
+			r.Delivered = true
+			end = sc.Timestamp
+			break
+		}
+		if sc.Status.IsTerminal() {
+			end = sc.Timestamp
+			break
+		}
+	}
+
+	r.Elapsed = end.Sub(r.AcceptedAt)
+	if r.Delivered {
+		r.DeliveredIn = r.Elapsed
+	}
+	r.Breached = r.Elapsed > slaFromAccepted
+	return r
+}
+
+// impliesAccepted reports whether a task in status s must have been accepted.
+func impliesAccepted(s TaskStatus) bool {
+	switch s {
+	case TaskStatusActive, TaskStatusScheduled, TaskStatusTasked, TaskStatusTransmitted,
+		TaskStatusIncomplete, TaskStatusProcessing, TaskStatusProcessed,
+		TaskStatusDelivering, TaskStatusDelivered, TaskStatusCompleted:
+		return true
+	}
+	return false
+}
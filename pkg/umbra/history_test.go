@@ -0,0 +1,253 @@
+package umbra_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+var historyBase = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func hoursIn(hours float64) time.Time {
+	return historyBase.Add(time.Duration(hours * float64(time.Hour)))
+}
+
+func statusAt(s umbra.TaskStatus, hours float64) umbra.StatusChange {
+	return umbra.StatusChange{Status: s, Timestamp: hoursIn(hours)}
+}
+
+func TestTask_History_SortsAndDeduplicates(t *testing.T) {
+	task := &umbra.Task{StatusHistory: []umbra.StatusChange{
+		statusAt(umbra.TaskStatusTasked, 5),
+		statusAt(umbra.TaskStatusReceived, 0),
+		statusAt(umbra.TaskStatusAccepted, 1),
+		statusAt(umbra.TaskStatusReceived, 0),
+		statusAt(umbra.TaskStatusTasked, 6),
+		{Status: umbra.TaskStatusScheduled},
+	}}
+
+	h := task.History()
+	want := []umbra.StatusChange{
+		statusAt(umbra.TaskStatusReceived, 0),
+		statusAt(umbra.TaskStatusAccepted, 1),
+		statusAt(umbra.TaskStatusTasked, 5),
+	}
+	if len(h) != len(want) {
+		t.Fatalf("History() = %v, want %v", h, want)
+	}
+	for i := range want {
+		if h[i].Status != want[i].Status || !h[i].Timestamp.Equal(want[i].Timestamp) {
+			t.Errorf("History()[%d] = %v, want %v", i, h[i], want[i])
+		}
+	}
+}
+
+func TestTask_Durations(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []umbra.StatusChange
+		want    map[umbra.TaskStatus]time.Duration
+	}{
+		{
+			name: "delivered",
+			history: []umbra.StatusChange{
+				statusAt(umbra.TaskStatusReceived, 0),
+				statusAt(umbra.TaskStatusAccepted, 0.5),
+				statusAt(umbra.TaskStatusTasked, 10),
+				statusAt(umbra.TaskStatusDelivered, 20),
+			},
+			want: map[umbra.TaskStatus]time.Duration{
+				umbra.TaskStatusReceived:  30 * time.Minute,
+				umbra.TaskStatusAccepted:  9*time.Hour + 30*time.Minute,
+				umbra.TaskStatusTasked:    10 * time.Hour,
+				umbra.TaskStatusDelivered: 0,
+			},
+		},
+		{
+			name: "out of order with revisited status",
+			history: []umbra.StatusChange{
+				statusAt(umbra.TaskStatusScheduled, 2),
+				statusAt(umbra.TaskStatusActive, 1),
+				statusAt(umbra.TaskStatusActive, 3),
+				statusAt(umbra.TaskStatusCanceled, 7),
+				statusAt(umbra.TaskStatusScheduled, 2),
+			},
+			want: map[umbra.TaskStatus]time.Duration{
+				umbra.TaskStatusActive:    5 * time.Hour,
+				umbra.TaskStatusScheduled: time.Hour,
+				umbra.TaskStatusCanceled:  0,
+			},
+		},
+		{
+			name:    "empty",
+			history: nil,
+			want:    map[umbra.TaskStatus]time.Duration{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&umbra.Task{StatusHistory: tt.history}).Durations()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Durations() = %v, want %v", got, tt.want)
+			}
+			for s, d := range tt.want {
+				if got[s] != d {
+					t.Errorf("Durations()[%s] = %v, want %v", s, got[s], d)
+				}
+			}
+		})
+	}
+}
+
+func TestTask_Durations_CurrentStatusRunsUntilNow(t *testing.T) {
+	start := time.Now().Add(-2 * time.Hour)
+	task := &umbra.Task{StatusHistory: []umbra.StatusChange{{Status: umbra.TaskStatusScheduled, Timestamp: start}}}
+
+	if d := task.Durations()[umbra.TaskStatusScheduled]; d < 2*time.Hour {
+		t.Errorf("expected at least 2h in SCHEDULED, got %v", d)
+	}
+}
+
+func TestTask_TimeOf(t *testing.T) {
+	task := &umbra.Task{StatusHistory: []umbra.StatusChange{
+		statusAt(umbra.TaskStatusTasked, 8),
+		statusAt(umbra.TaskStatusAccepted, 1),
+		statusAt(umbra.TaskStatusActive, 4),
+		statusAt(umbra.TaskStatusTasked, 9),
+	}}
+
+	if ts, ok := task.TimeOf(umbra.TaskStatusTasked); !ok || !ts.Equal(hoursIn(8)) {
+		t.Errorf("TimeOf(TASKED) = %v, %v; want %v", ts, ok, hoursIn(8))
+	}
+	if ts, ok := task.TimeOf(umbra.TaskStatusAccepted); !ok || !ts.Equal(hoursIn(1)) {
+		t.Errorf("TimeOf(ACCEPTED) = %v, %v; want %v", ts, ok, hoursIn(1))
+	}
+	if _, ok := task.TimeOf(umbra.TaskStatusDelivered); ok {
+		t.Error("TimeOf(DELIVERED) should not be found")
+	}
+}
+
+func TestTask_Age(t *testing.T) {
+	created := time.Now().Add(-3 * time.Hour)
+
+	if age := (&umbra.Task{CreatedAt: created}).Age(); age < 3*time.Hour || age > 4*time.Hour {
+		t.Errorf("Age() = %v, want ~3h", age)
+	}
+	fromHistory := &umbra.Task{StatusHistory: []umbra.StatusChange{{Status: umbra.TaskStatusReceived, Timestamp: created}}}
+	if age := fromHistory.Age(); age < 3*time.Hour || age > 4*time.Hour {
+		t.Errorf("Age() from history = %v, want ~3h", age)
+	}
+	if age := (&umbra.Task{}).Age(); age != 0 {
+		t.Errorf("Age() of empty task = %v, want 0", age)
+	}
+}
+
+func TestTask_SLAReport(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+
+	tests := []struct {
+		name        string
+		history     []umbra.StatusChange
+		sla         time.Duration
+		breached    bool
+		delivered   bool
+		deliveredIn time.Duration
+		accepted    bool
+	}{
+		{
+			name: "delivered within SLA",
+			history: []umbra.StatusChange{
+				statusAt(umbra.TaskStatusReceived, 0),
+				statusAt(umbra.TaskStatusAccepted, 1),
+				statusAt(umbra.TaskStatusTasked, 6),
+				statusAt(umbra.TaskStatusDelivered, 13),
+			},
+			sla:         24 * time.Hour,
+			delivered:   true,
+			deliveredIn: 12 * time.Hour,
+			accepted:    true,
+		},
+		{
+			name: "delivered late",
+			history: []umbra.StatusChange{
+				statusAt(umbra.TaskStatusAccepted, 0),
+				statusAt(umbra.TaskStatusDelivered, 30),
+			},
+			sla:         24 * time.Hour,
+			breached:    true,
+			delivered:   true,
+			deliveredIn: 30 * time.Hour,
+			accepted:    true,
+		},
+		{
+			name: "missing ACCEPTED uses first implied status",
+			history: []umbra.StatusChange{
+				statusAt(umbra.TaskStatusReceived, 0),
+				statusAt(umbra.TaskStatusDelivered, 20),
+				statusAt(umbra.TaskStatusActive, 2),
+			},
+			sla:         12 * time.Hour,
+			breached:    true,
+			delivered:   true,
+			deliveredIn: 18 * time.Hour,
+			accepted:    true,
+		},
+		{
+			name: "in flight within SLA",
+			history: []umbra.StatusChange{
+				{Status: umbra.TaskStatusAccepted, Timestamp: ago(2 * time.Hour)},
+				{Status: umbra.TaskStatusScheduled, Timestamp: ago(time.Hour)},
+			},
+			sla:      24 * time.Hour,
+			accepted: true,
+		},
+		{
+			name: "in flight past SLA",
+			history: []umbra.StatusChange{
+				{Status: umbra.TaskStatusAccepted, Timestamp: ago(48 * time.Hour)},
+				{Status: umbra.TaskStatusTasked, Timestamp: ago(40 * time.Hour)},
+			},
+			sla:      24 * time.Hour,
+			breached: true,
+			accepted: true,
+		},
+		{
+			name: "cancelled mid-flight before SLA",
+			history: []umbra.StatusChange{
+				{Status: umbra.TaskStatusAccepted, Timestamp: ago(72 * time.Hour)},
+				{Status: umbra.TaskStatusCanceled, Timestamp: ago(70 * time.Hour)},
+			},
+			sla:      24 * time.Hour,
+			accepted: true,
+		},
+		{
+			name: "never accepted",
+			history: []umbra.StatusChange{
+				{Status: umbra.TaskStatusReceived, Timestamp: ago(72 * time.Hour)},
+				{Status: umbra.TaskStatusRejected, Timestamp: ago(71 * time.Hour)},
+			},
+			sla: time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := (&umbra.Task{StatusHistory: tt.history}).SLAReport(tt.sla)
+			if r.Breached != tt.breached {
+				t.Errorf("Breached = %v, want %v (elapsed %v)", r.Breached, tt.breached, r.Elapsed)
+			}
+			if r.Delivered != tt.delivered {
+				t.Errorf("Delivered = %v, want %v", r.Delivered, tt.delivered)
+			}
+			if r.DeliveredIn != tt.deliveredIn {
+				t.Errorf("DeliveredIn = %v, want %v", r.DeliveredIn, tt.deliveredIn)
+			}
+			if r.AcceptedAt.IsZero() == tt.accepted {
+				t.Errorf("AcceptedAt = %v, want accepted=%v", r.AcceptedAt, tt.accepted)
+			}
+		})
+	}
+}
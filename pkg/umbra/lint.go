@@ -0,0 +1,326 @@
+package umbra
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/paulmach/orb/geojson"
+)
+
+// ----------------------------------------------------------------------------
+// Offline Task Linting
+// ----------------------------------------------------------------------------
+
+// LintSeverity is how serious a lint finding is. Requests with error
+// findings are rejected by the API; warnings flag likely mistakes.
+type LintSeverity string
+
+const (
+	SeverityError   LintSeverity = "error"
+	SeverityWarning LintSeverity = "warning"
+)
+
+// LintFinding is a problem LintTaskRequest found in a task request. Field
+// is the JSON path of the offending field, e.g.
+// "spotlightConstraints.grazingAngleMinDegrees".
+type LintFinding struct {
+	Rule     string
+	Severity LintSeverity
+	Field    string
+	Message  string
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Severity, f.Field, f.Message, f.Rule)
+}
+
+// LintRule is a static check of a task request. Check returns the problems
+// it finds in req at now, with Field and Message set; LintTaskRequest fills
+// in Rule and Severity from the rule.
+type LintRule struct {
+	Name     string
+	Severity LintSeverity
+	Check    func(req *CreateTaskRequest, now time.Time) []LintFinding
+}
+
+// ValidRangeResolutions are the range resolutions, in meters, the API
+// documents as valid. Organizations may be allowed only some of them.
+var ValidRangeResolutions = []float64{0.25, 0.35, 0.5, 1.0, 2.0}
+
+// ValidMultilookFactors are the multilook factors the API documents as valid.
+var ValidMultilookFactors = []int{1, 2, 4, 8}
+
+// FinestRangeResolution is the finest range resolution, in meters, each
+// imaging mode supports. Modes not listed are not checked.
+var FinestRangeResolution = map[ImagingMode]float64{
+	ImagingModeSpotlight: 0.25,
+}
+
+// UnlookedProductTypes are the product types delivered before image
+// formation, which cannot be multilooked.
+var UnlookedProductTypes = []ProductType{ProductTypeCPHD}
+
+// LintRules are the rules LintTaskRequest applies, in order. Append rules to
+// extend the linter.
+var LintRules = []LintRule{
+	{Name: "imaging-mode", Severity: SeverityError, Check: lintImagingMode},
+	{Name: "window-required", Severity: SeverityError, Check: lintWindowRequired},
+	{Name: "window-order", Severity: SeverityError, Check: lintWindowOrder},
+	{Name: "window-past", Severity: SeverityError, Check: lintWindowPast},
+	{Name: "window-start-past", Severity: SeverityWarning, Check: lintWindowStartPast},
+	{Name: "geometry-required", Severity: SeverityError, Check: lintGeometryRequired},
+	{Name: "geometry-bounds", Severity: SeverityError, Check: lintGeometryBounds},
+	{Name: "grazing-order", Severity: SeverityError, Check: lintGrazingOrder},
+	{Name: "grazing-range", Severity: SeverityError, Check: lintGrazingRange},
+	{Name: "resolution-mode", Severity: SeverityError, Check: lintResolutionMode},
+	{Name: "resolution-value", Severity: SeverityWarning, Check: lintResolutionValue},
+	{Name: "multilook-value", Severity: SeverityWarning, Check: lintMultilookValue},
+	{Name: "product-type", Severity: SeverityWarning, Check: lintProductType},
+	{Name: "product-multilook", Severity: SeverityError, Check: lintProductMultilook},
+}
+
+// LintTaskRequest checks req against LintRules without contacting the API,
+// judging the acquisition window against now. It returns the findings of
+// every rule, in rule order; none means the request looks valid. Checks
+// that need the API, such as the product constraints of
+// ValidateTaskAgainstConstraints, are not run.
+func LintTaskRequest(req *CreateTaskRequest, now time.Time) []LintFinding {
+	if req == nil {
+		return []LintFinding{{Rule: "request", Severity: SeverityError, Message: "task request is nil"}}
+	}
+	var findings []LintFinding
+	for _, rule := range LintRules {
+		for _, f := range rule.Check(req, now) {
+			f.Rule, f.Severity = rule.Name, rule.Severity
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// HasLintErrors reports whether any finding has error severity.
+func HasLintErrors(findings []LintFinding) bool {
+	return slices.ContainsFunc(findings, func(f LintFinding) bool { return f.Severity == SeverityError })
+}
+
+// FormatLintFindings formats findings one per line, followed by a count of
+// errors and warnings, for display on a terminal.
+func FormatLintFindings(findings []LintFinding) string {
+	var b strings.Builder
+	errs := 0
+	for _, f := range findings {
+		b.WriteString(f.String())
+		b.WriteByte('\n')
+		if f.Severity == SeverityError {
+			errs++
+		}
+	}
+	fmt.Fprintf(&b, "%d error(s), %d warning(s)\n", errs, len(findings)-errs)
+	return b.String()
+}
+
+// LintError is returned by CreateTask, with WithTaskLint, when the request
+// has error findings. Findings holds all findings, warnings included.
+type LintError struct {
+	Findings []LintFinding
+}
+
+func (e *LintError) Error() string {
+	var msgs []string
+	for _, f := range e.Findings {
+		if f.Severity == SeverityError {
+			msgs = append(msgs, f.Field+": "+f.Message)
+		}
+	}
+	return "task request failed lint: " + strings.Join(msgs, "; ")
+}
+
+// lintConstraints returns the JSON name and the shared parameters of the
+// request's constraints, and false if it has none.
+func lintConstraints(req *CreateTaskRequest) (name string, resolution, grazingMin, grazingMax float64, ok bool) {
+	switch {
+	case req.SpotlightConstraints != nil:
+		sc := req.SpotlightConstraints
+		return "spotlightConstraints", sc.RangeResolutionMinMeters, sc.GrazingAngleMinDegrees, sc.GrazingAngleMaxDegrees, true
+	case req.ScanConstraints != nil:
+		sc := req.ScanConstraints
+		return "scanConstraints", sc.RangeResolutionMinMeters, sc.GrazingAngleMinDegrees, sc.GrazingAngleMaxDegrees, true
+	}
+	return "", 0, 0, 0, false
+}
+
+// finding returns a single finding for field.
+func finding(field, format string, args ...any) []LintFinding {
+	return []LintFinding{{Field: field, Message: fmt.Sprintf(format, args...)}}
+}
+
+func lintImagingMode(req *CreateTaskRequest, _ time.Time) []LintFinding {
+	switch req.ImagingMode {
+	case ImagingModeSpotlight:
+		if req.SpotlightConstraints == nil || req.ScanConstraints != nil {
+			return finding("spotlightConstraints", "SPOTLIGHT tasks take spotlightConstraints only")
+		}
+	case ImagingModeScan:
+		if req.ScanConstraints == nil || req.SpotlightConstraints != nil {
+			return finding("scanConstraints", "SCAN tasks take scanConstraints only")
+		}
+	default:
+		return finding("imagingMode", "unknown imaging mode %q", req.ImagingMode)
+	}
+	return nil
+}
+
+func lintWindowRequired(req *CreateTaskRequest, _ time.Time) []LintFinding {
+	var out []LintFinding
+	if req.WindowStartAt.IsZero() {
+		out = append(out, finding("windowStartAt", "is required")...)
+	}
+	if req.WindowEndAt.IsZero() {
+		out = append(out, finding("windowEndAt", "is required")...)
+	}
+	return out
+}
+
+func lintWindowOrder(req *CreateTaskRequest, _ time.Time) []LintFinding {
+	if req.WindowStartAt.IsZero() || req.WindowEndAt.IsZero() || req.WindowEndAt.After(req.WindowStartAt) {
+		return nil
+	}
+	return finding("windowEndAt", "%s is not after windowStartAt %s",
+		req.WindowEndAt.Format(time.RFC3339), req.WindowStartAt.Format(time.RFC3339))
+}
+
+func lintWindowPast(req *CreateTaskRequest, now time.Time) []LintFinding {
+	if req.WindowEndAt.IsZero() || req.WindowEndAt.After(now) {
+		return nil
+	}
+	return finding("windowEndAt", "%s is in the past", req.WindowEndAt.Format(time.RFC3339))
+}
+
+func lintWindowStartPast(req *CreateTaskRequest, now time.Time) []LintFinding {
+	if req.WindowStartAt.IsZero() || !req.WindowStartAt.Before(now) || !req.WindowEndAt.After(now) {
+		return nil
+	}
+	return finding("windowStartAt", "%s is in the past; only the rest of the window can be used",
+		req.WindowStartAt.Format(time.RFC3339))
+}
+
+// lintGeometry is a geometry of the request and its JSON path.
+type lintGeometry struct {
+	field string
+	g     *geojson.Geometry
+}
+
+// lintGeometries returns the geometries of the request's constraints.
+func lintGeometries(req *CreateTaskRequest) []lintGeometry {
+	var out []lintGeometry
+	if sc := req.SpotlightConstraints; sc != nil {
+		out = append(out, lintGeometry{"spotlightConstraints.geometry", sc.Geometry})
+	}
+	if sc := req.ScanConstraints; sc != nil {
+		out = append(out,
+			lintGeometry{"scanConstraints.startPoint", sc.StartPoint},
+			lintGeometry{"scanConstraints.endPoint", sc.EndPoint})
+	}
+	return out
+}
+
+func lintGeometryRequired(req *CreateTaskRequest, _ time.Time) []LintFinding {
+	var out []LintFinding
+	for _, lg := range lintGeometries(req) {
+		if lg.g == nil || lg.g.Geometry() == nil {
+			out = append(out, finding(lg.field, "is required")...)
+		}
+	}
+	return out
+}
+
+func lintGeometryBounds(req *CreateTaskRequest, _ time.Time) []LintFinding {
+	var out []LintFinding
+	for _, lg := range lintGeometries(req) {
+		if lg.g == nil || lg.g.Geometry() == nil {
+			continue
+		}
+		b := lg.g.Geometry().Bound()
+		if b.Min.Lon() < -180 || b.Max.Lon() > 180 || b.Min.Lat() < -90 || b.Max.Lat() > 90 {
+			out = append(out, finding(lg.field, "coordinates span lon [%g, %g], lat [%g, %g], outside [-180, 180], [-90, 90]",
+				b.Min.Lon(), b.Max.Lon(), b.Min.Lat(), b.Max.Lat())...)
+		}
+	}
+	return out
+}
+
+func lintGrazingOrder(req *CreateTaskRequest, _ time.Time) []LintFinding {
+	name, _, gmin, gmax, ok := lintConstraints(req)
+	if !ok || gmin == 0 || gmax == 0 || gmin <= gmax {
+		return nil
+	}
+	return finding(name+".grazingAngleMinDegrees", "%g exceeds grazingAngleMaxDegrees %g", gmin, gmax)
+}
+
+func lintGrazingRange(req *CreateTaskRequest, _ time.Time) []LintFinding {
+	name, _, gmin, gmax, ok := lintConstraints(req)
+	if !ok {
+		return nil
+	}
+	var out []LintFinding
+	if gmin < 0 || gmin > 90 {
+		out = append(out, finding(name+".grazingAngleMinDegrees", "%g is outside [0, 90]", gmin)...)
+	}
+	if gmax < 0 || gmax > 90 {
+		out = append(out, finding(name+".grazingAngleMaxDegrees", "%g is outside [0, 90]", gmax)...)
+	}
+	return out
+}
+
+func lintResolutionMode(req *CreateTaskRequest, _ time.Time) []LintFinding {
+	name, res, _, _, ok := lintConstraints(req)
+	finest, known := FinestRangeResolution[req.ImagingMode]
+	if !ok || !known || res == 0 || res >= finest {
+		return nil
+	}
+	return finding(name+".rangeResolutionMinMeters", "%g m is finer than %s supports (%g m)", res, req.ImagingMode, finest)
+}
+
+func lintResolutionValue(req *CreateTaskRequest, _ time.Time) []LintFinding {
+	name, res, _, _, ok := lintConstraints(req)
+	if !ok || res == 0 || slices.Contains(ValidRangeResolutions, res) {
+		return nil
+	}
+	return finding(name+".rangeResolutionMinMeters", "%g m is not one of the documented values %v", res, ValidRangeResolutions)
+}
+
+func lintMultilookValue(req *CreateTaskRequest, _ time.Time) []LintFinding {
+	sc := req.SpotlightConstraints
+	if sc == nil || sc.MultilookFactor == 0 || slices.Contains(ValidMultilookFactors, sc.MultilookFactor) {
+		return nil
+	}
+	return finding("spotlightConstraints.multilookFactor", "%d is not one of the documented values %v",
+		sc.MultilookFactor, ValidMultilookFactors)
+}
+
+func lintProductType(req *CreateTaskRequest, _ time.Time) []LintFinding {
+	var out []LintFinding
+	for i, pt := range req.ProductTypes {
+		if !pt.IsKnown() {
+			out = append(out, finding(fmt.Sprintf("productTypes[%d]", i), "unknown product type %q", pt)...)
+		}
+	}
+	return out
+}
+
+func lintProductMultilook(req *CreateTaskRequest, _ time.Time) []LintFinding {
+	sc := req.SpotlightConstraints
+	if sc == nil || sc.MultilookFactor <= 1 {
+		return nil
+	}
+	var out []LintFinding
+	for i, pt := range req.ProductTypes {
+		if slices.Contains(UnlookedProductTypes, pt) {
+			out = append(out, finding(fmt.Sprintf("productTypes[%d]", i), "%s cannot be delivered with multilookFactor %d",
+				pt, sc.MultilookFactor)...)
+		}
+	}
+	return out
+}
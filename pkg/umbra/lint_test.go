@@ -0,0 +1,273 @@
+package umbra_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+var lintNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// lintTask returns a spotlight task request with no findings at lintNow.
+func lintTask() *umbra.CreateTaskRequest {
+	return &umbra.CreateTaskRequest{
+		ImagingMode: umbra.ImagingModeSpotlight,
+		SpotlightConstraints: &umbra.SpotlightConstraints{
+			Geometry:                 umbra.NewPointGeometry(-122.4, 37.8),
+			RangeResolutionMinMeters: 0.5,
+			MultilookFactor:          4,
+			GrazingAngleMinDegrees:   30,
+			GrazingAngleMaxDegrees:   60,
+		},
+		WindowStartAt: lintNow.Add(24 * time.Hour),
+		WindowEndAt:   lintNow.Add(72 * time.Hour),
+		ProductTypes:  []umbra.ProductType{umbra.ProductTypeGEC, umbra.ProductTypeSICD},
+	}
+}
+
+func TestLintTaskRequest(t *testing.T) {
+	umbra.FinestRangeResolution[umbra.ImagingModeScan] = 0.5
+	t.Cleanup(func() { delete(umbra.FinestRangeResolution, umbra.ImagingModeScan) })
+
+	tests := []struct {
+		name     string
+		mutate   func(r *umbra.CreateTaskRequest)
+		rule     string
+		severity umbra.LintSeverity
+		field    string
+	}{
+		{
+			name:   "unknown imaging mode",
+			mutate: func(r *umbra.CreateTaskRequest) { r.ImagingMode = "STRIPMAP" },
+			rule:   "imaging-mode", severity: umbra.SeverityError, field: "imagingMode",
+		},
+		{
+			name: "scan without scan constraints",
+			mutate: func(r *umbra.CreateTaskRequest) {
+				r.ImagingMode = umbra.ImagingModeScan
+				r.ProductTypes = nil
+			},
+			rule: "imaging-mode", severity: umbra.SeverityError, field: "scanConstraints",
+		},
+		{
+			name:   "missing window start",
+			mutate: func(r *umbra.CreateTaskRequest) { r.WindowStartAt = time.Time{} },
+			rule:   "window-required", severity: umbra.SeverityError, field: "windowStartAt",
+		},
+		{
+			name:   "window ends before it starts",
+			mutate: func(r *umbra.CreateTaskRequest) { r.WindowEndAt = r.WindowStartAt.Add(-time.Hour) },
+			rule:   "window-order", severity: umbra.SeverityError, field: "windowEndAt",
+		},
+		{
+			name: "window in the past",
+			mutate: func(r *umbra.CreateTaskRequest) {
+				r.WindowStartAt = lintNow.Add(-48 * time.Hour)
+				r.WindowEndAt = lintNow.Add(-24 * time.Hour)
+			},
+			rule: "window-past", severity: umbra.SeverityError, field: "windowEndAt",
+		},
+		{
+			name:   "window already started",
+			mutate: func(r *umbra.CreateTaskRequest) { r.WindowStartAt = lintNow.Add(-time.Hour) },
+			rule:   "window-start-past", severity: umbra.SeverityWarning, field: "windowStartAt",
+		},
+		{
+			name:   "missing geometry",
+			mutate: func(r *umbra.CreateTaskRequest) { r.SpotlightConstraints.Geometry = nil },
+			rule:   "geometry-required", severity: umbra.SeverityError, field: "spotlightConstraints.geometry",
+		},
+		{
+			name: "coordinates out of bounds",
+			mutate: func(r *umbra.CreateTaskRequest) {
+				r.SpotlightConstraints.Geometry = geojson.NewGeometry(orb.Point{37.8, -122.4})
+			},
+			rule: "geometry-bounds", severity: umbra.SeverityError, field: "spotlightConstraints.geometry",
+		},
+		{
+			name: "scan end point out of bounds",
+			mutate: func(r *umbra.CreateTaskRequest) {
+				r.ImagingMode = umbra.ImagingModeScan
+				r.SpotlightConstraints = nil
+				r.ScanConstraints = &umbra.ScanConstraints{
+					StartPoint: umbra.NewPointGeometry(-122.4, 37.8),
+					EndPoint:   umbra.NewPointGeometry(-190, 37.8),
+				}
+				r.ProductTypes = nil
+			},
+			rule: "geometry-bounds", severity: umbra.SeverityError, field: "scanConstraints.endPoint",
+		},
+		{
+			name: "grazing angles inverted",
+			mutate: func(r *umbra.CreateTaskRequest) {
+				r.SpotlightConstraints.GrazingAngleMinDegrees = 70
+			},
+			rule: "grazing-order", severity: umbra.SeverityError, field: "spotlightConstraints.grazingAngleMinDegrees",
+		},
+		{
+			name:   "grazing angle above 90",
+			mutate: func(r *umbra.CreateTaskRequest) { r.SpotlightConstraints.GrazingAngleMaxDegrees = 95 },
+			rule:   "grazing-range", severity: umbra.SeverityError, field: "spotlightConstraints.grazingAngleMaxDegrees",
+		},
+		{
+			name: "resolution finer than the mode supports",
+			mutate: func(r *umbra.CreateTaskRequest) {
+				r.ImagingMode = umbra.ImagingModeScan
+				r.SpotlightConstraints = nil
+				r.ScanConstraints = &umbra.ScanConstraints{
+					StartPoint:               umbra.NewPointGeometry(-122.4, 37.8),
+					EndPoint:                 umbra.NewPointGeometry(-122.3, 37.9),
+					RangeResolutionMinMeters: 0.25,
+				}
+				r.ProductTypes = nil
+			},
+			rule: "resolution-mode", severity: umbra.SeverityError, field: "scanConstraints.rangeResolutionMinMeters",
+		},
+		{
+			name:   "undocumented resolution",
+			mutate: func(r *umbra.CreateTaskRequest) { r.SpotlightConstraints.RangeResolutionMinMeters = 0.75 },
+			rule:   "resolution-value", severity: umbra.SeverityWarning, field: "spotlightConstraints.rangeResolutionMinMeters",
+		},
+		{
+			name:   "undocumented multilook factor",
+			mutate: func(r *umbra.CreateTaskRequest) { r.SpotlightConstraints.MultilookFactor = 3 },
+			rule:   "multilook-value", severity: umbra.SeverityWarning, field: "spotlightConstraints.multilookFactor",
+		},
+		{
+			name: "unknown product type",
+			mutate: func(r *umbra.CreateTaskRequest) {
+				r.ProductTypes = append(r.ProductTypes, "HDR")
+			},
+			rule: "product-type", severity: umbra.SeverityWarning, field: "productTypes[2]",
+		},
+		{
+			name: "CPHD with multilook",
+			mutate: func(r *umbra.CreateTaskRequest) {
+				r.ProductTypes = []umbra.ProductType{umbra.ProductTypeGEC, umbra.ProductTypeCPHD}
+			},
+			rule: "product-multilook", severity: umbra.SeverityError, field: "productTypes[1]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := lintTask()
+			tt.mutate(req)
+			findings := umbra.LintTaskRequest(req, lintNow)
+			if len(findings) != 1 {
+				t.Fatalf("expected 1 finding, got %d:\n%s", len(findings), umbra.FormatLintFindings(findings))
+			}
+			f := findings[0]
+			if f.Rule != tt.rule || f.Severity != tt.severity || f.Field != tt.field {
+				t.Errorf("got %s/%s/%s, want %s/%s/%s", f.Rule, f.Severity, f.Field, tt.rule, tt.severity, tt.field)
+			}
+			if f.Message == "" {
+				t.Error("expected a message")
+			}
+		})
+	}
+}
+
+func TestLintTaskRequest_Clean(t *testing.T) {
+	if findings := umbra.LintTaskRequest(lintTask(), lintNow); len(findings) != 0 {
+		t.Errorf("expected no findings, got:\n%s", umbra.FormatLintFindings(findings))
+	}
+	if findings := umbra.LintTaskRequest(nil, lintNow); !umbra.HasLintErrors(findings) {
+		t.Error("expected an error finding for a nil request")
+	}
+}
+
+func TestLintTaskRequest_Severities(t *testing.T) {
+	req := lintTask()
+	req.SpotlightConstraints.MultilookFactor = 3
+	findings := umbra.LintTaskRequest(req, lintNow)
+	if umbra.HasLintErrors(findings) {
+		t.Errorf("warnings alone must not count as errors:\n%s", umbra.FormatLintFindings(findings))
+	}
+
+	req.WindowEndAt = time.Time{}
+	findings = umbra.LintTaskRequest(req, lintNow)
+	if !umbra.HasLintErrors(findings) {
+		t.Fatal("expected errors")
+	}
+	out := umbra.FormatLintFindings(findings)
+	if !strings.HasSuffix(out, "1 error(s), 1 warning(s)\n") {
+		t.Errorf("unexpected summary:\n%s", out)
+	}
+	if !strings.Contains(out, "error: windowEndAt: is required (window-required)\n") {
+		t.Errorf("missing error line:\n%s", out)
+	}
+}
+
+func TestLintRules_Extend(t *testing.T) {
+	orig := umbra.LintRules
+	t.Cleanup(func() { umbra.LintRules = orig })
+	umbra.LintRules = append(orig[:len(orig):len(orig)], umbra.LintRule{
+		Name:     "task-name",
+		Severity: umbra.SeverityWarning,
+		Check: func(req *umbra.CreateTaskRequest, _ time.Time) []umbra.LintFinding {
+			if req.TaskName == "" {
+				return []umbra.LintFinding{{Field: "taskName", Message: "is empty"}}
+			}
+			return nil
+		},
+	})
+
+	findings := umbra.LintTaskRequest(lintTask(), lintNow)
+	if len(findings) != 1 || findings[0].Rule != "task-name" || findings[0].Severity != umbra.SeverityWarning {
+		t.Errorf("unexpected findings:\n%s", umbra.FormatLintFindings(findings))
+	}
+}
+
+func TestWithTaskLint(t *testing.T) {
+	var creates atomic.Int32
+	_, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		creates.Add(1)
+		jsonResponse(w, http.StatusCreated, umbra.Task{ID: "task-1"})
+	})
+	cli, err := umbra.NewClient("test-token", umbra.WithBaseURL(srv.URL), umbra.WithTaskLint())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// CreateTask lints against the current time.
+	task := func() *umbra.CreateTaskRequest {
+		req := lintTask()
+		req.WindowStartAt = time.Now().Add(24 * time.Hour)
+		req.WindowEndAt = time.Now().Add(72 * time.Hour)
+		return req
+	}
+
+	bad := task()
+	bad.SpotlightConstraints.GrazingAngleMaxDegrees = 95
+	_, err = cli.CreateTask(context.Background(), bad)
+	var lintErr *umbra.LintError
+	if !errors.As(err, &lintErr) {
+		t.Fatalf("expected *LintError, got %v", err)
+	}
+	if len(lintErr.Findings) != 1 || lintErr.Findings[0].Rule != "grazing-range" {
+		t.Errorf("unexpected findings:\n%s", umbra.FormatLintFindings(lintErr.Findings))
+	}
+	if n := creates.Load(); n != 0 {
+		t.Fatalf("expected no request to the API, got %d", n)
+	}
+
+	// Warnings alone do not stop the task.
+	warned := task()
+	warned.SpotlightConstraints.MultilookFactor = 3
+	if _, err := cli.CreateTask(context.Background(), warned); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := creates.Load(); n != 1 {
+		t.Errorf("expected 1 task created, got %d", n)
+	}
+}
@@ -0,0 +1,197 @@
+package umbra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// ----------------------------------------------------------------------------
+// Order Groups
+// ----------------------------------------------------------------------------
+
+// OrderGroupStatus is the aggregate status of the tasks sharing a user order
+// ID.
+type OrderGroupStatus string
+
+const (
+	// OrderGroupInProgress means no task has failed and not all are delivered.
+	OrderGroupInProgress OrderGroupStatus = "IN_PROGRESS"
+	// OrderGroupDelivered means every task has been delivered.
+	OrderGroupDelivered OrderGroupStatus = "DELIVERED"
+	// OrderGroupFailed means at least one task ended without delivery. Other
+	// tasks may still be in progress or delivered.
+	OrderGroupFailed OrderGroupStatus = "FAILED"
+)
+
+// OrderGroup is an aggregate view of the tasks submitted under one user order
+// ID.
+type OrderGroup struct {
+	UserOrderID string
+	Tasks       []Task
+	Status      OrderGroupStatus
+
+	// Counts is the number of tasks in each task status.
+	Counts map[TaskStatus]int
+	// Delivered and Failed list the IDs of delivered tasks and of tasks that
+	// ended without delivery; tasks in neither are still in progress.
+	Delivered []string
+	Failed    []string
+
+	EarliestWindowStart time.Time // Earliest windowStartAt of the tasks
+	LatestDelivery      time.Time // Latest delivery time of delivered tasks
+	CollectCount        int       // Collects across all tasks
+}
+
+// Done reports whether every task in the group has reached a terminal
+// status. An empty group is never done.
+func (g *OrderGroup) Done() bool {
+	return len(g.Tasks) > 0 && len(g.Delivered)+len(g.Failed) == len(g.Tasks)
+}
+
+// taskDelivered reports whether a terminal status means the task delivered.
+func taskDelivered(s TaskStatus) bool {
+	return s == TaskStatusDelivered || s == TaskStatusCompleted
+}
+
+// newOrderGroup aggregates the tasks of a user order.
+func newOrderGroup(userOrderID string, tasks []Task) *OrderGroup {
+	slices.SortFunc(tasks, func(a, b Task) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	g := &OrderGroup{
+		UserOrderID: userOrderID,
+		Tasks:       tasks,
+		Counts:      make(map[TaskStatus]int),
+	}
+	for i := range tasks {
+		t := &tasks[i]
+		g.Counts[t.Status]++
+		g.CollectCount += len(t.CollectIDs)
+		if !t.WindowStartAt.IsZero() && (g.EarliestWindowStart.IsZero() || t.WindowStartAt.Before(g.EarliestWindowStart)) {
+			g.EarliestWindowStart = t.WindowStartAt
+		}
+
+		switch {
+		case taskDelivered(t.Status):
+			g.Delivered = append(g.Delivered, t.ID)
+			delivered := t.UpdatedAt
+			h := t.History()
+			if j := slices.IndexFunc(h, func(sc StatusChange) bool { return taskDelivered(sc.Status) }); j >= 0 {
+				delivered = h[j].Timestamp
+			}
+			if delivered.After(g.LatestDelivery) {
+				g.LatestDelivery = delivered
+			}
+		case t.Status.IsTerminal():
+			g.Failed = append(g.Failed, t.ID)
+		}
+	}
+
+	switch {
+	case len(g.Failed) > 0:
+		g.Status = OrderGroupFailed
+	case len(tasks) > 0 && len(g.Delivered) == len(tasks):
+		g.Status = OrderGroupDelivered
+	default:
+		g.Status = OrderGroupInProgress
+	}
+	return g
+}
+
+// searchOrderTasks returns all tasks with the given user order ID.
+func (c *Client) searchOrderTasks(ctx context.Context, userOrderID string) ([]Task, error) {
+	req := TaskSearchRequest{
+		Query: map[string]interface{}{"userOrderId": map[string]string{"eq": userOrderID}},
+	}
+	var tasks []Task
+	for t, err := range c.SearchTasks(ctx, req) {
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+// GetOrderGroup finds the tasks submitted with userOrderID and aggregates
+// their status: DELIVERED once all are delivered, FAILED if any ended without
+// delivery, and IN_PROGRESS otherwise. A failed group still reports which
+// tasks were delivered.
+func (c *Client) GetOrderGroup(ctx context.Context, userOrderID string) (*OrderGroup, error) {
+	if userOrderID == "" {
+		return nil, errors.New("user order ID is required")
+	}
+	tasks, err := c.searchOrderTasks(ctx, userOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("search tasks for user order %s: %w", userOrderID, err)
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no tasks found for user order %s", userOrderID)
+	}
+	return newOrderGroup(userOrderID, tasks), nil
+}
+
+// OrderGroupWaitOptions configures WaitForOrderGroup.
+type OrderGroupWaitOptions struct {
+	WaitOptions
+
+	// FixedMembership polls only the tasks found by the first search instead
+	// of searching again on every poll. By default each poll searches again,
+	// so tasks added to the order while waiting are picked up.
+	FixedMembership bool
+}
+
+// WaitForOrderGroup polls until every task of the user order has reached a
+// terminal status and returns the final aggregate. A group whose first task
+// fails keeps being polled until the remaining tasks finish, so the result
+// reports partial deliveries.
+func (c *Client) WaitForOrderGroup(ctx context.Context, userOrderID string, opts *OrderGroupWaitOptions) (*OrderGroup, error) {
+	if userOrderID == "" {
+		return nil, errors.New("user order ID is required")
+	}
+	if opts == nil {
+		opts = &OrderGroupWaitOptions{WaitOptions: WaitOptions{
+			PollInterval: 30 * time.Second,
+			Timeout:      24 * time.Hour,
+		}}
+	}
+
+	var (
+		g   *OrderGroup
+		ids []string
+	)
+	err := common.Poll(ctx, opts.WaitOptions, func(ctx context.Context) (bool, error) {
+		var tasks []Task
+		if opts.FixedMembership && ids != nil {
+			for _, id := range ids {
+				t, err := c.GetTask(ctx, id)
+				if err != nil {
+					return false, err
+				}
+				tasks = append(tasks, *t)
+			}
+		} else {
+			var err error
+			if tasks, err = c.searchOrderTasks(ctx, userOrderID); err != nil {
+				return false, err
+			}
+			if opts.FixedMembership {
+				for _, t := range tasks {
+					ids = append(ids, t.ID)
+				}
+			}
+		}
+		g = newOrderGroup(userOrderID, tasks)
+		return g.Done(), nil
+	})
+	if errors.Is(err, common.ErrWaitTimeout) {
+		return g, fmt.Errorf("timeout waiting for user order %s: %w", userOrderID, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
@@ -0,0 +1,191 @@
+package umbra_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+// orderTask returns a task of user order "order-1".
+func orderTask(id string, status umbra.TaskStatus, collects ...string) umbra.Task {
+	return umbra.Task{ID: id, UserOrderID: "order-1", Status: status, CollectIDs: collects}
+}
+
+// orderSearchHandler serves task searches for user order "order-1" from the
+// tasks returned by next, which is called once per search.
+func orderSearchHandler(t *testing.T, next func() []umbra.Task) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPost)
+		requirePath(t, r, "/tasking/tasks/search")
+		var req struct {
+			Query map[string]map[string]string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode search: %v", err)
+		}
+		if got := req.Query["userOrderId"]["eq"]; got != "order-1" {
+			t.Errorf("expected userOrderId eq order-1, got %q", got)
+		}
+		jsonResponse(w, http.StatusOK, next())
+	}
+}
+
+func TestGetOrderGroup_Aggregation(t *testing.T) {
+	tests := []struct {
+		name      string
+		tasks     []umbra.Task
+		status    umbra.OrderGroupStatus
+		delivered []string
+		failed    []string
+		done      bool
+	}{
+		{
+			name:   "in progress",
+			tasks:  []umbra.Task{orderTask("a", umbra.TaskStatusActive), orderTask("b", umbra.TaskStatusDelivered)},
+			status: umbra.OrderGroupInProgress, delivered: []string{"b"},
+		},
+		{
+			name:   "all delivered",
+			tasks:  []umbra.Task{orderTask("a", umbra.TaskStatusDelivered), orderTask("b", umbra.TaskStatusCompleted)},
+			status: umbra.OrderGroupDelivered, delivered: []string{"a", "b"}, done: true,
+		},
+		{
+			name:   "partial failure still running",
+			tasks:  []umbra.Task{orderTask("a", umbra.TaskStatusRejected), orderTask("b", umbra.TaskStatusTasked)},
+			status: umbra.OrderGroupFailed, failed: []string{"a"},
+		},
+		{
+			name: "partial failure finished",
+			tasks: []umbra.Task{
+				orderTask("a", umbra.TaskStatusDelivered), orderTask("b", umbra.TaskStatusExpired), orderTask("c", umbra.TaskStatusCanceled),
+			},
+			status: umbra.OrderGroupFailed, delivered: []string{"a"}, failed: []string{"b", "c"}, done: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, _ := newTestClient(t, orderSearchHandler(t, func() []umbra.Task { return tt.tasks }))
+			g, err := cli.GetOrderGroup(context.Background(), "order-1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if g.Status != tt.status {
+				t.Errorf("expected status %s, got %s", tt.status, g.Status)
+			}
+			if !slices.Equal(g.Delivered, tt.delivered) || !slices.Equal(g.Failed, tt.failed) {
+				t.Errorf("expected delivered %v failed %v, got %v %v", tt.delivered, tt.failed, g.Delivered, g.Failed)
+			}
+			if g.Done() != tt.done {
+				t.Errorf("expected Done() = %v", tt.done)
+			}
+			total := 0
+			for _, n := range g.Counts {
+				total += n
+			}
+			if total != len(tt.tasks) {
+				t.Errorf("expected counts to cover %d tasks, got %v", len(tt.tasks), g.Counts)
+			}
+		})
+	}
+}
+
+func TestGetOrderGroup_Summary(t *testing.T) {
+	open := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	a := orderTask("a", umbra.TaskStatusDelivered, "c-1", "c-2")
+	a.WindowStartAt = open.Add(time.Hour)
+	a.StatusHistory = []umbra.StatusChange{
+		{Status: umbra.TaskStatusActive, Timestamp: open},
+		{Status: umbra.TaskStatusDelivered, Timestamp: open.Add(48 * time.Hour)},
+	}
+	b := orderTask("b", umbra.TaskStatusDelivered, "c-3")
+	b.WindowStartAt = open
+	b.StatusHistory = []umbra.StatusChange{{Status: umbra.TaskStatusDelivered, Timestamp: open.Add(72 * time.Hour)}}
+
+	cli, _ := newTestClient(t, orderSearchHandler(t, func() []umbra.Task { return []umbra.Task{a, b} }))
+	g, err := cli.GetOrderGroup(context.Background(), "order-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !g.EarliestWindowStart.Equal(open) {
+		t.Errorf("expected earliest window %v, got %v", open, g.EarliestWindowStart)
+	}
+	if want := open.Add(72 * time.Hour); !g.LatestDelivery.Equal(want) {
+		t.Errorf("expected latest delivery %v, got %v", want, g.LatestDelivery)
+	}
+	if g.CollectCount != 3 {
+		t.Errorf("expected 3 collects, got %d", g.CollectCount)
+	}
+}
+
+func TestGetOrderGroup_NoTasks(t *testing.T) {
+	cli, _ := newTestClient(t, orderSearchHandler(t, func() []umbra.Task { return nil }))
+	if _, err := cli.GetOrderGroup(context.Background(), "order-1"); err == nil {
+		t.Error("expected error for an order without tasks")
+	}
+}
+
+func TestWaitForOrderGroup_LateTask(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		searches int
+	)
+	cli, _ := newTestClient(t, orderSearchHandler(t, func() []umbra.Task {
+		mu.Lock()
+		defer mu.Unlock()
+		searches++
+		switch searches {
+		case 1:
+			return []umbra.Task{orderTask("a", umbra.TaskStatusActive)}
+		case 2:
+			// "a" is delivered, but "b" was added to the order meanwhile.
+			return []umbra.Task{orderTask("a", umbra.TaskStatusDelivered), orderTask("b", umbra.TaskStatusActive)}
+		default:
+			return []umbra.Task{orderTask("a", umbra.TaskStatusDelivered), orderTask("b", umbra.TaskStatusDelivered)}
+		}
+	}))
+
+	opts := &umbra.OrderGroupWaitOptions{WaitOptions: umbra.WaitOptions{PollInterval: time.Millisecond, Timeout: time.Second}}
+	g, err := cli.WaitForOrderGroup(context.Background(), "order-1", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.Status != umbra.OrderGroupDelivered || len(g.Tasks) != 2 {
+		t.Errorf("expected both tasks delivered, got %s with %d tasks", g.Status, len(g.Tasks))
+	}
+	if searches != 3 {
+		t.Errorf("expected 3 searches, got %d", searches)
+	}
+}
+
+func TestWaitForOrderGroup_FixedMembership(t *testing.T) {
+	var gets int
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tasking/tasks/search":
+			jsonResponse(w, http.StatusOK, []umbra.Task{orderTask("a", umbra.TaskStatusActive)})
+		case "/tasking/tasks/a":
+			gets++
+			jsonResponse(w, http.StatusOK, orderTask("a", umbra.TaskStatusDelivered))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	opts := &umbra.OrderGroupWaitOptions{
+		WaitOptions:     umbra.WaitOptions{PollInterval: time.Millisecond, Timeout: time.Second},
+		FixedMembership: true,
+	}
+	g, err := cli.WaitForOrderGroup(context.Background(), "order-1", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.Status != umbra.OrderGroupDelivered || gets != 1 {
+		t.Errorf("expected delivery via one task fetch, got %s after %d fetches", g.Status, gets)
+	}
+}
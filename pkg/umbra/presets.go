@@ -0,0 +1,94 @@
+package umbra
+
+// ----------------------------------------------------------------------------
+// Spotlight Task Presets
+// ----------------------------------------------------------------------------
+
+// Presets bundle the spotlight constraints of common use cases. Each returns
+// options that apply the values of the constants below, in the order listed.
+// Task options apply in order, so options after a preset override its
+// values:
+//
+//	opts := append(umbra.PresetMaritime(), umbra.WithResolution(0.5))
+//	req := umbra.NewSpotlightTask(lon, lat, start, end, opts...)
+//
+// Options placed before a preset are overridden by it. Presets only set
+// constraints and product types; the task name, delivery and tags are left
+// to the caller.
+
+// Maritime: low grazing angles keep sea clutter down and ships bright; VV
+// with a wide scene covers shipping lanes.
+const (
+	MaritimeGrazingMinDegrees = 20.0
+	MaritimeGrazingMaxDegrees = 40.0
+	MaritimePolarization      = PolarizationVV
+	MaritimeResolutionMeters  = 1.0
+	MaritimeMultilookFactor   = 1
+	MaritimeSceneSize         = "10x10_KM"
+)
+
+// MaritimeProductTypes are the products delivered for maritime tasks.
+var MaritimeProductTypes = []ProductType{ProductTypeGEC, ProductTypeSICD, ProductTypeMetadata}
+
+// Infrastructure: steep grazing angles limit layover on structures, with
+// fine resolution multilooked for a low-speckle image.
+const (
+	InfrastructureGrazingMinDegrees = 50.0
+	InfrastructureGrazingMaxDegrees = 70.0
+	InfrastructurePolarization      = PolarizationVV
+	InfrastructureResolutionMeters  = 0.35
+	InfrastructureMultilookFactor   = 4
+	InfrastructureSceneSize         = "5x5_KM"
+)
+
+// InfrastructureProductTypes are the products delivered for infrastructure
+// monitoring tasks.
+var InfrastructureProductTypes = []ProductType{ProductTypeGEC, ProductTypeSIDD, ProductTypeSICD, ProductTypeMetadata}
+
+// Disaster response: the widest grazing range and a coarse single-look
+// image give the scheduler the most opportunities, so the first collect
+// comes as soon as possible.
+const (
+	DisasterResponseGrazingMinDegrees = 20.0
+	DisasterResponseGrazingMaxDegrees = 70.0
+	DisasterResponsePolarization      = PolarizationVV
+	DisasterResponseResolutionMeters  = 1.0
+	DisasterResponseMultilookFactor   = 1
+	DisasterResponseSceneSize         = "10x10_KM"
+)
+
+// DisasterResponseProductTypes are the products delivered for disaster
+// response tasks.
+var DisasterResponseProductTypes = []ProductType{ProductTypeGEC, ProductTypeMetadata}
+
+// PresetMaritime returns the options of the maritime preset.
+func PresetMaritime() []TaskOption {
+	return preset(MaritimeGrazingMinDegrees, MaritimeGrazingMaxDegrees, MaritimePolarization,
+		MaritimeResolutionMeters, MaritimeMultilookFactor, MaritimeSceneSize, MaritimeProductTypes)
+}
+
+// PresetInfrastructure returns the options of the infrastructure
+// monitoring preset.
+func PresetInfrastructure() []TaskOption {
+	return preset(InfrastructureGrazingMinDegrees, InfrastructureGrazingMaxDegrees, InfrastructurePolarization,
+		InfrastructureResolutionMeters, InfrastructureMultilookFactor, InfrastructureSceneSize, InfrastructureProductTypes)
+}
+
+// PresetDisasterResponse returns the options of the disaster response
+// preset.
+func PresetDisasterResponse() []TaskOption {
+	return preset(DisasterResponseGrazingMinDegrees, DisasterResponseGrazingMaxDegrees, DisasterResponsePolarization,
+		DisasterResponseResolutionMeters, DisasterResponseMultilookFactor, DisasterResponseSceneSize, DisasterResponseProductTypes)
+}
+
+func preset(grazingMin, grazingMax float64, pol Polarization, resolution float64, looks int, sceneSize string, products []ProductType) []TaskOption {
+	return []TaskOption{
+		WithGrazingAngle(grazingMin, grazingMax),
+		WithPolarization(pol),
+		WithResolution(resolution),
+		WithMultilookFactor(looks),
+		WithSceneSizeOption(sceneSize),
+		// A copy, so requests do not share the exported slice.
+		WithProductTypes(append([]ProductType(nil), products...)...),
+	}
+}
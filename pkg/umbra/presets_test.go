@@ -0,0 +1,134 @@
+package umbra_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+func TestSpotlightPresets(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(72 * time.Hour)
+
+	tests := []struct {
+		name     string
+		opts     []umbra.TaskOption
+		want     umbra.SpotlightConstraints
+		products []umbra.ProductType
+	}{
+		{
+			name: "maritime",
+			opts: umbra.PresetMaritime(),
+			want: umbra.SpotlightConstraints{
+				Polarization:             umbra.PolarizationVV,
+				RangeResolutionMinMeters: 1.0,
+				MultilookFactor:          1,
+				GrazingAngleMinDegrees:   20,
+				GrazingAngleMaxDegrees:   40,
+				SceneSizeOption:          "10x10_KM",
+			},
+			products: []umbra.ProductType{umbra.ProductTypeGEC, umbra.ProductTypeSICD, umbra.ProductTypeMetadata},
+		},
+		{
+			name: "infrastructure",
+			opts: umbra.PresetInfrastructure(),
+			want: umbra.SpotlightConstraints{
+				Polarization:             umbra.PolarizationVV,
+				RangeResolutionMinMeters: 0.35,
+				MultilookFactor:          4,
+				GrazingAngleMinDegrees:   50,
+				GrazingAngleMaxDegrees:   70,
+				SceneSizeOption:          "5x5_KM",
+			},
+			products: []umbra.ProductType{umbra.ProductTypeGEC, umbra.ProductTypeSIDD, umbra.ProductTypeSICD, umbra.ProductTypeMetadata},
+		},
+		{
+			name: "disaster response",
+			opts: umbra.PresetDisasterResponse(),
+			want: umbra.SpotlightConstraints{
+				Polarization:             umbra.PolarizationVV,
+				RangeResolutionMinMeters: 1.0,
+				MultilookFactor:          1,
+				GrazingAngleMinDegrees:   20,
+				GrazingAngleMaxDegrees:   70,
+				SceneSizeOption:          "10x10_KM",
+			},
+			products: []umbra.ProductType{umbra.ProductTypeGEC, umbra.ProductTypeMetadata},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := umbra.NewSpotlightTask(10, 20, start, end, tt.opts...)
+			got := *req.SpotlightConstraints
+			if got.Geometry == nil {
+				t.Fatal("preset dropped the geometry")
+			}
+			got.Geometry = nil
+			if got != tt.want {
+				t.Errorf("constraints = %+v, want %+v", got, tt.want)
+			}
+			if !slices.Equal(req.ProductTypes, tt.products) {
+				t.Errorf("product types = %v, want %v", req.ProductTypes, tt.products)
+			}
+			if req.ImagingMode != umbra.ImagingModeSpotlight || !req.WindowStartAt.Equal(start) || !req.WindowEndAt.Equal(end) {
+				t.Errorf("preset changed the task basics: %+v", req)
+			}
+		})
+	}
+}
+
+func TestSpotlightPresetOrdering(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(72 * time.Hour)
+
+	// Options after a preset override it.
+	opts := append(umbra.PresetMaritime(),
+		umbra.WithPolarization(umbra.PolarizationHH),
+		umbra.WithGrazingAngle(25, 35),
+		umbra.WithProductTypes(umbra.ProductTypeSICD),
+	)
+	req := umbra.NewSpotlightTask(10, 20, start, end, opts...)
+	sc := req.SpotlightConstraints
+	if sc.Polarization != umbra.PolarizationHH {
+		t.Errorf("polarization = %s, want the override HH", sc.Polarization)
+	}
+	if sc.GrazingAngleMinDegrees != 25 || sc.GrazingAngleMaxDegrees != 35 {
+		t.Errorf("grazing = %g-%g, want the override 25-35", sc.GrazingAngleMinDegrees, sc.GrazingAngleMaxDegrees)
+	}
+	if !slices.Equal(req.ProductTypes, []umbra.ProductType{umbra.ProductTypeSICD}) {
+		t.Errorf("product types = %v, want the override", req.ProductTypes)
+	}
+	if sc.SceneSizeOption != umbra.MaritimeSceneSize {
+		t.Errorf("scene size = %s, want the preset's %s", sc.SceneSizeOption, umbra.MaritimeSceneSize)
+	}
+
+	// Options before a preset are overridden by it.
+	opts = append([]umbra.TaskOption{umbra.WithPolarization(umbra.PolarizationHH), umbra.WithTaskName("ships")}, umbra.PresetMaritime()...)
+	req = umbra.NewSpotlightTask(10, 20, start, end, opts...)
+	if req.SpotlightConstraints.Polarization != umbra.MaritimePolarization {
+		t.Errorf("polarization = %s, want the preset's %s", req.SpotlightConstraints.Polarization, umbra.MaritimePolarization)
+	}
+	if req.TaskName != "ships" {
+		t.Errorf("task name = %q, presets should leave it alone", req.TaskName)
+	}
+
+	// A later preset replaces an earlier one entirely.
+	opts = append(umbra.PresetMaritime(), umbra.PresetInfrastructure()...)
+	req = umbra.NewSpotlightTask(10, 20, start, end, opts...)
+	if req.SpotlightConstraints.MultilookFactor != umbra.InfrastructureMultilookFactor ||
+		req.SpotlightConstraints.GrazingAngleMinDegrees != umbra.InfrastructureGrazingMinDegrees {
+		t.Errorf("constraints = %+v, want the infrastructure preset", req.SpotlightConstraints)
+	}
+}
+
+func TestSpotlightPresetsDoNotShareProductTypes(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	req := umbra.NewSpotlightTask(10, 20, start, start.Add(time.Hour), umbra.PresetMaritime()...)
+	req.ProductTypes[0] = umbra.ProductTypeCPHD
+	if umbra.MaritimeProductTypes[0] != umbra.ProductTypeGEC {
+		t.Error("modifying a request changed the preset's product types")
+	}
+}
@@ -0,0 +1,217 @@
+package umbra
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ----------------------------------------------------------------------------
+// Product Recommendation
+// ----------------------------------------------------------------------------
+
+// ProductRecommendationConstraints configures RecommendProducts.
+type ProductRecommendationConstraints struct {
+	// Products are the product constraints of the feasibility's imaging
+	// mode, as returned by GetProductConstraints. They are passed in so
+	// recommendations can be computed without a client.
+	Products []ProductConstraint
+	// Candidates are the product types to choose from, in order of
+	// preference. Defaults to the product types of Products in the order
+	// they first appear.
+	Candidates []ProductType
+
+	// MaxResolutionMeters is the coarsest acceptable range resolution:
+	// products whose finest resolution is coarser are excluded. Zero
+	// disables the check.
+	MaxResolutionMeters float64
+	// RequireComplex requires a complex product (SICD or CPHD) in the
+	// selection. Without it, complex products are left out, since detected
+	// products are smaller and cheaper to deliver.
+	RequireComplex bool
+
+	// MaxBudget caps the total price of the selection, using Price for the
+	// price of each product. Zero, or a nil Price, disables the check.
+	MaxBudget float64
+	Price     func(ProductType) (price float64, ok bool)
+}
+
+// ExclusionReason says why RecommendProducts left out a product type.
+type ExclusionReason string
+
+const (
+	// ExclusionGrazing: the opportunity's grazing angles lie outside the
+	// product's limits.
+	ExclusionGrazing ExclusionReason = "grazing_angle"
+	// ExclusionLooks: the feasibility's multilook factor is below the
+	// product's recommended looks.
+	ExclusionLooks ExclusionReason = "looks"
+	// ExclusionSceneSize: the product is not offered in the feasibility's
+	// scene size.
+	ExclusionSceneSize ExclusionReason = "scene_size"
+	// ExclusionResolution: the product cannot reach the required resolution.
+	ExclusionResolution ExclusionReason = "resolution"
+	// ExclusionComplex: the product is complex data, which was not required.
+	ExclusionComplex ExclusionReason = "complex_not_required"
+	// ExclusionBudget: the product has no known price or would exceed the
+	// budget.
+	ExclusionBudget ExclusionReason = "budget"
+)
+
+// ProductExclusion records why a product type was not recommended.
+type ProductExclusion struct {
+	ProductType ProductType     `json:"productType"`
+	Reason      ExclusionReason `json:"reason"`
+	Detail      string          `json:"detail"`
+}
+
+// Rationale explains a RecommendProducts selection: the products selected,
+// those excluded and why, and the total price if a budget was checked.
+type Rationale struct {
+	Selected   []ProductType      `json:"selected"`
+	Excluded   []ProductExclusion `json:"excluded,omitempty"`
+	TotalPrice float64            `json:"totalPrice,omitempty"`
+}
+
+// ErrNoProductRecommended is returned by RecommendProducts when no product
+// type meets the constraints; the Rationale lists why each was excluded.
+var ErrNoProductRecommended = errors.New("no product type meets the constraints")
+
+// isComplex reports whether pt is complex (phase-preserving) data.
+func isComplex(pt ProductType) bool {
+	return pt == ProductTypeSICD || pt == ProductTypeCPHD
+}
+
+// RecommendProducts suggests the product types to order for an opportunity
+// of a feasibility. Candidates are excluded if the opportunity's grazing
+// angles or the feasibility's multilook factor and scene size do not meet
+// their product constraints, if they cannot reach the required resolution,
+// if they are complex data that was not asked for, or if they do not fit
+// the budget. Products are added to the budget in order of preference,
+// complex products first when required.
+//
+// The Rationale lists every exclusion with a machine-readable reason. If
+// nothing is selected, or complex data is required but none was selected,
+// the error wraps ErrNoProductRecommended.
+func RecommendProducts(feas *Feasibility, opp Opportunity, constraints ProductRecommendationConstraints) ([]ProductType, Rationale, error) {
+	if feas == nil {
+		return nil, Rationale{}, errors.New("feasibility is nil")
+	}
+
+	var (
+		sceneSize string
+		looks     int
+	)
+	if sc := feas.SpotlightConstraints; sc != nil {
+		sceneSize, looks = sc.SceneSizeOption, sc.MultilookFactor
+	}
+	grazingMin := min(opp.GrazingAngleStartDegrees, opp.GrazingAngleEndDegrees)
+	grazingMax := max(opp.GrazingAngleStartDegrees, opp.GrazingAngleEndDegrees)
+
+	candidates := constraints.Candidates
+	if len(candidates) == 0 {
+		for _, pc := range constraints.Products {
+			if pt := ProductType(pc.ProductType); !slices.Contains(candidates, pt) {
+				candidates = append(candidates, pt)
+			}
+		}
+	}
+
+	var r Rationale
+	exclude := func(pt ProductType, reason ExclusionReason, format string, args ...any) {
+		r.Excluded = append(r.Excluded, ProductExclusion{ProductType: pt, Reason: reason, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	var eligible []ProductType
+	for _, pt := range candidates {
+		if isComplex(pt) && !constraints.RequireComplex {
+			exclude(pt, ExclusionComplex, "%s is complex data, which was not required", pt)
+			continue
+		}
+
+		var offered, matching []ProductConstraint
+		for _, pc := range constraints.Products {
+			if pc.ProductType != string(pt) {
+				continue
+			}
+			offered = append(offered, pc)
+			if sceneSize == "" || pc.SceneSize == sceneSize {
+				matching = append(matching, pc)
+			}
+		}
+		if len(offered) > 0 && len(matching) == 0 {
+			exclude(pt, ExclusionSceneSize, "%s is not offered in scene size %q", pt, sceneSize)
+			continue
+		}
+		if len(matching) == 0 {
+			// Products without constraints, such as metadata, are not checked.
+			eligible = append(eligible, pt)
+			continue
+		}
+
+		// Any matching scene size may be used, so check the loosest limits,
+		// as ValidateTaskAgainstConstraints does.
+		limit := matching[0]
+		for _, pc := range matching[1:] {
+			limit.MinGrazingDegrees = min(limit.MinGrazingDegrees, pc.MinGrazingDegrees)
+			limit.MaxGrazingDegrees = max(limit.MaxGrazingDegrees, pc.MaxGrazingDegrees)
+			limit.RangeResolutionMinMeters = min(limit.RangeResolutionMinMeters, pc.RangeResolutionMinMeters)
+			limit.RecommendedLooks = min(limit.RecommendedLooks, pc.RecommendedLooks)
+		}
+
+		switch {
+		case limit.MinGrazingDegrees > 0 && grazingMax < limit.MinGrazingDegrees:
+			exclude(pt, ExclusionGrazing, "opportunity grazing angle %g-%g° is below the product minimum of %g°",
+				grazingMin, grazingMax, limit.MinGrazingDegrees)
+		case limit.MaxGrazingDegrees > 0 && grazingMin > limit.MaxGrazingDegrees:
+			exclude(pt, ExclusionGrazing, "opportunity grazing angle %g-%g° is above the product maximum of %g°",
+				grazingMin, grazingMax, limit.MaxGrazingDegrees)
+		case looks > 0 && looks < limit.RecommendedLooks:
+			exclude(pt, ExclusionLooks, "multilook factor %d is below the recommended %d looks", looks, limit.RecommendedLooks)
+		case constraints.MaxResolutionMeters > 0 && limit.RangeResolutionMinMeters > constraints.MaxResolutionMeters:
+			exclude(pt, ExclusionResolution, "finest resolution %gm is coarser than the required %gm",
+				limit.RangeResolutionMinMeters, constraints.MaxResolutionMeters)
+		default:
+			eligible = append(eligible, pt)
+		}
+	}
+
+	if constraints.RequireComplex {
+		// Spend the budget on the required complex data first.
+		slices.SortStableFunc(eligible, func(a, b ProductType) int {
+			switch {
+			case isComplex(a) && !isComplex(b):
+				return -1
+			case !isComplex(a) && isComplex(b):
+				return 1
+			}
+			return 0
+		})
+	}
+
+	budgeted := constraints.MaxBudget > 0 && constraints.Price != nil
+	for _, pt := range eligible {
+		if budgeted {
+			price, ok := constraints.Price(pt)
+			if !ok {
+				exclude(pt, ExclusionBudget, "no price is known for %s", pt)
+				continue
+			}
+			if r.TotalPrice+price > constraints.MaxBudget {
+				exclude(pt, ExclusionBudget, "price %g would bring the total to %g, over the budget of %g",
+					price, r.TotalPrice+price, constraints.MaxBudget)
+				continue
+			}
+			r.TotalPrice += price
+		}
+		r.Selected = append(r.Selected, pt)
+	}
+
+	if len(r.Selected) == 0 {
+		return nil, r, ErrNoProductRecommended
+	}
+	if constraints.RequireComplex && !slices.ContainsFunc(r.Selected, isComplex) {
+		return nil, r, fmt.Errorf("complex data required: %w", ErrNoProductRecommended)
+	}
+	return slices.Clone(r.Selected), r, nil
+}
@@ -0,0 +1,171 @@
+package umbra_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+// recommendProducts are spotlight constraints in the shape returned by
+// GetProductConstraints.
+var recommendProducts = []umbra.ProductConstraint{
+	{ProductType: "GEC", SceneSize: "5x5_KM", MinGrazingDegrees: 30, MaxGrazingDegrees: 70, RecommendedLooks: 1, RangeResolutionMinMeters: 0.5},
+	{ProductType: "SIDD", SceneSize: "5x5_KM", MinGrazingDegrees: 40, MaxGrazingDegrees: 70, RecommendedLooks: 2, RangeResolutionMinMeters: 0.25},
+	{ProductType: "SICD", SceneSize: "5x5_KM", MinGrazingDegrees: 20, MaxGrazingDegrees: 80, RecommendedLooks: 1, RangeResolutionMinMeters: 0.25},
+	{ProductType: "CPHD", SceneSize: "10x10_KM", MinGrazingDegrees: 20, MaxGrazingDegrees: 80, RecommendedLooks: 1, RangeResolutionMinMeters: 0.25},
+}
+
+var recommendPrices = map[umbra.ProductType]float64{"GEC": 100, "SIDD": 150, "SICD": 300, "CPHD": 500}
+
+func priceOf(pt umbra.ProductType) (float64, bool) {
+	p, ok := recommendPrices[pt]
+	return p, ok
+}
+
+func grazing(start, end float64) umbra.Opportunity {
+	return umbra.Opportunity{GrazingAngleStartDegrees: start, GrazingAngleEndDegrees: end}
+}
+
+func TestRecommendProducts(t *testing.T) {
+	spotlight := func(sceneSize string, looks int) *umbra.Feasibility {
+		return &umbra.Feasibility{
+			ImagingMode:          umbra.ImagingModeSpotlight,
+			SpotlightConstraints: &umbra.SpotlightConstraints{SceneSizeOption: sceneSize, MultilookFactor: looks},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		feas        *umbra.Feasibility
+		opp         umbra.Opportunity
+		constraints umbra.ProductRecommendationConstraints
+		want        []umbra.ProductType
+		excluded    map[umbra.ProductType]umbra.ExclusionReason
+		total       float64
+		wantErr     bool
+	}{
+		{
+			name:        "high grazing detected products",
+			feas:        spotlight("5x5_KM", 2),
+			opp:         grazing(45, 60),
+			constraints: umbra.ProductRecommendationConstraints{Products: recommendProducts},
+			want:        []umbra.ProductType{"GEC", "SIDD"},
+			excluded:    map[umbra.ProductType]umbra.ExclusionReason{"SICD": umbra.ExclusionComplex, "CPHD": umbra.ExclusionComplex},
+		},
+		{
+			name:        "low grazing drops GEC and SIDD",
+			feas:        spotlight("5x5_KM", 2),
+			opp:         grazing(22, 28),
+			constraints: umbra.ProductRecommendationConstraints{Products: recommendProducts, RequireComplex: true},
+			want:        []umbra.ProductType{"SICD"},
+			excluded: map[umbra.ProductType]umbra.ExclusionReason{
+				"GEC": umbra.ExclusionGrazing, "SIDD": umbra.ExclusionGrazing, "CPHD": umbra.ExclusionSceneSize,
+			},
+		},
+		{
+			name:        "low grazing without complex recommends nothing",
+			feas:        spotlight("5x5_KM", 2),
+			opp:         grazing(22, 28),
+			constraints: umbra.ProductRecommendationConstraints{Products: recommendProducts},
+			excluded: map[umbra.ProductType]umbra.ExclusionReason{
+				"GEC": umbra.ExclusionGrazing, "SIDD": umbra.ExclusionGrazing,
+				"SICD": umbra.ExclusionComplex, "CPHD": umbra.ExclusionComplex,
+			},
+			wantErr: true,
+		},
+		{
+			name:        "single look below SIDD recommendation",
+			feas:        spotlight("5x5_KM", 1),
+			opp:         grazing(45, 60),
+			constraints: umbra.ProductRecommendationConstraints{Products: recommendProducts},
+			want:        []umbra.ProductType{"GEC"},
+			excluded: map[umbra.ProductType]umbra.ExclusionReason{
+				"SIDD": umbra.ExclusionLooks, "SICD": umbra.ExclusionComplex, "CPHD": umbra.ExclusionComplex,
+			},
+		},
+		{
+			name: "resolution floor",
+			feas: spotlight("5x5_KM", 2),
+			opp:  grazing(45, 60),
+			constraints: umbra.ProductRecommendationConstraints{
+				Products: recommendProducts, Candidates: []umbra.ProductType{"GEC", "SIDD"}, MaxResolutionMeters: 0.3,
+			},
+			want:     []umbra.ProductType{"SIDD"},
+			excluded: map[umbra.ProductType]umbra.ExclusionReason{"GEC": umbra.ExclusionResolution},
+		},
+		{
+			name: "tight budget keeps complex first",
+			feas: spotlight("", 2),
+			opp:  grazing(45, 60),
+			constraints: umbra.ProductRecommendationConstraints{
+				Products: recommendProducts, RequireComplex: true, MaxBudget: 450, Price: priceOf,
+			},
+			want:  []umbra.ProductType{"SICD", "GEC"},
+			total: 400,
+			excluded: map[umbra.ProductType]umbra.ExclusionReason{
+				"CPHD": umbra.ExclusionBudget, "SIDD": umbra.ExclusionBudget,
+			},
+		},
+		{
+			name: "budget below any complex product",
+			feas: spotlight("5x5_KM", 2),
+			opp:  grazing(45, 60),
+			constraints: umbra.ProductRecommendationConstraints{
+				Products: recommendProducts, RequireComplex: true, MaxBudget: 200, Price: priceOf,
+			},
+			total: 100, // GEC alone fits, but does not satisfy RequireComplex
+			excluded: map[umbra.ProductType]umbra.ExclusionReason{
+				"SICD": umbra.ExclusionBudget, "SIDD": umbra.ExclusionBudget, "CPHD": umbra.ExclusionSceneSize,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown price",
+			feas: spotlight("5x5_KM", 2),
+			opp:  grazing(45, 60),
+			constraints: umbra.ProductRecommendationConstraints{
+				Products: recommendProducts, Candidates: []umbra.ProductType{"GEC", "METADATA"}, MaxBudget: 1000, Price: priceOf,
+			},
+			want:     []umbra.ProductType{"GEC"},
+			total:    100,
+			excluded: map[umbra.ProductType]umbra.ExclusionReason{"METADATA": umbra.ExclusionBudget},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rationale, err := umbra.RecommendProducts(tt.feas, tt.opp, tt.constraints)
+			if tt.wantErr {
+				if !errors.Is(err, umbra.ErrNoProductRecommended) {
+					t.Fatalf("expected ErrNoProductRecommended, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if rationale.TotalPrice != tt.total {
+				t.Errorf("expected total %g, got %g", tt.total, rationale.TotalPrice)
+			}
+			if len(rationale.Excluded) != len(tt.excluded) {
+				t.Errorf("expected %d exclusions, got %+v", len(tt.excluded), rationale.Excluded)
+			}
+			for _, ex := range rationale.Excluded {
+				if want, ok := tt.excluded[ex.ProductType]; !ok || ex.Reason != want {
+					t.Errorf("%s: expected reason %q, got %q (%s)", ex.ProductType, want, ex.Reason, ex.Detail)
+				}
+				if ex.Detail == "" {
+					t.Errorf("%s: expected a detail message", ex.ProductType)
+				}
+			}
+		})
+	}
+}
+
+func TestRecommendProducts_NilFeasibility(t *testing.T) {
+	if _, _, err := umbra.RecommendProducts(nil, umbra.Opportunity{}, umbra.ProductRecommendationConstraints{}); err == nil {
+		t.Error("expected an error for a nil feasibility")
+	}
+}
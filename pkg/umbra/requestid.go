@@ -0,0 +1,91 @@
+package umbra
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// ClientRequestIDHeader is the request header carrying the client-generated
+// correlation ID of each call.
+const ClientRequestIDHeader = "X-Client-Request-Id"
+
+// ----------------------------------------------------------------------------
+// Request Correlation
+// ----------------------------------------------------------------------------
+
+// ResponseMeta records the correlation IDs of the last call made with a
+// context returned by WithResponseMeta. It is filled in for successful and
+// failed calls alike; failed calls also carry the server ID in
+// APIError.RequestID.
+type ResponseMeta = common.ResponseMeta
+
+type clientRequestIDKey struct{}
+
+// WithResponseMeta returns a context that records the correlation IDs of
+// calls made with it, and the ResponseMeta they are recorded in:
+//
+//	ctx, meta := umbra.WithResponseMeta(ctx)
+//	task, err := client.GetTask(ctx, id)
+//	log.Printf("request-id=%s", meta.RequestID())
+func WithResponseMeta(ctx context.Context) (context.Context, *ResponseMeta) {
+	return common.WithResponseMeta(ctx)
+}
+
+// MetaFromContext returns the ResponseMeta attached by WithResponseMeta, or
+// nil if there is none.
+func MetaFromContext(ctx context.Context) *ResponseMeta {
+	return common.MetaFromContext(ctx)
+}
+
+// WithClientRequestID returns a context whose calls send id as their
+// X-Client-Request-Id instead of a generated one. Use it to keep one ID
+// across the calls of a logical operation, such as retries of a request.
+func WithClientRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, clientRequestIDKey{}, id)
+}
+
+// requestIDTransport sets X-Client-Request-Id on every request and records
+// the correlation IDs in the context's ResponseMeta. A request that already
+// carries the header, such as a retry cloned from an earlier attempt, keeps
+// its ID.
+type requestIDTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := req.Header.Get(ClientRequestIDHeader)
+	if id == "" {
+		id, _ = req.Context().Value(clientRequestIDKey{}).(string)
+		if id == "" {
+			id = newRequestID()
+		}
+		// A RoundTripper must not modify the caller's request.
+		req = req.Clone(req.Context())
+		req.Header.Set(ClientRequestIDHeader, id)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+
+	if meta := MetaFromContext(req.Context()); meta != nil {
+		if resp != nil {
+			meta.Record(resp.StatusCode, common.RequestIDFromHeader(resp.Header), id, nil)
+		} else {
+			meta.Record(0, "", id, nil)
+		}
+	}
+	return resp, err
+}
+
+// newRequestID returns a random (version 4) UUID.
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
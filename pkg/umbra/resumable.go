@@ -0,0 +1,142 @@
+package umbra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+)
+
+// WaitState is the progress of a resumable task wait. It is JSON-serializable
+// so callers can persist it between polls and resume the wait in another
+// process with WaitForTaskStatusResumable.
+type WaitState struct {
+	TaskID string `json:"taskId"`
+	// TargetStatus is the status waited for; the wait also ends on any
+	// terminal status. Defaults to DELIVERED.
+	TargetStatus TaskStatus `json:"targetStatus,omitempty"`
+	// Deadline is when the wait times out. Zero means no deadline beyond
+	// the context.
+	Deadline time.Time `json:"deadline,omitzero"`
+	// LastStatus is the last status observed and reported to
+	// OnStatusChange.
+	LastStatus TaskStatus `json:"lastStatus,omitempty"`
+	LastPollAt time.Time  `json:"lastPollAt,omitzero"`
+	Polls      int        `json:"polls,omitempty"`
+}
+
+// NewWaitState returns the state of a new wait for task taskID to reach
+// target, timing out after timeout (none if zero).
+func NewWaitState(taskID string, target TaskStatus, timeout time.Duration) *WaitState {
+	s := &WaitState{TaskID: taskID, TargetStatus: target}
+	if timeout > 0 {
+		s.Deadline = time.Now().Add(timeout).UTC()
+	}
+	return s
+}
+
+// ResumableWaitOptions configures WaitForTaskStatusResumable.
+type ResumableWaitOptions struct {
+	// WaitOptions sets the polling cadence. Timeout sets the deadline of a
+	// state without one; a deadline already in the state is kept.
+	WaitOptions
+
+	// OnStatusChange is called when the task's status differs from the
+	// state's LastStatus, so a resumed wait does not report a status again.
+	OnStatusChange TaskStatusCallback
+
+	// Checkpoint is called with the updated state after every status change
+	// and, if CheckpointEvery is positive, every CheckpointEvery polls. An
+	// error stops the wait.
+	Checkpoint      func(WaitState) error
+	CheckpointEvery int
+}
+
+// WaitForTaskStatusResumable polls task state.TaskID until it reaches
+// state.TargetStatus or a terminal status, like WaitForTaskStatus, but
+// records its progress in a WaitState that can be checkpointed and resumed
+// after a restart. A resumed wait keeps the original deadline, waits out the
+// rest of the poll interval since state.LastPollAt, and only reports status
+// changes after state.LastStatus.
+//
+// The caller's state is not modified; the updated state is returned along
+// with the task, or with the error so it can still be persisted.
+func (c *Client) WaitForTaskStatusResumable(ctx context.Context, state *WaitState, opts *ResumableWaitOptions) (*Task, *WaitState, error) {
+	if state == nil || state.TaskID == "" {
+		return nil, nil, errors.New("wait state with a task ID is required")
+	}
+	if opts == nil {
+		opts = &ResumableWaitOptions{WaitOptions: WaitOptions{
+			PollInterval: 30 * time.Second,
+			Timeout:      24 * time.Hour,
+		}}
+	}
+
+	s := *state
+	if s.TargetStatus == "" {
+		s.TargetStatus = TaskStatusDelivered
+	}
+	if s.Deadline.IsZero() && opts.Timeout > 0 {
+		s.Deadline = time.Now().Add(opts.Timeout).UTC()
+	}
+
+	pollOpts := opts.WaitOptions
+	pollOpts.Timeout, pollOpts.MaxElapsed = 0, 0
+	if !s.Deadline.IsZero() {
+		// A deadline that has already passed still allows one final poll.
+		pollOpts.Timeout = max(time.Until(s.Deadline), time.Nanosecond)
+	}
+
+	// Resume at the cadence of the original wait.
+	if interval := max(pollOpts.PollInterval, pollOpts.InitialInterval); !s.LastPollAt.IsZero() && interval > 0 {
+		if wait := time.Until(s.LastPollAt.Add(interval)); wait > 0 {
+			if !s.Deadline.IsZero() {
+				wait = min(wait, time.Until(s.Deadline))
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, &s, ctx.Err()
+			case <-timer.C:
+			}
+			if !s.Deadline.IsZero() {
+				pollOpts.Timeout = max(time.Until(s.Deadline), time.Nanosecond)
+			}
+		}
+	}
+
+	var t *Task
+	err := common.Poll(ctx, pollOpts, func(ctx context.Context) (bool, error) {
+		var err error
+		if t, err = c.GetTask(ctx, s.TaskID); err != nil {
+			return false, err
+		}
+		s.Polls++
+		s.LastPollAt = time.Now().UTC()
+
+		checkpoint := opts.CheckpointEvery > 0 && s.Polls%opts.CheckpointEvery == 0
+		if t.Status != s.LastStatus {
+			s.LastStatus = t.Status
+			if opts.OnStatusChange != nil {
+				opts.OnStatusChange(t)
+			}
+			checkpoint = true
+		}
+		if checkpoint && opts.Checkpoint != nil {
+			if err := opts.Checkpoint(s); err != nil {
+				return false, fmt.Errorf("checkpoint wait state: %w", err)
+			}
+		}
+		return t.Status == s.TargetStatus || t.Status.IsTerminal(), nil
+	})
+	if errors.Is(err, common.ErrWaitTimeout) {
+		return nil, &s, fmt.Errorf("timeout waiting for task %s to reach status %s: %w", s.TaskID, s.TargetStatus, err)
+	}
+	if err != nil {
+		return nil, &s, err
+	}
+	return t, &s, nil
+}
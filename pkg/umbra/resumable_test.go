@@ -0,0 +1,184 @@
+package umbra_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/common"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+// statusSequence serves task-1 with each status in turn, repeating the last.
+func statusSequence(t *testing.T, statuses ...umbra.TaskStatus) *umbra.Client {
+	t.Helper()
+	var mu sync.Mutex
+	i := 0
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requirePath(t, r, "/tasking/tasks/task-1")
+		mu.Lock()
+		status := statuses[min(i, len(statuses)-1)]
+		i++
+		mu.Unlock()
+		jsonResponse(w, http.StatusOK, umbra.Task{ID: "task-1", Status: status})
+	})
+	return cli
+}
+
+func fastWait() umbra.WaitOptions {
+	return umbra.WaitOptions{PollInterval: time.Millisecond, Timeout: time.Minute}
+}
+
+func TestWaitForTaskStatusResumable_Checkpoints(t *testing.T) {
+	cli := statusSequence(t, umbra.TaskStatusScheduled, umbra.TaskStatusScheduled, umbra.TaskStatusTasked, umbra.TaskStatusDelivered)
+
+	var changes []umbra.TaskStatus
+	var checkpoints []umbra.WaitState
+	task, state, err := cli.WaitForTaskStatusResumable(context.Background(),
+		umbra.NewWaitState("task-1", umbra.TaskStatusDelivered, 0),
+		&umbra.ResumableWaitOptions{
+			WaitOptions:    fastWait(),
+			OnStatusChange: func(t *umbra.Task) { changes = append(changes, t.Status) },
+			Checkpoint: func(s umbra.WaitState) error {
+				checkpoints = append(checkpoints, s)
+				return nil
+			},
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.Status != umbra.TaskStatusDelivered || state.Polls != 4 {
+		t.Errorf("expected delivery after 4 polls, got %s after %d", task.Status, state.Polls)
+	}
+	want := []umbra.TaskStatus{umbra.TaskStatusScheduled, umbra.TaskStatusTasked, umbra.TaskStatusDelivered}
+	if len(changes) != len(want) || len(checkpoints) != len(want) {
+		t.Fatalf("expected %d changes and checkpoints, got %v and %d", len(want), changes, len(checkpoints))
+	}
+	for i, s := range want {
+		if changes[i] != s || checkpoints[i].LastStatus != s {
+			t.Errorf("change %d: expected %s, got %s (checkpoint %s)", i, s, changes[i], checkpoints[i].LastStatus)
+		}
+	}
+	if state.Deadline.IsZero() {
+		t.Error("expected the deadline to be set from the timeout")
+	}
+}
+
+func TestWaitForTaskStatusResumable_CheckpointEvery(t *testing.T) {
+	cli := statusSequence(t, umbra.TaskStatusScheduled, umbra.TaskStatusScheduled, umbra.TaskStatusScheduled,
+		umbra.TaskStatusScheduled, umbra.TaskStatusDelivered)
+
+	var polls []int
+	_, _, err := cli.WaitForTaskStatusResumable(context.Background(),
+		&umbra.WaitState{TaskID: "task-1", LastStatus: umbra.TaskStatusScheduled},
+		&umbra.ResumableWaitOptions{
+			WaitOptions:     fastWait(),
+			CheckpointEvery: 2,
+			Checkpoint: func(s umbra.WaitState) error {
+				polls = append(polls, s.Polls)
+				return nil
+			},
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Polls 2 and 4 by count, poll 5 for the change to DELIVERED.
+	if len(polls) != 3 || polls[0] != 2 || polls[1] != 4 || polls[2] != 5 {
+		t.Errorf("unexpected checkpoints at polls %v", polls)
+	}
+}
+
+func TestWaitForTaskStatusResumable_ResumeWithoutReplay(t *testing.T) {
+	cli := statusSequence(t, umbra.TaskStatusTasked, umbra.TaskStatusDelivered)
+
+	// A state persisted by a previous process, round-tripped through JSON.
+	saved := umbra.NewWaitState("task-1", umbra.TaskStatusDelivered, time.Hour)
+	saved.LastStatus = umbra.TaskStatusTasked
+	saved.Polls = 7
+	data, err := json.Marshal(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resumed umbra.WaitState
+	if err := json.Unmarshal(data, &resumed); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := json.Marshal(resumed); string(again) != string(data) {
+		t.Errorf("JSON round-trip is not stable:\n%s\n%s", data, again)
+	}
+
+	var changes []umbra.TaskStatus
+	task, state, err := cli.WaitForTaskStatusResumable(context.Background(), &resumed, &umbra.ResumableWaitOptions{
+		WaitOptions:    fastWait(),
+		OnStatusChange: func(t *umbra.Task) { changes = append(changes, t.Status) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.Status != umbra.TaskStatusDelivered {
+		t.Errorf("expected DELIVERED, got %s", task.Status)
+	}
+	if len(changes) != 1 || changes[0] != umbra.TaskStatusDelivered {
+		t.Errorf("expected only the DELIVERED change to be reported, got %v", changes)
+	}
+	if state.Polls != 9 || !state.Deadline.Equal(saved.Deadline) {
+		t.Errorf("expected 9 polls and the original deadline, got %d and %v", state.Polls, state.Deadline)
+	}
+	if resumed.Polls != 7 {
+		t.Error("expected the caller's state to be left unchanged")
+	}
+}
+
+func TestWaitForTaskStatusResumable_DeadlineCarriedAcrossResume(t *testing.T) {
+	cli := statusSequence(t, umbra.TaskStatusScheduled)
+
+	state := &umbra.WaitState{
+		TaskID:     "task-1",
+		Deadline:   time.Now().Add(50 * time.Millisecond),
+		LastStatus: umbra.TaskStatusScheduled,
+	}
+	start := time.Now()
+	_, got, err := cli.WaitForTaskStatusResumable(context.Background(), state, &umbra.ResumableWaitOptions{
+		// A fresh timeout must not restart the wait.
+		WaitOptions: umbra.WaitOptions{PollInterval: 10 * time.Millisecond, Timeout: time.Hour},
+	})
+	if !errors.Is(err, common.ErrWaitTimeout) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the original deadline to apply, waited %v", elapsed)
+	}
+	if got == nil || got.Polls == 0 {
+		t.Errorf("expected the state to be returned with the error, got %+v", got)
+	}
+}
+
+func TestWaitForTaskStatusResumable_AlreadyTerminal(t *testing.T) {
+	cli := statusSequence(t, umbra.TaskStatusDelivered)
+
+	called := false
+	task, _, err := cli.WaitForTaskStatusResumable(context.Background(),
+		&umbra.WaitState{
+			TaskID:     "task-1",
+			Deadline:   time.Now().Add(-time.Minute), // expired while the worker was down
+			LastStatus: umbra.TaskStatusDelivered,
+			LastPollAt: time.Now().Add(-2 * time.Minute),
+		},
+		&umbra.ResumableWaitOptions{
+			WaitOptions:    fastWait(),
+			OnStatusChange: func(*umbra.Task) { called = true },
+		})
+	if err != nil {
+		t.Fatalf("expected the final poll to succeed, got %v", err)
+	}
+	if task.Status != umbra.TaskStatusDelivered {
+		t.Errorf("expected DELIVERED, got %s", task.Status)
+	}
+	if called {
+		t.Error("expected no callback for the already recorded status")
+	}
+}
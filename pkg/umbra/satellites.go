@@ -0,0 +1,116 @@
+package umbra
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Satellites
+// ----------------------------------------------------------------------------
+
+// SatelliteStatus is the operational status of a satellite.
+type SatelliteStatus string
+
+const (
+	SatelliteStatusActive      SatelliteStatus = "ACTIVE"
+	SatelliteStatusDegraded    SatelliteStatus = "DEGRADED"
+	SatelliteStatusMaintenance SatelliteStatus = "MAINTENANCE"
+)
+
+// Satellite describes a satellite of the Umbra constellation. The Canopy API
+// does not publish constellation status, so the helpers below check against
+// a satellite list supplied by the caller.
+type Satellite struct {
+	ID     string           `json:"id"`
+	Name   string           `json:"name,omitempty"`
+	Status SatelliteStatus  `json:"status"`
+	Orbit  *OrbitParameters `json:"orbit,omitempty"`
+	// NextAvailableAt is when a satellite that is not ACTIVE is expected to
+	// take tasks again, if published.
+	NextAvailableAt *time.Time `json:"nextAvailableAt,omitempty"`
+}
+
+// OrbitParameters describes a satellite's orbit.
+type OrbitParameters struct {
+	AltitudeKm      float64 `json:"altitudeKm,omitempty"`
+	InclinationDeg  float64 `json:"inclinationDegrees,omitempty"`
+	PeriodMinutes   float64 `json:"periodMinutes,omitempty"`
+	LocalTimeOfNode string  `json:"localTimeOfAscendingNode,omitempty"`
+}
+
+// SatelliteUnavailableError lists requested satellites that are not ACTIVE.
+// Satellites missing from the satellite list are reported with an empty
+// status.
+type SatelliteUnavailableError struct {
+	Satellites map[string]SatelliteStatus
+}
+
+func (e *SatelliteUnavailableError) Error() string {
+	ids := make([]string, 0, len(e.Satellites))
+	for id := range e.Satellites {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		status := string(e.Satellites[id])
+		if status == "" {
+			status = "unknown"
+		}
+		parts[i] = fmt.Sprintf("%s (%s)", id, status)
+	}
+	return "satellites not available: " + strings.Join(parts, ", ")
+}
+
+// WithSatelliteIDsValidated is WithSatelliteIDs checked against the given
+// constellation status. It always returns the option; if any of the satellites
+// is not ACTIVE or missing from satellites, it also returns a
+// *SatelliteUnavailableError so the caller can decide whether to task them
+// anyway.
+func WithSatelliteIDsValidated(satellites []Satellite, ids ...string) (TaskOption, error) {
+	opt := WithSatelliteIDs(ids...)
+	sats := satellitesByID(satellites)
+	unavailable := map[string]SatelliteStatus{}
+	for _, id := range ids {
+		if s, ok := sats[id]; !ok || s.Status != SatelliteStatusActive {
+			unavailable[id] = s.Status
+		}
+	}
+	if len(unavailable) > 0 {
+		return opt, &SatelliteUnavailableError{Satellites: unavailable}
+	}
+	return opt, nil
+}
+
+// FilterOpportunitiesBySatelliteStatus returns the opportunities whose
+// satellite has one of the allowed statuses, ACTIVE if none are given.
+// Opportunities without a satellite ID are kept; those on satellites missing
+// from satellites are dropped.
+func FilterOpportunitiesBySatelliteStatus(opportunities []Opportunity, satellites []Satellite, allowed ...SatelliteStatus) []Opportunity {
+	if len(allowed) == 0 {
+		allowed = []SatelliteStatus{SatelliteStatusActive}
+	}
+	sats := satellitesByID(satellites)
+	var out []Opportunity
+	for _, o := range opportunities {
+		if o.SatelliteID != "" {
+			s, ok := sats[o.SatelliteID]
+			if !ok || !slices.Contains(allowed, s.Status) {
+				continue
+			}
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+func satellitesByID(satellites []Satellite) map[string]Satellite {
+	byID := make(map[string]Satellite, len(satellites))
+	for _, s := range satellites {
+		byID[s.ID] = s
+	}
+	return byID
+}
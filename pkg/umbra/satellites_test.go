@@ -0,0 +1,74 @@
+package umbra_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+// constellation is an active, a degraded and a maintenance satellite.
+var constellation = []umbra.Satellite{
+	{ID: "UMBRA_04", Status: umbra.SatelliteStatusActive},
+	{ID: "UMBRA_05", Status: umbra.SatelliteStatusDegraded},
+	{ID: "UMBRA_06", Status: umbra.SatelliteStatusMaintenance},
+}
+
+func TestWithSatelliteIDsValidated(t *testing.T) {
+	opt, err := umbra.WithSatelliteIDsValidated(constellation, "UMBRA_04")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var req umbra.CreateTaskRequest
+	opt(&req)
+	if len(req.SatelliteIDs) != 1 || req.SatelliteIDs[0] != "UMBRA_04" {
+		t.Errorf("expected satellite IDs to be set, got %v", req.SatelliteIDs)
+	}
+
+	opt, err = umbra.WithSatelliteIDsValidated(constellation, "UMBRA_04", "UMBRA_06", "UMBRA_99")
+	var unavailable *umbra.SatelliteUnavailableError
+	if !errors.As(err, &unavailable) {
+		t.Fatalf("expected *SatelliteUnavailableError, got %v", err)
+	}
+	if len(unavailable.Satellites) != 2 || unavailable.Satellites["UMBRA_06"] != umbra.SatelliteStatusMaintenance {
+		t.Errorf("unexpected unavailable satellites: %v", unavailable.Satellites)
+	}
+	if want := "satellites not available: UMBRA_06 (MAINTENANCE), UMBRA_99 (unknown)"; err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+	if opt == nil {
+		t.Error("expected the option to be returned with the warning")
+	}
+}
+
+func TestFilterOpportunitiesBySatelliteStatus(t *testing.T) {
+	opps := []umbra.Opportunity{
+		{SatelliteID: "UMBRA_04"},
+		{SatelliteID: "UMBRA_05"},
+		{SatelliteID: "UMBRA_06"},
+		{SatelliteID: "UMBRA_99"},
+		{},
+	}
+
+	tests := []struct {
+		name    string
+		allowed []umbra.SatelliteStatus
+		want    []string
+	}{
+		{"default active", nil, []string{"UMBRA_04", ""}},
+		{"active or degraded", []umbra.SatelliteStatus{umbra.SatelliteStatusActive, umbra.SatelliteStatusDegraded}, []string{"UMBRA_04", "UMBRA_05", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := umbra.FilterOpportunitiesBySatelliteStatus(opps, constellation, tt.allowed...)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d opportunities, got %d", len(tt.want), len(got))
+			}
+			for i, o := range got {
+				if o.SatelliteID != tt.want[i] {
+					t.Errorf("opportunity %d: expected satellite %q, got %q", i, tt.want[i], o.SatelliteID)
+				}
+			}
+		})
+	}
+}
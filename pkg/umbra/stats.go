@@ -0,0 +1,148 @@
+package umbra
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// DefaultTaskStatsMaxPages is the page budget GetTaskStatistics uses when
+// TaskStatsOptions.MaxPages is not set.
+const DefaultTaskStatsMaxPages = 50
+
+// ----------------------------------------------------------------------------
+// Task Statistics
+// ----------------------------------------------------------------------------
+
+// TaskStatsOptions filters the tasks counted by GetTaskStatistics.
+type TaskStatsOptions struct {
+	// CreatedAfter and CreatedBefore bound the task creation time; zero
+	// values leave the range open.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// Tags restricts the count to tasks carrying all of the tags.
+	Tags []string
+	// SatelliteIDs restricts the count to tasks requesting any of the
+	// satellites. The search API cannot filter on them, so they are applied
+	// to the search results.
+	SatelliteIDs []string
+
+	// MaxPages is the most search pages of tasks counted. If a further
+	// page holds more tasks, GetTaskStatistics gives up with a
+	// *PageBudgetExceededError. Defaults to DefaultTaskStatsMaxPages.
+	MaxPages int
+	// PageSize is the number of tasks per search page. Defaults to 100.
+	PageSize int
+}
+
+// TaskStatistics holds task counts for dashboards.
+type TaskStatistics struct {
+	Total         int
+	ByStatus      map[TaskStatus]int
+	ByImagingMode map[ImagingMode]int
+	// ByTag counts tasks per tag; a task with several tags counts once for
+	// each of them.
+	ByTag map[string]int
+	// Pages is the number of search pages read to compute the counts.
+	Pages int
+}
+
+// PageBudgetExceededError is returned by GetTaskStatistics when counting the
+// matching tasks would take more than MaxPages search pages. Partial holds
+// the counts over the first MaxPages pages.
+type PageBudgetExceededError struct {
+	MaxPages int
+	Partial  *TaskStatistics
+}
+
+func (e *PageBudgetExceededError) Error() string {
+	return fmt.Sprintf("task statistics need more than %d search pages; narrow the filters or raise MaxPages", e.MaxPages)
+}
+
+// GetTaskStatistics counts tasks by status, imaging mode and tag. A nil opts
+// counts all tasks of the organization.
+//
+// Umbra has no statistics endpoint, so the counts are computed by paging
+// through POST /tasking/tasks/search. This costs one request per page and
+// can be slow for large organizations; the page budget (see
+// TaskStatsOptions.MaxPages) stops it from running away. Delivered area is
+// not reported, as tasks do not carry it.
+func (c *Client) GetTaskStatistics(ctx context.Context, opts *TaskStatsOptions) (*TaskStatistics, error) {
+	if opts == nil {
+		opts = &TaskStatsOptions{}
+	}
+	maxPages := opts.MaxPages
+	if maxPages <= 0 {
+		maxPages = DefaultTaskStatsMaxPages
+	}
+	limit := opts.PageSize
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	stats := &TaskStatistics{
+		ByStatus:      make(map[TaskStatus]int),
+		ByImagingMode: make(map[ImagingMode]int),
+		ByTag:         make(map[string]int),
+	}
+	// SearchTasks reads pages until one is short, so the n-th task is on
+	// page n/limit+1 and n tasks take n/limit+1 pages.
+	n := 0
+	for t, err := range c.SearchTasks(ctx, TaskSearchRequest{Limit: &limit, Query: opts.query()}) {
+		if err != nil {
+			return nil, err
+		}
+		if n/limit == maxPages {
+			stats.Pages = maxPages
+			return nil, &PageBudgetExceededError{MaxPages: maxPages, Partial: stats}
+		}
+		n++
+		if opts.matches(&t) {
+			stats.add(&t)
+		}
+	}
+	stats.Pages = n/limit + 1
+	return stats, nil
+}
+
+// query returns the search query for the server-side filters.
+func (o *TaskStatsOptions) query() map[string]interface{} {
+	q := make(map[string]interface{})
+	created := make(map[string]string)
+	if !o.CreatedAfter.IsZero() {
+		created["gte"] = o.CreatedAfter.UTC().Format(time.RFC3339)
+	}
+	if !o.CreatedBefore.IsZero() {
+		created["lt"] = o.CreatedBefore.UTC().Format(time.RFC3339)
+	}
+	if len(created) > 0 {
+		q["createdAt"] = created
+	}
+	if len(o.Tags) > 0 {
+		q["tags"] = map[string][]string{"all": o.Tags}
+	}
+	if len(q) == 0 {
+		return nil
+	}
+	return q
+}
+
+// matches applies the filters the search API does not support.
+func (o *TaskStatsOptions) matches(t *Task) bool {
+	if len(o.SatelliteIDs) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(t.SatelliteIDs, func(id string) bool {
+		return slices.Contains(o.SatelliteIDs, id)
+	})
+}
+
+func (s *TaskStatistics) add(t *Task) {
+	s.Total++
+	s.ByStatus[t.Status]++
+	s.ByImagingMode[t.ImagingMode]++
+	for _, tag := range t.Tags {
+		s.ByTag[tag]++
+	}
+}
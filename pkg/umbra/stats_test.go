@@ -0,0 +1,143 @@
+package umbra_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+// statsSearchHandler serves tasks in pages of the requested limit, recording
+// each search request.
+func statsSearchHandler(t *testing.T, tasks []umbra.Task, requests *[]umbra.TaskSearchRequest) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodPost)
+		requirePath(t, r, "/tasking/tasks/search")
+		var req umbra.TaskSearchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode search: %v", err)
+		}
+		*requests = append(*requests, req)
+		skip, limit := *req.Skip, *req.Limit
+		page := tasks[min(skip, len(tasks)):min(skip+limit, len(tasks))]
+		jsonResponse(w, http.StatusOK, page)
+	}
+}
+
+func statsTasks() []umbra.Task {
+	return []umbra.Task{
+		{ID: "t1", Status: umbra.TaskStatusDelivered, ImagingMode: umbra.ImagingModeSpotlight, Tags: []string{"port", "weekly"}, SatelliteIDs: []string{"UMBRA_05"}},
+		{ID: "t2", Status: umbra.TaskStatusDelivered, ImagingMode: umbra.ImagingModeSpotlight, Tags: []string{"port"}},
+		{ID: "t3", Status: umbra.TaskStatusActive, ImagingMode: umbra.ImagingModeScan, Tags: []string{"weekly"}, SatelliteIDs: []string{"UMBRA_06"}},
+		{ID: "t4", Status: umbra.TaskStatusRejected, ImagingMode: umbra.ImagingModeSpotlight},
+		{ID: "t5", Status: umbra.TaskStatusActive, ImagingMode: umbra.ImagingModeSpotlight, Tags: []string{"port"}, SatelliteIDs: []string{"UMBRA_05", "UMBRA_07"}},
+	}
+}
+
+func TestGetTaskStatistics_Aggregation(t *testing.T) {
+	var requests []umbra.TaskSearchRequest
+	cli, _ := newTestClient(t, statsSearchHandler(t, statsTasks(), &requests))
+
+	stats, err := cli.GetTaskStatistics(context.Background(), &umbra.TaskStatsOptions{PageSize: 2})
+	if err != nil {
+		t.Fatalf("GetTaskStatistics() error = %v", err)
+	}
+	if len(requests) != 3 || stats.Pages != 3 {
+		t.Errorf("expected 3 pages, got %d requests and Pages = %d", len(requests), stats.Pages)
+	}
+	for i, req := range requests {
+		if *req.Skip != 2*i || *req.Limit != 2 {
+			t.Errorf("page %d: skip = %d, limit = %d", i, *req.Skip, *req.Limit)
+		}
+	}
+	if stats.Total != 5 {
+		t.Errorf("Total = %d, want 5", stats.Total)
+	}
+	wantStatus := map[umbra.TaskStatus]int{umbra.TaskStatusDelivered: 2, umbra.TaskStatusActive: 2, umbra.TaskStatusRejected: 1}
+	if !reflect.DeepEqual(stats.ByStatus, wantStatus) {
+		t.Errorf("ByStatus = %v, want %v", stats.ByStatus, wantStatus)
+	}
+	wantMode := map[umbra.ImagingMode]int{umbra.ImagingModeSpotlight: 4, umbra.ImagingModeScan: 1}
+	if !reflect.DeepEqual(stats.ByImagingMode, wantMode) {
+		t.Errorf("ByImagingMode = %v, want %v", stats.ByImagingMode, wantMode)
+	}
+	wantTag := map[string]int{"port": 3, "weekly": 2}
+	if !reflect.DeepEqual(stats.ByTag, wantTag) {
+		t.Errorf("ByTag = %v, want %v", stats.ByTag, wantTag)
+	}
+	if requests[0].Query != nil {
+		t.Errorf("expected no query without filters, got %v", requests[0].Query)
+	}
+}
+
+func TestGetTaskStatistics_PageBudget(t *testing.T) {
+	var requests []umbra.TaskSearchRequest
+	cli, _ := newTestClient(t, statsSearchHandler(t, statsTasks(), &requests))
+
+	_, err := cli.GetTaskStatistics(context.Background(), &umbra.TaskStatsOptions{PageSize: 2, MaxPages: 2})
+	var budgetErr *umbra.PageBudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected *PageBudgetExceededError, got %v", err)
+	}
+	// The third page shows that more tasks remain.
+	if len(requests) != 3 {
+		t.Errorf("expected the search to stop after 3 pages, got %d requests", len(requests))
+	}
+	if budgetErr.MaxPages != 2 || budgetErr.Partial.Total != 4 {
+		t.Errorf("got MaxPages = %d, partial total = %d; want 2, 4", budgetErr.MaxPages, budgetErr.Partial.Total)
+	}
+
+	// A budget that fits the search succeeds.
+	requests = nil
+	if _, err := cli.GetTaskStatistics(context.Background(), &umbra.TaskStatsOptions{PageSize: 2, MaxPages: 3}); err != nil {
+		t.Errorf("unexpected error with a sufficient budget: %v", err)
+	}
+}
+
+func TestGetTaskStatistics_ExactBudget(t *testing.T) {
+	// MaxPages full pages and an empty one fit the budget.
+	var requests []umbra.TaskSearchRequest
+	cli, _ := newTestClient(t, statsSearchHandler(t, statsTasks()[:4], &requests))
+
+	stats, err := cli.GetTaskStatistics(context.Background(), &umbra.TaskStatsOptions{PageSize: 2, MaxPages: 2})
+	if err != nil {
+		t.Fatalf("GetTaskStatistics() error = %v", err)
+	}
+	if stats.Total != 4 || stats.Pages != 3 || len(requests) != 3 {
+		t.Errorf("got Total = %d, Pages = %d after %d requests; want 4, 3, 3", stats.Total, stats.Pages, len(requests))
+	}
+}
+
+func TestGetTaskStatistics_Filters(t *testing.T) {
+	var requests []umbra.TaskSearchRequest
+	cli, _ := newTestClient(t, statsSearchHandler(t, statsTasks(), &requests))
+
+	after := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 7, 1, 2, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	stats, err := cli.GetTaskStatistics(context.Background(), &umbra.TaskStatsOptions{
+		CreatedAfter:  after,
+		CreatedBefore: before,
+		Tags:          []string{"port", "weekly"},
+		SatelliteIDs:  []string{"UMBRA_05"},
+	})
+	if err != nil {
+		t.Fatalf("GetTaskStatistics() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"createdAt": map[string]interface{}{"gte": "2024-06-01T00:00:00Z", "lt": "2024-07-01T00:00:00Z"},
+		"tags":      map[string]interface{}{"all": []interface{}{"port", "weekly"}},
+	}
+	if len(requests) != 1 || !reflect.DeepEqual(requests[0].Query, want) {
+		t.Errorf("query = %v, want %v", requests[0].Query, want)
+	}
+	// The satellite filter is applied to the results: t1 and t5.
+	if stats.Total != 2 || stats.ByStatus[umbra.TaskStatusDelivered] != 1 || stats.ByStatus[umbra.TaskStatusActive] != 1 {
+		t.Errorf("expected t1 and t5 to be counted, got %+v", stats)
+	}
+}
@@ -2,6 +2,7 @@ package umbra
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"net/http"
@@ -36,6 +37,20 @@ const (
 	TaskStatusCompleted       TaskStatus = "COMPLETED"
 )
 
+// IsKnown reports whether s is one of the statuses above. Statuses added by
+// Umbra after this release decode unchanged but are not known.
+func (s TaskStatus) IsKnown() bool {
+	switch s {
+	case TaskStatusReceived, TaskStatusSubmitted, TaskStatusReview, TaskStatusAccepted,
+		TaskStatusActive, TaskStatusScheduled, TaskStatusRejected, TaskStatusExpired,
+		TaskStatusTasked, TaskStatusTransmitted, TaskStatusIncomplete, TaskStatusProcessing,
+		TaskStatusProcessed, TaskStatusDelivering, TaskStatusDelivered, TaskStatusCancelRequested,
+		TaskStatusCanceled, TaskStatusError, TaskStatusAnomaly, TaskStatusCompleted:
+		return true
+	}
+	return false
+}
+
 // IsTerminal returns true if the status is a terminal state.
 func (s TaskStatus) IsTerminal() bool {
 	switch s {
@@ -73,6 +88,10 @@ type Task struct {
 	UserID               string                `json:"userId,omitempty"`
 	SatelliteIDs         []string              `json:"satelliteIds,omitempty"`
 	Tags                 []string              `json:"tags,omitempty"`
+
+	// Extra holds top-level fields the API returned that Task does not
+	// declare. They are re-emitted when the task is marshaled.
+	Extra map[string]any `json:"-"`
 }
 
 // CreateTaskRequest contains parameters for creating a new task.
@@ -107,9 +126,23 @@ type TaskListResponse struct {
 	Offset     int    `json:"offset"`
 }
 
-// CreateTask creates a new task.
+// CreateTask creates a new task. With WithTaskLint, the task is first
+// checked with LintTaskRequest, and with WithTaskValidation with
+// ValidateTaskAgainstConstraints.
 // POST /tasking/tasks
 func (c *Client) CreateTask(ctx context.Context, req *CreateTaskRequest) (*Task, error) {
+	if c.lintTasks {
+		if findings := LintTaskRequest(req, time.Now()); HasLintErrors(findings) {
+			return nil, &LintError{Findings: findings}
+		}
+	}
+	if c.validateTasks {
+		return c.CreateTaskValidated(ctx, req)
+	}
+	return c.createTask(ctx, req)
+}
+
+func (c *Client) createTask(ctx context.Context, req *CreateTaskRequest) (*Task, error) {
 	body, err := common.MarshalBody(req)
 	if err != nil {
 		return nil, err
@@ -127,7 +160,8 @@ func (c *Client) GetTask(ctx context.Context, id string) (*Task, error) {
 	return &t, err
 }
 
-// ListTasks retrieves all tasks with optional filtering.
+// ListTasks retrieves a single page of tasks with optional filtering. Use
+// ListTasksIter to iterate over every page.
 // GET /tasking/tasks
 func (c *Client) ListTasks(ctx context.Context, opts *ListTasksOptions) (*TaskListResponse, error) {
 	u := c.BaseURL().JoinPath("tasking", "tasks")
@@ -155,6 +189,52 @@ func (c *Client) ListTasks(ctx context.Context, opts *ListTasksOptions) (*TaskLi
 	return &resp, err
 }
 
+// ListTasksIter returns an iterator over all tasks matching opts, paging with
+// limit/offset until TotalCount is reached or a page comes back empty. The
+// offset advances by the number of tasks actually returned, so a server that
+// caps the page size below opts.Limit is handled.
+//
+// Tasks created during iteration shift later pages; each task is yielded at
+// most once (by ID), so items re-served across a page boundary are skipped
+// rather than yielded twice. Newly created tasks may or may not be yielded.
+func (c *Client) ListTasksIter(ctx context.Context, opts *ListTasksOptions) iter.Seq2[Task, error] {
+	var o ListTasksOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Limit <= 0 {
+		o.Limit = defaultSearchLimit
+	}
+
+	return func(yield func(Task, error) bool) {
+		seen := make(map[string]bool)
+		for {
+			resp, err := c.ListTasks(ctx, &o)
+			if err != nil {
+				yield(Task{}, err)
+				return
+			}
+
+			for _, task := range resp.Tasks {
+				if task.ID != "" {
+					if seen[task.ID] {
+						continue
+					}
+					seen[task.ID] = true
+				}
+				if !yield(task, nil) {
+					return
+				}
+			}
+
+			o.Offset += len(resp.Tasks)
+			if len(resp.Tasks) == 0 || o.Offset >= resp.TotalCount {
+				return
+			}
+		}
+	}
+}
+
 // CancelTask cancels an active task.
 // PATCH /tasking/tasks/{id}/cancel
 func (c *Client) CancelTask(ctx context.Context, id string) (*Task, error) {
@@ -230,30 +310,22 @@ func (c *Client) WaitForTaskStatus(ctx context.Context, taskID string, targetSta
 		}
 	}
 
-	deadline := time.Now().Add(opts.Timeout)
-	ticker := time.NewTicker(opts.PollInterval)
-	defer ticker.Stop()
-
-	for {
-		t, err := c.GetTask(ctx, taskID)
+	var t *Task
+	err := common.Poll(ctx, *opts, func(ctx context.Context) (bool, error) {
+		var err error
+		t, err = c.GetTask(ctx, taskID)
 		if err != nil {
-			return nil, err
-		}
-
-		if t.Status == targetStatus || t.Status.IsTerminal() {
-			return t, nil
-		}
-
-		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("timeout waiting for task %s to reach status %s", taskID, targetStatus)
-		}
-
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
+			return false, err
 		}
+		return t.Status == targetStatus || t.Status.IsTerminal(), nil
+	})
+	if errors.Is(err, common.ErrWaitTimeout) {
+		return nil, fmt.Errorf("timeout waiting for task %s to reach status %s: %w", taskID, targetStatus, err)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return t, nil
 }
 
 // WaitForTaskDelivery polls until the task is delivered or fails.
@@ -270,37 +342,27 @@ func (c *Client) WaitForTaskDeliveryWithCallback(ctx context.Context, taskID str
 		}
 	}
 
-	deadline := time.Now().Add(opts.Timeout)
-	ticker := time.NewTicker(opts.PollInterval)
-	defer ticker.Stop()
-
+	var t *Task
 	var lastStatus TaskStatus
-
-	for {
-		t, err := c.GetTask(ctx, taskID)
+	err := common.Poll(ctx, *opts, func(ctx context.Context) (bool, error) {
+		var err error
+		t, err = c.GetTask(ctx, taskID)
 		if err != nil {
-			return nil, err
+			return false, err
 		}
-
 		if t.Status != lastStatus {
 			lastStatus = t.Status
 			if callback != nil {
 				callback(t)
 			}
 		}
-
-		if t.Status == TaskStatusDelivered || t.Status.IsTerminal() {
-			return t, nil
-		}
-
-		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("timeout waiting for task %s delivery", taskID)
-		}
-
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
-		}
+		return t.Status == TaskStatusDelivered || t.Status.IsTerminal(), nil
+	})
+	if errors.Is(err, common.ErrWaitTimeout) {
+		return nil, fmt.Errorf("timeout waiting for task %s delivery: %w", taskID, err)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return t, nil
 }
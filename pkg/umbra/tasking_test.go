@@ -3,6 +3,8 @@ package umbra_test
 import (
 	"context"
 	"net/http"
+	"slices"
+	"strconv"
 	"testing"
 	"time"
 
@@ -117,6 +119,112 @@ func TestListTasks(t *testing.T) {
 	}
 }
 
+// taskPager serves tasks newest first with limit/offset, capping the page
+// size at maxLimit. onPage runs after each page is served.
+func taskPager(t *testing.T, tasks *[]umbra.Task, maxLimit int, onPage func(page int)) http.HandlerFunc {
+	page := 0
+	return func(w http.ResponseWriter, r *http.Request) {
+		requireMethod(t, r, http.MethodGet)
+		requirePath(t, r, "/tasking/tasks")
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit = min(limit, maxLimit)
+
+		all := *tasks
+		end := min(offset+limit, len(all))
+		var pageTasks []umbra.Task
+		if offset < end {
+			pageTasks = all[offset:end]
+		}
+		jsonResponse(w, http.StatusOK, umbra.TaskListResponse{
+			Tasks:      pageTasks,
+			TotalCount: len(all),
+			Limit:      limit,
+			Offset:     offset,
+		})
+		page++
+		if onPage != nil {
+			onPage(page)
+		}
+	}
+}
+
+func makeTasks(ids ...string) []umbra.Task {
+	tasks := make([]umbra.Task, len(ids))
+	for i, id := range ids {
+		tasks[i] = umbra.Task{ID: id}
+	}
+	return tasks
+}
+
+func collectTaskIDs(t *testing.T, seq func(func(umbra.Task, error) bool)) []string {
+	t.Helper()
+	var ids []string
+	for task, err := range seq {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestListTasksIter_CappedLimit(t *testing.T) {
+	tasks := makeTasks("t7", "t6", "t5", "t4", "t3", "t2", "t1")
+	var pages int
+	cli, _ := newTestClient(t, taskPager(t, &tasks, 3, func(p int) { pages = p }))
+
+	ids := collectTaskIDs(t, cli.ListTasksIter(context.Background(), &umbra.ListTasksOptions{Limit: 10}))
+	want := []string{"t7", "t6", "t5", "t4", "t3", "t2", "t1"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+	if pages != 3 {
+		t.Errorf("expected 3 pages, got %d", pages)
+	}
+}
+
+func TestListTasksIter_InsertionDuringIteration(t *testing.T) {
+	tasks := makeTasks("t6", "t5", "t4", "t3", "t2", "t1")
+	cli, _ := newTestClient(t, taskPager(t, &tasks, 2, func(p int) {
+		if p == 1 {
+			// A task created after the first page shifts everything down by one.
+			tasks = append(makeTasks("new"), tasks...)
+		}
+	}))
+
+	ids := collectTaskIDs(t, cli.ListTasksIter(context.Background(), &umbra.ListTasksOptions{Limit: 2}))
+	want := []string{"t6", "t5", "t4", "t3", "t2", "t1"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("got %v, want %v (no skips, no duplicates)", ids, want)
+	}
+}
+
+func TestListTasksIter_Error(t *testing.T) {
+	calls := 0
+	cli, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 2 {
+			errorResponse(w, http.StatusInternalServerError, "boom")
+			return
+		}
+		jsonResponse(w, http.StatusOK, umbra.TaskListResponse{Tasks: makeTasks("a", "b"), TotalCount: 10})
+	})
+
+	var ids []string
+	var errs int
+	for task, err := range cli.ListTasksIter(context.Background(), &umbra.ListTasksOptions{Limit: 2}) {
+		if err != nil {
+			errs++
+			continue
+		}
+		ids = append(ids, task.ID)
+	}
+	if errs != 1 || len(ids) != 2 {
+		t.Errorf("expected 2 tasks then a single error, got %v and %d errors", ids, errs)
+	}
+}
+
 func TestCancelTask(t *testing.T) {
 	expectedTask := umbra.Task{
 		ID:     "task-789",
@@ -14,6 +14,11 @@ const (
 	ImagingModeScan      ImagingMode = "SCAN"
 )
 
+// IsKnown reports whether m is one of the imaging modes above.
+func (m ImagingMode) IsKnown() bool {
+	return m == ImagingModeSpotlight || m == ImagingModeScan
+}
+
 // Polarization represents the radar polarization type.
 type Polarization string
 
@@ -36,6 +41,16 @@ const (
 	ProductTypeDITIF    ProductType = "DI_TIF"   // Display Image TIF
 )
 
+// IsKnown reports whether p is one of the product types above.
+func (p ProductType) IsKnown() bool {
+	switch p {
+	case ProductTypeGEC, ProductTypeSICD, ProductTypeSIDD, ProductTypeCPHD,
+		ProductTypeCRSD, ProductTypeMetadata, ProductTypeDIGIF, ProductTypeDITIF:
+		return true
+	}
+	return false
+}
+
 // GeoJSONGeometry is an alias for geojson.Geometry for backwards compatibility.
 type GeoJSONGeometry = geojson.Geometry
 
@@ -83,11 +98,12 @@ type ScanConstraints struct {
 
 // ProductConstraint represents constraints for a product type.
 type ProductConstraint struct {
-	ProductType       string  `json:"productType"`
-	SceneSize         string  `json:"sceneSize"`
-	MinGrazingDegrees float64 `json:"minGrazingAngle"`
-	MaxGrazingDegrees float64 `json:"maxGrazingAngle"`
-	RecommendedLooks  int     `json:"recommendedLooks"`
+	ProductType              string  `json:"productType"`
+	SceneSize                string  `json:"sceneSize"`
+	MinGrazingDegrees        float64 `json:"minGrazingAngle"`
+	MaxGrazingDegrees        float64 `json:"maxGrazingAngle"`
+	RecommendedLooks         int     `json:"recommendedLooks"`
+	RangeResolutionMinMeters float64 `json:"rangeResolutionMinMeters,omitempty"`
 }
 
 // ListOptions contains common pagination options.
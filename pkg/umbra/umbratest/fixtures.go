@@ -0,0 +1,408 @@
+// Package umbratest builds internally consistent Umbra Task and Feasibility
+// fixtures for tests of code built on the umbra package.
+//
+// Fixtures hold the invariants the real API's payloads do: the status
+// history follows the task lifecycle and ends at the task's status, window
+// end is after window start, collect IDs are present only once a task has
+// been tasked, and opportunities lie within the feasibility window with
+// angles and ranges in physical bounds. Values not set by an option are
+// drawn from a seeded generator, so a fixture is reproducible.
+//
+//	task := umbratest.NewTaskFixture(umbratest.WithStatus(umbra.TaskStatusDelivered), umbratest.WithCollects(2))
+//	mux.Handle("GET /tasking/tasks/"+task.ID, umbratest.JSONHandler(http.StatusOK, umbratest.TaskJSON(task)))
+package umbratest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+// orbitAltitudeKm is the nominal altitude of the Umbra constellation, used
+// to derive slant and ground ranges from grazing angles.
+const orbitAltitudeKm = 525.0
+
+// Grazing angle bounds of generated constraints and opportunities, in
+// degrees.
+const (
+	minGrazingDegrees = 25.0
+	maxGrazingDegrees = 70.0
+)
+
+// lifecycle is the status progression of a task that is delivered.
+var lifecycle = []umbra.TaskStatus{
+	umbra.TaskStatusReceived,
+	umbra.TaskStatusReview,
+	umbra.TaskStatusAccepted,
+	umbra.TaskStatusActive,
+	umbra.TaskStatusSubmitted,
+	umbra.TaskStatusScheduled,
+	umbra.TaskStatusTasked,
+	umbra.TaskStatusTransmitted,
+	umbra.TaskStatusProcessing,
+	umbra.TaskStatusProcessed,
+	umbra.TaskStatusDelivering,
+	umbra.TaskStatusDelivered,
+	umbra.TaskStatusCompleted,
+}
+
+// branches maps each status outside lifecycle to the statuses that lead to
+// it.
+var branches = map[umbra.TaskStatus][]umbra.TaskStatus{
+	umbra.TaskStatusRejected:        {umbra.TaskStatusReceived, umbra.TaskStatusReview},
+	umbra.TaskStatusExpired:         lifecycle[:slices.Index(lifecycle, umbra.TaskStatusScheduled)+1],
+	umbra.TaskStatusCancelRequested: lifecycle[:slices.Index(lifecycle, umbra.TaskStatusSubmitted)+1],
+	umbra.TaskStatusCanceled: append(slices.Clone(lifecycle[:slices.Index(lifecycle, umbra.TaskStatusSubmitted)+1]),
+		umbra.TaskStatusCancelRequested),
+	umbra.TaskStatusAnomaly:    lifecycle[:slices.Index(lifecycle, umbra.TaskStatusTasked)+1],
+	umbra.TaskStatusIncomplete: lifecycle[:slices.Index(lifecycle, umbra.TaskStatusTransmitted)+1],
+	umbra.TaskStatusError:      lifecycle[:slices.Index(lifecycle, umbra.TaskStatusProcessing)+1],
+}
+
+// History returns the statuses a task passes through to reach status,
+// ending with status itself. It returns nil for a status unknown to the
+// umbra package.
+func History(status umbra.TaskStatus) []umbra.TaskStatus {
+	if i := slices.Index(lifecycle, status); i >= 0 {
+		return slices.Clone(lifecycle[:i+1])
+	}
+	if path, ok := branches[status]; ok {
+		return append(slices.Clone(path), status)
+	}
+	return nil
+}
+
+// IsTasked reports whether a task in status has been tasked, and so has
+// collects.
+func IsTasked(status umbra.TaskStatus) bool {
+	return slices.Contains(History(status), umbra.TaskStatusTasked)
+}
+
+// ----------------------------------------------------------------------------
+// Options
+// ----------------------------------------------------------------------------
+
+type config struct {
+	seed        uint64
+	status      umbra.TaskStatus
+	mode        umbra.ImagingMode
+	collects    int
+	createdAt   time.Time
+	lon, lat    float64
+	hasLocation bool
+}
+
+// Option configures a fixture.
+type Option func(*config)
+
+// WithSeed seeds the generator of the values no option sets. Fixtures built
+// with the same options and seed are equal. The default seed is 1.
+func WithSeed(seed uint64) Option {
+	return func(c *config) { c.seed = seed }
+}
+
+// WithStatus sets the task status; the default is ACCEPTED. The status
+// history is the lifecycle leading to it. Statuses unknown to the umbra
+// package make NewTaskFixture panic.
+func WithStatus(status umbra.TaskStatus) Option {
+	return func(c *config) { c.status = status }
+}
+
+// WithImagingMode sets the imaging mode and with it which constraints are
+// set; the default is SPOTLIGHT.
+func WithImagingMode(mode umbra.ImagingMode) Option {
+	return func(c *config) { c.mode = mode }
+}
+
+// WithCollects sets the number of collect IDs of a tasked task; the default
+// is 1. Tasks that have not been tasked have no collects regardless.
+func WithCollects(n int) Option {
+	return func(c *config) { c.collects = max(n, 0) }
+}
+
+// WithCreatedAt sets the creation time, from which the window and status
+// history follow. The default is the current time.
+func WithCreatedAt(t time.Time) Option {
+	return func(c *config) { c.createdAt = t.UTC() }
+}
+
+// WithLocation sets the target location; by default one is generated.
+func WithLocation(lon, lat float64) Option {
+	return func(c *config) { c.lon, c.lat, c.hasLocation = lon, lat, true }
+}
+
+func newConfig(opts []Option) (*config, *rand.Rand) {
+	c := &config{
+		seed:      1,
+		status:    umbra.TaskStatusAccepted,
+		mode:      umbra.ImagingModeSpotlight,
+		collects:  1,
+		createdAt: time.Now().UTC(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.createdAt = c.createdAt.Truncate(time.Second)
+	r := rand.New(rand.NewPCG(c.seed, c.seed^0x9e3779b97f4a7c15))
+	if !c.hasLocation {
+		c.lon, c.lat = round(r.Float64()*360-180, 4), round(r.Float64()*140-70, 4)
+	}
+	return c, r
+}
+
+// ----------------------------------------------------------------------------
+// Task Fixtures
+// ----------------------------------------------------------------------------
+
+// NewTaskFixture returns a task in the status set with WithStatus, with a
+// status history that follows the lifecycle to it. The imaging window
+// opens an hour after creation and lasts one to seven days; the steps up to
+// SCHEDULED happen before it opens, TASKED within it and EXPIRED after it.
+// UpdatedAt is the time of the last status change. It panics for an
+// unknown status or imaging mode.
+func NewTaskFixture(opts ...Option) umbra.Task {
+	c, r := newConfig(opts)
+	path := History(c.status)
+	if path == nil {
+		panic(fmt.Sprintf("umbratest: unknown task status %q", c.status))
+	}
+
+	windowStart := c.createdAt.Add(time.Hour)
+	windowEnd := windowStart.Add(time.Duration(1+r.IntN(7)) * 24 * time.Hour)
+	t := umbra.Task{
+		ID:               uuid(r),
+		TaskName:         fmt.Sprintf("fixture-%d", c.seed),
+		Status:           c.status,
+		ImagingMode:      c.mode,
+		WindowStartAt:    windowStart,
+		WindowEndAt:      windowEnd,
+		DeliveryConfigID: uuid(r),
+		CreatedAt:        c.createdAt,
+		OrganizationID:   uuid(r),
+		UserID:           "auth0|" + fmt.Sprintf("%024x", r.Uint64()),
+	}
+	switch c.mode {
+	case umbra.ImagingModeSpotlight:
+		t.SpotlightConstraints = spotlightConstraints(c, r)
+		t.ProductTypes = []umbra.ProductType{umbra.ProductTypeGEC, umbra.ProductTypeSICD}
+	case umbra.ImagingModeScan:
+		t.ScanConstraints = scanConstraints(c, r)
+		t.ProductTypes = []umbra.ProductType{umbra.ProductTypeGEC}
+	default:
+		panic(fmt.Sprintf("umbratest: unknown imaging mode %q", c.mode))
+	}
+
+	at := c.createdAt
+	preWindowStep := time.Hour / time.Duration(len(lifecycle))
+	for i, status := range path {
+		switch {
+		case i == 0:
+		case status == umbra.TaskStatusTasked:
+			// The steps so far all happen before the window opens.
+			at = windowStart.Add(time.Duration(r.Int64N(int64(windowEnd.Sub(windowStart))))).Truncate(time.Second)
+		case status == umbra.TaskStatusExpired:
+			at = windowEnd.Add(time.Duration(1+r.IntN(30)) * time.Minute)
+		case IsTasked(status):
+			at = at.Add(time.Duration(5+r.IntN(55)) * time.Minute)
+		default:
+			at = at.Add(time.Duration(1+r.Int64N(int64(preWindowStep/time.Second))) * time.Second)
+		}
+		t.StatusHistory = append(t.StatusHistory, umbra.StatusChange{Status: status, Timestamp: at})
+	}
+	t.UpdatedAt = at
+
+	if IsTasked(c.status) {
+		t.CollectIDs = make([]string, c.collects)
+		for i := range t.CollectIDs {
+			t.CollectIDs[i] = uuid(r)
+		}
+		t.SatelliteIDs = []string{satelliteID(r)}
+	}
+	return t
+}
+
+func spotlightConstraints(c *config, r *rand.Rand) *umbra.SpotlightConstraints {
+	lo, hi := grazingRange(r)
+	return &umbra.SpotlightConstraints{
+		Geometry:                 umbra.NewPointGeometry(c.lon, c.lat),
+		Polarization:             umbra.PolarizationVV,
+		RangeResolutionMinMeters: []float64{0.25, 0.35, 0.5, 1}[r.IntN(4)],
+		MultilookFactor:          1,
+		GrazingAngleMinDegrees:   lo,
+		GrazingAngleMaxDegrees:   hi,
+		SceneSizeOption:          "5x5_KM",
+	}
+}
+
+func scanConstraints(c *config, r *rand.Rand) *umbra.ScanConstraints {
+	lo, hi := grazingRange(r)
+	// A strip of up to about 50 km heading north-east.
+	d := 0.1 + r.Float64()*0.3
+	return &umbra.ScanConstraints{
+		StartPoint:               umbra.NewPointGeometry(c.lon, c.lat),
+		EndPoint:                 umbra.NewPointGeometry(round(c.lon+d, 4), round(math.Min(c.lat+d, 89), 4)),
+		Polarization:             umbra.PolarizationVV,
+		RangeResolutionMinMeters: 1,
+		GrazingAngleMinDegrees:   lo,
+		GrazingAngleMaxDegrees:   hi,
+	}
+}
+
+// grazingRange returns a grazing angle range at least 10 degrees wide
+// within the generated bounds.
+func grazingRange(r *rand.Rand) (lo, hi float64) {
+	lo = minGrazingDegrees + float64(r.IntN(int(maxGrazingDegrees-minGrazingDegrees-10)+1))
+	hi = lo + 10 + float64(r.IntN(int(maxGrazingDegrees-lo-10)+1))
+	return lo, hi
+}
+
+// ----------------------------------------------------------------------------
+// Feasibility Fixtures
+// ----------------------------------------------------------------------------
+
+// NewFeasibilityFixture returns a COMPLETED feasibility with n opportunities
+// over a window opening an hour after creation and lasting seven days.
+// Opportunities are ordered, do not overlap and lie within the window.
+// Their grazing angles are within the request's constraints, and their
+// slant and ground ranges follow from the grazing angles and the orbit
+// altitude. It panics for an unknown imaging mode.
+func NewFeasibilityFixture(n int, opts ...Option) umbra.Feasibility {
+	c, r := newConfig(opts)
+	windowStart := c.createdAt.Add(time.Hour)
+	windowEnd := windowStart.Add(7 * 24 * time.Hour)
+	f := umbra.Feasibility{
+		ID:            uuid(r),
+		Status:        umbra.FeasibilityStatusCompleted,
+		ImagingMode:   c.mode,
+		WindowStartAt: windowStart,
+		WindowEndAt:   windowEnd,
+		Opportunities: []umbra.Opportunity{},
+		CreatedAt:     c.createdAt,
+		UpdatedAt:     c.createdAt.Add(time.Duration(30+r.IntN(90)) * time.Second),
+	}
+	var lo, hi float64
+	var maxDuration int
+	switch c.mode {
+	case umbra.ImagingModeSpotlight:
+		f.SpotlightConstraints = spotlightConstraints(c, r)
+		lo, hi = f.SpotlightConstraints.GrazingAngleMinDegrees, f.SpotlightConstraints.GrazingAngleMaxDegrees
+		maxDuration = 30
+	case umbra.ImagingModeScan:
+		f.ScanConstraints = scanConstraints(c, r)
+		lo, hi = f.ScanConstraints.GrazingAngleMinDegrees, f.ScanConstraints.GrazingAngleMaxDegrees
+		maxDuration = 120
+	default:
+		panic(fmt.Sprintf("umbratest: unknown imaging mode %q", c.mode))
+	}
+
+	if n <= 0 {
+		return f
+	}
+	// One opportunity per equal slot of the window.
+	slot := windowEnd.Sub(windowStart) / time.Duration(n)
+	for i := range n {
+		duration := min(time.Duration(5+r.IntN(maxDuration-4))*time.Second, slot/2).Truncate(time.Second)
+		offset := time.Duration(r.Int64N(int64(slot-duration) + 1)).Truncate(time.Second)
+		start := windowStart.Add(time.Duration(i)*slot + offset)
+
+		grazingStart := round(lo+r.Float64()*(hi-lo), 2)
+		grazingEnd := round(math.Max(lo, math.Min(hi, grazingStart+r.Float64()*2-1)), 2)
+		azimuth := r.IntN(36000) // hundredths of a degree
+		squint := round(r.Float64()*60-30, 2)
+		f.Opportunities = append(f.Opportunities, umbra.Opportunity{
+			WindowStartAt:                  start,
+			WindowEndAt:                    start.Add(duration),
+			DurationSec:                    duration.Seconds(),
+			GrazingAngleStartDegrees:       grazingStart,
+			GrazingAngleEndDegrees:         grazingEnd,
+			TargetAzimuthAngleStartDegrees: float64(azimuth) / 100,
+			TargetAzimuthAngleEndDegrees:   float64((azimuth+r.IntN(400))%36000) / 100,
+			SquintAngleStartDegrees:        squint,
+			SquintAngleEndDegrees:          round(-squint, 2),
+			SlantRangeStartKm:              slantRangeKm(grazingStart),
+			SlantRangeEndKm:                slantRangeKm(grazingEnd),
+			GroundRangeStartKm:             groundRangeKm(grazingStart),
+			GroundRangeEndKm:               groundRangeKm(grazingEnd),
+			SatelliteID:                    satelliteID(r),
+		})
+	}
+	return f
+}
+
+// slantRangeKm and groundRangeKm approximate the ranges to a target seen at
+// a grazing angle, ignoring Earth curvature.
+func slantRangeKm(grazingDegrees float64) float64 {
+	return round(orbitAltitudeKm/math.Sin(grazingDegrees*math.Pi/180), 2)
+}
+
+func groundRangeKm(grazingDegrees float64) float64 {
+	return round(orbitAltitudeKm/math.Tan(grazingDegrees*math.Pi/180), 2)
+}
+
+// ----------------------------------------------------------------------------
+// JSON
+// ----------------------------------------------------------------------------
+
+// TaskJSON returns t encoded as GET /tasking/tasks/{id} returns it.
+func TaskJSON(t umbra.Task) []byte {
+	return mustMarshal(t)
+}
+
+// TaskListJSON returns tasks encoded as GET /tasking/tasks returns a single
+// page holding all of them.
+func TaskListJSON(tasks ...umbra.Task) []byte {
+	if tasks == nil {
+		tasks = []umbra.Task{}
+	}
+	return mustMarshal(umbra.TaskListResponse{Tasks: tasks, TotalCount: len(tasks), Limit: len(tasks)})
+}
+
+// FeasibilityJSON returns f encoded as GET /tasking/feasibilities/{id}
+// returns it.
+func FeasibilityJSON(f umbra.Feasibility) []byte {
+	return mustMarshal(f)
+}
+
+// JSONHandler returns a handler that answers every request with status and
+// the JSON body, e.g. one of the encodings above.
+func JSONHandler(status int, body []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	}
+}
+
+func mustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("umbratest: %v", err))
+	}
+	return data
+}
+
+// ----------------------------------------------------------------------------
+// Generated Values
+// ----------------------------------------------------------------------------
+
+// uuid returns a random version 4 UUID.
+func uuid(r *rand.Rand) string {
+	hi, lo := r.Uint64(), r.Uint64()
+	return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x",
+		hi>>32, (hi>>16)&0xffff, hi&0x0fff, (lo>>48)&0x3fff|0x8000, lo&0xffffffffffff)
+}
+
+func satelliteID(r *rand.Rand) string {
+	return fmt.Sprintf("UMBRA_%02d", 4+r.IntN(7))
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,245 @@
+package umbratest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra/umbratest"
+)
+
+var allStatuses = []umbra.TaskStatus{
+	umbra.TaskStatusReceived, umbra.TaskStatusSubmitted, umbra.TaskStatusReview, umbra.TaskStatusAccepted,
+	umbra.TaskStatusActive, umbra.TaskStatusScheduled, umbra.TaskStatusRejected, umbra.TaskStatusExpired,
+	umbra.TaskStatusTasked, umbra.TaskStatusTransmitted, umbra.TaskStatusIncomplete, umbra.TaskStatusProcessing,
+	umbra.TaskStatusProcessed, umbra.TaskStatusDelivering, umbra.TaskStatusDelivered, umbra.TaskStatusCancelRequested,
+	umbra.TaskStatusCanceled, umbra.TaskStatusError, umbra.TaskStatusAnomaly, umbra.TaskStatusCompleted,
+}
+
+var createdAt = time.Date(2025, 6, 1, 8, 30, 0, 0, time.UTC)
+
+// checkTask fails t for each invariant task violates.
+func checkTask(t *testing.T, task umbra.Task, collects int) {
+	t.Helper()
+	if !task.WindowEndAt.After(task.WindowStartAt) {
+		t.Errorf("window end %s is not after start %s", task.WindowEndAt, task.WindowStartAt)
+	}
+	if !task.WindowStartAt.After(task.CreatedAt) {
+		t.Errorf("window start %s is not after creation %s", task.WindowStartAt, task.CreatedAt)
+	}
+
+	h := task.StatusHistory
+	if len(h) == 0 || h[len(h)-1].Status != task.Status {
+		t.Fatalf("history %v does not end at status %s", h, task.Status)
+	}
+	if h[0].Status != umbra.TaskStatusReceived || !h[0].Timestamp.Equal(task.CreatedAt) {
+		t.Errorf("history starts with %+v, want RECEIVED at creation", h[0])
+	}
+	for i := 1; i < len(h); i++ {
+		if !h[i].Timestamp.After(h[i-1].Timestamp) {
+			t.Errorf("history is not in order at %d: %v", i, h)
+		}
+	}
+	if !slices.Equal(statuses(h), umbratest.History(task.Status)) {
+		t.Errorf("history %v does not follow the lifecycle %v", statuses(h), umbratest.History(task.Status))
+	}
+	if len(task.History()) != len(h) {
+		t.Errorf("History() drops entries of %v", h)
+	}
+	if !task.UpdatedAt.Equal(h[len(h)-1].Timestamp) {
+		t.Errorf("updatedAt %s is not the last status change", task.UpdatedAt)
+	}
+
+	for _, sc := range h {
+		switch sc.Status {
+		case umbra.TaskStatusTasked:
+			if sc.Timestamp.Before(task.WindowStartAt) || sc.Timestamp.After(task.WindowEndAt) {
+				t.Errorf("tasked at %s, outside the window", sc.Timestamp)
+			}
+		case umbra.TaskStatusExpired:
+			if !sc.Timestamp.After(task.WindowEndAt) {
+				t.Errorf("expired at %s, before the window closed", sc.Timestamp)
+			}
+		}
+	}
+
+	want := 0
+	if umbratest.IsTasked(task.Status) {
+		want = collects
+	}
+	if len(task.CollectIDs) != want {
+		t.Errorf("%s task has %d collects, want %d", task.Status, len(task.CollectIDs), want)
+	}
+
+	switch task.ImagingMode {
+	case umbra.ImagingModeSpotlight:
+		c := task.SpotlightConstraints
+		if c == nil || task.ScanConstraints != nil || c.GrazingAngleMinDegrees >= c.GrazingAngleMaxDegrees {
+			t.Errorf("spotlight constraints = %+v", c)
+		}
+	case umbra.ImagingModeScan:
+		c := task.ScanConstraints
+		if c == nil || task.SpotlightConstraints != nil || c.GrazingAngleMinDegrees >= c.GrazingAngleMaxDegrees {
+			t.Errorf("scan constraints = %+v", c)
+		}
+	}
+}
+
+func statuses(h []umbra.StatusChange) []umbra.TaskStatus {
+	out := make([]umbra.TaskStatus, len(h))
+	for i, sc := range h {
+		out[i] = sc.Status
+	}
+	return out
+}
+
+func TestNewTaskFixtureInvariants(t *testing.T) {
+	for _, status := range allStatuses {
+		for _, mode := range []umbra.ImagingMode{umbra.ImagingModeSpotlight, umbra.ImagingModeScan} {
+			for seed := range uint64(25) {
+				task := umbratest.NewTaskFixture(
+					umbratest.WithStatus(status),
+					umbratest.WithImagingMode(mode),
+					umbratest.WithCollects(int(seed%4)),
+					umbratest.WithCreatedAt(createdAt),
+					umbratest.WithSeed(seed),
+				)
+				if task.Status != status || task.ImagingMode != mode || !task.CreatedAt.Equal(createdAt) {
+					t.Fatalf("options not applied: %+v", task)
+				}
+				checkTask(t, task, int(seed%4))
+			}
+		}
+	}
+}
+
+func TestNewTaskFixtureReproducible(t *testing.T) {
+	opts := []umbratest.Option{umbratest.WithStatus(umbra.TaskStatusDelivered), umbratest.WithCreatedAt(createdAt)}
+	a := umbratest.NewTaskFixture(append(opts, umbratest.WithSeed(7))...)
+	b := umbratest.NewTaskFixture(append(opts, umbratest.WithSeed(7))...)
+	c := umbratest.NewTaskFixture(append(opts, umbratest.WithSeed(8))...)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("fixtures with the same seed differ")
+	}
+	if a.ID == c.ID {
+		t.Error("fixtures with different seeds share an ID")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("unknown status did not panic")
+		}
+	}()
+	umbratest.NewTaskFixture(umbratest.WithStatus("LAUNCHED"))
+}
+
+func TestNewFeasibilityFixtureInvariants(t *testing.T) {
+	for _, mode := range []umbra.ImagingMode{umbra.ImagingModeSpotlight, umbra.ImagingModeScan} {
+		for seed := range uint64(25) {
+			n := int(seed) * 3
+			f := umbratest.NewFeasibilityFixture(n, umbratest.WithImagingMode(mode), umbratest.WithSeed(seed))
+			if f.Status != umbra.FeasibilityStatusCompleted || len(f.Opportunities) != n {
+				t.Fatalf("feasibility has status %s and %d opportunities, want COMPLETED and %d", f.Status, len(f.Opportunities), n)
+			}
+			if !f.WindowEndAt.After(f.WindowStartAt) {
+				t.Errorf("window end %s is not after start %s", f.WindowEndAt, f.WindowStartAt)
+			}
+
+			var lo, hi float64
+			if mode == umbra.ImagingModeSpotlight {
+				lo, hi = f.SpotlightConstraints.GrazingAngleMinDegrees, f.SpotlightConstraints.GrazingAngleMaxDegrees
+			} else {
+				lo, hi = f.ScanConstraints.GrazingAngleMinDegrees, f.ScanConstraints.GrazingAngleMaxDegrees
+			}
+			var prevEnd time.Time
+			for i, o := range f.Opportunities {
+				if o.WindowStartAt.Before(f.WindowStartAt) || o.WindowEndAt.After(f.WindowEndAt) {
+					t.Errorf("opportunity %d [%s, %s] is outside the window", i, o.WindowStartAt, o.WindowEndAt)
+				}
+				if !o.WindowEndAt.After(o.WindowStartAt) || o.DurationSec != o.WindowEndAt.Sub(o.WindowStartAt).Seconds() {
+					t.Errorf("opportunity %d has window [%s, %s] and duration %gs", i, o.WindowStartAt, o.WindowEndAt, o.DurationSec)
+				}
+				if o.WindowStartAt.Before(prevEnd) {
+					t.Errorf("opportunity %d overlaps the one before", i)
+				}
+				prevEnd = o.WindowEndAt
+				for _, g := range []float64{o.GrazingAngleStartDegrees, o.GrazingAngleEndDegrees} {
+					if g < lo || g > hi {
+						t.Errorf("opportunity %d grazing angle %g is outside [%g, %g]", i, g, lo, hi)
+					}
+				}
+				if o.SlantRangeStartKm <= o.GroundRangeStartKm || o.SlantRangeStartKm < 525 {
+					t.Errorf("opportunity %d slant range %g km is not beyond ground range %g km and altitude",
+						i, o.SlantRangeStartKm, o.GroundRangeStartKm)
+				}
+				for _, a := range []float64{o.TargetAzimuthAngleStartDegrees, o.TargetAzimuthAngleEndDegrees} {
+					if a < 0 || a >= 360 {
+						t.Errorf("opportunity %d azimuth %g is outside [0, 360)", i, a)
+					}
+				}
+			}
+		}
+	}
+}
+
+// The JSON emitters produce what the client decodes back to the fixture.
+func TestFixtureJSON(t *testing.T) {
+	task := umbratest.NewTaskFixture(umbratest.WithStatus(umbra.TaskStatusProcessed), umbratest.WithCollects(2))
+	f := umbratest.NewFeasibilityFixture(3)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /tasking/tasks/"+task.ID, umbratest.JSONHandler(http.StatusOK, umbratest.TaskJSON(task)))
+	mux.Handle("GET /tasking/tasks", umbratest.JSONHandler(http.StatusOK, umbratest.TaskListJSON(task)))
+	mux.Handle("GET /tasking/feasibilities/"+f.ID, umbratest.JSONHandler(http.StatusOK, umbratest.FeasibilityJSON(f)))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cli, err := umbra.NewClient("test-token", umbra.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	got, err := cli.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if !sameJSON(t, got, task) {
+		t.Errorf("decoded task = %+v, want %+v", got, task)
+	}
+
+	list, err := cli.ListTasks(ctx, nil)
+	if err != nil || list.TotalCount != 1 || len(list.Tasks) != 1 || list.Tasks[0].ID != task.ID {
+		t.Errorf("ListTasks = %+v, %v", list, err)
+	}
+
+	gotF, err := cli.GetFeasibility(ctx, f.ID)
+	if err != nil {
+		t.Fatalf("GetFeasibility: %v", err)
+	}
+	if !sameJSON(t, gotF, f) {
+		t.Errorf("decoded feasibility = %+v, want %+v", gotF, f)
+	}
+}
+
+// sameJSON reports whether a and b encode to the same JSON document.
+func sameJSON(t *testing.T, a, b any) bool {
+	t.Helper()
+	var docs [2]any
+	for i, v := range []any{a, b} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &docs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return reflect.DeepEqual(docs[0], docs[1])
+}
@@ -0,0 +1,151 @@
+package umbra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// constraintsTTL is how long fetched product constraints are reused.
+const constraintsTTL = time.Hour
+
+// ----------------------------------------------------------------------------
+// Task Validation
+// ----------------------------------------------------------------------------
+
+// constraintsCache holds the product constraints fetched per imaging mode.
+type constraintsCache struct {
+	mu      sync.Mutex
+	entries map[ImagingMode]cachedConstraints
+}
+
+type cachedConstraints struct {
+	constraints []ProductConstraint
+	fetched     time.Time
+}
+
+// productConstraints returns the cached constraints of mode, fetching them
+// when missing or older than constraintsTTL. Failed fetches are not cached.
+func (c *Client) productConstraints(ctx context.Context, mode ImagingMode) ([]ProductConstraint, error) {
+	c.constraints.mu.Lock()
+	defer c.constraints.mu.Unlock()
+
+	if e, ok := c.constraints.entries[mode]; ok && time.Since(e.fetched) < constraintsTTL {
+		return e.constraints, nil
+	}
+	pc, err := c.GetProductConstraints(ctx, mode)
+	if err != nil {
+		return nil, err
+	}
+	if c.constraints.entries == nil {
+		c.constraints.entries = make(map[ImagingMode]cachedConstraints)
+	}
+	c.constraints.entries[mode] = cachedConstraints{constraints: pc, fetched: time.Now()}
+	return pc, nil
+}
+
+// ValidateTaskAgainstConstraints checks a task against the product
+// constraints of its imaging mode for every requested product type: the
+// range resolution must not be finer than the product allows, the grazing
+// angle range must lie within the product's limits, the scene size option
+// must be offered for the product, and the multilook factor must not be
+// below the recommended looks. Product types without constraints are not
+// checked. All violations are returned together, each naming the product
+// type and field.
+//
+// Constraints are fetched once per imaging mode and cached for an hour. If
+// they cannot be fetched the error is returned, unless the client was created
+// with WithSkipValidationOnConstraintsError.
+func (c *Client) ValidateTaskAgainstConstraints(ctx context.Context, req *CreateTaskRequest) error {
+	if req == nil {
+		return errors.New("task request is nil")
+	}
+	constraints, err := c.productConstraints(ctx, req.ImagingMode)
+	if err != nil {
+		err = fmt.Errorf("get product constraints for %s: %w", req.ImagingMode, err)
+		if c.skipOnConstraintsError {
+			if c.constraintsWarn != nil {
+				c.constraintsWarn(err)
+			}
+			return nil
+		}
+		return err
+	}
+
+	// The requested parameters shared by both imaging modes.
+	var (
+		resolution, grazingMin, grazingMax float64
+		sceneSize                          string
+		looks                              int
+	)
+	switch {
+	case req.SpotlightConstraints != nil:
+		sc := req.SpotlightConstraints
+		resolution, grazingMin, grazingMax = sc.RangeResolutionMinMeters, sc.GrazingAngleMinDegrees, sc.GrazingAngleMaxDegrees
+		sceneSize, looks = sc.SceneSizeOption, sc.MultilookFactor
+	case req.ScanConstraints != nil:
+		sc := req.ScanConstraints
+		resolution, grazingMin, grazingMax = sc.RangeResolutionMinMeters, sc.GrazingAngleMinDegrees, sc.GrazingAngleMaxDegrees
+	}
+
+	var errs []error
+	for _, pt := range req.ProductTypes {
+		var matching []ProductConstraint
+		offered := false
+		for _, pc := range constraints {
+			if pc.ProductType != string(pt) {
+				continue
+			}
+			offered = true
+			if sceneSize == "" || pc.SceneSize == sceneSize {
+				matching = append(matching, pc)
+			}
+		}
+		if !offered {
+			continue
+		}
+		if len(matching) == 0 {
+			errs = append(errs, fmt.Errorf("%s: sceneSizeOption %q is not offered", pt, sceneSize))
+			continue
+		}
+
+		// Without a scene size, any of the product's scene sizes may be used,
+		// so check against the loosest limits.
+		limit := matching[0]
+		for _, pc := range matching[1:] {
+			limit.MinGrazingDegrees = min(limit.MinGrazingDegrees, pc.MinGrazingDegrees)
+			limit.MaxGrazingDegrees = max(limit.MaxGrazingDegrees, pc.MaxGrazingDegrees)
+			limit.RangeResolutionMinMeters = min(limit.RangeResolutionMinMeters, pc.RangeResolutionMinMeters)
+			limit.RecommendedLooks = min(limit.RecommendedLooks, pc.RecommendedLooks)
+		}
+
+		if resolution > 0 && resolution < limit.RangeResolutionMinMeters {
+			errs = append(errs, fmt.Errorf("%s: rangeResolutionMinMeters %g is finer than the product minimum of %g",
+				pt, resolution, limit.RangeResolutionMinMeters))
+		}
+		if grazingMin > 0 && limit.MinGrazingDegrees > 0 && grazingMin < limit.MinGrazingDegrees {
+			errs = append(errs, fmt.Errorf("%s: grazingAngleMinDegrees %g is below the product minimum of %g",
+				pt, grazingMin, limit.MinGrazingDegrees))
+		}
+		if grazingMax > 0 && limit.MaxGrazingDegrees > 0 && grazingMax > limit.MaxGrazingDegrees {
+			errs = append(errs, fmt.Errorf("%s: grazingAngleMaxDegrees %g is above the product maximum of %g",
+				pt, grazingMax, limit.MaxGrazingDegrees))
+		}
+		if looks > 0 && looks < limit.RecommendedLooks {
+			errs = append(errs, fmt.Errorf("%s: multilookFactor %d is below the recommended %d looks",
+				pt, looks, limit.RecommendedLooks))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CreateTaskValidated runs ValidateTaskAgainstConstraints and creates the
+// task only if it passes.
+func (c *Client) CreateTaskValidated(ctx context.Context, req *CreateTaskRequest) (*Task, error) {
+	if err := c.ValidateTaskAgainstConstraints(ctx, req); err != nil {
+		return nil, err
+	}
+	return c.createTask(ctx, req)
+}
@@ -0,0 +1,188 @@
+package umbra_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/robert-malhotra/go-sar-vendor/pkg/umbra"
+)
+
+// constraintsHandler serves spotlight product constraints and counts their
+// fetches; task submissions are counted in creates. A non-200 status fails
+// the constraints endpoint.
+func constraintsHandler(t *testing.T, status int, fetches, creates *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tasking/products/SPOTLIGHT/constraints":
+			fetches.Add(1)
+			if status != http.StatusOK {
+				errorResponse(w, status, "constraints unavailable")
+				return
+			}
+			jsonResponse(w, http.StatusOK, []umbra.ProductConstraint{
+				{ProductType: "GEC", SceneSize: "5x5_KM", MinGrazingDegrees: 20, MaxGrazingDegrees: 70, RecommendedLooks: 4, RangeResolutionMinMeters: 0.5},
+				{ProductType: "GEC", SceneSize: "10x10_KM", MinGrazingDegrees: 30, MaxGrazingDegrees: 60, RecommendedLooks: 4, RangeResolutionMinMeters: 1},
+				{ProductType: "SICD", SceneSize: "5x5_KM", MinGrazingDegrees: 10, MaxGrazingDegrees: 80, RecommendedLooks: 1, RangeResolutionMinMeters: 0.15},
+			})
+		case "/tasking/tasks":
+			creates.Add(1)
+			jsonResponse(w, http.StatusCreated, umbra.Task{ID: "task-1"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+}
+
+func spotlightTask(sc umbra.SpotlightConstraints, products ...umbra.ProductType) *umbra.CreateTaskRequest {
+	sc.Geometry = umbra.NewPointGeometry(-122.4, 37.8)
+	return &umbra.CreateTaskRequest{
+		ImagingMode:          umbra.ImagingModeSpotlight,
+		SpotlightConstraints: &sc,
+		ProductTypes:         products,
+	}
+}
+
+func TestValidateTaskAgainstConstraints(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *umbra.CreateTaskRequest
+		want []string
+	}{
+		{
+			name: "valid",
+			req: spotlightTask(umbra.SpotlightConstraints{RangeResolutionMinMeters: 0.5, GrazingAngleMinDegrees: 30, MultilookFactor: 4},
+				umbra.ProductTypeGEC, umbra.ProductTypeSICD, umbra.ProductTypeMetadata),
+		},
+		{
+			name: "one violating product",
+			req: spotlightTask(umbra.SpotlightConstraints{RangeResolutionMinMeters: 0.15, GrazingAngleMinDegrees: 10},
+				umbra.ProductTypeSICD, umbra.ProductTypeGEC),
+			want: []string{"GEC: rangeResolutionMinMeters 0.15", "GEC: grazingAngleMinDegrees 10"},
+		},
+		{
+			name: "scene size narrows limits",
+			req: spotlightTask(umbra.SpotlightConstraints{SceneSizeOption: "10x10_KM", GrazingAngleMaxDegrees: 65, RangeResolutionMinMeters: 0.5},
+				umbra.ProductTypeGEC),
+			want: []string{"GEC: rangeResolutionMinMeters 0.5", "GEC: grazingAngleMaxDegrees 65"},
+		},
+		{
+			name: "scene size not offered",
+			req:  spotlightTask(umbra.SpotlightConstraints{SceneSizeOption: "10x10_KM"}, umbra.ProductTypeSICD),
+			want: []string{`SICD: sceneSizeOption "10x10_KM"`},
+		},
+		{
+			name: "multilook below recommended",
+			req:  spotlightTask(umbra.SpotlightConstraints{MultilookFactor: 2}, umbra.ProductTypeGEC),
+			want: []string{"GEC: multilookFactor 2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetches, creates atomic.Int32
+			cli, _ := newTestClient(t, constraintsHandler(t, http.StatusOK, &fetches, &creates))
+
+			err := cli.ValidateTaskAgainstConstraints(context.Background(), tt.req)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			joined, ok := err.(interface{ Unwrap() []error })
+			if !ok || len(joined.Unwrap()) != len(tt.want) {
+				t.Fatalf("expected %d violations, got: %v", len(tt.want), err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTaskAgainstConstraints_CachesPerMode(t *testing.T) {
+	var fetches, creates atomic.Int32
+	cli, _ := newTestClient(t, constraintsHandler(t, http.StatusOK, &fetches, &creates))
+
+	req := spotlightTask(umbra.SpotlightConstraints{}, umbra.ProductTypeGEC)
+	for range 3 {
+		if err := cli.ValidateTaskAgainstConstraints(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("expected constraints to be fetched once, got %d", n)
+	}
+}
+
+func TestCreateTaskValidated(t *testing.T) {
+	var fetches, creates atomic.Int32
+	cli, _ := newTestClient(t, constraintsHandler(t, http.StatusOK, &fetches, &creates))
+
+	bad := spotlightTask(umbra.SpotlightConstraints{RangeResolutionMinMeters: 0.15}, umbra.ProductTypeGEC)
+	if _, err := cli.CreateTaskValidated(context.Background(), bad); err == nil {
+		t.Fatal("expected validation error")
+	}
+	// Without WithTaskValidation, CreateTask submits as before.
+	if _, err := cli.CreateTask(context.Background(), bad); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := creates.Load(); n != 1 {
+		t.Errorf("expected only the unvalidated task to be created, got %d", n)
+	}
+}
+
+func TestWithTaskValidation(t *testing.T) {
+	var fetches, creates atomic.Int32
+	_, srv := newTestClient(t, constraintsHandler(t, http.StatusOK, &fetches, &creates))
+	cli, err := umbra.NewClient("test-token", umbra.WithBaseURL(srv.URL), umbra.WithTaskValidation())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	bad := spotlightTask(umbra.SpotlightConstraints{RangeResolutionMinMeters: 0.15}, umbra.ProductTypeGEC)
+	if _, err := cli.CreateTask(context.Background(), bad); err == nil {
+		t.Fatal("expected validation error")
+	}
+	good := spotlightTask(umbra.SpotlightConstraints{RangeResolutionMinMeters: 1}, umbra.ProductTypeGEC)
+	if _, err := cli.CreateTask(context.Background(), good); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := creates.Load(); n != 1 {
+		t.Errorf("expected one task to be created, got %d", n)
+	}
+}
+
+func TestValidateTaskAgainstConstraints_FetchFailure(t *testing.T) {
+	var fetches, creates atomic.Int32
+	_, srv := newTestClient(t, constraintsHandler(t, http.StatusServiceUnavailable, &fetches, &creates))
+	req := spotlightTask(umbra.SpotlightConstraints{RangeResolutionMinMeters: 0.15}, umbra.ProductTypeGEC)
+
+	strict, err := umbra.NewClient("test-token", umbra.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if err := strict.ValidateTaskAgainstConstraints(context.Background(), req); !umbra.IsServerError(err) {
+		t.Errorf("expected the fetch error, got %v", err)
+	}
+
+	var warned error
+	lenient, err := umbra.NewClient("test-token", umbra.WithBaseURL(srv.URL),
+		umbra.WithSkipValidationOnConstraintsError(func(err error) { warned = err }))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if err := lenient.ValidateTaskAgainstConstraints(context.Background(), req); err != nil {
+		t.Errorf("expected validation to be skipped, got %v", err)
+	}
+	if !umbra.IsServerError(warned) {
+		t.Errorf("expected the fetch error to be reported, got %v", warned)
+	}
+	if err := lenient.ValidateTaskAgainstConstraints(context.Background(), req); err != nil || fetches.Load() != 3 {
+		t.Errorf("expected failed fetches not to be cached, got %d fetches", fetches.Load())
+	}
+}